@@ -0,0 +1,55 @@
+package addons
+
+import (
+	"context"
+	"testing"
+
+	ctrlfwk "github.com/u-ctf/controller-fwk"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/utils/ptr"
+)
+
+func TestNetworkPolicySpec(t *testing.T) {
+	spec := networkPolicySpec(NetworkPolicyOptions{})
+	if len(spec.PolicyTypes) != 1 || len(spec.Ingress) != 0 || len(spec.Egress) != 0 {
+		t.Fatalf("expected the ingress traffic only to be denied, got %+v", spec)
+	}
+
+	spec = networkPolicySpec(NetworkPolicyOptions{DenyEgress: true, AllowSameNamespace: true, AllowDNS: true})
+	if len(spec.PolicyTypes) != 2 || spec.PolicyTypes[1] != networkingv1.PolicyTypeEgress {
+		t.Fatalf("expected the egress traffic to be denied, got %v", spec.PolicyTypes)
+	}
+	if len(spec.Ingress) != 1 || len(spec.Egress) != 2 {
+		t.Fatalf("expected the same namespace and DNS traffic to be allowed, got %+v", spec)
+	}
+}
+
+func TestPDBForDeploymentSkippedBelowTwoReplicas(t *testing.T) {
+	ctx := ctrlfwk.NewContext[*corev1.ConfigMap](context.Background(), nil)
+	key := types.NamespacedName{Name: "app", Namespace: "default"}
+	deployment := ctrlfwk.NewResourceBuilder(ctx, &appsv1.Deployment{}).WithKey(key).Build()
+	pdb := NewPDBForDeployment(ctx, deployment, intstr.FromInt32(1)).Build()
+
+	if pdb.ShouldDeleteNow() {
+		t.Fatal("expected the PodDisruptionBudget not to be skipped before the deployment is reconciled")
+	}
+
+	for replicas, skipped := range map[int32]bool{1: true, 2: false} {
+		deploy := &appsv1.Deployment{}
+		deploy.SetName(key.Name)
+		deploy.SetNamespace(key.Namespace)
+		deploy.SetResourceVersion("1")
+		deploy.Spec.Replicas = ptr.To(replicas)
+		if err := deployment.Set(deploy); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if pdb.ShouldDeleteNow() != skipped {
+			t.Fatalf("expected the PodDisruptionBudget skipped to be %v with %d replicas", skipped, replicas)
+		}
+	}
+}
@@ -0,0 +1,100 @@
+package addons
+
+import (
+	ctrlfwk "github.com/u-ctf/controller-fwk"
+
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// DefaultNetworkPolicyName is the name of the NetworkPolicy built by NewDefaultNetworkPolicy, unless another one is given.
+const DefaultNetworkPolicyName = "default-deny"
+
+// NetworkPolicyOptions configures the NetworkPolicy built by NewDefaultNetworkPolicy.
+type NetworkPolicyOptions struct {
+	// Name is the name of the NetworkPolicy. Defaults to DefaultNetworkPolicyName.
+	Name string
+	// DenyEgress denies the egress traffic of the pods as well, only the ingress traffic is denied otherwise.
+	DenyEgress bool
+	// AllowSameNamespace allows the traffic between the pods of the namespace.
+	AllowSameNamespace bool
+	// AllowDNS allows the egress traffic to the DNS port, so that the pods can still resolve names when DenyEgress is set.
+	AllowDNS bool
+}
+
+// NewDefaultNetworkPolicy builds a NetworkPolicy denying the traffic of every pod of the namespace returned
+// by namespaceFunc, except what options allow. The NetworkPolicy is skipped when the namespace is empty,
+// and is ready as soon as it exists, NetworkPolicies having no status.
+//
+// As several custom resources of the namespace may declare it, the NetworkPolicy is built with
+// ResourceBuilder.WithSharedOwnership, being deleted once none of them declares it anymore.
+//
+// Example:
+//
+//	addons.NewDefaultNetworkPolicy(ctx, func() string { return ctx.GetCustomResource().GetNamespace() },
+//		addons.NetworkPolicyOptions{DenyEgress: true, AllowDNS: true},
+//	).Build()
+func NewDefaultNetworkPolicy[CustomResource client.Object, ContextType ctrlfwk.Context[CustomResource]](
+	ctx ContextType,
+	namespaceFunc func() string,
+	options NetworkPolicyOptions,
+) *ctrlfwk.ResourceBuilder[CustomResource, ContextType, *networkingv1.NetworkPolicy] {
+	name := options.Name
+	if name == "" {
+		name = DefaultNetworkPolicyName
+	}
+
+	return ctrlfwk.NewResourceBuilder(ctx, &networkingv1.NetworkPolicy{}).
+		WithKeyFunc(func() types.NamespacedName {
+			return types.NamespacedName{Name: name, Namespace: namespaceFunc()}
+		}).
+		WithSkipAndDeleteOnCondition(func() bool {
+			return namespaceFunc() == ""
+		}).
+		WithSharedOwnership(true).
+		WithMutator(func(policy *networkingv1.NetworkPolicy) error {
+			policy.Spec = networkPolicySpec(options)
+			return nil
+		}).
+		WithReadinessCondition(func(*networkingv1.NetworkPolicy) bool {
+			return true
+		})
+}
+
+// networkPolicySpec returns the spec of the NetworkPolicy built by NewDefaultNetworkPolicy.
+func networkPolicySpec(options NetworkPolicyOptions) networkingv1.NetworkPolicySpec {
+	spec := networkingv1.NetworkPolicySpec{
+		PodSelector: metav1.LabelSelector{},
+		PolicyTypes: []networkingv1.PolicyType{networkingv1.PolicyTypeIngress},
+	}
+
+	sameNamespace := []networkingv1.NetworkPolicyPeer{{PodSelector: &metav1.LabelSelector{}}}
+	if options.AllowSameNamespace {
+		spec.Ingress = []networkingv1.NetworkPolicyIngressRule{{From: sameNamespace}}
+	}
+
+	if !options.DenyEgress {
+		return spec
+	}
+
+	spec.PolicyTypes = append(spec.PolicyTypes, networkingv1.PolicyTypeEgress)
+	if options.AllowSameNamespace {
+		spec.Egress = append(spec.Egress, networkingv1.NetworkPolicyEgressRule{To: sameNamespace})
+	}
+	if options.AllowDNS {
+		udp, tcp := corev1.ProtocolUDP, corev1.ProtocolTCP
+		port := intstr.FromInt32(53)
+		spec.Egress = append(spec.Egress, networkingv1.NetworkPolicyEgressRule{
+			Ports: []networkingv1.NetworkPolicyPort{
+				{Protocol: &udp, Port: &port},
+				{Protocol: &tcp, Port: &port},
+			},
+		})
+	}
+
+	return spec
+}
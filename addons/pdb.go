@@ -0,0 +1,85 @@
+// Package addons provides prebuilt resources commonly managed alongside the workloads of an operator,
+// such as the PodDisruptionBudget of a Deployment or a default-deny NetworkPolicy. They are built with
+// ctrlfwk.ResourceBuilder, so any other builder option can be added before building them.
+package addons
+
+import (
+	"fmt"
+	"maps"
+
+	ctrlfwk "github.com/u-ctf/controller-fwk"
+
+	appsv1 "k8s.io/api/apps/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// NewPDBForDeployment builds the PodDisruptionBudget of the Deployment managed by deployment, named after it,
+// selecting its pods and carrying its labels. The PodDisruptionBudget is reconciled after the Deployment,
+// see ResourceBuilder.WithDependsOn, and is ready once observed by the disruption controller.
+//
+// As a PodDisruptionBudget would block the eviction of a single replica, it is skipped, or deleted,
+// while the Deployment has less than 2 replicas.
+//
+// Example:
+//
+//	deployment := ctrlfwk.NewResourceBuilder(ctx, &appsv1.Deployment{}).
+//		WithKeyFunc(...).
+//		Build()
+//	pdb := addons.NewPDBForDeployment(ctx, deployment, intstr.FromInt32(1)).
+//		WithUserIdentifier("pdb").
+//		Build()
+func NewPDBForDeployment[CustomResource client.Object, ContextType ctrlfwk.Context[CustomResource]](
+	ctx ContextType,
+	deployment *ctrlfwk.Resource[CustomResource, ContextType, *appsv1.Deployment],
+	minAvailable intstr.IntOrString,
+) *ctrlfwk.ResourceBuilder[CustomResource, ContextType, *policyv1.PodDisruptionBudget] {
+	return ctrlfwk.NewResourceBuilder(ctx, &policyv1.PodDisruptionBudget{}).
+		WithKeyFunc(func() types.NamespacedName {
+			obj, _, _ := deployment.ObjectMetaGenerator()
+			return client.ObjectKeyFromObject(obj)
+		}).
+		WithDependsOn(deployment.ID()).
+		WithSkipAndDeleteOnCondition(func() bool {
+			// The replicas are only known once the Deployment is reconciled
+			deploy := reconciledDeployment(deployment)
+			if deploy == nil {
+				return false
+			}
+			return deploy.Spec.Replicas != nil && *deploy.Spec.Replicas < 2
+		}).
+		WithMutator(func(pdb *policyv1.PodDisruptionBudget) error {
+			deploy := reconciledDeployment(deployment)
+			if deploy == nil || deploy.Spec.Selector == nil {
+				return fmt.Errorf("deployment %s must be reconciled before its PodDisruptionBudget", deployment.ID())
+			}
+
+			labels := pdb.GetLabels()
+			if labels == nil {
+				labels = make(map[string]string)
+			}
+			maps.Copy(labels, deploy.GetLabels())
+			pdb.SetLabels(labels)
+
+			pdb.Spec.MinAvailable = &minAvailable
+			pdb.Spec.MaxUnavailable = nil
+			pdb.Spec.Selector = deploy.Spec.Selector.DeepCopy()
+			return nil
+		}).
+		WithReadinessCondition(func(pdb *policyv1.PodDisruptionBudget) bool {
+			return pdb.Status.ObservedGeneration >= pdb.GetGeneration()
+		})
+}
+
+// reconciledDeployment returns the Deployment of the resource once it is reconciled, nil before.
+func reconciledDeployment[CustomResource client.Object, ContextType ctrlfwk.Context[CustomResource]](
+	deployment *ctrlfwk.Resource[CustomResource, ContextType, *appsv1.Deployment],
+) *appsv1.Deployment {
+	deploy, ok := deployment.Get().(*appsv1.Deployment)
+	if !ok || deploy == nil || deploy.GetResourceVersion() == "" {
+		return nil
+	}
+	return deploy
+}
@@ -0,0 +1,123 @@
+package ctrlfwk
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/uuid"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+)
+
+// admissionWebhookTimeout bounds the reviews sent to the admission webhooks, as they are sent while reconciling.
+const admissionWebhookTimeout = 10 * time.Second
+
+var admissionWebhookClient = &http.Client{Timeout: admissionWebhookTimeout}
+
+// admissionWebhookResource is implemented by the resources that can be built with WithAdmissionWebhookValidation.
+type admissionWebhookResource interface {
+	admissionWebhookURL() string
+}
+
+func getAdmissionWebhookURL(resource any) string {
+	if validated, ok := resource.(admissionWebhookResource); ok {
+		return validated.admissionWebhookURL()
+	}
+	return ""
+}
+
+// reviewAdmission sends obj to the admission webhook at url as a dry-run AdmissionReview, old being the live object
+// it updates, nil on creation. It returns a ValidationError when the webhook denies it.
+func reviewAdmission(ctx context.Context, c client.Client, url string, obj client.Object, old client.Object) error {
+	gvk, err := apiutil.GVKForObject(obj, c.Scheme())
+	if err != nil {
+		return err
+	}
+
+	dryRun := true
+	request := &admissionv1.AdmissionRequest{
+		UID:       uuid.NewUUID(),
+		Kind:      metav1.GroupVersionKind{Group: gvk.Group, Version: gvk.Version, Kind: gvk.Kind},
+		Name:      obj.GetName(),
+		Namespace: obj.GetNamespace(),
+		Operation: admissionv1.Create,
+		DryRun:    &dryRun,
+	}
+	request.RequestKind = &request.Kind
+	// The webhook may match on the resource, it is left out when the kind is not served
+	if mapping, err := c.RESTMapper().RESTMapping(gvk.GroupKind(), gvk.Version); err == nil {
+		request.Resource = metav1.GroupVersionResource{Group: mapping.Resource.Group, Version: mapping.Resource.Version, Resource: mapping.Resource.Resource}
+		request.RequestResource = &request.Resource
+	}
+
+	if request.Object.Raw, err = marshalAdmissionObject(obj, gvk.GroupVersion().String(), gvk.Kind); err != nil {
+		return err
+	}
+	if old != nil {
+		request.Operation = admissionv1.Update
+		if request.OldObject.Raw, err = marshalAdmissionObject(old, gvk.GroupVersion().String(), gvk.Kind); err != nil {
+			return err
+		}
+	}
+
+	body, err := json.Marshal(&admissionv1.AdmissionReview{
+		TypeMeta: metav1.TypeMeta{APIVersion: admissionv1.SchemeGroupVersion.String(), Kind: "AdmissionReview"},
+		Request:  request,
+	})
+	if err != nil {
+		return err
+	}
+
+	httpRequest, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	httpRequest.Header.Set("Content-Type", "application/json")
+
+	httpResponse, err := admissionWebhookClient.Do(httpRequest)
+	if err != nil {
+		return err
+	}
+	defer httpResponse.Body.Close()
+
+	if httpResponse.StatusCode != http.StatusOK {
+		message, _ := io.ReadAll(io.LimitReader(httpResponse.Body, 1024))
+		return fmt.Errorf("admission webhook returned %s: %s", httpResponse.Status, bytes.TrimSpace(message))
+	}
+
+	review := &admissionv1.AdmissionReview{}
+	if err := json.NewDecoder(httpResponse.Body).Decode(review); err != nil {
+		return fmt.Errorf("failed to decode admission review: %w", err)
+	}
+	if review.Response == nil || review.Response.UID != request.UID {
+		return fmt.Errorf("admission webhook did not answer the review %s", request.UID)
+	}
+
+	if !review.Response.Allowed {
+		reason := "denied by admission webhook"
+		if review.Response.Result != nil && review.Response.Result.Message != "" {
+			reason = fmt.Sprintf("denied by admission webhook: %s", review.Response.Result.Message)
+		}
+		return &ValidationError{Reason: reason}
+	}
+	return nil
+}
+
+// marshalAdmissionObject serializes obj with its apiVersion and kind, which typed objects don't hold.
+func marshalAdmissionObject(obj client.Object, apiVersion, kind string) ([]byte, error) {
+	raw, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+	if err != nil {
+		return nil, err
+	}
+	raw["apiVersion"] = apiVersion
+	raw["kind"] = kind
+	return json.Marshal(raw)
+}
@@ -0,0 +1,161 @@
+package ctrlfwk
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+const (
+	// AnnotationReconciliationAttempts is set on custom resources by the ReconciliationAttemptsMiddleware,
+	// it counts the reconciliations that failed or requeued since the last successful one.
+	AnnotationReconciliationAttempts = "ctrlfwk.com/reconciliation-attempts"
+
+	// AnnotationFirstFailureTime is set on custom resources by the ReconciliationAttemptsMiddleware,
+	// it holds the time of the first failed reconciliation since the last successful one, in RFC 3339.
+	AnnotationFirstFailureTime = "ctrlfwk.com/first-failure-time"
+)
+
+// ImplementsReconciliationAttempts gives access to the reconciliation attempts of the custom resource,
+// as tracked by the ReconciliationAttemptsMiddleware. Hooks can use it to escalate, e.g. alert after
+// 10 failed attempts or back off heavily after an hour of failures.
+type ImplementsReconciliationAttempts interface {
+	// GetReconciliationAttempts returns the number of reconciliations that failed or requeued
+	// since the last successful one, 0 if the last reconciliation succeeded.
+	GetReconciliationAttempts() int
+	// GetFirstFailureTime returns the time of the first failed reconciliation since the last successful one,
+	// nil if none failed.
+	GetFirstFailureTime() *metav1.Time
+}
+
+var _ ImplementsReconciliationAttempts = &CustomResource[client.Object]{}
+
+func (cr *CustomResource[K]) GetReconciliationAttempts() int {
+	return reconciliationAttempts(cr.GetCustomResource())
+}
+
+func (cr *CustomResource[K]) GetFirstFailureTime() *metav1.Time {
+	return firstFailureTime(cr.GetCustomResource())
+}
+
+func reconciliationAttempts(obj client.Object) int {
+	attempts, err := strconv.Atoi(GetAnnotation(obj, AnnotationReconciliationAttempts))
+	if err != nil || attempts < 0 {
+		return 0
+	}
+	return attempts
+}
+
+func firstFailureTime(obj client.Object) *metav1.Time {
+	value := GetAnnotation(obj, AnnotationFirstFailureTime)
+	if value == "" {
+		return nil
+	}
+	parsed, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return nil
+	}
+	return &metav1.Time{Time: parsed}
+}
+
+// ReconciliationAttemptsMiddleware tracks the reconciliation attempts of the custom resource in its
+// ctrlfwk.com/reconciliation-attempts and ctrlfwk.com/first-failure-time annotations, read back
+// through ctx.GetReconciliationAttempts and ctx.GetFirstFailureTime.
+//
+// Reconciliations returning an error or requeueing count as an attempt, errors also setting the first failure time.
+// Both annotations are removed once a reconciliation succeeds without requeueing.
+//
+// Updating the annotations triggers an update event for the custom resource, use a predicate like
+// predicate.GenerationChangedPredicate to keep it from causing an extra reconciliation.
+func ReconciliationAttemptsMiddleware[
+	ControllerResourceType ControllerCustomResource,
+	ContextType Context[ControllerResourceType],
+](
+	reconciler Reconciler[ControllerResourceType],
+) Middleware[ControllerResourceType, ContextType] {
+	return MiddlewareFunc[ControllerResourceType, ContextType](func(next ReconcileFunc[ControllerResourceType, ContextType]) ReconcileFunc[ControllerResourceType, ContextType] {
+		return func(ctx ContextType, req ctrl.Request) (ctrl.Result, error) {
+			result, err := next(ctx, req)
+
+			// The custom resource was not found, or is being deleted
+			if cr := ctx.GetCustomResource(); cr.GetName() == "" || IsFinalizing(cr) {
+				return result, err
+			}
+
+			requeued := result.RequeueAfter > 0 || result.Requeue
+			if attemptsErr := setReconciliationAttempts(ctx, reconciler, err != nil, requeued, time.Now()); client.IgnoreNotFound(attemptsErr) != nil {
+				if err == nil {
+					return result, errors.Wrap(attemptsErr, "failed to set reconciliation attempts")
+				}
+				logf.FromContext(ctx).Error(attemptsErr, "Failed to set reconciliation attempts")
+			}
+
+			return result, err
+		}
+	})
+}
+
+// setReconciliationAttempts updates the reconciliation attempts annotations of the custom resource
+// from the outcome of the reconciliation.
+func setReconciliationAttempts[
+	ControllerResourceType ControllerCustomResource,
+	ContextType Context[ControllerResourceType],
+](
+	ctx ContextType,
+	reconciler Reconciler[ControllerResourceType],
+	failed, requeued bool,
+	now time.Time,
+) error {
+	defer LockContext(ctx)()
+
+	cr := ctx.GetCustomResource()
+
+	attempts := ""
+	firstFailure := GetAnnotation(cr, AnnotationFirstFailureTime)
+	if failed || requeued {
+		attempts = strconv.Itoa(reconciliationAttempts(cr) + 1)
+		if failed && firstFailure == "" {
+			firstFailure = now.UTC().Format(time.RFC3339)
+		}
+	} else {
+		firstFailure = ""
+	}
+
+	if GetAnnotation(cr, AnnotationReconciliationAttempts) == attempts && GetAnnotation(cr, AnnotationFirstFailureTime) == firstFailure {
+		return nil
+	}
+
+	// Patch from the clean object so that pending changes of the custom resource are not sent along
+	cleanObject := ctx.GetCleanCustomResource()
+	modifiedObject := cleanObject.DeepCopyObject().(ControllerResourceType)
+	setOrRemoveAnnotation(modifiedObject, AnnotationReconciliationAttempts, attempts)
+	setOrRemoveAnnotation(modifiedObject, AnnotationFirstFailureTime, firstFailure)
+
+	if err := reconciler.Patch(ctx, modifiedObject, client.MergeFrom(cleanObject)); err != nil {
+		return err
+	}
+
+	setOrRemoveAnnotation(cr, AnnotationReconciliationAttempts, attempts)
+	setOrRemoveAnnotation(cr, AnnotationFirstFailureTime, firstFailure)
+
+	return nil
+}
+
+// setOrRemoveAnnotation sets the annotation on obj, removing it when value is empty.
+func setOrRemoveAnnotation(obj client.Object, key, value string) {
+	if value != "" {
+		SetAnnotation(obj, key, value)
+		return
+	}
+
+	annotations := obj.GetAnnotations()
+	if _, ok := annotations[key]; ok {
+		delete(annotations, key)
+		obj.SetAnnotations(annotations)
+	}
+}
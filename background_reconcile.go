@@ -0,0 +1,179 @@
+package ctrlfwk
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+)
+
+type backgroundRefreshKey struct{}
+
+// IsBackgroundRefresh tells whether the reconciliation ctx belongs to was triggered by the background reconcile
+// of a resource built with WithBackgroundReconcile, e.g. for its BeforeReconcile hook to renew a certificate.
+func IsBackgroundRefresh(ctx context.Context) bool {
+	refresh, _ := ctx.Value(backgroundRefreshKey{}).(bool)
+	return refresh
+}
+
+// BackgroundReconciler enqueues custom resources on a schedule, for the resources built with WithBackgroundReconcile.
+// It is implemented by WatchCache.
+type BackgroundReconciler interface {
+	// ScheduleBackgroundReconcile enqueues the custom resource cr every interval, on behalf of the resource resourceID.
+	// Scheduling an already scheduled resource only updates its interval.
+	ScheduleBackgroundReconcile(cr types.NamespacedName, resourceID string, interval time.Duration) error
+	// StopBackgroundReconcile stops the background reconciles of the custom resource cr.
+	StopBackgroundReconcile(cr types.NamespacedName)
+	// ConsumeBackgroundRefresh tells whether a background reconcile of the custom resource cr is pending,
+	// and marks it as handled.
+	ConsumeBackgroundRefresh(cr types.NamespacedName) bool
+}
+
+var _ BackgroundReconciler = &WatchCache{}
+
+type backgroundTicker struct {
+	interval time.Duration
+	ticker   *time.Ticker
+	stop     chan struct{}
+}
+
+type backgroundRefresher struct {
+	lock    sync.Mutex
+	events  chan event.GenericEvent
+	tickers map[types.NamespacedName]map[string]*backgroundTicker
+	pending map[types.NamespacedName]bool
+}
+
+func newBackgroundRefresher() *backgroundRefresher {
+	return &backgroundRefresher{
+		events:  make(chan event.GenericEvent),
+		tickers: make(map[types.NamespacedName]map[string]*backgroundTicker),
+		pending: make(map[types.NamespacedName]bool),
+	}
+}
+
+func (w *WatchCache) backgroundRefresher() *backgroundRefresher {
+	if w.background == nil {
+		w.background = newBackgroundRefresher()
+	}
+	return w.background
+}
+
+func (w *WatchCache) ScheduleBackgroundReconcile(cr types.NamespacedName, resourceID string, interval time.Duration) error {
+	refresher := w.backgroundRefresher()
+
+	// The ticks are delivered to the controller through a channel source, registered once
+	watchSource := WatchCacheKey("background/channel")
+	if !w.IsWatchingSource(watchSource) {
+		if w.controller == nil {
+			return errors.New("failed to schedule background reconcile: the controller is not set")
+		}
+		if err := w.controller.Watch(source.Channel(refresher.events, &handler.EnqueueRequestForObject{})); err != nil {
+			return errors.Wrap(err, "failed to watch background reconciles")
+		}
+		w.AddWatchSource(watchSource)
+	}
+
+	refresher.lock.Lock()
+	defer refresher.lock.Unlock()
+
+	if refresher.tickers[cr] == nil {
+		refresher.tickers[cr] = make(map[string]*backgroundTicker)
+	}
+	if scheduled, ok := refresher.tickers[cr][resourceID]; ok {
+		if scheduled.interval != interval {
+			scheduled.interval = interval
+			scheduled.ticker.Reset(interval)
+		}
+		return nil
+	}
+
+	scheduled := &backgroundTicker{interval: interval, ticker: time.NewTicker(interval), stop: make(chan struct{})}
+	refresher.tickers[cr][resourceID] = scheduled
+	go refresher.run(cr, scheduled)
+
+	return nil
+}
+
+// run enqueues cr on every tick until the ticker is stopped.
+func (r *backgroundRefresher) run(cr types.NamespacedName, scheduled *backgroundTicker) {
+	defer scheduled.ticker.Stop()
+
+	obj := &metav1.PartialObjectMetadata{ObjectMeta: metav1.ObjectMeta{Name: cr.Name, Namespace: cr.Namespace}}
+	for {
+		select {
+		case <-scheduled.stop:
+			return
+		case <-scheduled.ticker.C:
+		}
+
+		r.lock.Lock()
+		r.pending[cr] = true
+		r.lock.Unlock()
+
+		select {
+		case <-scheduled.stop:
+			return
+		case r.events <- event.GenericEvent{Object: obj}:
+		}
+	}
+}
+
+func (w *WatchCache) StopBackgroundReconcile(cr types.NamespacedName) {
+	refresher := w.backgroundRefresher()
+
+	refresher.lock.Lock()
+	defer refresher.lock.Unlock()
+
+	for _, scheduled := range refresher.tickers[cr] {
+		close(scheduled.stop)
+	}
+	delete(refresher.tickers, cr)
+	delete(refresher.pending, cr)
+}
+
+func (w *WatchCache) ConsumeBackgroundRefresh(cr types.NamespacedName) bool {
+	refresher := w.backgroundRefresher()
+
+	refresher.lock.Lock()
+	defer refresher.lock.Unlock()
+
+	pending := refresher.pending[cr]
+	delete(refresher.pending, cr)
+	return pending
+}
+
+// backgroundReconcileResource is implemented by the resources that can be built with WithBackgroundReconcile.
+type backgroundReconcileResource interface {
+	backgroundReconcileInterval() time.Duration
+}
+
+// scheduleBackgroundReconcile schedules the background reconciles of the resource, when it has an interval
+// and the reconciler can schedule them.
+func scheduleBackgroundReconcile(reconciler any, resource any, resourceID string, cr client.Object) error {
+	background, ok := resource.(backgroundReconcileResource)
+	if !ok || background.backgroundReconcileInterval() <= 0 {
+		return nil
+	}
+	scheduler, ok := reconciler.(BackgroundReconciler)
+	if !ok {
+		return nil
+	}
+	return scheduler.ScheduleBackgroundReconcile(client.ObjectKeyFromObject(cr), resourceID, background.backgroundReconcileInterval())
+}
+
+// markBackgroundRefresh flags the context when the reconciliation of cr was triggered by a background reconcile.
+func markBackgroundRefresh[K client.Object](ctx Context[K], reconciler any, cr types.NamespacedName) {
+	scheduler, ok := reconciler.(BackgroundReconciler)
+	if !ok || !scheduler.ConsumeBackgroundRefresh(cr) {
+		return
+	}
+	ctx.SetParentContext(context.WithValue(ctx.GetParentContext(), backgroundRefreshKey{}, true))
+}
@@ -0,0 +1,69 @@
+package ctrlfwk
+
+import (
+	"fmt"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// ConditionTypeChildrenReady is set on the custom resource by the ReconcileResourcesStep built with
+	// WithChildReadinessAggregation, it tells whether enough of its resources are ready.
+	ConditionTypeChildrenReady = "ChildrenReady"
+)
+
+// WithChildReadinessAggregation sets the ChildrenReady condition of the custom resource from the readiness of
+// the resources of the step, e.g. one Deployment per shard. The condition is True once the fraction of ready resources
+// reaches minReadyFraction, between 0 and 1, and its message lists the resources that are not ready.
+//
+// The resources skipped by their skip condition are not counted, and the resources waiting for the ones they
+// depend on are not ready. The readiness of the resources doesn't change the result of the step: a resource that
+// is not ready still requeues the reconciliation.
+//
+// Example:
+//
+//	ctrlfwk.NewReconcileResourcesStep(ctx, reconciler, ctrlfwk.WithChildReadinessAggregation(0.5))
+func WithChildReadinessAggregation(minReadyFraction float64) ReconcileResourcesOption {
+	return func(config *reconcileResourcesConfig) {
+		config.minReadyFraction = &minReadyFraction
+	}
+}
+
+// setChildrenReadyCondition reflects the readiness of the children of the custom resource on its status,
+// notReady holding the IDs of the children that are not ready.
+func setChildrenReadyCondition[
+	ControllerResourceType ControllerCustomResource,
+	ContextType Context[ControllerResourceType],
+](
+	ctx ContextType,
+	reconciler Reconciler[ControllerResourceType],
+	minReadyFraction float64,
+	total int,
+	notReady []string,
+) error {
+	cr := ctx.GetCustomResource()
+
+	ready := total - len(notReady)
+	fraction := 1.0
+	if total > 0 {
+		fraction = float64(ready) / float64(total)
+	}
+
+	condition := metav1.Condition{
+		Status:             metav1.ConditionTrue,
+		Reason:             "ChildrenReady",
+		Message:            fmt.Sprintf("%d/%d children ready", ready, total),
+		ObservedGeneration: cr.GetGeneration(),
+	}
+	if fraction < minReadyFraction {
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = "ChildrenNotReady"
+	}
+	if len(notReady) > 0 {
+		condition.Message += fmt.Sprintf(", not ready: %s", strings.Join(notReady, ", "))
+	}
+
+	_, err := applyCondition(ctx, reconciler, ConditionTypeChildrenReady, &condition)
+	return err
+}
@@ -0,0 +1,51 @@
+package ctrlfwk_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/go-logr/logr"
+	ctrlfwk "github.com/u-ctf/controller-fwk"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+func TestReconcileResourcesStep_ChildReadinessAggregation(t *testing.T) {
+	ctx, reconciler := newConditionsTest(t)
+
+	shard := func(name string, ready bool) ctrlfwk.GenericResource[*conditionsCR, conditionsContext] {
+		return ctrlfwk.NewResourceBuilder(ctx, &corev1.ConfigMap{}).
+			WithKey(types.NamespacedName{Name: name, Namespace: "default"}).
+			WithUserIdentifier(name).
+			WithReadinessCondition(func(*corev1.ConfigMap) bool { return ready }).
+			Build()
+	}
+	reconciler.resources = []ctrlfwk.GenericResource[*conditionsCR, conditionsContext]{
+		shard("shard-0", true),
+		shard("shard-1", true),
+		shard("shard-2", false),
+	}
+
+	for _, tc := range []struct {
+		minReadyFraction float64
+		status           metav1.ConditionStatus
+	}{
+		{minReadyFraction: 0.5, status: metav1.ConditionTrue},
+		{minReadyFraction: 1, status: metav1.ConditionFalse},
+	} {
+		step := ctrlfwk.NewReconcileResourcesStep(ctx, reconciler, ctrlfwk.WithChildReadinessAggregation(tc.minReadyFraction))
+		step.Step(ctx, logr.Discard(), ctrl.Request{})
+
+		condition := meta.FindStatusCondition(ctx.GetCustomResource().Status.Conditions, ctrlfwk.ConditionTypeChildrenReady)
+		if condition == nil || condition.Status != tc.status {
+			t.Fatalf("expected the ChildrenReady condition to be %s with a fraction of %v, got %v", tc.status, tc.minReadyFraction, condition)
+		}
+		if !strings.Contains(condition.Message, "2/3 children ready, not ready: shard-2") {
+			t.Fatalf("expected the message to list the children not ready, got %q", condition.Message)
+		}
+	}
+}
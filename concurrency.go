@@ -0,0 +1,39 @@
+package ctrlfwk
+
+import (
+	"context"
+	"sync"
+)
+
+type concurrentReconciliationKey struct{}
+
+// withConcurrentReconciliation marks the context as shared by concurrent goroutines, see LockContext.
+func withConcurrentReconciliation(ctx context.Context) context.Context {
+	return context.WithValue(ctx, concurrentReconciliationKey{}, &sync.Mutex{})
+}
+
+func isConcurrentReconciliation(ctx context.Context) bool {
+	_, ok := ctx.Value(concurrentReconciliationKey{}).(*sync.Mutex)
+	return ok
+}
+
+// LockContext locks the framework context while resources are reconciled concurrently, see WithConcurrency.
+// Hooks and mutators must hold the lock to modify the context or the custom resource.
+// It returns the function releasing the lock, and does nothing when resources are reconciled sequentially.
+//
+// Example:
+//
+//	.WithAfterReconcile(func(ctx MyContext, deployment *appsv1.Deployment) error {
+//		defer ctrlfwk.LockContext(ctx)()
+//		ctx.Data.ReadyReplicas += deployment.Status.ReadyReplicas
+//		return nil
+//	})
+func LockContext(ctx context.Context) func() {
+	mu, ok := ctx.Value(concurrentReconciliationKey{}).(*sync.Mutex)
+	if !ok {
+		return func() {}
+	}
+
+	mu.Lock()
+	return mu.Unlock
+}
@@ -0,0 +1,82 @@
+package ctrlfwk
+
+import (
+	"encoding/json"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// StructuredConditionMessagePrefix marks the condition messages holding a StructuredConditionMessage,
+// the JSON encoded message following it.
+const StructuredConditionMessagePrefix = "ctrlfwk.com/v1+json:"
+
+// StructuredConditionMessage is a machine-readable condition message, so that tools and UIs can show
+// rich error details instead of parsing free-form strings.
+type StructuredConditionMessage struct {
+	// Summary is the human-readable description of the condition.
+	Summary string `json:"summary"`
+	// Details holds additional information, e.g. the resource or the field at fault.
+	Details map[string]string `json:"details,omitempty"`
+	// ErrorCode identifies the error, e.g. "E1234".
+	ErrorCode string `json:"errorCode,omitempty"`
+}
+
+// String encodes the message as stored in the Message field of a condition.
+func (m StructuredConditionMessage) String() string {
+	// Encoding a struct of strings can't fail
+	data, _ := json.Marshal(m)
+	return StructuredConditionMessagePrefix + string(data)
+}
+
+// StructuredConditionDetails describes a condition whose message is a StructuredConditionMessage,
+// see SetStructuredCondition.
+type StructuredConditionDetails struct {
+	Type               string
+	Status             metav1.ConditionStatus
+	Reason             string
+	ObservedGeneration int64
+	Message            StructuredConditionMessage
+}
+
+// SetStructuredCondition sets the condition described by c in conditions, its message being encoded as JSON
+// behind StructuredConditionMessagePrefix. It returns true if the conditions were changed.
+//
+// Example:
+//
+//	ctrlfwk.SetStructuredCondition(&cr.Status.Conditions, ctrlfwk.StructuredConditionDetails{
+//		Type:   "DatabaseReady",
+//		Status: metav1.ConditionFalse,
+//		Reason: "ConnectionFailed",
+//		Message: ctrlfwk.StructuredConditionMessage{
+//			Summary:   "the database can't be reached",
+//			Details:   map[string]string{"host": "db.default.svc"},
+//			ErrorCode: "E1001",
+//		},
+//	})
+func SetStructuredCondition(conditions *[]metav1.Condition, c StructuredConditionDetails) bool {
+	return meta.SetStatusCondition(conditions, metav1.Condition{
+		Type:               c.Type,
+		Status:             c.Status,
+		Reason:             c.Reason,
+		ObservedGeneration: c.ObservedGeneration,
+		Message:            c.Message.String(),
+	})
+}
+
+// ParseStructuredConditionMessage reads back a message set by SetStructuredCondition.
+// It returns false for free-form messages, or when the JSON following the prefix is invalid.
+func ParseStructuredConditionMessage(message string) (*StructuredConditionMessage, bool) {
+	data, ok := strings.CutPrefix(message, StructuredConditionMessagePrefix)
+	if !ok {
+		return nil, false
+	}
+
+	var out StructuredConditionMessage
+	if err := json.Unmarshal([]byte(data), &out); err != nil {
+		return nil, false
+	}
+
+	return &out, true
+}
@@ -7,5 +7,18 @@ const (
 	// when using resources that support pausing.
 	// It can also be added to CRs to pause the whole reconciliation if the NotPausedPredicate is used.
 	// You can set the value to anything, so you can use it to document who/what paused the reconciliation.
+	// Paused custom resources get a Paused condition holding the value, removed once the label is removed.
 	LabelReconciliationPaused = "ctrlfwk.com/pause"
+
+	// LabelTrackingOwnerUID is set on every resource reconciled by the framework, it holds the UID of the custom resource
+	// declaring it. It is used by the prune step to find resources that are not declared anymore.
+	LabelTrackingOwnerUID = "ctrlfwk.com/owner-uid"
+
+	// LabelTrackingGVK is set on every resource reconciled by the framework, it holds the GroupVersionKind of the resource
+	// encoded as "Kind.version.group". It allows pruning resources whose type is not registered in the scheme anymore.
+	LabelTrackingGVK = "ctrlfwk.com/gvk"
+
+	// AnnotationTrackedGVKs is set on custom resources by the prune step, it lists the GroupVersionKinds
+	// that may hold resources tracked for this custom resource.
+	AnnotationTrackedGVKs = "ctrlfwk.com/tracked-gvks"
 )
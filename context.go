@@ -3,20 +3,71 @@ package ctrlfwk
 import (
 	"context"
 
+	"github.com/go-logr/logr"
+	"k8s.io/apimachinery/pkg/runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
 
 	corev1 "k8s.io/api/core/v1"
 )
 
+// Context is the context passed to the steps, hooks and mutators of a reconciliation.
+// K is the type of the custom resource being reconciled.
+//
+// Context is meant to be created using NewContext or NewContextWithData: new framework features add methods to it,
+// which breaks types implementing it from scratch. Custom contexts should embed the Context returned by NewContext
+// to get the methods added over time, see ContextWithData. Since v1.1.0, Context requires GetParentContext,
+// SetParentContext and the methods of ImplementsLogger, ImplementsResourceMetadata, ImplementsRequeueRequest,
+// ImplementsReconcileReport, ImplementsReconciliationAttempts and ImplementsControllerReference.
+// The optional interfaces, e.g. ImplementsStatusTransaction, are not promoted through an embedded Context,
+// forward them to it like ContextWithData does.
 type Context[K client.Object] interface {
 	context.Context
 
+	// GetParentContext returns the context.Context wrapped by the framework context.
+	GetParentContext() context.Context
+	// SetParentContext replaces the context.Context wrapped by the framework context,
+	// e.g. to add a deadline to the reconciliation.
+	SetParentContext(ctx context.Context)
+
+	ImplementsLogger
 	ImplementsCustomResource[K]
+	ImplementsResourceMetadata
+	ImplementsRequeueRequest
+	ImplementsReconcileReport
+	ImplementsReconciliationAttempts
+	ImplementsControllerReference
 }
 
 type baseContext[K client.Object] struct {
 	context.Context
+	logger *logr.Logger
+	// scheme is the scheme of the reconciler, used to set owner references
+	scheme *runtime.Scheme
+
 	CustomResource[K]
+	ResourceMetadata
+	RequeueRequest
+	ReconcileReporting
+}
+
+func (c *baseContext[K]) GetParentContext() context.Context {
+	return c.Context
+}
+
+func (c *baseContext[K]) SetParentContext(ctx context.Context) {
+	c.Context = ctx
+}
+
+func (c *baseContext[K]) GetLogger() logr.Logger {
+	if c.logger == nil {
+		return logf.FromContext(c.Context)
+	}
+	return *c.logger
+}
+
+func (c *baseContext[K]) SetLogger(logger logr.Logger) {
+	c.logger = &logger
 }
 
 // NewContext creates a new Context for the given reconciler and base context.
@@ -29,10 +80,19 @@ type baseContext[K client.Object] struct {
 func NewContext[K client.Object](ctx context.Context, reconciler Reconciler[K]) Context[K] {
 	return &baseContext[K]{
 		Context:        ctx,
+		scheme:         reconcilerScheme(reconciler),
 		CustomResource: CustomResource[K]{},
 	}
 }
 
+// reconcilerScheme returns the scheme of the reconciler, nil without reconciler.
+func reconcilerScheme[K client.Object](reconciler Reconciler[K]) *runtime.Scheme {
+	if reconciler == nil {
+		return nil
+	}
+	return reconciler.Scheme()
+}
+
 var _ Context[*corev1.Secret] = &baseContext[*corev1.Secret]{}
 
 // ContextWithData is a context that holds additional data of type D along with the base context.
@@ -53,7 +113,33 @@ type ContextWithData[K client.Object, D any] struct {
 //		context := ctrlfwk.NewContextWithData(ctx, reconciler, &MyDataType{})
 func NewContextWithData[K client.Object, D any](ctx context.Context, reconciler Reconciler[K], data D) *ContextWithData[K, D] {
 	return &ContextWithData[K, D]{
-		Context: &baseContext[K]{Context: ctx},
+		Context: &baseContext[K]{Context: ctx, scheme: reconcilerScheme(reconciler)},
 		Data:    data,
 	}
 }
+
+// BeginStatusTransaction forwards to the wrapped context, see ImplementsStatusTransaction.
+func (c *ContextWithData[K, D]) BeginStatusTransaction() {
+	if transaction, ok := c.Context.(ImplementsStatusTransaction); ok {
+		transaction.BeginStatusTransaction()
+	}
+}
+
+// InStatusTransaction forwards to the wrapped context, see ImplementsStatusTransaction.
+func (c *ContextWithData[K, D]) InStatusTransaction() bool {
+	return inStatusTransaction(c.Context)
+}
+
+// EndStatusTransaction forwards to the wrapped context, see ImplementsStatusTransaction.
+func (c *ContextWithData[K, D]) EndStatusTransaction() {
+	endStatusTransaction(c.Context)
+}
+
+// RollbackStatusTransaction forwards to the wrapped context, see ImplementsStatusTransaction.
+func (c *ContextWithData[K, D]) RollbackStatusTransaction() {
+	if transaction, ok := c.Context.(ImplementsStatusTransaction); ok {
+		transaction.RollbackStatusTransaction()
+	}
+}
+
+var _ ImplementsStatusTransaction = &ContextWithData[*corev1.Secret, struct{}]{}
@@ -3,6 +3,7 @@ package ctrlfwk
 import (
 	"reflect"
 
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
@@ -12,12 +13,25 @@ type ImplementsCustomResource[K client.Object] interface {
 	SetCustomResource(key K)
 }
 
+// ImplementsStatusTransaction allows buffering the status patches of the custom resource,
+// see BeginStatusTransaction. It is implemented by the contexts of the framework, custom contexts that
+// don't implement it get their status patches sent right away, BeginStatusTransaction doing nothing.
+type ImplementsStatusTransaction interface {
+	BeginStatusTransaction()
+	InStatusTransaction() bool
+	EndStatusTransaction()
+	RollbackStatusTransaction()
+}
+
 type CustomResource[K client.Object] struct {
 	cleanObject K
 	CR          K
 
 	cleanObjectInitialized bool
 	crInitialized          bool
+
+	inStatusTransaction bool
+	transactionSnapshot K
 }
 
 // GetCleanCustomResource gives back the resource that was stored previously unedited of any changes that the resource may have went through,
@@ -57,3 +71,61 @@ func (cr *CustomResource[K]) SetCustomResource(key K) {
 	cr.crInitialized = true
 	cr.cleanObjectInitialized = true
 }
+
+// BeginStatusTransaction starts buffering the status patches of the custom resource.
+// Starting a transaction while one is already in progress does nothing.
+func (cr *CustomResource[K]) BeginStatusTransaction() {
+	if cr.inStatusTransaction {
+		return
+	}
+
+	cr.transactionSnapshot = cr.GetCustomResource().DeepCopyObject().(K)
+	cr.inStatusTransaction = true
+}
+
+// InStatusTransaction tells whether the status patches are currently buffered.
+func (cr *CustomResource[K]) InStatusTransaction() bool {
+	return cr.inStatusTransaction
+}
+
+// EndStatusTransaction stops buffering the status patches, keeping the in-memory status as is.
+func (cr *CustomResource[K]) EndStatusTransaction() {
+	var zero K
+	cr.inStatusTransaction = false
+	cr.transactionSnapshot = zero
+}
+
+// RollbackStatusTransaction stops buffering the status patches and restores the in-memory status
+// to its state at the beginning of the transaction.
+func (cr *CustomResource[K]) RollbackStatusTransaction() {
+	if !cr.inStatusTransaction {
+		return
+	}
+
+	restoreStatus(cr.GetCustomResource(), cr.transactionSnapshot)
+	cr.EndStatusTransaction()
+}
+
+// restoreStatus copies the status of from into to, both must be of the same type.
+func restoreStatus(to client.Object, from client.Object) {
+	if toUnstructured, ok := to.(*unstructured.Unstructured); ok {
+		fromUnstructured := from.(*unstructured.Unstructured)
+
+		status, found := fromUnstructured.Object["status"]
+		if !found {
+			delete(toUnstructured.Object, "status")
+			return
+		}
+		toUnstructured.Object["status"] = status
+		return
+	}
+
+	toValue := reflect.ValueOf(to).Elem()
+	fromValue := reflect.ValueOf(from).Elem()
+
+	statusField := toValue.FieldByName("Status")
+	if !statusField.IsValid() || !statusField.CanSet() {
+		return
+	}
+	statusField.Set(fromValue.FieldByName("Status"))
+}
@@ -69,3 +69,45 @@ func TestNewCustomResource_CleanIsResetable(t *testing.T) {
 		t.Fatalf("expected clean resource name to be 'test-name2', got '%s'", cleanObject.GetName())
 	}
 }
+
+func TestNewCustomResource_StatusTransactionRollback(t *testing.T) {
+	cr := &ctrlfwk.CustomResource[*unstructured.Unstructured]{}
+
+	var resource unstructured.Unstructured
+	resource.Object = map[string]any{"status": map[string]any{"phase": "Pending"}}
+	cr.SetCustomResource(&resource)
+
+	cr.BeginStatusTransaction()
+	if !cr.InStatusTransaction() {
+		t.Fatalf("expected a status transaction to be in progress")
+	}
+
+	_ = unstructured.SetNestedField(cr.GetCustomResource().Object, "Running", "status", "phase")
+	cr.RollbackStatusTransaction()
+
+	if cr.InStatusTransaction() {
+		t.Fatalf("expected the status transaction to be over")
+	}
+
+	phase, _, _ := unstructured.NestedString(cr.GetCustomResource().Object, "status", "phase")
+	if phase != "Pending" {
+		t.Fatalf("expected status phase to be 'Pending', got '%s'", phase)
+	}
+}
+
+func TestNewCustomResource_StatusTransactionEnd(t *testing.T) {
+	cr := &ctrlfwk.CustomResource[*unstructured.Unstructured]{}
+
+	var resource unstructured.Unstructured
+	resource.Object = map[string]any{"status": map[string]any{"phase": "Pending"}}
+	cr.SetCustomResource(&resource)
+
+	cr.BeginStatusTransaction()
+	_ = unstructured.SetNestedField(cr.GetCustomResource().Object, "Running", "status", "phase")
+	cr.EndStatusTransaction()
+
+	phase, _, _ := unstructured.NestedString(cr.GetCustomResource().Object, "status", "phase")
+	if phase != "Running" {
+		t.Fatalf("expected status phase to be 'Running', got '%s'", phase)
+	}
+}
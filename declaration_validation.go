@@ -0,0 +1,232 @@
+package ctrlfwk
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// ErrSkipDeclarationValidation can be returned by GetDependencies or GetResources to skip their validation
+// by ValidateDeclarations, e.g. when the declarations depend on state only known while reconciling.
+// Use IsValidatingDeclarations to tell a validation from a reconciliation.
+var ErrSkipDeclarationValidation = stderrors.New("declaration validation skipped")
+
+// DeclarationValidationOption configures ValidateDeclarations.
+type DeclarationValidationOption func(config *declarationValidationConfig)
+
+type declarationValidationConfig struct {
+	skipDependencies bool
+	skipResources    bool
+	skipClusterCheck bool
+}
+
+// WithoutDependencyValidation doesn't validate the dependencies of the reconciler.
+func WithoutDependencyValidation() DeclarationValidationOption {
+	return func(config *declarationValidationConfig) {
+		config.skipDependencies = true
+	}
+}
+
+// WithoutResourceValidation doesn't validate the resources of the reconciler.
+func WithoutResourceValidation() DeclarationValidationOption {
+	return func(config *declarationValidationConfig) {
+		config.skipResources = true
+	}
+}
+
+// WithoutClusterChecks only runs the static checks, without reading the namespaces of the declarations
+// nor asking the API server whether the GroupVersionKinds of the untyped declarations are served.
+func WithoutClusterChecks() DeclarationValidationOption {
+	return func(config *declarationValidationConfig) {
+		config.skipClusterCheck = true
+	}
+}
+
+// declarationValidationKey marks the contexts passed to the declarations by ValidateDeclarations.
+type declarationValidationKey struct{}
+
+// IsValidatingDeclarations tells whether the declarations are built by ValidateDeclarations rather than
+// during a reconciliation, so that GetDependencies and GetResources can leave out what needs runtime state.
+func IsValidatingDeclarations[K client.Object](ctx Context[K]) bool {
+	validating, _ := ctx.Value(declarationValidationKey{}).(bool)
+	return validating
+}
+
+// ValidateDeclarations builds the dependencies and resources the reconciler declares for the custom resource of ctx,
+// and checks them as the reconciliation would: their names must be set and valid object names, namespaced kinds need
+// a valid namespace which must exist, and the GroupVersionKinds of untyped declarations must be served.
+// It is meant to be called from a validating webhook, to reject a custom resource before it is reconciled.
+//
+// The errors are rooted at the ID of each declaration, e.g. dependencies[Secret,default/creds].metadata.name.
+// Resources skipped for the custom resource are not validated.
+//
+// Example:
+//
+//	err := ctrlfwk.NewWebhookBuilder(&v1.MyResource{}).
+//		WithFieldValidator(func(cr *v1.MyResource) field.ErrorList {
+//			ctx := ctrlfwk.NewContext(context.Background(), reconciler)
+//			ctx.SetCustomResource(cr)
+//			return ctrlfwk.ValidateDeclarations(ctx, reconciler)
+//		}).
+//		Complete(mgr)
+func ValidateDeclarations[
+	ControllerResourceType ControllerCustomResource,
+	ContextType Context[ControllerResourceType],
+](
+	ctx ContextType,
+	reconciler Reconciler[ControllerResourceType],
+	opts ...DeclarationValidationOption,
+) field.ErrorList {
+	config := &declarationValidationConfig{}
+	for _, opt := range opts {
+		opt(config)
+	}
+
+	parent := ctx.GetParentContext()
+	ctx.SetParentContext(context.WithValue(parent, declarationValidationKey{}, true))
+	defer ctx.SetParentContext(parent)
+
+	cr := ctx.GetCustomResource()
+	req := ctrl.Request{NamespacedName: client.ObjectKeyFromObject(cr)}
+	validator := &declarationValidator[ControllerResourceType]{reconciler: reconciler, config: config}
+
+	var errs field.ErrorList
+
+	if withDependencies, ok := reconciler.(ReconcilerWithDependencies[ControllerResourceType, ContextType]); ok && !config.skipDependencies {
+		path := field.NewPath("dependencies")
+		dependencies, err := withDependencies.GetDependencies(ctx, req)
+		switch {
+		case stderrors.Is(err, ErrSkipDeclarationValidation):
+		case err != nil:
+			errs = append(errs, field.InternalError(path, fmt.Errorf("failed to get dependencies: %w", err)))
+		default:
+			for _, dependency := range dependencies {
+				errs = append(errs, validator.validate(ctx, path.Key(dependency.ID()), dependency, dependency.New(), dependency.Key())...)
+			}
+		}
+	}
+
+	if withResources, ok := reconciler.(ReconcilerWithResources[ControllerResourceType, ContextType]); ok && !config.skipResources {
+		path := field.NewPath("resources")
+		resources, err := withResources.GetResources(ctx, req)
+		switch {
+		case stderrors.Is(err, ErrSkipDeclarationValidation):
+		case err != nil:
+			errs = append(errs, field.InternalError(path, fmt.Errorf("failed to get resources: %w", err)))
+		default:
+			for _, resource := range resources {
+				resourcePath := path.Key(resource.ID())
+				obj, skip, err := generateResourceObject(ctx, resource)
+				if err != nil {
+					errs = append(errs, field.InternalError(resourcePath, err))
+					continue
+				}
+				if skip || obj == nil {
+					continue
+				}
+				errs = append(errs, validator.validate(ctx, resourcePath, resource, obj, client.ObjectKeyFromObject(obj))...)
+			}
+		}
+	}
+
+	return errs
+}
+
+type declarationValidator[ControllerResourceType ControllerCustomResource] struct {
+	reconciler Reconciler[ControllerResourceType]
+	config     *declarationValidationConfig
+	// namespaces caches whether the namespaces exist, so that each is read once
+	namespaces map[string]bool
+}
+
+// untypedDeclaration is implemented by the untyped dependencies and resources.
+type untypedDeclaration interface {
+	preferredGVK() schema.GroupVersionKind
+	gvkCandidates() []schema.GroupVersionKind
+}
+
+// validate checks the key of the object declared by declaration.
+func (v *declarationValidator[ControllerResourceType]) validate(ctx Context[ControllerResourceType], path *field.Path, declaration any, obj client.Object, key types.NamespacedName) field.ErrorList {
+	var errs field.ErrorList
+
+	if untyped, ok := declaration.(untypedDeclaration); ok && !v.config.skipClusterCheck {
+		candidates := untyped.gvkCandidates()
+		if len(candidates) == 0 {
+			candidates = []schema.GroupVersionKind{untyped.preferredGVK()}
+		}
+		gvk, err := NegotiateGVK(v.reconciler.RESTMapper(), candidates...)
+		if err != nil {
+			// The scope of a kind that is not served is unknown, its namespace can't be checked
+			return append(errs, field.Invalid(path.Child("apiVersion"), candidates[0].GroupVersion().String(), fmt.Sprintf("%s is not served", qualifiedKind(candidates[0]))))
+		}
+		// The scope is resolved with the version that would be negotiated
+		obj = obj.DeepCopyObject().(client.Object)
+		obj.GetObjectKind().SetGroupVersionKind(gvk)
+	}
+
+	namePath := path.Child("metadata", "name")
+	if key.Name == "" {
+		errs = append(errs, field.Required(namePath, "the name must be set"))
+	} else {
+		for _, msg := range validation.IsDNS1123Subdomain(key.Name) {
+			errs = append(errs, field.Invalid(namePath, key.Name, msg))
+		}
+	}
+
+	namespaced, err := v.reconciler.IsObjectNamespaced(obj)
+	if err != nil || !namespaced {
+		// Cluster scoped objects have no namespace, and the scope of unknown kinds is not checked
+		return errs
+	}
+
+	namespacePath := path.Child("metadata", "namespace")
+	if key.Namespace == "" {
+		return append(errs, field.Required(namespacePath, "the namespace must be set"))
+	}
+	if msgs := validation.IsDNS1123Label(key.Namespace); len(msgs) > 0 {
+		for _, msg := range msgs {
+			errs = append(errs, field.Invalid(namespacePath, key.Namespace, msg))
+		}
+		return errs
+	}
+
+	if err := v.namespaceExists(ctx, namespacePath, key.Namespace); err != nil {
+		errs = append(errs, err)
+	}
+
+	return errs
+}
+
+// namespaceExists reports the namespaces that don't exist, unless the cluster checks are disabled.
+func (v *declarationValidator[ControllerResourceType]) namespaceExists(ctx Context[ControllerResourceType], path *field.Path, namespace string) *field.Error {
+	if v.config.skipClusterCheck {
+		return nil
+	}
+	if v.namespaces == nil {
+		v.namespaces = make(map[string]bool)
+	}
+
+	exists, ok := v.namespaces[namespace]
+	if !ok {
+		err := v.reconciler.Get(ctx, types.NamespacedName{Name: namespace}, &corev1.Namespace{})
+		if err != nil && !apierrors.IsNotFound(err) {
+			return field.InternalError(path, fmt.Errorf("failed to get namespace %s: %w", namespace, err))
+		}
+		exists = err == nil
+		v.namespaces[namespace] = exists
+	}
+
+	if !exists {
+		return field.NotFound(path, namespace)
+	}
+	return nil
+}
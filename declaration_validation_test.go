@@ -0,0 +1,124 @@
+package ctrlfwk_test
+
+import (
+	"context"
+	"testing"
+
+	ctrlfwk "github.com/u-ctf/controller-fwk"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+type declarationsReconciler struct {
+	client.Client
+	dependencies func(ctx conditionsContext) ([]ctrlfwk.GenericDependency[*conditionsCR, conditionsContext], error)
+	resources    func(ctx conditionsContext) ([]ctrlfwk.GenericResource[*conditionsCR, conditionsContext], error)
+}
+
+func (*declarationsReconciler) For(*conditionsCR) {}
+
+func (r *declarationsReconciler) GetDependencies(ctx conditionsContext, _ ctrl.Request) ([]ctrlfwk.GenericDependency[*conditionsCR, conditionsContext], error) {
+	return r.dependencies(ctx)
+}
+
+func (r *declarationsReconciler) GetResources(ctx conditionsContext, _ ctrl.Request) ([]ctrlfwk.GenericResource[*conditionsCR, conditionsContext], error) {
+	return r.resources(ctx)
+}
+
+func newDeclarationsTest(t *testing.T) (conditionsContext, *declarationsReconciler) {
+	t.Helper()
+
+	scheme := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(scheme); err != nil {
+		t.Fatalf("failed to build scheme: %v", err)
+	}
+	scheme.AddKnownTypes(schema.GroupVersion{Group: "test.ctrlfwk.com", Version: "v1"}, &conditionsCR{})
+
+	mapper := meta.NewDefaultRESTMapper(nil)
+	mapper.Add(corev1.SchemeGroupVersion.WithKind("Secret"), meta.RESTScopeNamespace)
+	mapper.Add(corev1.SchemeGroupVersion.WithKind("ConfigMap"), meta.RESTScopeNamespace)
+	mapper.Add(corev1.SchemeGroupVersion.WithKind("Namespace"), meta.RESTScopeRoot)
+
+	reconciler := &declarationsReconciler{
+		Client: fake.NewClientBuilder().WithScheme(scheme).WithRESTMapper(mapper).WithObjects(&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "default"}}).Build(),
+	}
+
+	ctx := ctrlfwk.NewContext(context.Background(), reconciler)
+	ctx.SetCustomResource(&conditionsCR{ObjectMeta: metav1.ObjectMeta{Name: "cr", Namespace: "default"}})
+
+	return ctx, reconciler
+}
+
+func TestValidateDeclarations(t *testing.T) {
+	ctx, reconciler := newDeclarationsTest(t)
+
+	reconciler.dependencies = func(ctx conditionsContext) ([]ctrlfwk.GenericDependency[*conditionsCR, conditionsContext], error) {
+		if !ctrlfwk.IsValidatingDeclarations(ctx) {
+			t.Error("expected the declarations to know they are validated")
+		}
+		return []ctrlfwk.GenericDependency[*conditionsCR, conditionsContext]{
+			ctrlfwk.NewDependencyBuilder(ctx, &corev1.Secret{}).WithName("creds").WithNamespace("default").Build(),
+			ctrlfwk.NewDependencyBuilder(ctx, &corev1.Secret{}).WithName("creds").Build(),
+			ctrlfwk.NewDependencyBuilder(ctx, &corev1.ConfigMap{}).WithName("Not_A_Name").WithNamespace("missing").Build(),
+			ctrlfwk.NewUntypedDependencyBuilder(ctx, schema.GroupVersionKind{Group: "example.com", Version: "v1", Kind: "Widget"}).WithName("widget").WithNamespace("default").Build(),
+		}, nil
+	}
+	reconciler.resources = func(ctx conditionsContext) ([]ctrlfwk.GenericResource[*conditionsCR, conditionsContext], error) {
+		return []ctrlfwk.GenericResource[*conditionsCR, conditionsContext]{
+			ctrlfwk.NewResourceBuilder(ctx, &corev1.ConfigMap{}).WithKey(types.NamespacedName{Namespace: "default"}).Build(),
+			ctrlfwk.NewResourceBuilder(ctx, &corev1.Namespace{}).WithKey(types.NamespacedName{Name: "tenant"}).Build(),
+			ctrlfwk.NewResourceBuilder(ctx, &corev1.ConfigMap{}).WithKey(types.NamespacedName{Namespace: "default"}).WithSkipAndDeleteOnCondition(func() bool { return true }).Build(),
+		}, nil
+	}
+
+	errs := ctrlfwk.ValidateDeclarations(ctx, reconciler)
+
+	expected := map[string]field.ErrorType{
+		"dependencies[Secret,/creds].metadata.namespace":                field.ErrorTypeRequired,
+		"dependencies[ConfigMap,missing/Not_A_Name].metadata.name":      field.ErrorTypeInvalid,
+		"dependencies[ConfigMap,missing/Not_A_Name].metadata.namespace": field.ErrorTypeNotFound,
+		"dependencies[example.com/v1/Widget,default/widget].apiVersion": field.ErrorTypeInvalid,
+		"resources[ConfigMap,default/].metadata.name":                   field.ErrorTypeRequired,
+	}
+	if len(errs) != len(expected) {
+		t.Fatalf("expected %d errors, got %v", len(expected), errs)
+	}
+	for _, err := range errs {
+		if errType, ok := expected[err.Field]; !ok || errType != err.Type {
+			t.Errorf("unexpected error %v", err)
+		}
+	}
+	if ctrlfwk.IsValidatingDeclarations(ctx) {
+		t.Error("expected the context to be restored")
+	}
+}
+
+func TestValidateDeclarations_Skipped(t *testing.T) {
+	ctx, reconciler := newDeclarationsTest(t)
+
+	reconciler.dependencies = func(conditionsContext) ([]ctrlfwk.GenericDependency[*conditionsCR, conditionsContext], error) {
+		return nil, ctrlfwk.ErrSkipDeclarationValidation
+	}
+	reconciler.resources = func(ctx conditionsContext) ([]ctrlfwk.GenericResource[*conditionsCR, conditionsContext], error) {
+		return []ctrlfwk.GenericResource[*conditionsCR, conditionsContext]{
+			ctrlfwk.NewResourceBuilder(ctx, &corev1.ConfigMap{}).WithKey(types.NamespacedName{Name: "config", Namespace: "missing"}).Build(),
+		}, nil
+	}
+
+	if errs := ctrlfwk.ValidateDeclarations(ctx, reconciler, ctrlfwk.WithoutClusterChecks()); len(errs) != 0 {
+		t.Fatalf("expected no errors, got %v", errs)
+	}
+	if errs := ctrlfwk.ValidateDeclarations(ctx, reconciler); len(errs) != 1 || errs[0].Type != field.ErrorTypeNotFound {
+		t.Fatalf("expected the missing namespace to be reported, got %v", errs)
+	}
+}
@@ -3,6 +3,7 @@ package ctrlfwk
 import (
 	"fmt"
 	"reflect"
+	"time"
 
 	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -12,13 +13,16 @@ type GenericDependency[CustomResourceType client.Object, ContextType Context[Cus
 	ID() string
 	New() client.Object
 	Key() types.NamespacedName
-	Set(obj client.Object)
+	// Set stores the resolved obj into the outputs of the dependency, it fails when an output can't hold it,
+	// e.g. when the func given to WithOutputFunc returns nil.
+	Set(obj client.Object) error
 	Get() client.Object
 	ShouldWaitForReady() bool
 	ShouldAddManagedByAnnotation() bool
 	IsReady() bool
 	IsOptional() bool
 	Kind() string
+	APIVersionConstraint() string
 
 	// Hooks
 	BeforeReconcile(ctx ContextType) error
@@ -28,18 +32,29 @@ type GenericDependency[CustomResourceType client.Object, ContextType Context[Cus
 var _ GenericDependency[client.Object, Context[client.Object]] = &Dependency[client.Object, Context[client.Object], client.Object]{}
 
 type Dependency[CustomResourceType client.Object, ContextType Context[CustomResourceType], DependencyType client.Object] struct {
-	userIdentifier string
-	isReadyF       func(obj DependencyType) bool
-	output         DependencyType
-	isOptional     bool
-	waitForReady   bool
-	addManagedBy   bool
-	name           string
-	namespace      string
+	userIdentifier          string
+	isReadyF                func(obj DependencyType) bool
+	output                  DependencyType
+	outputF                 func() DependencyType
+	hasOutputF              bool
+	isOptional              bool
+	waitForReady            bool
+	addManagedBy            bool
+	name                    string
+	namespace               string
+	clientF                 func(ctx ContextType) (client.Client, error)
+	conditionType           string
+	noAvailabilityCondition bool
+	readinessTTL            time.Duration
+	fallbacks               []string
+	backoff                 DependencyBackoff
+	breaker                 *circuitBreaker
 
 	// Hooks
 	beforeReconcileF func(ctx ContextType) error
 	afterReconcileF  func(ctx ContextType, resource DependencyType) error
+	transformF       func(resolved DependencyType) (any, error)
+	transformed      any
 }
 
 func (c *Dependency[CustomResourceType, ContextType, DependencyType]) New() client.Object {
@@ -50,7 +65,7 @@ func (c *Dependency[CustomResourceType, ContextType, DependencyType]) Kind() str
 	return reflect.TypeOf(c.output).Elem().Name()
 }
 
-func (c *Dependency[CustomResourceType, ContextType, DependencyType]) Set(obj client.Object) {
+func (c *Dependency[CustomResourceType, ContextType, DependencyType]) Set(obj client.Object) error {
 	if reflect.TypeOf(c.output) == reflect.TypeOf(obj) {
 		if reflect.ValueOf(c.output).IsNil() {
 			c.output = reflect.New(reflect.TypeOf(c.output).Elem()).Interface().(DependencyType)
@@ -58,6 +73,14 @@ func (c *Dependency[CustomResourceType, ContextType, DependencyType]) Set(obj cl
 
 		reflect.ValueOf(c.output).Elem().Set(reflect.ValueOf(obj).Elem())
 	}
+
+	if c.hasOutputF {
+		if err := setOutputFromFunc(c.outputF, obj); err != nil {
+			return fmt.Errorf("failed to set output of %s: %w", c.ID(), err)
+		}
+	}
+
+	return nil
 }
 
 func (c *Dependency[CustomResourceType, ContextType, DependencyType]) Get() client.Object {
@@ -113,6 +136,59 @@ func (c *Dependency[CustomResourceType, ContextType, DependencyType]) AfterRecon
 	return nil
 }
 
+// Transformed returns the value the resolved dependency was last transformed into, see DependencyBuilder.WithTransform.
+func (c *Dependency[CustomResourceType, ContextType, DependencyType]) Transformed() any {
+	return c.transformed
+}
+
+func (c *Dependency[CustomResourceType, ContextType, DependencyType]) transform() error {
+	if c.transformF == nil {
+		return nil
+	}
+	transformed, err := c.transformF(c.output)
+	if err != nil {
+		return err
+	}
+	c.transformed = transformed
+	return nil
+}
+
 func (c *Dependency[CustomResourceType, ContextType, DependencyType]) ShouldAddManagedByAnnotation() bool {
 	return c.addManagedBy
 }
+
+func (c *Dependency[CustomResourceType, ContextType, DependencyType]) APIVersionConstraint() string {
+	return ""
+}
+
+func (c *Dependency[CustomResourceType, ContextType, DependencyType]) remoteClient(ctx ContextType) (client.Client, bool, error) {
+	if c.clientF == nil {
+		return nil, false, nil
+	}
+	remote, err := c.clientF(ctx)
+	return remote, true, err
+}
+
+func (c *Dependency[CustomResourceType, ContextType, DependencyType]) reportedConditionType() string {
+	return c.conditionType
+}
+
+func (c *Dependency[CustomResourceType, ContextType, DependencyType]) availabilityConditionDisabled() bool {
+	return c.noAvailabilityCondition
+}
+
+func (c *Dependency[CustomResourceType, ContextType, DependencyType]) readinessExpiry() time.Duration {
+	return c.readinessTTL
+}
+
+func (c *Dependency[CustomResourceType, ContextType, DependencyType]) fallbackNamespaces() []string {
+	return c.fallbacks
+}
+
+func (c *Dependency[CustomResourceType, ContextType, DependencyType]) notFoundBackoff() DependencyBackoff {
+	return c.backoff
+}
+
+func (c *Dependency[CustomResourceType, ContextType, DependencyType]) circuitBreaker() *circuitBreaker {
+	return c.breaker
+}
@@ -0,0 +1,94 @@
+package ctrlfwk
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// dependencyBackoffTTL is how long the backoff of a dependency is kept without the dependency being checked again,
+// so that the state of deleted custom resources does not pile up.
+const dependencyBackoffTTL = time.Hour
+
+// DependencyBackoff configures how the reconciliation of a custom resource is requeued while one of its dependencies
+// is not found, see DependencyBuilder.WithNotFoundBackoff. The zero values use the defaults.
+type DependencyBackoff struct {
+	// Initial is the delay of the first requeue. Defaults to 5 seconds.
+	Initial time.Duration
+	// Max caps the delay, which doubles on every requeue. Defaults to 5 minutes.
+	Max time.Duration
+}
+
+type dependencyBackoffState struct {
+	attempts  int
+	checkedAt time.Time
+}
+
+// dependencyBackoffs holds the consecutive reconciliations that did not find each dependency,
+// the state being reset once the dependency is found.
+var dependencyBackoffs = struct {
+	lock   sync.Mutex
+	states map[resourceStateKey]dependencyBackoffState
+}{states: make(map[resourceStateKey]dependencyBackoffState)}
+
+// next returns the delay of the next check of the dependency, counting the attempt.
+func (b DependencyBackoff) next(key resourceStateKey, now time.Time) time.Duration {
+	initial, maxDelay := b.Initial, b.Max
+	if initial <= 0 {
+		initial = 5 * time.Second
+	}
+	if maxDelay <= 0 {
+		maxDelay = 5 * time.Minute
+	}
+
+	dependencyBackoffs.lock.Lock()
+	defer dependencyBackoffs.lock.Unlock()
+
+	for stateKey, state := range dependencyBackoffs.states {
+		if now.Sub(state.checkedAt) > dependencyBackoffTTL {
+			delete(dependencyBackoffs.states, stateKey)
+		}
+	}
+
+	attempt := dependencyBackoffs.states[key].attempts
+	dependencyBackoffs.states[key] = dependencyBackoffState{attempts: attempt + 1, checkedAt: now}
+
+	delay := initial << min(attempt, 32)
+	if delay <= 0 || delay > maxDelay {
+		delay = maxDelay
+	}
+	return delay
+}
+
+// resetDependencyBackoff resets the backoff of a dependency once it is found.
+func resetDependencyBackoff(key resourceStateKey) {
+	dependencyBackoffs.lock.Lock()
+	defer dependencyBackoffs.lock.Unlock()
+
+	delete(dependencyBackoffs.states, key)
+}
+
+// backoffDependency is implemented by the dependencies that can be built with WithNotFoundBackoff.
+type backoffDependency interface {
+	notFoundBackoff() DependencyBackoff
+}
+
+// getDependencyBackoff returns the backoff of the checks of the dependency while it is not found.
+func getDependencyBackoff(dependency any) DependencyBackoff {
+	if backoff, ok := dependency.(backoffDependency); ok {
+		return backoff.notFoundBackoff()
+	}
+	return DependencyBackoff{}
+}
+
+// approximateDuration renders d with a single unit, e.g. 2m, for the messages of the conditions.
+func approximateDuration(d time.Duration) string {
+	switch {
+	case d >= time.Hour:
+		return fmt.Sprintf("%dh", d.Round(time.Hour)/time.Hour)
+	case d >= time.Minute:
+		return fmt.Sprintf("%dm", d.Round(time.Minute)/time.Minute)
+	default:
+		return fmt.Sprintf("%ds", d.Round(time.Second)/time.Second)
+	}
+}
@@ -1,6 +1,8 @@
 package ctrlfwk
 
 import (
+	"time"
+
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
@@ -88,6 +90,31 @@ func (b *DependencyBuilder[CustomResourceType, ContextType, DependencyType]) Wit
 	return b
 }
 
+// WithOutputFunc specifies where to store the resolved dependency resource, the function is evaluated
+// at resolution time instead of when the builder runs.
+//
+// This is useful when the output is a field of your context's data structure that is only
+// allocated later on, for example in a BeforeReconcile hook. The function must return an
+// allocated object, the resolution fails with an error otherwise.
+//
+// Example:
+//
+//	dep := NewDependencyBuilder(ctx, &corev1.Secret{}).
+//		WithName("database-creds").
+//		WithBeforeReconcile(func(ctx MyContext) error {
+//			ctx.Data.DatabaseSecret = &corev1.Secret{}
+//			return nil
+//		}).
+//		WithOutputFunc(func() *corev1.Secret {
+//			return ctx.Data.DatabaseSecret
+//		}).
+//		Build()
+func (b *DependencyBuilder[CustomResourceType, ContextType, DependencyType]) WithOutputFunc(f func() DependencyType) *DependencyBuilder[CustomResourceType, ContextType, DependencyType] {
+	b.dependency.outputF = f
+	b.dependency.hasOutputF = true
+	return b
+}
+
 // WithIsReadyFunc defines custom logic to determine if the dependency is ready for use.
 //
 // The provided function is called with the resolved dependency resource and should
@@ -118,7 +145,13 @@ func (b *DependencyBuilder[CustomResourceType, ContextType, DependencyType]) Wit
 //
 // When set to true, the dependency resolution will continue even if this dependency
 // is missing or not ready. When false (default), missing or unready dependencies
-// will cause reconciliation to requeue and wait.
+// will cause reconciliation to requeue and wait. Mutators and hooks can tell whether an optional
+// dependency is available with ctx.DependencyState.
+//
+// While an optional dependency is missing, the custom resource has an informational condition
+// "<ID>Available" set to False with the reason OptionalDependencyMissing, see OptionalDependencyConditionType.
+// It is removed once the dependency exists and never affects the readiness of the custom resource.
+// It can be disabled with WithAvailabilityCondition.
 //
 // Use optional dependencies for:
 //   - Feature flags or optional configurations
@@ -137,6 +170,18 @@ func (b *DependencyBuilder[CustomResourceType, ContextType, DependencyType]) Wit
 	return b
 }
 
+// WithAvailabilityCondition configures whether the custom resource has a condition telling that the optional
+// dependency is missing, see WithOptional. It is enabled by default.
+//
+// Example:
+//
+//	.WithOptional(true).
+//	WithAvailabilityCondition(false) // The dependency is routinely absent, don't report it
+func (b *DependencyBuilder[CustomResourceType, ContextType, DependencyType]) WithAvailabilityCondition(enabled bool) *DependencyBuilder[CustomResourceType, ContextType, DependencyType] {
+	b.dependency.noAvailabilityCondition = !enabled
+	return b
+}
+
 // WithName specifies the name of the Kubernetes resource to depend on.
 //
 // This is the metadata.name field of the target resource. The name is required
@@ -254,6 +299,56 @@ func (b *DependencyBuilder[CustomResourceType, ContextType, DependencyType]) Wit
 	return b
 }
 
+// WithTransform converts the resolved dependency, e.g. parses the data of a ConfigMap into a typed struct,
+// once it is resolved and after the WithAfterReconcile hook ran. The converted value can be read with
+// Dependency.Transformed, see WithTypedOutput to store it in a typed variable instead.
+// The transform is not called when the dependency could not be resolved, e.g. an optional dependency is missing,
+// the previous value being kept. If the function returns an error, the reconciliation will fail.
+//
+// Example:
+//
+//	.WithTransform(func(cm *corev1.ConfigMap) (any, error) {
+//		return strconv.Atoi(cm.Data["replicas"])
+//	})
+func (b *DependencyBuilder[CustomResourceType, ContextType, DependencyType]) WithTransform(f func(resolved DependencyType) (any, error)) *DependencyBuilder[CustomResourceType, ContextType, DependencyType] {
+	b.dependency.transformF = f
+	return b
+}
+
+// WithTypedOutput converts the resolved dependency with transform and stores the result at target,
+// for later steps to use it, see DependencyBuilder.WithTransform.
+//
+// Example:
+//
+//	dep := ctrlfwk.WithTypedOutput(
+//		ctrlfwk.NewDependencyBuilder(ctx, &corev1.ConfigMap{}).WithName("settings"),
+//		&ctx.Data.Settings,
+//		func(cm *corev1.ConfigMap) (Settings, error) {
+//			var settings Settings
+//			err := yaml.Unmarshal([]byte(cm.Data["settings.yaml"]), &settings)
+//			return settings, err
+//		},
+//	).Build()
+func WithTypedOutput[
+	Transformed any,
+	CustomResourceType client.Object,
+	ContextType Context[CustomResourceType],
+	DependencyType client.Object,
+](
+	b *DependencyBuilder[CustomResourceType, ContextType, DependencyType],
+	target *Transformed,
+	transform func(resolved DependencyType) (Transformed, error),
+) *DependencyBuilder[CustomResourceType, ContextType, DependencyType] {
+	return b.WithTransform(func(resolved DependencyType) (any, error) {
+		transformed, err := transform(resolved)
+		if err != nil {
+			return nil, err
+		}
+		*target = transformed
+		return transformed, nil
+	})
+}
+
 // WithReadinessCondition is an alias for WithIsReadyFunc that defines custom readiness logic.
 //
 // This method provides the same functionality as WithIsReadyFunc but with a more
@@ -276,6 +371,7 @@ func (b *DependencyBuilder[CustomResourceType, ContextType, DependencyType]) Wit
 //
 // Also, when enabled, if the reconciler has a Watcher configured, it will automatically
 // watch for changes to this dependency resource and trigger reconciliations accordingly.
+// Reconcilers without a Watcher can register the same watch on their controller builder with ManagedByWatch.
 //
 // This is not enabled by default to avoid unnecessary annotations on resources.
 //
@@ -291,6 +387,97 @@ func (b *DependencyBuilder[CustomResourceType, ContextType, DependencyType]) Wit
 	return b
 }
 
+// WithConditionReporting reflects the state of the dependency on a condition of the custom resource.
+// The condition is set to False with the reason "<Kind>NotFound" while the dependency does not exist,
+// and "<Kind>NotReady" while it is not ready when waiting for it, see WithWaitForReady.
+// It is removed once the dependency is ready. Events are emitted when the state changes
+// if the reconciler is an event recorder.
+//
+// Example:
+//
+//	.WithConditionReporting("SecretFound") // SecretNotFound, SecretNotReady
+func (b *DependencyBuilder[CustomResourceType, ContextType, DependencyType]) WithConditionReporting(conditionType string) *DependencyBuilder[CustomResourceType, ContextType, DependencyType] {
+	b.dependency.conditionType = conditionType
+	return b
+}
+
+// WithReadinessTTL bounds how long the readiness of the dependency is trusted. Once the dependency is resolved,
+// the custom resource is reconciled again after ttl even if the dependency did not change,
+// so that readiness functions relying on time, such as a heartbeat being recent, can lapse.
+//
+// Example:
+//
+//	.WithIsReadyFunc(func(agent *v1.Agent) bool {
+//		return time.Since(agent.Status.LastHeartbeat.Time) < time.Minute
+//	}).
+//	WithReadinessTTL(time.Minute)
+func (b *DependencyBuilder[CustomResourceType, ContextType, DependencyType]) WithReadinessTTL(ttl time.Duration) *DependencyBuilder[CustomResourceType, ContextType, DependencyType] {
+	b.dependency.readinessTTL = ttl
+	return b
+}
+
+// WithNamespaceFallback searches the dependency in the given namespaces, in order, when it is not found
+// in its own namespace, e.g. to default to a shared "platform" namespace. The first object found is used.
+// The DependencyFoundInFallback condition is set on the custom resource while the dependency is resolved
+// from a fallback namespace.
+//
+// Example:
+//
+//	.WithName("tls").
+//	WithNamespace(cr.Namespace).
+//	WithNamespaceFallback("platform")
+func (b *DependencyBuilder[CustomResourceType, ContextType, DependencyType]) WithNamespaceFallback(namespaces ...string) *DependencyBuilder[CustomResourceType, ContextType, DependencyType] {
+	b.dependency.fallbacks = append(b.dependency.fallbacks, namespaces...)
+	return b
+}
+
+// WithNotFoundBackoff configures how the custom resource is requeued while the dependency is not found,
+// e.g. a Secret the user did not create yet. The first check happens after backoff.Initial, the delay doubling
+// on every requeue up to backoff.Max, and being reset once the dependency is found, e.g. when its watch triggers
+// a reconciliation. The delay of the next check is shown in the message of the condition set by WithConditionReporting.
+//
+// Without it, missing dependencies are checked after 5 seconds, up to every 5 minutes.
+//
+// Example:
+//
+//	.WithNotFoundBackoff(ctrlfwk.DependencyBackoff{Initial: time.Second, Max: 10 * time.Minute})
+func (b *DependencyBuilder[CustomResourceType, ContextType, DependencyType]) WithNotFoundBackoff(backoff DependencyBackoff) *DependencyBuilder[CustomResourceType, ContextType, DependencyType] {
+	b.dependency.backoff = backoff
+	return b
+}
+
+// WithCircuitBreaker stops resolving the dependency for resetAfter once it failed to resolve threshold times in a row,
+// e.g. because it is missing or an external system it relies on is down, instead of requeueing constantly.
+// While the circuit is open, the ExternalDependencyCircuitOpen condition is set on the custom resource and the
+// reconciliation is requeued once it closes. The circuit then closes if the dependency resolves, or opens again
+// on the first failure. Use ForceCloseCircuit to close it manually.
+//
+// Example:
+//
+//	.WithCircuitBreaker(5, 10*time.Minute)
+func (b *DependencyBuilder[CustomResourceType, ContextType, DependencyType]) WithCircuitBreaker(threshold int, resetAfter time.Duration) *DependencyBuilder[CustomResourceType, ContextType, DependencyType] {
+	b.dependency.breaker = &circuitBreaker{threshold: threshold, resetAfter: resetAfter}
+	return b
+}
+
+// WithClient resolves the dependency with the given client instead of the reconciler,
+// e.g. to read it from a remote cluster. See WithClientFunc.
+func (b *DependencyBuilder[CustomResourceType, ContextType, DependencyType]) WithClient(c client.Client) *DependencyBuilder[CustomResourceType, ContextType, DependencyType] {
+	return b.WithClientFunc(func(ContextType) (client.Client, error) { return c, nil })
+}
+
+// WithClientFunc resolves the dependency with the client returned by f, called on each reconciliation,
+// e.g. to build the client of a remote cluster from a kubeconfig secret. Callers should cache the clients they build.
+//
+// Dependencies of a remote cluster can't be watched, WithAddManagedByAnnotation still annotates them
+// but changes to them are only noticed on the next reconciliation.
+//
+// When f fails, the RemoteClusterUnavailable condition is set on the custom resource and the reconciliation is requeued.
+func (b *DependencyBuilder[CustomResourceType, ContextType, DependencyType]) WithClientFunc(f func(ctx ContextType) (client.Client, error)) *DependencyBuilder[CustomResourceType, ContextType, DependencyType] {
+	b.dependency.clientF = f
+	return b
+}
+
 // Build constructs and returns the final Dependency instance with all configured options.
 //
 // This method finalizes the builder pattern and creates the dependency that can be
@@ -0,0 +1,142 @@
+package ctrlfwk
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// ConditionTypeExternalDependencyCircuitOpen is set on the custom resource while the circuit of one of its
+	// dependencies is open, see DependencyBuilder.WithCircuitBreaker.
+	ConditionTypeExternalDependencyCircuitOpen = "ExternalDependencyCircuitOpen"
+)
+
+// circuitBreaker configures the circuit breaker of a dependency, see DependencyBuilder.WithCircuitBreaker.
+type circuitBreaker struct {
+	threshold  int
+	resetAfter time.Duration
+}
+
+type circuitState struct {
+	failures  int
+	openUntil time.Time
+}
+
+// dependencyCircuits holds the consecutive failures to resolve each dependency,
+// the state being reset once the dependency is resolved.
+var dependencyCircuits = struct {
+	lock   sync.Mutex
+	states map[resourceStateKey]circuitState
+}{states: make(map[resourceStateKey]circuitState)}
+
+// open returns how long the circuit of the dependency stays open, 0 if it is closed.
+func (b circuitBreaker) open(key resourceStateKey, now time.Time) time.Duration {
+	dependencyCircuits.lock.Lock()
+	defer dependencyCircuits.lock.Unlock()
+
+	openUntil := dependencyCircuits.states[key].openUntil
+	if !now.Before(openUntil) {
+		return 0
+	}
+	return openUntil.Sub(now)
+}
+
+// recordFailure counts a failure to resolve the dependency, returning the consecutive failures and how long
+// its circuit is opened for, 0 if the threshold is not reached. Once the circuit closes again, a single failure opens it again.
+func (b circuitBreaker) recordFailure(key resourceStateKey, now time.Time) (int, time.Duration) {
+	dependencyCircuits.lock.Lock()
+	defer dependencyCircuits.lock.Unlock()
+
+	state := dependencyCircuits.states[key]
+	state.failures++
+	if state.failures >= b.threshold {
+		state.openUntil = now.Add(b.resetAfter)
+	}
+	dependencyCircuits.states[key] = state
+
+	if state.openUntil.IsZero() {
+		return state.failures, 0
+	}
+	return state.failures, b.resetAfter
+}
+
+// closeCircuit resets the circuit of a dependency.
+func closeCircuit(key resourceStateKey) {
+	dependencyCircuits.lock.Lock()
+	defer dependencyCircuits.lock.Unlock()
+
+	delete(dependencyCircuits.states, key)
+}
+
+// ForceCloseCircuit closes the circuit of the dependency with the given ID of the custom resource of ctx,
+// so that it is resolved on the next reconciliation instead of waiting for the circuit to close by itself,
+// e.g. once the external system it depends on is known to be back. See DependencyBuilder.WithCircuitBreaker.
+func ForceCloseCircuit[K client.Object](ctx Context[K], dependencyID string) {
+	closeCircuit(newResourceStateKey(ctx.GetCustomResource(), dependencyID))
+}
+
+// circuitBreakingDependency is implemented by the dependencies that can be built with WithCircuitBreaker.
+type circuitBreakingDependency interface {
+	circuitBreaker() *circuitBreaker
+}
+
+// getCircuitBreaker returns the circuit breaker of the dependency, nil if it has none.
+func getCircuitBreaker(dependency any) *circuitBreaker {
+	if breaking, ok := dependency.(circuitBreakingDependency); ok {
+		return breaking.circuitBreaker()
+	}
+	return nil
+}
+
+// setDependencyCircuitOpenCondition reflects the circuit of a dependency being open on the custom resource status,
+// openFor being 0 once it is closed. The condition is shared by the dependencies, the last one opened being reported,
+// and is only removed by the dependency it reports.
+func setDependencyCircuitOpenCondition[
+	ControllerResourceType ControllerCustomResource,
+	ContextType Context[ControllerResourceType],
+](
+	ctx ContextType,
+	reconciler Reconciler[ControllerResourceType],
+	id string,
+	failures int,
+	openFor time.Duration,
+) error {
+	defer LockContext(ctx)()
+
+	cr := ctx.GetCustomResource()
+
+	existing, err := getStatusCondition(cr, ConditionTypeExternalDependencyCircuitOpen)
+	if err != nil {
+		return err
+	}
+
+	prefix := fmt.Sprintf("dependency %s ", id)
+
+	if openFor <= 0 {
+		if existing == nil || !strings.HasPrefix(existing.Message, prefix) {
+			return nil
+		}
+		_, err := applyCondition(ctx, reconciler, ConditionTypeExternalDependencyCircuitOpen, nil)
+		return err
+	}
+
+	message := fmt.Sprintf("%sfailed to resolve %d times in a row, next attempt in ~%s", prefix, failures, approximateDuration(openFor))
+	changed, err := applyCondition(ctx, reconciler, ConditionTypeExternalDependencyCircuitOpen, &metav1.Condition{
+		Status:             metav1.ConditionTrue,
+		Reason:             "ResolutionFailing",
+		Message:            message,
+		ObservedGeneration: cr.GetGeneration(),
+	})
+	if changed {
+		if recorder, ok := reconciler.(record.EventRecorder); ok {
+			recorder.Event(cr, "Warning", "CircuitOpen", message)
+		}
+	}
+	return err
+}
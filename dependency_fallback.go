@@ -0,0 +1,75 @@
+package ctrlfwk
+
+import (
+	"fmt"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+)
+
+const (
+	// ConditionTypeDependencyFoundInFallback is set on the custom resource when a dependency is not found
+	// in its namespace but in one of its fallback namespaces, see DependencyBuilder.WithNamespaceFallback.
+	ConditionTypeDependencyFoundInFallback = "DependencyFoundInFallback"
+)
+
+// namespaceFallbackDependency is implemented by the dependencies that can be built with WithNamespaceFallback.
+type namespaceFallbackDependency interface {
+	fallbackNamespaces() []string
+}
+
+// getFallbackNamespaces returns the namespaces searched for the dependency when it is not in its own namespace.
+func getFallbackNamespaces(dependency any) []string {
+	if fallback, ok := dependency.(namespaceFallbackDependency); ok {
+		return fallback.fallbackNamespaces()
+	}
+	return nil
+}
+
+// setDependencyFoundInFallbackCondition reflects a dependency found in a fallback namespace on the custom resource status,
+// foundIn being empty when it was found in its own namespace. The condition is removed once the dependency it reports
+// is found in its own namespace again.
+func setDependencyFoundInFallbackCondition[
+	ControllerResourceType ControllerCustomResource,
+	ContextType Context[ControllerResourceType],
+](
+	ctx ContextType,
+	reconciler Reconciler[ControllerResourceType],
+	id string,
+	namespace string,
+	foundIn string,
+) error {
+	defer LockContext(ctx)()
+
+	cr := ctx.GetCustomResource()
+
+	existing, err := getStatusCondition(cr, ConditionTypeDependencyFoundInFallback)
+	if err != nil {
+		return err
+	}
+
+	prefix := fmt.Sprintf("dependency %s was found in ", id)
+
+	if foundIn == "" {
+		if existing == nil || !strings.HasPrefix(existing.Message, prefix) {
+			return nil
+		}
+		_, err := applyCondition(ctx, reconciler, ConditionTypeDependencyFoundInFallback, nil)
+		return err
+	}
+
+	message := fmt.Sprintf("%snamespace %s, it is missing from namespace %s", prefix, foundIn, namespace)
+	changed, err := applyCondition(ctx, reconciler, ConditionTypeDependencyFoundInFallback, &metav1.Condition{
+		Status:             metav1.ConditionTrue,
+		Reason:             "FoundInFallbackNamespace",
+		Message:            message,
+		ObservedGeneration: cr.GetGeneration(),
+	})
+	if changed {
+		if recorder, ok := reconciler.(record.EventRecorder); ok {
+			recorder.Event(cr, "Warning", "FoundInFallbackNamespace", message)
+		}
+	}
+	return err
+}
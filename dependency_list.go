@@ -0,0 +1,120 @@
+package ctrlfwk
+
+import (
+	"fmt"
+	"reflect"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+)
+
+// ListDependency is a dependency on a set of objects matching a label selector, like the Pods of
+// a workload managed by someone else. It is resolved by NewResolveListDependencyStep.
+type ListDependency[CustomResourceType client.Object, ContextType Context[CustomResourceType], DependencyType client.Object] struct {
+	userIdentifier string
+	prototype      DependencyType
+	namespace      string
+	selector       labels.Selector
+	minCount       int
+	eachReadyF     func(obj DependencyType) bool
+	aggregateF     func(items []DependencyType) bool
+	output         *[]DependencyType
+
+	items []DependencyType
+}
+
+func (c *ListDependency[CustomResourceType, ContextType, DependencyType]) Kind() string {
+	return reflect.TypeOf(c.prototype).Elem().Name()
+}
+
+func (c *ListDependency[CustomResourceType, ContextType, DependencyType]) ID() string {
+	if c.userIdentifier != "" {
+		return c.userIdentifier
+	}
+	return fmt.Sprintf("%v,%v,%v", c.Kind(), c.namespace, c.selector)
+}
+
+// List lists the objects matching the namespace and selector of the dependency, and stores them in the output.
+func (c *ListDependency[CustomResourceType, ContextType, DependencyType]) List(ctx ContextType, reader client.Reader, scheme *runtime.Scheme) error {
+	gvk, err := apiutil.GVKForObject(c.prototype, scheme)
+	if err != nil {
+		return err
+	}
+
+	listObj, err := scheme.New(gvk.GroupVersion().WithKind(gvk.Kind + "List"))
+	if err != nil {
+		return err
+	}
+	list, ok := listObj.(client.ObjectList)
+	if !ok {
+		return fmt.Errorf("%T is not a list", listObj)
+	}
+
+	opts := []client.ListOption{client.InNamespace(c.namespace)}
+	if c.selector != nil {
+		opts = append(opts, client.MatchingLabelsSelector{Selector: c.selector})
+	}
+	if err := reader.List(ctx, list, opts...); err != nil {
+		return err
+	}
+
+	objects, err := meta.ExtractList(list)
+	if err != nil {
+		return err
+	}
+
+	c.items = make([]DependencyType, 0, len(objects))
+	for _, obj := range objects {
+		item, ok := obj.(DependencyType)
+		if !ok {
+			return fmt.Errorf("expected %T in list, got %T", c.prototype, obj)
+		}
+		c.items = append(c.items, item)
+	}
+
+	if c.output != nil {
+		*c.output = c.items
+	}
+
+	return nil
+}
+
+// Items returns the objects matched by the last List call.
+func (c *ListDependency[CustomResourceType, ContextType, DependencyType]) Items() []DependencyType {
+	return c.items
+}
+
+// ReadyCount returns the number of matched objects that are ready.
+// Without an each ready function, every matched object is ready.
+func (c *ListDependency[CustomResourceType, ContextType, DependencyType]) ReadyCount() int {
+	if c.eachReadyF == nil {
+		return len(c.items)
+	}
+
+	count := 0
+	for _, item := range c.items {
+		if c.eachReadyF(item) {
+			count++
+		}
+	}
+	return count
+}
+
+// IsReady tells whether at least the minimum count of objects are ready, and the aggregate ready function agrees.
+func (c *ListDependency[CustomResourceType, ContextType, DependencyType]) IsReady() bool {
+	if c.ReadyCount() < c.minCount {
+		return false
+	}
+	if c.aggregateF != nil {
+		return c.aggregateF(c.items)
+	}
+	return true
+}
+
+// Status describes the matched and ready objects, it is used in the condition message.
+func (c *ListDependency[CustomResourceType, ContextType, DependencyType]) Status() string {
+	return fmt.Sprintf("%d matched, %d ready, %d required", len(c.items), c.ReadyCount(), c.minCount)
+}
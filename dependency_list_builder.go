@@ -0,0 +1,90 @@
+package ctrlfwk
+
+import (
+	"k8s.io/apimachinery/pkg/labels"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// ListDependencyBuilder provides a fluent builder pattern for creating ListDependency instances.
+//
+// Type parameters:
+//   - CustomResourceType: The custom resource that owns this dependency
+//   - ContextType: The context type containing the custom resource and additional data
+//   - DependencyType: The Kubernetes resource type of the listed objects
+type ListDependencyBuilder[CustomResourceType client.Object, ContextType Context[CustomResourceType], DependencyType client.Object] struct {
+	dependency *ListDependency[CustomResourceType, ContextType, DependencyType]
+}
+
+// NewListDependencyBuilder creates a new ListDependencyBuilder for a dependency on a set of objects.
+//
+// The list type of the objects (e.g. PodList for Pod) must be registered in the scheme of the reconciler.
+//
+// Example:
+//
+//	// Wait for at least 3 ready Pods of an externally managed workload
+//	dep := NewListDependencyBuilder(ctx, &corev1.Pod{}).
+//		WithListOptions(ctx.GetCustomResource().Namespace, labels.SelectorFromSet(labels.Set{"app": "database"})).
+//		WithMinCount(3).
+//		WithEachReadyFunc(func(pod *corev1.Pod) bool {
+//			return pod.Status.Phase == corev1.PodRunning
+//		}).
+//		WithOutput(&ctx.Data.DatabasePods).
+//		Build()
+func NewListDependencyBuilder[
+	CustomResourceType client.Object,
+	ContextType Context[CustomResourceType],
+	DependencyType client.Object,
+](
+	ctx ContextType,
+	prototype DependencyType,
+) *ListDependencyBuilder[CustomResourceType, ContextType, DependencyType] {
+	return &ListDependencyBuilder[CustomResourceType, ContextType, DependencyType]{
+		dependency: &ListDependency[CustomResourceType, ContextType, DependencyType]{
+			prototype: prototype,
+		},
+	}
+}
+
+// WithListOptions specifies the namespace and the label selector of the listed objects.
+// An empty namespace lists the objects of every namespace, a nil selector matches every object.
+func (b *ListDependencyBuilder[CustomResourceType, ContextType, DependencyType]) WithListOptions(namespace string, selector labels.Selector) *ListDependencyBuilder[CustomResourceType, ContextType, DependencyType] {
+	b.dependency.namespace = namespace
+	b.dependency.selector = selector
+	return b
+}
+
+// WithMinCount specifies the minimum number of ready objects for the dependency to be ready.
+func (b *ListDependencyBuilder[CustomResourceType, ContextType, DependencyType]) WithMinCount(n int) *ListDependencyBuilder[CustomResourceType, ContextType, DependencyType] {
+	b.dependency.minCount = n
+	return b
+}
+
+// WithEachReadyFunc specifies how to tell whether a single object is ready,
+// only ready objects count towards the minimum count.
+func (b *ListDependencyBuilder[CustomResourceType, ContextType, DependencyType]) WithEachReadyFunc(f func(obj DependencyType) bool) *ListDependencyBuilder[CustomResourceType, ContextType, DependencyType] {
+	b.dependency.eachReadyF = f
+	return b
+}
+
+// WithAggregateReadyFunc specifies an additional readiness check, called with every matched object.
+func (b *ListDependencyBuilder[CustomResourceType, ContextType, DependencyType]) WithAggregateReadyFunc(f func(items []DependencyType) bool) *ListDependencyBuilder[CustomResourceType, ContextType, DependencyType] {
+	b.dependency.aggregateF = f
+	return b
+}
+
+// WithOutput specifies where to store the matched objects.
+func (b *ListDependencyBuilder[CustomResourceType, ContextType, DependencyType]) WithOutput(into *[]DependencyType) *ListDependencyBuilder[CustomResourceType, ContextType, DependencyType] {
+	b.dependency.output = into
+	return b
+}
+
+// WithUserIdentifier assigns a custom identifier to this dependency.
+func (b *ListDependencyBuilder[CustomResourceType, ContextType, DependencyType]) WithUserIdentifier(identifier string) *ListDependencyBuilder[CustomResourceType, ContextType, DependencyType] {
+	b.dependency.userIdentifier = identifier
+	return b
+}
+
+// Build constructs and returns the final ListDependency instance.
+func (b *ListDependencyBuilder[CustomResourceType, ContextType, DependencyType]) Build() *ListDependency[CustomResourceType, ContextType, DependencyType] {
+	return b.dependency
+}
@@ -0,0 +1,91 @@
+package ctrlfwk
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// ConditionReasonOptionalDependencyMissing is the reason of the conditions set on the custom resource
+	// for the optional dependencies that are missing, see OptionalDependencyConditionType.
+	ConditionReasonOptionalDependencyMissing = "OptionalDependencyMissing"
+)
+
+// OptionalDependencyConditionType returns the type of the condition set on the custom resource while the optional
+// dependency with the given ID is missing, e.g. "ServiceMonitorAvailable" for the ID "service-monitor".
+// Give the dependency a user identifier, see WithUserIdentifier, for the condition type to be stable and readable.
+func OptionalDependencyConditionType(id string) string {
+	var b strings.Builder
+	upper := true
+	for _, r := range id {
+		if r > unicode.MaxASCII || !(unicode.IsLetter(r) || unicode.IsDigit(r)) {
+			upper = true
+			continue
+		}
+		if upper {
+			r = unicode.ToUpper(r)
+			upper = false
+		}
+		b.WriteRune(r)
+	}
+	if b.Len() == 0 {
+		b.WriteString("OptionalDependency")
+	}
+	return b.String() + "Available"
+}
+
+// IsInformationalCondition tells whether condition only informs about the custom resource, like the conditions
+// of missing optional dependencies, rather than about its health. Informational conditions must not be taken into
+// account when aggregating the readiness of the custom resource from its conditions.
+func IsInformationalCondition(condition metav1.Condition) bool {
+	return condition.Reason == ConditionReasonOptionalDependencyMissing
+}
+
+// availabilityConditionDependency is implemented by the dependencies that can be built with WithAvailabilityCondition.
+type availabilityConditionDependency interface {
+	availabilityConditionDisabled() bool
+}
+
+// setOptionalDependencyCondition reflects the absence of an optional dependency on the status of the custom resource,
+// the condition being removed once the dependency exists.
+func setOptionalDependencyCondition[
+	ControllerResourceType ControllerCustomResource,
+	ContextType Context[ControllerResourceType],
+](
+	ctx ContextType,
+	reconciler Reconciler[ControllerResourceType],
+	dependency GenericDependency[ControllerResourceType, ContextType],
+	missing bool,
+) error {
+	if !dependency.IsOptional() {
+		return nil
+	}
+	if disabled, ok := dependency.(availabilityConditionDependency); ok && disabled.availabilityConditionDisabled() {
+		return nil
+	}
+
+	defer LockContext(ctx)()
+
+	cr := ctx.GetCustomResource()
+	if cr.GetName() == "" || IsFinalizing(cr) {
+		return nil
+	}
+
+	conditionType := OptionalDependencyConditionType(dependency.ID())
+
+	if !missing {
+		_, err := applyCondition(ctx, reconciler, conditionType, nil)
+		return err
+	}
+
+	_, err := applyCondition(ctx, reconciler, conditionType, &metav1.Condition{
+		Status:             metav1.ConditionFalse,
+		Reason:             ConditionReasonOptionalDependencyMissing,
+		Message:            fmt.Sprintf("optional dependency %s was not found, the features relying on it are disabled", dependency.ID()),
+		ObservedGeneration: cr.GetGeneration(),
+	})
+	return err
+}
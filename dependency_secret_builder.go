@@ -0,0 +1,298 @@
+package ctrlfwk
+
+import (
+	"encoding/base64"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// SecretDependencyBuilder provides a fluent builder pattern for creating dependencies on secrets,
+// checking the presence of required keys and extracting values into your context data.
+//
+// Type parameters:
+//   - CustomResourceType: The custom resource that owns this dependency
+//   - ContextType: The context type containing the custom resource and additional data
+//
+// Example:
+//
+//	dep := NewSecretDependencyBuilder(ctx).
+//		WithName("database-credentials").
+//		WithNamespace(ctx.GetCustomResource().Namespace).
+//		WithRequiredKeys("username", "password").
+//		WithKeyExtractor("username", &ctx.Data.Username).
+//		WithKeyExtractor("password", &ctx.Data.Password).
+//		Build()
+type SecretDependencyBuilder[CustomResourceType client.Object, ContextType Context[CustomResourceType]] struct {
+	inner *DependencyBuilder[CustomResourceType, ContextType, *corev1.Secret]
+
+	requiredKeys []string
+	extractors   []secretKeyExtractor
+	base64Decode bool
+}
+
+type secretKeyExtractor struct {
+	key    string
+	target *string
+}
+
+// NewSecretDependencyBuilder creates a new SecretDependencyBuilder for constructing
+// dependencies on secrets.
+//
+// See NewDependencyBuilder for more details on dependencies.
+func NewSecretDependencyBuilder[CustomResourceType client.Object, ContextType Context[CustomResourceType]](ctx ContextType) *SecretDependencyBuilder[CustomResourceType, ContextType] {
+	return &SecretDependencyBuilder[CustomResourceType, ContextType]{
+		inner: NewDependencyBuilder(ctx, &corev1.Secret{}),
+	}
+}
+
+// Build constructs and returns the final Dependency instance with all configured options.
+//
+// The readiness function checks the required keys are present in the secret, in addition
+// to the readiness function given with WithIsReadyFunc if any. Keys are extracted before
+// the AfterReconcile hook runs, so the hook can rely on the extracted values.
+func (b *SecretDependencyBuilder[CustomResourceType, ContextType]) Build() *Dependency[CustomResourceType, ContextType, *corev1.Secret] {
+	dependency := b.inner.Build()
+
+	if len(b.requiredKeys) > 0 {
+		requiredKeys := b.requiredKeys
+		isReadyF := dependency.isReadyF
+		dependency.isReadyF = func(secret *corev1.Secret) bool {
+			if secret == nil {
+				return false
+			}
+			for _, key := range requiredKeys {
+				if _, ok := secret.Data[key]; !ok {
+					return false
+				}
+			}
+			return isReadyF == nil || isReadyF(secret)
+		}
+	}
+
+	if len(b.extractors) > 0 {
+		extractors := b.extractors
+		base64Decode := b.base64Decode
+		afterReconcileF := dependency.afterReconcileF
+		dependency.afterReconcileF = func(ctx ContextType, secret *corev1.Secret) error {
+			for _, extractor := range extractors {
+				value, err := secretValue(secret, extractor.key, base64Decode)
+				if err != nil {
+					return err
+				}
+				*extractor.target = value
+			}
+
+			if afterReconcileF != nil {
+				return afterReconcileF(ctx, secret)
+			}
+			return nil
+		}
+	}
+
+	return dependency
+}
+
+// secretValue returns the value of the given key of the secret, or an empty string when the secret
+// or the key is missing.
+func secretValue(secret *corev1.Secret, key string, base64Decode bool) (string, error) {
+	if secret == nil {
+		return "", nil
+	}
+
+	value, ok := secret.Data[key]
+	if !ok {
+		return "", nil
+	}
+
+	if base64Decode {
+		decoded, err := base64.StdEncoding.DecodeString(string(value))
+		if err != nil {
+			return "", fmt.Errorf("failed to decode key %q of secret %s: %w", key, client.ObjectKeyFromObject(secret), err)
+		}
+		return string(decoded), nil
+	}
+
+	return string(value), nil
+}
+
+// WithRequiredKeys makes the dependency ready only once the secret contains all the given keys.
+//
+// It also makes reconciliation wait for the dependency to be ready, see WithWaitForReady.
+//
+// Example:
+//
+//	.WithRequiredKeys("username", "password")
+func (b *SecretDependencyBuilder[CustomResourceType, ContextType]) WithRequiredKeys(keys ...string) *SecretDependencyBuilder[CustomResourceType, ContextType] {
+	b.requiredKeys = append(b.requiredKeys, keys...)
+	b.inner = b.inner.WithWaitForReady(true)
+	return b
+}
+
+// WithKeyExtractor fills the target with the value of the given key once the secret is resolved.
+//
+// The target is set to an empty string when the secret or the key is missing.
+//
+// Example:
+//
+//	.WithKeyExtractor("password", &ctx.Data.DatabasePassword)
+func (b *SecretDependencyBuilder[CustomResourceType, ContextType]) WithKeyExtractor(key string, target *string) *SecretDependencyBuilder[CustomResourceType, ContextType] {
+	b.extractors = append(b.extractors, secretKeyExtractor{key: key, target: target})
+	return b
+}
+
+// WithBase64Decode decodes the extracted values from base64, for secrets whose values are themselves
+// base64 encoded on top of the encoding done by Kubernetes.
+//
+// A value that is not valid base64 fails the AfterReconcile hook of the dependency.
+func (b *SecretDependencyBuilder[CustomResourceType, ContextType]) WithBase64Decode(decode bool) *SecretDependencyBuilder[CustomResourceType, ContextType] {
+	b.base64Decode = decode
+	return b
+}
+
+// WithAfterReconcile registers a hook function to execute after dependency resolution,
+// once the keys are extracted.
+//
+// See DependencyBuilder.WithAfterReconcile for more details.
+func (b *SecretDependencyBuilder[CustomResourceType, ContextType]) WithAfterReconcile(f func(ctx ContextType, resource *corev1.Secret) error) *SecretDependencyBuilder[CustomResourceType, ContextType] {
+	b.inner = b.inner.WithAfterReconcile(f)
+	return b
+}
+
+// WithTransform converts the resolved dependency once resolved, see DependencyBuilder.WithTransform.
+func (b *SecretDependencyBuilder[CustomResourceType, ContextType]) WithTransform(f func(resolved *corev1.Secret) (any, error)) *SecretDependencyBuilder[CustomResourceType, ContextType] {
+	b.inner = b.inner.WithTransform(f)
+	return b
+}
+
+// WithBeforeReconcile registers a hook function to execute before dependency resolution.
+//
+// See DependencyBuilder.WithBeforeReconcile for more details.
+func (b *SecretDependencyBuilder[CustomResourceType, ContextType]) WithBeforeReconcile(f func(ctx ContextType) error) *SecretDependencyBuilder[CustomResourceType, ContextType] {
+	b.inner = b.inner.WithBeforeReconcile(f)
+	return b
+}
+
+// WithIsReadyFunc defines custom logic to determine if the secret is ready for use,
+// on top of the required keys.
+//
+// See DependencyBuilder.WithIsReadyFunc for more details.
+func (b *SecretDependencyBuilder[CustomResourceType, ContextType]) WithIsReadyFunc(f func(obj *corev1.Secret) bool) *SecretDependencyBuilder[CustomResourceType, ContextType] {
+	b.inner = b.inner.WithIsReadyFunc(f)
+	return b
+}
+
+// WithName specifies the name of the secret to depend on.
+func (b *SecretDependencyBuilder[CustomResourceType, ContextType]) WithName(name string) *SecretDependencyBuilder[CustomResourceType, ContextType] {
+	b.inner = b.inner.WithName(name)
+	return b
+}
+
+// WithNamespace specifies the namespace where the secret is located.
+func (b *SecretDependencyBuilder[CustomResourceType, ContextType]) WithNamespace(namespace string) *SecretDependencyBuilder[CustomResourceType, ContextType] {
+	b.inner = b.inner.WithNamespace(namespace)
+	return b
+}
+
+// WithOptional configures whether this dependency is required for reconciliation.
+//
+// See DependencyBuilder.WithOptional for more details.
+func (b *SecretDependencyBuilder[CustomResourceType, ContextType]) WithOptional(optional bool) *SecretDependencyBuilder[CustomResourceType, ContextType] {
+	b.inner = b.inner.WithOptional(optional)
+	return b
+}
+
+// WithAvailabilityCondition configures whether the custom resource has a condition telling that the optional
+// dependency is missing, see DependencyBuilder.WithAvailabilityCondition.
+func (b *SecretDependencyBuilder[CustomResourceType, ContextType]) WithAvailabilityCondition(enabled bool) *SecretDependencyBuilder[CustomResourceType, ContextType] {
+	b.inner = b.inner.WithAvailabilityCondition(enabled)
+	return b
+}
+
+// WithOutput specifies where to store the resolved secret.
+//
+// See DependencyBuilder.WithOutput for more details.
+func (b *SecretDependencyBuilder[CustomResourceType, ContextType]) WithOutput(obj *corev1.Secret) *SecretDependencyBuilder[CustomResourceType, ContextType] {
+	b.inner = b.inner.WithOutput(obj)
+	return b
+}
+
+// WithOutputFunc specifies where to store the resolved secret, the function is evaluated
+// at resolution time instead of when the builder runs.
+//
+// See DependencyBuilder.WithOutputFunc for more details.
+func (b *SecretDependencyBuilder[CustomResourceType, ContextType]) WithOutputFunc(f func() *corev1.Secret) *SecretDependencyBuilder[CustomResourceType, ContextType] {
+	b.inner = b.inner.WithOutputFunc(f)
+	return b
+}
+
+// WithUserIdentifier assigns a custom identifier for this dependency.
+func (b *SecretDependencyBuilder[CustomResourceType, ContextType]) WithUserIdentifier(identifier string) *SecretDependencyBuilder[CustomResourceType, ContextType] {
+	b.inner = b.inner.WithUserIdentifier(identifier)
+	return b
+}
+
+// WithWaitForReady determines whether reconciliation should wait for this dependency
+// to become ready before proceeding.
+//
+// See DependencyBuilder.WithWaitForReady for more details.
+func (b *SecretDependencyBuilder[CustomResourceType, ContextType]) WithWaitForReady(waitForReady bool) *SecretDependencyBuilder[CustomResourceType, ContextType] {
+	b.inner = b.inner.WithWaitForReady(waitForReady)
+	return b
+}
+
+// WithAddManagedByAnnotation controls whether to add a "managed-by" annotation to the secret.
+//
+// See DependencyBuilder.WithAddManagedByAnnotation for more details.
+func (b *SecretDependencyBuilder[CustomResourceType, ContextType]) WithAddManagedByAnnotation(add bool) *SecretDependencyBuilder[CustomResourceType, ContextType] {
+	b.inner = b.inner.WithAddManagedByAnnotation(add)
+	return b
+}
+
+// WithClient reads the secret with the given client, see DependencyBuilder.WithClient.
+func (b *SecretDependencyBuilder[CustomResourceType, ContextType]) WithClient(c client.Client) *SecretDependencyBuilder[CustomResourceType, ContextType] {
+	b.inner = b.inner.WithClient(c)
+	return b
+}
+
+// WithClientFunc reads the secret with the client returned by f, see DependencyBuilder.WithClientFunc.
+func (b *SecretDependencyBuilder[CustomResourceType, ContextType]) WithClientFunc(f func(ctx ContextType) (client.Client, error)) *SecretDependencyBuilder[CustomResourceType, ContextType] {
+	b.inner = b.inner.WithClientFunc(f)
+	return b
+}
+
+// WithConditionReporting reflects the state of the secret on a condition of the custom resource,
+// see DependencyBuilder.WithConditionReporting.
+func (b *SecretDependencyBuilder[CustomResourceType, ContextType]) WithConditionReporting(conditionType string) *SecretDependencyBuilder[CustomResourceType, ContextType] {
+	b.inner = b.inner.WithConditionReporting(conditionType)
+	return b
+}
+
+// WithReadinessTTL re-evaluates the readiness of the secret after ttl, see DependencyBuilder.WithReadinessTTL.
+func (b *SecretDependencyBuilder[CustomResourceType, ContextType]) WithReadinessTTL(ttl time.Duration) *SecretDependencyBuilder[CustomResourceType, ContextType] {
+	b.inner = b.inner.WithReadinessTTL(ttl)
+	return b
+}
+
+// WithNamespaceFallback searches the secret in the given namespaces when it is not found in its own namespace,
+// see DependencyBuilder.WithNamespaceFallback.
+func (b *SecretDependencyBuilder[CustomResourceType, ContextType]) WithNamespaceFallback(namespaces ...string) *SecretDependencyBuilder[CustomResourceType, ContextType] {
+	b.inner = b.inner.WithNamespaceFallback(namespaces...)
+	return b
+}
+
+// WithNotFoundBackoff configures how the custom resource is requeued while the secret is not found,
+// see DependencyBuilder.WithNotFoundBackoff.
+func (b *SecretDependencyBuilder[CustomResourceType, ContextType]) WithNotFoundBackoff(backoff DependencyBackoff) *SecretDependencyBuilder[CustomResourceType, ContextType] {
+	b.inner = b.inner.WithNotFoundBackoff(backoff)
+	return b
+}
+
+// WithCircuitBreaker stops resolving the secret for resetAfter once it failed to resolve threshold times in a row,
+// see DependencyBuilder.WithCircuitBreaker.
+func (b *SecretDependencyBuilder[CustomResourceType, ContextType]) WithCircuitBreaker(threshold int, resetAfter time.Duration) *SecretDependencyBuilder[CustomResourceType, ContextType] {
+	b.inner = b.inner.WithCircuitBreaker(threshold, resetAfter)
+	return b
+}
@@ -0,0 +1,629 @@
+package ctrlfwk_test
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	ctrlfwk "github.com/u-ctf/controller-fwk"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+type outputData struct {
+	Secret *corev1.Secret
+}
+
+func TestDependency_OutputFunc(t *testing.T) {
+	ctx := ctrlfwk.NewContextWithData[*corev1.Secret](context.Background(), nil, &outputData{})
+
+	dependency := ctrlfwk.NewDependencyBuilder(ctx, &corev1.Secret{}).
+		WithName("secret").
+		WithOutputFunc(func() *corev1.Secret {
+			return ctx.Data.Secret
+		}).
+		Build()
+
+	// The output is only allocated after the builder ran
+	ctx.Data.Secret = &corev1.Secret{}
+
+	resolved := &corev1.Secret{}
+	resolved.SetName("secret")
+	if err := dependency.Set(resolved); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if ctx.Data.Secret.GetName() != "secret" {
+		t.Fatalf("expected output name to be 'secret', got '%s'", ctx.Data.Secret.GetName())
+	}
+}
+
+func TestDependency_OutputFuncNil(t *testing.T) {
+	ctx := ctrlfwk.NewContextWithData[*corev1.Secret](context.Background(), nil, &outputData{})
+
+	dependency := ctrlfwk.NewDependencyBuilder(ctx, &corev1.Secret{}).
+		WithName("secret").
+		WithOutputFunc(func() *corev1.Secret {
+			return ctx.Data.Secret
+		}).
+		Build()
+
+	if err := dependency.Set(&corev1.Secret{}); err == nil {
+		t.Fatal("expected an error when the output func returns nil")
+	}
+}
+
+func TestListDependency_WaitsForMinReadyCount(t *testing.T) {
+	pod := func(name string, phase corev1.PodPhase) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", Labels: map[string]string{"app": "db"}},
+			Status:     corev1.PodStatus{Phase: phase},
+		}
+	}
+	cr := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "cr", Namespace: "default"}}
+	reconciler := &fakeReconciler{
+		Client: fake.NewClientBuilder().
+			WithObjects(cr, pod("db-0", corev1.PodRunning), pod("db-1", corev1.PodPending)).
+			WithStatusSubresource(&corev1.Pod{}).
+			Build(),
+	}
+
+	ctx := ctrlfwk.NewContext(context.Background(), reconciler)
+	ctx.SetCustomResource(cr)
+
+	var pods []*corev1.Pod
+	dependency := ctrlfwk.NewListDependencyBuilder(ctx, &corev1.Pod{}).
+		WithListOptions("default", labels.SelectorFromSet(labels.Set{"app": "db"})).
+		WithMinCount(2).
+		WithEachReadyFunc(func(pod *corev1.Pod) bool {
+			return pod.Status.Phase == corev1.PodRunning
+		}).
+		WithOutput(&pods).
+		Build()
+
+	step := ctrlfwk.NewResolveListDependencyStep(ctx, reconciler, dependency)
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Name: "cr", Namespace: "default"}}
+
+	result, err := step.Step(ctx, logr.Discard(), req).Normal()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.RequeueAfter == 0 {
+		t.Fatal("expected a requeue while not enough pods are ready")
+	}
+	if len(pods) != 2 {
+		t.Fatalf("expected 2 matched pods, got %d", len(pods))
+	}
+
+	pending := pods[1]
+	pending.Status.Phase = corev1.PodRunning
+	if err := reconciler.Status().Update(ctx, pending); err != nil {
+		t.Fatalf("failed to update pod: %v", err)
+	}
+
+	result, err = step.Step(ctx, logr.Discard(), req).Normal()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.RequeueAfter != 0 {
+		t.Fatalf("expected the dependency to be ready, got a requeue after %s", result.RequeueAfter)
+	}
+}
+
+func TestNegotiateGVK_CachedUntilInvalidated(t *testing.T) {
+	v1 := schema.GroupVersionKind{Group: "monitoring.coreos.com", Version: "v1", Kind: "ServiceMonitor"}
+	v1alpha1 := schema.GroupVersionKind{Group: "monitoring.coreos.com", Version: "v1alpha1", Kind: "ServiceMonitor"}
+
+	mapper := meta.NewDefaultRESTMapper(nil)
+	mapper.Add(v1alpha1, meta.RESTScopeNamespace)
+
+	gvk, err := ctrlfwk.NegotiateGVK(mapper, v1, v1alpha1)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if gvk != v1alpha1 {
+		t.Fatalf("expected the fallback version to be negotiated, got %s", gvk)
+	}
+
+	mapper.Add(v1, meta.RESTScopeNamespace)
+
+	gvk, _ = ctrlfwk.NegotiateGVK(mapper, v1, v1alpha1)
+	if gvk != v1alpha1 {
+		t.Fatalf("expected the negotiated version to be cached, got %s", gvk)
+	}
+
+	ctrlfwk.InvalidateGVKNegotiation(mapper, v1, v1alpha1)
+
+	gvk, _ = ctrlfwk.NegotiateGVK(mapper, v1, v1alpha1)
+	if gvk != v1 {
+		t.Fatalf("expected the preferred version once invalidated, got %s", gvk)
+	}
+
+	if _, err := ctrlfwk.NegotiateGVK(mapper, schema.GroupVersionKind{Group: "example.com", Version: "v1", Kind: "Missing"}); !meta.IsNoMatchError(err) {
+		t.Fatalf("expected a no match error, got %v", err)
+	}
+}
+
+func TestManagedByPredicate(t *testing.T) {
+	owner := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "owner", Namespace: "default"}}
+
+	managed := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "managed", Namespace: "default", ResourceVersion: "1"}}
+	if _, err := ctrlfwk.AddManagedBy(managed, owner, clientgoscheme.Scheme); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	unmanaged := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "unmanaged", Namespace: "default", ResourceVersion: "1"}}
+
+	_, predicates, err := ctrlfwk.ManagedByWatch(owner, clientgoscheme.Scheme)
+	if err != nil || predicates == nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	predicate := ctrlfwk.ManagedByPredicate{GVK: corev1.SchemeGroupVersion.WithKind("ConfigMap")}
+	if !predicate.Create(event.CreateEvent{Object: managed}) {
+		t.Fatal("expected events of annotated objects to be let through")
+	}
+	if predicate.Create(event.CreateEvent{Object: unmanaged}) {
+		t.Fatal("expected events of objects without the annotation to be filtered")
+	}
+
+	updated := unmanaged.DeepCopy()
+	updated.ResourceVersion = "2"
+	if !predicate.Update(event.UpdateEvent{ObjectOld: managed, ObjectNew: updated}) {
+		t.Fatal("expected the removal of the annotation to be let through")
+	}
+	if predicate.Update(event.UpdateEvent{ObjectOld: managed, ObjectNew: managed}) {
+		t.Fatal("expected updates without a resource version change to be filtered")
+	}
+}
+
+func TestResolveDependencyStep_RecordsOutcome(t *testing.T) {
+	cr := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "cr", Namespace: "default"}}
+	reconciler := &fakeReconciler{Client: fake.NewClientBuilder().WithObjects(cr).Build()}
+
+	ctx := ctrlfwk.NewContext(context.Background(), reconciler)
+	ctx.SetCustomResource(cr)
+
+	dependency := ctrlfwk.NewDependencyBuilder(ctx, &corev1.Secret{}).
+		WithName("missing").
+		WithNamespace("default").
+		WithUserIdentifier("outcome-test").
+		Build()
+
+	step := ctrlfwk.NewResolveDependencyStep(ctx, reconciler, dependency)
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Name: "cr", Namespace: "default"}}
+
+	if _, err := step.Step(ctx, logr.Discard(), req).Normal(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	families, err := metrics.Registry.Gather()
+	if err != nil {
+		t.Fatalf("failed to gather metrics: %v", err)
+	}
+	for _, family := range families {
+		if family.GetName() != "ctrlfwk_dependency_resolution_total" {
+			continue
+		}
+		for _, metric := range family.GetMetric() {
+			found := map[string]string{}
+			for _, label := range metric.GetLabel() {
+				found[label.GetName()] = label.GetValue()
+			}
+			if found["dependency"] == "outcome-test" && found["outcome"] == string(ctrlfwk.DependencyOutcomeNotFound) {
+				if metric.GetCounter().GetValue() != 1 {
+					t.Fatalf("expected 1 not found resolution, got %v", metric.GetCounter().GetValue())
+				}
+				return
+			}
+		}
+	}
+	t.Fatal("expected a not found resolution to be recorded")
+}
+
+func TestSecretDependency_RequiredKeysAndExtraction(t *testing.T) {
+	ctx := ctrlfwk.NewContextWithData[*corev1.Secret](context.Background(), nil, &outputData{})
+
+	var username, password string
+	dependency := ctrlfwk.NewSecretDependencyBuilder(ctx).
+		WithName("credentials").
+		WithRequiredKeys("username", "password").
+		WithKeyExtractor("username", &username).
+		WithKeyExtractor("password", &password).
+		WithBase64Decode(true).
+		Build()
+
+	if !dependency.ShouldWaitForReady() {
+		t.Fatal("expected required keys to make the dependency wait for readiness")
+	}
+
+	secret := &corev1.Secret{Data: map[string][]byte{"username": []byte("YWRtaW4=")}}
+	if err := dependency.Set(secret); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if dependency.IsReady() {
+		t.Fatal("expected the dependency not to be ready while a required key is missing")
+	}
+
+	secret.Data["password"] = []byte("czNjcjN0")
+	if err := dependency.Set(secret); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !dependency.IsReady() {
+		t.Fatal("expected the dependency to be ready once all required keys are present")
+	}
+
+	if err := dependency.AfterReconcile(ctx, secret); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if username != "admin" || password != "s3cr3t" {
+		t.Fatalf("expected decoded values, got %q and %q", username, password)
+	}
+
+	secret.Data["password"] = []byte("not base64!")
+	if err := dependency.AfterReconcile(ctx, secret); err == nil {
+		t.Fatal("expected an error for a value that is not valid base64")
+	}
+}
+
+func TestResolveDependencyStep_ConditionReporting(t *testing.T) {
+	ctx, reconciler := newConditionsTest(t)
+
+	dependency := ctrlfwk.NewDependencyBuilder(ctx, &corev1.Secret{}).
+		WithName("secret").
+		WithNamespace("default").
+		WithIsReadyFunc(func(secret *corev1.Secret) bool { return secret.Data["ready"] != nil }).
+		WithWaitForReady(true).
+		WithConditionReporting("SecretFound").
+		Build()
+	step := ctrlfwk.NewResolveDependencyStep(ctx, reconciler, dependency)
+
+	assertCondition := func(expectedReason string) {
+		t.Helper()
+		step.Step(ctx, logr.Discard(), ctrl.Request{})
+
+		condition := meta.FindStatusCondition(ctx.GetCustomResource().Status.Conditions, "SecretFound")
+		switch {
+		case expectedReason == "" && condition != nil:
+			t.Fatalf("expected no condition, got %v", condition)
+		case expectedReason == "":
+		case condition == nil || condition.Status != metav1.ConditionFalse || condition.Reason != expectedReason:
+			t.Fatalf("expected a False condition with reason %s, got %v", expectedReason, condition)
+		}
+	}
+
+	assertCondition("SecretNotFound")
+
+	secret := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "secret", Namespace: "default"}}
+	if err := reconciler.Create(ctx, secret); err != nil {
+		t.Fatalf("failed to create secret: %v", err)
+	}
+	assertCondition("SecretNotReady")
+
+	secret.Data = map[string][]byte{"ready": []byte("true")}
+	if err := reconciler.Update(ctx, secret); err != nil {
+		t.Fatalf("failed to update secret: %v", err)
+	}
+	assertCondition("")
+
+	if err := reconciler.Delete(ctx, secret); err != nil {
+		t.Fatalf("failed to delete secret: %v", err)
+	}
+	assertCondition("SecretNotFound")
+}
+
+func TestResolveDependencyStep_ReadinessTTL(t *testing.T) {
+	ctx, reconciler := newConditionsTest(t)
+
+	secret := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "secret", Namespace: "default"}}
+	if err := reconciler.Create(ctx, secret); err != nil {
+		t.Fatalf("failed to create secret: %v", err)
+	}
+
+	dependency := ctrlfwk.NewDependencyBuilder(ctx, &corev1.Secret{}).
+		WithName("secret").
+		WithNamespace("default").
+		WithReadinessTTL(time.Minute).
+		Build()
+
+	if result := ctrlfwk.NewResolveDependencyStep(ctx, reconciler, dependency).Step(ctx, logr.Discard(), ctrl.Request{}); result.ShouldReturn() {
+		t.Fatalf("unexpected result: %v", result)
+	}
+	if ctx.GetRequeueAfter() != time.Minute || ctx.GetRequeueReason() != ctrlfwk.RequeueReasonReadinessExpired {
+		t.Fatalf("expected the readiness to be re-evaluated after its TTL, got %v (%s)", ctx.GetRequeueAfter(), ctx.GetRequeueReason())
+	}
+}
+
+func TestResolveDependencyStep_NamespaceFallback(t *testing.T) {
+	ctx, reconciler := newConditionsTest(t)
+
+	for _, namespace := range []string{"platform", "shared"} {
+		secret := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "tls", Namespace: namespace}}
+		if err := reconciler.Create(ctx, secret); err != nil {
+			t.Fatalf("failed to create secret: %v", err)
+		}
+	}
+
+	dependency := ctrlfwk.NewDependencyBuilder(ctx, &corev1.Secret{}).
+		WithName("tls").
+		WithNamespace("default").
+		WithNamespaceFallback("missing", "platform", "shared").
+		Build()
+	step := ctrlfwk.NewResolveDependencyStep(ctx, reconciler, dependency)
+
+	if result := step.Step(ctx, logr.Discard(), ctrl.Request{}); result.ShouldReturn() {
+		t.Fatalf("unexpected result: %v", result)
+	}
+	if dependency.Get().GetNamespace() != "platform" {
+		t.Fatalf("expected the secret of the first fallback namespace, got %q", dependency.Get().GetNamespace())
+	}
+	condition := meta.FindStatusCondition(ctx.GetCustomResource().Status.Conditions, ctrlfwk.ConditionTypeDependencyFoundInFallback)
+	if condition == nil || condition.Status != metav1.ConditionTrue {
+		t.Fatalf("expected the fallback condition to be set, got %v", condition)
+	}
+
+	secret := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "tls", Namespace: "default"}}
+	if err := reconciler.Create(ctx, secret); err != nil {
+		t.Fatalf("failed to create secret: %v", err)
+	}
+
+	if result := step.Step(ctx, logr.Discard(), ctrl.Request{}); result.ShouldReturn() {
+		t.Fatalf("unexpected result: %v", result)
+	}
+	if dependency.Get().GetNamespace() != "default" {
+		t.Fatalf("expected the secret of its own namespace, got %q", dependency.Get().GetNamespace())
+	}
+	if meta.FindStatusCondition(ctx.GetCustomResource().Status.Conditions, ctrlfwk.ConditionTypeDependencyFoundInFallback) != nil {
+		t.Fatal("expected the fallback condition to be removed")
+	}
+}
+
+func TestResolveDependencyStep_NotFoundBackoff(t *testing.T) {
+	ctx, reconciler := newConditionsTest(t)
+
+	dependency := ctrlfwk.NewDependencyBuilder(ctx, &corev1.Secret{}).
+		WithName("forgotten").
+		WithNamespace("default").
+		WithConditionReporting("ForgottenFound").
+		WithNotFoundBackoff(ctrlfwk.DependencyBackoff{Initial: 30 * time.Second, Max: 90 * time.Second}).
+		Build()
+	step := ctrlfwk.NewResolveDependencyStep(ctx, reconciler, dependency)
+
+	assertNextCheck := func(expected time.Duration, message string) {
+		t.Helper()
+		result, err := step.Step(ctx, logr.Discard(), ctrl.Request{}).Normal()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if result.RequeueAfter != expected {
+			t.Fatalf("expected the next check in %v, got %v", expected, result.RequeueAfter)
+		}
+		condition := meta.FindStatusCondition(ctx.GetCustomResource().Status.Conditions, "ForgottenFound")
+		if condition == nil || !strings.HasSuffix(condition.Message, message) {
+			t.Fatalf("expected the condition message to end with %q, got %v", message, condition)
+		}
+	}
+
+	assertNextCheck(30*time.Second, "next check in ~30s")
+	assertNextCheck(time.Minute, "next check in ~1m")
+	assertNextCheck(90*time.Second, "next check in ~2m")
+	assertNextCheck(90*time.Second, "next check in ~2m")
+
+	// The backoff is reset once the dependency is found
+	secret := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "forgotten", Namespace: "default"}}
+	if err := reconciler.Create(ctx, secret); err != nil {
+		t.Fatalf("failed to create secret: %v", err)
+	}
+	if result := step.Step(ctx, logr.Discard(), ctrl.Request{}); result.ShouldReturn() {
+		t.Fatalf("unexpected result: %v", result)
+	}
+	if err := reconciler.Delete(ctx, secret); err != nil {
+		t.Fatalf("failed to delete secret: %v", err)
+	}
+	assertNextCheck(30*time.Second, "next check in ~30s")
+}
+
+func TestResolveDependencyStep_CircuitBreaker(t *testing.T) {
+	ctx, reconciler := newConditionsTest(t)
+
+	var resolutions int
+	dependency := ctrlfwk.NewDependencyBuilder(ctx, &corev1.Secret{}).
+		WithName("provider").
+		WithNamespace("default").
+		WithBeforeReconcile(func(ctrlfwk.Context[*conditionsCR]) error {
+			resolutions++
+			return nil
+		}).
+		WithCircuitBreaker(2, time.Hour).
+		Build()
+	step := ctrlfwk.NewResolveDependencyStep(ctx, reconciler, dependency)
+
+	circuitOpen := func() bool {
+		return meta.IsStatusConditionTrue(ctx.GetCustomResource().Status.Conditions, ctrlfwk.ConditionTypeExternalDependencyCircuitOpen)
+	}
+
+	for range 3 {
+		step.Step(ctx, logr.Discard(), ctrl.Request{})
+	}
+	if resolutions != 2 || !circuitOpen() {
+		t.Fatalf("expected the circuit to open after 2 failures, got %d resolutions", resolutions)
+	}
+	result, _ := step.Step(ctx, logr.Discard(), ctrl.Request{}).Normal()
+	if result.RequeueAfter <= 0 || result.RequeueAfter > time.Hour {
+		t.Fatalf("expected a requeue once the circuit closes, got %v", result.RequeueAfter)
+	}
+
+	secret := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "provider", Namespace: "default"}}
+	if err := reconciler.Create(ctx, secret); err != nil {
+		t.Fatalf("failed to create secret: %v", err)
+	}
+	ctrlfwk.ForceCloseCircuit(ctx, dependency.ID())
+	if result := step.Step(ctx, logr.Discard(), ctrl.Request{}); result.ShouldReturn() {
+		t.Fatalf("unexpected result: %v", result)
+	}
+	if resolutions != 3 || circuitOpen() {
+		t.Fatalf("expected the circuit to be closed, got %d resolutions", resolutions)
+	}
+}
+
+func TestResolveDependencyStep_DependencyState(t *testing.T) {
+	cr := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "cr", Namespace: "default"}}
+	secret := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "present", Namespace: "default"}}
+	reconciler := &fakeReconciler{Client: fake.NewClientBuilder().WithObjects(cr, secret).Build()}
+
+	ctx := ctrlfwk.NewContext(context.Background(), reconciler)
+	ctx.SetCustomResource(cr)
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Name: "cr", Namespace: "default"}}
+
+	resolve := func(id, name string, optional bool, ready bool) ctrlfwk.StepResult {
+		t.Helper()
+		dependency := ctrlfwk.NewDependencyBuilder(ctx, &corev1.Secret{}).
+			WithName(name).
+			WithNamespace("default").
+			WithUserIdentifier(id).
+			WithOptional(optional).
+			WithIsReadyFunc(func(*corev1.Secret) bool { return ready }).
+			WithWaitForReady(true).
+			Build()
+		return ctrlfwk.NewResolveDependencyStep(ctx, reconciler, dependency).Step(ctx, logr.Discard(), req)
+	}
+
+	if ctx.DependencyState("ready") != ctrlfwk.DependencyStateUnresolved {
+		t.Fatal("expected dependencies to be unresolved before their step runs")
+	}
+	if result := resolve("ready", "present", false, true); result.ShouldReturn() {
+		t.Fatal("expected the ready dependency not to stop the reconciliation")
+	}
+	if result := resolve("optional", "missing", true, true); result.ShouldReturn() {
+		t.Fatal("expected the missing optional dependency not to stop the reconciliation")
+	}
+	if result := resolve("not-ready", "present", false, false); !result.ShouldReturn() {
+		t.Fatal("expected the dependency that is not ready to stop the reconciliation")
+	}
+	if result := resolve("required", "missing", false, true); !result.ShouldReturn() {
+		t.Fatal("expected the missing dependency to stop the reconciliation")
+	}
+
+	expected := map[string]ctrlfwk.DependencyState{
+		"ready":     ctrlfwk.DependencyStateReady,
+		"optional":  ctrlfwk.DependencyStateSkippedOptional,
+		"not-ready": ctrlfwk.DependencyStateNotReady,
+		"required":  ctrlfwk.DependencyStateMissing,
+	}
+	for id, state := range expected {
+		if got := ctx.DependencyState(id); got != state {
+			t.Errorf("expected dependency %s to be %s, got %q", id, state, got)
+		}
+	}
+}
+
+func TestResolveDependencyStep_OptionalDependencyCondition(t *testing.T) {
+	ctx, reconciler := newConditionsTest(t)
+
+	build := func(id string, enabled bool) ctrlfwk.GenericDependency[*conditionsCR, conditionsContext] {
+		return ctrlfwk.NewDependencyBuilder(ctx, &corev1.Secret{}).
+			WithName("monitoring").
+			WithNamespace("default").
+			WithUserIdentifier(id).
+			WithOptional(true).
+			WithAvailabilityCondition(enabled).
+			Build()
+	}
+	step := ctrlfwk.NewResolveDependencyStep(ctx, reconciler, build("service-monitor", true))
+	disabled := ctrlfwk.NewResolveDependencyStep(ctx, reconciler, build("quiet", false))
+
+	run := func() {
+		t.Helper()
+		for _, s := range []ctrlfwk.Step[*conditionsCR, conditionsContext]{step, disabled} {
+			if result := s.Step(ctx, logr.Discard(), ctrl.Request{}); result.ShouldReturn() {
+				t.Fatalf("expected the optional dependency not to stop the reconciliation, got %v", result)
+			}
+		}
+	}
+
+	run()
+	conditions := ctx.GetCustomResource().Status.Conditions
+	condition := meta.FindStatusCondition(conditions, "ServiceMonitorAvailable")
+	if condition == nil || condition.Status != metav1.ConditionFalse || condition.Reason != ctrlfwk.ConditionReasonOptionalDependencyMissing {
+		t.Fatalf("expected a False condition for the missing optional dependency, got %v", conditions)
+	}
+	if !ctrlfwk.IsInformationalCondition(*condition) {
+		t.Fatal("expected the condition to be informational")
+	}
+	if meta.FindStatusCondition(conditions, "QuietAvailable") != nil {
+		t.Fatal("expected no condition for the dependency with the condition disabled")
+	}
+
+	secret := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "monitoring", Namespace: "default"}}
+	if err := reconciler.Create(ctx, secret); err != nil {
+		t.Fatalf("failed to create secret: %v", err)
+	}
+	run()
+	if condition := meta.FindStatusCondition(ctx.GetCustomResource().Status.Conditions, "ServiceMonitorAvailable"); condition != nil {
+		t.Fatalf("expected the condition to be removed once the dependency exists, got %v", condition)
+	}
+}
+
+func TestResolveDependencyStep_TypedOutput(t *testing.T) {
+	ctx, reconciler := newConditionsTest(t)
+	settings := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "settings", Namespace: "default"},
+		Data:       map[string]string{"replicas": "3"},
+	}
+	if err := reconciler.Create(ctx, settings); err != nil {
+		t.Fatalf("failed to create config map: %v", err)
+	}
+
+	var calls []string
+	var replicas int
+	dependency := ctrlfwk.WithTypedOutput(
+		ctrlfwk.NewDependencyBuilder(ctx, &corev1.ConfigMap{}).
+			WithName("settings").
+			WithNamespace("default").
+			WithAfterReconcile(func(conditionsContext, *corev1.ConfigMap) error {
+				calls = append(calls, "AfterReconcile")
+				return nil
+			}),
+		&replicas,
+		func(cm *corev1.ConfigMap) (int, error) {
+			calls = append(calls, "Transform")
+			return strconv.Atoi(cm.Data["replicas"])
+		},
+	).Build()
+	step := ctrlfwk.NewResolveDependencyStep(ctx, reconciler, dependency)
+
+	if result := step.Step(ctx, logr.Discard(), ctrl.Request{}); result.ShouldReturn() {
+		t.Fatalf("unexpected result: %v", result)
+	}
+	if replicas != 3 || dependency.Transformed() != 3 {
+		t.Fatalf("expected the transformed value to be stored, got %d", replicas)
+	}
+	if strings.Join(calls, ",") != "AfterReconcile,Transform" {
+		t.Fatalf("expected the transform to run after the AfterReconcile hook, got %v", calls)
+	}
+
+	settings.Data["replicas"] = "many"
+	if err := reconciler.Update(ctx, settings); err != nil {
+		t.Fatalf("failed to update config map: %v", err)
+	}
+	result := step.Step(ctx, logr.Discard(), ctrl.Request{})
+	if _, err := result.Normal(); !errors.Is(err, ctrlfwk.ErrHookFailed) {
+		t.Fatalf("expected the transform error to fail the reconciliation, got %v", err)
+	}
+	if replicas != 3 {
+		t.Fatalf("expected the previous value to be kept, got %d", replicas)
+	}
+}
@@ -11,6 +11,9 @@ import (
 type UntypedDependency[CustomResourceType client.Object, ContextType Context[CustomResourceType]] struct {
 	*Dependency[CustomResourceType, ContextType, *unstructured.Unstructured]
 	gvk schema.GroupVersionKind
+
+	apiVersionConstraint string
+	candidates           []schema.GroupVersionKind
 }
 
 var _ GenericDependency[client.Object, Context[client.Object]] = &UntypedDependency[client.Object, Context[client.Object]]{}
@@ -22,10 +25,27 @@ func (c *UntypedDependency[CustomResourceType, ContextType]) New() client.Object
 }
 
 func (c *UntypedDependency[CustomResourceType, ContextType]) Kind() string {
-	return fmt.Sprintf("Untyped%s", c.gvk.Kind)
+	return fmt.Sprintf("Untyped%s", c.preferredGVK().Kind)
 }
 
-func (c *UntypedDependency[CustomResourceType, ContextType]) Set(obj client.Object) {
+// ID returns the user identifier of the dependency, or one qualified with its group and version
+// (e.g. "monitoring.coreos.com/v1/ServiceMonitor,default/name").
+func (c *UntypedDependency[CustomResourceType, ContextType]) ID() string {
+	if c.userIdentifier != "" {
+		return c.userIdentifier
+	}
+	return fmt.Sprintf("%v,%v", qualifiedKind(c.preferredGVK()), c.Key())
+}
+
+// preferredGVK is the GroupVersionKind the dependency is identified with, whatever the negotiated version.
+func (c *UntypedDependency[CustomResourceType, ContextType]) preferredGVK() schema.GroupVersionKind {
+	if len(c.candidates) > 0 {
+		return c.candidates[0]
+	}
+	return c.gvk
+}
+
+func (c *UntypedDependency[CustomResourceType, ContextType]) Set(obj client.Object) error {
 	if c.output == nil {
 		c.output = &unstructured.Unstructured{}
 		c.output.SetGroupVersionKind(c.gvk)
@@ -34,4 +54,29 @@ func (c *UntypedDependency[CustomResourceType, ContextType]) Set(obj client.Obje
 	unstructuredObj := obj.(*unstructured.Unstructured)
 	*c.output = *unstructuredObj
 	c.output.SetGroupVersionKind(c.gvk)
+
+	if c.hasOutputF {
+		if err := setOutputFromFunc(c.outputF, c.output); err != nil {
+			return fmt.Errorf("failed to set output of %s: %w", c.ID(), err)
+		}
+	}
+
+	return nil
+}
+
+func (c *UntypedDependency[CustomResourceType, ContextType]) APIVersionConstraint() string {
+	return c.apiVersionConstraint
+}
+
+func (c *UntypedDependency[CustomResourceType, ContextType]) gvkCandidates() []schema.GroupVersionKind {
+	return c.candidates
+}
+
+// Dependencies are not owned by the controller, they are never migrated
+func (c *UntypedDependency[CustomResourceType, ContextType]) gvkMigrationPolicy() GVKMigrationPolicy {
+	return GVKMigrationPolicyConvert
+}
+
+func (c *UntypedDependency[CustomResourceType, ContextType]) setNegotiatedGVK(gvk schema.GroupVersionKind) {
+	c.gvk = gvk
 }
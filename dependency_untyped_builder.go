@@ -1,6 +1,8 @@
 package ctrlfwk
 
 import (
+	"time"
+
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -42,6 +44,9 @@ import (
 type UntypedDependencyBuilder[CustomResourceType client.Object, ContextType Context[CustomResourceType]] struct {
 	inner *DependencyBuilder[CustomResourceType, ContextType, *unstructured.Unstructured]
 	gvk   schema.GroupVersionKind
+
+	apiVersionConstraint string
+	candidates           []schema.GroupVersionKind
 }
 
 // NewUntypedDependencyBuilder creates a new UntypedDependencyBuilder for constructing
@@ -92,11 +97,51 @@ func NewUntypedDependencyBuilder[CustomResourceType client.Object, ContextType C
 // Returns a configured UntypedDependency instance ready for use in reconciliation.
 func (b *UntypedDependencyBuilder[CustomResourceType, ContextType]) Build() *UntypedDependency[CustomResourceType, ContextType] {
 	return &UntypedDependency[CustomResourceType, ContextType]{
-		Dependency: b.inner.Build(),
-		gvk:        b.gvk,
+		Dependency:           b.inner.Build(),
+		gvk:                  b.gvk,
+		apiVersionConstraint: b.apiVersionConstraint,
+		candidates:           b.candidates,
 	}
 }
 
+// WithAPIVersionConstraint requires the server to serve the dependency kind in the given group
+// with at least the given version (e.g. "monitoring.coreos.com/v1beta1") before resolving it.
+//
+// Versions are compared the Kubernetes way, GA versions being more recent than beta versions,
+// themselves more recent than alpha versions (v1 > v1beta2 > v1beta1 > v1alpha1).
+//
+// When the version is not served, the dependency is skipped instead of failing the reconciliation,
+// and the custom resource gets a DependencyVersionUnsupported condition listing the skipped dependencies.
+// This lets the controller degrade gracefully on clusters running an older version of a third-party operator.
+//
+// Example:
+//
+//	.WithAPIVersionConstraint("monitoring.coreos.com/v1beta1")
+func (b *UntypedDependencyBuilder[CustomResourceType, ContextType]) WithAPIVersionConstraint(groupVersion string) *UntypedDependencyBuilder[CustomResourceType, ContextType] {
+	b.apiVersionConstraint = groupVersion
+	return b
+}
+
+// WithGVKCandidates resolves the dependency with the first of the given GroupVersionKinds served by the cluster,
+// candidates being sorted by preference. It replaces the GroupVersionKind given to NewUntypedDependencyBuilder.
+//
+// The negotiated version is cached and refreshed periodically, so the preferred version gets used once it is served.
+// While a fallback version is used, the custom resource gets an APIVersionFallback condition.
+//
+// Example:
+//
+//	.WithGVKCandidates(
+//		schema.GroupVersionKind{Group: "monitoring.coreos.com", Version: "v1", Kind: "ServiceMonitor"},
+//		schema.GroupVersionKind{Group: "monitoring.coreos.com", Version: "v1alpha1", Kind: "ServiceMonitor"},
+//	)
+func (b *UntypedDependencyBuilder[CustomResourceType, ContextType]) WithGVKCandidates(gvks ...schema.GroupVersionKind) *UntypedDependencyBuilder[CustomResourceType, ContextType] {
+	b.candidates = gvks
+	if len(gvks) > 0 {
+		b.gvk = gvks[0]
+	}
+	return b
+}
+
 // WithAfterReconcile registers a hook function to execute after successful dependency resolution.
 //
 // This function is called with the resolved dependency as an unstructured.Unstructured object
@@ -123,6 +168,12 @@ func (b *UntypedDependencyBuilder[CustomResourceType, ContextType]) WithAfterRec
 	return b
 }
 
+// WithTransform converts the resolved dependency once resolved, see DependencyBuilder.WithTransform.
+func (b *UntypedDependencyBuilder[CustomResourceType, ContextType]) WithTransform(f func(resolved *unstructured.Unstructured) (any, error)) *UntypedDependencyBuilder[CustomResourceType, ContextType] {
+	b.inner = b.inner.WithTransform(f)
+	return b
+}
+
 // WithBeforeReconcile registers a hook function to execute before dependency resolution.
 //
 // This function is called before attempting to resolve the untyped dependency and can be used
@@ -217,6 +268,13 @@ func (b *UntypedDependencyBuilder[CustomResourceType, ContextType]) WithOptional
 	return b
 }
 
+// WithAvailabilityCondition configures whether the custom resource has a condition telling that the optional
+// dependency is missing, see DependencyBuilder.WithAvailabilityCondition.
+func (b *UntypedDependencyBuilder[CustomResourceType, ContextType]) WithAvailabilityCondition(enabled bool) *UntypedDependencyBuilder[CustomResourceType, ContextType] {
+	b.inner = b.inner.WithAvailabilityCondition(enabled)
+	return b
+}
+
 // WithOutput specifies where to store the resolved untyped dependency resource.
 //
 // The provided unstructured.Unstructured object will be populated with the dependency's
@@ -241,6 +299,21 @@ func (b *UntypedDependencyBuilder[CustomResourceType, ContextType]) WithOutput(o
 	return b
 }
 
+// WithOutputFunc specifies where to store the resolved untyped dependency resource, the function is evaluated
+// at resolution time instead of when the builder runs.
+//
+// See DependencyBuilder.WithOutputFunc for more details.
+//
+// Example:
+//
+//	.WithOutputFunc(func() *unstructured.Unstructured {
+//		return ctx.Data.DatabaseInstance
+//	})
+func (b *UntypedDependencyBuilder[CustomResourceType, ContextType]) WithOutputFunc(f func() *unstructured.Unstructured) *UntypedDependencyBuilder[CustomResourceType, ContextType] {
+	b.inner = b.inner.WithOutputFunc(f)
+	return b
+}
+
 // WithReadinessCondition is an alias for WithIsReadyFunc that defines custom readiness logic.
 //
 // This method provides the same functionality as WithIsReadyFunc but with a more
@@ -295,6 +368,7 @@ func (b *UntypedDependencyBuilder[CustomResourceType, ContextType]) WithWaitForR
 // When enabled, this adds metadata to help identify which controller is managing
 // or depending on this resource. This is especially useful for untyped dependencies
 // since the relationship between controllers and third-party resources may not be obvious.
+// Like typed dependencies, the dependency then gets watched, see DependencyBuilder.WithAddManagedByAnnotation.
 //
 // The annotation typically follows the format:
 //
@@ -307,3 +381,49 @@ func (b *UntypedDependencyBuilder[CustomResourceType, ContextType]) WithAddManag
 	b.inner = b.inner.WithAddManagedByAnnotation(add)
 	return b
 }
+
+// WithClient resolves the untyped dependency with the given client, see DependencyBuilder.WithClient.
+func (b *UntypedDependencyBuilder[CustomResourceType, ContextType]) WithClient(c client.Client) *UntypedDependencyBuilder[CustomResourceType, ContextType] {
+	b.inner = b.inner.WithClient(c)
+	return b
+}
+
+// WithClientFunc resolves the untyped dependency with the client returned by f, see DependencyBuilder.WithClientFunc.
+func (b *UntypedDependencyBuilder[CustomResourceType, ContextType]) WithClientFunc(f func(ctx ContextType) (client.Client, error)) *UntypedDependencyBuilder[CustomResourceType, ContextType] {
+	b.inner = b.inner.WithClientFunc(f)
+	return b
+}
+
+// WithConditionReporting reflects the state of the untyped dependency on a condition of the custom resource,
+// see DependencyBuilder.WithConditionReporting.
+func (b *UntypedDependencyBuilder[CustomResourceType, ContextType]) WithConditionReporting(conditionType string) *UntypedDependencyBuilder[CustomResourceType, ContextType] {
+	b.inner = b.inner.WithConditionReporting(conditionType)
+	return b
+}
+
+// WithReadinessTTL re-evaluates the readiness of the untyped dependency after ttl, see DependencyBuilder.WithReadinessTTL.
+func (b *UntypedDependencyBuilder[CustomResourceType, ContextType]) WithReadinessTTL(ttl time.Duration) *UntypedDependencyBuilder[CustomResourceType, ContextType] {
+	b.inner = b.inner.WithReadinessTTL(ttl)
+	return b
+}
+
+// WithNamespaceFallback searches the untyped dependency in the given namespaces when it is not found in its own namespace,
+// see DependencyBuilder.WithNamespaceFallback.
+func (b *UntypedDependencyBuilder[CustomResourceType, ContextType]) WithNamespaceFallback(namespaces ...string) *UntypedDependencyBuilder[CustomResourceType, ContextType] {
+	b.inner = b.inner.WithNamespaceFallback(namespaces...)
+	return b
+}
+
+// WithNotFoundBackoff configures how the custom resource is requeued while the untyped dependency is not found,
+// see DependencyBuilder.WithNotFoundBackoff.
+func (b *UntypedDependencyBuilder[CustomResourceType, ContextType]) WithNotFoundBackoff(backoff DependencyBackoff) *UntypedDependencyBuilder[CustomResourceType, ContextType] {
+	b.inner = b.inner.WithNotFoundBackoff(backoff)
+	return b
+}
+
+// WithCircuitBreaker stops resolving the untyped dependency for resetAfter once it failed to resolve threshold times in a row,
+// see DependencyBuilder.WithCircuitBreaker.
+func (b *UntypedDependencyBuilder[CustomResourceType, ContextType]) WithCircuitBreaker(threshold int, resetAfter time.Duration) *UntypedDependencyBuilder[CustomResourceType, ContextType] {
+	b.inner = b.inner.WithCircuitBreaker(threshold, resetAfter)
+	return b
+}
@@ -0,0 +1,46 @@
+package ctrlfwk
+
+import (
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/version"
+)
+
+const (
+	// ConditionTypeDependencyVersionUnsupported is set on the custom resource when dependencies were skipped
+	// because the server does not serve the API version they require.
+	ConditionTypeDependencyVersionUnsupported = "DependencyVersionUnsupported"
+)
+
+// DependencyVersionUnsupportedError is returned when the server does not serve the API version
+// required by a dependency, see UntypedDependencyBuilder.WithAPIVersionConstraint.
+type DependencyVersionUnsupportedError struct {
+	DependencyID string
+	Constraint   string
+}
+
+func (e *DependencyVersionUnsupportedError) Error() string {
+	return fmt.Sprintf("dependency %s requires %s which is not served", e.DependencyID, e.Constraint)
+}
+
+// isAPIVersionSupported tells whether the server serves the kind in the group of the constraint,
+// with a version at least as recent as the one of the constraint.
+func isAPIVersionSupported(mapper meta.RESTMapper, kind string, constraint schema.GroupVersion) (bool, error) {
+	mappings, err := mapper.RESTMappings(schema.GroupKind{Group: constraint.Group, Kind: kind})
+	if meta.IsNoMatchError(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	for _, mapping := range mappings {
+		if version.CompareKubeAwareVersionStrings(mapping.GroupVersionKind.Version, constraint.Version) >= 0 {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
@@ -0,0 +1,103 @@
+package ctrlfwk_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/go-logr/logr"
+	ctrlfwk "github.com/u-ctf/controller-fwk"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestResolveDynamicDependenciesStep_APIVersionConstraint(t *testing.T) {
+	v1 := schema.GroupVersionKind{Group: "monitoring.coreos.com", Version: "v1", Kind: "ServiceMonitor"}
+	v1beta1 := schema.GroupVersionKind{Group: "monitoring.coreos.com", Version: "v1beta1", Kind: "ServiceMonitor"}
+
+	scheme := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(scheme); err != nil {
+		t.Fatalf("failed to build scheme: %v", err)
+	}
+	scheme.AddKnownTypes(schema.GroupVersion{Group: "test.ctrlfwk.com", Version: "v1"}, &conditionsCR{})
+
+	// The cluster runs an older version of the operator, only serving v1beta1
+	mapper := meta.NewDefaultRESTMapper([]schema.GroupVersion{v1.GroupVersion(), v1beta1.GroupVersion()})
+	mapper.Add(v1beta1, meta.RESTScopeNamespace)
+
+	monitor := &unstructured.Unstructured{}
+	monitor.SetGroupVersionKind(v1beta1)
+	monitor.SetName("monitor")
+	monitor.SetNamespace("default")
+
+	cr := &conditionsCR{ObjectMeta: metav1.ObjectMeta{Name: "cr", Namespace: "default"}}
+	reconciler := &declarationsReconciler{
+		Client: fake.NewClientBuilder().WithScheme(scheme).WithRESTMapper(mapper).WithObjects(cr, monitor).WithStatusSubresource(cr).Build(),
+	}
+	ctx := ctrlfwk.NewContext(context.Background(), reconciler)
+	ctx.SetCustomResource(cr)
+
+	var kind, constraint string
+	reconciler.dependencies = func(ctx conditionsContext) ([]ctrlfwk.GenericDependency[*conditionsCR, conditionsContext], error) {
+		dependency := ctrlfwk.NewUntypedDependencyBuilder(ctx, v1beta1.GroupVersion().WithKind(kind)).
+			WithName("monitor").
+			WithNamespace("default").
+			WithAPIVersionConstraint(constraint).
+			Build()
+		return []ctrlfwk.GenericDependency[*conditionsCR, conditionsContext]{dependency}, nil
+	}
+	step := ctrlfwk.NewResolveDynamicDependenciesStep(ctx, reconciler)
+
+	resolve := func(dependencyKind, dependencyConstraint string) error {
+		t.Helper()
+		kind, constraint = dependencyKind, dependencyConstraint
+		_, err := step.Step(ctx, logr.Discard(), ctrl.Request{}).Normal()
+		return err
+	}
+	assertSkipped := func(skipped bool) {
+		t.Helper()
+		condition := meta.FindStatusCondition(ctx.GetCustomResource().Status.Conditions, ctrlfwk.ConditionTypeDependencyVersionUnsupported)
+		if skipped != (condition != nil) {
+			t.Fatalf("expected the dependency to be skipped: %t, got condition %v", skipped, condition)
+		}
+	}
+
+	// An older version is served, the constraint requiring a more recent one skips the dependency
+	if err := resolve("ServiceMonitor", "monitoring.coreos.com/v1"); err != nil {
+		t.Fatalf("expected the dependency to be skipped without error, got %v", err)
+	}
+	assertSkipped(true)
+
+	// The served version satisfies an older constraint, the skipped dependency is resolved again
+	if err := resolve("ServiceMonitor", "monitoring.coreos.com/v1alpha1"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	assertSkipped(false)
+
+	// The kind is not served at all
+	if err := resolve("PodMonitor", "monitoring.coreos.com/v1alpha1"); err != nil {
+		t.Fatalf("expected the dependency to be skipped without error, got %v", err)
+	}
+	assertSkipped(true)
+
+	// Invalid constraints are errors, not skipped dependencies
+	err := resolve("ServiceMonitor", "monitoring.coreos.com/v1/extra")
+	var unsupportedErr *ctrlfwk.DependencyVersionUnsupportedError
+	if err == nil || errors.As(err, &unsupportedErr) {
+		t.Fatalf("expected an invalid constraint error, got %v", err)
+	}
+
+	// The version becomes served once the operator is upgraded
+	mapper.Add(v1, meta.RESTScopeNamespace)
+	if err := resolve("ServiceMonitor", "monitoring.coreos.com/v1"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	assertSkipped(false)
+}
@@ -2,11 +2,24 @@ package ctrlfwk
 
 const (
 	StepFindControllerCustomResource = "find controller custom resource"
+	StepValidateCustomResource       = "validate controller custom resource"
 	StepAddFinalizer                 = "adding finalizer %s"
 	StepExecuteFinalizer             = "executing finalizer %s"
+	StepFinalizer                    = "finalizer %s"
+	StepFinalizationProgress         = "finalization progress"
+	StepMigrateResource              = "migrate resource %s"
+	StepPhases                       = "phases"
+	StepPhase                        = "phase %s"
 	StepResolveDependency            = "resolve dependency %s"
 	StepResolveDependencies          = "resolve dependencies"
+	StepResolveListDependency        = "resolve list dependency %s"
 	StepReconcileResource            = "reconcile resource %s"
 	StepReconcileResources           = "reconcile resources"
+	StepPruneResources               = "prune resources"
+	StepValidateResources            = "validate resources"
+	StepReconcileAtomicResourceGroup = "reconcile atomic resource group"
+	StepSummaryStatus                = "summary status"
+	StepConditionCleanup             = "condition cleanup"
+	StepCommitStatus                 = "commit status"
 	StepEndReconciliation            = "end reconciliation"
 )
@@ -0,0 +1,28 @@
+package ctrlfwk
+
+import (
+	"reflect"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// equalityResource is implemented by the resources that can be built with WithEqualityFunc.
+type equalityResource interface {
+	// equalityFunc returns the function telling whether the mutated object is equal to the live one, nil if the resource has none.
+	equalityFunc() func(current, desired client.Object) bool
+}
+
+func getEqualityFunc(resource any) func(current, desired client.Object) bool {
+	if comparable, ok := resource.(equalityResource); ok {
+		return comparable.equalityFunc()
+	}
+	return nil
+}
+
+// restoreLiveObject resets obj to the live object, so that it is not updated.
+func restoreLiveObject(obj client.Object, live client.Object) {
+	if reflect.TypeOf(obj) != reflect.TypeOf(live) {
+		return
+	}
+	reflect.ValueOf(obj).Elem().Set(reflect.ValueOf(live.DeepCopyObject()).Elem())
+}
@@ -0,0 +1,112 @@
+package ctrlfwk
+
+import (
+	stderrors "errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// Sentinels matching the errors of the framework with errors.Is, whatever their details.
+var (
+	ErrDependencyNotFound  = stderrors.New("dependency not found")
+	ErrDependencyNotReady  = stderrors.New("dependency not ready")
+	ErrMutatorFailed       = stderrors.New("mutator failed")
+	ErrHookFailed          = stderrors.New("hook failed")
+	ErrFinalizationBlocked = stderrors.New("finalization blocked")
+)
+
+// ErrorClass returns the class of the first error of the framework in the chain of err, such as "DependencyNotFound",
+// or an empty string. It is meant to tag logs, spans and error reports.
+func ErrorClass(err error) string {
+	var classified interface{ ErrorClass() string }
+	if stderrors.As(err, &classified) {
+		return classified.ErrorClass()
+	}
+	return ""
+}
+
+// DependencyNotFoundError is returned when a dependency does not exist.
+type DependencyNotFoundError struct {
+	ID  string
+	Key types.NamespacedName
+	GVK schema.GroupVersionKind
+	Err error
+}
+
+func (e *DependencyNotFoundError) Error() string {
+	return fmt.Sprintf("dependency %s (%s %s) not found: %v", e.ID, e.GVK.Kind, e.Key, e.Err)
+}
+
+func (e *DependencyNotFoundError) Unwrap() error        { return e.Err }
+func (e *DependencyNotFoundError) Is(target error) bool { return target == ErrDependencyNotFound }
+func (e *DependencyNotFoundError) ErrorClass() string   { return "DependencyNotFound" }
+
+// DependencyNotReadyError is returned when a dependency waited for is not ready yet.
+type DependencyNotReadyError struct {
+	ID     string
+	Reason string
+}
+
+func (e *DependencyNotReadyError) Error() string {
+	if e.Reason == "" {
+		return fmt.Sprintf("dependency %s is not ready", e.ID)
+	}
+	return fmt.Sprintf("dependency %s is not ready: %s", e.ID, e.Reason)
+}
+
+func (e *DependencyNotReadyError) Is(target error) bool { return target == ErrDependencyNotReady }
+func (e *DependencyNotReadyError) ErrorClass() string   { return "DependencyNotReady" }
+
+// MutatorError is returned when the mutator of a resource fails.
+type MutatorError struct {
+	ResourceID string
+	Err        error
+}
+
+func (e *MutatorError) Error() string {
+	return fmt.Sprintf("failed to run mutator for resource %s: %v", e.ResourceID, e.Err)
+}
+
+func (e *MutatorError) Unwrap() error        { return e.Err }
+func (e *MutatorError) Is(target error) bool { return target == ErrMutatorFailed }
+func (e *MutatorError) ErrorClass() string   { return "MutatorFailed" }
+
+// FinalizationBlockedError is returned when the custom resource can't be finalized because
+// the deletion of some of its resources was vetoed, see SkipDeletion.
+type FinalizationBlockedError struct {
+	ResourceIDs []string
+	Err         error
+}
+
+func (e *FinalizationBlockedError) Error() string {
+	return fmt.Sprintf("finalization blocked by resource(s) %s: %v", strings.Join(e.ResourceIDs, ", "), e.Err)
+}
+
+func (e *FinalizationBlockedError) Unwrap() error        { return e.Err }
+func (e *FinalizationBlockedError) Is(target error) bool { return target == ErrFinalizationBlocked }
+func (e *FinalizationBlockedError) ErrorClass() string   { return "FinalizationBlocked" }
+
+// isTransientError tells whether err is expected to resolve by itself, in which case the reconciliation
+// is requeued rather than failed. Terminal errors are never transient.
+func isTransientError(err error) bool {
+	if IsTerminal(err) {
+		return false
+	}
+	return stderrors.Is(err, ErrDependencyNotFound) ||
+		stderrors.Is(err, ErrDependencyNotReady) ||
+		stderrors.Is(err, ErrFinalizationBlocked)
+}
+
+// resultFromError requeues the reconciliation on transient errors, and fails it on the others.
+func resultFromError(logger logr.Logger, err error) StepResult {
+	if isTransientError(err) {
+		logger.Info("Requeueing until the error resolves", "reason", err.Error(), "errorClass", ErrorClass(err))
+		return ResultRequeueIn(30 * time.Second).WithRequeueReason(RequeueReason(ErrorClass(err)))
+	}
+	return ResultInError(err)
+}
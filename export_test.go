@@ -0,0 +1,54 @@
+package ctrlfwk
+
+import (
+	"time"
+
+	"golang.org/x/time/rate"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+)
+
+// SetOutputFromFunc exposes setOutputFromFunc to the tests of the package.
+func SetOutputFromFunc[T client.Object](outputF func() T, obj client.Object) error {
+	return setOutputFromFunc(outputF, obj)
+}
+
+// MergeResourceMetadata exposes mergeResourceMetadata to the tests of the package, the reserved metadata
+// being taken from before.
+func MergeResourceMetadata(obj, before client.Object, metadata ImplementsResourceMetadata) error {
+	return mergeResourceMetadata(obj, getReservedMetadata(before), metadata)
+}
+
+// NextNotReadyBackoff exposes NotReadyBackoff.next to the tests of the package.
+func NextNotReadyBackoff(b NotReadyBackoff, cr client.Object, resourceID string, now time.Time) time.Duration {
+	return b.next(newResourceStateKey(cr, resourceID), now)
+}
+
+// TracksNotReadyAttempts tells whether the not ready attempts of the resource are still counted.
+func TracksNotReadyAttempts(cr client.Object, resourceID string) bool {
+	notReadyAttempts.lock.Lock()
+	defer notReadyAttempts.lock.Unlock()
+
+	_, ok := notReadyAttempts.attempts[newResourceStateKey(cr, resourceID)]
+	return ok
+}
+
+// NewRateLimitedEnqueueRequestsFromMapFunc exposes the handler of the rate limited dependency requeues
+// to the tests of the package.
+func NewRateLimitedEnqueueRequestsFromMapFunc(mapFunc handler.MapFunc, limiter *rate.Limiter) handler.EventHandler {
+	return &rateLimitedEnqueueRequestsFromMapFunc{mapFunc: mapFunc, limiter: limiter}
+}
+
+// RecordLoopUpdate exposes LoopDetector.recordUpdate to the tests of the package.
+func (d *LoopDetector) RecordLoopUpdate(cr client.Object, resourceID, hash string, now time.Time) time.Duration {
+	return d.recordUpdate(newResourceStateKey(cr, resourceID), hash, now)
+}
+
+// TracksLoopState tells whether the detector holds a state for the resource, whether it reset or not.
+func (d *LoopDetector) TracksLoopState(cr client.Object, resourceID string) bool {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	_, ok := d.states[newResourceStateKey(cr, resourceID)]
+	return ok
+}
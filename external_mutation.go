@@ -0,0 +1,95 @@
+package ctrlfwk
+
+import (
+	"strconv"
+
+	"github.com/go-logr/logr"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// ExternalMutationPolicy tells how a resource modified by another tool than the controller,
+// e.g. kubectl or a GitOps system, is reconciled, see ResourceBuilder.WithExternalMutationPolicy.
+type ExternalMutationPolicy string
+
+const (
+	// ExternalMutationPolicyRepair applies the desired state again, overwriting the external changes.
+	ExternalMutationPolicyRepair ExternalMutationPolicy = "Repair"
+	// ExternalMutationPolicyAccept keeps the external changes, the resource being left as it is in the cluster
+	// until the generation of the custom resource changes.
+	ExternalMutationPolicyAccept ExternalMutationPolicy = "Accept"
+	// ExternalMutationPolicyWarn keeps the external changes and reports them on every reconciliation,
+	// the resource being left as it is in the cluster until they are reverted.
+	ExternalMutationPolicyWarn ExternalMutationPolicy = "Warn"
+)
+
+// externalMutationResource is implemented by the resources that can be built with WithExternalMutationPolicy.
+type externalMutationResource interface {
+	externalMutationPolicy() ExternalMutationPolicy
+}
+
+// getExternalMutationPolicy returns the external mutation policy of the resource, empty when external mutations are not tracked.
+func getExternalMutationPolicy(resource any) ExternalMutationPolicy {
+	if tracked, ok := resource.(externalMutationResource); ok {
+		return tracked.externalMutationPolicy()
+	}
+	return ""
+}
+
+// externalMutationError is returned when a server-side apply conflicts with the fields of another manager.
+type externalMutationError struct {
+	live client.Object
+	err  error
+}
+
+func (e *externalMutationError) Error() string {
+	return e.err.Error()
+}
+
+func (e *externalMutationError) Unwrap() error {
+	return e.err
+}
+
+// externallyMutated tells if the live resource was modified since it was last reconciled,
+// its hash not matching the one recorded in the ctrlfwk.com/last-reconciled-hash annotation.
+func externallyMutated(live client.Object) (bool, error) {
+	recorded := GetAnnotation(live, AnnotationLastReconciledHash)
+	if recorded == "" {
+		return false, nil
+	}
+
+	hash, err := generationGateHash(live)
+	if err != nil {
+		return false, err
+	}
+	return hash != recorded, nil
+}
+
+// keepsMutation tells if the live resource, modified externally, is left as is by the policy.
+func (policy ExternalMutationPolicy) keepsMutation(live client.Object, generation int64) bool {
+	switch policy {
+	case ExternalMutationPolicyWarn:
+		return true
+	case ExternalMutationPolicyAccept:
+		return generation != 0 && GetAnnotation(live, AnnotationLastReconciledGeneration) == strconv.FormatInt(generation, 10)
+	default:
+		return false
+	}
+}
+
+// reportExternalMutation logs the external mutation of a resource and records an event when the reconciler
+// is a record.EventRecorder, accepted mutations being reported as normal events.
+func reportExternalMutation(reconciler any, logger logr.Logger, cr client.Object, resourceID string, policy ExternalMutationPolicy, kept bool) {
+	eventType, reason, message := "Warning", "ExternalMutationRepaired", "resource %s was modified externally, the changes are overwritten"
+	switch {
+	case kept && policy == ExternalMutationPolicyAccept:
+		eventType, reason, message = "Normal", "ExternalMutationAccepted", "resource %s was modified externally, the changes are kept"
+	case kept:
+		reason, message = "ExternalMutationDetected", "resource %s was modified externally, the changes are kept until reverted"
+	}
+
+	logger.Info("Resource was modified externally", "policy", policy, "kept", kept)
+	if recorder, ok := reconciler.(record.EventRecorder); ok {
+		recorder.Eventf(cr, eventType, reason, message, resourceID)
+	}
+}
@@ -0,0 +1,286 @@
+// Package faultinject provides a client.Client decorator failing or slowing down the requests matching rules,
+// such as returning a conflict on the status patches of a kind, to test how reconcilers behave when the API server
+// misbehaves without building a custom client wrapper each time.
+package faultinject
+
+import (
+	"context"
+	"fmt"
+	"math/rand/v2"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/rest"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+)
+
+// Verbs of the requests rules match on.
+const (
+	VerbGet         = "get"
+	VerbList        = "list"
+	VerbCreate      = "create"
+	VerbUpdate      = "update"
+	VerbPatch       = "patch"
+	VerbDelete      = "delete"
+	VerbDeleteAllOf = "deleteallof"
+)
+
+// Rule describes the requests to fault and how. The zero value of a field matches any request.
+type Rule struct {
+	// Verbs are the verbs of the requests to fault, e.g. VerbPatch.
+	Verbs []string
+	// GVK is the kind of the objects to fault, its empty fields matching any group, version or kind.
+	GVK schema.GroupVersionKind
+	// Subresource is the subresource of the requests to fault, e.g. "status". The requests to the object itself
+	// are only matched when it is empty.
+	Subresource string
+	// Name matches the name of the objects to fault, list and deleteallof requests having no name.
+	Name *regexp.Regexp
+
+	// Err is returned instead of sending the request, e.g. Conflict() or TooManyRequests(time.Second).
+	Err error
+	// Latency is waited for before sending the request, or returning Err.
+	Latency time.Duration
+	// Probability is the probability for a matching request to be faulted, 1 when zero.
+	Probability float64
+	// Times bounds how many requests are faulted, unbounded when zero.
+	Times int
+}
+
+// Conflict returns the error of the API server rejecting a write made on a stale version of an object.
+func Conflict() error {
+	return apierrors.NewConflict(schema.GroupResource{}, "", fmt.Errorf("the object has been modified, fault injected"))
+}
+
+// TooManyRequests returns the error of the API server throttling the client, retryAfter being given
+// to the client as the Retry-After delay.
+func TooManyRequests(retryAfter time.Duration) error {
+	return apierrors.NewTooManyRequests("too many requests, fault injected", int(retryAfter.Seconds()))
+}
+
+// ServerTimeout returns the error of the API server failing to complete a request in time.
+func ServerTimeout() error {
+	return apierrors.NewServerTimeout(schema.GroupResource{}, "", 1)
+}
+
+// Client is a client.Client faulting the requests matching its rules, the other requests being sent
+// to the client it decorates.
+type Client struct {
+	client.Client
+
+	mu       sync.Mutex
+	rules    []Rule
+	injected []int
+}
+
+var _ client.Client = &Client{}
+
+// NewClient decorates c to fault the requests matching rules. Each request is faulted by the first matching rule
+// whose probability draw succeeds, if any.
+func NewClient(c client.Client, rules ...Rule) *Client {
+	return &Client{
+		Client:   c,
+		rules:    rules,
+		injected: make([]int, len(rules)),
+	}
+}
+
+// NewClientFunc returns a client.NewClientFunc creating clients faulting the requests matching rules,
+// to be given to the options of a manager, e.g. the manager of an envtest suite:
+//
+//	mgr, err := ctrl.NewManager(cfg, ctrl.Options{
+//		NewClient: faultinject.NewClientFunc(faultinject.Rule{
+//			Verbs:       []string{faultinject.VerbPatch},
+//			Subresource: "status",
+//			Err:         faultinject.Conflict(),
+//			Probability: 0.2,
+//		}),
+//	})
+func NewClientFunc(rules ...Rule) client.NewClientFunc {
+	return func(config *rest.Config, options client.Options) (client.Client, error) {
+		c, err := client.New(config, options)
+		if err != nil {
+			return nil, err
+		}
+		return NewClient(c, rules...), nil
+	}
+}
+
+// Injected returns the number of requests faulted so far.
+func (c *Client) Injected() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	total := 0
+	for _, injected := range c.injected {
+		total += injected
+	}
+	return total
+}
+
+// fault returns the fault of the first rule matching the request, after waiting for its latency.
+func (c *Client) fault(ctx context.Context, verb string, obj any, subresource string, name string) error {
+	var gvk schema.GroupVersionKind
+	if runtimeObj, ok := obj.(client.Object); ok {
+		gvk, _ = apiutil.GVKForObject(runtimeObj, c.Scheme())
+	} else if list, ok := obj.(client.ObjectList); ok {
+		gvk, _ = apiutil.GVKForObject(list, c.Scheme())
+		gvk.Kind = strings.TrimSuffix(gvk.Kind, "List")
+	}
+
+	rule, ok := c.match(verb, gvk, subresource, name)
+	if !ok {
+		return nil
+	}
+
+	if rule.Latency > 0 {
+		timer := time.NewTimer(rule.Latency)
+		defer timer.Stop()
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+	return rule.Err
+}
+
+func (c *Client) match(verb string, gvk schema.GroupVersionKind, subresource string, name string) (Rule, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for i, rule := range c.rules {
+		if !rule.matches(verb, gvk, subresource, name) {
+			continue
+		}
+		if rule.Times > 0 && c.injected[i] >= rule.Times {
+			continue
+		}
+		if rule.Probability > 0 && rand.Float64() >= rule.Probability {
+			continue
+		}
+		c.injected[i]++
+		return rule, true
+	}
+	return Rule{}, false
+}
+
+func (r Rule) matches(verb string, gvk schema.GroupVersionKind, subresource string, name string) bool {
+	if len(r.Verbs) > 0 && !slices.Contains(r.Verbs, verb) {
+		return false
+	}
+	if r.Subresource != subresource {
+		return false
+	}
+	if (r.GVK.Group != "" && r.GVK.Group != gvk.Group) ||
+		(r.GVK.Version != "" && r.GVK.Version != gvk.Version) ||
+		(r.GVK.Kind != "" && r.GVK.Kind != gvk.Kind) {
+		return false
+	}
+	if r.Name != nil && !r.Name.MatchString(name) {
+		return false
+	}
+	return true
+}
+
+func (c *Client) Get(ctx context.Context, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+	if err := c.fault(ctx, VerbGet, obj, "", key.Name); err != nil {
+		return err
+	}
+	return c.Client.Get(ctx, key, obj, opts...)
+}
+
+func (c *Client) List(ctx context.Context, list client.ObjectList, opts ...client.ListOption) error {
+	if err := c.fault(ctx, VerbList, list, "", ""); err != nil {
+		return err
+	}
+	return c.Client.List(ctx, list, opts...)
+}
+
+func (c *Client) Create(ctx context.Context, obj client.Object, opts ...client.CreateOption) error {
+	if err := c.fault(ctx, VerbCreate, obj, "", obj.GetName()); err != nil {
+		return err
+	}
+	return c.Client.Create(ctx, obj, opts...)
+}
+
+func (c *Client) Update(ctx context.Context, obj client.Object, opts ...client.UpdateOption) error {
+	if err := c.fault(ctx, VerbUpdate, obj, "", obj.GetName()); err != nil {
+		return err
+	}
+	return c.Client.Update(ctx, obj, opts...)
+}
+
+func (c *Client) Patch(ctx context.Context, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+	if err := c.fault(ctx, VerbPatch, obj, "", obj.GetName()); err != nil {
+		return err
+	}
+	return c.Client.Patch(ctx, obj, patch, opts...)
+}
+
+func (c *Client) Delete(ctx context.Context, obj client.Object, opts ...client.DeleteOption) error {
+	if err := c.fault(ctx, VerbDelete, obj, "", obj.GetName()); err != nil {
+		return err
+	}
+	return c.Client.Delete(ctx, obj, opts...)
+}
+
+func (c *Client) DeleteAllOf(ctx context.Context, obj client.Object, opts ...client.DeleteAllOfOption) error {
+	if err := c.fault(ctx, VerbDeleteAllOf, obj, "", ""); err != nil {
+		return err
+	}
+	return c.Client.DeleteAllOf(ctx, obj, opts...)
+}
+
+func (c *Client) Status() client.SubResourceWriter {
+	return c.SubResource("status")
+}
+
+func (c *Client) SubResource(subResource string) client.SubResourceClient {
+	return &subResourceClient{
+		client:      c,
+		subResource: subResource,
+		inner:       c.Client.SubResource(subResource),
+	}
+}
+
+// subResourceClient faults the requests to a subresource matching the rules of its client.
+type subResourceClient struct {
+	client      *Client
+	subResource string
+	inner       client.SubResourceClient
+}
+
+func (c *subResourceClient) Get(ctx context.Context, obj client.Object, subResource client.Object, opts ...client.SubResourceGetOption) error {
+	if err := c.client.fault(ctx, VerbGet, obj, c.subResource, obj.GetName()); err != nil {
+		return err
+	}
+	return c.inner.Get(ctx, obj, subResource, opts...)
+}
+
+func (c *subResourceClient) Create(ctx context.Context, obj client.Object, subResource client.Object, opts ...client.SubResourceCreateOption) error {
+	if err := c.client.fault(ctx, VerbCreate, obj, c.subResource, obj.GetName()); err != nil {
+		return err
+	}
+	return c.inner.Create(ctx, obj, subResource, opts...)
+}
+
+func (c *subResourceClient) Update(ctx context.Context, obj client.Object, opts ...client.SubResourceUpdateOption) error {
+	if err := c.client.fault(ctx, VerbUpdate, obj, c.subResource, obj.GetName()); err != nil {
+		return err
+	}
+	return c.inner.Update(ctx, obj, opts...)
+}
+
+func (c *subResourceClient) Patch(ctx context.Context, obj client.Object, patch client.Patch, opts ...client.SubResourcePatchOption) error {
+	if err := c.client.fault(ctx, VerbPatch, obj, c.subResource, obj.GetName()); err != nil {
+		return err
+	}
+	return c.inner.Patch(ctx, obj, patch, opts...)
+}
@@ -0,0 +1,89 @@
+package faultinject
+
+import (
+	"context"
+	"regexp"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestClient_FaultsMatchingRequests(t *testing.T) {
+	ctx := context.Background()
+	cm := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "app-config", Namespace: "default"}}
+	other := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "other", Namespace: "default"}}
+
+	c := NewClient(fake.NewClientBuilder().WithObjects(cm, other).Build(),
+		Rule{
+			Verbs: []string{VerbGet},
+			GVK:   schema.GroupVersionKind{Kind: "ConfigMap"},
+			Name:  regexp.MustCompile(`^app-`),
+			Err:   Conflict(),
+			Times: 1,
+		},
+		Rule{
+			Verbs: []string{VerbList},
+			GVK:   schema.GroupVersionKind{Kind: "ConfigMap"},
+			Err:   TooManyRequests(2 * time.Second),
+		},
+	)
+
+	if err := c.Get(ctx, client.ObjectKeyFromObject(other), &corev1.ConfigMap{}); err != nil {
+		t.Fatalf("expected the request not matching the name to be sent, got %v", err)
+	}
+	if err := c.Get(ctx, client.ObjectKeyFromObject(cm), &corev1.ConfigMap{}); !apierrors.IsConflict(err) {
+		t.Fatalf("expected a conflict, got %v", err)
+	}
+	if err := c.Get(ctx, client.ObjectKeyFromObject(cm), &corev1.ConfigMap{}); err != nil {
+		t.Fatalf("expected the rule to fault a single request, got %v", err)
+	}
+	if err := c.Get(ctx, client.ObjectKeyFromObject(cm), &corev1.Secret{}); !apierrors.IsNotFound(err) {
+		t.Fatalf("expected the request of another kind to be sent, got %v", err)
+	}
+
+	err := c.List(ctx, &corev1.ConfigMapList{})
+	if seconds, ok := apierrors.SuggestsClientDelay(err); !apierrors.IsTooManyRequests(err) || !ok || seconds != 2 {
+		t.Fatalf("expected a 429 asking to retry after 2s, got %v", err)
+	}
+
+	if c.Injected() != 2 {
+		t.Fatalf("expected 2 faulted requests, got %d", c.Injected())
+	}
+}
+
+func TestClient_FaultsSubresources(t *testing.T) {
+	ctx := context.Background()
+	cm := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "app-config", Namespace: "default"}}
+
+	c := NewClient(fake.NewClientBuilder().WithObjects(cm).Build(), Rule{
+		Verbs:       []string{VerbPatch},
+		Subresource: "status",
+		Err:         ServerTimeout(),
+	})
+
+	patch := client.MergeFrom(cm.DeepCopy())
+	if err := c.Patch(ctx, cm, patch); err != nil {
+		t.Fatalf("expected the patch of the object to be sent, got %v", err)
+	}
+	if err := c.Status().Patch(ctx, cm, patch); !apierrors.IsServerTimeout(err) {
+		t.Fatalf("expected the status patch to time out, got %v", err)
+	}
+}
+
+func TestClient_Latency(t *testing.T) {
+	cm := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "app-config", Namespace: "default"}}
+	c := NewClient(fake.NewClientBuilder().WithObjects(cm).Build(), Rule{Latency: time.Hour})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	if err := c.Get(ctx, client.ObjectKeyFromObject(cm), &corev1.ConfigMap{}); err != context.DeadlineExceeded {
+		t.Fatalf("expected the slow request to be cancelled with its context, got %v", err)
+	}
+}
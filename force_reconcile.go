@@ -0,0 +1,95 @@
+package ctrlfwk
+
+import (
+	"context"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+)
+
+// AnnotationForceReconcile can be set on a custom resource to reconcile it fully right away, e.g. to pick up
+// a rotated credential, see ForceReconcile. It is removed by NewEndStep once the reconciliation succeeded.
+const AnnotationForceReconcile = "forcereconcile.ctrlfwk.com/trigger"
+
+// ForceReconcile requests a full reconciliation of the custom resource by setting the AnnotationForceReconcile
+// annotation on it. It can be called by other controllers, the same can be done by hand with:
+//
+//	kubectl annotate app my-app forcereconcile.ctrlfwk.com/trigger="$(date +%s)" --overwrite
+//
+// The resources built with WithGenerationGate are updated even if they were already reconciled for the
+// generation of the custom resource. The update event is let through NewNotPausedPredicate whatever its predicates,
+// ForceReconcilePredicate can be used to let it through other predicates.
+func ForceReconcile(ctx context.Context, c client.Client, cr client.Object) error {
+	before := cr.DeepCopyObject().(client.Object)
+
+	// The annotations of unstructured objects are copies, they are set back once modified
+	annotations := cr.GetAnnotations()
+	if annotations == nil {
+		annotations = make(map[string]string)
+	}
+	annotations[AnnotationForceReconcile] = time.Now().UTC().Format(time.RFC3339Nano)
+	cr.SetAnnotations(annotations)
+
+	return c.Patch(ctx, cr, client.MergeFrom(before))
+}
+
+// IsForcedReconcile tells if a full reconciliation of the custom resource was requested, see ForceReconcile.
+func IsForcedReconcile(cr client.Object) bool {
+	_, ok := cr.GetAnnotations()[AnnotationForceReconcile]
+	return ok
+}
+
+// ForceReconcilePredicate only lets through the updates setting or changing the AnnotationForceReconcile
+// annotation, to compose with predicates filtering them out, e.g. predicate.GenerationChangedPredicate.
+//
+// Example:
+//
+//	predicate.Or(predicate.GenerationChangedPredicate{}, ctrlfwk.ForceReconcilePredicate())
+func ForceReconcilePredicate() predicate.Predicate {
+	return predicate.Funcs{
+		CreateFunc:  func(event.CreateEvent) bool { return false },
+		DeleteFunc:  func(event.DeleteEvent) bool { return false },
+		GenericFunc: func(event.GenericEvent) bool { return false },
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			if e.ObjectOld == nil || e.ObjectNew == nil {
+				return false
+			}
+			newValue, forced := e.ObjectNew.GetAnnotations()[AnnotationForceReconcile]
+			return forced && e.ObjectOld.GetAnnotations()[AnnotationForceReconcile] != newValue
+		},
+	}
+}
+
+// clearForceReconcile removes the AnnotationForceReconcile annotation from the custom resource once it was
+// reconciled. The annotation is left as is when it was set again in the meantime, so that the new request
+// triggers another reconciliation.
+func clearForceReconcile[
+	ControllerResourceType ControllerCustomResource,
+	ContextType Context[ControllerResourceType],
+](
+	ctx ContextType,
+	reconciler Reconciler[ControllerResourceType],
+) error {
+	defer LockContext(ctx)()
+
+	cr := ctx.GetCustomResource()
+	if !IsForcedReconcile(cr) || IsFinalizing(cr) {
+		return nil
+	}
+
+	// The annotation is removed from a copy, the status of the custom resource in memory may not be patched yet
+	clean := ctx.GetCleanCustomResource()
+	patched := clean.DeepCopyObject().(ControllerResourceType)
+	annotations := patched.GetAnnotations()
+	delete(annotations, AnnotationForceReconcile)
+	patched.SetAnnotations(annotations)
+
+	err := reconciler.Patch(ctx, patched, client.MergeFromWithOptions(clean, client.MergeFromWithOptimisticLock{}))
+	if apierrors.IsConflict(err) {
+		return nil
+	}
+	return client.IgnoreNotFound(err)
+}
@@ -0,0 +1,83 @@
+package ctrlfwk
+
+import (
+	"context"
+	"strconv"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// AnnotationLastReconciledGeneration is set on the resources built with WithGenerationGate or WithExternalMutationPolicy,
+	// it holds the generation of the custom resource they were last reconciled for.
+	AnnotationLastReconciledGeneration = "ctrlfwk.com/last-reconciled-generation"
+
+	// AnnotationLastReconciledHash is set on the resources built with WithGenerationGate or WithExternalMutationPolicy,
+	// it holds the hash of the resource as it was last reconciled, to detect manual edits.
+	AnnotationLastReconciledHash = "ctrlfwk.com/last-reconciled-hash"
+)
+
+// generationGatedResource is implemented by the resources that can be built with WithGenerationGate.
+type generationGatedResource interface {
+	generationGated() bool
+}
+
+func isGenerationGated(resource any) bool {
+	gated, ok := resource.(generationGatedResource)
+	return ok && gated.generationGated()
+}
+
+// generationGateHolds tells if the live resource was last reconciled for generation and was not modified since.
+func generationGateHolds(live client.Object, generation int64) (bool, error) {
+	if generation == 0 || GetAnnotation(live, AnnotationLastReconciledGeneration) != strconv.FormatInt(generation, 10) {
+		return false, nil
+	}
+
+	hash, err := generationGateHash(live)
+	if err != nil {
+		return false, err
+	}
+	return GetAnnotation(live, AnnotationLastReconciledHash) == hash, nil
+}
+
+// stampGenerationGate records on the live resource the generation it was reconciled for and its hash,
+// the hash being computed on the live resource so that the fields defaulted by the API server are included.
+func stampGenerationGate(ctx context.Context, c client.Client, live client.Object, generation int64) error {
+	if generation == 0 {
+		return nil
+	}
+
+	before := live.DeepCopyObject().(client.Object)
+
+	// The annotations of unstructured objects are copies, they are set back once modified
+	annotations := live.GetAnnotations()
+	if annotations == nil {
+		annotations = make(map[string]string)
+	}
+	annotations[AnnotationLastReconciledGeneration] = strconv.FormatInt(generation, 10)
+	live.SetAnnotations(annotations)
+
+	hash, err := generationGateHash(live)
+	if err != nil {
+		return err
+	}
+	if GetAnnotation(before, AnnotationLastReconciledGeneration) == annotations[AnnotationLastReconciledGeneration] &&
+		GetAnnotation(before, AnnotationLastReconciledHash) == hash {
+		return nil
+	}
+	annotations[AnnotationLastReconciledHash] = hash
+	live.SetAnnotations(annotations)
+
+	return c.Patch(ctx, live, client.MergeFrom(before))
+}
+
+// generationGateHash hashes the resource, leaving out the hash annotation itself.
+func generationGateHash(obj client.Object) (string, error) {
+	obj = obj.DeepCopyObject().(client.Object)
+
+	annotations := obj.GetAnnotations()
+	delete(annotations, AnnotationLastReconciledHash)
+	obj.SetAnnotations(annotations)
+
+	return hashUpdatedObject(obj)
+}
@@ -0,0 +1,225 @@
+package ctrlfwk
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// ConditionTypeAPIVersionFallback is set on the custom resource when an untyped resource or dependency
+	// uses another version than its preferred one, because the preferred version is not served.
+	ConditionTypeAPIVersionFallback = "APIVersionFallback"
+)
+
+// GVKMigrationPolicy tells what to do with the objects written with a fallback GroupVersionKind
+// once a more preferred candidate gets served, see UntypedResourceBuilder.WithGVKCandidates.
+type GVKMigrationPolicy string
+
+const (
+	// GVKMigrationPolicyConvert starts using the preferred version for all operations, the API server
+	// converting the existing objects between the versions of a same group. This is the default.
+	GVKMigrationPolicyConvert GVKMigrationPolicy = "Convert"
+	// GVKMigrationPolicyRecreate deletes the objects written with a less preferred candidate of another group,
+	// before creating them with the preferred one. Candidates of the same group are converted as they are the same object.
+	GVKMigrationPolicyRecreate GVKMigrationPolicy = "Recreate"
+)
+
+// gvkNegotiationTTL is how long a negotiated GroupVersionKind is trusted before asking the RESTMapper again,
+// so that a more preferred version getting served is eventually picked up.
+const gvkNegotiationTTL = 5 * time.Minute
+
+type gvkNegotiation struct {
+	gvk        schema.GroupVersionKind
+	resolvedAt time.Time
+}
+
+var gvkNegotiations = struct {
+	sync.Mutex
+	entries map[string]gvkNegotiation
+}{entries: make(map[string]gvkNegotiation)}
+
+// NegotiateGVK returns the first candidate served according to the RESTMapper, candidates being sorted by preference.
+//
+// Results are cached for all the callers using the same candidates, and refreshed after a few minutes
+// or when InvalidateGVKNegotiation is called, typically after a NoKindMatchError.
+func NegotiateGVK(mapper meta.RESTMapper, candidates ...schema.GroupVersionKind) (schema.GroupVersionKind, error) {
+	if len(candidates) == 0 {
+		return schema.GroupVersionKind{}, fmt.Errorf("no GroupVersionKind candidate to negotiate")
+	}
+
+	key := gvkCandidatesKey(candidates)
+
+	gvkNegotiations.Lock()
+	defer gvkNegotiations.Unlock()
+
+	if entry, ok := gvkNegotiations.entries[key]; ok && time.Since(entry.resolvedAt) < gvkNegotiationTTL {
+		return entry.gvk, nil
+	}
+
+	for _, candidate := range candidates {
+		_, err := mapper.RESTMapping(candidate.GroupKind(), candidate.Version)
+		if meta.IsNoMatchError(err) {
+			continue
+		}
+		if err != nil {
+			return schema.GroupVersionKind{}, err
+		}
+
+		gvkNegotiations.entries[key] = gvkNegotiation{gvk: candidate, resolvedAt: time.Now()}
+		return candidate, nil
+	}
+
+	return schema.GroupVersionKind{}, &meta.NoKindMatchError{
+		GroupKind:        candidates[0].GroupKind(),
+		SearchedVersions: candidateVersions(candidates),
+	}
+}
+
+// InvalidateGVKNegotiation drops the cached negotiation of the candidates, the next NegotiateGVK asking the RESTMapper again.
+// When the RESTMapper is resettable, its discovery cache is reset as well.
+func InvalidateGVKNegotiation(mapper meta.RESTMapper, candidates ...schema.GroupVersionKind) {
+	gvkNegotiations.Lock()
+	delete(gvkNegotiations.entries, gvkCandidatesKey(candidates))
+	gvkNegotiations.Unlock()
+
+	if resettable, ok := mapper.(meta.ResettableRESTMapper); ok {
+		resettable.Reset()
+	}
+}
+
+func gvkCandidatesKey(candidates []schema.GroupVersionKind) string {
+	keys := make([]string, 0, len(candidates))
+	for _, candidate := range candidates {
+		keys = append(keys, candidate.String())
+	}
+	return strings.Join(keys, ";")
+}
+
+func candidateVersions(candidates []schema.GroupVersionKind) []string {
+	versions := make([]string, 0, len(candidates))
+	for _, candidate := range candidates {
+		versions = append(versions, candidate.GroupVersion().String())
+	}
+	return versions
+}
+
+// gvkNegotiator is implemented by the untyped resources and dependencies built with GVK candidates.
+type gvkNegotiator interface {
+	gvkCandidates() []schema.GroupVersionKind
+	gvkMigrationPolicy() GVKMigrationPolicy
+	setNegotiatedGVK(gvk schema.GroupVersionKind)
+}
+
+// negotiateObjectGVK negotiates the GroupVersionKind of an untyped resource or dependency before it is reconciled,
+// reflecting a fallback version on the custom resource status. The versions served are read from mapper, the RESTMapper
+// of the cluster of the object. It returns nil when there is nothing to negotiate.
+func negotiateObjectGVK[
+	ControllerResourceType ControllerCustomResource,
+	ContextType Context[ControllerResourceType],
+](
+	ctx ContextType,
+	reconciler Reconciler[ControllerResourceType],
+	mapper meta.RESTMapper,
+	id string,
+	negotiator gvkNegotiator,
+) (*schema.GroupVersionKind, error) {
+	candidates := negotiator.gvkCandidates()
+	if len(candidates) == 0 {
+		return nil, nil
+	}
+
+	gvk, err := NegotiateGVK(mapper, candidates...)
+	if err != nil {
+		return nil, err
+	}
+	negotiator.setNegotiatedGVK(gvk)
+
+	if err := setAPIVersionFallbackCondition(ctx, reconciler, id, candidates[0], gvk); err != nil {
+		return nil, err
+	}
+
+	return &gvk, nil
+}
+
+// migrateObjectGVK deletes the objects written with the less preferred candidates of another group than the negotiated one,
+// with the delete options of the resource.
+func migrateObjectGVK(ctx context.Context, c client.Client, mapper meta.RESTMapper, key client.ObjectKey, negotiated schema.GroupVersionKind, candidates []schema.GroupVersionKind, opts ...client.DeleteOption) error {
+	var lessPreferred bool
+	for _, candidate := range candidates {
+		if candidate == negotiated {
+			lessPreferred = true
+			continue
+		}
+		if !lessPreferred || candidate.GroupKind() == negotiated.GroupKind() {
+			continue
+		}
+		if _, err := mapper.RESTMapping(candidate.GroupKind(), candidate.Version); err != nil {
+			continue
+		}
+
+		stale := &metav1.PartialObjectMetadata{}
+		stale.SetGroupVersionKind(candidate)
+		stale.SetName(key.Name)
+		stale.SetNamespace(key.Namespace)
+		if err := c.Delete(ctx, stale, opts...); client.IgnoreNotFound(err) != nil {
+			return fmt.Errorf("failed to delete %s %s written with a previous version: %w", candidate, key, err)
+		}
+	}
+
+	return nil
+}
+
+// setAPIVersionFallbackCondition reflects an untyped object using another version than its preferred one on the
+// custom resource status. The condition is removed once the object it reports uses its preferred version again.
+func setAPIVersionFallbackCondition[
+	ControllerResourceType ControllerCustomResource,
+	ContextType Context[ControllerResourceType],
+](
+	ctx ContextType,
+	reconciler Reconciler[ControllerResourceType],
+	id string,
+	preferred schema.GroupVersionKind,
+	negotiated schema.GroupVersionKind,
+) error {
+	defer LockContext(ctx)()
+
+	cr := ctx.GetCustomResource()
+
+	existing, err := getStatusCondition(cr, ConditionTypeAPIVersionFallback)
+	if err != nil {
+		return err
+	}
+
+	prefix := fmt.Sprintf("%s uses ", id)
+
+	if negotiated == preferred {
+		if existing == nil || !strings.HasPrefix(existing.Message, prefix) {
+			return nil
+		}
+		_, err := applyCondition(ctx, reconciler, ConditionTypeAPIVersionFallback, nil)
+		return err
+	}
+
+	message := fmt.Sprintf("%s%s, %s is not served", prefix, negotiated.GroupVersion(), preferred.GroupVersion())
+	changed, err := applyCondition(ctx, reconciler, ConditionTypeAPIVersionFallback, &metav1.Condition{
+		Status:             metav1.ConditionTrue,
+		Reason:             "PreferredVersionNotServed",
+		Message:            message,
+		ObservedGeneration: cr.GetGeneration(),
+	})
+	if changed {
+		if recorder, ok := reconciler.(record.EventRecorder); ok {
+			recorder.Event(cr, "Warning", "PreferredVersionNotServed", message)
+		}
+	}
+	return err
+}
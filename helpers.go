@@ -1,8 +1,10 @@
 package ctrlfwk
 
 import (
+	"fmt"
 	"reflect"
 
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
@@ -35,3 +37,28 @@ func GetAnnotation(obj client.Object, key string) string {
 	}
 	return obj.GetAnnotations()[key]
 }
+
+// setOutputFromFunc copies obj into the object returned by outputF.
+// It returns an error if there is nowhere to copy the object to, instead of silently dropping it.
+func setOutputFromFunc[T client.Object](outputF func() T, obj client.Object) error {
+	if outputF == nil {
+		return fmt.Errorf("output func is nil")
+	}
+
+	target := outputF()
+	if any(target) == nil || reflect.ValueOf(target).IsNil() {
+		return fmt.Errorf("output func returned a nil %T, it must return an allocated object", target)
+	}
+
+	if reflect.TypeOf(target) != reflect.TypeOf(obj) {
+		return fmt.Errorf("output func returned a %T, expected a %T", target, obj)
+	}
+
+	reflect.ValueOf(target).Elem().Set(reflect.ValueOf(obj).Elem())
+	return nil
+}
+
+// qualifiedKind formats gvk as "group/version/Kind", or "version/Kind" for the core group.
+func qualifiedKind(gvk schema.GroupVersionKind) string {
+	return fmt.Sprintf("%s/%s", gvk.GroupVersion(), gvk.Kind)
+}
@@ -0,0 +1,39 @@
+package ctrlfwk_test
+
+import (
+	"testing"
+
+	ctrlfwk "github.com/u-ctf/controller-fwk"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func TestSetOutputFromFunc(t *testing.T) {
+	resolved := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "secret"}}
+
+	target := &corev1.Secret{}
+	if err := ctrlfwk.SetOutputFromFunc(func() *corev1.Secret { return target }, resolved); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if target.GetName() != "secret" {
+		t.Fatalf("expected the object to be copied into the output, got %v", target)
+	}
+	if target == resolved {
+		t.Fatal("expected the object to be copied, not shared")
+	}
+
+	if err := ctrlfwk.SetOutputFromFunc[*corev1.Secret](nil, resolved); err == nil {
+		t.Fatal("expected an error when the output func is nil")
+	}
+	if err := ctrlfwk.SetOutputFromFunc(func() *corev1.Secret { return nil }, resolved); err == nil {
+		t.Fatal("expected an error when the output func returns nil")
+	}
+	if err := ctrlfwk.SetOutputFromFunc(func() client.Object { return nil }, resolved); err == nil {
+		t.Fatal("expected an error when the output func returns a nil interface")
+	}
+	if err := ctrlfwk.SetOutputFromFunc(func() client.Object { return &corev1.ConfigMap{} }, resolved); err == nil {
+		t.Fatal("expected an error when the output func returns another type")
+	}
+}
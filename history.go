@@ -0,0 +1,293 @@
+package ctrlfwk
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+const (
+	// AnnotationDumpHistory can be set on a custom resource to have the ReconcileHistoryMiddleware dump its
+	// reconcile history into the ctrlfwk.com/reconcile-history annotation. It is removed once the history is dumped.
+	AnnotationDumpHistory = "ctrlfwk.com/dump-history"
+
+	// AnnotationReconcileHistory is set on custom resources by the ReconcileHistoryMiddleware when
+	// AnnotationDumpHistory is set, it holds the recorded reconciliations of the custom resource as JSON.
+	AnnotationReconcileHistory = "ctrlfwk.com/reconcile-history"
+)
+
+// DefaultReconcileHistorySize is the number of reconciliations recorded per custom resource by default.
+const DefaultReconcileHistorySize = 20
+
+// ReconcileTrigger tells what most likely triggered a reconciliation, as guessed by the ReconcileHistory.
+type ReconcileTrigger string
+
+const (
+	// ReconcileTriggerCustomResourceChanged reconciliations follow a change of the custom resource,
+	// or are its first reconciliation since the controller started.
+	ReconcileTriggerCustomResourceChanged ReconcileTrigger = "CustomResourceChanged"
+	// ReconcileTriggerRequeue reconciliations were requested by the previous one, returning an error or requeueing.
+	ReconcileTriggerRequeue ReconcileTrigger = "Requeue"
+	// ReconcileTriggerBackgroundRefresh reconciliations were enqueued by a resource built with WithBackgroundReconcile.
+	ReconcileTriggerBackgroundRefresh ReconcileTrigger = "BackgroundRefresh"
+	// ReconcileTriggerOther reconciliations follow an event of a resource or dependency, or a periodic resync.
+	ReconcileTriggerOther ReconcileTrigger = "Other"
+)
+
+// ReconcileRecord describes a past reconciliation of a custom resource.
+type ReconcileRecord struct {
+	Time         time.Time           `json:"time"`
+	Trigger      ReconcileTrigger    `json:"trigger"`
+	Duration     time.Duration       `json:"duration"`
+	Resources    []ResourceOperation `json:"resources,omitempty"`
+	Outcome      string              `json:"outcome"`
+	Error        string              `json:"error,omitempty"`
+	RequeueAfter time.Duration       `json:"requeueAfter,omitempty"`
+
+	// resourceVersion of the custom resource when it was reconciled, to tell its changes from the other triggers
+	resourceVersion string
+}
+
+// ResourceOperation is the operation done on a resource during a reconciliation.
+type ResourceOperation struct {
+	ID        string                         `json:"id"`
+	Operation controllerutil.OperationResult `json:"operation"`
+	Ready     bool                           `json:"ready"`
+	Error     string                         `json:"error,omitempty"`
+}
+
+// ReconcileHistory keeps the last reconciliations of each custom resource in memory, to tell what the controller
+// did to a custom resource without going through the logs of its pods. It is filled by the ReconcileHistoryMiddleware
+// and served by its Handler, it is safe for concurrent use.
+//
+// The history of a custom resource is forgotten once it is deleted. It only holds the errors of the reconciliations,
+// which are redacted by the steps as the other messages about sensitive resources, see Redactor.
+type ReconcileHistory struct {
+	lock    sync.RWMutex
+	size    int
+	records map[types.NamespacedName][]ReconcileRecord
+}
+
+// NewReconcileHistory creates a ReconcileHistory recording the last size reconciliations of each custom resource,
+// DefaultReconcileHistorySize if size is not positive.
+func NewReconcileHistory(size int) *ReconcileHistory {
+	if size <= 0 {
+		size = DefaultReconcileHistorySize
+	}
+	return &ReconcileHistory{
+		size:    size,
+		records: make(map[types.NamespacedName][]ReconcileRecord),
+	}
+}
+
+// Get returns the recorded reconciliations of the custom resource, from the oldest to the most recent.
+func (h *ReconcileHistory) Get(key types.NamespacedName) []ReconcileRecord {
+	h.lock.RLock()
+	defer h.lock.RUnlock()
+
+	records := make([]ReconcileRecord, len(h.records[key]))
+	copy(records, h.records[key])
+	return records
+}
+
+// Forget drops the recorded reconciliations of the custom resource.
+func (h *ReconcileHistory) Forget(key types.NamespacedName) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	delete(h.records, key)
+}
+
+// record adds the reconciliation to the history of the custom resource, dropping the oldest one when full.
+func (h *ReconcileHistory) record(key types.NamespacedName, record ReconcileRecord) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	records := append(h.records[key], record)
+	if len(records) > h.size {
+		// The oldest records are copied out so that the dropped ones can be garbage collected
+		records = append([]ReconcileRecord(nil), records[len(records)-h.size:]...)
+	}
+	h.records[key] = records
+}
+
+// trigger guesses what triggered the reconciliation of the custom resource at resourceVersion, started at now.
+func (h *ReconcileHistory) trigger(key types.NamespacedName, resourceVersion string, now time.Time) ReconcileTrigger {
+	h.lock.RLock()
+	defer h.lock.RUnlock()
+
+	records := h.records[key]
+	if len(records) == 0 {
+		return ReconcileTriggerCustomResourceChanged
+	}
+	last := records[len(records)-1]
+	switch {
+	case last.resourceVersion != resourceVersion:
+		return ReconcileTriggerCustomResourceChanged
+	case last.Error != "":
+		return ReconcileTriggerRequeue
+	case last.RequeueAfter > 0 && !now.Before(last.Time.Add(last.Duration+last.RequeueAfter)):
+		return ReconcileTriggerRequeue
+	}
+	return ReconcileTriggerOther
+}
+
+// Handler serves the history of the custom resources as JSON, under prefix followed by the namespace and name of
+// the custom resource, or only its name if it is cluster scoped. It is meant to be served by the metrics server of
+// the manager, behind a flag as it exposes what the controller does.
+//
+// Example:
+//
+//	if enableHistoryEndpoint {
+//		err := mgr.AddMetricsServerExtraHandler("/debug/history/apps/", history.Handler("/debug/history/apps/"))
+//	}
+//	// curl http://localhost:8080/debug/history/apps/default/my-app
+func (h *ReconcileHistory) Handler(prefix string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		var key types.NamespacedName
+		parts := strings.Split(strings.Trim(strings.TrimPrefix(r.URL.Path, prefix), "/"), "/")
+		switch len(parts) {
+		case 1:
+			key.Name = parts[0]
+		case 2:
+			key.Namespace, key.Name = parts[0], parts[1]
+		}
+		if key.Name == "" {
+			http.Error(w, "expected a path ending with <namespace>/<name> or <name>", http.StatusBadRequest)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(h.Get(key)); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+	})
+}
+
+// ReconcileHistoryMiddleware records the reconciliations of the custom resources into history: when they happened,
+// what likely triggered them, how long they took, the operations done on the resources and their outcome.
+//
+// The history of a custom resource is dumped into its ctrlfwk.com/reconcile-history annotation when its
+// ctrlfwk.com/dump-history annotation is set, e.g. with kubectl annotate, see ReconcileHistory.Handler to serve it instead.
+//
+// Example:
+//
+//	history := ctrlfwk.NewReconcileHistory(ctrlfwk.DefaultReconcileHistorySize)
+//	stepper := ctrlfwk.NewStepperFor(ctx, logger).
+//		WithMiddleware(ctrlfwk.ReconcileHistoryMiddleware[*v1.App, AppContext](reconciler, history)).
+//		...
+func ReconcileHistoryMiddleware[
+	ControllerResourceType ControllerCustomResource,
+	ContextType Context[ControllerResourceType],
+](
+	reconciler Reconciler[ControllerResourceType],
+	history *ReconcileHistory,
+) Middleware[ControllerResourceType, ContextType] {
+	return MiddlewareFunc[ControllerResourceType, ContextType](func(next ReconcileFunc[ControllerResourceType, ContextType]) ReconcileFunc[ControllerResourceType, ContextType] {
+		return func(ctx ContextType, req ctrl.Request) (ctrl.Result, error) {
+			start := time.Now()
+			result, err := next(ctx, req)
+
+			cr := ctx.GetCustomResource()
+			if cr.GetName() == "" {
+				// The custom resource was not found, it was deleted
+				history.Forget(req.NamespacedName)
+				return result, err
+			}
+
+			trigger := history.trigger(req.NamespacedName, cr.GetResourceVersion(), start)
+			if IsBackgroundRefresh(ctx) {
+				trigger = ReconcileTriggerBackgroundRefresh
+			}
+
+			record := ReconcileRecord{
+				Time:            start,
+				Trigger:         trigger,
+				Duration:        time.Since(start),
+				RequeueAfter:    result.RequeueAfter,
+				resourceVersion: cr.GetResourceVersion(),
+			}
+			switch {
+			case err != nil:
+				record.Outcome = "Error"
+				record.Error = err.Error()
+			case result.RequeueAfter > 0 || result.Requeue:
+				record.Outcome = "Requeue"
+			default:
+				record.Outcome = "Success"
+			}
+
+			report := ctx.GetReconcileReport()
+			report.mu.Lock()
+			for _, resource := range report.Resources {
+				operation := ResourceOperation{ID: resource.ID, Operation: resource.Operation, Ready: resource.Ready}
+				if resource.Error != nil {
+					operation.Error = resource.Error.Error()
+				}
+				record.Resources = append(record.Resources, operation)
+			}
+			report.mu.Unlock()
+
+			history.record(req.NamespacedName, record)
+
+			if _, ok := cr.GetAnnotations()[AnnotationDumpHistory]; ok {
+				if dumpErr := dumpReconcileHistory(ctx, reconciler, history.Get(req.NamespacedName)); client.IgnoreNotFound(dumpErr) != nil {
+					if err == nil {
+						return result, errors.Wrap(dumpErr, "failed to dump reconcile history")
+					}
+					logf.FromContext(ctx).Error(dumpErr, "Failed to dump reconcile history")
+				}
+			}
+
+			return result, err
+		}
+	})
+}
+
+// dumpReconcileHistory writes the history into the annotation of the custom resource, removing the annotation
+// that requested it.
+func dumpReconcileHistory[
+	ControllerResourceType ControllerCustomResource,
+	ContextType Context[ControllerResourceType],
+](
+	ctx ContextType,
+	reconciler Reconciler[ControllerResourceType],
+	records []ReconcileRecord,
+) error {
+	defer LockContext(ctx)()
+
+	data, err := json.Marshal(records)
+	if err != nil {
+		return err
+	}
+
+	// Patch from the clean object so that pending changes of the custom resource are not sent along
+	cleanObject := ctx.GetCleanCustomResource()
+	modifiedObject := cleanObject.DeepCopyObject().(ControllerResourceType)
+	SetAnnotation(modifiedObject, AnnotationReconcileHistory, string(data))
+	setOrRemoveAnnotation(modifiedObject, AnnotationDumpHistory, "")
+
+	if err := reconciler.Patch(ctx, modifiedObject, client.MergeFrom(cleanObject)); err != nil {
+		return err
+	}
+
+	cr := ctx.GetCustomResource()
+	SetAnnotation(cr, AnnotationReconcileHistory, string(data))
+	setOrRemoveAnnotation(cr, AnnotationDumpHistory, "")
+
+	return nil
+}
@@ -0,0 +1,90 @@
+package ctrlfwk
+
+import "fmt"
+
+// HookErrorPolicy defines how the framework reacts when a resource hook returns an error.
+type HookErrorPolicy string
+
+const (
+	// HookErrorPolicyAbort fails the reconciliation of the resource with the hook error.
+	// This is the default policy.
+	HookErrorPolicyAbort HookErrorPolicy = "Abort"
+	// HookErrorPolicyContinueAndAggregate keeps reconciling the other resources, then
+	// returns all the hook errors joined together at the end of the step.
+	HookErrorPolicyContinueAndAggregate HookErrorPolicy = "ContinueAndAggregate"
+	// HookErrorPolicyContinueAndIgnore logs the hook error and carries on as if the hook succeeded.
+	HookErrorPolicyContinueAndIgnore HookErrorPolicy = "ContinueAndIgnore"
+)
+
+const (
+	// ConditionTypeResourceHooksFailed is set on the custom resource when hooks using
+	// the HookErrorPolicyContinueAndAggregate policy failed during reconciliation.
+	ConditionTypeResourceHooksFailed = "ResourceHooksFailed"
+	// ConditionTypeDeletionSkipped is set on the custom resource when a BeforeDelete hook
+	// vetoed the deletion of a resource by returning a SkipDeletionError, its message listing every vetoed resource.
+	ConditionTypeDeletionSkipped = "DeletionSkipped"
+	// ConditionTypeValidationFailed is set on the custom resource when a pre-mutate validator
+	// rejected it by returning a ValidationError, its message listing every rejecting resource.
+	ConditionTypeValidationFailed = "ValidationFailed"
+)
+
+// HookError is returned when a resource hook fails, it keeps track of the resource
+// and the hook that failed so the errors can be aggregated and reported.
+type HookError struct {
+	ResourceID string
+	Hook       string
+	Err        error
+}
+
+func (e *HookError) Error() string {
+	return fmt.Sprintf("failed to run %s hook for resource %s: %v", e.Hook, e.ResourceID, e.Err)
+}
+
+func (e *HookError) Unwrap() error {
+	return e.Err
+}
+
+func (e *HookError) Is(target error) bool { return target == ErrHookFailed }
+func (e *HookError) ErrorClass() string   { return "HookFailed" }
+
+// continuedHookError marks the hook errors of the HookErrorPolicyContinueAndAggregate policy,
+// so that the other resources keep being reconciled.
+type continuedHookError struct {
+	*HookError
+}
+
+func (e *continuedHookError) Unwrap() error {
+	return e.HookError
+}
+
+// SkipDeletionError can be returned by a BeforeDelete hook to veto the deletion of a resource.
+// Unlike other errors, it does not fail the reconciliation: the custom resource gets a DeletionSkipped
+// condition and the deletion is attempted again later on.
+type SkipDeletionError struct {
+	Reason string
+}
+
+func (e *SkipDeletionError) Error() string {
+	return fmt.Sprintf("deletion skipped: %s", e.Reason)
+}
+
+// SkipDeletion returns a SkipDeletionError with the given reason.
+func SkipDeletion(reason string) error {
+	return &SkipDeletionError{Reason: reason}
+}
+
+// ValidationError can be returned by a pre-mutate validator to reject the custom resource.
+// Unlike other errors, it is not retried: the custom resource gets a ValidationFailed condition
+// and is not reconciled further until it changes.
+type ValidationError struct {
+	Reason string
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("validation failed: %s", e.Reason)
+}
+
+// NewValidationError returns a ValidationError with the given reason.
+func NewValidationError(reason string) error {
+	return &ValidationError{Reason: reason}
+}
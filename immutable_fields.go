@@ -0,0 +1,190 @@
+package ctrlfwk
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	utiljson "k8s.io/apimachinery/pkg/util/json"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// AnnotationImmutableFields is set on resources built with WithImmutableFields, it holds the values
+	// last written by the framework for each immutable field, so that external modifications can be detected.
+	AnnotationImmutableFields = "ctrlfwk.com/immutable-fields"
+)
+
+type immutableFields struct {
+	paths    []string
+	recreate bool
+}
+
+// immutableFieldsResource is implemented by the resources that can be built with WithImmutableFields.
+type immutableFieldsResource interface {
+	immutableFields() *immutableFields
+}
+
+// immutableFieldDrift is an immutable field modified by someone else than the framework.
+type immutableFieldDrift struct {
+	path     string
+	live     any
+	restored any
+}
+
+func (d immutableFieldDrift) String() string {
+	return fmt.Sprintf("field %s was modified externally to %s, restoring %s", d.path, encodeFieldValue(d.live), encodeFieldValue(d.restored))
+}
+
+// enforce restores on obj the values of the immutable fields that were modified externally on live, unless the mutator
+// set them itself, then records the values of obj. live is nil when the resource does not exist yet.
+func (f *immutableFields) enforce(obj client.Object, live client.Object) ([]immutableFieldDrift, error) {
+	objContent, err := toUnstructuredContent(obj)
+	if err != nil {
+		return nil, err
+	}
+
+	var drifts []immutableFieldDrift
+	if live != nil {
+		liveContent, err := toUnstructuredContent(live)
+		if err != nil {
+			return nil, err
+		}
+
+		recorded := make(map[string]any)
+		if value := GetAnnotation(live, AnnotationImmutableFields); value != "" {
+			if err := utiljson.Unmarshal([]byte(value), &recorded); err != nil {
+				return nil, fmt.Errorf("invalid %s annotation: %w", AnnotationImmutableFields, err)
+			}
+		}
+
+		for _, path := range f.paths {
+			ours, hasRecorded := recorded[path]
+			if !hasRecorded {
+				continue
+			}
+
+			liveValue, _, err := getFieldValue(liveContent, path)
+			if err != nil {
+				return nil, err
+			}
+			if fieldValuesEqual(liveValue, ours) {
+				continue
+			}
+
+			drifts = append(drifts, immutableFieldDrift{path: path, live: liveValue, restored: ours})
+
+			// The mutator setting another value is a change of ours, not a drift to correct
+			desired, found, err := getFieldValue(objContent, path)
+			if err != nil {
+				return nil, err
+			}
+			if found && !fieldValuesEqual(desired, liveValue) {
+				drifts[len(drifts)-1].restored = desired
+				continue
+			}
+			if err := setFieldValue(objContent, path, ours); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	values := make(map[string]any, len(f.paths))
+	for _, path := range f.paths {
+		value, found, err := getFieldValue(objContent, path)
+		if err != nil {
+			return nil, err
+		}
+		if found {
+			values[path] = value
+		}
+	}
+	encoded, err := json.Marshal(values)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(drifts) > 0 {
+		if err := fromUnstructuredContent(objContent, obj); err != nil {
+			return nil, err
+		}
+	}
+	SetAnnotation(obj, AnnotationImmutableFields, string(encoded))
+
+	return drifts, nil
+}
+
+func toUnstructuredContent(obj client.Object) (map[string]any, error) {
+	if u, ok := obj.(*unstructured.Unstructured); ok {
+		return u.Object, nil
+	}
+	return runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+}
+
+func fromUnstructuredContent(content map[string]any, obj client.Object) error {
+	if u, ok := obj.(*unstructured.Unstructured); ok {
+		u.Object = content
+		return nil
+	}
+	return runtime.DefaultUnstructuredConverter.FromUnstructured(content, obj)
+}
+
+// parseFieldPath splits a JSON pointer (RFC 6901) such as /metadata/labels/app.kubernetes.io~1name into its fields.
+func parseFieldPath(path string) ([]string, error) {
+	if !strings.HasPrefix(path, "/") || path == "/" {
+		return nil, fmt.Errorf("invalid field path %q, expected a JSON pointer such as /spec/clusterIP", path)
+	}
+	fields := strings.Split(path[1:], "/")
+	for i, field := range fields {
+		fields[i] = strings.ReplaceAll(strings.ReplaceAll(field, "~1", "/"), "~0", "~")
+	}
+	return fields, nil
+}
+
+func getFieldValue(content map[string]any, path string) (any, bool, error) {
+	fields, err := parseFieldPath(path)
+	if err != nil {
+		return nil, false, err
+	}
+	value, found, err := unstructured.NestedFieldCopy(content, fields...)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to read field %s: %w", path, err)
+	}
+	return value, found, nil
+}
+
+func setFieldValue(content map[string]any, path string, value any) error {
+	fields, err := parseFieldPath(path)
+	if err != nil {
+		return err
+	}
+	if value == nil {
+		unstructured.RemoveNestedField(content, fields...)
+		return nil
+	}
+	if err := unstructured.SetNestedField(content, runtime.DeepCopyJSONValue(value), fields...); err != nil {
+		return fmt.Errorf("failed to restore field %s: %w", path, err)
+	}
+	return nil
+}
+
+// fieldValuesEqual compares field values through their JSON encoding, as numbers may be decoded with different types.
+func fieldValuesEqual(a, b any) bool {
+	return encodeFieldValue(a) == encodeFieldValue(b)
+}
+
+func encodeFieldValue(value any) string {
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprintf("%v", value)
+	}
+	return string(encoded)
+}
+
+// isImmutableFieldConflict tells whether err is the API server rejecting the change of a field it holds immutable.
+func isImmutableFieldConflict(err error) bool {
+	return apierrors.IsInvalid(err) && strings.Contains(err.Error(), "immutable")
+}
@@ -5,6 +5,7 @@ import (
 	"unsafe"
 
 	"github.com/go-logr/logr"
+	"k8s.io/client-go/util/workqueue"
 	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
@@ -17,6 +18,9 @@ import (
 
 type InstrumentedBuilder struct {
 	manager manager.Manager
+	options controller.TypedOptions[reconcile.Request]
+	// forPredicates filter the events of the object given to For, see WithForPredicates
+	forPredicates []predicate.Predicate
 
 	*builder.Builder
 	Instrumenter
@@ -42,23 +46,34 @@ func forceSetNewController[request comparable](obj *builder.TypedBuilder[request
 // InstrumentedControllerManagedBy returns a new controller builder that will be started by the provided Manager.
 func InstrumentedControllerManagedBy(t Instrumenter, m manager.Manager) *InstrumentedBuilder {
 	blder := builder.TypedControllerManagedBy[reconcile.Request](m)
-	blder.WithOptions(controller.TypedOptions[reconcile.Request]{
-		NewQueue: t.NewQueue(m),
-	})
 	forceSetNewController(blder, NewTracerControllerFunc(t))
 
-	return &InstrumentedBuilder{
+	instrumented := &InstrumentedBuilder{
 		Instrumenter: t,
 		Builder:      blder,
 		manager:      m,
 	}
+	instrumented.applyOptions()
+
+	return instrumented
 }
 
 func (blder *InstrumentedBuilder) For(object client.Object, opts ...builder.ForOption) *InstrumentedBuilder {
+	if len(blder.forPredicates) > 0 {
+		opts = append(opts, builder.WithPredicates(blder.forPredicates...))
+	}
 	blder.Builder = blder.Builder.For(object, opts...)
 	return blder
 }
 
+// WithForPredicates adds predicates filtering the events of the object given to For, in addition to the
+// predicates given to For itself. Unlike WithEventFilter, the events of the owned and watched objects are not filtered.
+// It must be called before For.
+func (blder *InstrumentedBuilder) WithForPredicates(predicates ...predicate.Predicate) *InstrumentedBuilder {
+	blder.forPredicates = append(blder.forPredicates, predicates...)
+	return blder
+}
+
 func (blder *InstrumentedBuilder) Build(r reconcile.TypedReconciler[reconcile.Request]) (controller.TypedController[reconcile.Request], error) {
 	return blder.Builder.Build(NewInstrumentedReconciler(blder.Instrumenter, r))
 }
@@ -102,8 +117,32 @@ func (blder *InstrumentedBuilder) WithLogConstructor(logConstructor func(*reconc
 	return blder
 }
 
+// WithOptions sets the options of the controller, the instrumented queue always being used.
+// It replaces the options set by WithMaxConcurrentReconciles and WithRateLimiter.
 func (blder *InstrumentedBuilder) WithOptions(options controller.TypedOptions[reconcile.Request]) *InstrumentedBuilder {
+	blder.options = options
+	blder.applyOptions()
+	return blder
+}
+
+// WithMaxConcurrentReconciles sets the maximum number of concurrent reconciles of the controller.
+func (blder *InstrumentedBuilder) WithMaxConcurrentReconciles(n int) *InstrumentedBuilder {
+	blder.options.MaxConcurrentReconciles = n
+	blder.applyOptions()
+	return blder
+}
+
+// WithRateLimiter sets the rate limiter of the controller queue, used by the instrumented queue
+// instead of the default controller rate limiter.
+func (blder *InstrumentedBuilder) WithRateLimiter(rateLimiter workqueue.TypedRateLimiter[reconcile.Request]) *InstrumentedBuilder {
+	blder.options.RateLimiter = rateLimiter
+	blder.applyOptions()
+	return blder
+}
+
+// applyOptions sets the accumulated options on the builder, which only keeps the last ones it was given.
+func (blder *InstrumentedBuilder) applyOptions() {
+	options := blder.options
 	options.NewQueue = blder.Instrumenter.NewQueue(blder.manager)
 	blder.Builder = blder.Builder.WithOptions(options)
-	return blder
 }
@@ -0,0 +1,275 @@
+package instrument
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"sync"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/embedded"
+	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace"
+	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/ext"
+	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/tracer"
+)
+
+// dataDogStepOperation is the operation name of the spans nested in the spans of the DataDogInstrumentation,
+// such as the spans of the steps, their name being used as resource.
+const dataDogStepOperation = "controller.step"
+
+// DataDogInstrumentation is a Tracer reporting the spans to DataDog APM with dd-trace-go.
+//
+// The reconciliations are reported as controller.reconcile root spans, with the namespace and name of the
+// custom resource as resource, and the spans started from their context, such as the spans of the steps,
+// as controller.step child spans. Failed spans are finished with tracer.WithError.
+type DataDogInstrumentation struct{}
+
+var _ Tracer = &DataDogInstrumentation{}
+
+type dataDogConfig struct {
+	agentAddr  string
+	sampleRate float64
+	env        string
+	version    string
+}
+
+// DataDogOption configures the DataDogInstrumentation.
+type DataDogOption func(*dataDogConfig)
+
+// WithDataDogAgentAddr sets the address of the trace agent of DataDog, defaults to the DD_AGENT_HOST and
+// DD_TRACE_AGENT_PORT environment variables, or "localhost:8126".
+func WithDataDogAgentAddr(agentAddr string) DataDogOption {
+	return func(c *dataDogConfig) {
+		c.agentAddr = agentAddr
+	}
+}
+
+// WithDataDogSampleRate sets the rate of the traces kept, between 0 and 1, defaults to keeping every trace.
+func WithDataDogSampleRate(rate float64) DataDogOption {
+	return func(c *dataDogConfig) {
+		c.sampleRate = rate
+	}
+}
+
+// WithDataDogEnv sets the environment the spans are tagged with, e.g. "production".
+func WithDataDogEnv(env string) DataDogOption {
+	return func(c *dataDogConfig) {
+		c.env = env
+	}
+}
+
+// WithDataDogVersion sets the version of the service the spans are tagged with.
+func WithDataDogVersion(version string) DataDogOption {
+	return func(c *dataDogConfig) {
+		c.version = version
+	}
+}
+
+// NewDataDogInstrumentation starts the global tracer of dd-trace-go for the given service and returns a Tracer
+// reporting the spans with it.
+//
+// You can use it as such:
+//
+//	datadog := instrument.NewDataDogInstrumentation("my-operator",
+//		instrument.WithDataDogEnv("production"),
+//		instrument.WithDataDogSampleRate(0.1),
+//	)
+//	defer datadog.Shutdown()
+//
+//	instrumenter := instrument.NewInstrumenter(mgr).
+//		WithTracer(datadog).
+//		Build()
+func NewDataDogInstrumentation(serviceName string, options ...DataDogOption) *DataDogInstrumentation {
+	config := &dataDogConfig{
+		sampleRate: 1,
+	}
+	for _, option := range options {
+		option(config)
+	}
+
+	startOptions := []tracer.StartOption{
+		tracer.WithService(serviceName),
+		tracer.WithSampler(tracer.NewRateSampler(config.sampleRate)),
+	}
+	if config.agentAddr != "" {
+		startOptions = append(startOptions, tracer.WithAgentAddr(config.agentAddr))
+	}
+	if config.env != "" {
+		startOptions = append(startOptions, tracer.WithEnv(config.env))
+	}
+	if config.version != "" {
+		startOptions = append(startOptions, tracer.WithServiceVersion(config.version))
+	}
+	tracer.Start(startOptions...)
+
+	return &DataDogInstrumentation{}
+}
+
+func (t *DataDogInstrumentation) StartSpan(globalCtx *context.Context, localCtx context.Context, spanName string, opts ...trace.SpanStartOption) (context.Context, trace.Span) {
+	config := trace.NewSpanStartConfig(opts...)
+
+	// The custom resource of the reconciliation is the resource of the span
+	var name, namespace string
+	for _, kv := range config.Attributes() {
+		switch kv.Key {
+		case "k8s.resource.name":
+			name = kv.Value.AsString()
+		case "k8s.resource.namespace":
+			namespace = kv.Value.AsString()
+		}
+	}
+	resourceName := spanName
+	if name != "" {
+		resourceName = name
+		if namespace != "" {
+			resourceName = namespace + "/" + name
+		}
+	}
+
+	return startDataDogSpan(localCtx, "controller."+spanName, resourceName, config)
+}
+
+// Shutdown flushes the remaining spans and stops the global tracer of dd-trace-go.
+func (t *DataDogInstrumentation) Shutdown() {
+	tracer.Stop()
+}
+
+// startDataDogSpan starts a span nested in the DataDog span of ctx, if any, the returned context holding it
+// both as the current span of OpenTelemetry and of dd-trace-go.
+func startDataDogSpan(ctx context.Context, operationName string, resourceName string, config trace.SpanConfig) (context.Context, trace.Span) {
+	startOptions := []ddtrace.StartSpanOption{tracer.ResourceName(resourceName)}
+	if !config.Timestamp().IsZero() {
+		startOptions = append(startOptions, tracer.StartTime(config.Timestamp()))
+	}
+	for _, kv := range config.Attributes() {
+		startOptions = append(startOptions, tracer.Tag(string(kv.Key), kv.Value.AsInterface()))
+	}
+
+	// The parent of a span is the current span of OpenTelemetry, the spans of other tracers being ignored
+	if parent, ok := trace.SpanFromContext(ctx).(*dataDogSpan); ok && !config.NewRoot() {
+		startOptions = append(startOptions, tracer.ChildOf(parent.span.Context()))
+	}
+
+	span, ctx := tracer.StartSpanFromContext(ctx, operationName, startOptions...)
+	ddSpan := &dataDogSpan{span: span}
+	return trace.ContextWithSpan(ctx, ddSpan), ddSpan
+}
+
+// dataDogSpan is a span of dd-trace-go seen as a span of OpenTelemetry.
+type dataDogSpan struct {
+	embedded.Span
+	span ddtrace.Span
+
+	mu    sync.Mutex
+	err   error
+	ended bool
+}
+
+var _ trace.Span = &dataDogSpan{}
+
+func (s *dataDogSpan) End(options ...trace.SpanEndOption) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.ended {
+		return
+	}
+	s.ended = true
+
+	config := trace.NewSpanEndConfig(options...)
+	var finishOptions []ddtrace.FinishOption
+	if !config.Timestamp().IsZero() {
+		finishOptions = append(finishOptions, tracer.FinishTime(config.Timestamp()))
+	}
+	if s.err != nil {
+		finishOptions = append(finishOptions, tracer.WithError(s.err))
+	}
+	s.span.Finish(finishOptions...)
+}
+
+// AddEvent is not supported by the spans of dd-trace-go, the events are dropped.
+func (s *dataDogSpan) AddEvent(string, ...trace.EventOption) {}
+
+// AddLink is not supported by the spans of dd-trace-go, the links are dropped.
+func (s *dataDogSpan) AddLink(trace.Link) {}
+
+func (s *dataDogSpan) IsRecording() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return !s.ended
+}
+
+// RecordError records the error the span is finished with.
+func (s *dataDogSpan) RecordError(err error, _ ...trace.EventOption) {
+	if err == nil {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.err = err
+}
+
+// SpanContext returns the IDs of the span of dd-trace-go, its 64 bits trace ID being the low bits of the trace ID.
+func (s *dataDogSpan) SpanContext() trace.SpanContext {
+	var traceID trace.TraceID
+	binary.BigEndian.PutUint64(traceID[8:], s.span.Context().TraceID())
+	var spanID trace.SpanID
+	binary.BigEndian.PutUint64(spanID[:], s.span.Context().SpanID())
+
+	return trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    traceID,
+		SpanID:     spanID,
+		TraceFlags: trace.FlagsSampled,
+	})
+}
+
+// SetStatus records the description of an error status as the error the span is finished with,
+// unless an error was recorded with RecordError.
+func (s *dataDogSpan) SetStatus(code codes.Code, description string) {
+	if code != codes.Error {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.err == nil {
+		s.err = errors.New(description)
+	}
+}
+
+func (s *dataDogSpan) SetName(name string) {
+	s.span.SetTag(ext.ResourceName, name)
+}
+
+func (s *dataDogSpan) SetAttributes(kv ...attribute.KeyValue) {
+	for _, attr := range kv {
+		s.span.SetTag(string(attr.Key), attr.Value.AsInterface())
+	}
+}
+
+func (s *dataDogSpan) TracerProvider() trace.TracerProvider {
+	return &dataDogTracerProvider{}
+}
+
+// dataDogTracerProvider provides the tracer of the child spans of a dataDogSpan.
+type dataDogTracerProvider struct {
+	embedded.TracerProvider
+}
+
+func (p *dataDogTracerProvider) Tracer(string, ...trace.TracerOption) trace.Tracer {
+	return &dataDogTracer{}
+}
+
+type dataDogTracer struct {
+	embedded.Tracer
+}
+
+func (t *dataDogTracer) Start(ctx context.Context, spanName string, opts ...trace.SpanStartOption) (context.Context, trace.Span) {
+	return startDataDogSpan(ctx, dataDogStepOperation, spanName, trace.NewSpanStartConfig(opts...))
+}
@@ -0,0 +1,53 @@
+package instrument
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/ext"
+	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/mocktracer"
+)
+
+func TestDataDogInstrumentation_StartSpan(t *testing.T) {
+	mt := mocktracer.Start()
+	defer mt.Stop()
+
+	datadog := &DataDogInstrumentation{}
+	globalCtx := context.Background()
+	ctx, span := datadog.StartSpan(&globalCtx, context.Background(), "reconcile", trace.WithAttributes(
+		attribute.String("k8s.resource.name", "app"),
+		attribute.String("k8s.resource.namespace", "default"),
+	))
+
+	// The spans of the steps are started from the provider of the current span
+	_, child := trace.SpanFromContext(ctx).TracerProvider().Tracer("steps").Start(ctx, "ReconcileResource")
+	child.RecordError(errors.New("failed"))
+	child.SetStatus(codes.Error, "failed")
+	child.End()
+	span.End()
+
+	finished := mt.FinishedSpans()
+	if len(finished) != 2 {
+		t.Fatalf("expected 2 finished spans, got %d", len(finished))
+	}
+	step, root := finished[0], finished[1]
+	if root.OperationName() != "controller.reconcile" || root.Tag(ext.ResourceName) != "default/app" {
+		t.Fatalf("unexpected root span %s on %v", root.OperationName(), root.Tag(ext.ResourceName))
+	}
+	if step.OperationName() != dataDogStepOperation || step.Tag(ext.ResourceName) != "ReconcileResource" {
+		t.Fatalf("unexpected step span %s on %v", step.OperationName(), step.Tag(ext.ResourceName))
+	}
+	if step.ParentID() != root.SpanID() {
+		t.Fatal("expected the step span to be nested in the reconcile span")
+	}
+	if step.Tag(ext.ErrorMsg) != "failed" {
+		t.Fatalf("expected the step span to be finished with its error, got %v", step.Tag(ext.ErrorMsg))
+	}
+	if root.Tag(ext.ErrorMsg) != nil {
+		t.Fatalf("expected the root span not to be in error, got %v", root.Tag(ext.ErrorMsg))
+	}
+}
@@ -10,6 +10,7 @@ import (
 	"weak"
 
 	"github.com/go-logr/logr"
+	"github.com/prometheus/client_golang/prometheus"
 	"go.opentelemetry.io/otel/trace"
 	"k8s.io/client-go/util/workqueue"
 	"k8s.io/utils/ptr"
@@ -47,6 +48,7 @@ type instrumenter struct {
 	ctxCache        map[string]weak.Pointer[context.Context]
 	ctxCacheReverse map[*context.Context]string
 	newLogger       func(ctx context.Context) logr.Logger
+	queueMetrics    prometheus.Registerer
 
 	Tracer
 }
@@ -60,25 +62,43 @@ func (t *instrumenter) InstrumentRequestHandler(handler handler.TypedEventHandle
 }
 
 func (t *instrumenter) NewQueue(mgr ctrl.Manager) func(controllerName string, rateLimiter workqueue.TypedRateLimiter[reconcile.Request]) workqueue.TypedRateLimitingInterface[reconcile.Request] {
-	return func(controllerName string, _ workqueue.TypedRateLimiter[reconcile.Request]) workqueue.TypedRateLimitingInterface[reconcile.Request] {
+	return func(controllerName string, rateLimiter workqueue.TypedRateLimiter[reconcile.Request]) workqueue.TypedRateLimitingInterface[reconcile.Request] {
 		ratelimiter := workqueue.DefaultTypedControllerRateLimiter[*reconcile.Request]()
+		if rateLimiter != nil {
+			ratelimiter = &pointerRateLimiter[reconcile.Request]{inner: rateLimiter}
+		}
 
 		if ptr.Deref(mgr.GetControllerOptions().UsePriorityQueue, false) {
-			t.queue = NewInstrumentedQueue(priorityqueue.New(controllerName, func(o *priorityqueue.Opts[*reconcile.Request]) {
+			t.queue = t.withQueueMetrics(mgr, controllerName, NewInstrumentedQueue(priorityqueue.New(controllerName, func(o *priorityqueue.Opts[*reconcile.Request]) {
 				o.Log = mgr.GetLogger().WithValues("controller", controllerName)
 				o.RateLimiter = ratelimiter
-			}))
+			})))
 
 			return t.queue
 		}
 
-		t.queue = NewInstrumentedQueue(workqueue.NewTypedRateLimitingQueueWithConfig(ratelimiter, workqueue.TypedRateLimitingQueueConfig[*reconcile.Request]{
+		t.queue = t.withQueueMetrics(mgr, controllerName, NewInstrumentedQueue(workqueue.NewTypedRateLimitingQueueWithConfig(ratelimiter, workqueue.TypedRateLimitingQueueConfig[*reconcile.Request]{
 			Name: controllerName,
-		}))
+		})))
 		return t.queue
 	}
 }
 
+// withQueueMetrics records the metrics of the queue when configured, the queue working without them if they can't be registered.
+func (t *instrumenter) withQueueMetrics(mgr ctrl.Manager, controllerName string, queue *InstrumentedQueue[reconcile.Request]) *InstrumentedQueue[reconcile.Request] {
+	if t.queueMetrics == nil {
+		return queue
+	}
+
+	metrics, err := NewQueueMetrics(t.queueMetrics)
+	if err != nil {
+		mgr.GetLogger().Error(err, "Failed to register work-queue metrics", "controller", controllerName)
+		return queue
+	}
+
+	return queue.WithMetrics(metrics, controllerName)
+}
+
 func (t *instrumenter) GetContextForRequest(req reconcile.Request) (*context.Context, bool) {
 	var defaultContext = context.Background()
 	if t.queue.internalQueue == nil {
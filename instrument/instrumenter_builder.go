@@ -5,12 +5,14 @@ import (
 	"weak"
 
 	"github.com/go-logr/logr"
+	"github.com/prometheus/client_golang/prometheus"
 	ctrl "sigs.k8s.io/controller-runtime"
 )
 
 type instrumenterBuilder struct {
-	tracer    Tracer
-	newLogger func(ctx context.Context) logr.Logger
+	tracer       Tracer
+	newLogger    func(ctx context.Context) logr.Logger
+	queueMetrics prometheus.Registerer
 
 	mgr ctrl.Manager
 }
@@ -36,12 +38,21 @@ func (b *instrumenterBuilder) WithLoggerFunc(l func(ctx context.Context) logr.Lo
 	return b
 }
 
+// WithQueueMetrics records the work-queue metrics of the instrumented queues on registerer, such as
+// ctrlfwk_queue_depth, labeled with the name of their controller. The controller-runtime metrics.Registry
+// can be used to expose them along with the metrics of the manager.
+func (b *instrumenterBuilder) WithQueueMetrics(registerer prometheus.Registerer) *instrumenterBuilder {
+	b.queueMetrics = registerer
+	return b
+}
+
 func (b *instrumenterBuilder) Build() Instrumenter {
 	return &instrumenter{
 		mgr:             b.mgr,
 		ctxCache:        make(map[string]weak.Pointer[context.Context]),
 		ctxCacheReverse: make(map[*context.Context]string),
 		newLogger:       b.newLogger,
+		queueMetrics:    b.queueMetrics,
 
 		Tracer: b.tracer,
 	}
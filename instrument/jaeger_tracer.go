@@ -0,0 +1,114 @@
+package instrument
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const jaegerTracerName = "github.com/u-ctf/controller-fwk"
+
+// JaegerInstrumentation is a Tracer exporting spans to a Jaeger backend using the OTLP protocol.
+// Jaeger natively accepts OTLP, on port 4318 for HTTP.
+type JaegerInstrumentation struct {
+	provider *sdktrace.TracerProvider
+	tracer   trace.Tracer
+}
+
+var _ Tracer = &JaegerInstrumentation{}
+
+type jaegerConfig struct {
+	insecure   bool
+	sampler    sdktrace.Sampler
+	propagator propagation.TextMapPropagator
+}
+
+// JaegerOption configures the JaegerInstrumentation.
+type JaegerOption func(*jaegerConfig)
+
+// WithJaegerSampler sets the sampler used for the spans, defaults to sampling every span
+// unless the parent span says otherwise.
+func WithJaegerSampler(sampler sdktrace.Sampler) JaegerOption {
+	return func(c *jaegerConfig) {
+		c.sampler = sampler
+	}
+}
+
+// WithJaegerPropagator sets the propagator registered globally, defaults to W3C trace context and baggage.
+func WithJaegerPropagator(propagator propagation.TextMapPropagator) JaegerOption {
+	return func(c *jaegerConfig) {
+		c.propagator = propagator
+	}
+}
+
+// WithJaegerInsecure controls whether the exporter uses plain HTTP to reach the collector, defaults to true.
+func WithJaegerInsecure(insecure bool) JaegerOption {
+	return func(c *jaegerConfig) {
+		c.insecure = insecure
+	}
+}
+
+// NewJaegerInstrumentation creates a Tracer sending spans to the Jaeger collector at agentHost (e.g. "localhost:4318").
+// The spans are tagged with the given service name.
+//
+// You can use it as such:
+//
+//	jaeger, err := instrument.NewJaegerInstrumentation("my-operator", "localhost:4318")
+//	if err != nil {
+//		return err
+//	}
+//	defer jaeger.Shutdown(context.Background())
+//
+//	instrumenter := instrument.NewInstrumenter(mgr).
+//		WithTracer(jaeger).
+//		Build()
+func NewJaegerInstrumentation(serviceName, agentHost string, options ...JaegerOption) (*JaegerInstrumentation, error) {
+	config := &jaegerConfig{
+		insecure:   true,
+		sampler:    sdktrace.ParentBased(sdktrace.AlwaysSample()),
+		propagator: propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}),
+	}
+	for _, option := range options {
+		option(config)
+	}
+
+	exporterOptions := []otlptracehttp.Option{
+		otlptracehttp.WithEndpoint(agentHost),
+	}
+	if config.insecure {
+		exporterOptions = append(exporterOptions, otlptracehttp.WithInsecure())
+	}
+
+	exporter, err := otlptracehttp.New(context.Background(), exporterOptions...)
+	if err != nil {
+		return nil, err
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithSampler(config.sampler),
+		sdktrace.WithResource(resource.NewSchemaless(semconv.ServiceName(serviceName))),
+	)
+
+	otel.SetTextMapPropagator(config.propagator)
+
+	return &JaegerInstrumentation{
+		provider: provider,
+		tracer:   provider.Tracer(jaegerTracerName),
+	}, nil
+}
+
+func (t *JaegerInstrumentation) StartSpan(globalCtx *context.Context, localCtx context.Context, spanName string, opts ...trace.SpanStartOption) (context.Context, trace.Span) {
+	return t.tracer.Start(localCtx, spanName, opts...)
+}
+
+// Shutdown flushes the remaining spans and stops the exporter.
+func (t *JaegerInstrumentation) Shutdown(ctx context.Context) error {
+	return t.provider.Shutdown(ctx)
+}
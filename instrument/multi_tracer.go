@@ -0,0 +1,153 @@
+package instrument
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/embedded"
+)
+
+// MultiInstrumentation is a Tracer sending the spans to several tracers, e.g. to DataDog and Sentry at once.
+//
+// The spans started from the context of its spans, such as the spans of the steps, are sent to all the tracers,
+// each span being nested in the span of its own tracer. The span context of a span is the one of the first tracer.
+//
+// You can use it as such:
+//
+//	instrumenter := instrument.NewInstrumenter(mgr).
+//		WithTracer(instrument.NewMultiInstrumentation(datadog, instrument.NewSentryTracer(sentryTracer))).
+//		Build()
+type MultiInstrumentation struct {
+	tracers []Tracer
+}
+
+var _ Tracer = &MultiInstrumentation{}
+
+// NewMultiInstrumentation creates a Tracer sending the spans to all the given tracers.
+func NewMultiInstrumentation(tracers ...Tracer) *MultiInstrumentation {
+	return &MultiInstrumentation{tracers: tracers}
+}
+
+func (t *MultiInstrumentation) StartSpan(globalCtx *context.Context, localCtx context.Context, spanName string, opts ...trace.SpanStartOption) (context.Context, trace.Span) {
+	if len(t.tracers) == 0 {
+		return localCtx, trace.SpanFromContext(localCtx)
+	}
+
+	return startMultiSpan(localCtx, len(t.tracers), func(i int, ctx context.Context) (context.Context, trace.Span) {
+		return t.tracers[i].StartSpan(globalCtx, ctx, spanName, opts...)
+	})
+}
+
+// startMultiSpan starts a span with each of the n tracers, nested in the span of the same tracer of the current
+// multiSpan, the contexts returned by the tracers being chained so that their values are all kept.
+func startMultiSpan(ctx context.Context, n int, start func(i int, ctx context.Context) (context.Context, trace.Span)) (context.Context, trace.Span) {
+	parent, _ := trace.SpanFromContext(ctx).(*multiSpan)
+
+	span := &multiSpan{spans: make([]trace.Span, n)}
+	for i := range n {
+		if parent != nil && i < len(parent.spans) {
+			ctx = trace.ContextWithSpan(ctx, parent.spans[i])
+		}
+		ctx, span.spans[i] = start(i, ctx)
+	}
+
+	return trace.ContextWithSpan(ctx, span), span
+}
+
+// multiSpan is a span of every tracer of a MultiInstrumentation.
+type multiSpan struct {
+	embedded.Span
+	spans []trace.Span
+}
+
+var _ trace.Span = &multiSpan{}
+
+func (s *multiSpan) End(options ...trace.SpanEndOption) {
+	for _, span := range s.spans {
+		span.End(options...)
+	}
+}
+
+func (s *multiSpan) AddEvent(name string, options ...trace.EventOption) {
+	for _, span := range s.spans {
+		span.AddEvent(name, options...)
+	}
+}
+
+func (s *multiSpan) AddLink(link trace.Link) {
+	for _, span := range s.spans {
+		span.AddLink(link)
+	}
+}
+
+func (s *multiSpan) IsRecording() bool {
+	for _, span := range s.spans {
+		if span.IsRecording() {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *multiSpan) RecordError(err error, options ...trace.EventOption) {
+	for _, span := range s.spans {
+		span.RecordError(err, options...)
+	}
+}
+
+func (s *multiSpan) SpanContext() trace.SpanContext {
+	return s.spans[0].SpanContext()
+}
+
+func (s *multiSpan) SetStatus(code codes.Code, description string) {
+	for _, span := range s.spans {
+		span.SetStatus(code, description)
+	}
+}
+
+func (s *multiSpan) SetName(name string) {
+	for _, span := range s.spans {
+		span.SetName(name)
+	}
+}
+
+func (s *multiSpan) SetAttributes(kv ...attribute.KeyValue) {
+	for _, span := range s.spans {
+		span.SetAttributes(kv...)
+	}
+}
+
+func (s *multiSpan) TracerProvider() trace.TracerProvider {
+	providers := make([]trace.TracerProvider, len(s.spans))
+	for i, span := range s.spans {
+		providers[i] = span.TracerProvider()
+	}
+	return &multiTracerProvider{providers: providers}
+}
+
+// multiTracerProvider provides the tracers of the child spans of a multiSpan.
+type multiTracerProvider struct {
+	embedded.TracerProvider
+	providers []trace.TracerProvider
+}
+
+func (p *multiTracerProvider) Tracer(name string, options ...trace.TracerOption) trace.Tracer {
+	tracers := make([]trace.Tracer, len(p.providers))
+	for i, provider := range p.providers {
+		tracers[i] = provider.Tracer(name, options...)
+	}
+	return &multiTracer{tracers: tracers}
+}
+
+type multiTracer struct {
+	embedded.Tracer
+	tracers []trace.Tracer
+}
+
+func (t *multiTracer) Start(ctx context.Context, spanName string, opts ...trace.SpanStartOption) (context.Context, trace.Span) {
+	return startMultiSpan(ctx, len(t.tracers), func(i int, ctx context.Context) (context.Context, trace.Span) {
+		return t.tracers[i].Start(ctx, spanName, opts...)
+	})
+}
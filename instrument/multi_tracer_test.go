@@ -0,0 +1,63 @@
+package instrument
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// recordingTracer is a Tracer recording its ended spans.
+type recordingTracer struct {
+	recorder *tracetest.SpanRecorder
+	tracer   trace.Tracer
+}
+
+func newRecordingTracer() *recordingTracer {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	return &recordingTracer{recorder: recorder, tracer: provider.Tracer("test")}
+}
+
+func (t *recordingTracer) StartSpan(_ *context.Context, localCtx context.Context, spanName string, opts ...trace.SpanStartOption) (context.Context, trace.Span) {
+	return t.tracer.Start(localCtx, spanName, opts...)
+}
+
+func TestMultiInstrumentation_StartSpan(t *testing.T) {
+	first, second := newRecordingTracer(), newRecordingTracer()
+	multi := NewMultiInstrumentation(first, second)
+
+	globalCtx := context.Background()
+	ctx, span := multi.StartSpan(&globalCtx, context.Background(), "reconcile")
+
+	// The spans of the steps are started from the provider of the current span
+	_, child := trace.SpanFromContext(ctx).TracerProvider().Tracer("steps").Start(ctx, "step")
+	child.SetStatus(codes.Error, "failed")
+	child.RecordError(errors.New("failed"))
+	child.End()
+	span.End()
+
+	for _, tracer := range []*recordingTracer{first, second} {
+		ended := tracer.recorder.Ended()
+		if len(ended) != 2 {
+			t.Fatalf("expected both spans to be recorded by every tracer, got %d", len(ended))
+		}
+		step, root := ended[0], ended[1]
+		if step.Name() != "step" || root.Name() != "reconcile" {
+			t.Fatalf("unexpected spans %s and %s", step.Name(), root.Name())
+		}
+		if step.Parent().SpanID() != root.SpanContext().SpanID() {
+			t.Fatal("expected the step span to be nested in the reconcile span of its tracer")
+		}
+		if step.Status().Code != codes.Error {
+			t.Fatalf("expected the step span to be in error, got %v", step.Status())
+		}
+	}
+	if span.SpanContext().TraceID() != first.recorder.Ended()[1].SpanContext().TraceID() {
+		t.Fatal("expected the span context of the first tracer")
+	}
+}
@@ -15,6 +15,10 @@ import (
 type encapsulatedItem[T comparable] struct {
 	Context *context.Context
 	Object  weak.Pointer[T]
+
+	// addedAt and startedAt are when the item became available and when it was picked, for the queue metrics
+	addedAt   time.Time
+	startedAt time.Time
 }
 
 type InstrumentedQueue[T comparable] struct {
@@ -24,6 +28,8 @@ type InstrumentedQueue[T comparable] struct {
 	internalQueue  workqueue.TypedRateLimitingInterface[*T]
 
 	metamap map[T]*encapsulatedItem[T]
+
+	metrics *queueObserver
 }
 
 var _ priorityqueue.PriorityQueue[reconcile.Request] = InstrumentedQueue[reconcile.Request]{}
@@ -49,6 +55,18 @@ func (q InstrumentedQueue[T]) WithContext(ctx *context.Context) *InstrumentedQue
 		currentContext: ctx,
 		internalQueue:  q.internalQueue,
 		metamap:        q.metamap,
+		metrics:        q.metrics,
+	}
+}
+
+// WithMetrics records the work-queue metrics of the queue, labeled with the name of its controller.
+func (q InstrumentedQueue[T]) WithMetrics(metrics *QueueMetrics, controller string) *InstrumentedQueue[T] {
+	return &InstrumentedQueue[T]{
+		lock:           q.lock,
+		currentContext: q.currentContext,
+		internalQueue:  q.internalQueue,
+		metamap:        q.metamap,
+		metrics:        metrics.forController(controller),
 	}
 }
 
@@ -88,8 +106,10 @@ func (q InstrumentedQueue[T]) Add(item T) {
 		q.metamap[item] = &encapsulatedItem[T]{
 			Context: q.currentContext,
 			Object:  weakPointerToItem,
+			addedAt: time.Now(),
 		}
 	}
+	q.metrics.add(q.internalQueue.Len())
 }
 
 func (q InstrumentedQueue[T]) AddAfter(item T, duration time.Duration) {
@@ -105,8 +125,10 @@ func (q InstrumentedQueue[T]) AddAfter(item T, duration time.Duration) {
 		q.metamap[item] = &encapsulatedItem[T]{
 			Context: q.currentContext,
 			Object:  weakPointerToItem,
+			addedAt: time.Now().Add(duration),
 		}
 	}
+	q.metrics.add(q.internalQueue.Len())
 }
 
 func (q InstrumentedQueue[T]) AddRateLimited(item T) {
@@ -122,8 +144,11 @@ func (q InstrumentedQueue[T]) AddRateLimited(item T) {
 		q.metamap[item] = &encapsulatedItem[T]{
 			Context: q.currentContext,
 			Object:  weakPointerToItem,
+			addedAt: time.Now(),
 		}
 	}
+	q.metrics.retry()
+	q.metrics.add(q.internalQueue.Len())
 }
 
 func (q InstrumentedQueue[T]) Done(item T) {
@@ -133,6 +158,7 @@ func (q InstrumentedQueue[T]) Done(item T) {
 	}
 
 	q.internalQueue.Done(capsule.Object.Value())
+	q.metrics.done(capsule.startedAt)
 
 	q.lock.Lock()
 	defer q.lock.Unlock()
@@ -160,9 +186,23 @@ func (q InstrumentedQueue[T]) Get() (item T, shutdown bool) {
 	}
 
 	item = *pointerToItem
+	q.picked(item)
 	return item, shutdown
 }
 
+// picked records that item was picked from the queue to be processed.
+func (q InstrumentedQueue[T]) picked(item T) {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	var addedAt time.Time
+	if capsule, ok := q.metamap[item]; ok {
+		addedAt = capsule.addedAt
+		capsule.startedAt = time.Now()
+	}
+	q.metrics.get(q.internalQueue.Len(), addedAt)
+}
+
 func (q InstrumentedQueue[T]) Len() int {
 	return q.internalQueue.Len()
 }
@@ -200,8 +240,10 @@ func (q InstrumentedQueue[T]) AddWithOpts(o priorityqueue.AddOpts, Items ...T) {
 			runtime.AddCleanup(pointerToItem, q.cleanupKey, item)
 
 			if !q.isInQueue(item) {
+				addedAt := time.Now()
 				if o.After > 0 {
 					pq.AddAfter(pointerToItem, o.After)
+					addedAt = addedAt.Add(o.After)
 				} else if o.RateLimited {
 					pq.AddRateLimited(pointerToItem)
 				} else {
@@ -211,8 +253,13 @@ func (q InstrumentedQueue[T]) AddWithOpts(o priorityqueue.AddOpts, Items ...T) {
 				q.metamap[item] = &encapsulatedItem[T]{
 					Context: q.currentContext,
 					Object:  weakPointerToItem,
+					addedAt: addedAt,
 				}
 			}
+			if o.RateLimited {
+				q.metrics.retry()
+			}
+			q.metrics.add(q.internalQueue.Len())
 		}
 		return
 	}
@@ -238,15 +285,29 @@ func (q InstrumentedQueue[T]) GetWithPriority() (item T, priority int, shutdown
 		}
 
 		item = *pointerToItem
+		q.picked(item)
 		return item, priority, shutdown
 	}
 
-	pointerToItem, shutdown := q.internalQueue.Get()
-	if pointerToItem == nil {
-		var zero T
-		return zero, 0, shutdown
-	}
-
-	item = *pointerToItem
+	item, shutdown = q.Get()
 	return item, 0, shutdown
 }
+
+// pointerRateLimiter adapts a rate limiter of items to the pointers to items stored by the instrumented queue.
+type pointerRateLimiter[T comparable] struct {
+	inner workqueue.TypedRateLimiter[T]
+}
+
+var _ workqueue.TypedRateLimiter[*reconcile.Request] = &pointerRateLimiter[reconcile.Request]{}
+
+func (r *pointerRateLimiter[T]) When(item *T) time.Duration {
+	return r.inner.When(*item)
+}
+
+func (r *pointerRateLimiter[T]) Forget(item *T) {
+	r.inner.Forget(*item)
+}
+
+func (r *pointerRateLimiter[T]) NumRequeues(item *T) int {
+	return r.inner.NumRequeues(*item)
+}
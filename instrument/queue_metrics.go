@@ -0,0 +1,131 @@
+package instrument
+
+import (
+	stderrors "errors"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// QueueMetrics are the work-queue metrics of the instrumented queues, labeled by controller.
+type QueueMetrics struct {
+	depth      *prometheus.GaugeVec
+	adds       *prometheus.CounterVec
+	latency    *prometheus.HistogramVec
+	processing *prometheus.HistogramVec
+	retries    *prometheus.CounterVec
+}
+
+// NewQueueMetrics creates the work-queue metrics and registers them on registerer.
+// Metrics already registered by another instance are reused, so queues can share a registerer.
+func NewQueueMetrics(registerer prometheus.Registerer) (*QueueMetrics, error) {
+	metrics := &QueueMetrics{
+		depth: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "ctrlfwk_queue_depth",
+			Help: "Current number of items waiting in the work-queue",
+		}, []string{"controller"}),
+		adds: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "ctrlfwk_queue_adds_total",
+			Help: "Total number of items added to the work-queue",
+		}, []string{"controller"}),
+		latency: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "ctrlfwk_queue_latency_seconds",
+			Help:    "How long items stay in the work-queue before being processed",
+			Buckets: prometheus.ExponentialBuckets(10e-9, 10, 12),
+		}, []string{"controller"}),
+		processing: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "ctrlfwk_queue_processing_duration_seconds",
+			Help:    "How long processing an item of the work-queue takes",
+			Buckets: prometheus.ExponentialBuckets(10e-9, 10, 12),
+		}, []string{"controller"}),
+		retries: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "ctrlfwk_queue_retries_total",
+			Help: "Total number of items added back to the work-queue with rate limiting",
+		}, []string{"controller"}),
+	}
+
+	var err error
+	if metrics.depth, err = registerOrReuse(registerer, metrics.depth); err != nil {
+		return nil, err
+	}
+	if metrics.adds, err = registerOrReuse(registerer, metrics.adds); err != nil {
+		return nil, err
+	}
+	if metrics.latency, err = registerOrReuse(registerer, metrics.latency); err != nil {
+		return nil, err
+	}
+	if metrics.processing, err = registerOrReuse(registerer, metrics.processing); err != nil {
+		return nil, err
+	}
+	if metrics.retries, err = registerOrReuse(registerer, metrics.retries); err != nil {
+		return nil, err
+	}
+
+	return metrics, nil
+}
+
+func registerOrReuse[C prometheus.Collector](registerer prometheus.Registerer, collector C) (C, error) {
+	if err := registerer.Register(collector); err != nil {
+		var alreadyRegistered prometheus.AlreadyRegisteredError
+		if stderrors.As(err, &alreadyRegistered) {
+			if existing, ok := alreadyRegistered.ExistingCollector.(C); ok {
+				return existing, nil
+			}
+		}
+		return collector, err
+	}
+	return collector, nil
+}
+
+// queueObserver records the metrics of a single queue.
+type queueObserver struct {
+	depth      prometheus.Gauge
+	adds       prometheus.Counter
+	latency    prometheus.Observer
+	processing prometheus.Observer
+	retries    prometheus.Counter
+}
+
+func (m *QueueMetrics) forController(controller string) *queueObserver {
+	return &queueObserver{
+		depth:      m.depth.WithLabelValues(controller),
+		adds:       m.adds.WithLabelValues(controller),
+		latency:    m.latency.WithLabelValues(controller),
+		processing: m.processing.WithLabelValues(controller),
+		retries:    m.retries.WithLabelValues(controller),
+	}
+}
+
+// The observer methods are no-ops on a nil observer, for queues without metrics.
+
+func (o *queueObserver) add(depth int) {
+	if o == nil {
+		return
+	}
+	o.adds.Inc()
+	o.depth.Set(float64(depth))
+}
+
+func (o *queueObserver) retry() {
+	if o == nil {
+		return
+	}
+	o.retries.Inc()
+}
+
+func (o *queueObserver) get(depth int, addedAt time.Time) {
+	if o == nil {
+		return
+	}
+	o.depth.Set(float64(depth))
+	if !addedAt.IsZero() {
+		o.latency.Observe(max(time.Since(addedAt), 0).Seconds())
+	}
+}
+
+func (o *queueObserver) done(startedAt time.Time) {
+	if o == nil || startedAt.IsZero() {
+		return
+	}
+	o.processing.Observe(time.Since(startedAt).Seconds())
+}
@@ -3,7 +3,10 @@ package instrument
 import (
 	"context"
 	"testing"
+	"time"
 
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/util/workqueue"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
@@ -71,3 +74,73 @@ func TestInstrumentedQueue_AddAndGet(t *testing.T) {
 		t.Errorf("expected queue length to be 0, got %d", queueWithContext.Len())
 	}
 }
+
+type countingRateLimiter struct {
+	workqueue.TypedRateLimiter[reconcile.Request]
+	items []reconcile.Request
+}
+
+func (r *countingRateLimiter) When(item reconcile.Request) time.Duration {
+	r.items = append(r.items, item)
+	return 0
+}
+
+func TestInstrumentedQueue_UsesGivenRateLimiter(t *testing.T) {
+	rateLimiter := &countingRateLimiter{TypedRateLimiter: workqueue.DefaultTypedControllerRateLimiter[reconcile.Request]()}
+	internalQueue := workqueue.NewTypedRateLimitingQueue[*reconcile.Request](&pointerRateLimiter[reconcile.Request]{inner: rateLimiter})
+	instrumentedQueue := NewInstrumentedQueue(internalQueue)
+	defer instrumentedQueue.ShutDown()
+
+	ctx := context.Background()
+	testRequest := reconcile.Request{NamespacedName: types.NamespacedName{Namespace: "test-namespace", Name: "test-name"}}
+
+	instrumentedQueue.WithContext(&ctx).AddRateLimited(testRequest)
+
+	if len(rateLimiter.items) != 1 || rateLimiter.items[0] != testRequest {
+		t.Fatalf("expected the rate limiter to be asked for the request, got %v", rateLimiter.items)
+	}
+}
+
+func TestInstrumentedQueue_Metrics(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	metrics, err := NewQueueMetrics(registry)
+	if err != nil {
+		t.Fatalf("failed to create metrics: %v", err)
+	}
+	// Metrics can be created again on the same registry, for another controller
+	if _, err := NewQueueMetrics(registry); err != nil {
+		t.Fatalf("failed to reuse metrics: %v", err)
+	}
+
+	internalQueue := workqueue.NewTypedRateLimitingQueue(workqueue.DefaultTypedControllerRateLimiter[*reconcile.Request]())
+	queue := NewInstrumentedQueue(internalQueue).WithMetrics(metrics, "test")
+
+	first := reconcile.Request{NamespacedName: types.NamespacedName{Namespace: "default", Name: "first"}}
+	second := reconcile.Request{NamespacedName: types.NamespacedName{Namespace: "default", Name: "second"}}
+
+	queue.Add(first)
+	queue.AddRateLimited(second)
+
+	if depth := testutil.ToFloat64(metrics.depth.WithLabelValues("test")); depth != 1 {
+		t.Errorf("expected a depth of 1, got %v", depth)
+	}
+
+	item, _ := queue.Get()
+	queue.Done(item)
+
+	if adds := testutil.ToFloat64(metrics.adds.WithLabelValues("test")); adds != 2 {
+		t.Errorf("expected 2 adds, got %v", adds)
+	}
+	if retries := testutil.ToFloat64(metrics.retries.WithLabelValues("test")); retries != 1 {
+		t.Errorf("expected 1 retry, got %v", retries)
+	}
+	if depth := testutil.ToFloat64(metrics.depth.WithLabelValues("test")); depth != 0 {
+		t.Errorf("expected a depth of 0, got %v", depth)
+	}
+	if count := testutil.CollectAndCount(metrics.latency); count != 1 {
+		t.Errorf("expected the latency to be observed, got %d series", count)
+	}
+	if count := testutil.CollectAndCount(metrics.processing); count != 1 {
+		t.Errorf("expected the processing duration to be observed, got %d series", count)
+	}
+}
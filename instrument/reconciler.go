@@ -3,6 +3,9 @@ package instrument
 import (
 	"context"
 
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 )
@@ -27,12 +30,17 @@ func (t *InstrumentedReconciler) Reconcile(ctx context.Context, req reconcile.Re
 
 	ctxPtr, _ := t.GetContextForRequest(req)
 
-	ctx, span := t.StartSpan(ctxPtr, ctx, "reconcile")
+	ctx, span := t.StartSpan(ctxPtr, ctx, "reconcile", trace.WithAttributes(
+		attribute.String("k8s.resource.name", req.Name),
+		attribute.String("k8s.resource.namespace", req.Namespace),
+	))
 	defer span.End()
 
 	result, err := t.internalReconciler.Reconcile(ctx, req)
 	if err != nil {
 		logger.Error(err, "failed to reconcile")
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
 	}
 
 	t.Cleanup(ctxPtr, req)
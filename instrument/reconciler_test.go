@@ -54,7 +54,7 @@ func TestInstrumentedReconciler_Reconcile_Success(t *testing.T) {
 	// Set up expectations
 	mockInstrumenter.EXPECT().GetContextForRequest(req).Return(&ctx, true)
 	mockInstrumenter.EXPECT().NewLogger(gomock.Any()).Return(logr.New(nil))
-	mockInstrumenter.EXPECT().StartSpan(gomock.Any(), gomock.Any(), gomock.Any()).Return(ctx, trace.SpanFromContext(ctx))
+	mockInstrumenter.EXPECT().StartSpan(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(ctx, trace.SpanFromContext(ctx))
 	mockReconciler.EXPECT().Reconcile(gomock.Any(), req).Return(expectedResult, nil)
 	mockInstrumenter.EXPECT().Cleanup(&ctx, req)
 
@@ -94,7 +94,7 @@ func TestInstrumentedReconciler_Reconcile_WithError(t *testing.T) {
 	// Set up expectations
 	mockInstrumenter.EXPECT().GetContextForRequest(req).Return(&ctx, true)
 	mockInstrumenter.EXPECT().NewLogger(gomock.Any()).Return(logr.New(nil))
-	mockInstrumenter.EXPECT().StartSpan(gomock.Any(), gomock.Any(), gomock.Any()).Return(ctx, trace.SpanFromContext(ctx))
+	mockInstrumenter.EXPECT().StartSpan(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(ctx, trace.SpanFromContext(ctx))
 	mockReconciler.EXPECT().Reconcile(gomock.Any(), req).Return(expectedResult, expectedError)
 	mockInstrumenter.EXPECT().Cleanup(&ctx, req)
 
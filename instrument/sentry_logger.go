@@ -2,6 +2,7 @@ package instrument
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"maps"
 	"time"
@@ -73,6 +74,16 @@ func (s *sentrySink) Error(err error, msg string, keysAndValues ...any) {
 		return
 	}
 
+	// Errors of the framework are tagged with their class, so that they can be told apart in Sentry
+	var classified interface{ ErrorClass() string }
+	if errors.As(err, &classified) {
+		hub.WithScope(func(scope *sentry.Scope) {
+			scope.SetTag("ctrlfwk.error_class", classified.ErrorClass())
+			hub.CaptureException(err)
+		})
+		return
+	}
+
 	hub.CaptureException(err)
 }
 
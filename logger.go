@@ -0,0 +1,55 @@
+package ctrlfwk
+
+import (
+	"context"
+
+	"github.com/go-logr/logr"
+)
+
+// ImplementsLogger gives access to a logger scoped to what the framework is currently doing.
+//
+// Before running a step, a hook or a mutator, the framework adds the step name, the identifier of the
+// resource or dependency and the operation to the logger. The same logger is available from the
+// context.Context using logr.FromContextOrDiscard or log.FromContext.
+type ImplementsLogger interface {
+	// GetLogger returns the scoped logger, or the logger of the context.Context if none was set.
+	GetLogger() logr.Logger
+	// SetLogger sets the scoped logger.
+	SetLogger(logger logr.Logger)
+}
+
+// loggerScope is implemented by every Context, it allows scoping the logger without knowing the custom resource type.
+type loggerScope interface {
+	ImplementsLogger
+	GetParentContext() context.Context
+	SetParentContext(ctx context.Context)
+}
+
+// scopeLogger adds keysAndValues to the logger of the context, and to the logger of its context.Context,
+// until the returned function is called.
+// While resources are reconciled concurrently, the context is shared so only the returned logger is scoped.
+func scopeLogger(ctx loggerScope, keysAndValues ...any) (logr.Logger, func()) {
+	previousLogger := ctx.GetLogger()
+	previousParent := ctx.GetParentContext()
+
+	logger := previousLogger.WithValues(keysAndValues...)
+	if isConcurrentReconciliation(previousParent) {
+		return logger, func() {}
+	}
+
+	ctx.SetLogger(logger)
+	ctx.SetParentContext(logr.NewContext(previousParent, logger))
+
+	return logger, func() {
+		ctx.SetParentContext(previousParent)
+		ctx.SetLogger(previousLogger)
+	}
+}
+
+// runOperation runs f with the logger of the context scoped to the operation.
+func runOperation(ctx loggerScope, operation string, f func() error) error {
+	_, restore := scopeLogger(ctx, "operation", operation)
+	defer restore()
+
+	return f()
+}
@@ -0,0 +1,303 @@
+package ctrlfwk
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// ConditionTypePossibleReconcileLoop is set on the custom resource while the circuit breaker of the LoopDetector
+	// slows down the updates of one of its resources.
+	ConditionTypePossibleReconcileLoop = "PossibleReconcileLoop"
+)
+
+// LoopDetectorConfig configures a LoopDetector, the zero values using the defaults.
+type LoopDetectorConfig struct {
+	// Disabled turns off the detection, resources are updated as often as they are reconciled.
+	Disabled bool
+	// Window is the period the updates of a resource are counted on. Defaults to 1 minute.
+	Window time.Duration
+	// MaxUpdates is the number of updates of a resource within Window above which it is considered looping.
+	// Defaults to 20.
+	MaxUpdates int
+	// InitialBackoff is how long the updates of a looping resource are held the first time the breaker trips,
+	// it doubles every time it trips again. Defaults to 10 seconds.
+	InitialBackoff time.Duration
+	// MaxBackoff caps how long the updates of a looping resource are held. Defaults to 5 minutes.
+	MaxBackoff time.Duration
+	// QuietPeriod is how long a resource must not be updated for the breaker to reset. Defaults to 10 minutes.
+	QuietPeriod time.Duration
+}
+
+// LoopDetector detects resources updated on every reconciliation, e.g. because their mutator sets a timestamp,
+// and trips a circuit breaker slowing down their updates exponentially.
+//
+// A resource is considered looping when it is updated more than MaxUpdates times within Window,
+// or when its updates flip-flop between two states. While the breaker is open, the PossibleReconcileLoop
+// condition is set on the custom resource and a Warning event is emitted each time it trips.
+// The breaker resets once the resource was not updated for QuietPeriod.
+//
+// Reconcilers use DefaultLoopDetector, they can provide their own by implementing ReconcilerWithLoopDetector.
+type LoopDetector struct {
+	config LoopDetectorConfig
+
+	lock   sync.Mutex
+	states map[resourceStateKey]*loopState
+	// lastPrune is when the states that reset were last dropped, see prune
+	lastPrune time.Time
+}
+
+// DefaultLoopDetector is the LoopDetector of the reconcilers that don't implement ReconcilerWithLoopDetector.
+var DefaultLoopDetector = NewLoopDetector(LoopDetectorConfig{})
+
+// ReconcilerWithLoopDetector can be implemented by reconcilers to replace the DefaultLoopDetector,
+// e.g. to disable the detection with LoopDetectorConfig.Disabled.
+type ReconcilerWithLoopDetector[ControllerResourceType ControllerCustomResource] interface {
+	Reconciler[ControllerResourceType]
+
+	GetLoopDetector() *LoopDetector
+}
+
+// NewLoopDetector returns a LoopDetector using config, see LoopDetectorConfig for the defaults.
+func NewLoopDetector(config LoopDetectorConfig) *LoopDetector {
+	if config.Window <= 0 {
+		config.Window = time.Minute
+	}
+	if config.MaxUpdates <= 0 {
+		config.MaxUpdates = 20
+	}
+	if config.InitialBackoff <= 0 {
+		config.InitialBackoff = 10 * time.Second
+	}
+	if config.MaxBackoff <= 0 {
+		config.MaxBackoff = 5 * time.Minute
+	}
+	if config.QuietPeriod <= 0 {
+		config.QuietPeriod = 10 * time.Minute
+	}
+
+	return &LoopDetector{
+		config: config,
+		states: make(map[resourceStateKey]*loopState),
+	}
+}
+
+// resourceStateKey identifies a resource of a custom resource.
+type resourceStateKey struct {
+	owner      types.UID
+	ownerKey   types.NamespacedName
+	resourceID string
+}
+
+type loopUpdate struct {
+	at   time.Time
+	hash string
+}
+
+type loopState struct {
+	// updates are the updates of the resource within the window, oldest first
+	updates []loopUpdate
+	// trips is the number of times the breaker tripped since the last reset
+	trips int
+	// holdUntil is when the updates of the resource are allowed again
+	holdUntil time.Time
+}
+
+func newResourceStateKey(cr client.Object, resourceID string) resourceStateKey {
+	return resourceStateKey{owner: cr.GetUID(), ownerKey: client.ObjectKeyFromObject(cr), resourceID: resourceID}
+}
+
+// hold returns how long the updates of the resource are still held by the breaker, 0 if they are allowed.
+func (d *LoopDetector) hold(key resourceStateKey, now time.Time) time.Duration {
+	if d.config.Disabled {
+		return 0
+	}
+
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	state, ok := d.states[key]
+	if !ok || !now.Before(state.holdUntil) {
+		return 0
+	}
+	return state.holdUntil.Sub(now)
+}
+
+// tripped tells if the breaker of the resource is open, i.e. it tripped and did not reset yet.
+func (d *LoopDetector) tripped(key resourceStateKey, now time.Time) bool {
+	if d.config.Disabled {
+		return false
+	}
+
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	state := d.current(key, now)
+	return state != nil && state.trips > 0
+}
+
+// recordUpdate records an update of the resource, hash identifying the state it was updated to.
+// It returns how long the following updates are held when the update trips the breaker, 0 otherwise.
+func (d *LoopDetector) recordUpdate(key resourceStateKey, hash string, now time.Time) time.Duration {
+	if d.config.Disabled {
+		return 0
+	}
+
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	d.prune(now)
+
+	state := d.current(key, now)
+	if state == nil {
+		state = &loopState{}
+		d.states[key] = state
+	}
+
+	state.updates = append(state.updates, loopUpdate{at: now, hash: hash})
+	for len(state.updates) > 0 && now.Sub(state.updates[0].at) > d.config.Window {
+		state.updates = state.updates[1:]
+	}
+
+	// Once tripped, every update before the quiet period is part of the loop
+	if state.trips == 0 && len(state.updates) <= d.config.MaxUpdates && !state.oscillates() {
+		return 0
+	}
+
+	state.trips++
+	backoff := d.config.InitialBackoff << min(state.trips-1, 16)
+	if backoff <= 0 || backoff > d.config.MaxBackoff {
+		backoff = d.config.MaxBackoff
+	}
+	state.holdUntil = now.Add(backoff)
+
+	return backoff
+}
+
+// current returns the state of the resource, nil if it has none or it reset after the quiet period.
+// It must be called with the lock held.
+func (d *LoopDetector) current(key resourceStateKey, now time.Time) *loopState {
+	state, ok := d.states[key]
+	if !ok {
+		return nil
+	}
+
+	if now.Sub(state.updates[len(state.updates)-1].at) > d.config.QuietPeriod {
+		delete(d.states, key)
+		return nil
+	}
+	return state
+}
+
+// prune drops the states that reset after the quiet period, so that the ones of deleted custom resources
+// don't pile up. The states are scanned at most once per quiet period, not on every update.
+// It must be called with the lock held.
+func (d *LoopDetector) prune(now time.Time) {
+	if now.Sub(d.lastPrune) < d.config.QuietPeriod {
+		return
+	}
+	d.lastPrune = now
+
+	for key := range d.states {
+		d.current(key, now)
+	}
+}
+
+// oscillates tells if the last updates flip-flop between two states, A B A B.
+func (s *loopState) oscillates() bool {
+	n := len(s.updates)
+	if n < 4 {
+		return false
+	}
+	last := s.updates[n-4:]
+	return last[0].hash == last[2].hash && last[1].hash == last[3].hash && last[0].hash != last[1].hash
+}
+
+// loopDetectorFor returns the loop detector of the reconciler, DefaultLoopDetector if it does not provide one.
+func loopDetectorFor(reconciler any) *LoopDetector {
+	if withDetector, ok := reconciler.(interface{ GetLoopDetector() *LoopDetector }); ok {
+		if detector := withDetector.GetLoopDetector(); detector != nil {
+			return detector
+		}
+	}
+	return DefaultLoopDetector
+}
+
+// hashUpdatedObject hashes the state an object was updated to, ignoring the metadata and the status
+// the API server changes on every update.
+func hashUpdatedObject(obj client.Object) (string, error) {
+	// The content of unstructured objects is not copied by the converter
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj.DeepCopyObject())
+	if err != nil {
+		return "", err
+	}
+
+	delete(content, "status")
+	if metadata, ok := content["metadata"].(map[string]any); ok {
+		for _, field := range []string{"resourceVersion", "generation", "managedFields", "creationTimestamp", "uid"} {
+			delete(metadata, field)
+		}
+	}
+
+	data, err := json.Marshal(content)
+	if err != nil {
+		return "", err
+	}
+	hash := sha256.Sum256(data)
+	return hex.EncodeToString(hash[:]), nil
+}
+
+// setPossibleReconcileLoopCondition reflects the breaker of a resource being open on the custom resource status,
+// backoff being how long its updates are held, 0 when the breaker is closed. The condition is removed once the breaker
+// of the resource it reports resets.
+func setPossibleReconcileLoopCondition[
+	ControllerResourceType ControllerCustomResource,
+	ContextType Context[ControllerResourceType],
+](
+	ctx ContextType,
+	reconciler Reconciler[ControllerResourceType],
+	id string,
+	backoff time.Duration,
+) error {
+	defer LockContext(ctx)()
+
+	cr := ctx.GetCustomResource()
+
+	existing, err := getStatusCondition(cr, ConditionTypePossibleReconcileLoop)
+	if err != nil {
+		return err
+	}
+
+	prefix := fmt.Sprintf("resource %s ", id)
+
+	if backoff == 0 {
+		if existing == nil || !strings.HasPrefix(existing.Message, prefix) {
+			return nil
+		}
+		_, err := applyCondition(ctx, reconciler, ConditionTypePossibleReconcileLoop, nil)
+		return err
+	}
+
+	message := fmt.Sprintf("%sis updated on every reconciliation, its updates are held for %s", prefix, backoff)
+	if recorder, ok := reconciler.(record.EventRecorder); ok {
+		recorder.Event(cr, "Warning", "PossibleReconcileLoop", message)
+	}
+	_, err = applyCondition(ctx, reconciler, ConditionTypePossibleReconcileLoop, &metav1.Condition{
+		Status:             metav1.ConditionTrue,
+		Reason:             "PossibleReconcileLoop",
+		Message:            message,
+		ObservedGeneration: cr.GetGeneration(),
+	})
+	return err
+}
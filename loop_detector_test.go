@@ -0,0 +1,110 @@
+package ctrlfwk_test
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	ctrlfwk "github.com/u-ctf/controller-fwk"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+type loopReconciler struct {
+	*conditionsReconciler
+	detector *ctrlfwk.LoopDetector
+}
+
+func (r *loopReconciler) GetLoopDetector() *ctrlfwk.LoopDetector {
+	return r.detector
+}
+
+func newLoopingStep(t *testing.T, config ctrlfwk.LoopDetectorConfig) (conditionsContext, ctrlfwk.Step[*conditionsCR, conditionsContext]) {
+	t.Helper()
+
+	ctx, inner := newConditionsTest(t)
+	reconciler := &loopReconciler{conditionsReconciler: inner, detector: ctrlfwk.NewLoopDetector(config)}
+
+	// The mutator sets a different annotation on every reconciliation
+	var reconciliations int
+	resource := ctrlfwk.NewResourceBuilder(ctx, &corev1.ConfigMap{}).
+		WithKey(types.NamespacedName{Name: "app", Namespace: "default"}).
+		WithMutator(func(cm *corev1.ConfigMap) error {
+			reconciliations++
+			cm.Annotations = map[string]string{"example.com/reconciled": strconv.Itoa(reconciliations)}
+			return nil
+		}).
+		WithReadinessCondition(func(*corev1.ConfigMap) bool { return true }).
+		Build()
+
+	return ctx, ctrlfwk.NewReconcileResourceStep(ctx, reconciler, resource)
+}
+
+func TestReconcileResourceStep_LoopDetectorTripsBreaker(t *testing.T) {
+	ctx, step := newLoopingStep(t, ctrlfwk.LoopDetectorConfig{MaxUpdates: 2, InitialBackoff: time.Minute})
+
+	// Creation, then two updates within the limit
+	for range 3 {
+		if result := step.Step(ctx, logr.Discard(), ctrl.Request{}); result.ShouldReturn() {
+			t.Fatalf("unexpected result: %v", result)
+		}
+	}
+
+	result := step.Step(ctx, logr.Discard(), ctrl.Request{})
+	if result.RequeueReason() != ctrlfwk.RequeueReasonPossibleReconcileLoop {
+		t.Fatalf("expected the breaker to trip, got %v", result)
+	}
+	condition := meta.FindStatusCondition(ctx.GetCustomResource().Status.Conditions, ctrlfwk.ConditionTypePossibleReconcileLoop)
+	if condition == nil || condition.Status != metav1.ConditionTrue {
+		t.Fatalf("expected the reconcile loop condition to be set, got %v", condition)
+	}
+
+	// The updates are held while the breaker is open
+	result = step.Step(ctx, logr.Discard(), ctrl.Request{})
+	if result.RequeueReason() != ctrlfwk.RequeueReasonPossibleReconcileLoop {
+		t.Fatalf("expected the updates to be held, got %v", result)
+	}
+	if after, _ := result.Normal(); after.RequeueAfter <= 0 || after.RequeueAfter > time.Minute {
+		t.Fatalf("expected a requeue within the backoff, got %v", after.RequeueAfter)
+	}
+}
+
+func TestReconcileResourceStep_LoopDetectorDisabled(t *testing.T) {
+	ctx, step := newLoopingStep(t, ctrlfwk.LoopDetectorConfig{Disabled: true, MaxUpdates: 2})
+
+	for range 6 {
+		if result := step.Step(ctx, logr.Discard(), ctrl.Request{}); result.ShouldReturn() {
+			t.Fatalf("unexpected result: %v", result)
+		}
+	}
+}
+
+func TestLoopDetector_PrunesResetStates(t *testing.T) {
+	detector := ctrlfwk.NewLoopDetector(ctrlfwk.LoopDetectorConfig{QuietPeriod: time.Minute})
+	deleted := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "deleted", Namespace: "default", UID: "deleted"}}
+	live := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "live", Namespace: "default", UID: "live"}}
+	now := time.Now()
+
+	detector.RecordLoopUpdate(live, "app", "a", now)
+	detector.RecordLoopUpdate(deleted, "app", "a", now.Add(10*time.Second))
+	detector.RecordLoopUpdate(live, "app", "b", now.Add(65*time.Second))
+
+	// The states are scanned at most once per quiet period, the reset ones are kept until then
+	detector.RecordLoopUpdate(live, "app", "c", now.Add(100*time.Second))
+	if !detector.TracksLoopState(deleted, "app") {
+		t.Fatal("expected the states not to be scanned on every update")
+	}
+
+	detector.RecordLoopUpdate(live, "app", "d", now.Add(130*time.Second))
+	if detector.TracksLoopState(deleted, "app") {
+		t.Fatal("expected the state of the custom resource not updated for the quiet period to be dropped")
+	}
+	if !detector.TracksLoopState(live, "app") {
+		t.Fatal("expected the state of the resource still updated to be kept")
+	}
+}
@@ -5,11 +5,15 @@ import (
 	"encoding/json"
 	"slices"
 
+	"github.com/pkg/errors"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 )
 
@@ -26,94 +30,106 @@ type ManagedBy struct {
 	GVK       schema.GroupVersionKind `json:"gvk"`
 }
 
-// GetManagedBy retrieves the list of ManagedBy references from the specified
-// object's annotations. If the annotation is not present, it returns an empty
-// slice. If there is an error during unmarshalling, it returns the error.
-func GetManagedBy(obj client.Object) ([]ManagedBy, error) {
-	annotations := obj.GetAnnotations()
-	v, ok := annotations[AnnotationRef]
-	if !ok {
-		return []ManagedBy{}, nil
-	}
+// ManagedByRef is a reference to a custom resource listed in the managed-by annotation of a dependency.
+type ManagedByRef = ManagedBy
 
-	var out []ManagedBy
-	err := json.Unmarshal([]byte(v), &out)
+// NewManagedByRef returns the reference to controlledBy as listed in the managed-by annotation.
+func NewManagedByRef(controlledBy client.Object, scheme *runtime.Scheme) (ManagedByRef, error) {
+	gvk, err := apiutil.GVKForObject(controlledBy, scheme)
 	if err != nil {
-		return nil, err
+		return ManagedByRef{}, err
 	}
 
-	return out, err
+	return ManagedByRef{
+		Name:      controlledBy.GetName(),
+		Namespace: controlledBy.GetNamespace(),
+		GVK:       gvk,
+	}, nil
 }
 
-// AddManagedBy adds a ManagedBy reference to the specified object's annotations.
-// It returns true if the annotation was added or modified, and false if the
-// reference already exists. If there is an error during the process, it returns
-// the error.
-func AddManagedBy(obj client.Object, controlledBy client.Object, scheme *runtime.Scheme) (changed bool, err error) {
-	gvk, err := apiutil.GVKForObject(controlledBy, scheme)
-	if err != nil {
-		return false, err
+// ParseManagedBy returns the references listed in the managed-by annotation of obj, that is
+// the custom resources depending on it. The annotation holds a JSON list of references, e.g.
+//
+//	[{"name":"app","namespace":"default","gvk":{"Group":"example.com","Version":"v1","Kind":"App"}}]
+//
+// An empty slice is returned when the annotation is not set.
+func ParseManagedBy(obj client.Object) ([]ManagedByRef, error) {
+	v, ok := obj.GetAnnotations()[AnnotationRef]
+	if !ok {
+		return []ManagedByRef{}, nil
 	}
 
-	references, err := GetManagedBy(obj)
+	var out []ManagedByRef
+	if err := json.Unmarshal([]byte(v), &out); err != nil {
+		return nil, errors.Wrapf(err, "invalid %s annotation", AnnotationRef)
+	}
+
+	return out, nil
+}
+
+// GetManagedBy retrieves the list of ManagedBy references from the specified
+// object's annotations. If the annotation is not present, it returns an empty
+// slice. If there is an error during unmarshalling, it returns the error.
+//
+// Deprecated: use ParseManagedBy.
+func GetManagedBy(obj client.Object) ([]ManagedBy, error) {
+	return ParseManagedBy(obj)
+}
+
+// AddManagedByRef appends ref to the managed-by annotation of obj.
+// It returns false if the reference was already listed.
+func AddManagedByRef(obj client.Object, ref ManagedByRef) (changed bool, err error) {
+	references, err := ParseManagedBy(obj)
 	if err != nil {
 		return false, err
 	}
 
 	// Early return if ref is already present
-	ref := ManagedBy{
-		Name:      controlledBy.GetName(),
-		Namespace: controlledBy.GetNamespace(),
-		GVK:       gvk,
-	}
 	if slices.Contains(references, ref) {
 		return false, nil
 	}
 
-	references = append(references, ref)
-
-	annotations := obj.GetAnnotations()
-	if annotations == nil {
-		annotations = make(map[string]string)
-	}
+	return true, setManagedBy(obj, append(references, ref))
+}
 
-	annotationValue, err := json.Marshal(references)
+// AddManagedBy adds a ManagedBy reference to the specified object's annotations.
+// It returns true if the annotation was added or modified, and false if the
+// reference already exists. If there is an error during the process, it returns
+// the error.
+func AddManagedBy(obj client.Object, controlledBy client.Object, scheme *runtime.Scheme) (changed bool, err error) {
+	ref, err := NewManagedByRef(controlledBy, scheme)
 	if err != nil {
 		return false, err
 	}
 
-	annotations[AnnotationRef] = string(annotationValue)
-
-	obj.SetAnnotations(annotations)
-
-	return true, nil
+	return AddManagedByRef(obj, ref)
 }
 
 func RemoveManagedBy(obj client.Object, controlledBy client.Object, scheme *runtime.Scheme) (changed bool, err error) {
-	gvk, err := apiutil.GVKForObject(controlledBy, scheme)
+	ref, err := NewManagedByRef(controlledBy, scheme)
 	if err != nil {
 		return false, err
 	}
 
-	references, err := GetManagedBy(obj)
+	references, err := ParseManagedBy(obj)
 	if err != nil {
 		return false, err
 	}
 
 	// Early return if ref is not present
-	ref := ManagedBy{
-		Name:      controlledBy.GetName(),
-		Namespace: controlledBy.GetNamespace(),
-		GVK:       gvk,
-	}
 	if !slices.Contains(references, ref) {
 		return false, nil
 	}
 
-	references = slices.DeleteFunc(references, func(val ManagedBy) bool {
+	references = slices.DeleteFunc(references, func(val ManagedByRef) bool {
 		return val == ref
 	})
 
+	return true, setManagedBy(obj, references)
+}
+
+// setManagedBy writes the managed-by annotation of obj, removing it when there are no references left.
+func setManagedBy(obj client.Object, references []ManagedByRef) error {
 	annotations := obj.GetAnnotations()
 	if annotations == nil {
 		annotations = make(map[string]string)
@@ -122,25 +138,25 @@ func RemoveManagedBy(obj client.Object, controlledBy client.Object, scheme *runt
 	if len(references) == 0 {
 		delete(annotations, AnnotationRef)
 		obj.SetAnnotations(annotations)
-		return true, nil
+		return nil
 	}
 
 	annotationValue, err := json.Marshal(references)
 	if err != nil {
-		return false, err
+		return err
 	}
 
 	annotations[AnnotationRef] = string(annotationValue)
 
 	obj.SetAnnotations(annotations)
 
-	return true, nil
+	return nil
 }
 
 func GetManagedByReconcileRequests(ownedBy client.Object, scheme *runtime.Scheme) (func(ctx context.Context, obj client.Object) []reconcile.Request, error) {
 	gvk, err := apiutil.GVKForObject(ownedBy, scheme)
 	return func(ctx context.Context, obj client.Object) []reconcile.Request {
-		references, err := GetManagedBy(obj)
+		references, err := ParseManagedBy(obj)
 		if err != nil {
 			return nil
 		}
@@ -162,3 +178,67 @@ func GetManagedByReconcileRequests(ownedBy client.Object, scheme *runtime.Scheme
 		return requests
 	}, err
 }
+
+// ManagedByWatch returns the event handler and predicate to watch a dependency type from the controller builder,
+// for the dependencies built with WithAddManagedByAnnotation. Events are mapped back to the custom resources
+// listed in the managed-by annotation, only the objects annotated by a controlledBy custom resource being considered.
+//
+// Reconcilers with a Watcher get this watch set up on the fly, this is meant for the other reconcilers.
+//
+// Example:
+//
+//	eventHandler, predicates, err := ctrlfwk.ManagedByWatch(&myv1.MyResource{}, mgr.GetScheme())
+//	if err != nil {
+//		return err
+//	}
+//	return ctrl.NewControllerManagedBy(mgr).
+//		For(&myv1.MyResource{}).
+//		Watches(&corev1.Secret{}, eventHandler, predicates).
+//		Complete(reconciler)
+func ManagedByWatch(controlledBy client.Object, scheme *runtime.Scheme) (handler.EventHandler, builder.WatchesOption, error) {
+	gvk, err := apiutil.GVKForObject(controlledBy, scheme)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	managedByHandler, err := GetManagedByReconcileRequests(controlledBy, scheme)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return handler.EnqueueRequestsFromMapFunc(managedByHandler), builder.WithPredicates(ManagedByPredicate{GVK: gvk}), nil
+}
+
+// ManagedByPredicate only lets through the events of objects whose managed-by annotation references
+// a custom resource of the given GroupVersionKind. Updates are let through when either version is annotated,
+// so that removing the annotation is noticed, and only when the resource version changed.
+type ManagedByPredicate struct {
+	GVK schema.GroupVersionKind
+}
+
+func (p ManagedByPredicate) Create(e event.CreateEvent) bool {
+	return p.isManaged(e.Object)
+}
+
+func (p ManagedByPredicate) Update(e event.UpdateEvent) bool {
+	if e.ObjectOld.GetResourceVersion() == e.ObjectNew.GetResourceVersion() {
+		return false
+	}
+	return p.isManaged(e.ObjectOld) || p.isManaged(e.ObjectNew)
+}
+
+func (p ManagedByPredicate) Delete(e event.DeleteEvent) bool {
+	return p.isManaged(e.Object)
+}
+
+func (p ManagedByPredicate) Generic(e event.GenericEvent) bool {
+	return p.isManaged(e.Object)
+}
+
+func (p ManagedByPredicate) isManaged(obj client.Object) bool {
+	references, err := ParseManagedBy(obj)
+	if err != nil {
+		return false
+	}
+	return slices.ContainsFunc(references, func(ref ManagedBy) bool { return ref.GVK == p.GVK })
+}
@@ -0,0 +1,57 @@
+package ctrlfwk_test
+
+import (
+	"slices"
+	"testing"
+
+	ctrlfwk "github.com/u-ctf/controller-fwk"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+)
+
+func TestManagedBy_RoundTripWithMultipleOwners(t *testing.T) {
+	secret := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "shared", Namespace: "default"}}
+
+	first, err := ctrlfwk.NewManagedByRef(&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "first", Namespace: "default"}}, clientgoscheme.Scheme)
+	if err != nil {
+		t.Fatalf("failed to build reference: %v", err)
+	}
+	second := ctrlfwk.ManagedByRef{Name: "second", Namespace: "other", GVK: corev1.SchemeGroupVersion.WithKind("Pod")}
+
+	for _, ref := range []ctrlfwk.ManagedByRef{first, second, first} {
+		if _, err := ctrlfwk.AddManagedByRef(secret, ref); err != nil {
+			t.Fatalf("failed to add reference: %v", err)
+		}
+	}
+
+	// The annotation survives being read back from its serialized form
+	parsed, err := ctrlfwk.ParseManagedBy(&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Annotations: secret.Annotations}})
+	if err != nil {
+		t.Fatalf("failed to parse annotation: %v", err)
+	}
+	if !slices.Equal(parsed, []ctrlfwk.ManagedByRef{first, second}) {
+		t.Fatalf("expected both owners once, got %v", parsed)
+	}
+
+	changed, err := ctrlfwk.RemoveManagedBy(secret, &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "first", Namespace: "default"}}, clientgoscheme.Scheme)
+	if err != nil || !changed {
+		t.Fatalf("expected the first owner to be removed, got %v, %v", changed, err)
+	}
+	if parsed, _ := ctrlfwk.ParseManagedBy(secret); !slices.Equal(parsed, []ctrlfwk.ManagedByRef{second}) {
+		t.Fatalf("expected the second owner to be kept, got %v", parsed)
+	}
+}
+
+func TestParseManagedBy_InvalidAnnotation(t *testing.T) {
+	secret := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{ctrlfwk.AnnotationRef: "{"}}}
+	if _, err := ctrlfwk.ParseManagedBy(secret); err == nil {
+		t.Fatal("expected an error for an invalid annotation")
+	}
+
+	refs, err := ctrlfwk.ParseManagedBy(&corev1.Secret{})
+	if err != nil || len(refs) != 0 {
+		t.Fatalf("expected no references without the annotation, got %v, %v", refs, err)
+	}
+}
@@ -0,0 +1,141 @@
+package ctrlfwk
+
+import (
+	"fmt"
+	"maps"
+	"strings"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// ReservedMetadataDomain is the domain of the labels and annotations managed by the framework.
+// Mutators are not allowed to set keys in this domain (or its subdomains), except for LabelReconciliationPaused.
+const ReservedMetadataDomain = "ctrlfwk.com"
+
+// IsReservedMetadataKey tells whether the label or annotation key is managed by the framework.
+func IsReservedMetadataKey(key string) bool {
+	if key == LabelReconciliationPaused {
+		return false
+	}
+
+	prefix, _, found := strings.Cut(key, "/")
+	if !found {
+		return false
+	}
+
+	return prefix == ReservedMetadataDomain || strings.HasSuffix(prefix, "."+ReservedMetadataDomain)
+}
+
+// ImplementsResourceMetadata allows adding labels and annotations to every resource reconciled
+// by the framework, independently of the order in which mutators run.
+type ImplementsResourceMetadata interface {
+	// AddLabel adds a label to every resource reconciled after the call.
+	AddLabel(key, value string)
+	// AddAnnotation adds an annotation to every resource reconciled after the call.
+	AddAnnotation(key, value string)
+	// GetResourceLabels returns the labels added using AddLabel.
+	GetResourceLabels() map[string]string
+	// GetResourceAnnotations returns the annotations added using AddAnnotation.
+	GetResourceAnnotations() map[string]string
+}
+
+// ResourceMetadata holds the labels and annotations added through the context,
+// they are merged into the resources once their mutator ran.
+type ResourceMetadata struct {
+	labels      map[string]string
+	annotations map[string]string
+}
+
+var _ ImplementsResourceMetadata = &ResourceMetadata{}
+
+func (m *ResourceMetadata) AddLabel(key, value string) {
+	if m.labels == nil {
+		m.labels = make(map[string]string)
+	}
+	m.labels[key] = value
+}
+
+func (m *ResourceMetadata) AddAnnotation(key, value string) {
+	if m.annotations == nil {
+		m.annotations = make(map[string]string)
+	}
+	m.annotations[key] = value
+}
+
+func (m *ResourceMetadata) GetResourceLabels() map[string]string {
+	return maps.Clone(m.labels)
+}
+
+func (m *ResourceMetadata) GetResourceAnnotations() map[string]string {
+	return maps.Clone(m.annotations)
+}
+
+// reservedMetadata is a snapshot of the framework managed labels and annotations of an object.
+type reservedMetadata struct {
+	labels      map[string]string
+	annotations map[string]string
+}
+
+func getReservedMetadata(obj client.Object) reservedMetadata {
+	return reservedMetadata{
+		labels:      filterReservedKeys(obj.GetLabels()),
+		annotations: filterReservedKeys(obj.GetAnnotations()),
+	}
+}
+
+func filterReservedKeys(values map[string]string) map[string]string {
+	out := make(map[string]string)
+	for key, value := range values {
+		if IsReservedMetadataKey(key) {
+			out[key] = value
+		}
+	}
+	return out
+}
+
+// mergeResourceMetadata merges the metadata of a mutated object with the framework managed metadata:
+//   - reserved keys dropped by the mutator are restored from the snapshot taken before the mutation
+//   - setting or changing a reserved key from the mutator or the context is an error
+//   - labels and annotations added through the context are applied
+func mergeResourceMetadata(obj client.Object, before reservedMetadata, metadata ImplementsResourceMetadata) error {
+	labels, err := mergeMetadataMap(obj.GetLabels(), before.labels, metadata.GetResourceLabels())
+	if err != nil {
+		return fmt.Errorf("invalid label: %w", err)
+	}
+
+	annotations, err := mergeMetadataMap(obj.GetAnnotations(), before.annotations, metadata.GetResourceAnnotations())
+	if err != nil {
+		return fmt.Errorf("invalid annotation: %w", err)
+	}
+
+	if len(labels) > 0 {
+		obj.SetLabels(labels)
+	}
+	if len(annotations) > 0 {
+		obj.SetAnnotations(annotations)
+	}
+
+	return nil
+}
+
+func mergeMetadataMap(current, reserved, added map[string]string) (map[string]string, error) {
+	for key, value := range current {
+		if IsReservedMetadataKey(key) && reserved[key] != value {
+			return nil, fmt.Errorf("key %s is reserved to the framework", key)
+		}
+	}
+	for key := range added {
+		if IsReservedMetadataKey(key) {
+			return nil, fmt.Errorf("key %s is reserved to the framework", key)
+		}
+	}
+
+	out := maps.Clone(current)
+	if out == nil {
+		out = make(map[string]string)
+	}
+	maps.Copy(out, added)
+	maps.Copy(out, reserved)
+
+	return out, nil
+}
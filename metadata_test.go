@@ -0,0 +1,123 @@
+package ctrlfwk_test
+
+import (
+	"maps"
+	"testing"
+
+	ctrlfwk "github.com/u-ctf/controller-fwk"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestIsReservedMetadataKey(t *testing.T) {
+	cases := map[string]bool{
+		ctrlfwk.LabelTrackingOwnerUID:     true,
+		ctrlfwk.AnnotationRef:             true,
+		ctrlfwk.LabelReconciliationPaused: false,
+		"app.kubernetes.io/name":          false,
+		"notctrlfwk.com/key":              false,
+		"team.example.com/ctrlfwk.com":    false,
+		"sub.ctrlfwk.com/key":             true,
+		"ctrlfwk.com":                     false,
+	}
+
+	for key, expected := range cases {
+		if ctrlfwk.IsReservedMetadataKey(key) != expected {
+			t.Errorf("expected IsReservedMetadataKey(%q) to be %v", key, expected)
+		}
+	}
+}
+
+func TestMergeResourceMetadata(t *testing.T) {
+	before := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{
+		Labels:      map[string]string{ctrlfwk.LabelTrackingOwnerUID: "uid", "app": "web"},
+		Annotations: map[string]string{ctrlfwk.AnnotationRef: "ref"},
+	}}
+
+	cases := []struct {
+		name                string
+		mutate              func(cm *corev1.ConfigMap)
+		contextLabels       map[string]string
+		expectErr           bool
+		expectedLabels      map[string]string
+		expectedAnnotations map[string]string
+	}{
+		{
+			name:                "reserved keys are kept",
+			mutate:              func(cm *corev1.ConfigMap) {},
+			expectedLabels:      map[string]string{ctrlfwk.LabelTrackingOwnerUID: "uid", "app": "web"},
+			expectedAnnotations: map[string]string{ctrlfwk.AnnotationRef: "ref"},
+		},
+		{
+			name: "reserved keys removed by the mutator are restored",
+			mutate: func(cm *corev1.ConfigMap) {
+				cm.Labels = map[string]string{"app": "web"}
+				cm.Annotations = nil
+			},
+			expectedLabels:      map[string]string{ctrlfwk.LabelTrackingOwnerUID: "uid", "app": "web"},
+			expectedAnnotations: map[string]string{ctrlfwk.AnnotationRef: "ref"},
+		},
+		{
+			name:      "reserved label set by the mutator is rejected",
+			mutate:    func(cm *corev1.ConfigMap) { cm.Labels["sub.ctrlfwk.com/key"] = "value" },
+			expectErr: true,
+		},
+		{
+			name:      "reserved annotation changed by the mutator is rejected",
+			mutate:    func(cm *corev1.ConfigMap) { cm.Annotations[ctrlfwk.AnnotationRef] = "other" },
+			expectErr: true,
+		},
+		{
+			name:          "reserved label added through the context is rejected",
+			mutate:        func(cm *corev1.ConfigMap) {},
+			contextLabels: map[string]string{ctrlfwk.LabelTrackingOwnerUID: "other"},
+			expectErr:     true,
+		},
+		{
+			name: "other keys pass through unchanged",
+			mutate: func(cm *corev1.ConfigMap) {
+				cm.Labels["app"] = "api"
+				cm.Labels[ctrlfwk.LabelReconciliationPaused] = "true"
+				cm.Annotations["team.example.com/ctrlfwk.com"] = "owner"
+			},
+			contextLabels: map[string]string{"tier": "backend"},
+			expectedLabels: map[string]string{
+				ctrlfwk.LabelTrackingOwnerUID:     "uid",
+				ctrlfwk.LabelReconciliationPaused: "true",
+				"app":                             "api",
+				"tier":                            "backend",
+			},
+			expectedAnnotations: map[string]string{ctrlfwk.AnnotationRef: "ref", "team.example.com/ctrlfwk.com": "owner"},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			obj := before.DeepCopy()
+			tc.mutate(obj)
+
+			metadata := &ctrlfwk.ResourceMetadata{}
+			for key, value := range tc.contextLabels {
+				metadata.AddLabel(key, value)
+			}
+
+			err := ctrlfwk.MergeResourceMetadata(obj, before, metadata)
+			if tc.expectErr {
+				if err == nil {
+					t.Fatal("expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !maps.Equal(obj.Labels, tc.expectedLabels) {
+				t.Errorf("expected labels %v, got %v", tc.expectedLabels, obj.Labels)
+			}
+			if !maps.Equal(obj.Annotations, tc.expectedAnnotations) {
+				t.Errorf("expected annotations %v, got %v", tc.expectedAnnotations, obj.Annotations)
+			}
+		})
+	}
+}
@@ -0,0 +1,50 @@
+package ctrlfwk
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+// DependencyOutcome is the outcome of the resolution of a dependency, recorded on the dependency metrics and spans.
+type DependencyOutcome string
+
+const (
+	DependencyOutcomeFound    DependencyOutcome = "found"
+	DependencyOutcomeNotFound DependencyOutcome = "not_found"
+	DependencyOutcomeNotReady DependencyOutcome = "not_ready"
+	DependencyOutcomeError    DependencyOutcome = "error"
+)
+
+var (
+	// dependencyResolutionTotal counts the resolutions of each dependency by outcome.
+	// Dependencies are identified by their ID, WithUserIdentifier keeps the cardinality low.
+	dependencyResolutionTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "ctrlfwk_dependency_resolution_total",
+		Help: "Total number of dependency resolutions per dependency and outcome",
+	}, []string{"dependency", "outcome"})
+
+	// dependencyResolutionDuration measures how long each dependency takes to resolve, by outcome.
+	dependencyResolutionDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "ctrlfwk_dependency_resolution_duration_seconds",
+		Help:    "Duration of dependency resolutions per dependency and outcome",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"dependency", "outcome"})
+
+	// reconcileRequeueTotal counts the reconciliations that were requeued or returned early, by custom resource kind,
+	// step and reason, telling why an object keeps being reconciled.
+	reconcileRequeueTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "ctrlfwk_reconcile_requeue_total",
+		Help: "Total number of requeued or early returned reconciliations per custom resource kind, step and reason",
+	}, []string{"kind", "step", "reason"})
+
+	// phaseDuration measures how long each phase takes to execute, by custom resource kind and outcome.
+	phaseDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "ctrlfwk_phase_duration_seconds",
+		Help:    "Duration of phases per custom resource kind, phase and outcome",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"kind", "phase", "outcome"})
+)
+
+func init() {
+	metrics.Registry.MustRegister(dependencyResolutionTotal, dependencyResolutionDuration, reconcileRequeueTotal, phaseDuration)
+}
@@ -0,0 +1,82 @@
+package ctrlfwk
+
+import (
+	"context"
+	"fmt"
+	"runtime/debug"
+	"time"
+
+	"golang.org/x/time/rate"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// ReconcileFunc is the reconciliation of a custom resource, as run by the Stepper.
+type ReconcileFunc[K client.Object, C Context[K]] func(ctx C, req ctrl.Request) (ctrl.Result, error)
+
+// Middleware wraps the reconciliation run by the Stepper, allowing to inject cross-cutting behaviors
+// like audit logging, metrics or feature flags without modifying the steps. See StepperBuilder.WithMiddleware.
+type Middleware[K client.Object, C Context[K]] interface {
+	// Wrap returns a ReconcileFunc calling next, or not if the reconciliation should not happen.
+	Wrap(next ReconcileFunc[K, C]) ReconcileFunc[K, C]
+}
+
+// MiddlewareFunc is a function implementing Middleware.
+type MiddlewareFunc[K client.Object, C Context[K]] func(next ReconcileFunc[K, C]) ReconcileFunc[K, C]
+
+func (f MiddlewareFunc[K, C]) Wrap(next ReconcileFunc[K, C]) ReconcileFunc[K, C] {
+	return f(next)
+}
+
+// RateLimitMiddleware limits the rate of reconciliations using limiter.
+// Reconciliations going over the limit are not run, they are requeued once a token is available.
+// The limiter can be shared by several controllers to limit them globally.
+func RateLimitMiddleware[K client.Object, C Context[K]](limiter *rate.Limiter) Middleware[K, C] {
+	return MiddlewareFunc[K, C](func(next ReconcileFunc[K, C]) ReconcileFunc[K, C] {
+		return func(ctx C, req ctrl.Request) (ctrl.Result, error) {
+			reservation := limiter.Reserve()
+			if delay := reservation.Delay(); delay > 0 {
+				// The token is taken again once requeued, the requeued reconciliations don't delay the next ones
+				reservation.Cancel()
+				logf.FromContext(ctx).Info("Reconciliation rate limited, requeueing", "after", delay)
+				return ctrl.Result{RequeueAfter: delay}, nil
+			}
+			return next(ctx, req)
+		}
+	})
+}
+
+// PanicRecoveryMiddleware turns panics happening during the reconciliation into errors,
+// so the custom resource gets requeued with a backoff instead of crashing the controller.
+func PanicRecoveryMiddleware[K client.Object, C Context[K]]() Middleware[K, C] {
+	return MiddlewareFunc[K, C](func(next ReconcileFunc[K, C]) ReconcileFunc[K, C] {
+		return func(ctx C, req ctrl.Request) (result ctrl.Result, err error) {
+			defer func() {
+				if r := recover(); r != nil {
+					err = fmt.Errorf("panic during reconciliation: %v", r)
+					logf.FromContext(ctx).Error(err, "Recovered from panic", "stack", string(debug.Stack()))
+					result = ctrl.Result{}
+				}
+			}()
+			return next(ctx, req)
+		}
+	})
+}
+
+// TimeoutMiddleware cancels the context of the reconciliation once d elapsed.
+// Requests made using the context fail once it is canceled, failing the reconciliation.
+func TimeoutMiddleware[K client.Object, C Context[K]](d time.Duration) Middleware[K, C] {
+	return MiddlewareFunc[K, C](func(next ReconcileFunc[K, C]) ReconcileFunc[K, C] {
+		return func(ctx C, req ctrl.Request) (ctrl.Result, error) {
+			parent := ctx.GetParentContext()
+			timeoutCtx, cancel := context.WithTimeout(parent, d)
+			defer cancel()
+
+			ctx.SetParentContext(timeoutCtx)
+			defer ctx.SetParentContext(parent)
+
+			return next(ctx, req)
+		}
+	})
+}
@@ -0,0 +1,286 @@
+package ctrlfwk_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	ctrlfwk "github.com/u-ctf/controller-fwk"
+	"golang.org/x/time/rate"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+)
+
+type testContext = ctrlfwk.Context[*corev1.ConfigMap]
+
+func TestStepper_MiddlewaresOrder(t *testing.T) {
+	ctx := ctrlfwk.NewContext[*corev1.ConfigMap](context.Background(), nil)
+
+	var calls []string
+	record := func(name string) ctrlfwk.Middleware[*corev1.ConfigMap, testContext] {
+		return ctrlfwk.MiddlewareFunc[*corev1.ConfigMap, testContext](func(next ctrlfwk.ReconcileFunc[*corev1.ConfigMap, testContext]) ctrlfwk.ReconcileFunc[*corev1.ConfigMap, testContext] {
+			return func(ctx testContext, req ctrl.Request) (ctrl.Result, error) {
+				calls = append(calls, name)
+				return next(ctx, req)
+			}
+		})
+	}
+
+	stepper := ctrlfwk.NewStepperFor(ctx, logr.Discard()).
+		WithMiddleware(record("first"), record("second")).
+		WithStep(ctrlfwk.NewStep("step", func(testContext, logr.Logger, ctrl.Request) ctrlfwk.StepResult {
+			calls = append(calls, "step")
+			return ctrlfwk.ResultSuccess()
+		})).
+		Build()
+
+	if _, err := stepper.Execute(ctx, ctrl.Request{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := []string{"first", "second", "step"}
+	if len(calls) != len(expected) {
+		t.Fatalf("expected calls %v, got %v", expected, calls)
+	}
+	for i := range expected {
+		if calls[i] != expected[i] {
+			t.Fatalf("expected calls %v, got %v", expected, calls)
+		}
+	}
+}
+
+func TestRateLimitMiddleware(t *testing.T) {
+	ctx := ctrlfwk.NewContext[*corev1.ConfigMap](context.Background(), nil)
+
+	var reconciliations int
+	stepper := ctrlfwk.NewStepperFor(ctx, logr.Discard()).
+		WithMiddleware(ctrlfwk.RateLimitMiddleware[*corev1.ConfigMap, testContext](rate.NewLimiter(rate.Every(time.Hour), 1))).
+		WithStep(ctrlfwk.NewStep("step", func(testContext, logr.Logger, ctrl.Request) ctrlfwk.StepResult {
+			reconciliations++
+			return ctrlfwk.ResultSuccess()
+		})).
+		Build()
+
+	if result, err := stepper.Execute(ctx, ctrl.Request{}); err != nil || result.RequeueAfter != 0 {
+		t.Fatalf("expected the first reconciliation to run, got %v, %v", result, err)
+	}
+
+	// The reconciliations going over the limit are requeued once a token is available
+	var delays []time.Duration
+	for range 3 {
+		result, err := stepper.Execute(ctx, ctrl.Request{})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		delays = append(delays, result.RequeueAfter)
+	}
+	if reconciliations != 1 {
+		t.Fatalf("expected the rate limited reconciliations not to run, got %d reconciliations", reconciliations)
+	}
+	for _, delay := range delays {
+		if delay <= 0 || delay > time.Hour {
+			t.Fatalf("expected every reconciliation to be requeued within the next token, got %v", delays)
+		}
+	}
+}
+
+func TestPanicRecoveryMiddleware(t *testing.T) {
+	ctx := ctrlfwk.NewContext[*corev1.ConfigMap](context.Background(), nil)
+
+	stepper := ctrlfwk.NewStepperFor(ctx, logr.Discard()).
+		WithMiddleware(ctrlfwk.PanicRecoveryMiddleware[*corev1.ConfigMap, testContext]()).
+		WithStep(ctrlfwk.NewStep("step", func(testContext, logr.Logger, ctrl.Request) ctrlfwk.StepResult {
+			panic("boom")
+		})).
+		Build()
+
+	if _, err := stepper.Execute(ctx, ctrl.Request{}); err == nil {
+		t.Fatal("expected the panic to be turned into an error")
+	}
+}
+
+func TestTimeoutMiddleware(t *testing.T) {
+	ctx := ctrlfwk.NewContext[*corev1.ConfigMap](context.Background(), nil)
+
+	stepper := ctrlfwk.NewStepperFor(ctx, logr.Discard()).
+		WithMiddleware(ctrlfwk.TimeoutMiddleware[*corev1.ConfigMap, testContext](10 * time.Millisecond)).
+		WithStep(ctrlfwk.NewStep("step", func(ctx testContext, _ logr.Logger, _ ctrl.Request) ctrlfwk.StepResult {
+			<-ctx.Done()
+			return ctrlfwk.ResultInError(ctx.Err())
+		})).
+		Build()
+
+	if _, err := stepper.Execute(ctx, ctrl.Request{}); err == nil {
+		t.Fatal("expected the reconciliation to time out")
+	}
+	if ctx.Err() != nil {
+		t.Fatal("expected the parent context to be restored after the reconciliation")
+	}
+}
+
+func TestStepper_WithReconcileTimeout(t *testing.T) {
+	ctx := ctrlfwk.NewContext[*corev1.ConfigMap](context.Background(), nil)
+
+	stepper := ctrlfwk.NewStepperFor(ctx, logr.Discard()).
+		WithReconcileTimeout(10 * time.Millisecond).
+		WithStep(ctrlfwk.NewStep("step", func(ctx testContext, _ logr.Logger, _ ctrl.Request) ctrlfwk.StepResult {
+			// A hook doing network calls respects the deadline through the context
+			<-ctx.Done()
+			return ctrlfwk.ResultInError(ctx.Err())
+		})).
+		Build()
+
+	result, err := stepper.Execute(ctx, ctrl.Request{})
+	if err != nil {
+		t.Fatalf("expected the timeout to requeue instead of failing, got %v", err)
+	}
+	if result.RequeueAfter != 10*time.Millisecond {
+		t.Fatalf("expected a requeue after the timeout, got %v", result.RequeueAfter)
+	}
+	if ctx.Err() != nil {
+		t.Fatal("expected the parent context to be restored after the reconciliation")
+	}
+}
+
+func TestReconciliationAttemptsMiddleware(t *testing.T) {
+	_, reconciler := newDeletionTest(t, interceptor.Funcs{})
+
+	reconcile := func(fail bool) (testContext, *corev1.ConfigMap) {
+		t.Helper()
+
+		cr := &corev1.ConfigMap{}
+		if err := reconciler.Get(context.Background(), types.NamespacedName{Name: "cr", Namespace: "default"}, cr); err != nil {
+			t.Fatalf("failed to get custom resource: %v", err)
+		}
+		ctx := ctrlfwk.NewContext(context.Background(), reconciler)
+		ctx.SetCustomResource(cr)
+
+		stepper := ctrlfwk.NewStepperFor(ctx, logr.Discard()).
+			WithMiddleware(ctrlfwk.ReconciliationAttemptsMiddleware[*corev1.ConfigMap, testContext](reconciler)).
+			WithStep(ctrlfwk.NewStep("step", func(testContext, logr.Logger, ctrl.Request) ctrlfwk.StepResult {
+				if fail {
+					return ctrlfwk.ResultInError(errors.New("boom"))
+				}
+				return ctrlfwk.ResultSuccess()
+			})).
+			Build()
+
+		_, err := stepper.Execute(ctx, ctrl.Request{})
+		if fail != (err != nil) {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if err := reconciler.Get(context.Background(), types.NamespacedName{Name: "cr", Namespace: "default"}, cr); err != nil {
+			t.Fatalf("failed to get custom resource: %v", err)
+		}
+		return ctx, cr
+	}
+
+	ctx, _ := reconcile(true)
+	if ctx.GetReconciliationAttempts() != 1 || ctx.GetFirstFailureTime() == nil {
+		t.Fatalf("expected 1 attempt with a first failure time, got %d and %v", ctx.GetReconciliationAttempts(), ctx.GetFirstFailureTime())
+	}
+	firstFailure := ctx.GetFirstFailureTime()
+
+	_, cr := reconcile(true)
+	if got := cr.Annotations[ctrlfwk.AnnotationReconciliationAttempts]; got != "2" {
+		t.Fatalf("expected 2 attempts, got %q", got)
+	}
+	if got := cr.Annotations[ctrlfwk.AnnotationFirstFailureTime]; got != firstFailure.UTC().Format(time.RFC3339) {
+		t.Fatalf("expected the first failure time to be kept, got %q", got)
+	}
+
+	ctx, cr = reconcile(false)
+	if ctx.GetReconciliationAttempts() != 0 || ctx.GetFirstFailureTime() != nil {
+		t.Fatalf("expected the attempts to be reset, got %d and %v", ctx.GetReconciliationAttempts(), ctx.GetFirstFailureTime())
+	}
+	if _, ok := cr.Annotations[ctrlfwk.AnnotationReconciliationAttempts]; ok {
+		t.Fatalf("expected the attempts annotation to be removed, got %v", cr.Annotations)
+	}
+}
+
+func TestReconcileHistoryMiddleware(t *testing.T) {
+	_, reconciler := newDeletionTest(t, interceptor.Funcs{})
+	history := ctrlfwk.NewReconcileHistory(2)
+	key := types.NamespacedName{Name: "cr", Namespace: "default"}
+
+	reconcile := func(fail bool) *corev1.ConfigMap {
+		t.Helper()
+
+		cr := &corev1.ConfigMap{}
+		if err := reconciler.Get(context.Background(), key, cr); err != nil {
+			t.Fatalf("failed to get custom resource: %v", err)
+		}
+		ctx := ctrlfwk.NewContext(context.Background(), reconciler)
+		ctx.SetCustomResource(cr)
+
+		stepper := ctrlfwk.NewStepperFor(ctx, logr.Discard()).
+			WithMiddleware(ctrlfwk.ReconcileHistoryMiddleware[*corev1.ConfigMap, testContext](reconciler, history)).
+			WithStep(ctrlfwk.NewStep("step", func(testContext, logr.Logger, ctrl.Request) ctrlfwk.StepResult {
+				if fail {
+					return ctrlfwk.ResultInError(errors.New("boom"))
+				}
+				return ctrlfwk.ResultSuccess()
+			})).
+			Build()
+
+		_, _ = stepper.Execute(ctx, ctrl.Request{NamespacedName: key})
+
+		if err := reconciler.Get(context.Background(), key, cr); err != nil {
+			t.Fatalf("failed to get custom resource: %v", err)
+		}
+		return cr
+	}
+
+	reconcile(true)
+	reconcile(false)
+	records := history.Get(key)
+	if len(records) != 2 {
+		t.Fatalf("expected 2 records, got %d", len(records))
+	}
+	if records[0].Trigger != ctrlfwk.ReconcileTriggerCustomResourceChanged || records[0].Outcome != "Error" || records[0].Error != "boom" {
+		t.Fatalf("unexpected first record: %+v", records[0])
+	}
+	if records[1].Trigger != ctrlfwk.ReconcileTriggerRequeue || records[1].Outcome != "Success" {
+		t.Fatalf("unexpected second record: %+v", records[1])
+	}
+
+	cr := reconcile(false)
+	if records := history.Get(key); len(records) != 2 || records[0].Outcome != "Success" {
+		t.Fatalf("expected the oldest record to be dropped, got %+v", records)
+	}
+	if _, ok := cr.Annotations[ctrlfwk.AnnotationReconcileHistory]; ok {
+		t.Fatalf("expected no history annotation without a dump request, got %v", cr.Annotations)
+	}
+
+	ctrlfwk.SetAnnotation(cr, ctrlfwk.AnnotationDumpHistory, "true")
+	if err := reconciler.Update(context.Background(), cr); err != nil {
+		t.Fatalf("failed to update custom resource: %v", err)
+	}
+	cr = reconcile(false)
+	if _, ok := cr.Annotations[ctrlfwk.AnnotationDumpHistory]; ok {
+		t.Fatalf("expected the dump annotation to be removed, got %v", cr.Annotations)
+	}
+	var dumped []ctrlfwk.ReconcileRecord
+	if err := json.Unmarshal([]byte(cr.Annotations[ctrlfwk.AnnotationReconcileHistory]), &dumped); err != nil {
+		t.Fatalf("failed to decode the dumped history: %v", err)
+	}
+	if len(dumped) != 2 || dumped[1].Trigger != ctrlfwk.ReconcileTriggerCustomResourceChanged {
+		t.Fatalf("unexpected dumped history: %+v", dumped)
+	}
+
+	recorder := httptest.NewRecorder()
+	history.Handler("/debug/history/").ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/debug/history/default/cr", nil))
+	var served []ctrlfwk.ReconcileRecord
+	if err := json.Unmarshal(recorder.Body.Bytes(), &served); err != nil || len(served) != 2 {
+		t.Fatalf("unexpected served history %q: %v", recorder.Body.String(), err)
+	}
+}
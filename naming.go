@@ -0,0 +1,71 @@
+package ctrlfwk
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// nameHashLength is the number of hexadecimal characters of the hash appended by Name.
+const nameHashLength = 8
+
+// Name builds a resource name from base and suffixes joined with dashes, e.g. Name("my-app", "config") is
+// "my-app-config". The name is always a valid DNS-1123 label, which is also a valid DNS-1123 subdomain,
+// so it can name any kind of resource.
+//
+// The characters that are not allowed are replaced by dashes and the name is truncated to 63 characters.
+// When the name had to be changed, a hash of base and suffixes is appended to it, e.g.
+// "my-very-long-app-name-…-config-1a2b3c4d", so that different inputs don't end up with the same name.
+// The name only depends on base and suffixes, and the way it is built won't change across versions,
+// as changing it would rename the resources of existing custom resources.
+func Name(base string, suffixes ...string) string {
+	parts := make([]string, 0, len(suffixes)+1)
+	altered := false
+	for _, part := range append([]string{base}, suffixes...) {
+		sanitized := sanitizeNamePart(part)
+		altered = altered || sanitized != part
+		if sanitized != "" {
+			parts = append(parts, sanitized)
+		}
+	}
+
+	name := strings.Join(parts, "-")
+	if !altered && len(name) <= validation.DNS1123LabelMaxLength {
+		return name
+	}
+
+	// The hash is computed on the inputs as given, so that inputs sanitized to the same name get different hashes
+	sum := sha256.Sum256([]byte(strings.Join(append([]string{base}, suffixes...), "\x00")))
+	hash := hex.EncodeToString(sum[:])[:nameHashLength]
+
+	maxPrefix := validation.DNS1123LabelMaxLength - nameHashLength - 1
+	if len(name) > maxPrefix {
+		name = name[:maxPrefix]
+	}
+	name = strings.TrimRight(name, "-")
+	if name == "" {
+		return hash
+	}
+	return name + "-" + hash
+}
+
+// sanitizeNamePart lowercases part, replacing the runs of characters not allowed in a DNS-1123 label by a dash,
+// without leading or trailing dashes.
+func sanitizeNamePart(part string) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(part) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			if dash && b.Len() > 0 {
+				b.WriteByte('-')
+			}
+			dash = false
+			b.WriteRune(r)
+			continue
+		}
+		dash = true
+	}
+	return b.String()
+}
@@ -0,0 +1,77 @@
+package ctrlfwk_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	ctrlfwk "github.com/u-ctf/controller-fwk"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+func TestName(t *testing.T) {
+	long := strings.Repeat("a", 70)
+
+	// The names must never change, as they name the resources of existing custom resources
+	cases := []struct {
+		name     string
+		base     string
+		suffixes []string
+		want     string
+	}{
+		{"short", "my-app", []string{"config"}, "my-app-config"},
+		{"no suffix", "my-app", nil, "my-app"},
+		{"dots", "my.app", []string{"config"}, "my-app-config-4ba701c5"},
+		{"unicode", "café", []string{"config"}, "caf-config-9b022a01"},
+		{"only unicode", "日本", []string{"config"}, "config-6d24fe39"},
+		{"long", long, []string{"config"}, strings.Repeat("a", 54) + "-ed2af8b3"},
+	}
+	for _, tc := range cases {
+		got := ctrlfwk.Name(tc.base, tc.suffixes...)
+		if got != tc.want {
+			t.Errorf("%s: expected %q, got %q", tc.name, tc.want, got)
+		}
+		if errs := validation.IsDNS1123Label(got); len(errs) > 0 {
+			t.Errorf("%s: expected a valid name, got %q: %v", tc.name, got, errs)
+		}
+	}
+}
+
+func TestName_TruncatedNamesStayUnique(t *testing.T) {
+	long := strings.Repeat("my-application-", 5)
+
+	first := ctrlfwk.Name(long, "config")
+	second := ctrlfwk.Name(long, "secret")
+	if first == second {
+		t.Fatalf("expected different names, got %q", first)
+	}
+	if ctrlfwk.Name(long, "config") != first {
+		t.Fatal("expected the same inputs to give the same name")
+	}
+
+	for _, name := range []string{first, second, ctrlfwk.Name(strings.Repeat("é-", 100))} {
+		if errs := validation.IsDNS1123Label(name); len(errs) > 0 {
+			t.Fatalf("expected a valid name, got %q: %v", name, errs)
+		}
+	}
+}
+
+func TestResourceBuilder_WithGeneratedKey(t *testing.T) {
+	ctx := ctrlfwk.NewContext[*corev1.ConfigMap](context.Background(), nil)
+	ctx.SetCustomResource(&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "my-app", Namespace: "default"}})
+
+	resource := ctrlfwk.NewResourceBuilder(ctx, &corev1.Secret{}).
+		WithGeneratedKey("credentials").
+		Build()
+
+	obj, _, err := resource.ObjectMetaGenerator()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if obj.GetName() != "my-app-credentials" || obj.GetNamespace() != "default" {
+		t.Fatalf("expected the key to be named after the custom resource, got %s/%s", obj.GetNamespace(), obj.GetName())
+	}
+}
@@ -0,0 +1,96 @@
+package ctrlfwk
+
+import (
+	"sync"
+	"time"
+)
+
+// NotReadyBackoff configures how the reconciliation of a custom resource is requeued while one of its resources
+// is not ready, see ResourceBuilder.WithNotReadyBackoff. The zero values use the defaults.
+type NotReadyBackoff struct {
+	// Initial is the delay of the first requeue. Defaults to 1 second.
+	Initial time.Duration
+	// Max caps the delay, which doubles on every requeue. Defaults to 5 minutes.
+	Max time.Duration
+	// Jitter spreads the delays across ±Jitter of their value, see JitterRequeue. Defaults to 0.2.
+	Jitter float64
+}
+
+// notReadyBackoffPruneInterval is how often the expired attempts of notReadyAttempts are dropped.
+const notReadyBackoffPruneInterval = time.Minute
+
+// notReadyAttempts counts the consecutive reconciliations that found each resource not ready,
+// the count being reset once the resource is ready. The count of a resource expires when the custom resource
+// is not reconciled again within twice its requeue delay, e.g. because it was deleted, so that it is dropped lazily.
+var notReadyAttempts = struct {
+	lock      sync.Mutex
+	attempts  map[resourceStateKey]notReadyAttempt
+	lastPrune time.Time
+}{attempts: make(map[resourceStateKey]notReadyAttempt)}
+
+type notReadyAttempt struct {
+	count     int
+	expiresAt time.Time
+}
+
+// next returns the delay of the next requeue of the resource, counting the attempt.
+func (b NotReadyBackoff) next(key resourceStateKey, now time.Time) time.Duration {
+	initial, maxDelay, jitter := b.Initial, b.Max, b.Jitter
+	if initial <= 0 {
+		initial = time.Second
+	}
+	if maxDelay <= 0 {
+		maxDelay = 5 * time.Minute
+	}
+	if jitter <= 0 {
+		jitter = 0.2
+	}
+
+	notReadyAttempts.lock.Lock()
+	defer notReadyAttempts.lock.Unlock()
+
+	if now.Sub(notReadyAttempts.lastPrune) >= notReadyBackoffPruneInterval {
+		for otherKey, attempt := range notReadyAttempts.attempts {
+			if !now.Before(attempt.expiresAt) {
+				delete(notReadyAttempts.attempts, otherKey)
+			}
+		}
+		notReadyAttempts.lastPrune = now
+	}
+
+	attempt := notReadyAttempts.attempts[key]
+	if !now.Before(attempt.expiresAt) {
+		attempt.count = 0
+	}
+
+	delay := initial << min(attempt.count, 32)
+	if delay <= 0 || delay > maxDelay {
+		delay = maxDelay
+	}
+	delay = JitterRequeue(delay, jitter, key.ownerKey, now)
+
+	notReadyAttempts.attempts[key] = notReadyAttempt{count: attempt.count + 1, expiresAt: now.Add(2 * delay)}
+
+	return delay
+}
+
+// resetNotReadyBackoff resets the backoff of a resource once it is ready.
+func resetNotReadyBackoff(key resourceStateKey) {
+	notReadyAttempts.lock.Lock()
+	defer notReadyAttempts.lock.Unlock()
+
+	delete(notReadyAttempts.attempts, key)
+}
+
+// notReadyBackoffResource is implemented by the resources that can be built with WithNotReadyBackoff.
+type notReadyBackoffResource interface {
+	notReadyBackoff() *NotReadyBackoff
+}
+
+// getNotReadyBackoff returns the backoff of the requeues of the resource while it is not ready, nil if it has none.
+func getNotReadyBackoff(resource any) *NotReadyBackoff {
+	if backoffResource, ok := resource.(notReadyBackoffResource); ok {
+		return backoffResource.notReadyBackoff()
+	}
+	return nil
+}
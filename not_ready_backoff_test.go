@@ -0,0 +1,33 @@
+package ctrlfwk_test
+
+import (
+	"testing"
+	"time"
+
+	ctrlfwk "github.com/u-ctf/controller-fwk"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestNotReadyBackoff_AttemptsExpire(t *testing.T) {
+	backoff := ctrlfwk.NotReadyBackoff{Initial: time.Second, Max: 4 * time.Second, Jitter: 0.01}
+	deleted := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "deleted", Namespace: "default", UID: "not-ready-backoff-deleted"}}
+	live := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "live", Namespace: "default", UID: "not-ready-backoff-live"}}
+	now := time.Now()
+
+	ctrlfwk.NextNotReadyBackoff(backoff, deleted, "app", now)
+	ctrlfwk.NextNotReadyBackoff(backoff, live, "app", now)
+	if delay := ctrlfwk.NextNotReadyBackoff(backoff, live, "app", now.Add(time.Second)); delay < 1900*time.Millisecond {
+		t.Fatalf("expected the attempts of a resource requeued on time to be counted, got a delay of %s", delay)
+	}
+
+	// The custom resource is not reconciled again, e.g. because it was deleted
+	later := now.Add(time.Hour)
+	if delay := ctrlfwk.NextNotReadyBackoff(backoff, live, "app", later); delay > 1100*time.Millisecond {
+		t.Fatalf("expected the expired attempts to be reset, got a delay of %s", delay)
+	}
+	if ctrlfwk.TracksNotReadyAttempts(deleted, "app") {
+		t.Fatal("expected the expired attempts of the deleted custom resource to be dropped")
+	}
+}
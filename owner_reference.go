@@ -0,0 +1,62 @@
+package ctrlfwk
+
+import (
+	"errors"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+// ImplementsControllerReference allows setting the custom resource as the controller of an object,
+// using the scheme of the reconciler the context was created with.
+type ImplementsControllerReference interface {
+	// SetControllerReference sets the custom resource as the controller owner of obj, so that obj
+	// is garbage collected along with it.
+	//
+	// Example:
+	//
+	//	WithMutator(func(cm *corev1.ConfigMap) error {
+	//		cm.Data = data
+	//		return ctx.SetControllerReference(cm)
+	//	})
+	SetControllerReference(obj client.Object) error
+}
+
+var _ ImplementsControllerReference = &baseContext[client.Object]{}
+
+func (c *baseContext[K]) SetControllerReference(obj client.Object) error {
+	if c.scheme == nil {
+		return errors.New("failed to set controller reference: the context was created without a reconciler")
+	}
+	return controllerutil.SetControllerReference(c.GetCustomResource(), obj, c.scheme)
+}
+
+// ownerReferenceResource is implemented by the resources that can be built with WithoutOwnerReference.
+type ownerReferenceResource interface {
+	withoutOwnerReference() bool
+}
+
+// setsControllerReference tells whether the framework sets the custom resource as the controller of the resource,
+// see ResourceBuilder.WithoutOwnerReference.
+func setsControllerReference(resource any) bool {
+	owned, ok := resource.(ownerReferenceResource)
+	return ok && !owned.withoutOwnerReference()
+}
+
+// setAutomaticControllerReference sets cr as the controller of obj, unless the owner reference would not be valid:
+// a namespaced custom resource can only own the objects of its namespace. Objects already controlled by another
+// owner are left untouched, it returns false when no reference was set.
+func setAutomaticControllerReference(obj client.Object, cr client.Object, scheme *runtime.Scheme) (bool, error) {
+	if cr.GetNamespace() != "" && obj.GetNamespace() != cr.GetNamespace() {
+		return false, nil
+	}
+	if owner := metav1.GetControllerOf(obj); owner != nil && owner.UID != cr.GetUID() {
+		return false, nil
+	}
+	if err := controllerutil.SetControllerReference(cr, obj, scheme); err != nil {
+		return false, err
+	}
+	return true, nil
+}
@@ -0,0 +1,79 @@
+package ctrlfwk
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+// PatchStrategy tells how the updates of a resource are sent to the API server, see ResourceBuilder.WithPatchStrategy.
+type PatchStrategy string
+
+const (
+	// PatchStrategyMerge sends a JSON merge patch holding the changes made by the mutator.
+	PatchStrategyMerge PatchStrategy = "Merge"
+	// PatchStrategyStrategicMerge sends a strategic merge patch holding the changes made by the mutator,
+	// lists being merged by key, e.g. the containers of a Deployment by name. Only built-in types support it.
+	PatchStrategyStrategicMerge PatchStrategy = "StrategicMerge"
+	// PatchStrategyUpdate sends the whole object.
+	PatchStrategyUpdate PatchStrategy = "Update"
+)
+
+// patchStrategyResource is implemented by the resources that can be built with WithPatchStrategy.
+type patchStrategyResource interface {
+	patchStrategy() PatchStrategy
+}
+
+// getPatchStrategy returns the patch strategy of the resource, empty when the default one is used.
+func getPatchStrategy(resource any) PatchStrategy {
+	if patched, ok := resource.(patchStrategyResource); ok {
+		return patched.patchStrategy()
+	}
+	return ""
+}
+
+// createOrPatchWithStrategy is like controllerutil.CreateOrPatch, the update being sent using strategy with
+// the resource version of the fetched object as a precondition, so that it fails with a conflict when the object
+// was modified in the meantime. Unlike controllerutil.CreateOrPatch, the status of the object is not written.
+func createOrPatchWithStrategy(ctx context.Context, c client.Client, obj client.Object, strategy PatchStrategy, f controllerutil.MutateFn) (controllerutil.OperationResult, error) {
+	if err := c.Get(ctx, client.ObjectKeyFromObject(obj), obj); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return controllerutil.OperationResultNone, err
+		}
+		if err := f(); err != nil {
+			return controllerutil.OperationResultNone, err
+		}
+		if err := c.Create(ctx, obj); err != nil {
+			return controllerutil.OperationResultNone, err
+		}
+		return controllerutil.OperationResultCreated, nil
+	}
+
+	original := obj.DeepCopyObject().(client.Object)
+	if err := f(); err != nil {
+		return controllerutil.OperationResultNone, err
+	}
+	if equality.Semantic.DeepEqual(original, obj) {
+		return controllerutil.OperationResultNone, nil
+	}
+
+	var err error
+	switch strategy {
+	case PatchStrategyMerge:
+		err = c.Patch(ctx, obj, client.MergeFromWithOptions(original, client.MergeFromWithOptimisticLock{}))
+	case PatchStrategyStrategicMerge:
+		err = c.Patch(ctx, obj, client.StrategicMergeFrom(original, client.MergeFromWithOptimisticLock{}))
+	case PatchStrategyUpdate:
+		err = c.Update(ctx, obj)
+	default:
+		err = fmt.Errorf("unknown patch strategy %q", strategy)
+	}
+	if err != nil {
+		return controllerutil.OperationResultNone, err
+	}
+	return controllerutil.OperationResultUpdated, nil
+}
@@ -0,0 +1,55 @@
+package ctrlfwk
+
+import (
+	"context"
+	"fmt"
+
+	appsv1 "k8s.io/api/apps/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+// managedPDBResource is implemented by the resources that can be built with WithManagedPDB.
+type managedPDBResource interface {
+	managedPDBSpec(obj client.Object) (spec *policyv1.PodDisruptionBudgetSpec, managed bool, err error)
+}
+
+// reconcileManagedPDB creates, updates or deletes the PodDisruptionBudget of a Deployment, named after it and owned by it
+// so that it gets garbage collected along with it. It returns false while the PodDisruptionBudget is not observed yet.
+func reconcileManagedPDB(ctx context.Context, c client.Client, scheme *runtime.Scheme, deployment client.Object, spec *policyv1.PodDisruptionBudgetSpec) (bool, error) {
+	pdb := &policyv1.PodDisruptionBudget{}
+	pdb.SetName(deployment.GetName())
+	pdb.SetNamespace(deployment.GetNamespace())
+
+	if spec == nil {
+		if err := c.Delete(ctx, pdb); client.IgnoreNotFound(err) != nil {
+			return false, fmt.Errorf("failed to delete PodDisruptionBudget: %w", err)
+		}
+		return true, nil
+	}
+
+	_, err := controllerutil.CreateOrPatch(ctx, c, pdb, func() error {
+		pdb.Spec = *spec
+		return controllerutil.SetControllerReference(deployment, pdb, scheme)
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to create or patch PodDisruptionBudget: %w", err)
+	}
+
+	return pdb.Status.ObservedGeneration >= pdb.GetGeneration(), nil
+}
+
+func (c *Resource[CustomResource, ContextType, ResourceType]) managedPDBSpec(obj client.Object) (*policyv1.PodDisruptionBudgetSpec, bool, error) {
+	if c.managedPDBF == nil {
+		return nil, false, nil
+	}
+
+	deployment, ok := obj.(*appsv1.Deployment)
+	if !ok {
+		return nil, false, fmt.Errorf("a managed PodDisruptionBudget requires a Deployment resource, got %T", obj)
+	}
+
+	return c.managedPDBF(deployment), true, nil
+}
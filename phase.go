@@ -0,0 +1,258 @@
+package ctrlfwk
+
+import (
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/pkg/errors"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// PhaseCompleted is written to the phase field of the custom resource once every phase completed.
+	PhaseCompleted = "Completed"
+
+	PhaseReasonCompleted = "PhaseCompleted"
+	PhaseReasonSkipped   = "PhaseSkipped"
+	PhaseReasonFailed    = "StepFailed"
+	PhaseReasonWaiting   = "Waiting"
+)
+
+// PhaseOutcome is the outcome of the execution of a phase, recorded on the phase metrics and spans.
+type PhaseOutcome string
+
+const (
+	PhaseOutcomeCompleted PhaseOutcome = "completed"
+	PhaseOutcomeSkipped   PhaseOutcome = "skipped"
+	PhaseOutcomeWaiting   PhaseOutcome = "waiting"
+	PhaseOutcomeFailed    PhaseOutcome = "failed"
+)
+
+// Phase groups steps under a name, e.g. ProvisionInfrastructure, for the progress of the reconciliation
+// to be visible on the custom resource, see NewPhasesStep.
+type Phase[K client.Object, C Context[K]] struct {
+	// Name is the name of the phase, it is used as the type of its condition.
+	Name string
+	// Steps are the steps of the phase, executed in order.
+	Steps []Step[K, C]
+
+	skipWhen func(ctx C) bool
+}
+
+// NewPhase creates a phase executing steps in order.
+func NewPhase[K client.Object, C Context[K]](name string, steps ...Step[K, C]) Phase[K, C] {
+	return Phase[K, C]{
+		Name:  name,
+		Steps: steps,
+	}
+}
+
+// SkipWhen skips the steps of the phase when f returns true, its condition being set as completed.
+func (p Phase[K, C]) SkipWhen(f func(ctx C) bool) Phase[K, C] {
+	p.skipWhen = f
+	return p
+}
+
+// NewPhasesStep executes phases in order, each phase running its steps until one of them returns.
+//
+// Once a phase is executed, a condition named after it is set on the custom resource:
+//   - True when its steps completed, or when it was skipped, see Phase.SkipWhen
+//   - False when one of its steps failed, the reason being the class of the error when it has one
+//   - Unknown when one of its steps requeued, the reason being the requeue reason
+//
+// The following phases are not executed, their conditions being left as they are.
+//
+// When phaseField is not nil, the name of the phase blocking the reconciliation, or PhaseCompleted, is written
+// to the status field it returns as well, so it can be shown by kubectl using an additionalPrinterColumn.
+//
+// The duration of each phase is recorded on the ctrlfwk_phase_duration_seconds metric and each phase gets
+// its own span, the spans of its steps being nested in it.
+//
+// Example:
+//
+//	.WithStep(ctrlfwk.NewPhasesStep(ctx, reconciler, func(cr *v1.App) *string { return &cr.Status.Phase },
+//		ctrlfwk.NewPhase("ResolveDependencies", ctrlfwk.NewResolveDynamicDependenciesStep(ctx, reconciler)),
+//		ctrlfwk.NewPhase("DeployWorkload", ctrlfwk.NewReconcileResourcesStep(ctx, reconciler)),
+//		ctrlfwk.NewPhase("Verify", verifyStep).SkipWhen(func(ctx ctrlfwk.Context[*v1.App]) bool {
+//			return !ctx.GetCustomResource().Spec.Verify
+//		}),
+//	))
+func NewPhasesStep[
+	ControllerResourceType ControllerCustomResource,
+	ContextType Context[ControllerResourceType],
+](
+	_ ContextType,
+	reconciler Reconciler[ControllerResourceType],
+	phaseField func(cr ControllerResourceType) *string,
+	phases ...Phase[ControllerResourceType, ContextType],
+) Step[ControllerResourceType, ContextType] {
+	return Step[ControllerResourceType, ContextType]{
+		Name: StepPhases,
+		Step: func(ctx ContextType, logger logr.Logger, req ctrl.Request) StepResult {
+			for _, phase := range phases {
+				result, condition := runPhase(ctx, logger, req, phase)
+
+				// The custom resource is gone, or the reconciliation ended normally, e.g. a finalizer was removed
+				if result.earlyReturn && result.err == nil && result.requeueReason == "" {
+					return result
+				}
+
+				current := phase.Name
+				if !result.ShouldReturn() {
+					current = ""
+				}
+				if err := setPhaseCondition(ctx, reconciler, phaseField, condition, current); client.IgnoreNotFound(err) != nil && result.err == nil {
+					return ResultInError(errors.Wrapf(err, "failed to set condition of phase %s", phase.Name))
+				}
+
+				if result.ShouldReturn() {
+					return result
+				}
+			}
+
+			if phaseField == nil || len(phases) == 0 {
+				return ResultSuccess()
+			}
+			if err := setPhaseField(ctx, reconciler, phaseField, PhaseCompleted); err != nil {
+				return ResultInError(errors.Wrap(err, "failed to set phase"))
+			}
+			return ResultSuccess()
+		},
+	}
+}
+
+// runPhase executes the steps of phase in its own span, returning the result of the step blocking it, if any,
+// and the condition reflecting its outcome.
+func runPhase[K client.Object, C Context[K]](ctx C, logger logr.Logger, req ctrl.Request, phase Phase[K, C]) (StepResult, metav1.Condition) {
+	condition := metav1.Condition{
+		Type:    phase.Name,
+		Status:  metav1.ConditionTrue,
+		Reason:  PhaseReasonCompleted,
+		Message: fmt.Sprintf("Phase %s completed", phase.Name),
+	}
+
+	if phase.skipWhen != nil && phase.skipWhen(ctx) {
+		logger.Info("Skipping phase", "phase", phase.Name)
+		condition.Reason = PhaseReasonSkipped
+		condition.Message = fmt.Sprintf("Phase %s skipped", phase.Name)
+		recordPhase(ctx, phase.Name, PhaseOutcomeSkipped, 0)
+		return ResultSuccess(), condition
+	}
+
+	span, restoreSpan := startSpan(ctx, fmt.Sprintf(StepPhase, phase.Name),
+		attribute.String("k8s.resource.name", req.Name),
+		attribute.String("k8s.resource.namespace", req.Namespace),
+	)
+	defer span.End()
+	defer restoreSpan()
+
+	startedAt := time.Now()
+	outcome := PhaseOutcomeCompleted
+	result := ResultSuccess()
+
+	for _, step := range phase.Steps {
+		result, _ = runStep(ctx, step, req)
+		if !result.ShouldReturn() {
+			continue
+		}
+
+		if result.err != nil {
+			outcome = PhaseOutcomeFailed
+			condition.Status = metav1.ConditionFalse
+			condition.Reason = PhaseReasonFailed
+			if class := ErrorClass(result.err); class != "" {
+				condition.Reason = class
+			}
+			condition.Message = fmt.Sprintf("Step %s failed: %v", step.Name, result.err)
+			span.RecordError(result.err)
+			span.SetStatus(codes.Error, result.err.Error())
+		} else {
+			outcome = PhaseOutcomeWaiting
+			condition.Status = metav1.ConditionUnknown
+			condition.Reason = PhaseReasonWaiting
+			if reason := result.requeueReason; reason != "" {
+				condition.Reason = string(reason)
+			}
+			condition.Message = fmt.Sprintf("Waiting on step %s", step.Name)
+		}
+		break
+	}
+
+	duration := time.Since(startedAt)
+	span.SetAttributes(attribute.String("ctrlfwk.phase_outcome", string(outcome)))
+	recordPhase(ctx, phase.Name, outcome, duration)
+	logger.Info("Executed phase", "phase", phase.Name, "outcome", outcome, "phaseDuration", duration)
+
+	return result, condition
+}
+
+// setPhaseCondition sets the condition of a phase on the custom resource, and the name of the phase
+// blocking the reconciliation to the phase field when current is not empty.
+func setPhaseCondition[
+	ControllerResourceType ControllerCustomResource,
+	ContextType Context[ControllerResourceType],
+](
+	ctx ContextType,
+	reconciler Reconciler[ControllerResourceType],
+	phaseField func(cr ControllerResourceType) *string,
+	condition metav1.Condition,
+	current string,
+) error {
+	defer LockContext(ctx)()
+
+	cr := ctx.GetCustomResource()
+	condition.ObservedGeneration = cr.GetGeneration()
+
+	var phaseChanged bool
+	if current != "" && phaseField != nil {
+		if field := phaseField(cr); field != nil && *field != current {
+			*field = current
+			phaseChanged = true
+		}
+	}
+
+	// The phase field is patched along with the condition, when the condition changed
+	patched, err := applyCondition(ctx, reconciler, condition.Type, &condition)
+	if err != nil {
+		return err
+	}
+
+	if phaseChanged && !patched {
+		return PatchCustomResourceStatus(ctx, reconciler)
+	}
+
+	return nil
+}
+
+// setPhaseField writes phase to the phase field of the custom resource.
+func setPhaseField[
+	ControllerResourceType ControllerCustomResource,
+	ContextType Context[ControllerResourceType],
+](
+	ctx ContextType,
+	reconciler Reconciler[ControllerResourceType],
+	phaseField func(cr ControllerResourceType) *string,
+	phase string,
+) error {
+	defer LockContext(ctx)()
+
+	field := phaseField(ctx.GetCustomResource())
+	if field == nil || *field == phase {
+		return nil
+	}
+	*field = phase
+
+	return PatchCustomResourceStatus(ctx, reconciler)
+}
+
+// recordPhase records the duration of a phase on the ctrlfwk_phase_duration_seconds metric.
+func recordPhase[K client.Object](ctx Context[K], phase string, outcome PhaseOutcome, duration time.Duration) {
+	kind := reflect.TypeOf(ctx.GetCustomResource()).Elem().Name()
+	phaseDuration.WithLabelValues(kind, phase, string(outcome)).Observe(duration.Seconds())
+}
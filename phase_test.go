@@ -0,0 +1,115 @@
+package ctrlfwk_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	ctrlfwk "github.com/u-ctf/controller-fwk"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+func TestPhasesStep(t *testing.T) {
+	ctx, reconciler := newConditionsTest(t)
+	key := types.NamespacedName{Name: "cr", Namespace: "default"}
+	req := ctrl.Request{NamespacedName: key}
+
+	verified := false
+	var executed []string
+	step := func(name string) ctrlfwk.Step[*conditionsCR, conditionsContext] {
+		return ctrlfwk.NewStep(name, func(conditionsContext, logr.Logger, ctrl.Request) ctrlfwk.StepResult {
+			executed = append(executed, name)
+			if name == "verify" && !verified {
+				return ctrlfwk.ResultRequeueIn(time.Second).WithRequeueReason(ctrlfwk.RequeueReasonWaitingOnExternal)
+			}
+			return ctrlfwk.ResultSuccess()
+		})
+	}
+
+	phases := ctrlfwk.NewPhasesStep(ctx, reconciler, func(cr *conditionsCR) *string { return &cr.Status.Phase },
+		ctrlfwk.NewPhase("ProvisionInfrastructure", step("network"), step("database")),
+		ctrlfwk.NewPhase("Backup", step("backup")).SkipWhen(func(conditionsContext) bool { return true }),
+		ctrlfwk.NewPhase("Verify", step("verify")),
+	)
+
+	assertPhase := func(phase string, conditions map[string]metav1.ConditionStatus) {
+		t.Helper()
+		cr := &conditionsCR{}
+		if err := reconciler.Get(ctx, key, cr); err != nil {
+			t.Fatalf("failed to get custom resource: %v", err)
+		}
+		if cr.Status.Phase != phase {
+			t.Fatalf("expected phase %q, got %q", phase, cr.Status.Phase)
+		}
+		for conditionType, status := range conditions {
+			if condition := meta.FindStatusCondition(cr.Status.Conditions, conditionType); condition == nil || condition.Status != status {
+				t.Fatalf("expected condition %s to be %s, got %+v", conditionType, status, condition)
+			}
+		}
+	}
+
+	result, err := phases.Step(ctx, logr.Discard(), req).Normal()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.RequeueAfter != time.Second {
+		t.Fatalf("expected the requeue of the blocking step, got %v", result.RequeueAfter)
+	}
+	if len(executed) != 3 {
+		t.Fatalf("expected the skipped phase not to run, got %v", executed)
+	}
+	assertPhase("Verify", map[string]metav1.ConditionStatus{
+		"ProvisionInfrastructure": metav1.ConditionTrue,
+		"Backup":                  metav1.ConditionTrue,
+		"Verify":                  metav1.ConditionUnknown,
+	})
+
+	verified = true
+	if _, err := phases.Step(ctx, logr.Discard(), req).Normal(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	assertPhase(ctrlfwk.PhaseCompleted, map[string]metav1.ConditionStatus{"Verify": metav1.ConditionTrue})
+}
+
+func TestPhaseTracker(t *testing.T) {
+	ctx, _ := newConditionsTest(t)
+	cr := ctx.GetCustomResource()
+	recorder := record.NewFakeRecorder(10)
+
+	tracker := ctrlfwk.NewPhaseTracker(map[string][]string{
+		"":             {"Pending"},
+		"Pending":      {"Provisioning"},
+		"Provisioning": {"Ready", "Failed"},
+	}).WithEventRecorder(recorder)
+
+	var transitionErr *ctrlfwk.PhaseTransitionError
+	if err := tracker.SetPhase(ctx, cr, "Ready"); !errors.As(err, &transitionErr) {
+		t.Fatalf("expected Ready not to be a valid initial phase, got %v", err)
+	}
+	for _, phase := range []string{"Pending", "Provisioning", "Provisioning", "Ready"} {
+		if err := tracker.SetPhase(ctx, cr, phase); err != nil {
+			t.Fatalf("unexpected error moving to %s: %v", phase, err)
+		}
+	}
+	if err := tracker.SetPhase(ctx, cr, "Pending"); !errors.As(err, &transitionErr) || transitionErr.From != "Ready" {
+		t.Fatalf("expected an illegal transition from Ready, got %v", err)
+	}
+	if phase := tracker.GetPhase(cr); phase != "Ready" || cr.Status.Phase != "Ready" {
+		t.Fatalf("expected the phase to be Ready, got %q", phase)
+	}
+
+	// Setting the current phase again records no event
+	if len(recorder.Events) != 3 {
+		t.Fatalf("expected an event per transition, got %d", len(recorder.Events))
+	}
+	if event := <-recorder.Events; !strings.Contains(event, ctrlfwk.EventReasonPhaseTransition) || !strings.Contains(event, "Pending") {
+		t.Fatalf("unexpected event %q", event)
+	}
+}
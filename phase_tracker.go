@@ -0,0 +1,150 @@
+package ctrlfwk
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"slices"
+
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// EventReasonPhaseTransition is the reason of the events recorded when the phase of a custom resource changes,
+// see PhaseTracker.
+const EventReasonPhaseTransition = "PhaseTransition"
+
+// PhaseTransitionError is returned when a custom resource is moved to a phase it can't reach from its current phase.
+type PhaseTransitionError struct {
+	From string
+	To   string
+}
+
+func (e *PhaseTransitionError) Error() string {
+	if e.From == "" {
+		return fmt.Sprintf("phase %q is not a valid initial phase", e.To)
+	}
+	return fmt.Sprintf("illegal phase transition from %q to %q", e.From, e.To)
+}
+
+func (e *PhaseTransitionError) ErrorClass() string { return "InvalidPhaseTransition" }
+
+// PhaseTracker manages the phase of custom resources, held by a string field named Phase in their status,
+// as a state machine whose legal transitions are given by the user:
+//
+//	type MyCustomResourceStatus struct {
+//	    Phase string `json:"phase,omitempty"`
+//	    ...
+//	}
+//
+// The phases a custom resource can start with are the ones reachable from the empty phase, when the transitions
+// declare it, or any declared phase otherwise.
+type PhaseTracker struct {
+	transitions map[string][]string
+	recorder    record.EventRecorder
+}
+
+// NewPhaseTracker creates a phase tracker allowing the transitions from each phase to the phases it maps to.
+//
+// Example:
+//
+//	var phases = ctrlfwk.NewPhaseTracker(map[string][]string{
+//		"":             {"Pending"},
+//		"Pending":      {"Provisioning", "Failed"},
+//		"Provisioning": {"Ready", "Failed"},
+//		"Ready":        {"Provisioning"},
+//		"Failed":       {"Pending"},
+//	})
+func NewPhaseTracker(transitions map[string][]string) *PhaseTracker {
+	return &PhaseTracker{transitions: transitions}
+}
+
+// WithEventRecorder records an event on the custom resource each time its phase changes.
+func (t *PhaseTracker) WithEventRecorder(recorder record.EventRecorder) *PhaseTracker {
+	t.recorder = recorder
+	return t
+}
+
+// GetPhase returns the phase of the custom resource, or an empty string if it has no phase field.
+func (t *PhaseTracker) GetPhase(cr client.Object) string {
+	field, err := getPhaseField(cr)
+	if err != nil {
+		return ""
+	}
+	return field.String()
+}
+
+// CanTransition tells whether a custom resource in phase from can be moved to phase to.
+func (t *PhaseTracker) CanTransition(from, to string) bool {
+	if from == to {
+		return true
+	}
+	if next, ok := t.transitions[from]; ok {
+		return slices.Contains(next, to)
+	}
+	if from != "" {
+		return false
+	}
+
+	// Without initial phases declared, any declared phase can be the initial one
+	if _, ok := t.transitions[to]; ok {
+		return true
+	}
+	for _, next := range t.transitions {
+		if slices.Contains(next, to) {
+			return true
+		}
+	}
+	return false
+}
+
+// SetPhase moves the custom resource to newPhase, returning a PhaseTransitionError if the transition is illegal.
+// Only the field is updated, the status of the custom resource still has to be patched, e.g. with
+// PatchCustomResourceStatus. Nothing happens when the custom resource is already in newPhase.
+func (t *PhaseTracker) SetPhase(ctx context.Context, cr client.Object, newPhase string) error {
+	field, err := getPhaseField(cr)
+	if err != nil {
+		return err
+	}
+
+	current := field.String()
+	if current == newPhase {
+		return nil
+	}
+	if !t.CanTransition(current, newPhase) {
+		return &PhaseTransitionError{From: current, To: newPhase}
+	}
+
+	field.SetString(newPhase)
+	logf.FromContext(ctx).Info("Phase changed", "from", current, "to", newPhase)
+
+	if t.recorder != nil {
+		if current == "" {
+			t.recorder.Eventf(cr, "Normal", EventReasonPhaseTransition, "Phase set to %s", newPhase)
+		} else {
+			t.recorder.Eventf(cr, "Normal", EventReasonPhaseTransition, "Phase changed from %s to %s", current, newPhase)
+		}
+	}
+
+	return nil
+}
+
+func getPhaseField(obj client.Object) (reflect.Value, error) {
+	objValue := reflect.ValueOf(obj)
+	if objValue.Kind() == reflect.Ptr {
+		objValue = objValue.Elem()
+	}
+
+	statusField := objValue.FieldByName("Status")
+	if !statusField.IsValid() {
+		return reflect.Value{}, fmt.Errorf("status field not found on controller resource")
+	}
+
+	phaseField := statusField.FieldByName("Phase")
+	if !phaseField.IsValid() || phaseField.Kind() != reflect.String {
+		return reflect.Value{}, fmt.Errorf("phase field not found or is not a string on status")
+	}
+
+	return phaseField, nil
+}
@@ -0,0 +1,285 @@
+package ctrlfwk
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/wI2L/jsondiff"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+)
+
+// PlannedAction is the kind of change a reconciliation would make to a resource.
+type PlannedAction string
+
+const (
+	PlannedActionCreate PlannedAction = "Create"
+	PlannedActionUpdate PlannedAction = "Update"
+	PlannedActionDelete PlannedAction = "Delete"
+)
+
+// PlannedChange is a change a reconciliation would make to a resource.
+// Diff is the JSON patch from the current object to the planned one, empty for deletions.
+// The values of secrets and of the resources built with WithSensitive are redacted from the diff.
+type PlannedChange struct {
+	Action           PlannedAction           `json:"action"`
+	GroupVersionKind schema.GroupVersionKind `json:"groupVersionKind"`
+	Key              types.NamespacedName    `json:"key"`
+	Diff             jsondiff.Patch          `json:"diff,omitempty"`
+}
+
+// Plan lists the changes a reconciliation would make, see PlanResources.
+type Plan struct {
+	Changes []PlannedChange `json:"changes"`
+}
+
+// PlanClient is an in-memory client recording the objects written through it, used to compute a Plan
+// without touching the cluster. Reads are served from the objects it was seeded with.
+type PlanClient struct {
+	client.Client
+
+	lock      sync.Mutex
+	touched   map[plannedObjectKey]*plannedObject
+	sensitive map[schema.GroupVersionKind]bool
+}
+
+type plannedObjectKey struct {
+	gvk schema.GroupVersionKind
+	key types.NamespacedName
+}
+
+type plannedObject struct {
+	// before is the object as it was before the first write, nil if it did not exist
+	before client.Object
+	// template is used to read the object back from the client
+	template client.Object
+}
+
+// NewPlanClient returns a PlanClient seeded with objects, typically the custom resource and the live state
+// of the resources it manages. The objects are registered with a status subresource.
+func NewPlanClient(scheme *runtime.Scheme, objects ...client.Object) *PlanClient {
+	planClient := &PlanClient{
+		touched:   make(map[plannedObjectKey]*plannedObject),
+		sensitive: make(map[schema.GroupVersionKind]bool),
+	}
+
+	inner := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(objects...).
+		WithStatusSubresource(objects...).
+		Build()
+
+	planClient.Client = interceptor.NewClient(inner, interceptor.Funcs{
+		Create: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.CreateOption) error {
+			if err := planClient.record(ctx, c, obj); err != nil {
+				return err
+			}
+			return c.Create(ctx, obj, opts...)
+		},
+		Update: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.UpdateOption) error {
+			if err := planClient.record(ctx, c, obj); err != nil {
+				return err
+			}
+			return c.Update(ctx, obj, opts...)
+		},
+		Patch: func(ctx context.Context, c client.WithWatch, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+			if err := planClient.record(ctx, c, obj); err != nil {
+				return err
+			}
+			return c.Patch(ctx, obj, patch, opts...)
+		},
+		Delete: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.DeleteOption) error {
+			if err := planClient.record(ctx, c, obj); err != nil {
+				return err
+			}
+			return c.Delete(ctx, obj, opts...)
+		},
+	})
+
+	return planClient
+}
+
+// record keeps the state of obj before its first write, so the plan can be computed against it.
+func (c *PlanClient) record(ctx context.Context, inner client.Client, obj client.Object) error {
+	gvk, err := getObjectGVK(obj, inner.Scheme())
+	if err != nil {
+		return err
+	}
+	key := plannedObjectKey{gvk: gvk, key: client.ObjectKeyFromObject(obj)}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if _, ok := c.touched[key]; ok {
+		return nil
+	}
+
+	template := obj.DeepCopyObject().(client.Object)
+	before := obj.DeepCopyObject().(client.Object)
+	if err := inner.Get(ctx, key.key, before); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return err
+		}
+		before = nil
+	}
+
+	c.touched[key] = &plannedObject{before: before, template: template}
+	return nil
+}
+
+func (c *PlanClient) markSensitive(gvk schema.GroupVersionKind) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.sensitive[gvk] = true
+}
+
+// Plan returns the changes written through the client since it was created, writes to a same object being merged.
+func (c *PlanClient) Plan(ctx context.Context) (*Plan, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	plan := &Plan{Changes: []PlannedChange{}}
+	for key, object := range c.touched {
+		after := object.template.DeepCopyObject().(client.Object)
+		if err := c.Client.Get(ctx, key.key, after); err != nil {
+			if !apierrors.IsNotFound(err) {
+				return nil, err
+			}
+			after = nil
+		}
+
+		change := PlannedChange{GroupVersionKind: key.gvk, Key: key.key}
+		switch {
+		case object.before == nil && after == nil:
+			continue
+		case after == nil:
+			change.Action = PlannedActionDelete
+		case object.before == nil:
+			change.Action = PlannedActionCreate
+			diff, err := compareObjects(map[string]any{}, after)
+			if err != nil {
+				return nil, err
+			}
+			change.Diff = c.redactDiff(key.gvk, diff)
+		default:
+			diff, err := compareObjects(object.before, after)
+			if err != nil {
+				return nil, err
+			}
+			if len(diff) == 0 {
+				continue
+			}
+			change.Action = PlannedActionUpdate
+			change.Diff = c.redactDiff(key.gvk, diff)
+		}
+		plan.Changes = append(plan.Changes, change)
+	}
+
+	sort.Slice(plan.Changes, func(i, j int) bool {
+		a, b := plan.Changes[i], plan.Changes[j]
+		if a.GroupVersionKind.String() != b.GroupVersionKind.String() {
+			return a.GroupVersionKind.String() < b.GroupVersionKind.String()
+		}
+		return a.Key.String() < b.Key.String()
+	})
+
+	return plan, nil
+}
+
+// redactDiff replaces the values of the sensitive fields in the diff of an object of the given kind,
+// keeping the key names.
+func (c *PlanClient) redactDiff(gvk schema.GroupVersionKind, diff jsondiff.Patch) jsondiff.Patch {
+	secret := isSecretGVK(gvk)
+	if !secret && !c.sensitive[gvk] {
+		return diff
+	}
+
+	redactField := func(field string, value any) any {
+		if !isSensitiveField(secret, field) {
+			return value
+		}
+		return redactStructure(value, secret && field == "data")
+	}
+
+	for i, operation := range diff {
+		field, _, _ := strings.Cut(strings.TrimPrefix(operation.Path, "/"), "/")
+		if field != "" {
+			diff[i].Value = redactField(field, operation.Value)
+			diff[i].OldValue = redactField(field, operation.OldValue)
+			continue
+		}
+
+		// The operation replaces the whole object
+		for _, value := range []*any{&diff[i].Value, &diff[i].OldValue} {
+			object, ok := (*value).(map[string]any)
+			if !ok {
+				continue
+			}
+			redacted := make(map[string]any, len(object))
+			for field, fieldValue := range object {
+				redacted[field] = redactField(field, fieldValue)
+			}
+			*value = redacted
+		}
+	}
+	return diff
+}
+
+func compareObjects(before, after any) (jsondiff.Patch, error) {
+	return jsondiff.Compare(before, after,
+		jsondiff.Ignores("/metadata/managedFields", "/metadata/resourceVersion", "/metadata/creationTimestamp", "/kind", "/apiVersion"),
+	)
+}
+
+// PlanResources computes what reconciling the resources of cr would do, without applying anything.
+//
+// The reconciler must be built on planClient, so that the hooks and mutators capturing it write to memory
+// as well. The plan covers the resources the reconciliation reaches, a resource that is not ready yet
+// stopping it as it would on the cluster.
+//
+// Example:
+//
+//	planClient := ctrlfwk.NewPlanClient(scheme, cr, liveObjects...)
+//	reconciler := &MyReconciler{Client: planClient}
+//	plan, err := ctrlfwk.PlanResources(ctrlfwk.NewContext(ctx, reconciler), reconciler, planClient, cr)
+func PlanResources[
+	ControllerResourceType ControllerCustomResource,
+	ContextType Context[ControllerResourceType],
+](
+	ctx ContextType,
+	reconciler ReconcilerWithResources[ControllerResourceType, ContextType],
+	planClient *PlanClient,
+	cr ControllerResourceType,
+) (*Plan, error) {
+	ctx.SetCustomResource(cr)
+
+	req := ctrl.Request{NamespacedName: client.ObjectKeyFromObject(cr)}
+
+	resources, err := reconciler.GetResources(ctx, req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the resources of %s: %w", req.NamespacedName, err)
+	}
+	for _, resource := range resources {
+		if sensitive, ok := resource.(sensitiveResource); ok && sensitive.isSensitive() {
+			if gvk, err := resource.GroupVersionKind(planClient.Scheme()); err == nil {
+				planClient.markSensitive(gvk)
+			}
+		}
+	}
+	result := NewReconcileResourcesStep(ctx, reconciler).Step(ctx, ctx.GetLogger(), req)
+	if result.err != nil {
+		return nil, fmt.Errorf("failed to plan the resources of %s: %w", req.NamespacedName, result.err)
+	}
+
+	return planClient.Plan(ctx)
+}
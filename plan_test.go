@@ -0,0 +1,64 @@
+package ctrlfwk_test
+
+import (
+	"context"
+	"testing"
+
+	ctrlfwk "github.com/u-ctf/controller-fwk"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+)
+
+func TestPlanResources(t *testing.T) {
+	cr := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "cr", Namespace: "default", UID: "uid"}}
+	live := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "existing", Namespace: "default"},
+		StringData: map[string]string{"key": "old"},
+	}
+
+	planClient := ctrlfwk.NewPlanClient(clientgoscheme.Scheme, cr, live)
+	reconciler := &fakeReconcilerWithResources{fakeReconciler: &fakeReconciler{Client: planClient}}
+	ctx := ctrlfwk.NewContext[*corev1.ConfigMap](context.Background(), reconciler)
+
+	newSecret := func(name string) ctrlfwk.GenericResource[*corev1.ConfigMap, ctrlfwk.Context[*corev1.ConfigMap]] {
+		return ctrlfwk.NewResourceBuilder(ctx, &corev1.Secret{}).
+			WithKey(types.NamespacedName{Name: name, Namespace: "default"}).
+			WithMutator(func(secret *corev1.Secret) error {
+				secret.StringData = map[string]string{"key": "new"}
+				return nil
+			}).
+			WithReadinessCondition(func(*corev1.Secret) bool { return true }).
+			Build()
+	}
+	reconciler.resources = []ctrlfwk.GenericResource[*corev1.ConfigMap, ctrlfwk.Context[*corev1.ConfigMap]]{
+		newSecret("existing"),
+		newSecret("missing"),
+	}
+
+	plan, err := ctrlfwk.PlanResources(ctx, reconciler, planClient, cr)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(plan.Changes) != 2 {
+		t.Fatalf("expected 2 planned changes, got %+v", plan.Changes)
+	}
+	actions := map[string]ctrlfwk.PlannedAction{}
+	for _, change := range plan.Changes {
+		if len(change.Diff) == 0 {
+			t.Fatalf("expected a diff for %s", change.Key)
+		}
+		actions[change.Key.Name] = change.Action
+	}
+	if actions["existing"] != ctrlfwk.PlannedActionUpdate || actions["missing"] != ctrlfwk.PlannedActionCreate {
+		t.Fatalf("unexpected planned actions %v", actions)
+	}
+
+	// Nothing was written to the live state the client was seeded with
+	if live.StringData["key"] != "old" {
+		t.Fatal("expected the seeded object to be left untouched")
+	}
+}
@@ -1,8 +1,10 @@
 package ctrlfwk
 
 import (
+	"k8s.io/apimachinery/pkg/api/equality"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
 )
 
 // NotPausedPredicate is a predicate that filters out paused resources from reconciliation.
@@ -30,6 +32,8 @@ func (p TypedNotPausedPredicate[object]) Delete(e event.TypedDeleteEvent[object]
 	return true
 }
 
+// Update filters out the updates of paused resources, except the one pausing them,
+// so the pause gets reflected on the Paused condition of the resource.
 func (p TypedNotPausedPredicate[object]) Update(e event.TypedUpdateEvent[object]) bool {
 	obj := e.ObjectNew
 	labels := obj.GetLabels()
@@ -37,7 +41,8 @@ func (p TypedNotPausedPredicate[object]) Update(e event.TypedUpdateEvent[object]
 		return true
 	}
 	if _, ok := labels[LabelReconciliationPaused]; ok {
-		return false
+		_, wasPaused := e.ObjectOld.GetLabels()[LabelReconciliationPaused]
+		return !wasPaused
 	}
 	return true
 }
@@ -53,3 +58,73 @@ func (p TypedNotPausedPredicate[object]) Generic(e event.TypedGenericEvent[objec
 	}
 	return true
 }
+
+// NewNotPausedPredicate composes the NotPausedPredicate with predicates: the events of paused resources are filtered out,
+// the updates pausing or resuming a resource are always let through so that its Paused condition reflects them,
+// as well as the ones forcing its reconciliation, see ForceReconcile, and the other events must satisfy all the predicates.
+//
+// Using predicate.And(NotPausedPredicate{}, predicates...) instead would filter out the pause of a resource
+// with predicates such as predicate.GenerationChangedPredicate, labels not changing the generation.
+//
+// Example:
+//
+//	For(&v1.App{}, builder.WithPredicates(ctrlfwk.NewNotPausedPredicate(
+//		predicate.Or(predicate.GenerationChangedPredicate{}, predicate.LabelChangedPredicate{}),
+//	)))
+func NewNotPausedPredicate(predicates ...predicate.Predicate) predicate.Predicate {
+	return predicate.And(NotPausedPredicate{}, predicate.Or(pauseChangedPredicate(), ForceReconcilePredicate(), predicate.And(predicates...)))
+}
+
+// pauseChangedPredicate only lets through the updates adding, removing or changing the pause label of a resource.
+func pauseChangedPredicate() predicate.Predicate {
+	return predicate.Funcs{
+		CreateFunc:  func(event.CreateEvent) bool { return false },
+		DeleteFunc:  func(event.DeleteEvent) bool { return false },
+		GenericFunc: func(event.GenericEvent) bool { return false },
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			if e.ObjectOld == nil || e.ObjectNew == nil {
+				return false
+			}
+			oldValue, wasPaused := e.ObjectOld.GetLabels()[LabelReconciliationPaused]
+			newValue, isPaused := e.ObjectNew.GetLabels()[LabelReconciliationPaused]
+			return wasPaused != isPaused || oldValue != newValue
+		},
+	}
+}
+
+// IgnoreAnnotationsPredicate filters out the updates of a resource only changing the given annotations,
+// such as kubectl.kubernetes.io/last-applied-configuration, so that they don't trigger reconciliations.
+// The other events are let through.
+//
+// Example:
+//
+//	ctrlfwk.IgnoreAnnotationsPredicate{Annotations: []string{corev1.LastAppliedConfigAnnotation}}
+type IgnoreAnnotationsPredicate struct {
+	predicate.Funcs
+
+	// Annotations are the keys of the annotations whose changes are ignored.
+	Annotations []string
+}
+
+func (p IgnoreAnnotationsPredicate) Update(e event.UpdateEvent) bool {
+	if e.ObjectOld == nil || e.ObjectNew == nil {
+		return true
+	}
+	return !equality.Semantic.DeepEqual(p.withoutIgnoredFields(e.ObjectOld), p.withoutIgnoredFields(e.ObjectNew))
+}
+
+// withoutIgnoredFields returns a copy of obj without the ignored annotations, nor the fields changing on every update.
+func (p IgnoreAnnotationsPredicate) withoutIgnoredFields(obj client.Object) client.Object {
+	obj = obj.DeepCopyObject().(client.Object)
+	annotations := obj.GetAnnotations()
+	for _, key := range p.Annotations {
+		delete(annotations, key)
+	}
+	if len(annotations) == 0 {
+		annotations = nil
+	}
+	obj.SetAnnotations(annotations)
+	obj.SetResourceVersion("")
+	obj.SetManagedFields(nil)
+	return obj
+}
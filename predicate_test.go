@@ -0,0 +1,81 @@
+package ctrlfwk_test
+
+import (
+	"testing"
+
+	ctrlfwk "github.com/u-ctf/controller-fwk"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+)
+
+func TestNotPausedPredicate_LetsPauseThrough(t *testing.T) {
+	running := &corev1.ConfigMap{}
+	paused := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{ctrlfwk.LabelReconciliationPaused: "maintenance"}}}
+
+	predicate := ctrlfwk.NotPausedPredicate{}
+
+	if !predicate.Update(event.UpdateEvent{ObjectOld: running, ObjectNew: paused}) {
+		t.Fatal("expected the update pausing the resource to be reconciled")
+	}
+	if predicate.Update(event.UpdateEvent{ObjectOld: paused, ObjectNew: paused}) {
+		t.Fatal("expected the updates of a paused resource to be filtered out")
+	}
+	if !predicate.Update(event.UpdateEvent{ObjectOld: paused, ObjectNew: running}) {
+		t.Fatal("expected the update resuming the resource to be reconciled")
+	}
+}
+
+func TestNewNotPausedPredicate_LetsPauseThroughPredicates(t *testing.T) {
+	running := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Generation: 1}}
+	paused := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Generation: 1, Labels: map[string]string{ctrlfwk.LabelReconciliationPaused: "maintenance"}}}
+	changed := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Generation: 2}}
+
+	p := ctrlfwk.NewNotPausedPredicate(predicate.GenerationChangedPredicate{})
+
+	if !p.Update(event.UpdateEvent{ObjectOld: running, ObjectNew: paused}) {
+		t.Fatal("expected the update pausing the resource to be reconciled")
+	}
+	if !p.Update(event.UpdateEvent{ObjectOld: paused, ObjectNew: running}) {
+		t.Fatal("expected the update resuming the resource to be reconciled")
+	}
+	if p.Update(event.UpdateEvent{ObjectOld: running, ObjectNew: running.DeepCopy()}) {
+		t.Fatal("expected the updates not changing the generation to be filtered out")
+	}
+	if !p.Update(event.UpdateEvent{ObjectOld: running, ObjectNew: changed}) {
+		t.Fatal("expected the updates changing the generation to be reconciled")
+	}
+	if p.Create(event.CreateEvent{Object: paused}) {
+		t.Fatal("expected the creation of a paused resource to be filtered out")
+	}
+
+	forced := running.DeepCopy()
+	forced.Annotations = map[string]string{ctrlfwk.AnnotationForceReconcile: "1"}
+	if !p.Update(event.UpdateEvent{ObjectOld: running, ObjectNew: forced}) {
+		t.Fatal("expected the update forcing the reconciliation to be reconciled")
+	}
+	if p.Update(event.UpdateEvent{ObjectOld: forced, ObjectNew: running}) {
+		t.Fatal("expected the removal of the force reconcile annotation to be filtered out")
+	}
+}
+
+func TestIgnoreAnnotationsPredicate(t *testing.T) {
+	old := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{ResourceVersion: "1", Annotations: map[string]string{"ignored": "a"}}}
+	annotated := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{ResourceVersion: "2", Annotations: map[string]string{"ignored": "b"}}}
+	changed := annotated.DeepCopy()
+	changed.Data = map[string]string{"key": "value"}
+
+	p := ctrlfwk.IgnoreAnnotationsPredicate{Annotations: []string{"ignored"}}
+
+	if p.Update(event.UpdateEvent{ObjectOld: old, ObjectNew: annotated}) {
+		t.Fatal("expected the update of an ignored annotation to be filtered out")
+	}
+	if !p.Update(event.UpdateEvent{ObjectOld: old, ObjectNew: changed}) {
+		t.Fatal("expected the update of the data to be reconciled")
+	}
+	if !p.Create(event.CreateEvent{Object: old}) {
+		t.Fatal("expected the creations to be reconciled")
+	}
+}
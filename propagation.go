@@ -0,0 +1,279 @@
+package ctrlfwk
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"reflect"
+	"slices"
+	"unicode/utf8"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// AnnotationPropagationSourceHash is set on resources built with WithDataPropagation, it holds the hash
+	// of the propagated data so that workloads can roll when the source changes.
+	AnnotationPropagationSourceHash = "ctrlfwk.com/propagation-source-hash"
+
+	// ConditionTypePropagationSourceMissing is set on the custom resource when the source of a data propagation
+	// does not exist, see ResourceBuilder.WithDataPropagation.
+	ConditionTypePropagationSourceMissing = "PropagationSourceMissing"
+)
+
+// PropagationKeyMapper transforms a key of the source data before it is written to the target,
+// returning false to drop the key.
+type PropagationKeyMapper func(key string, value []byte) (targetKey string, targetValue []byte, keep bool)
+
+// RenameKeys returns a PropagationKeyMapper only keeping the keys of renames, renamed to their value.
+func RenameKeys(renames map[string]string) PropagationKeyMapper {
+	return func(key string, value []byte) (string, []byte, bool) {
+		target, ok := renames[key]
+		return target, value, ok
+	}
+}
+
+// SourceDeletionPolicy tells what happens to the target of a data propagation when its source does not exist anymore.
+type SourceDeletionPolicy string
+
+const (
+	// SourceDeletionPolicyRetain keeps the target with the data last propagated. This is the default.
+	SourceDeletionPolicyRetain SourceDeletionPolicy = "Retain"
+	// SourceDeletionPolicyDelete deletes the target along with its source.
+	SourceDeletionPolicyDelete SourceDeletionPolicy = "Delete"
+)
+
+type dataPropagation struct {
+	source         func() client.Object
+	mapper         PropagationKeyMapper
+	deletionPolicy SourceDeletionPolicy
+}
+
+// propagatingResource is implemented by the resources that can be built with WithDataPropagation.
+type propagatingResource interface {
+	dataPropagation() *dataPropagation
+}
+
+func (p *dataPropagation) sourceMissing() bool {
+	source := p.source()
+	if source == nil || reflect.ValueOf(source).IsNil() {
+		return true
+	}
+	// Optional dependencies that are not found leave an empty output
+	return source.GetName() == ""
+}
+
+func (p *dataPropagation) shouldDeleteTarget() bool {
+	return p.deletionPolicy == SourceDeletionPolicyDelete && p.sourceMissing()
+}
+
+// apply writes the mapped data of the source to target, along with its hash.
+// The target is left untouched when the source is missing.
+func (p *dataPropagation) apply(target client.Object) error {
+	if p.sourceMissing() {
+		return nil
+	}
+
+	data, err := ExtractData(p.source())
+	if err != nil {
+		return err
+	}
+
+	mapped := make(map[string][]byte, len(data))
+	for key, value := range data {
+		if p.mapper == nil {
+			mapped[key] = value
+			continue
+		}
+		if targetKey, targetValue, keep := p.mapper(key, value); keep {
+			mapped[targetKey] = targetValue
+		}
+	}
+
+	if err := SetData(target, mapped); err != nil {
+		return err
+	}
+	SetAnnotation(target, AnnotationPropagationSourceHash, hashData(mapped))
+
+	return nil
+}
+
+func hashData(data map[string][]byte) string {
+	keys := make([]string, 0, len(data))
+	for key := range data {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+
+	hash := sha256.New()
+	for _, key := range keys {
+		fmt.Fprintf(hash, "%s=%d:", key, len(data[key]))
+		hash.Write(data[key])
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// ExtractData returns the decoded data of a Secret or ConfigMap, typed or unstructured.
+// The string data of Secrets and the binary data of ConfigMaps are included.
+func ExtractData(obj client.Object) (map[string][]byte, error) {
+	data := make(map[string][]byte)
+
+	switch typed := obj.(type) {
+	case *corev1.Secret:
+		for key, value := range typed.Data {
+			data[key] = value
+		}
+		for key, value := range typed.StringData {
+			data[key] = []byte(value)
+		}
+	case *corev1.ConfigMap:
+		for key, value := range typed.Data {
+			data[key] = []byte(value)
+		}
+		for key, value := range typed.BinaryData {
+			data[key] = value
+		}
+	case *unstructured.Unstructured:
+		switch typed.GetKind() {
+		case "Secret":
+			if err := extractUnstructuredData(typed, "data", true, data); err != nil {
+				return nil, err
+			}
+			if err := extractUnstructuredData(typed, "stringData", false, data); err != nil {
+				return nil, err
+			}
+		case "ConfigMap":
+			if err := extractUnstructuredData(typed, "data", false, data); err != nil {
+				return nil, err
+			}
+			if err := extractUnstructuredData(typed, "binaryData", true, data); err != nil {
+				return nil, err
+			}
+		default:
+			return nil, fmt.Errorf("can't extract data from a %s, only Secrets and ConfigMaps are supported", typed.GetKind())
+		}
+	default:
+		return nil, fmt.Errorf("can't extract data from %T, only Secrets and ConfigMaps are supported", obj)
+	}
+
+	return data, nil
+}
+
+func extractUnstructuredData(obj *unstructured.Unstructured, field string, encoded bool, out map[string][]byte) error {
+	values, _, err := unstructured.NestedStringMap(obj.Object, field)
+	if err != nil {
+		return err
+	}
+	for key, value := range values {
+		if !encoded {
+			out[key] = []byte(value)
+			continue
+		}
+		decoded, err := base64.StdEncoding.DecodeString(value)
+		if err != nil {
+			return fmt.Errorf("invalid base64 value for key %s: %w", key, err)
+		}
+		out[key] = decoded
+	}
+	return nil
+}
+
+// SetData replaces the data of a Secret or ConfigMap, typed or unstructured. Values that are not valid UTF-8
+// are written to the binary data of ConfigMaps, and values are base64 encoded where the API expects it.
+func SetData(obj client.Object, data map[string][]byte) error {
+	switch typed := obj.(type) {
+	case *corev1.Secret:
+		typed.Data = data
+		typed.StringData = nil
+	case *corev1.ConfigMap:
+		typed.Data, typed.BinaryData = splitConfigMapData(data)
+	case *unstructured.Unstructured:
+		switch typed.GetKind() {
+		case "Secret":
+			unstructured.RemoveNestedField(typed.Object, "stringData")
+			return unstructured.SetNestedStringMap(typed.Object, encodeData(data), "data")
+		case "ConfigMap":
+			values, binaryValues := splitConfigMapData(data)
+			unstructured.RemoveNestedField(typed.Object, "data")
+			unstructured.RemoveNestedField(typed.Object, "binaryData")
+			if len(values) > 0 {
+				if err := unstructured.SetNestedStringMap(typed.Object, values, "data"); err != nil {
+					return err
+				}
+			}
+			if len(binaryValues) > 0 {
+				return unstructured.SetNestedStringMap(typed.Object, encodeData(binaryValues), "binaryData")
+			}
+		default:
+			return fmt.Errorf("can't set data on a %s, only Secrets and ConfigMaps are supported", typed.GetKind())
+		}
+	default:
+		return fmt.Errorf("can't set data on %T, only Secrets and ConfigMaps are supported", obj)
+	}
+
+	return nil
+}
+
+func splitConfigMapData(data map[string][]byte) (map[string]string, map[string][]byte) {
+	var values map[string]string
+	var binaryValues map[string][]byte
+	for key, value := range data {
+		if utf8.Valid(value) {
+			if values == nil {
+				values = make(map[string]string)
+			}
+			values[key] = string(value)
+			continue
+		}
+		if binaryValues == nil {
+			binaryValues = make(map[string][]byte)
+		}
+		binaryValues[key] = value
+	}
+	return values, binaryValues
+}
+
+func encodeData(data map[string][]byte) map[string]string {
+	out := make(map[string]string, len(data))
+	for key, value := range data {
+		out[key] = base64.StdEncoding.EncodeToString(value)
+	}
+	return out
+}
+
+// setPropagationSourceMissingCondition reflects a missing propagation source on the custom resource status,
+// the condition is removed once the source exists again.
+func setPropagationSourceMissingCondition[
+	ControllerResourceType ControllerCustomResource,
+	ContextType Context[ControllerResourceType],
+](
+	ctx ContextType,
+	reconciler Reconciler[ControllerResourceType],
+	resourceID string,
+	propagation *dataPropagation,
+) error {
+	defer LockContext(ctx)()
+
+	cr := ctx.GetCustomResource()
+
+	if !propagation.sourceMissing() {
+		_, err := applyCondition(ctx, reconciler, ConditionTypePropagationSourceMissing, nil)
+		return err
+	}
+
+	action := "retained"
+	if propagation.deletionPolicy == SourceDeletionPolicyDelete {
+		action = "deleted"
+	}
+	_, err := applyCondition(ctx, reconciler, ConditionTypePropagationSourceMissing, &metav1.Condition{
+		Status:             metav1.ConditionTrue,
+		Reason:             "SourceNotFound",
+		Message:            fmt.Sprintf("source of resource %s does not exist, the resource is %s", resourceID, action),
+		ObservedGeneration: cr.GetGeneration(),
+	})
+	return err
+}
@@ -0,0 +1,108 @@
+package ctrlfwk_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-logr/logr"
+	ctrlfwk "github.com/u-ctf/controller-fwk"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+)
+
+func TestReconcileResourceStep_DataPropagation(t *testing.T) {
+	ctx, reconciler := newDeletionTest(t, interceptor.Funcs{})
+
+	source := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "source", Namespace: "default"},
+		Data:       map[string][]byte{"password": []byte("secret"), "cert": {0xff, 0xfe}, "ignored": []byte("x")},
+	}
+
+	resource := ctrlfwk.NewResourceBuilder(ctx, &corev1.ConfigMap{}).
+		WithKey(types.NamespacedName{Name: "target", Namespace: "other"}).
+		WithDataPropagation(func() client.Object { return source }, ctrlfwk.RenameKeys(map[string]string{
+			"password": "DB_PASSWORD",
+			"cert":     "tls.crt",
+		})).
+		WithReadinessCondition(func(*corev1.ConfigMap) bool { return true }).
+		Build()
+
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Name: "cr", Namespace: "default"}}
+	if _, err := ctrlfwk.NewReconcileResourceStep(ctx, reconciler, resource).Step(ctx, logr.Discard(), req).Normal(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	target := &corev1.ConfigMap{}
+	if err := reconciler.Get(context.Background(), types.NamespacedName{Name: "target", Namespace: "other"}, target); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if target.Data["DB_PASSWORD"] != "secret" || string(target.BinaryData["tls.crt"]) != "\xff\xfe" || len(target.Data)+len(target.BinaryData) != 2 {
+		t.Fatalf("unexpected propagated data %v %v", target.Data, target.BinaryData)
+	}
+	hash := target.Annotations[ctrlfwk.AnnotationPropagationSourceHash]
+	if hash == "" {
+		t.Fatal("expected the source hash annotation to be set")
+	}
+
+	source.Data["password"] = []byte("rotated")
+	if _, err := ctrlfwk.NewReconcileResourceStep(ctx, reconciler, resource).Step(ctx, logr.Discard(), req).Normal(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := reconciler.Get(context.Background(), types.NamespacedName{Name: "target", Namespace: "other"}, target); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if target.Data["DB_PASSWORD"] != "rotated" || target.Annotations[ctrlfwk.AnnotationPropagationSourceHash] == hash {
+		t.Fatal("expected the source change to be propagated along with a new hash")
+	}
+}
+
+func TestResource_DataPropagationSourceDeletionPolicy(t *testing.T) {
+	ctx := ctrlfwk.NewContext[*corev1.ConfigMap](context.Background(), nil)
+
+	var source *corev1.Secret
+	newResource := func(policy ctrlfwk.SourceDeletionPolicy) ctrlfwk.GenericResource[*corev1.ConfigMap, ctrlfwk.Context[*corev1.ConfigMap]] {
+		return ctrlfwk.NewResourceBuilder(ctx, &corev1.Secret{}).
+			WithKey(types.NamespacedName{Name: "target", Namespace: "default"}).
+			WithDataPropagation(func() client.Object { return source }, nil).
+			WithSourceDeletionPolicy(policy).
+			Build()
+	}
+
+	if newResource(ctrlfwk.SourceDeletionPolicyRetain).ShouldDeleteNow() {
+		t.Fatal("expected the target to be retained")
+	}
+	if !newResource(ctrlfwk.SourceDeletionPolicyDelete).ShouldDeleteNow() {
+		t.Fatal("expected the target to be deleted along with its source")
+	}
+
+	source = &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "source"}}
+	if newResource(ctrlfwk.SourceDeletionPolicyDelete).ShouldDeleteNow() {
+		t.Fatal("expected the target to be kept while its source exists")
+	}
+}
+
+func TestSetData_UnstructuredSecret(t *testing.T) {
+	secret := &unstructured.Unstructured{}
+	secret.SetKind("Secret")
+
+	if err := ctrlfwk.SetData(secret, map[string][]byte{"key": []byte("value")}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	data, err := ctrlfwk.ExtractData(secret)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if string(data["key"]) != "value" {
+		t.Fatalf("expected the data to round trip through base64, got %v", data)
+	}
+	if encoded, _, _ := unstructured.NestedString(secret.Object, "data", "key"); encoded != "dmFsdWU=" {
+		t.Fatalf("expected the value to be base64 encoded, got %s", encoded)
+	}
+}
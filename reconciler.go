@@ -1,6 +1,9 @@
 package ctrlfwk
 
 import (
+	"context"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -39,3 +42,30 @@ type ReconcilerWithEventRecorder[ControllerResourceType ControllerCustomResource
 
 	record.EventRecorder
 }
+
+// ReconcilerWithConversion can be implemented by reconcilers of multi-version custom resources.
+// When implemented, FindControllerCustomResourceStep reads the custom resource as unstructured,
+// keeping every field as sent by the API server, and lets the reconciler convert it into the typed version
+// used by the steps. With the "None" conversion strategy, objects written with another version of the CRD
+// keep their fields as is, this allows a single reconciler to normalize them during a migration window.
+//
+// The unstructured objects are not cached by default, so the custom resource is read from the API server.
+// Build the client of the manager with client.CacheOptions{Unstructured: true} to read it from the cache:
+//
+//	mgr, err := ctrl.NewManager(cfg, ctrl.Options{
+//		Client: client.Options{Cache: &client.CacheOptions{Unstructured: true}},
+//	})
+type ReconcilerWithConversion[ControllerResourceType ControllerCustomResource] interface {
+	Reconciler[ControllerResourceType]
+
+	// ConvertCustomResource fills cr from the raw custom resource, including the fields unknown to its type.
+	ConvertCustomResource(ctx context.Context, raw *unstructured.Unstructured, cr ControllerResourceType) error
+}
+
+// ReconcilerWithRedactor can be implemented by reconcilers to replace the DefaultRedactor,
+// e.g. to apply org-specific rules to the messages about sensitive objects.
+type ReconcilerWithRedactor[ControllerResourceType ControllerCustomResource] interface {
+	Reconciler[ControllerResourceType]
+
+	GetRedactor() Redactor
+}
@@ -0,0 +1,198 @@
+package ctrlfwk
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/u-ctf/controller-fwk/instrument"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/workqueue"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// ReconcileHandler reconciles the custom resource of req for a reconciler built by a ReconcilerFactory,
+// typically by building and executing a Stepper.
+type ReconcileHandler[ControllerResourceType ControllerCustomResource, ContextType Context[ControllerResourceType]] func(
+	ctx ContextType,
+	reconciler Reconciler[ControllerResourceType],
+	req ctrl.Request,
+) (ctrl.Result, error)
+
+// ReconcilerFactory builds the reconcilers of several controllers sharing the same client, scheme,
+// instrumentation and rate limiter, so that controllers of a manager are set up consistently.
+//
+// Example:
+//
+//	factory := ctrlfwk.NewReconcilerFactory(mgr, ctrlfwk.NewContext[*v1.App]).
+//		WithSharedInstrumentation(instrumenter).
+//		WithSharedRateLimiter(rateLimiter)
+//
+//	reconciler := factory.Build("app", func(ctx ctrlfwk.Context[*v1.App], reconciler ctrlfwk.Reconciler[*v1.App], req ctrl.Request) (ctrl.Result, error) {
+//		return ctrlfwk.NewStepperFor(ctx, ctx.GetLogger()).
+//			WithStep(ctrlfwk.NewFindControllerCustomResourceStep(ctx, reconciler)).
+//			Build().
+//			Execute(ctx, req)
+//	})
+//
+//	_, err := reconciler.ControllerManagedBy().For(&v1.App{}).Build(reconciler)
+type ReconcilerFactory[ControllerResourceType ControllerCustomResource, ContextType Context[ControllerResourceType]] struct {
+	mgr          ctrl.Manager
+	newContext   func(ctx context.Context, reconciler Reconciler[ControllerResourceType]) ContextType
+	client       client.Client
+	scheme       *runtime.Scheme
+	instrumenter instrument.Instrumenter
+	rateLimiter  workqueue.TypedRateLimiter[reconcile.Request]
+	timeout      time.Duration
+	predicates   []predicate.Predicate
+}
+
+// NewReconcilerFactory creates a factory of reconcilers registered on mgr, newContext building the context
+// of each reconciliation, e.g. NewContext. The client and scheme of the manager are used unless shared ones are given.
+func NewReconcilerFactory[ControllerResourceType ControllerCustomResource, ContextType Context[ControllerResourceType]](
+	mgr ctrl.Manager,
+	newContext func(ctx context.Context, reconciler Reconciler[ControllerResourceType]) ContextType,
+) *ReconcilerFactory[ControllerResourceType, ContextType] {
+	return &ReconcilerFactory[ControllerResourceType, ContextType]{
+		mgr:        mgr,
+		newContext: newContext,
+		client:     mgr.GetClient(),
+		scheme:     mgr.GetScheme(),
+	}
+}
+
+// WithSharedClient sets the client used by the reconcilers instead of the client of the manager.
+func (f *ReconcilerFactory[ControllerResourceType, ContextType]) WithSharedClient(c client.Client) *ReconcilerFactory[ControllerResourceType, ContextType] {
+	f.client = c
+	return f
+}
+
+// WithSharedScheme sets the scheme returned by the reconcilers instead of the scheme of the manager.
+func (f *ReconcilerFactory[ControllerResourceType, ContextType]) WithSharedScheme(scheme *runtime.Scheme) *ReconcilerFactory[ControllerResourceType, ContextType] {
+	f.scheme = scheme
+	return f
+}
+
+// WithSharedInstrumentation sets the instrumenter of the controllers of the reconcilers.
+// Without it, each reconciler gets its own instrumenter using the logger of controller-runtime and no tracer.
+func (f *ReconcilerFactory[ControllerResourceType, ContextType]) WithSharedInstrumentation(instrumenter instrument.Instrumenter) *ReconcilerFactory[ControllerResourceType, ContextType] {
+	f.instrumenter = instrumenter
+	return f
+}
+
+// WithSharedRateLimiter sets the rate limiter of the queues of the controllers of the reconcilers,
+// instead of the default controller rate limiter.
+func (f *ReconcilerFactory[ControllerResourceType, ContextType]) WithSharedRateLimiter(rateLimiter workqueue.TypedRateLimiter[reconcile.Request]) *ReconcilerFactory[ControllerResourceType, ContextType] {
+	f.rateLimiter = rateLimiter
+	return f
+}
+
+// WithReconcileTimeout bounds each reconciliation of the reconcilers to d, so that a hook hanging on an external
+// call cannot hold a worker indefinitely. The context of the reconciliation gets a deadline, hooks doing network calls
+// respect it through the context.Context embedded in the Context. A reconciliation going past the deadline is
+// requeued after d with the RequeueReasonTimeout reason instead of failing.
+// Reconcilers built without the factory get the same timeout using StepperBuilder.WithReconcileTimeout.
+func (f *ReconcilerFactory[ControllerResourceType, ContextType]) WithReconcileTimeout(d time.Duration) *ReconcilerFactory[ControllerResourceType, ContextType] {
+	f.timeout = d
+	return f
+}
+
+// WithPredicates sets the predicates filtering the events of the custom resources of the controllers of the reconcilers,
+// e.g. to only reconcile on spec changes with predicate.GenerationChangedPredicate. They are composed with the
+// NotPausedPredicate, see NewNotPausedPredicate, and applied to the object given to For on the builder returned
+// by FactoryReconciler.ControllerManagedBy. The events of the owned and watched objects are not filtered.
+//
+// Example:
+//
+//	factory.WithPredicates(
+//		predicate.Or(predicate.GenerationChangedPredicate{}, predicate.LabelChangedPredicate{}),
+//		ctrlfwk.IgnoreAnnotationsPredicate{Annotations: []string{corev1.LastAppliedConfigAnnotation}},
+//	)
+func (f *ReconcilerFactory[ControllerResourceType, ContextType]) WithPredicates(predicates ...predicate.Predicate) *ReconcilerFactory[ControllerResourceType, ContextType] {
+	f.predicates = append(f.predicates, predicates...)
+	return f
+}
+
+// Build creates the reconciler of the controller named controllerName, handler reconciling each request.
+// The reconciler records events under the name of the controller.
+func (f *ReconcilerFactory[ControllerResourceType, ContextType]) Build(controllerName string, handler ReconcileHandler[ControllerResourceType, ContextType]) *FactoryReconciler[ControllerResourceType, ContextType] {
+	instrumenter := f.instrumenter
+	if instrumenter == nil {
+		instrumenter = instrument.NewInstrumenter(f.mgr).
+			WithLoggerFunc(func(ctx context.Context) logr.Logger { return logf.FromContext(ctx) }).
+			Build()
+	}
+
+	return &FactoryReconciler[ControllerResourceType, ContextType]{
+		Client:        f.client,
+		Instrumenter:  instrumenter,
+		EventRecorder: f.mgr.GetEventRecorderFor(controllerName),
+
+		mgr:         f.mgr,
+		name:        controllerName,
+		scheme:      f.scheme,
+		rateLimiter: f.rateLimiter,
+		timeout:     f.timeout,
+		predicates:  f.predicates,
+		newContext:  f.newContext,
+		handler:     handler,
+	}
+}
+
+// FactoryReconciler is a reconciler built by a ReconcilerFactory.
+type FactoryReconciler[ControllerResourceType ControllerCustomResource, ContextType Context[ControllerResourceType]] struct {
+	client.Client
+	instrument.Instrumenter
+	record.EventRecorder
+
+	mgr         ctrl.Manager
+	name        string
+	scheme      *runtime.Scheme
+	rateLimiter workqueue.TypedRateLimiter[reconcile.Request]
+	timeout     time.Duration
+	predicates  []predicate.Predicate
+	newContext  func(ctx context.Context, reconciler Reconciler[ControllerResourceType]) ContextType
+	handler     ReconcileHandler[ControllerResourceType, ContextType]
+}
+
+var _ ReconcilerWithEventRecorder[client.Object] = &FactoryReconciler[client.Object, Context[client.Object]]{}
+var _ reconcile.Reconciler = &FactoryReconciler[client.Object, Context[client.Object]]{}
+
+func (*FactoryReconciler[ControllerResourceType, ContextType]) For(ControllerResourceType) {}
+
+// Name returns the name of the controller of the reconciler.
+func (r *FactoryReconciler[ControllerResourceType, ContextType]) Name() string {
+	return r.name
+}
+
+// Scheme returns the shared scheme of the factory.
+func (r *FactoryReconciler[ControllerResourceType, ContextType]) Scheme() *runtime.Scheme {
+	return r.scheme
+}
+
+// Reconcile builds the context of the reconciliation and runs the handler of the reconciler,
+// within the timeout of the reconciler if any, see ReconcilerFactory.WithReconcileTimeout.
+func (r *FactoryReconciler[ControllerResourceType, ContextType]) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	reconcileCtx := r.newContext(ctx, r)
+	return reconcileWithTimeout(reconcileCtx, r.timeout, func() (ctrl.Result, error) {
+		return r.handler(reconcileCtx, r, req)
+	})
+}
+
+// ControllerManagedBy returns the builder of the controller of the reconciler, named after it and using
+// the shared instrumentation, rate limiter and predicates of the factory.
+func (r *FactoryReconciler[ControllerResourceType, ContextType]) ControllerManagedBy() *instrument.InstrumentedBuilder {
+	blder := instrument.InstrumentedControllerManagedBy(r.Instrumenter, r.mgr).Named(r.name)
+	if len(r.predicates) > 0 {
+		blder = blder.WithForPredicates(NewNotPausedPredicate(r.predicates...))
+	}
+	if r.rateLimiter != nil {
+		blder = blder.WithRateLimiter(r.rateLimiter)
+	}
+	return blder
+}
@@ -0,0 +1,102 @@
+package ctrlfwk_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	ctrlfwk "github.com/u-ctf/controller-fwk"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+)
+
+// stubManager is a manager backed by a fake client, so that the tests don't need a cluster.
+// The methods it does not implement panic.
+type stubManager struct {
+	manager.Manager
+	client client.Client
+}
+
+func newStubManager(objs ...client.Object) *stubManager {
+	return &stubManager{client: fake.NewClientBuilder().WithObjects(objs...).Build()}
+}
+
+func (m *stubManager) GetClient() client.Client       { return m.client }
+func (m *stubManager) GetScheme() *runtime.Scheme     { return m.client.Scheme() }
+func (m *stubManager) GetRESTMapper() meta.RESTMapper { return m.client.RESTMapper() }
+func (m *stubManager) GetCache() cache.Cache          { return nil }
+func (m *stubManager) GetEventRecorderFor(string) record.EventRecorder {
+	return record.NewFakeRecorder(100)
+}
+
+func TestReconcilerFactory_SharesConfiguration(t *testing.T) {
+	mgr := newStubManager()
+
+	scheme := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(scheme); err != nil {
+		t.Fatalf("failed to build scheme: %v", err)
+	}
+	cr := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "cr", Namespace: "default"}}
+	sharedClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(cr).Build()
+
+	factory := ctrlfwk.NewReconcilerFactory(mgr, ctrlfwk.NewContext[*corev1.ConfigMap]).
+		WithSharedClient(sharedClient).
+		WithSharedScheme(scheme)
+
+	var handled []string
+	handler := func(ctx ctrlfwk.Context[*corev1.ConfigMap], reconciler ctrlfwk.Reconciler[*corev1.ConfigMap], req ctrl.Request) (ctrl.Result, error) {
+		if reconciler.Scheme() != scheme {
+			t.Fatalf("expected the shared scheme to be used")
+		}
+		if err := reconciler.Get(ctx, req.NamespacedName, &corev1.ConfigMap{}); err != nil {
+			t.Fatalf("expected the shared client to be used: %v", err)
+		}
+		handled = append(handled, req.Name)
+		return ctrl.Result{}, nil
+	}
+
+	first := factory.Build("first", handler)
+	second := factory.Build("second", handler)
+	if first.Name() != "first" || second.Name() != "second" {
+		t.Fatalf("unexpected controller names %q and %q", first.Name(), second.Name())
+	}
+
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Name: "cr", Namespace: "default"}}
+	for _, reconciler := range []*ctrlfwk.FactoryReconciler[*corev1.ConfigMap, ctrlfwk.Context[*corev1.ConfigMap]]{first, second} {
+		if _, err := reconciler.Reconcile(context.Background(), req); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if len(handled) != 2 {
+		t.Fatalf("expected both reconcilers to run their handler, got %v", handled)
+	}
+}
+
+func TestReconcilerFactory_WithReconcileTimeout(t *testing.T) {
+	reconciler := ctrlfwk.NewReconcilerFactory(newStubManager(), ctrlfwk.NewContext[*corev1.ConfigMap]).
+		WithReconcileTimeout(10*time.Millisecond).
+		Build("timeout", func(ctx ctrlfwk.Context[*corev1.ConfigMap], _ ctrlfwk.Reconciler[*corev1.ConfigMap], _ ctrl.Request) (ctrl.Result, error) {
+			// A hook doing network calls respects the deadline through the context
+			<-ctx.Done()
+			return ctrl.Result{}, ctx.Err()
+		})
+
+	result, err := reconciler.Reconcile(context.Background(), ctrl.Request{})
+	if err != nil {
+		t.Fatalf("expected the timeout to requeue instead of failing, got %v", err)
+	}
+	if result.RequeueAfter != 10*time.Millisecond {
+		t.Fatalf("expected a requeue after the timeout, got %v", result.RequeueAfter)
+	}
+}
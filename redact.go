@@ -0,0 +1,236 @@
+package ctrlfwk
+
+import (
+	"encoding/base64"
+	stderrors "errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// Redactor removes the sensitive data of obj from a message about it, e.g. an error, an event or a log field.
+//
+// It is only called for sensitive objects, that is secrets and the resources built with WithSensitive(true).
+// Reconcilers can replace the DefaultRedactor by implementing ReconcilerWithRedactor.
+type Redactor interface {
+	Redact(obj client.Object, message string) string
+}
+
+// RedactorFunc is a function implementing Redactor.
+type RedactorFunc func(obj client.Object, message string) string
+
+func (f RedactorFunc) Redact(obj client.Object, message string) string {
+	return f(obj, message)
+}
+
+// DefaultRedactor replaces the values of the sensitive fields of the object found in the message
+// by "[redacted, N bytes]", keeping the key names. The sensitive fields are data and stringData for secrets,
+// and every field but apiVersion, kind and metadata for the other objects.
+var DefaultRedactor Redactor = RedactorFunc(redactValues)
+
+// sensitiveResource is implemented by the resources built with WithSensitive.
+type sensitiveResource interface {
+	isSensitive() bool
+}
+
+// isSensitive tells whether the messages about obj, reconciled as resource, must be redacted.
+func isSensitive(resource any, obj client.Object) bool {
+	if sensitive, ok := resource.(sensitiveResource); ok && sensitive.isSensitive() {
+		return true
+	}
+	return isSecret(obj)
+}
+
+func isSecret(obj client.Object) bool {
+	switch typed := obj.(type) {
+	case *corev1.Secret:
+		return true
+	case *unstructured.Unstructured:
+		return isSecretGVK(typed.GroupVersionKind())
+	}
+	return false
+}
+
+func isSecretGVK(gvk schema.GroupVersionKind) bool {
+	return gvk.Group == "" && gvk.Kind == "Secret"
+}
+
+// redactorFor returns the redactor of the reconciler, DefaultRedactor if it does not provide one.
+func redactorFor(reconciler any) Redactor {
+	if withRedactor, ok := reconciler.(interface{ GetRedactor() Redactor }); ok {
+		if redactor := withRedactor.GetRedactor(); redactor != nil {
+			return redactor
+		}
+	}
+	return DefaultRedactor
+}
+
+// redactMessage redacts the message about obj when it is sensitive.
+func redactMessage(reconciler, resource any, obj client.Object, message string) string {
+	if obj == nil || !isSensitive(resource, obj) {
+		return message
+	}
+	return redactorFor(reconciler).Redact(obj, message)
+}
+
+// redactError redacts the error about obj when it is sensitive. The redacted error does not unwrap
+// to the original one, as error reporters like Sentry walk the chain of wrapped errors.
+func redactError(reconciler, resource any, obj client.Object, err error) error {
+	if err == nil {
+		return nil
+	}
+	message := err.Error()
+	redacted := redactMessage(reconciler, resource, obj, message)
+	if redacted == message {
+		return err
+	}
+
+	redactedErr := &redactedError{err: err, message: redacted}
+	var status apierrors.APIStatus
+	if stderrors.As(err, &status) {
+		return &redactedAPIError{redactedError: redactedErr, status: status.Status()}
+	}
+	return redactedErr
+}
+
+// redactedError hides the message of an error about a sensitive object, while still being recognized
+// by stderrors.Is, the API error helpers and ErrorClass.
+type redactedError struct {
+	err     error
+	message string
+}
+
+func (e *redactedError) Error() string {
+	return e.message
+}
+
+func (e *redactedError) Is(target error) bool {
+	return stderrors.Is(e.err, target)
+}
+
+func (e *redactedError) ErrorClass() string {
+	return ErrorClass(e.err)
+}
+
+// redactedAPIError is a redactedError of an API error, it allows the API error helpers
+// like apierrors.IsConflict to see through the redaction.
+type redactedAPIError struct {
+	*redactedError
+	status metav1.Status
+}
+
+func (e *redactedAPIError) Status() metav1.Status {
+	status := e.status
+	status.Message = e.message
+	// The details hold the causes of the error, with their messages
+	status.Details = nil
+	return status
+}
+
+// redactValues replaces the values of the sensitive fields of obj found in message.
+func redactValues(obj client.Object, message string) string {
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+	if err != nil {
+		return message
+	}
+
+	secret := isSecret(obj)
+	replacements := map[string]string{}
+	for field, value := range content {
+		if !isSensitiveField(secret, field) {
+			continue
+		}
+		collectSensitiveValues(value, secret && field == "data", replacements)
+	}
+
+	// The longest values are replaced first, so that a value containing another one is redacted whole
+	values := make([]string, 0, len(replacements))
+	for value := range replacements {
+		values = append(values, value)
+	}
+	sort.Slice(values, func(i, j int) bool { return len(values[i]) > len(values[j]) })
+
+	for _, value := range values {
+		message = strings.ReplaceAll(message, value, replacements[value])
+	}
+	return message
+}
+
+// isSensitiveField tells whether the top-level field of an object holds sensitive data.
+func isSensitiveField(secret bool, field string) bool {
+	if secret {
+		return field == "data" || field == "stringData"
+	}
+	return field != "apiVersion" && field != "kind" && field != "metadata"
+}
+
+// collectSensitiveValues adds the leaf values of value to replacements, in their raw and base64 encoded forms,
+// the values of secret data being base64 encoded already.
+func collectSensitiveValues(value any, base64Encoded bool, replacements map[string]string) {
+	switch typed := value.(type) {
+	case map[string]any:
+		for _, item := range typed {
+			collectSensitiveValues(item, base64Encoded, replacements)
+		}
+	case []any:
+		for _, item := range typed {
+			collectSensitiveValues(item, base64Encoded, replacements)
+		}
+	case nil:
+	default:
+		raw := fmt.Sprint(typed)
+		if base64Encoded {
+			if decoded, err := base64.StdEncoding.DecodeString(raw); err == nil {
+				raw = string(decoded)
+			}
+		}
+		if raw == "" {
+			return
+		}
+
+		replacement := redactedValue(raw)
+		replacements[raw] = replacement
+		replacements[base64.StdEncoding.EncodeToString([]byte(raw))] = replacement
+	}
+}
+
+func redactedValue(raw string) string {
+	return fmt.Sprintf("[redacted, %d bytes]", len(raw))
+}
+
+// redactStructure replaces the leaf values of value by "[redacted, N bytes]", keeping the key names.
+func redactStructure(value any, base64Encoded bool) any {
+	switch typed := value.(type) {
+	case map[string]any:
+		redacted := make(map[string]any, len(typed))
+		for key, item := range typed {
+			redacted[key] = redactStructure(item, base64Encoded)
+		}
+		return redacted
+	case []any:
+		redacted := make([]any, len(typed))
+		for i, item := range typed {
+			redacted[i] = redactStructure(item, base64Encoded)
+		}
+		return redacted
+	case nil:
+		return nil
+	default:
+		raw := fmt.Sprint(typed)
+		if base64Encoded {
+			if decoded, err := base64.StdEncoding.DecodeString(raw); err == nil {
+				raw = string(decoded)
+			}
+		}
+		return redactedValue(raw)
+	}
+}
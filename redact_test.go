@@ -0,0 +1,197 @@
+package ctrlfwk_test
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/go-logr/logr"
+	ctrlfwk "github.com/u-ctf/controller-fwk"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+)
+
+const secretValue = "hunter2-super-secret"
+
+type recordingReconciler struct {
+	*fakeReconciler
+	*record.FakeRecorder
+}
+
+// errorMessages returns the messages of err and of the errors it wraps, as collected by error reporters like Sentry.
+func errorMessages(err error) []string {
+	if err == nil {
+		return nil
+	}
+	messages := []string{err.Error()}
+	switch wrapping := err.(type) {
+	case interface{ Unwrap() []error }:
+		for _, inner := range wrapping.Unwrap() {
+			messages = append(messages, errorMessages(inner)...)
+		}
+	case interface{ Unwrap() error }:
+		messages = append(messages, errorMessages(wrapping.Unwrap())...)
+	case interface{ Cause() error }:
+		messages = append(messages, errorMessages(wrapping.Cause())...)
+	}
+	return messages
+}
+
+func assertRedacted(t *testing.T, messages ...string) {
+	t.Helper()
+
+	encoded := base64.StdEncoding.EncodeToString([]byte(secretValue))
+	for _, message := range messages {
+		if strings.Contains(message, secretValue) || strings.Contains(message, encoded) {
+			t.Fatalf("expected the secret value to be redacted, got %q", message)
+		}
+	}
+}
+
+func invalidSecretError(obj client.Object) error {
+	value := base64.StdEncoding.EncodeToString([]byte(secretValue))
+	return apierrors.NewInvalid(schema.GroupKind{Kind: "Secret"}, obj.GetName(), field.ErrorList{
+		field.Invalid(field.NewPath("data").Key("password"), value, "field is immutable"),
+	})
+}
+
+func TestReconcileResourceStep_RedactsSecretValues(t *testing.T) {
+	ctx, reconciler := newDeletionTest(t, interceptor.Funcs{
+		Create: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.CreateOption) error {
+			return invalidSecretError(obj)
+		},
+	})
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Name: "cr", Namespace: "default"}}
+
+	resource := ctrlfwk.NewResourceBuilder(ctx, &corev1.Secret{}).
+		WithKey(types.NamespacedName{Name: "credentials", Namespace: "default"}).
+		WithMutator(func(secret *corev1.Secret) error {
+			secret.Data = map[string][]byte{"password": []byte(secretValue)}
+			return nil
+		}).
+		Build()
+
+	_, err := ctrlfwk.NewReconcileResourceStep(ctx, reconciler, resource).Step(ctx, logr.Discard(), req).Normal()
+	if err == nil {
+		t.Fatal("expected the creation to fail")
+	}
+	if !apierrors.IsInvalid(err) {
+		t.Fatalf("expected the redacted error to still be an invalid error, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "[redacted, 20 bytes]") {
+		t.Fatalf("expected the value to be replaced, got %q", err.Error())
+	}
+	assertRedacted(t, errorMessages(err)...)
+}
+
+func TestReconcileResourceStep_RedactsSensitiveResources(t *testing.T) {
+	ctx, base := newDeletionTest(t, interceptor.Funcs{
+		Patch: func(ctx context.Context, c client.WithWatch, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+			return invalidSecretError(obj)
+		},
+	})
+	reconciler := &recordingReconciler{fakeReconciler: base, FakeRecorder: record.NewFakeRecorder(10)}
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Name: "cr", Namespace: "default"}}
+
+	// A ConfigMap stands for a credentials custom resource
+	resource := ctrlfwk.NewResourceBuilder(ctx, &corev1.ConfigMap{}).
+		WithKey(types.NamespacedName{Name: "credentials", Namespace: "default"}).
+		WithMutator(func(cm *corev1.ConfigMap) error {
+			cm.Data = map[string]string{"token": secretValue}
+			return nil
+		}).
+		WithImmutableFields("/metadata/labels/app").
+		WithRecreateOnImmutableFieldConflict(true).
+		WithSensitive(true).
+		Build()
+	step := ctrlfwk.NewReconcileResourceStep(ctx, reconciler, resource)
+
+	if _, err := step.Step(ctx, logr.Discard(), req).Normal(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	live := &corev1.ConfigMap{}
+	if err := reconciler.Get(ctx, types.NamespacedName{Name: "credentials", Namespace: "default"}, live); err != nil {
+		t.Fatalf("failed to get configmap: %v", err)
+	}
+	live.Data["token"] = "rotated"
+	if err := reconciler.Update(ctx, live); err != nil {
+		t.Fatalf("failed to update configmap: %v", err)
+	}
+
+	// The update is rejected, the API error quoting the value of the mutator
+	if _, err := step.Step(ctx, logr.Discard(), req).Normal(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	close(reconciler.Events)
+	var events []string
+	for event := range reconciler.Events {
+		events = append(events, event)
+	}
+	if len(events) == 0 {
+		t.Fatal("expected an immutable field conflict event")
+	}
+	assertRedacted(t, events...)
+
+	failing := ctrlfwk.NewResourceBuilder(ctx, &corev1.ConfigMap{}).
+		WithKey(types.NamespacedName{Name: "failing", Namespace: "default"}).
+		WithMutator(func(cm *corev1.ConfigMap) error {
+			cm.Data = map[string]string{"token": secretValue}
+			return errors.New("failed to validate token " + secretValue)
+		}).
+		WithSensitive(true).
+		Build()
+
+	_, err := ctrlfwk.NewReconcileResourceStep(ctx, reconciler, failing).Step(ctx, logr.Discard(), req).Normal()
+	if !errors.Is(err, ctrlfwk.ErrMutatorFailed) {
+		t.Fatalf("expected a mutator error, got %v", err)
+	}
+	assertRedacted(t, errorMessages(err)...)
+}
+
+func TestPlanResources_RedactsSecretDiff(t *testing.T) {
+	cr := &corev1.ConfigMap{}
+	cr.SetName("cr")
+	cr.SetNamespace("default")
+
+	planClient := ctrlfwk.NewPlanClient(clientgoscheme.Scheme, cr)
+	reconciler := &fakeReconcilerWithResources{fakeReconciler: &fakeReconciler{Client: planClient}}
+	ctx := ctrlfwk.NewContext[*corev1.ConfigMap](context.Background(), reconciler)
+
+	reconciler.resources = []ctrlfwk.GenericResource[*corev1.ConfigMap, ctrlfwk.Context[*corev1.ConfigMap]]{
+		ctrlfwk.NewResourceBuilder(ctx, &corev1.Secret{}).
+			WithKey(types.NamespacedName{Name: "credentials", Namespace: "default"}).
+			WithMutator(func(secret *corev1.Secret) error {
+				secret.Data = map[string][]byte{"password": []byte(secretValue)}
+				return nil
+			}).
+			WithReadinessCondition(func(*corev1.Secret) bool { return true }).
+			Build(),
+	}
+
+	plan, err := ctrlfwk.PlanResources(ctx, reconciler, planClient, cr)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var diffs []string
+	for _, change := range plan.Changes {
+		diffs = append(diffs, change.Diff.String())
+	}
+	if len(diffs) == 0 || !strings.Contains(strings.Join(diffs, ""), "password") {
+		t.Fatalf("expected the diff to keep the key names, got %v", diffs)
+	}
+	assertRedacted(t, diffs...)
+}
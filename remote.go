@@ -0,0 +1,99 @@
+package ctrlfwk
+
+import (
+	"fmt"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+const (
+	// ConditionTypeRemoteClusterUnavailable is set on the custom resource when the client of a resource
+	// or dependency of a remote cluster can't be built, see ResourceBuilder.WithClientFunc.
+	ConditionTypeRemoteClusterUnavailable = "RemoteClusterUnavailable"
+)
+
+// remoteClusterRequeueInterval is the delay before reconciling again when a remote cluster can't be reached,
+// or when a resource of a remote cluster is not ready, as it can't be watched.
+const remoteClusterRequeueInterval = 30 * time.Second
+
+// remoteClientObject is implemented by the resources and dependencies that can be built with WithClient.
+type remoteClientObject[ContextType any] interface {
+	// remoteClient returns the client of the cluster of the object, false if it is reconciled with the reconciler.
+	remoteClient(ctx ContextType) (client.Client, bool, error)
+}
+
+// clientFor returns the client used to reconcile the resource or dependency identified by id, the reconciler
+// unless it was built with WithClient or WithClientFunc. When the client of a remote cluster can't be built,
+// the RemoteClusterUnavailable condition is set and the reconciliation is requeued.
+func clientFor[
+	ControllerResourceType ControllerCustomResource,
+	ContextType Context[ControllerResourceType],
+](
+	ctx ContextType,
+	reconciler Reconciler[ControllerResourceType],
+	obj any,
+	id string,
+) (client.Client, bool, StepResult) {
+	remote, ok := obj.(remoteClientObject[ContextType])
+	if !ok {
+		return reconciler, false, ResultSuccess()
+	}
+
+	var c client.Client
+	var isRemote bool
+	err := runOperation(ctx, "Client", func() error {
+		var err error
+		c, isRemote, err = remote.remoteClient(ctx)
+		return err
+	})
+	if err == nil && isRemote && c == nil {
+		err = fmt.Errorf("client func returned a nil client")
+	}
+	if err != nil {
+		logf.FromContext(ctx).Info("Remote cluster is unavailable, requeueing", "reason", err.Error())
+		if err := setRemoteClusterUnavailableCondition(ctx, reconciler, id, err); err != nil {
+			return nil, false, ResultInError(fmt.Errorf("failed to set remote cluster unavailable condition: %w", err))
+		}
+		return nil, false, ResultRequeueIn(remoteClusterRequeueInterval).WithRequeueReason(RequeueReasonRemoteClusterUnavailable)
+	}
+	if !isRemote {
+		return reconciler, false, ResultSuccess()
+	}
+
+	if err := setRemoteClusterUnavailableCondition(ctx, reconciler, id, nil); err != nil {
+		return nil, false, ResultInError(fmt.Errorf("failed to remove remote cluster unavailable condition: %w", err))
+	}
+	return c, true, ResultSuccess()
+}
+
+// setRemoteClusterUnavailableCondition reflects a remote cluster that can't be reached on the custom resource status,
+// the condition is removed once the client of a remote cluster is built.
+func setRemoteClusterUnavailableCondition[
+	ControllerResourceType ControllerCustomResource,
+	ContextType Context[ControllerResourceType],
+](
+	ctx ContextType,
+	reconciler Reconciler[ControllerResourceType],
+	id string,
+	clientErr error,
+) error {
+	defer LockContext(ctx)()
+
+	cr := ctx.GetCustomResource()
+
+	if clientErr == nil {
+		_, err := applyCondition(ctx, reconciler, ConditionTypeRemoteClusterUnavailable, nil)
+		return err
+	}
+
+	_, err := applyCondition(ctx, reconciler, ConditionTypeRemoteClusterUnavailable, &metav1.Condition{
+		Status:             metav1.ConditionTrue,
+		Reason:             "ClientUnavailable",
+		Message:            fmt.Sprintf("cluster of %s is unavailable: %v", id, clientErr),
+		ObservedGeneration: cr.GetGeneration(),
+	})
+	return err
+}
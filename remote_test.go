@@ -0,0 +1,81 @@
+package ctrlfwk_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/go-logr/logr"
+	ctrlfwk "github.com/u-ctf/controller-fwk"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+)
+
+func TestReconcileResourceStep_RemoteClient(t *testing.T) {
+	ctx, reconciler := newDeletionTest(t, interceptor.Funcs{})
+	cr := ctx.GetCustomResource()
+	cr.SetUID("cr-uid")
+
+	remote := fake.NewClientBuilder().Build()
+	key := types.NamespacedName{Name: "remote", Namespace: "default"}
+
+	resource := ctrlfwk.NewResourceBuilder(ctx, &corev1.ConfigMap{}).
+		WithKey(key).
+		WithClient(remote).
+		WithReadinessCondition(func(*corev1.ConfigMap) bool { return true }).
+		Build()
+	step := ctrlfwk.NewReconcileResourceStep(ctx, reconciler, resource)
+
+	if result := step.Step(ctx, logr.Discard(), ctrl.Request{}); result.ShouldReturn() {
+		t.Fatalf("unexpected result: %v", result)
+	}
+
+	created := &corev1.ConfigMap{}
+	if err := remote.Get(ctx, key, created); err != nil {
+		t.Fatalf("expected the resource to be created in the remote cluster: %v", err)
+	}
+	if len(created.OwnerReferences) != 0 {
+		t.Fatalf("expected no owner references across clusters, got %v", created.OwnerReferences)
+	}
+	if created.Labels[ctrlfwk.LabelTrackingOwnerUID] != "cr-uid" {
+		t.Fatalf("expected the tracking labels to be set, got %v", created.Labels)
+	}
+	if err := reconciler.Get(ctx, key, &corev1.ConfigMap{}); !apierrors.IsNotFound(err) {
+		t.Fatalf("expected the resource not to be created in the cluster of the reconciler, got %v", err)
+	}
+
+	// Remote resources are not garbage collected along with the custom resource
+	now := metav1.Now()
+	cr.SetDeletionTimestamp(&now)
+	if result := step.Step(ctx, logr.Discard(), ctrl.Request{}); result.ShouldReturn() {
+		t.Fatalf("unexpected result: %v", result)
+	}
+	if err := remote.Get(ctx, key, &corev1.ConfigMap{}); !apierrors.IsNotFound(err) {
+		t.Fatalf("expected the resource to be deleted from the remote cluster on finalization, got %v", err)
+	}
+}
+
+func TestReconcileResourceStep_RemoteClientUnavailable(t *testing.T) {
+	ctx, reconciler := newDeletionTest(t, interceptor.Funcs{})
+
+	resource := ctrlfwk.NewResourceBuilder(ctx, &corev1.ConfigMap{}).
+		WithKey(types.NamespacedName{Name: "remote", Namespace: "default"}).
+		WithClientFunc(func(ctrlfwk.Context[*corev1.ConfigMap]) (client.Client, error) {
+			return nil, errors.New("kubeconfig secret not found")
+		}).
+		Build()
+
+	result := ctrlfwk.NewReconcileResourceStep(ctx, reconciler, resource).Step(ctx, logr.Discard(), ctrl.Request{})
+	if result.RequeueReason() != ctrlfwk.RequeueReasonRemoteClusterUnavailable {
+		t.Fatalf("expected a requeue because of the remote cluster, got %v", result)
+	}
+	if res, err := result.Normal(); err != nil || res.RequeueAfter == 0 {
+		t.Fatalf("expected a requeue without error, got %v, %v", res, err)
+	}
+}
@@ -0,0 +1,277 @@
+package ctrlfwk
+
+import (
+	"fmt"
+	"maps"
+	"strings"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+// ImplementsReconcileReport gives access to the report of the current reconciliation,
+// populated by the framework steps as they run.
+type ImplementsReconcileReport interface {
+	// GetReconcileReport returns the report of the current reconciliation.
+	GetReconcileReport() *ReconcileReport
+	// DependencyState returns the state of the dependency with the given ID, as resolved during the current
+	// reconciliation, e.g. for a mutator to enable a feature only when an optional dependency exists.
+	// It is DependencyStateUnresolved until the dependency is resolved.
+	DependencyState(id string) DependencyState
+}
+
+// ReconcileReporting holds the report of the reconciliation in the context.
+type ReconcileReporting struct {
+	report ReconcileReport
+}
+
+var _ ImplementsReconcileReport = &ReconcileReporting{}
+
+func (r *ReconcileReporting) GetReconcileReport() *ReconcileReport {
+	return &r.report
+}
+
+func (r *ReconcileReporting) DependencyState(id string) DependencyState {
+	r.report.mu.Lock()
+	defer r.report.mu.Unlock()
+
+	for _, dependency := range r.report.Dependencies {
+		if dependency.ID == id {
+			return dependency.State
+		}
+	}
+	return DependencyStateUnresolved
+}
+
+// ReconcileReport describes what happened during a reconciliation: the resources reconciled,
+// the dependencies resolved and the time spent in each step. It can be used to write a summary
+// of the reconciliation into the status of the custom resource, see NewSummaryStatusStep.
+//
+// The report only contains the resources and dependencies whose step ran, a step returning
+// early preventing the following ones from running.
+type ReconcileReport struct {
+	mu sync.Mutex
+
+	Resources    []ResourceReport
+	Dependencies []DependencyReport
+	Steps        []StepReport
+
+	// tracked holds the keys of the resources the tracking labels were set on, grouped by GVK, see trackResource
+	tracked map[schema.GroupVersionKind]map[types.NamespacedName]bool
+}
+
+// ResourceReport describes the reconciliation of a resource.
+type ResourceReport struct {
+	ID        string
+	Kind      string
+	Key       types.NamespacedName
+	Operation controllerutil.OperationResult
+	Ready     bool
+	Error     error
+}
+
+// DependencyReport describes the resolution of a dependency.
+type DependencyReport struct {
+	ID       string
+	Found    bool
+	Ready    bool
+	Optional bool
+	State    DependencyState
+}
+
+// DependencyState is the state of a dependency once resolved, see ImplementsReconcileReport.DependencyState.
+type DependencyState string
+
+const (
+	// DependencyStateUnresolved is the state of the dependencies that were not resolved yet, or failed to resolve.
+	DependencyStateUnresolved DependencyState = ""
+	// DependencyStateMissing is the state of the required dependencies that don't exist.
+	DependencyStateMissing DependencyState = "Missing"
+	// DependencyStateNotReady is the state of the dependencies that exist but are not ready.
+	DependencyStateNotReady DependencyState = "NotReady"
+	// DependencyStateReady is the state of the dependencies that exist and are ready.
+	DependencyStateReady DependencyState = "Ready"
+	// DependencyStateSkippedOptional is the state of the optional dependencies that don't exist.
+	DependencyStateSkippedOptional DependencyState = "SkippedOptional"
+)
+
+// dependencyStateFromOutcome returns the state of a dependency resolved with outcome.
+func dependencyStateFromOutcome(outcome DependencyOutcome, optional bool) DependencyState {
+	switch outcome {
+	case DependencyOutcomeFound:
+		return DependencyStateReady
+	case DependencyOutcomeNotReady:
+		return DependencyStateNotReady
+	case DependencyOutcomeNotFound:
+		if optional {
+			return DependencyStateSkippedOptional
+		}
+		return DependencyStateMissing
+	}
+	return DependencyStateUnresolved
+}
+
+// StepReport describes the execution of a step.
+type StepReport struct {
+	Name     string
+	Duration time.Duration
+}
+
+// recordResource adds the resource to the report, replacing a previous report of the same resource.
+func (r *ReconcileReport) recordResource(resource ResourceReport) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i := range r.Resources {
+		if r.Resources[i].ID == resource.ID {
+			r.Resources[i] = resource
+			return
+		}
+	}
+	r.Resources = append(r.Resources, resource)
+}
+
+// recordTrackedResource adds the resource to the resources the tracking labels were set on.
+func (r *ReconcileReport) recordTrackedResource(gvk schema.GroupVersionKind, key types.NamespacedName) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.tracked == nil {
+		r.tracked = make(map[schema.GroupVersionKind]map[types.NamespacedName]bool)
+	}
+	if r.tracked[gvk] == nil {
+		r.tracked[gvk] = make(map[types.NamespacedName]bool)
+	}
+	r.tracked[gvk][key] = true
+}
+
+// trackedResources returns a copy of the resources the tracking labels were set on, grouped by GVK.
+func (r *ReconcileReport) trackedResources() map[schema.GroupVersionKind]map[types.NamespacedName]bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	out := make(map[schema.GroupVersionKind]map[types.NamespacedName]bool, len(r.tracked))
+	for gvk, keys := range r.tracked {
+		out[gvk] = maps.Clone(keys)
+	}
+	return out
+}
+
+// recordDependency adds the dependency to the report, replacing a previous report of the same dependency.
+func (r *ReconcileReport) recordDependency(dependency DependencyReport) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i := range r.Dependencies {
+		if r.Dependencies[i].ID == dependency.ID {
+			r.Dependencies[i] = dependency
+			return
+		}
+	}
+	r.Dependencies = append(r.Dependencies, dependency)
+}
+
+func (r *ReconcileReport) recordStep(step StepReport) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.Steps = append(r.Steps, step)
+}
+
+// Duration returns the time spent in the steps executed so far.
+func (r *ReconcileReport) Duration() time.Duration {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var total time.Duration
+	for _, step := range r.Steps {
+		total += step.Duration
+	}
+	return total
+}
+
+// IsReady returns true if every reported resource is ready and every required dependency is ready.
+func (r *ReconcileReport) IsReady() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, resource := range r.Resources {
+		if !resource.Ready || resource.Error != nil {
+			return false
+		}
+	}
+	for _, dependency := range r.Dependencies {
+		if !dependency.Optional && !dependency.Ready {
+			return false
+		}
+	}
+	return true
+}
+
+// HasErrors returns true if the reconciliation of a reported resource failed.
+func (r *ReconcileReport) HasErrors() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, resource := range r.Resources {
+		if resource.Error != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// Summary renders the report as a human readable message, e.g.
+// "3 resources up to date, 1 waiting (Deployment frontend not ready), dependencies ok".
+func (r *ReconcileReport) Summary() string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	upToDate := 0
+	var waiting, failing []string
+	for _, resource := range r.Resources {
+		switch {
+		case resource.Error != nil:
+			failing = append(failing, fmt.Sprintf("%s %s: %v", resource.Kind, resource.Key.Name, resource.Error))
+		case !resource.Ready:
+			waiting = append(waiting, fmt.Sprintf("%s %s not ready", resource.Kind, resource.Key.Name))
+		default:
+			upToDate++
+		}
+	}
+
+	var parts []string
+	if len(r.Resources) > 0 {
+		parts = append(parts, fmt.Sprintf("%d resources up to date", upToDate))
+	}
+	if len(waiting) > 0 {
+		parts = append(parts, fmt.Sprintf("%d waiting (%s)", len(waiting), strings.Join(waiting, ", ")))
+	}
+	if len(failing) > 0 {
+		parts = append(parts, fmt.Sprintf("%d failing (%s)", len(failing), strings.Join(failing, ", ")))
+	}
+
+	var unresolved []string
+	for _, dependency := range r.Dependencies {
+		switch {
+		case dependency.Optional:
+		case !dependency.Found:
+			unresolved = append(unresolved, dependency.ID+" not found")
+		case !dependency.Ready:
+			unresolved = append(unresolved, dependency.ID+" not ready")
+		}
+	}
+	if len(unresolved) > 0 {
+		parts = append(parts, fmt.Sprintf("dependencies waiting (%s)", strings.Join(unresolved, ", ")))
+	} else if len(r.Dependencies) > 0 {
+		parts = append(parts, "dependencies ok")
+	}
+
+	if len(parts) == 0 {
+		return "nothing to reconcile"
+	}
+	return strings.Join(parts, ", ")
+}
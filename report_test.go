@@ -0,0 +1,57 @@
+package ctrlfwk_test
+
+import (
+	"testing"
+
+	"github.com/go-logr/logr"
+	ctrlfwk "github.com/u-ctf/controller-fwk"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+func TestReconcileReport_Summary(t *testing.T) {
+	ctx, reconciler := newDeletionTest(t, interceptor.Funcs{})
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Name: "cr", Namespace: "default"}}
+
+	frontend := ctrlfwk.NewResourceBuilder(ctx, &corev1.ConfigMap{}).
+		WithKey(types.NamespacedName{Name: "frontend", Namespace: "default"}).
+		WithUserIdentifier("frontend").
+		WithReadinessCondition(func(*corev1.ConfigMap) bool { return false }).
+		Build()
+	dependency := ctrlfwk.NewDependencyBuilder(ctx, &corev1.Secret{}).
+		WithName("secret").
+		WithNamespace("default").
+		WithUserIdentifier("secret").
+		Build()
+
+	steps := []ctrlfwk.Step[*corev1.ConfigMap, ctrlfwk.Context[*corev1.ConfigMap]]{
+		ctrlfwk.NewResolveDependencyStep(ctx, reconciler, dependency),
+		ctrlfwk.NewReconcileResourceStep(ctx, reconciler, newConfigMapResource(ctx, "backend")),
+		ctrlfwk.NewReconcileResourceStep(ctx, reconciler, frontend),
+	}
+	for _, step := range steps {
+		if _, err := step.Step(ctx, logr.Discard(), req).Normal(); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	report := ctx.GetReconcileReport()
+	if len(report.Resources) != 2 || len(report.Dependencies) != 1 {
+		t.Fatalf("expected 2 resources and 1 dependency, got %+v", report)
+	}
+	if report.Resources[0].Operation != controllerutil.OperationResultCreated {
+		t.Fatalf("expected backend to be created, got %q", report.Resources[0].Operation)
+	}
+	if report.IsReady() {
+		t.Fatal("expected the report not to be ready while frontend is not ready")
+	}
+
+	expected := "1 resources up to date, 1 waiting (ConfigMap frontend not ready), dependencies ok"
+	if summary := report.Summary(); summary != expected {
+		t.Fatalf("expected summary %q, got %q", expected, summary)
+	}
+}
@@ -0,0 +1,40 @@
+package ctrlfwk
+
+import (
+	"context"
+
+	"k8s.io/apimachinery/pkg/util/uuid"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+type requestIDKey struct{}
+
+// GetRequestID returns the ID of the reconciliation ctx belongs to, empty outside of a reconciliation.
+// The ID is a UUID generated by the Stepper when it starts executing, it is added to the log lines and spans
+// of the framework, and can be added by hooks to their own log lines to correlate them.
+//
+// Example:
+//
+//	logger.Info("Calling the provider API", "requestID", ctrlfwk.GetRequestID(ctx))
+func GetRequestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// startRequest generates the ID of the reconciliation and stores it in the context, unless it already has one,
+// until restore is called.
+func startRequest(ctx spanScope) (id string, restore func()) {
+	parent := ctx.GetParentContext()
+	if id := GetRequestID(parent); id != "" {
+		return id, func() {}
+	}
+
+	id = string(uuid.NewUUID())
+	requestCtx := context.WithValue(parent, requestIDKey{}, id)
+	// Middlewares log using the logger of the context, the steps using the logger of the stepper
+	requestCtx = logf.IntoContext(requestCtx, logf.FromContext(parent).WithValues("requestID", id))
+	ctx.SetParentContext(requestCtx)
+	return id, func() {
+		ctx.SetParentContext(parent)
+	}
+}
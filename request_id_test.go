@@ -0,0 +1,49 @@
+package ctrlfwk_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/go-logr/logr"
+	"github.com/go-logr/logr/funcr"
+	ctrlfwk "github.com/u-ctf/controller-fwk"
+
+	corev1 "k8s.io/api/core/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+func TestStepper_RequestID(t *testing.T) {
+	ctx := ctrlfwk.NewContext[*corev1.ConfigMap](context.Background(), nil)
+
+	var lines []string
+	logger := funcr.New(func(prefix, args string) {
+		lines = append(lines, args)
+	}, funcr.Options{})
+
+	var requestIDs []string
+	stepper := ctrlfwk.NewStepperFor(ctx, logger).
+		WithStep(ctrlfwk.NewStep("step", func(ctx testContext, _ logr.Logger, _ ctrl.Request) ctrlfwk.StepResult {
+			requestIDs = append(requestIDs, ctrlfwk.GetRequestID(ctx))
+			return ctrlfwk.ResultSuccess()
+		})).
+		Build()
+
+	for range 2 {
+		if _, err := stepper.Execute(ctx, ctrl.Request{}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	if len(requestIDs) != 2 || requestIDs[0] == "" || requestIDs[0] == requestIDs[1] {
+		t.Fatalf("expected a different request ID per execution, got %v", requestIDs)
+	}
+	if ctrlfwk.GetRequestID(ctx) != "" {
+		t.Fatal("expected the request ID to be cleared after the execution")
+	}
+	for _, line := range lines {
+		if !strings.Contains(line, `"requestID"=`) {
+			t.Fatalf("expected every log line to hold the request ID, got %s", line)
+		}
+	}
+}
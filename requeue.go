@@ -0,0 +1,112 @@
+package ctrlfwk
+
+import (
+	"encoding/binary"
+	"hash/fnv"
+	"math"
+	"time"
+
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// JitterRequeue spreads the requeue delay d across ±factor of its value (e.g. 0.2 for ±20%).
+//
+// The jitter is deterministic for a given object within a window as long as d, so reconciling
+// the same object several times in a row does not keep pushing it back, while different objects
+// requeued at the same time are spread across the whole range.
+func JitterRequeue(d time.Duration, factor float64, key types.NamespacedName, now time.Time) time.Duration {
+	if factor <= 0 || d <= 0 {
+		return d
+	}
+
+	window := now.UnixNano() / int64(d)
+
+	hash := fnv.New64a()
+	_, _ = hash.Write([]byte(key.String()))
+	_ = binary.Write(hash, binary.LittleEndian, window)
+
+	ratio := float64(hash.Sum64()) / float64(math.MaxUint64)
+
+	return time.Duration(float64(d) * (1 + factor*(2*ratio-1)))
+}
+
+// RequeueReason tells why a reconciliation was requeued or returned early,
+// it is logged and recorded on the ctrlfwk_reconcile_requeue_total metric.
+type RequeueReason string
+
+const (
+	// RequeueReasonUnknown is used when a step requeues without giving a reason.
+	RequeueReasonUnknown RequeueReason = "Unknown"
+	// RequeueReasonRequested is used for requeues requested through the context without a reason.
+	RequeueReasonRequested RequeueReason = "Requested"
+	// RequeueReasonWaitingOnExternal is meant for steps and hooks waiting on a system outside of the cluster.
+	RequeueReasonWaitingOnExternal RequeueReason = "WaitingOnExternal"
+
+	RequeueReasonDependencyNotFound        RequeueReason = "DependencyNotFound"
+	RequeueReasonDependencyNotReady        RequeueReason = "DependencyNotReady"
+	RequeueReasonDependencyCircuitOpen     RequeueReason = "DependencyCircuitOpen"
+	RequeueReasonResourceNotReady          RequeueReason = "ResourceNotReady"
+	RequeueReasonResourceRecreated         RequeueReason = "ResourceRecreated"
+	RequeueReasonResourceInvariantViolated RequeueReason = "ResourceInvariantViolated"
+	RequeueReasonResourceInvalid           RequeueReason = "ResourceInvalid"
+	RequeueReasonDeletionSkipped           RequeueReason = "DeletionSkipped"
+	RequeueReasonFinalizationBlocked       RequeueReason = "FinalizationBlocked"
+	RequeueReasonFinalizationInProgress    RequeueReason = "FinalizationInProgress"
+	RequeueReasonNotFoundWhileFinalizing   RequeueReason = "NotFoundWhileFinalizing"
+	RequeueReasonCustomResourceInvalid     RequeueReason = "CustomResourceInvalid"
+	RequeueReasonRemoteClusterUnavailable  RequeueReason = "RemoteClusterUnavailable"
+	RequeueReasonRollingUpdateInProgress   RequeueReason = "RollingUpdateInProgress"
+	RequeueReasonReadinessExpired          RequeueReason = "ReadinessExpired"
+	RequeueReasonPossibleReconcileLoop     RequeueReason = "PossibleReconcileLoop"
+	RequeueReasonTimeout                   RequeueReason = "Timeout"
+	RequeueReasonTerminalError             RequeueReason = "TerminalError"
+	RequeueReasonThrottled                 RequeueReason = "Throttled"
+)
+
+// ImplementsRequeueRequest allows hooks and steps to ask for the custom resource to be reconciled again later on,
+// without returning an error, e.g. to check a certificate again before it expires.
+type ImplementsRequeueRequest interface {
+	// RequeueAfter asks for the custom resource to be reconciled again after d.
+	// When called several times, the shortest delay wins.
+	RequeueAfter(d time.Duration)
+	// RequeueAfterWithReason is like RequeueAfter, the reason of the shortest delay being reported by the stepper.
+	RequeueAfterWithReason(d time.Duration, reason RequeueReason)
+	// GetRequeueAfter returns the shortest delay requested using RequeueAfter, 0 if none was requested.
+	GetRequeueAfter() time.Duration
+	// GetRequeueReason returns the reason of the shortest delay requested, RequeueReasonRequested if none was given.
+	GetRequeueReason() RequeueReason
+}
+
+// RequeueRequest holds the requeue delay requested through the context,
+// it is honored by the stepper once the steps are executed.
+type RequeueRequest struct {
+	requeueAfter  time.Duration
+	requeueReason RequeueReason
+}
+
+var _ ImplementsRequeueRequest = &RequeueRequest{}
+
+func (r *RequeueRequest) RequeueAfter(d time.Duration) {
+	r.RequeueAfterWithReason(d, RequeueReasonRequested)
+}
+
+func (r *RequeueRequest) RequeueAfterWithReason(d time.Duration, reason RequeueReason) {
+	if d <= 0 {
+		return
+	}
+	if r.requeueAfter == 0 || d < r.requeueAfter {
+		r.requeueAfter = d
+		r.requeueReason = reason
+	}
+}
+
+func (r *RequeueRequest) GetRequeueAfter() time.Duration {
+	return r.requeueAfter
+}
+
+func (r *RequeueRequest) GetRequeueReason() RequeueReason {
+	if r.requeueReason == "" {
+		return RequeueReasonRequested
+	}
+	return r.requeueReason
+}
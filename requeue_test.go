@@ -0,0 +1,215 @@
+package ctrlfwk_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	ctrlfwk "github.com/u-ctf/controller-fwk"
+	"github.com/u-ctf/controller-fwk/faultinject"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+func TestJitterRequeue_Bounds(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	d := 30 * time.Second
+
+	for i := range 100 {
+		key := types.NamespacedName{Namespace: "default", Name: string(rune('a' + i%26))}
+		got := ctrlfwk.JitterRequeue(d, 0.2, key, now.Add(time.Duration(i)*time.Second))
+		if got < 24*time.Second || got > 36*time.Second {
+			t.Fatalf("expected delay within ±20%% of %s, got %s", d, got)
+		}
+	}
+}
+
+func TestJitterRequeue_Deterministic(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	key := types.NamespacedName{Namespace: "default", Name: "test"}
+
+	first := ctrlfwk.JitterRequeue(time.Minute, 0.5, key, now)
+	second := ctrlfwk.JitterRequeue(time.Minute, 0.5, key, now)
+	if first != second {
+		t.Fatalf("expected the same delay for the same object, got %s and %s", first, second)
+	}
+}
+
+func TestJitterRequeue_Disabled(t *testing.T) {
+	key := types.NamespacedName{Namespace: "default", Name: "test"}
+
+	if got := ctrlfwk.JitterRequeue(time.Minute, 0, key, time.Now()); got != time.Minute {
+		t.Fatalf("expected no jitter, got %s", got)
+	}
+}
+
+func TestStepper_HonorsShortestRequestedRequeue(t *testing.T) {
+	ctx := ctrlfwk.NewContext[*corev1.ConfigMap](context.Background(), nil)
+	requestRequeue := func(d time.Duration) ctrlfwk.Step[*corev1.ConfigMap, ctrlfwk.Context[*corev1.ConfigMap]] {
+		return ctrlfwk.NewStep("request requeue", func(ctx ctrlfwk.Context[*corev1.ConfigMap], _ logr.Logger, _ ctrl.Request) ctrlfwk.StepResult {
+			ctx.RequeueAfter(d)
+			return ctrlfwk.ResultSuccess()
+		})
+	}
+
+	stepper := ctrlfwk.NewStepperFor(ctx, logr.Discard()).
+		WithStep(requestRequeue(5 * time.Minute)).
+		WithStep(requestRequeue(time.Minute)).
+		WithStep(requestRequeue(10 * time.Minute)).
+		Build()
+
+	result, err := stepper.Execute(ctx, ctrl.Request{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.RequeueAfter != time.Minute {
+		t.Fatalf("expected a requeue after 1m, got %s", result.RequeueAfter)
+	}
+}
+
+func TestStepper_RecordsRequeueReason(t *testing.T) {
+	ctx := ctrlfwk.NewContext[*corev1.ConfigMap](context.Background(), nil)
+
+	stepper := ctrlfwk.NewStepperFor(ctx, logr.Discard()).
+		WithStep(ctrlfwk.NewStep("wait on external", func(ctx ctrlfwk.Context[*corev1.ConfigMap], _ logr.Logger, _ ctrl.Request) ctrlfwk.StepResult {
+			ctx.RequeueAfterWithReason(time.Minute, ctrlfwk.RequeueReasonWaitingOnExternal)
+			return ctrlfwk.ResultRequeueIn(time.Hour).WithRequeueReason(ctrlfwk.RequeueReasonResourceNotReady)
+		})).
+		Build()
+
+	if _, err := stepper.Execute(ctx, ctrl.Request{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	families, err := metrics.Registry.Gather()
+	if err != nil {
+		t.Fatalf("failed to gather metrics: %v", err)
+	}
+	for _, family := range families {
+		if family.GetName() != "ctrlfwk_reconcile_requeue_total" {
+			continue
+		}
+		for _, metric := range family.GetMetric() {
+			found := map[string]string{}
+			for _, label := range metric.GetLabel() {
+				found[label.GetName()] = label.GetValue()
+			}
+			// The shortest delay wins, along with its reason
+			if found["kind"] == "ConfigMap" && found["step"] == "wait on external" {
+				if found["reason"] != string(ctrlfwk.RequeueReasonWaitingOnExternal) {
+					t.Fatalf("expected reason %s, got %s", ctrlfwk.RequeueReasonWaitingOnExternal, found["reason"])
+				}
+				return
+			}
+		}
+	}
+	t.Fatal("expected the requeue to be recorded")
+}
+
+func TestIsTerminal(t *testing.T) {
+	base := errors.New("invalid reference")
+
+	cases := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"plain", base, false},
+		{"terminal", ctrlfwk.Terminal(base), true},
+		{"wrapped terminal", fmt.Errorf("hook failed: %w", ctrlfwk.Terminal(base)), true},
+		{"retryable terminal", ctrlfwk.Retryable(ctrlfwk.Terminal(base)), false},
+		{"terminal retryable", ctrlfwk.Terminal(ctrlfwk.Retryable(base)), true},
+		{"joined", errors.Join(base, ctrlfwk.Terminal(base)), true},
+		{"controller-runtime", reconcile.TerminalError(base), true},
+	}
+	for _, tc := range cases {
+		if got := ctrlfwk.IsTerminal(tc.err); got != tc.want {
+			t.Errorf("%s: expected IsTerminal to be %v, got %v", tc.name, tc.want, got)
+		}
+	}
+}
+
+func TestStepper_TerminalErrors(t *testing.T) {
+	_, reconciler := newConditionsTest(t)
+	key := types.NamespacedName{Name: "cr", Namespace: "default"}
+
+	calls := 0
+	run := func() (*conditionsCR, error) {
+		t.Helper()
+
+		ctx := ctrlfwk.NewContext(context.Background(), reconciler)
+		ctx.SetCustomResource(&conditionsCR{})
+
+		_, err := ctrlfwk.NewStepperFor(ctx, logr.Discard()).
+			WithTerminalErrorCondition(reconciler).
+			WithStep(ctrlfwk.NewFindControllerCustomResourceStep(ctx, reconciler)).
+			WithStep(ctrlfwk.NewStep("step", func(conditionsContext, logr.Logger, ctrl.Request) ctrlfwk.StepResult {
+				calls++
+				return ctrlfwk.ResultInError(ctrlfwk.Terminal(errors.New("invalid reference")))
+			})).
+			Build().
+			Execute(ctx, ctrl.Request{NamespacedName: key})
+
+		cr := &conditionsCR{}
+		if getErr := reconciler.Get(context.Background(), key, cr); getErr != nil {
+			t.Fatalf("failed to get custom resource: %v", getErr)
+		}
+		return cr, err
+	}
+
+	cr, err := run()
+	if !errors.Is(err, reconcile.TerminalError(nil)) {
+		t.Fatalf("expected a terminal error for controller-runtime, got %v", err)
+	}
+	if condition := meta.FindStatusCondition(cr.Status.Conditions, ctrlfwk.ConditionTypeTerminalError); condition == nil || condition.Status != metav1.ConditionTrue {
+		t.Fatalf("expected a TerminalError condition, got %+v", cr.Status.Conditions)
+	}
+
+	// The same generation is not reconciled again
+	if _, err := run(); err != nil || calls != 1 {
+		t.Fatalf("expected the reconciliation to be skipped, got %d calls and %v", calls, err)
+	}
+
+	// A new generation is reconciled again
+	cr.Generation++
+	if err := reconciler.Update(context.Background(), cr); err != nil {
+		t.Fatalf("failed to update custom resource: %v", err)
+	}
+	if _, err := run(); err == nil || calls != 2 {
+		t.Fatalf("expected the new generation to be reconciled, got %d calls and %v", calls, err)
+	}
+}
+
+func TestStepper_ThrottlingRequeuesAfterRetryAfter(t *testing.T) {
+	ctx, reconciler := newConditionsTest(t)
+	reconciler.Client = faultinject.NewClient(reconciler.Client, faultinject.Rule{
+		Verbs: []string{faultinject.VerbGet},
+		Err:   faultinject.TooManyRequests(7 * time.Second),
+	})
+
+	stepper := ctrlfwk.NewStepperFor(ctx, logr.Discard()).
+		WithStep(ctrlfwk.NewStep("get", func(ctx conditionsContext, _ logr.Logger, _ ctrl.Request) ctrlfwk.StepResult {
+			if err := reconciler.Get(ctx, types.NamespacedName{Name: "cr", Namespace: "default"}, &conditionsCR{}); err != nil {
+				return ctrlfwk.ResultInError(fmt.Errorf("failed to get custom resource: %w", err))
+			}
+			return ctrlfwk.ResultSuccess()
+		})).
+		Build()
+
+	result, err := stepper.Execute(ctx, ctrl.Request{})
+	if err != nil {
+		t.Fatalf("expected the throttled reconciliation to be requeued, got %v", err)
+	}
+	if result.RequeueAfter != 7*time.Second {
+		t.Fatalf("expected a requeue after the Retry-After delay, got %s", result.RequeueAfter)
+	}
+}
@@ -3,7 +3,13 @@ package ctrlfwk
 import (
 	"fmt"
 	"reflect"
+	"time"
 
+	appsv1 "k8s.io/api/apps/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
@@ -15,18 +21,28 @@ type GenericResource[CustomResource client.Object, ContextType Context[CustomRes
 	ObjectMetaGenerator() (obj client.Object, delete bool, err error)
 	ShouldDeleteNow() bool
 	GetMutator(obj client.Object) func() error
-	Set(obj client.Object)
+	Set(obj client.Object) error
 	Get() client.Object
 	Kind() string
+	GroupVersionKind(scheme *runtime.Scheme) (schema.GroupVersionKind, error)
 	IsReady(obj client.Object) bool
+	ReadinessReason(obj client.Object) (ready bool, reason, message string)
 	RequiresManualDeletion(obj client.Object) bool
 	CanBePaused() bool
+	AfterReconcileErrorPolicy() HookErrorPolicy
+	DeleteOptions() []client.DeleteOption
+	OwnerReferenceBlocked() bool
+	ServerSideApplyFieldManager() string
+	DependsOn() []string
+	UpdateStatusField(cr CustomResource, obj client.Object) bool
 
 	// Hooks
+	ValidatePreMutate(cr CustomResource, existing client.Object) error
 	BeforeReconcile(ctx ContextType) error
 	AfterReconcile(ctx ContextType, resource client.Object) error
 	OnCreate(ctx ContextType, resource client.Object) error
 	OnUpdate(ctx ContextType, resource client.Object) error
+	OnBeforeDelete(ctx ContextType, resource client.Object) error
 	OnDelete(ctx ContextType, resource client.Object) error
 	OnFinalize(ctx ContextType, resource client.Object) error
 }
@@ -37,26 +53,75 @@ type Resource[CustomResource client.Object, ContextType Context[CustomResource],
 	userIdentifier string
 	keyF           func() types.NamespacedName
 	mutateF        Mutator[ResourceType]
+	createMutateF  Mutator[ResourceType]
+	updateMutateF  Mutator[ResourceType]
+	// mutateWithExistingF replaces mutateF, see WithMutatorWithExisting
+	mutateWithExistingF func(desired, existing ResourceType) error
+	// buildErr is the validation error of the configuration of the resource, returned by the steps reconciling it
+	buildErr error
 
 	isReadyF          func(obj ResourceType) bool
+	readinessReasonF  func(obj ResourceType) (bool, string, string)
 	shouldDeleteF     func() bool
+	shouldDeleteCtxF  func(ctx ContextType) (bool, error)
 	requiresDeletionF func(obj ResourceType) bool
 	output            ResourceType
+	outputF           func() ResourceType
+	hasOutputF        bool
 	canBePausedF      func() bool
 
+	afterReconcileErrorPolicy HookErrorPolicy
+	deletePropagationPolicy   *metav1.DeletionPropagation
+	deleteGracePeriodSeconds  *int64
+	ownerReferenceBlocked     bool
+	noOwnerReference          bool
+	dependsOn                 []string
+	statusFieldF              func(cr CustomResource) *ResourceStatus
+	propagation               *dataPropagation
+	managedPDBF               func(deploy *appsv1.Deployment) *policyv1.PodDisruptionBudgetSpec
+	immutable                 *immutableFields
+	defaultMutators           []Mutator[client.Object]
+	lifecycleEventsEnabled    bool
+	userIdentifierPrefix      string
+	sharedOwnership           bool
+	sensitive                 bool
+	clientF                   func(ctx ContextType) (client.Client, error)
+	ownedConditions           []string
+	rollingUpdateGuardF       func(obj ResourceType) bool
+	notReadyBackoffConfig     *NotReadyBackoff
+	generationGate            bool
+	suspendF                  func(obj ResourceType) error
+	mutationPolicy            ExternalMutationPolicy
+	patchStrategyConfig       PatchStrategy
+	backgroundInterval        time.Duration
+	equalityF                 func(current, desired ResourceType) bool
+	admissionWebhook          string
+	scaleF                    func(ctx ContextType) int32
+	statusMutateF             Mutator[ResourceType]
+
 	// Hooks
-	beforeReconcileF func(ctx ContextType) error
-	afterReconcileF  func(ctx ContextType, resource ResourceType) error
-	onCreateF        func(ctx ContextType, resource ResourceType) error
-	onUpdateF        func(ctx ContextType, resource ResourceType) error
-	onDeleteF        func(ctx ContextType, resource ResourceType) error
-	onFinalizeF      func(ctx ContextType, resource ResourceType) error
+	preMutateValidatorF func(cr CustomResource, existing ResourceType) error
+	beforeReconcileF    func(ctx ContextType) error
+	afterReconcileF     func(ctx ContextType, resource ResourceType) error
+	onCreateF           func(ctx ContextType, resource ResourceType) error
+	onUpdateF           func(ctx ContextType, resource ResourceType) error
+	onScaleF            func(ctx ContextType, resource ResourceType) error
+	onStatusUpdateF     func(ctx ContextType, resource ResourceType) error
+	onBeforeDeleteF     func(ctx ContextType, resource ResourceType) error
+	onDeleteF           func(ctx ContextType, resource ResourceType) error
+	onFinalizeF         func(ctx ContextType, resource ResourceType) error
 }
 
 func (c *Resource[CustomResource, ContextType, ResourceType]) Kind() string {
 	return reflect.TypeOf(c.output).Elem().Name()
 }
 
+// GroupVersionKind returns the fully qualified kind of the resource, typed resources being resolved through the scheme.
+func (c *Resource[CustomResource, ContextType, ResourceType]) GroupVersionKind(scheme *runtime.Scheme) (schema.GroupVersionKind, error) {
+	obj := reflect.New(reflect.TypeOf(c.output).Elem()).Interface().(ResourceType)
+	return getObjectGVK(obj, scheme)
+}
+
 func (c *Resource[CustomResource, ContextType, ResourceType]) ObjectMetaGenerator() (obj client.Object, skip bool, err error) {
 	if reflect.ValueOf(c.output).IsNil() {
 		c.output = reflect.New(reflect.TypeOf(c.output).Elem()).Interface().(ResourceType)
@@ -67,12 +132,12 @@ func (c *Resource[CustomResource, ContextType, ResourceType]) ObjectMetaGenerato
 	c.output.SetName(key.Name)
 	c.output.SetNamespace(key.Namespace)
 
-	return c.output, c.shouldDeleteF != nil && c.shouldDeleteF(), nil
+	return c.output, c.ShouldDeleteNow(), nil
 }
 
 func (c *Resource[CustomResource, ContextType, ResourceType]) ID() string {
 	if c.userIdentifier != "" {
-		return c.userIdentifier
+		return c.userIdentifierPrefix + c.userIdentifier
 	}
 
 	key := c.keyF()
@@ -80,7 +145,7 @@ func (c *Resource[CustomResource, ContextType, ResourceType]) ID() string {
 	return fmt.Sprintf("%v,%v", c.Kind(), key)
 }
 
-func (c *Resource[CustomResource, ContextType, ResourceType]) Set(obj client.Object) {
+func (c *Resource[CustomResource, ContextType, ResourceType]) Set(obj client.Object) error {
 	if reflect.TypeOf(c.output) == reflect.TypeOf(obj) {
 		if reflect.ValueOf(c.output).IsNil() {
 			c.output = reflect.New(reflect.TypeOf(c.output).Elem()).Interface().(ResourceType)
@@ -88,6 +153,14 @@ func (c *Resource[CustomResource, ContextType, ResourceType]) Set(obj client.Obj
 
 		reflect.ValueOf(c.output).Elem().Set(reflect.ValueOf(obj).Elem())
 	}
+
+	if c.hasOutputF {
+		if err := setOutputFromFunc(c.outputF, obj); err != nil {
+			return fmt.Errorf("failed to set output of %s: %w", c.ID(), err)
+		}
+	}
+
+	return nil
 }
 
 func (c *Resource[CustomResource, ContextType, ResourceType]) Get() client.Object {
@@ -95,6 +168,10 @@ func (c *Resource[CustomResource, ContextType, ResourceType]) Get() client.Objec
 }
 
 func (c *Resource[CustomResource, ContextType, ResourceType]) IsReady(obj client.Object) bool {
+	if c.readinessReasonF != nil {
+		ready, _, _ := c.ReadinessReason(obj)
+		return ready
+	}
 	if c.isReadyF != nil {
 		if typedObj, ok := obj.(ResourceType); ok {
 			return c.isReadyF(typedObj)
@@ -107,6 +184,22 @@ func (c *Resource[CustomResource, ContextType, ResourceType]) IsReady(obj client
 	return false
 }
 
+// ReadinessReason tells whether obj is ready, along with the reason and message explaining why it is not.
+// Without a readiness reason function, the reason and message are empty.
+func (c *Resource[CustomResource, ContextType, ResourceType]) ReadinessReason(obj client.Object) (bool, string, string) {
+	if c.readinessReasonF != nil {
+		if typedObj, ok := obj.(ResourceType); ok {
+			return c.readinessReasonF(typedObj)
+		}
+		if obj == nil {
+			var zero ResourceType
+			return c.readinessReasonF(zero)
+		}
+		return false, "", ""
+	}
+	return c.IsReady(obj), "", ""
+}
+
 func (c *Resource[CustomResource, ContextType, ResourceType]) RequiresManualDeletion(obj client.Object) bool {
 	if c.requiresDeletionF != nil {
 		if typedObj, ok := obj.(ResourceType); ok {
@@ -121,12 +214,28 @@ func (c *Resource[CustomResource, ContextType, ResourceType]) RequiresManualDele
 }
 
 func (c *Resource[CustomResource, ContextType, ResourceType]) ShouldDeleteNow() bool {
+	if c.propagation != nil && c.propagation.shouldDeleteTarget() {
+		return true
+	}
 	if c.shouldDeleteF != nil {
 		return c.shouldDeleteF()
 	}
 	return false
 }
 
+func (c *Resource[CustomResource, ContextType, ResourceType]) ValidatePreMutate(cr CustomResource, existing client.Object) error {
+	if c.preMutateValidatorF != nil {
+		if typedObj, ok := existing.(ResourceType); ok {
+			return c.preMutateValidatorF(cr, typedObj)
+		}
+		if existing == nil {
+			var zero ResourceType
+			return c.preMutateValidatorF(cr, zero)
+		}
+	}
+	return nil
+}
+
 func (c *Resource[CustomResource, ContextType, ResourceType]) BeforeReconcile(ctx ContextType) error {
 	if c.beforeReconcileF != nil {
 		return c.beforeReconcileF(ctx)
@@ -173,6 +282,37 @@ func (c *Resource[CustomResource, ContextType, ResourceType]) OnUpdate(ctx Conte
 	return nil
 }
 
+func (c *Resource[CustomResource, ContextType, ResourceType]) OnScale(ctx ContextType, resource client.Object) error {
+	if c.onScaleF != nil {
+		if typedObj, ok := resource.(ResourceType); ok {
+			return c.onScaleF(ctx, typedObj)
+		}
+	}
+	return nil
+}
+
+func (c *Resource[CustomResource, ContextType, ResourceType]) OnStatusUpdate(ctx ContextType, resource client.Object) error {
+	if c.onStatusUpdateF != nil {
+		if typedObj, ok := resource.(ResourceType); ok {
+			return c.onStatusUpdateF(ctx, typedObj)
+		}
+	}
+	return nil
+}
+
+func (c *Resource[CustomResource, ContextType, ResourceType]) OnBeforeDelete(ctx ContextType, resource client.Object) error {
+	if c.onBeforeDeleteF != nil {
+		if typedObj, ok := resource.(ResourceType); ok {
+			return c.onBeforeDeleteF(ctx, typedObj)
+		}
+		if resource == nil {
+			var zero ResourceType
+			return c.onBeforeDeleteF(ctx, zero)
+		}
+	}
+	return nil
+}
+
 func (c *Resource[CustomResource, ContextType, ResourceType]) OnDelete(ctx ContextType, resource client.Object) error {
 	if c.onDeleteF != nil {
 		if typedObj, ok := resource.(ResourceType); ok {
@@ -199,15 +339,55 @@ func (c *Resource[CustomResource, ContextType, ResourceType]) OnFinalize(ctx Con
 	return nil
 }
 
+// GetMutator returns the mutation of obj, which is considered to exist when it has a resource version.
 func (c *Resource[CustomResource, ContextType, ResourceType]) GetMutator(obj client.Object) func() error {
+	var live client.Object
+	if obj != nil && obj.GetResourceVersion() != "" {
+		live = obj
+	}
+	return c.lifecycleMutator(obj, live)
+}
+
+// lifecycleMutator returns the mutation of obj, using the create or update mutator depending on whether it exists,
+// live being the object as it exists before the mutation, nil if it does not exist yet.
+func (c *Resource[CustomResource, ContextType, ResourceType]) lifecycleMutator(obj client.Object, live client.Object) func() error {
+	exists := live != nil
+
+	mutateF := c.mutateF
+	if mutateF == nil && c.mutateWithExistingF != nil {
+		var existing ResourceType
+		if typedLive, ok := live.(ResourceType); ok && exists {
+			// The mutator gets a copy, so that it can't modify the live object by mistake
+			existing = typedLive.DeepCopyObject().(ResourceType)
+		}
+		mutateF = func(desired ResourceType) error {
+			return c.mutateWithExistingF(desired, existing)
+		}
+	}
+	if exists && c.updateMutateF != nil {
+		mutateF = c.updateMutateF
+	}
+	if !exists && c.createMutateF != nil {
+		mutateF = c.createMutateF
+	}
+
 	return func() error {
-		if c.mutateF != nil {
+		// The default mutators run first, so that the mutator of the resource can override what they set
+		for _, mutate := range c.defaultMutators {
+			if obj == nil {
+				break
+			}
+			if err := mutate(obj); err != nil {
+				return err
+			}
+		}
+		if mutateF != nil {
 			if typedObj, ok := obj.(ResourceType); ok {
-				return c.mutateF(typedObj)
+				return mutateF(typedObj)
 			}
 			if obj == nil {
 				var zero ResourceType
-				return c.mutateF(zero)
+				return mutateF(zero)
 			}
 		}
 		return nil
@@ -220,3 +400,193 @@ func (c *Resource[CustomResource, ContextType, ResourceType]) CanBePaused() bool
 	}
 	return false
 }
+
+func (c *Resource[CustomResource, ContextType, ResourceType]) AfterReconcileErrorPolicy() HookErrorPolicy {
+	if c.afterReconcileErrorPolicy == "" {
+		return HookErrorPolicyAbort
+	}
+	return c.afterReconcileErrorPolicy
+}
+
+func (c *Resource[CustomResource, ContextType, ResourceType]) DeleteOptions() []client.DeleteOption {
+	var opts []client.DeleteOption
+	if c.deletePropagationPolicy != nil {
+		opts = append(opts, client.PropagationPolicy(*c.deletePropagationPolicy))
+	}
+	if c.deleteGracePeriodSeconds != nil {
+		opts = append(opts, client.GracePeriodSeconds(*c.deleteGracePeriodSeconds))
+	}
+	return opts
+}
+
+func (c *Resource[CustomResource, ContextType, ResourceType]) OwnerReferenceBlocked() bool {
+	return c.ownerReferenceBlocked
+}
+
+func (c *Resource[CustomResource, ContextType, ResourceType]) DependsOn() []string {
+	return c.dependsOn
+}
+
+func (c *Resource[CustomResource, ContextType, ResourceType]) ServerSideApplyFieldManager() string {
+	return ""
+}
+
+// UpdateStatusField populates the status sub-object of the resource on cr from obj, a nil obj clearing it.
+// LastUpdated is only bumped when another field changes. It returns true if the status was changed.
+func (c *Resource[CustomResource, ContextType, ResourceType]) UpdateStatusField(cr CustomResource, obj client.Object) bool {
+	if c.statusFieldF == nil {
+		return false
+	}
+	status := c.statusFieldF(cr)
+	if status == nil {
+		return false
+	}
+
+	var desired ResourceStatus
+	if obj != nil {
+		desired = ResourceStatus{
+			Name:        obj.GetName(),
+			Namespace:   obj.GetNamespace(),
+			Generation:  obj.GetGeneration(),
+			Ready:       c.IsReady(obj),
+			LastUpdated: status.LastUpdated,
+		}
+	}
+	if *status == desired {
+		return false
+	}
+
+	if obj != nil {
+		desired.LastUpdated = metav1.Now()
+	}
+	*status = desired
+	return true
+}
+
+func (c *Resource[CustomResource, ContextType, ResourceType]) dataPropagation() *dataPropagation {
+	return c.propagation
+}
+
+func (c *Resource[CustomResource, ContextType, ResourceType]) immutableFields() *immutableFields {
+	return c.immutable
+}
+
+func (c *Resource[CustomResource, ContextType, ResourceType]) lifecycleEvents() bool {
+	return c.lifecycleEventsEnabled
+}
+
+func (c *Resource[CustomResource, ContextType, ResourceType]) isShared() bool {
+	return c.sharedOwnership
+}
+
+func (c *Resource[CustomResource, ContextType, ResourceType]) withoutOwnerReference() bool {
+	return c.noOwnerReference
+}
+
+func (c *Resource[CustomResource, ContextType, ResourceType]) isSensitive() bool {
+	return c.sensitive
+}
+
+func (c *Resource[CustomResource, ContextType, ResourceType]) remoteClient(ctx ContextType) (client.Client, bool, error) {
+	if c.clientF == nil {
+		return nil, false, nil
+	}
+	remote, err := c.clientF(ctx)
+	return remote, true, err
+}
+
+func (c *Resource[CustomResource, ContextType, ResourceType]) ownedConditionTypes() []string {
+	return c.ownedConditions
+}
+
+func (c *Resource[CustomResource, ContextType, ResourceType]) rollingUpdateGuard() func(live client.Object) bool {
+	if c.rollingUpdateGuardF == nil {
+		return nil
+	}
+	return func(live client.Object) bool {
+		typedObj, ok := live.(ResourceType)
+		return ok && c.rollingUpdateGuardF(typedObj)
+	}
+}
+
+func (c *Resource[CustomResource, ContextType, ResourceType]) equalityFunc() func(current, desired client.Object) bool {
+	if c.equalityF == nil {
+		return nil
+	}
+	return func(current, desired client.Object) bool {
+		typedCurrent, ok := current.(ResourceType)
+		if !ok {
+			return false
+		}
+		typedDesired, ok := desired.(ResourceType)
+		return ok && c.equalityF(typedCurrent, typedDesired)
+	}
+}
+
+func (c *Resource[CustomResource, ContextType, ResourceType]) admissionWebhookURL() string {
+	return c.admissionWebhook
+}
+
+func (c *Resource[CustomResource, ContextType, ResourceType]) buildError() error {
+	return c.buildErr
+}
+
+func (c *Resource[CustomResource, ContextType, ResourceType]) notReadyBackoff() *NotReadyBackoff {
+	return c.notReadyBackoffConfig
+}
+
+func (c *Resource[CustomResource, ContextType, ResourceType]) generationGated() bool {
+	return c.generationGate
+}
+
+func (c *Resource[CustomResource, ContextType, ResourceType]) externalMutationPolicy() ExternalMutationPolicy {
+	return c.mutationPolicy
+}
+
+func (c *Resource[CustomResource, ContextType, ResourceType]) patchStrategy() PatchStrategy {
+	return c.patchStrategyConfig
+}
+
+func (c *Resource[CustomResource, ContextType, ResourceType]) backgroundReconcileInterval() time.Duration {
+	return c.backgroundInterval
+}
+
+func (c *Resource[CustomResource, ContextType, ResourceType]) suspendBehavior() func(obj client.Object) error {
+	if c.suspendF == nil {
+		return nil
+	}
+	return func(obj client.Object) error {
+		typedObj, ok := obj.(ResourceType)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for resource %s", obj, c.ID())
+		}
+		return c.suspendF(typedObj)
+	}
+}
+
+func (c *Resource[CustomResource, ContextType, ResourceType]) desiredReplicas(ctx ContextType) (int32, bool) {
+	if c.scaleF == nil {
+		return 0, false
+	}
+	return c.scaleF(ctx), true
+}
+
+func (c *Resource[CustomResource, ContextType, ResourceType]) statusMutator() func(obj client.Object) error {
+	if c.statusMutateF == nil {
+		return nil
+	}
+	return func(obj client.Object) error {
+		typedObj, ok := obj.(ResourceType)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for resource %s", obj, c.ID())
+		}
+		return c.statusMutateF(typedObj)
+	}
+}
+
+func (c *Resource[CustomResource, ContextType, ResourceType]) shouldDeleteFor(ctx ContextType) (bool, error) {
+	if c.shouldDeleteCtxF == nil {
+		return false, nil
+	}
+	return c.shouldDeleteCtxF(ctx)
+}
@@ -1,6 +1,13 @@
 package ctrlfwk
 
 import (
+	"time"
+
+	"github.com/pkg/errors"
+
+	appsv1 "k8s.io/api/apps/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
@@ -36,13 +43,14 @@ import (
 //		WithMutator(func(deployment *appsv1.Deployment) error {
 //			// Configure deployment spec based on custom resource
 //			deployment.Spec.Replicas = ctx.GetCustomResource().Spec.Replicas
-//			return controllerutil.SetOwnerReference(ctx.GetCustomResource(), deployment, scheme)
+//			return nil
 //		}).
 //		WithReadinessCondition(func(deployment *appsv1.Deployment) bool {
 //			return deployment.Status.ReadyReplicas == *deployment.Spec.Replicas
 //		}).
 //		Build()
 type ResourceBuilder[CustomResource client.Object, ContextType Context[CustomResource], ResourceType client.Object] struct {
+	ctx      ContextType
 	resource *Resource[CustomResource, ContextType, ResourceType]
 }
 
@@ -88,11 +96,12 @@ type ResourceBuilder[CustomResource client.Object, ContextType Context[CustomRes
 //				TargetPort: intstr.FromInt(8080),
 //				Protocol:   corev1.ProtocolTCP,
 //			}}
-//			return controllerutil.SetOwnerReference(ctx.GetCustomResource(), svc, scheme)
+//			return nil
 //		}).
 //		Build()
 func NewResourceBuilder[CustomResource client.Object, ContextType Context[CustomResource], ResourceType client.Object](ctx ContextType, _ ResourceType) *ResourceBuilder[CustomResource, ContextType, ResourceType] {
 	return &ResourceBuilder[CustomResource, ContextType, ResourceType]{
+		ctx:      ctx,
 		resource: &Resource[CustomResource, ContextType, ResourceType]{},
 	}
 }
@@ -130,7 +139,7 @@ func (b *ResourceBuilder[CustomResource, ContextType, ResourceType]) WithKey(nam
 //   - Prefixing with custom resource name: ctx.GetCustomResource().Name + "-suffix"
 //   - Using custom resource namespace: ctx.GetCustomResource().Namespace
 //   - Conditional naming based on custom resource spec
-//   - When the name is stored in the spec, you might wanna refer to the status when the spec field is updated or disappears
+//   - When the name is stored in the spec, you might wanna refer to the status when the spec field is updated or disappears, see WithKeyFromStatusFallback
 //
 // Example:
 //
@@ -146,6 +155,49 @@ func (b *ResourceBuilder[CustomResource, ContextType, ResourceType]) WithKeyFunc
 	return b
 }
 
+// WithKeyFromStatusFallback computes the key of the resource with specFn, or with statusFn while useStatus
+// returns true. This is the pattern of resources whose name is set in the spec and recorded in the status once
+// created: when the resource is renamed or disabled, the key recorded in the status still finds the existing
+// resource so that it can be deleted. statusFn returning a key without name, e.g. as the resource was never created,
+// falls back to specFn.
+//
+// Example:
+//
+//	.WithSkipAndDeleteOnCondition(func() bool { return !cr.Spec.ConfigMap.Enabled }).
+//	WithKeyFromStatusFallback(
+//		func() types.NamespacedName { return types.NamespacedName{Name: cr.Spec.ConfigMap.Name, Namespace: cr.Namespace} },
+//		func() types.NamespacedName { return types.NamespacedName{Name: cr.Status.ConfigMapName, Namespace: cr.Namespace} },
+//		func() bool { return !cr.Spec.ConfigMap.Enabled }, // Find the ConfigMap to delete
+//	)
+func (b *ResourceBuilder[CustomResource, ContextType, ResourceType]) WithKeyFromStatusFallback(specFn, statusFn func() types.NamespacedName, useStatus func() bool) *ResourceBuilder[CustomResource, ContextType, ResourceType] {
+	return b.WithKeyFunc(func() types.NamespacedName {
+		if useStatus() {
+			if key := statusFn(); key.Name != "" {
+				return key
+			}
+		}
+		return specFn()
+	})
+}
+
+// WithGeneratedKey names the resource after the custom resource and suffix using Name, in the namespace of the
+// custom resource, e.g. "my-app-config" for the suffix "config". The name is always valid, long names being
+// truncated with a hash so that they stay unique.
+//
+// Example:
+//
+//	.WithGeneratedKey("config") // ConfigMap named after the custom resource
+func (b *ResourceBuilder[CustomResource, ContextType, ResourceType]) WithGeneratedKey(suffix string) *ResourceBuilder[CustomResource, ContextType, ResourceType] {
+	b.resource.keyF = func() types.NamespacedName {
+		cr := b.ctx.GetCustomResource()
+		return types.NamespacedName{
+			Name:      Name(cr.GetName(), suffix),
+			Namespace: cr.GetNamespace(),
+		}
+	}
+	return b
+}
+
 // WithMutator specifies the function that configures the resource's desired state.
 //
 // The mutator function is called whenever the resource needs to be created or updated.
@@ -177,14 +229,92 @@ func (b *ResourceBuilder[CustomResource, ContextType, ResourceType]) WithKeyFunc
 //			Image: cr.Spec.Image,
 //		}}
 //
-//		// Set owner reference for garbage collection
-//		return controllerutil.SetOwnerReference(cr, deployment, ctx.GetScheme())
+//		// The custom resource is set as the controller of the deployment by the framework, see WithoutOwnerReference
+//		return nil
 //	})
 func (b *ResourceBuilder[CustomResource, ContextType, ResourceType]) WithMutator(f Mutator[ResourceType]) *ResourceBuilder[CustomResource, ContextType, ResourceType] {
 	b.resource.mutateF = f
 	return b
 }
 
+// WithCreateMutator specifies the mutator used instead of the one of WithMutator when the resource does not exist yet,
+// typically to set defaults once and leave them to the users afterwards.
+//
+// Example:
+//
+//	.WithCreateMutator(func(cm *corev1.ConfigMap) error {
+//		cm.Data = map[string]string{"log-level": "info"}
+//		return nil
+//	})
+func (b *ResourceBuilder[CustomResource, ContextType, ResourceType]) WithCreateMutator(f Mutator[ResourceType]) *ResourceBuilder[CustomResource, ContextType, ResourceType] {
+	b.resource.createMutateF = f
+	return b
+}
+
+// WithMutatorWithExisting is an alternative to WithMutator for mutators that need the state of the resource
+// as it exists in the cluster, to copy forward the fields populated by the server or by users, such as
+// the clusterIP of a Service or the replicas of a Deployment scaled by an HPA.
+//
+// existing is the zero value when the resource does not exist yet, and a copy of the live object otherwise,
+// so modifying it has no effect. WithMutator and WithMutatorWithExisting are mutually exclusive,
+// the steps reconciling a resource built with both fail.
+//
+// Example:
+//
+//	.WithMutatorWithExisting(func(desired, existing *appsv1.Deployment) error {
+//		desired.Spec.Template = podTemplate(cr)
+//		if existing != nil {
+//			// The replicas are owned by the HPA
+//			desired.Spec.Replicas = existing.Spec.Replicas
+//		}
+//		return nil
+//	})
+func (b *ResourceBuilder[CustomResource, ContextType, ResourceType]) WithMutatorWithExisting(f func(desired, existing ResourceType) error) *ResourceBuilder[CustomResource, ContextType, ResourceType] {
+	b.resource.mutateWithExistingF = f
+	return b
+}
+
+// WithUpdateMutator specifies the mutator used instead of the one of WithMutator when the resource already exists,
+// typically to only enforce some fields and preserve the edits of the users on the others.
+func (b *ResourceBuilder[CustomResource, ContextType, ResourceType]) WithUpdateMutator(f Mutator[ResourceType]) *ResourceBuilder[CustomResource, ContextType, ResourceType] {
+	b.resource.updateMutateF = f
+	return b
+}
+
+// WithScale reconciles the replicas of the resource through its scale subresource, once the resource is created
+// or patched. The scale is only updated when its replicas differ from the ones returned by f, and the hook registered
+// with WithAfterScale runs when it was. The resource must support the scale subresource, like Deployments,
+// StatefulSets or the custom resources declaring it.
+//
+// The mutator should leave the replicas unset so that they are only written through the scale subresource,
+// as an HPA would. Resources suspended with WithSuspendBehavior are not scaled while suspended.
+//
+// Example:
+//
+//	.WithScale(func(ctx MyContext) int32 {
+//		return ctx.GetCustomResource().Spec.Replicas
+//	})
+func (b *ResourceBuilder[CustomResource, ContextType, ResourceType]) WithScale(f func(ctx ContextType) int32) *ResourceBuilder[CustomResource, ContextType, ResourceType] {
+	b.resource.scaleF = f
+	return b
+}
+
+// WithStatusMutator specifies a mutator for the status of the resource, for the controllers owning the status
+// of the resources they create. Once the resource is created or patched, f is run on the resource as it exists
+// in the cluster and its status subresource is patched, only when f changed the status. The hook registered
+// with WithAfterStatusUpdate runs when it was patched.
+//
+// Example:
+//
+//	.WithStatusMutator(func(db *dbv1.Database) error {
+//		db.Status.Endpoint = fmt.Sprintf("%s.%s.svc", db.Name, db.Namespace)
+//		return nil
+//	})
+func (b *ResourceBuilder[CustomResource, ContextType, ResourceType]) WithStatusMutator(f Mutator[ResourceType]) *ResourceBuilder[CustomResource, ContextType, ResourceType] {
+	b.resource.statusMutateF = f
+	return b
+}
+
 // WithOutput specifies where to store the reconciled resource after successful operations.
 //
 // The provided object will be populated with the resource's current state from the
@@ -216,6 +346,27 @@ func (b *ResourceBuilder[CustomResource, ContextType, ResourceType]) WithOutput(
 	return b
 }
 
+// WithOutputFunc specifies where to store the reconciled resource, the function is evaluated
+// at reconcile time instead of when the builder runs.
+//
+// This is useful when the output is a field of your context's data structure that is only
+// allocated later on, for example in a BeforeReconcile hook. The function must return an
+// allocated object, the reconciliation fails with an error otherwise.
+//
+// Example:
+//
+//	service := NewResourceBuilder(ctx, &corev1.Service{}).
+//		// ... other configuration ...
+//		WithOutputFunc(func() *corev1.Service {
+//			return ctx.Data.AppService // Allocated in a BeforeReconcile hook
+//		}).
+//		Build()
+func (b *ResourceBuilder[CustomResource, ContextType, ResourceType]) WithOutputFunc(f func() ResourceType) *ResourceBuilder[CustomResource, ContextType, ResourceType] {
+	b.resource.outputF = f
+	b.resource.hasOutputF = true
+	return b
+}
+
 // WithReadinessCondition defines custom logic to determine when the resource is ready.
 //
 // The provided function is called with the current resource state and should return
@@ -246,6 +397,197 @@ func (b *ResourceBuilder[CustomResource, ContextType, ResourceType]) WithReadine
 	return b
 }
 
+// WithReadinessReason defines how to determine if the resource is ready, like WithReadinessCondition,
+// while explaining why it is not.
+//
+// When the resource is not ready, the custom resource gets a Ready=False condition listing the reason and message
+// of each resource that is not ready, one line per resource, and an event is emitted if the reconciler is
+// a record.EventRecorder. The condition has the given reason when the resource is the only one that is not ready,
+// ResourcesNotReady otherwise. A resource is removed from the condition once ready, and the Ready condition is set
+// back to true by the end step once every resource is ready.
+//
+// It takes precedence over WithReadinessCondition.
+//
+// Example:
+//
+//	.WithReadinessReason(func(deployment *appsv1.Deployment) (bool, string, string) {
+//		replicas := ptr.Deref(deployment.Spec.Replicas, 1)
+//		if deployment.Status.ReadyReplicas < replicas {
+//			return false, "ReplicasNotReady", fmt.Sprintf("%d/%d replicas ready", deployment.Status.ReadyReplicas, replicas)
+//		}
+//		return true, "", ""
+//	})
+func (b *ResourceBuilder[CustomResource, ContextType, ResourceType]) WithReadinessReason(f func(obj ResourceType) (ready bool, reason, message string)) *ResourceBuilder[CustomResource, ContextType, ResourceType] {
+	b.resource.readinessReasonF = f
+	return b
+}
+
+// WithEqualityFunc skips the update of the resource when f reports the mutated object as equal to the live one,
+// e.g. to compare resource quantities semantically when the API server normalizes "1000m" to "1". Without it,
+// any difference between the objects updates the resource, triggering the AfterUpdate hook and the lifecycle events.
+//
+// f receives the live object and the mutated one, including the metadata managed by the framework, and the update
+// is skipped as a whole when it returns true. It is not used for resources reconciled with server-side apply,
+// whose apply configurations only hold the applied fields.
+//
+// Example:
+//
+//	.WithEqualityFunc(func(current, desired *corev1.LimitRange) bool {
+//		return equality.Semantic.DeepEqual(current.Spec, desired.Spec)
+//	})
+func (b *ResourceBuilder[CustomResource, ContextType, ResourceType]) WithEqualityFunc(f func(current, desired ResourceType) bool) *ResourceBuilder[CustomResource, ContextType, ResourceType] {
+	b.resource.equalityF = f
+	return b
+}
+
+// WithAdmissionWebhookValidation sends the mutated resource to the admission webhook at webhookURL before creating or
+// updating it, as a dry-run admission.k8s.io/v1 AdmissionReview, for policy engines such as OPA or Gatekeeper
+// that are reachable as an HTTP service rather than installed as admission webhooks of the cluster.
+//
+// When the webhook denies the resource, it is not written: the custom resource gets a ValidationFailed condition with
+// the message of the webhook, as for a PreMutateValidator, and is not reconciled further until it changes.
+// Failing to reach the webhook fails the reconciliation, which is retried. Resources that would not change are not sent.
+//
+// The whole resource is sent to the webhook, secret values included.
+//
+// Example:
+//
+//	.WithAdmissionWebhookValidation("http://gatekeeper.policy.svc:8443/v1/admit")
+func (b *ResourceBuilder[CustomResource, ContextType, ResourceType]) WithAdmissionWebhookValidation(webhookURL string) *ResourceBuilder[CustomResource, ContextType, ResourceType] {
+	b.resource.admissionWebhook = webhookURL
+	return b
+}
+
+// WithRollingUpdateGuard defers the updates of the resource while f reports a rollout in progress on the live object,
+// as updating a Deployment that is already rolling out can cause cascading failures. The update is skipped,
+// the RollingUpdateInProgress condition is set on the custom resource and the reconciliation is requeued,
+// the deferred update being applied once f returns false.
+//
+// Unlike WithReadinessCondition, which tells whether the resource is ready, this only affects when it can be updated.
+// Reconciliations that would not change the resource are not affected.
+//
+// Example:
+//
+//	.WithRollingUpdateGuard(func(deployment *appsv1.Deployment) bool {
+//		return deployment.Status.UpdatedReplicas < ptr.Deref(deployment.Spec.Replicas, 1)
+//	})
+func (b *ResourceBuilder[CustomResource, ContextType, ResourceType]) WithRollingUpdateGuard(f func(obj ResourceType) bool) *ResourceBuilder[CustomResource, ContextType, ResourceType] {
+	b.resource.rollingUpdateGuardF = f
+	return b
+}
+
+// WithNotReadyBackoff requeues the reconciliation while the resource is not ready, with a delay doubling
+// on every requeue up to backoff.Max and jittered so that custom resources becoming not ready at the same time,
+// e.g. after the outage of a shared dependency, don't all come back at once. The delay is reset once the resource is ready.
+//
+// Without it, the reconciliation waits for the resource to change, except for resources of a remote cluster
+// which are requeued at a fixed interval. The backoff is separate from the rate limiter used on errors.
+//
+// Example:
+//
+//	.WithNotReadyBackoff(ctrlfwk.NotReadyBackoff{Initial: 2 * time.Second, Max: time.Minute})
+func (b *ResourceBuilder[CustomResource, ContextType, ResourceType]) WithNotReadyBackoff(backoff NotReadyBackoff) *ResourceBuilder[CustomResource, ContextType, ResourceType] {
+	b.resource.notReadyBackoffConfig = &backoff
+	return b
+}
+
+// WithGenerationGate skips the mutation and update of the resource when it was already reconciled for the current
+// generation of the custom resource, so that reconciliations triggered by status changes don't recompute it.
+// The generation and a hash of the resource are recorded in its ctrlfwk.com/last-reconciled-generation and
+// ctrlfwk.com/last-reconciled-hash annotations once it is reconciled.
+//
+// The gate is bypassed when the resource was modified since, e.g. edited manually, as its hash does not match anymore,
+// for custom resources without generation, and when the reconciliation is forced, see ForceReconcile.
+// The mutator must only depend on the spec of the custom resource, as a change of anything else, like a dependency,
+// does not bump the generation, ForceReconcile being the way to pick such a change up.
+//
+// Example:
+//
+//	.WithGenerationGate(true)
+func (b *ResourceBuilder[CustomResource, ContextType, ResourceType]) WithGenerationGate(enabled bool) *ResourceBuilder[CustomResource, ContextType, ResourceType] {
+	b.resource.generationGate = enabled
+	return b
+}
+
+// WithBackgroundReconcile reconciles the custom resource every interval once the resource was reconciled, for resources
+// that must be refreshed on a schedule rather than on changes only, e.g. a TLS certificate approaching its expiry.
+// The reconciliations are enqueued through a channel source of the controller of the reconciler, which must embed a WatchCache.
+//
+// The hooks can tell these reconciliations from the other ones with IsBackgroundRefresh. The schedule stops once
+// the custom resource is deleted.
+//
+// Example:
+//
+//	.WithBackgroundReconcile(time.Hour).
+//	WithBeforeReconcile(func(ctx MyContext) error {
+//		if ctrlfwk.IsBackgroundRefresh(ctx) {
+//			return renewCertificateIfExpiring(ctx)
+//		}
+//		return nil
+//	})
+func (b *ResourceBuilder[CustomResource, ContextType, ResourceType]) WithBackgroundReconcile(interval time.Duration) *ResourceBuilder[CustomResource, ContextType, ResourceType] {
+	b.resource.backgroundInterval = interval
+	return b
+}
+
+// WithSuspendBehavior defines how the resource is suspended while the custom resource is, i.e. it has the
+// LabelSuspended label or implements Suspendable, e.g. to scale a Deployment to zero for maintenance
+// while the rest of the custom resource keeps being reconciled, unlike LabelReconciliationPaused.
+//
+// While suspended, f is applied to the live resource instead of the mutator. The values it changes are saved
+// in the ctrlfwk.com/suspended-state annotation of the resource, and restored when the custom resource is resumed,
+// before the mutator runs. The resource is not expected to be ready while suspended, the Suspended condition
+// is set on the custom resource instead.
+//
+// With server-side apply, the mutator still runs as the applied configuration is built from scratch,
+// and the saved values are not restored, the mutator being expected to set them.
+//
+// Example:
+//
+//	.WithSuspendBehavior(func(deployment *appsv1.Deployment) error {
+//		deployment.Spec.Replicas = ptr.To[int32](0)
+//		return nil
+//	})
+func (b *ResourceBuilder[CustomResource, ContextType, ResourceType]) WithSuspendBehavior(f func(obj ResourceType) error) *ResourceBuilder[CustomResource, ContextType, ResourceType] {
+	b.resource.suspendF = f
+	return b
+}
+
+// WithExternalMutationPolicy detects the modifications of the resource made by other tools than the controller,
+// e.g. kubectl or a GitOps system, and tells whether they are repaired, accepted or only reported, see ExternalMutationPolicy.
+// Detected modifications are logged and reported with an event when the reconciler is a record.EventRecorder.
+//
+// With WithServerSideApply, the resource is applied without forcing the ownership of its fields, a conflict with
+// the fields of another manager telling it was modified. Otherwise the generation of the custom resource and
+// a hash of the resource are recorded in its ctrlfwk.com/last-reconciled-generation and ctrlfwk.com/last-reconciled-hash
+// annotations once it is reconciled, the resource being modified when its hash does not match anymore.
+//
+// Without it, external modifications are repaired without being reported.
+//
+// Example:
+//
+//	.WithExternalMutationPolicy(ctrlfwk.ExternalMutationPolicyAccept)
+func (b *ResourceBuilder[CustomResource, ContextType, ResourceType]) WithExternalMutationPolicy(policy ExternalMutationPolicy) *ResourceBuilder[CustomResource, ContextType, ResourceType] {
+	b.resource.mutationPolicy = policy
+	return b
+}
+
+// WithPatchStrategy sends the updates of the resource using strategy, see PatchStrategy, the resource version of the
+// fetched object being used as a precondition so that concurrent writes fail with a conflict instead of being
+// overwritten, the reconciliation being retried. Patches only hold the changes made by the mutator, which keeps
+// the writes of large objects like Deployments small.
+//
+// Without it, the changes are sent as a JSON merge patch without precondition, the status of the resource being patched
+// as well when the mutator changed it. It has no effect with WithServerSideApply.
+//
+// Example:
+//
+//	.WithPatchStrategy(ctrlfwk.PatchStrategyStrategicMerge)
+func (b *ResourceBuilder[CustomResource, ContextType, ResourceType]) WithPatchStrategy(strategy PatchStrategy) *ResourceBuilder[CustomResource, ContextType, ResourceType] {
+	b.resource.patchStrategyConfig = strategy
+	return b
+}
+
 // WithSkipAndDeleteOnCondition specifies when to skip creating or delete an existing resource.
 //
 // The provided function is evaluated during reconciliation. When it returns true:
@@ -282,6 +624,22 @@ func (b *ResourceBuilder[CustomResource, ContextType, ResourceType]) WithSkipAnd
 	return b
 }
 
+// WithSkipAndDeleteOnConditionFunc is like WithSkipAndDeleteOnCondition, f receiving the context of the
+// reconciliation instead of capturing it, so that resource factories can read the current state of the custom
+// resource and the outputs of the dependencies resolved earlier. Returning an error aborts the reconciliation.
+//
+// The resource is skipped if either condition is true.
+//
+// Example:
+//
+//	.WithSkipAndDeleteOnConditionFunc(func(ctx ctrlfwk.Context[*v1.App]) (bool, error) {
+//		return !ctx.GetCustomResource().Spec.Monitoring.Enabled, nil
+//	})
+func (b *ResourceBuilder[CustomResource, ContextType, ResourceType]) WithSkipAndDeleteOnConditionFunc(f func(ctx ContextType) (bool, error)) *ResourceBuilder[CustomResource, ContextType, ResourceType] {
+	b.resource.shouldDeleteCtxF = f
+	return b
+}
+
 // WithRequireManualDeletionForFinalize specifies when a resource requires manual cleanup
 // during custom resource finalization.
 //
@@ -319,6 +677,88 @@ func (b *ResourceBuilder[CustomResource, ContextType, ResourceType]) WithRequire
 	return b
 }
 
+// WithDeletePropagationPolicy sets the propagation policy used whenever the framework deletes the resource,
+// that is when the skip condition is met, when the custom resource is being finalized, when the resource is
+// recreated (see WithRecreateOnImmutableFieldConflict) or rolled back (see NewAtomicResourceGroupStep), and when the object
+// written with a previous group is deleted (see UntypedResourceBuilder.WithGVKCandidates).
+//
+// When not set, the default propagation policy of the resource is used.
+//
+// The framework never renames resources: the migrations deleting the resource of a previous name,
+// see NewMigrateResourceStep, must pass the options themselves with DeleteOptions.
+//
+// This is typically used for resources that:
+//   - Own pods that must be gone before moving on (metav1.DeletePropagationForeground)
+//   - Own objects that must survive the deletion (metav1.DeletePropagationOrphan)
+//
+// Example:
+//
+//	.WithDeletePropagationPolicy(metav1.DeletePropagationForeground)
+func (b *ResourceBuilder[CustomResource, ContextType, ResourceType]) WithDeletePropagationPolicy(policy metav1.DeletionPropagation) *ResourceBuilder[CustomResource, ContextType, ResourceType] {
+	b.resource.deletePropagationPolicy = &policy
+	return b
+}
+
+// WithDeleteGracePeriodSeconds sets the grace period used whenever the framework deletes the resource,
+// see WithDeletePropagationPolicy.
+//
+// When not set, the default grace period of the resource is used.
+//
+// During finalization the resource is deleted after the BeforeReconcile hook and before the AfterFinalize hook.
+// Workloads that need to drain connections can be scaled down in the BeforeReconcile hook, returning an error
+// until they are drained so that the deletion is retried later on.
+//
+// Example:
+//
+//	.WithDeleteGracePeriodSeconds(30).
+//	WithBeforeReconcile(func(ctx MyContext) error {
+//		if !ctrlfwk.IsFinalizing(ctx.GetCustomResource()) {
+//			return nil
+//		}
+//		return scaleDownAndWait(ctx)
+//	})
+func (b *ResourceBuilder[CustomResource, ContextType, ResourceType]) WithDeleteGracePeriodSeconds(seconds int64) *ResourceBuilder[CustomResource, ContextType, ResourceType] {
+	b.resource.deleteGracePeriodSeconds = &seconds
+	return b
+}
+
+// WithOwnerReferenceBlocked marks the resource as one that must not be owned by the custom resource,
+// so that it is not garbage collected when the custom resource is deleted.
+//
+// The framework never sets owner references on such a resource. As owner references are usually
+// set in the mutator, the framework also inspects the resource after mutation and logs a warning
+// if it is owned by the custom resource anyway.
+//
+// This is typically used for resources that:
+//   - Hold audit data that must outlive the custom resource
+//   - Store important data, like PersistentVolumeClaims
+//
+// Example:
+//
+//	.WithOwnerReferenceBlocked(true)
+func (b *ResourceBuilder[CustomResource, ContextType, ResourceType]) WithOwnerReferenceBlocked(blocked bool) *ResourceBuilder[CustomResource, ContextType, ResourceType] {
+	b.resource.ownerReferenceBlocked = blocked
+	return b
+}
+
+// WithoutOwnerReference prevents the framework from setting the custom resource as the controller of the resource.
+//
+// By default, the custom resource is set as the controller owner of the resources after their mutator runs,
+// so that they are garbage collected along with it. The reference is not set on resources that can't be owned
+// by the custom resource: resources of another namespace, resources of a remote cluster (see WithClientFunc)
+// or resources already controlled by another object. Shared resources get a non-controller owner reference
+// instead (see WithSharedOwnership), and resources built with WithOwnerReferenceBlocked get none.
+//
+// This is typically used when the mutator sets its own owner references.
+//
+// Example:
+//
+//	.WithoutOwnerReference()
+func (b *ResourceBuilder[CustomResource, ContextType, ResourceType]) WithoutOwnerReference() *ResourceBuilder[CustomResource, ContextType, ResourceType] {
+	b.resource.noOwnerReference = true
+	return b
+}
+
 // WithBeforeReconcile registers a hook function to execute before resource reconciliation.
 //
 // This function is called before any resource operations (create, update, or delete)
@@ -395,6 +835,27 @@ func (b *ResourceBuilder[CustomResource, ContextType, ResourceType]) WithAfterRe
 	return b
 }
 
+// WithAfterReconcileErrorPolicy controls what happens when the AfterReconcile hook returns an error.
+//
+// By default (HookErrorPolicyAbort), a failing AfterReconcile hook fails the reconciliation
+// of the resource. This can be an issue when a broken status update on a minor resource
+// prevents more important resources from being reconciled.
+//
+// Available policies:
+//   - HookErrorPolicyAbort: The hook error is returned right away (default)
+//   - HookErrorPolicyContinueAndAggregate: Every resource is reconciled, hook errors are
+//     joined and returned at the end of ReconcileResourcesStep. The custom resource also
+//     gets a ResourceHooksFailed condition listing the resources whose hooks failed
+//   - HookErrorPolicyContinueAndIgnore: The hook error is logged and otherwise ignored
+//
+// Example:
+//
+//	.WithAfterReconcileErrorPolicy(ctrlfwk.HookErrorPolicyContinueAndAggregate)
+func (b *ResourceBuilder[CustomResource, ContextType, ResourceType]) WithAfterReconcileErrorPolicy(policy HookErrorPolicy) *ResourceBuilder[CustomResource, ContextType, ResourceType] {
+	b.resource.afterReconcileErrorPolicy = policy
+	return b
+}
+
 // WithAfterCreate registers a hook function that executes only when a resource is newly created.
 //
 // This function is called specifically when a resource is created for the first time,
@@ -465,6 +926,80 @@ func (b *ResourceBuilder[CustomResource, ContextType, ResourceType]) WithAfterUp
 	return b
 }
 
+// WithAfterScale registers a hook function that executes only when the resource is scaled, see WithScale.
+//
+// The function receives the resource as it exists in the cluster once scaled.
+//
+// Example:
+//
+//	.WithAfterScale(func(ctx MyContext, deployment *appsv1.Deployment) error {
+//		ctx.GetLogger().Info("Deployment scaled", "replicas", *deployment.Spec.Replicas)
+//		return nil
+//	})
+func (b *ResourceBuilder[CustomResource, ContextType, ResourceType]) WithAfterScale(f func(ctx ContextType, resource ResourceType) error) *ResourceBuilder[CustomResource, ContextType, ResourceType] {
+	b.resource.onScaleF = f
+	return b
+}
+
+// WithAfterStatusUpdate registers a hook function that executes only when the status of the resource is patched,
+// see WithStatusMutator.
+//
+// The function receives the resource as it exists in the cluster once its status is patched.
+func (b *ResourceBuilder[CustomResource, ContextType, ResourceType]) WithAfterStatusUpdate(f func(ctx ContextType, resource ResourceType) error) *ResourceBuilder[CustomResource, ContextType, ResourceType] {
+	b.resource.onStatusUpdateF = f
+	return b
+}
+
+// WithPreMutateValidator registers a function validating the custom resource before the mutator runs.
+//
+// The function receives the custom resource and the resource as it exists in the cluster,
+// or a nil resource if it does not exist yet. It is called on every reconciliation, before
+// the resource gets created or updated.
+//
+// Returning a ValidationError (see NewValidationError) blocks the creation or update of the
+// resource: the custom resource gets a ValidationFailed condition and the reconciliation stops
+// without being requeued, until the custom resource changes. Any other error fails the
+// reconciliation and is retried as usual.
+//
+// This allows for webhook-like validation when admission webhooks are not installed.
+//
+// Example:
+//
+//	.WithPreMutateValidator(func(cr *MyCustomResource, existing *corev1.PersistentVolumeClaim) error {
+//		if existing != nil && cr.Spec.StorageClassName != *existing.Spec.StorageClassName {
+//			return ctrlfwk.NewValidationError("the storage class can't be changed once the volume is created")
+//		}
+//		return nil
+//	})
+func (b *ResourceBuilder[CustomResource, ContextType, ResourceType]) WithPreMutateValidator(f func(cr CustomResource, existingResource ResourceType) error) *ResourceBuilder[CustomResource, ContextType, ResourceType] {
+	b.resource.preMutateValidatorF = f
+	return b
+}
+
+// WithBeforeDelete registers a hook function that executes right before the framework deletes a resource.
+//
+// This function is called with the live resource, as it exists in the cluster, either because
+// the skip condition is met or because the custom resource is being finalized. It is called exactly
+// once per deletion attempt, strictly before the delete request. It gives an opportunity to persist
+// the content of the resource before it goes away.
+//
+// Returning a SkipDeletionError (see SkipDeletion) vetoes the deletion: the custom resource gets
+// a DeletionSkipped condition and the deletion is attempted again later on. Any other error fails
+// the reconciliation.
+//
+// Example:
+//
+//	.WithBeforeDelete(func(ctx MyContext, secret *corev1.Secret) error {
+//		if err := backupToBucket(ctx, secret); err != nil {
+//			return ctrlfwk.SkipDeletion("backup of the secret failed")
+//		}
+//		return nil
+//	})
+func (b *ResourceBuilder[CustomResource, ContextType, ResourceType]) WithBeforeDelete(f func(ctx ContextType, resource ResourceType) error) *ResourceBuilder[CustomResource, ContextType, ResourceType] {
+	b.resource.onBeforeDeleteF = f
+	return b
+}
+
 // WithAfterDelete registers a hook function that executes after a resource is deleted.
 //
 // This function is called when a resource has been successfully deleted from the cluster,
@@ -535,6 +1070,176 @@ func (b *ResourceBuilder[CustomResource, ContextType, ResourceType]) WithAfterFi
 	return b
 }
 
+// WithDependsOn declares that this resource must be reconciled after the resources with the given identifiers,
+// see WithUserIdentifier. It is only taken into account by ReconcileResourcesStep, where the resource is not
+// reconciled until the resources it depends on are reconciled and ready.
+//
+// Resources without ordering constraints are reconciled in declaration order, or concurrently
+// when the step is configured with WithConcurrency.
+//
+// Example:
+//
+//	.WithUserIdentifier("deployment").
+//	WithDependsOn("config", "credentials")
+func (b *ResourceBuilder[CustomResource, ContextType, ResourceType]) WithDependsOn(resourceIDs ...string) *ResourceBuilder[CustomResource, ContextType, ResourceType] {
+	b.resource.dependsOn = append(b.resource.dependsOn, resourceIDs...)
+	return b
+}
+
+// WithDataPropagation fills the data of the resource, a Secret or a ConfigMap, from a source Secret or ConfigMap,
+// typically the output of a dependency. The source is read on each reconciliation, its keys transformed by mapper
+// (nil keeping them as is), and the result replaces the data of the resource after the mutator ran.
+// Binary data and base64 encoding are handled for typed and unstructured objects.
+//
+// The resource gets the AnnotationPropagationSourceHash annotation, changing along with the propagated data.
+// When the source does not exist, which requires the dependency to be optional, the resource is handled
+// according to WithSourceDeletionPolicy and the custom resource gets a PropagationSourceMissing condition.
+//
+// Example:
+//
+//	.WithDataPropagation(func() client.Object { return ctx.Data.SourceSecret },
+//		ctrlfwk.RenameKeys(map[string]string{"password": "DB_PASSWORD"}))
+func (b *ResourceBuilder[CustomResource, ContextType, ResourceType]) WithDataPropagation(source func() client.Object, mapper PropagationKeyMapper) *ResourceBuilder[CustomResource, ContextType, ResourceType] {
+	if b.resource.propagation == nil {
+		b.resource.propagation = &dataPropagation{}
+	}
+	b.resource.propagation.source = source
+	b.resource.propagation.mapper = mapper
+	return b
+}
+
+// WithSourceDeletionPolicy tells what happens to the resource when the source of WithDataPropagation does not exist.
+// SourceDeletionPolicyRetain, the default, keeps the data last propagated, SourceDeletionPolicyDelete deletes the resource.
+func (b *ResourceBuilder[CustomResource, ContextType, ResourceType]) WithSourceDeletionPolicy(policy SourceDeletionPolicy) *ResourceBuilder[CustomResource, ContextType, ResourceType] {
+	if b.resource.propagation == nil {
+		b.resource.propagation = &dataPropagation{}
+	}
+	b.resource.propagation.deletionPolicy = policy
+	return b
+}
+
+// WithImmutableFields protects fields of the resource against external modifications, such as a label used
+// for selection. Paths are JSON pointers, "/metadata/labels/app.kubernetes.io~1name" for example, into objects only.
+//
+// The values written by the framework are recorded in the AnnotationImmutableFields annotation. Before each update,
+// fields modified by someone else are restored to the recorded value, unless the mutator sets another value itself,
+// and the modification is logged and reported with a warning event when the reconciler is a record.EventRecorder.
+//
+// Example:
+//
+//	.WithImmutableFields("/spec/selector", "/metadata/labels/app")
+func (b *ResourceBuilder[CustomResource, ContextType, ResourceType]) WithImmutableFields(paths ...string) *ResourceBuilder[CustomResource, ContextType, ResourceType] {
+	if b.resource.immutable == nil {
+		b.resource.immutable = &immutableFields{}
+	}
+	b.resource.immutable.paths = append(b.resource.immutable.paths, paths...)
+	return b
+}
+
+// WithRecreateOnImmutableFieldConflict deletes the resource, to create it again on the next reconciliation, when the
+// API server rejects an update because it changes a field it holds immutable, such as the clusterIP of a Service.
+// It only applies to resources built with WithImmutableFields, the update failing otherwise.
+func (b *ResourceBuilder[CustomResource, ContextType, ResourceType]) WithRecreateOnImmutableFieldConflict(recreate bool) *ResourceBuilder[CustomResource, ContextType, ResourceType] {
+	if b.resource.immutable == nil {
+		b.resource.immutable = &immutableFields{}
+	}
+	b.resource.immutable.recreate = recreate
+	return b
+}
+
+// WithSharedOwnership declares a resource shared by several custom resources, such as a ConfigMap used by all
+// the instances of an application. The custom resource is added as a non-controller owner of the resource,
+// and when the framework would delete it, it only removes its own owner reference as long as other owners remain.
+// The last owner deletes it.
+//
+// Shared resources don't get the tracking labels of a single owner, so the prune step does not consider them.
+func (b *ResourceBuilder[CustomResource, ContextType, ResourceType]) WithSharedOwnership(shared bool) *ResourceBuilder[CustomResource, ContextType, ResourceType] {
+	b.resource.sharedOwnership = shared
+	return b
+}
+
+// WithSensitive declares a resource holding sensitive data, such as a credentials custom resource.
+// Like for secrets, the values of its fields are redacted from the errors, events and logs of the framework,
+// see Redactor.
+func (b *ResourceBuilder[CustomResource, ContextType, ResourceType]) WithSensitive(sensitive bool) *ResourceBuilder[CustomResource, ContextType, ResourceType] {
+	b.resource.sensitive = sensitive
+	return b
+}
+
+// WithClient reconciles the resource with the given client instead of the reconciler, e.g. to create it
+// in a remote cluster managed from the cluster of the custom resource. See WithClientFunc.
+func (b *ResourceBuilder[CustomResource, ContextType, ResourceType]) WithClient(c client.Client) *ResourceBuilder[CustomResource, ContextType, ResourceType] {
+	return b.WithClientFunc(func(ContextType) (client.Client, error) { return c, nil })
+}
+
+// WithClientFunc reconciles the resource with the client returned by f, called on each reconciliation,
+// e.g. to build the client of a remote cluster from a kubeconfig secret. Callers should cache the clients they build.
+//
+// Owner references don't work across clusters, so the resource gets the tracking labels of the custom resource
+// instead and is deleted by the framework when the custom resource is finalized, which requires a finalizer
+// (see NewAddFinalizerStep). Shared ownership is not supported, and as the resource can't be watched,
+// the reconciliation is requeued while it is not ready. The prune step only considers the cluster of the reconciler.
+//
+// When f fails, the RemoteClusterUnavailable condition is set on the custom resource and the reconciliation is requeued.
+//
+// Example:
+//
+//	.WithClientFunc(func(ctx MyContext) (client.Client, error) {
+//		return clusters.ClientFor(ctx, ctx.GetCustomResource().Spec.ClusterRef)
+//	})
+func (b *ResourceBuilder[CustomResource, ContextType, ResourceType]) WithClientFunc(f func(ctx ContextType) (client.Client, error)) *ResourceBuilder[CustomResource, ContextType, ResourceType] {
+	b.resource.clientF = f
+	return b
+}
+
+// WithOwnedConditions declares the condition types of the custom resource that belong to this resource,
+// typically set by its hooks. Once the resource is not declared anymore, the condition cleanup step
+// removes them from the custom resource, see NewConditionCleanupStep.
+//
+// Example:
+//
+//	.WithOwnedConditions("CacheReady")
+func (b *ResourceBuilder[CustomResource, ContextType, ResourceType]) WithOwnedConditions(conditionTypes ...string) *ResourceBuilder[CustomResource, ContextType, ResourceType] {
+	b.resource.ownedConditions = append(b.resource.ownedConditions, conditionTypes...)
+	return b
+}
+
+// WithManagedPDB manages a PodDisruptionBudget alongside a Deployment resource, as a single logical unit.
+// The PodDisruptionBudget is named after the Deployment and owned by it, so it is deleted along with it,
+// and the resource is only considered ready once the PodDisruptionBudget is observed as well.
+// The spec is computed from the reconciled Deployment on each reconciliation, nil deleting the PodDisruptionBudget.
+//
+// The resource must be a Deployment, the reconciliation fails otherwise.
+//
+// Example:
+//
+//	.WithManagedPDB(func(deploy *appsv1.Deployment) *policyv1.PodDisruptionBudgetSpec {
+//		minAvailable := intstr.FromInt32(1)
+//		return &policyv1.PodDisruptionBudgetSpec{
+//			MinAvailable: &minAvailable,
+//			Selector:     deploy.Spec.Selector,
+//		}
+//	})
+func (b *ResourceBuilder[CustomResource, ContextType, ResourceType]) WithManagedPDB(pdbSpec func(deploy *appsv1.Deployment) *policyv1.PodDisruptionBudgetSpec) *ResourceBuilder[CustomResource, ContextType, ResourceType] {
+	b.resource.managedPDBF = pdbSpec
+	return b
+}
+
+// WithStatusField has the framework populate a status sub-object of the custom resource after each reconciliation
+// of the resource, with its name, namespace, generation and readiness. The status is patched only when it changes,
+// and cleared when the resource gets deleted. The function must return a pointer into the given custom resource,
+// nil skipping the update.
+//
+// Example:
+//
+//	.WithStatusField(func(cr *MyCustomResource) *ctrlfwk.ResourceStatus {
+//		return &cr.Status.Deployment
+//	})
+func (b *ResourceBuilder[CustomResource, ContextType, ResourceType]) WithStatusField(f func(cr CustomResource) *ResourceStatus) *ResourceBuilder[CustomResource, ContextType, ResourceType] {
+	b.resource.statusFieldF = f
+	return b
+}
+
 // WithUserIdentifier assigns a custom identifier for this resource.
 //
 // This identifier is used for logging, debugging, and distinguishing between multiple
@@ -559,6 +1264,27 @@ func (b *ResourceBuilder[CustomResource, ContextType, ResourceType]) WithUserIde
 	return b
 }
 
+// WithUserIdentifierPrefix prefixes the identifier set with WithUserIdentifier, typically through ResourceDefaults
+// to namespace the identifiers of a controller. WithDependsOn must use the prefixed identifiers.
+func (b *ResourceBuilder[CustomResource, ContextType, ResourceType]) WithUserIdentifierPrefix(prefix string) *ResourceBuilder[CustomResource, ContextType, ResourceType] {
+	b.resource.userIdentifierPrefix = prefix
+	return b
+}
+
+// WithDefaultMutators sets the mutators running in order before the one set with WithMutator,
+// replacing the ones of ResourceDefaults. Calling it without mutators disables them.
+func (b *ResourceBuilder[CustomResource, ContextType, ResourceType]) WithDefaultMutators(mutators ...Mutator[client.Object]) *ResourceBuilder[CustomResource, ContextType, ResourceType] {
+	b.resource.defaultMutators = mutators
+	return b
+}
+
+// WithLifecycleEvents emits a Normal event on the custom resource when the resource gets created, updated or deleted
+// by the framework, if the reconciler is a record.EventRecorder.
+func (b *ResourceBuilder[CustomResource, ContextType, ResourceType]) WithLifecycleEvents(enabled bool) *ResourceBuilder[CustomResource, ContextType, ResourceType] {
+	b.resource.lifecycleEventsEnabled = enabled
+	return b
+}
+
 // WithCanBePaused specifies whether this resource supports pausing reconciliation.
 //
 // When set to true, the resource will respect the paused state of the custom resource.
@@ -615,8 +1341,13 @@ func (b *ResourceBuilder[CustomResource, ContextType, ResourceType]) WithCanBePa
 // Validation:
 //   - At least one of WithKey or WithKeyFunc must be called before Build()
 //   - WithMutator is typically required for meaningful resource management
+//   - WithMutator and WithMutatorWithExisting are mutually exclusive, the steps reconciling the resource
+//     fail with the validation error otherwise
 //
 // Returns a configured Resource instance ready for use in reconciliation.
 func (b *ResourceBuilder[CustomResource, ContextType, ResourceType]) Build() *Resource[CustomResource, ContextType, ResourceType] {
+	if b.resource.mutateF != nil && b.resource.mutateWithExistingF != nil {
+		b.resource.buildErr = errors.New("WithMutator and WithMutatorWithExisting are mutually exclusive")
+	}
 	return b.resource
 }
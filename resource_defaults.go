@@ -0,0 +1,79 @@
+package ctrlfwk
+
+import (
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// ResourceDefaults holds the options shared by the resources of a controller, see NewResourceBuilderWithDefaults.
+type ResourceDefaults struct {
+	// Mutators run in order before the mutator of each resource. The mutation order is: these mutators,
+	// then the mutator of the resource, then the framework managed metadata which always wins.
+	Mutators []Mutator[client.Object]
+	// CanBePaused is the default of WithCanBePaused.
+	CanBePaused bool
+	// LifecycleEvents is the default of WithLifecycleEvents.
+	LifecycleEvents bool
+	// UserIdentifierPrefix is the default of WithUserIdentifierPrefix.
+	UserIdentifierPrefix string
+}
+
+// NewResourceBuilderWithDefaults creates a ResourceBuilder with the options of defaults already applied,
+// so that the resources of a controller don't have to repeat them. Each builder can override any of them
+// using the matching option, WithDefaultMutators replacing the default mutators.
+//
+// Example:
+//
+//	defaults := ctrlfwk.ResourceDefaults{
+//		Mutators: []ctrlfwk.Mutator[client.Object]{func(obj client.Object) error {
+//			ctrlfwk.SetAnnotation(obj, "example.com/managed", "true")
+//			return nil
+//		}},
+//		CanBePaused:     true,
+//		LifecycleEvents: true,
+//	}
+//
+//	service := ctrlfwk.NewResourceBuilderWithDefaults(ctx, &corev1.Service{}, defaults).
+//		WithKey(key).
+//		WithMutator(mutateService).
+//		Build()
+func NewResourceBuilderWithDefaults[CustomResource client.Object, ContextType Context[CustomResource], ResourceType client.Object](ctx ContextType, obj ResourceType, defaults ResourceDefaults) *ResourceBuilder[CustomResource, ContextType, ResourceType] {
+	return NewResourceBuilder[CustomResource](ctx, obj).
+		WithDefaultMutators(defaults.Mutators...).
+		WithCanBePaused(defaults.CanBePaused).
+		WithLifecycleEvents(defaults.LifecycleEvents).
+		WithUserIdentifierPrefix(defaults.UserIdentifierPrefix)
+}
+
+// NewUntypedResourceBuilderWithDefaults creates an UntypedResourceBuilder with the options of defaults already applied,
+// see NewResourceBuilderWithDefaults.
+func NewUntypedResourceBuilderWithDefaults[CustomResource client.Object, ContextType Context[CustomResource]](ctx ContextType, gvk schema.GroupVersionKind, defaults ResourceDefaults) *UntypedResourceBuilder[CustomResource, ContextType] {
+	builder := NewUntypedResourceBuilder[CustomResource](ctx, gvk)
+	builder.inner = NewResourceBuilderWithDefaults[CustomResource](ctx, &unstructured.Unstructured{}, defaults)
+	return builder
+}
+
+// lifecycleEventsResource is implemented by the resources that can be built with WithLifecycleEvents.
+type lifecycleEventsResource interface {
+	lifecycleEvents() bool
+}
+
+// recordLifecycleEvent emits an event on the custom resource for a resource built with WithLifecycleEvents,
+// when the reconciler is a record.EventRecorder.
+func recordLifecycleEvent[ControllerResourceType ControllerCustomResource, ContextType Context[ControllerResourceType]](
+	reconciler Reconciler[ControllerResourceType],
+	resource GenericResource[ControllerResourceType, ContextType],
+	cr ControllerResourceType,
+	reason string,
+	action string,
+) {
+	withEvents, ok := resource.(lifecycleEventsResource)
+	if !ok || !withEvents.lifecycleEvents() {
+		return
+	}
+	if recorder, ok := reconciler.(record.EventRecorder); ok {
+		recorder.Eventf(cr, "Normal", reason, "Resource %s was %s", resource.ID(), action)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
@@ -11,12 +12,39 @@ import (
 type UntypedResource[CustomResource client.Object, ContextType Context[CustomResource]] struct {
 	*Resource[CustomResource, ContextType, *unstructured.Unstructured]
 	gvk schema.GroupVersionKind
+
+	fieldManager    string
+	candidates      []schema.GroupVersionKind
+	migrationPolicy GVKMigrationPolicy
 }
 
 var _ GenericResource[client.Object, Context[client.Object]] = &UntypedResource[client.Object, Context[client.Object]]{}
 
 func (c *UntypedResource[CustomResource, ContextType]) Kind() string {
-	return fmt.Sprintf("Untyped%s", c.gvk.Kind)
+	return fmt.Sprintf("Untyped%s", c.preferredGVK().Kind)
+}
+
+// ID returns the user identifier of the resource, or one qualified with its group and version
+// (e.g. "monitoring.coreos.com/v1/ServiceMonitor,default/name"), so that untyped resources of a same kind
+// from different groups don't collide.
+func (c *UntypedResource[CustomResource, ContextType]) ID() string {
+	if c.userIdentifier != "" {
+		return c.userIdentifierPrefix + c.userIdentifier
+	}
+	return fmt.Sprintf("%v,%v", qualifiedKind(c.preferredGVK()), c.keyF())
+}
+
+// preferredGVK is the GroupVersionKind the resource is identified with, whatever the negotiated version.
+func (c *UntypedResource[CustomResource, ContextType]) preferredGVK() schema.GroupVersionKind {
+	if len(c.candidates) > 0 {
+		return c.candidates[0]
+	}
+	return c.gvk
+}
+
+// GroupVersionKind returns the GroupVersionKind of the resource, the negotiated one when built with GVK candidates.
+func (c *UntypedResource[CustomResource, ContextType]) GroupVersionKind(_ *runtime.Scheme) (schema.GroupVersionKind, error) {
+	return c.gvk, nil
 }
 
 func (c *UntypedResource[CustomResource, ContextType]) ObjectMetaGenerator() (obj client.Object, skip bool, err error) {
@@ -35,3 +63,22 @@ func (c *UntypedResource[CustomResource, ContextType]) ObjectMetaGenerator() (ob
 	unstructuredObj.SetGroupVersionKind(c.gvk)
 	return unstructuredObj, false, nil
 }
+
+func (c *UntypedResource[CustomResource, ContextType]) ServerSideApplyFieldManager() string {
+	return c.fieldManager
+}
+
+func (c *UntypedResource[CustomResource, ContextType]) gvkCandidates() []schema.GroupVersionKind {
+	return c.candidates
+}
+
+func (c *UntypedResource[CustomResource, ContextType]) gvkMigrationPolicy() GVKMigrationPolicy {
+	if c.migrationPolicy == "" {
+		return GVKMigrationPolicyConvert
+	}
+	return c.migrationPolicy
+}
+
+func (c *UntypedResource[CustomResource, ContextType]) setNegotiatedGVK(gvk schema.GroupVersionKind) {
+	c.gvk = gvk
+}
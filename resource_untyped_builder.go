@@ -1,6 +1,9 @@
 package ctrlfwk
 
 import (
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
@@ -61,6 +64,10 @@ import (
 type UntypedResourceBuilder[CustomResource client.Object, ContextType Context[CustomResource]] struct {
 	inner *ResourceBuilder[CustomResource, ContextType, *unstructured.Unstructured]
 	gvk   schema.GroupVersionKind
+
+	fieldManager    string
+	candidates      []schema.GroupVersionKind
+	migrationPolicy GVKMigrationPolicy
 }
 
 // NewUntypedResourceBuilder creates a new UntypedResourceBuilder for constructing
@@ -130,11 +137,63 @@ func NewUntypedResourceBuilder[CustomResource client.Object, ContextType Context
 // Returns a configured UntypedResource instance ready for use in reconciliation.
 func (b *UntypedResourceBuilder[CustomResource, ContextType]) Build() *UntypedResource[CustomResource, ContextType] {
 	return &UntypedResource[CustomResource, ContextType]{
-		Resource: b.inner.Build(),
-		gvk:      b.gvk,
+		Resource:        b.inner.Build(),
+		gvk:             b.gvk,
+		fieldManager:    b.fieldManager,
+		candidates:      b.candidates,
+		migrationPolicy: b.migrationPolicy,
 	}
 }
 
+// WithServerSideApply reconciles the untyped resource using server-side apply with the given field manager,
+// instead of fetching the full object, mutating it and patching it back.
+//
+// The mutator then receives an object holding only the GroupVersionKind, name and namespace of the resource,
+// and must set every field the controller cares about, the result is used as the apply configuration.
+// Fields set by other managers, such as a third-party operator, are left untouched, while conflicting
+// fields are forcibly taken over by this field manager.
+//
+// Example:
+//
+//	.WithServerSideApply("my-operator").
+//	WithMutator(func(obj *unstructured.Unstructured) error {
+//		// Only the fields owned by this controller
+//		return unstructured.SetNestedField(obj.Object, "30s", "spec", "endpoints", "interval")
+//	})
+func (b *UntypedResourceBuilder[CustomResource, ContextType]) WithServerSideApply(fieldManager string) *UntypedResourceBuilder[CustomResource, ContextType] {
+	b.fieldManager = fieldManager
+	return b
+}
+
+// WithGVKCandidates manages the resource with the first of the given GroupVersionKinds served by the cluster,
+// candidates being sorted by preference. It replaces the GroupVersionKind given to NewUntypedResourceBuilder.
+//
+// The negotiated version is cached and refreshed periodically or when the cluster stops serving it,
+// so the preferred version gets used once it is served, see WithGVKMigrationPolicy.
+// While a fallback version is used, the custom resource gets an APIVersionFallback condition.
+//
+// Example:
+//
+//	.WithGVKCandidates(
+//		schema.GroupVersionKind{Group: "monitoring.coreos.com", Version: "v1", Kind: "ServiceMonitor"},
+//		schema.GroupVersionKind{Group: "monitoring.coreos.com", Version: "v1alpha1", Kind: "ServiceMonitor"},
+//	)
+func (b *UntypedResourceBuilder[CustomResource, ContextType]) WithGVKCandidates(gvks ...schema.GroupVersionKind) *UntypedResourceBuilder[CustomResource, ContextType] {
+	b.candidates = gvks
+	if len(gvks) > 0 {
+		b.gvk = gvks[0]
+	}
+	return b
+}
+
+// WithGVKMigrationPolicy tells what to do with the resource written with a fallback candidate once
+// a more preferred one is served. GVKMigrationPolicyConvert is used by default, which is enough for
+// versions of a same group, GVKMigrationPolicyRecreate must be used when candidates span several groups.
+func (b *UntypedResourceBuilder[CustomResource, ContextType]) WithGVKMigrationPolicy(policy GVKMigrationPolicy) *UntypedResourceBuilder[CustomResource, ContextType] {
+	b.migrationPolicy = policy
+	return b
+}
+
 // WithAfterCreate registers a hook function that executes only when an untyped resource is newly created.
 //
 // This function is called specifically when a resource is created for the first time,
@@ -162,6 +221,37 @@ func (b *UntypedResourceBuilder[CustomResource, ContextType]) WithAfterCreate(f
 	return b
 }
 
+// WithPreMutateValidator registers a function validating the custom resource before the mutator runs.
+//
+// See ResourceBuilder.WithPreMutateValidator for more details.
+//
+// Example:
+//
+//	.WithPreMutateValidator(func(cr *MyCustomResource, existing *unstructured.Unstructured) error {
+//		if cr.Spec.Interval == "" {
+//			return ctrlfwk.NewValidationError("spec.interval is required")
+//		}
+//		return nil
+//	})
+func (b *UntypedResourceBuilder[CustomResource, ContextType]) WithPreMutateValidator(f func(cr CustomResource, existingResource *unstructured.Unstructured) error) *UntypedResourceBuilder[CustomResource, ContextType] {
+	b.inner = b.inner.WithPreMutateValidator(f)
+	return b
+}
+
+// WithBeforeDelete registers a hook function that executes right before the framework deletes an untyped resource.
+//
+// See ResourceBuilder.WithBeforeDelete for more details.
+//
+// Example:
+//
+//	.WithBeforeDelete(func(ctx MyContext, obj *unstructured.Unstructured) error {
+//		return ctrlfwk.SkipDeletion("waiting for the backup")
+//	})
+func (b *UntypedResourceBuilder[CustomResource, ContextType]) WithBeforeDelete(f func(ctx ContextType, resource *unstructured.Unstructured) error) *UntypedResourceBuilder[CustomResource, ContextType] {
+	b.inner = b.inner.WithBeforeDelete(f)
+	return b
+}
+
 // WithAfterDelete registers a hook function that executes after an untyped resource is deleted.
 //
 // This function is called when a resource has been successfully deleted from the cluster,
@@ -253,6 +343,18 @@ func (b *UntypedResourceBuilder[CustomResource, ContextType]) WithAfterReconcile
 	return b
 }
 
+// WithAfterReconcileErrorPolicy controls what happens when the AfterReconcile hook returns an error.
+//
+// See ResourceBuilder.WithAfterReconcileErrorPolicy for the available policies.
+//
+// Example:
+//
+//	.WithAfterReconcileErrorPolicy(ctrlfwk.HookErrorPolicyContinueAndIgnore)
+func (b *UntypedResourceBuilder[CustomResource, ContextType]) WithAfterReconcileErrorPolicy(policy HookErrorPolicy) *UntypedResourceBuilder[CustomResource, ContextType] {
+	b.inner = b.inner.WithAfterReconcileErrorPolicy(policy)
+	return b
+}
+
 // WithAfterUpdate registers a hook function that executes only when an untyped resource is updated.
 //
 // This function is called specifically when an existing untyped resource is modified,
@@ -356,6 +458,20 @@ func (b *UntypedResourceBuilder[CustomResource, ContextType]) WithKeyFunc(f func
 	return b
 }
 
+// WithKeyFromStatusFallback computes the key of the resource with specFn, or with statusFn while useStatus
+// returns true, see ResourceBuilder.WithKeyFromStatusFallback.
+func (b *UntypedResourceBuilder[CustomResource, ContextType]) WithKeyFromStatusFallback(specFn, statusFn func() types.NamespacedName, useStatus func() bool) *UntypedResourceBuilder[CustomResource, ContextType] {
+	b.inner = b.inner.WithKeyFromStatusFallback(specFn, statusFn, useStatus)
+	return b
+}
+
+// WithGeneratedKey names the untyped resource after the custom resource and suffix using Name,
+// see ResourceBuilder.WithGeneratedKey.
+func (b *UntypedResourceBuilder[CustomResource, ContextType]) WithGeneratedKey(suffix string) *UntypedResourceBuilder[CustomResource, ContextType] {
+	b.inner = b.inner.WithGeneratedKey(suffix)
+	return b
+}
+
 // WithMutator specifies the function that configures the untyped resource's desired state.
 //
 // The mutator function receives an unstructured.Unstructured object and should configure
@@ -398,8 +514,8 @@ func (b *UntypedResourceBuilder[CustomResource, ContextType]) WithKeyFunc(f func
 //			return err
 //		}
 //
-//		// Set owner reference for garbage collection
-//		return controllerutil.SetOwnerReference(cr, obj, ctx.GetScheme())
+//		// The custom resource is set as the controller of the object by the framework, see WithoutOwnerReference
+//		return nil
 //	})
 func (b *UntypedResourceBuilder[CustomResource, ContextType]) WithMutator(f Mutator[*unstructured.Unstructured]) *UntypedResourceBuilder[CustomResource, ContextType] {
 	b.inner = b.inner.WithMutator(f)
@@ -434,6 +550,21 @@ func (b *UntypedResourceBuilder[CustomResource, ContextType]) WithOutput(obj *un
 	return b
 }
 
+// WithOutputFunc specifies where to store the reconciled untyped resource, the function is evaluated
+// at reconcile time instead of when the builder runs.
+//
+// See ResourceBuilder.WithOutputFunc for more details.
+//
+// Example:
+//
+//	.WithOutputFunc(func() *unstructured.Unstructured {
+//		return ctx.Data.ServiceMonitor
+//	})
+func (b *UntypedResourceBuilder[CustomResource, ContextType]) WithOutputFunc(f func() *unstructured.Unstructured) *UntypedResourceBuilder[CustomResource, ContextType] {
+	b.inner = b.inner.WithOutputFunc(f)
+	return b
+}
+
 // WithReadinessCondition defines custom logic to determine when the untyped resource is ready.
 //
 // The provided function is called with the current unstructured resource state and should
@@ -467,6 +598,21 @@ func (b *UntypedResourceBuilder[CustomResource, ContextType]) WithReadinessCondi
 	return b
 }
 
+// WithReadinessReason defines how to determine if the untyped resource is ready, while explaining why it is not.
+//
+// See ResourceBuilder.WithReadinessReason for more details.
+//
+// Example:
+//
+//	.WithReadinessReason(func(obj *unstructured.Unstructured) (bool, string, string) {
+//		phase, _, _ := unstructured.NestedString(obj.Object, "status", "phase")
+//		return phase == "Ready", "PhaseNotReady", fmt.Sprintf("phase is %q", phase)
+//	})
+func (b *UntypedResourceBuilder[CustomResource, ContextType]) WithReadinessReason(f func(obj *unstructured.Unstructured) (ready bool, reason, message string)) *UntypedResourceBuilder[CustomResource, ContextType] {
+	b.inner = b.inner.WithReadinessReason(f)
+	return b
+}
+
 // WithRequireManualDeletionForFinalize specifies when an untyped resource requires manual cleanup
 // during custom resource finalization.
 //
@@ -495,6 +641,54 @@ func (b *UntypedResourceBuilder[CustomResource, ContextType]) WithRequireManualD
 	return b
 }
 
+// WithDeletePropagationPolicy sets the propagation policy used whenever the framework deletes the untyped resource.
+//
+// See ResourceBuilder.WithDeletePropagationPolicy for more details.
+//
+// Example:
+//
+//	.WithDeletePropagationPolicy(metav1.DeletePropagationOrphan)
+func (b *UntypedResourceBuilder[CustomResource, ContextType]) WithDeletePropagationPolicy(policy metav1.DeletionPropagation) *UntypedResourceBuilder[CustomResource, ContextType] {
+	b.inner = b.inner.WithDeletePropagationPolicy(policy)
+	return b
+}
+
+// WithDeleteGracePeriodSeconds sets the grace period used whenever the framework deletes the untyped resource.
+//
+// See ResourceBuilder.WithDeleteGracePeriodSeconds for more details.
+//
+// Example:
+//
+//	.WithDeleteGracePeriodSeconds(30)
+func (b *UntypedResourceBuilder[CustomResource, ContextType]) WithDeleteGracePeriodSeconds(seconds int64) *UntypedResourceBuilder[CustomResource, ContextType] {
+	b.inner = b.inner.WithDeleteGracePeriodSeconds(seconds)
+	return b
+}
+
+// WithOwnerReferenceBlocked marks the untyped resource as one that must not be owned by the custom resource.
+//
+// See ResourceBuilder.WithOwnerReferenceBlocked for more details.
+//
+// Example:
+//
+//	.WithOwnerReferenceBlocked(true)
+func (b *UntypedResourceBuilder[CustomResource, ContextType]) WithOwnerReferenceBlocked(blocked bool) *UntypedResourceBuilder[CustomResource, ContextType] {
+	b.inner = b.inner.WithOwnerReferenceBlocked(blocked)
+	return b
+}
+
+// WithoutOwnerReference prevents the framework from setting the custom resource as the controller of the untyped resource.
+//
+// See ResourceBuilder.WithoutOwnerReference for more details.
+//
+// Example:
+//
+//	.WithoutOwnerReference()
+func (b *UntypedResourceBuilder[CustomResource, ContextType]) WithoutOwnerReference() *UntypedResourceBuilder[CustomResource, ContextType] {
+	b.inner = b.inner.WithoutOwnerReference()
+	return b
+}
+
 // WithSkipAndDeleteOnCondition specifies when to skip creating or delete an existing untyped resource.
 //
 // This is particularly useful for untyped resources that depend on optional third-party
@@ -533,6 +727,21 @@ func (b *UntypedResourceBuilder[CustomResource, ContextType]) WithSkipAndDeleteO
 	return b
 }
 
+// WithSkipAndDeleteOnConditionFunc specifies when to skip creating or delete an existing untyped resource,
+// f receiving the context of the reconciliation, see ResourceBuilder.WithSkipAndDeleteOnConditionFunc.
+func (b *UntypedResourceBuilder[CustomResource, ContextType]) WithSkipAndDeleteOnConditionFunc(f func(ctx ContextType) (bool, error)) *UntypedResourceBuilder[CustomResource, ContextType] {
+	b.inner = b.inner.WithSkipAndDeleteOnConditionFunc(f)
+	return b
+}
+
+// WithDependsOn declares that this untyped resource must be reconciled after the resources with the given identifiers.
+//
+// See ResourceBuilder.WithDependsOn for more details.
+func (b *UntypedResourceBuilder[CustomResource, ContextType]) WithDependsOn(resourceIDs ...string) *UntypedResourceBuilder[CustomResource, ContextType] {
+	b.inner = b.inner.WithDependsOn(resourceIDs...)
+	return b
+}
+
 // WithUserIdentifier assigns a custom identifier for this untyped resource.
 //
 // This identifier is used for logging, debugging, and distinguishing between multiple
@@ -593,3 +802,205 @@ func (b *UntypedResourceBuilder[CustomResource, ContextType]) WithCanBePausedFun
 	b.inner = b.inner.WithCanBePausedFunc(f)
 	return b
 }
+
+// WithStatusField has the framework populate a status sub-object of the custom resource after each reconciliation
+// of the untyped resource, with its name, namespace, generation and readiness. The status is patched only when
+// it changes, and cleared when the resource gets deleted.
+//
+// Example:
+//
+//	.WithStatusField(func(cr *MyCustomResource) *ctrlfwk.ResourceStatus {
+//		return &cr.Status.ServiceMonitor
+//	})
+func (b *UntypedResourceBuilder[CustomResource, ContextType]) WithStatusField(f func(cr CustomResource) *ResourceStatus) *UntypedResourceBuilder[CustomResource, ContextType] {
+	b.inner = b.inner.WithStatusField(f)
+	return b
+}
+
+// WithDataPropagation fills the data of the untyped Secret or ConfigMap from a source Secret or ConfigMap,
+// see ResourceBuilder.WithDataPropagation.
+func (b *UntypedResourceBuilder[CustomResource, ContextType]) WithDataPropagation(source func() client.Object, mapper PropagationKeyMapper) *UntypedResourceBuilder[CustomResource, ContextType] {
+	b.inner = b.inner.WithDataPropagation(source, mapper)
+	return b
+}
+
+// WithSourceDeletionPolicy tells what happens to the untyped resource when the source of WithDataPropagation
+// does not exist, see ResourceBuilder.WithSourceDeletionPolicy.
+func (b *UntypedResourceBuilder[CustomResource, ContextType]) WithSourceDeletionPolicy(policy SourceDeletionPolicy) *UntypedResourceBuilder[CustomResource, ContextType] {
+	b.inner = b.inner.WithSourceDeletionPolicy(policy)
+	return b
+}
+
+// WithImmutableFields protects fields of the untyped resource against external modifications,
+// see ResourceBuilder.WithImmutableFields.
+func (b *UntypedResourceBuilder[CustomResource, ContextType]) WithImmutableFields(paths ...string) *UntypedResourceBuilder[CustomResource, ContextType] {
+	b.inner = b.inner.WithImmutableFields(paths...)
+	return b
+}
+
+// WithRecreateOnImmutableFieldConflict recreates the untyped resource when an update changes a field the API server
+// holds immutable, see ResourceBuilder.WithRecreateOnImmutableFieldConflict.
+func (b *UntypedResourceBuilder[CustomResource, ContextType]) WithRecreateOnImmutableFieldConflict(recreate bool) *UntypedResourceBuilder[CustomResource, ContextType] {
+	b.inner = b.inner.WithRecreateOnImmutableFieldConflict(recreate)
+	return b
+}
+
+// WithUserIdentifierPrefix prefixes the identifier set with WithUserIdentifier, see ResourceBuilder.WithUserIdentifierPrefix.
+func (b *UntypedResourceBuilder[CustomResource, ContextType]) WithUserIdentifierPrefix(prefix string) *UntypedResourceBuilder[CustomResource, ContextType] {
+	b.inner = b.inner.WithUserIdentifierPrefix(prefix)
+	return b
+}
+
+// WithDefaultMutators sets the mutators running before the one of the untyped resource, see ResourceBuilder.WithDefaultMutators.
+func (b *UntypedResourceBuilder[CustomResource, ContextType]) WithDefaultMutators(mutators ...Mutator[client.Object]) *UntypedResourceBuilder[CustomResource, ContextType] {
+	b.inner = b.inner.WithDefaultMutators(mutators...)
+	return b
+}
+
+// WithLifecycleEvents emits events on the custom resource along the lifecycle of the untyped resource,
+// see ResourceBuilder.WithLifecycleEvents.
+func (b *UntypedResourceBuilder[CustomResource, ContextType]) WithLifecycleEvents(enabled bool) *UntypedResourceBuilder[CustomResource, ContextType] {
+	b.inner = b.inner.WithLifecycleEvents(enabled)
+	return b
+}
+
+// WithSharedOwnership declares an untyped resource shared by several custom resources,
+// see ResourceBuilder.WithSharedOwnership.
+func (b *UntypedResourceBuilder[CustomResource, ContextType]) WithSharedOwnership(shared bool) *UntypedResourceBuilder[CustomResource, ContextType] {
+	b.inner = b.inner.WithSharedOwnership(shared)
+	return b
+}
+
+// WithMutatorWithExisting is an alternative to WithMutator for mutators that need the untyped resource
+// as it exists in the cluster, see ResourceBuilder.WithMutatorWithExisting.
+func (b *UntypedResourceBuilder[CustomResource, ContextType]) WithMutatorWithExisting(f func(desired, existing *unstructured.Unstructured) error) *UntypedResourceBuilder[CustomResource, ContextType] {
+	b.inner = b.inner.WithMutatorWithExisting(f)
+	return b
+}
+
+// WithCreateMutator specifies the mutator used when the untyped resource does not exist yet,
+// see ResourceBuilder.WithCreateMutator.
+func (b *UntypedResourceBuilder[CustomResource, ContextType]) WithCreateMutator(f Mutator[*unstructured.Unstructured]) *UntypedResourceBuilder[CustomResource, ContextType] {
+	b.inner = b.inner.WithCreateMutator(f)
+	return b
+}
+
+// WithUpdateMutator specifies the mutator used when the untyped resource already exists,
+// see ResourceBuilder.WithUpdateMutator.
+func (b *UntypedResourceBuilder[CustomResource, ContextType]) WithUpdateMutator(f Mutator[*unstructured.Unstructured]) *UntypedResourceBuilder[CustomResource, ContextType] {
+	b.inner = b.inner.WithUpdateMutator(f)
+	return b
+}
+
+// WithScale reconciles the replicas of the untyped resource through its scale subresource, see ResourceBuilder.WithScale.
+func (b *UntypedResourceBuilder[CustomResource, ContextType]) WithScale(f func(ctx ContextType) int32) *UntypedResourceBuilder[CustomResource, ContextType] {
+	b.inner = b.inner.WithScale(f)
+	return b
+}
+
+// WithStatusMutator specifies a mutator for the status of the untyped resource, see ResourceBuilder.WithStatusMutator.
+func (b *UntypedResourceBuilder[CustomResource, ContextType]) WithStatusMutator(f Mutator[*unstructured.Unstructured]) *UntypedResourceBuilder[CustomResource, ContextType] {
+	b.inner = b.inner.WithStatusMutator(f)
+	return b
+}
+
+// WithAfterScale registers a hook function that executes only when the untyped resource is scaled,
+// see ResourceBuilder.WithAfterScale.
+func (b *UntypedResourceBuilder[CustomResource, ContextType]) WithAfterScale(f func(ctx ContextType, resource *unstructured.Unstructured) error) *UntypedResourceBuilder[CustomResource, ContextType] {
+	b.inner = b.inner.WithAfterScale(f)
+	return b
+}
+
+// WithAfterStatusUpdate registers a hook function that executes only when the status of the untyped resource
+// is patched, see ResourceBuilder.WithAfterStatusUpdate.
+func (b *UntypedResourceBuilder[CustomResource, ContextType]) WithAfterStatusUpdate(f func(ctx ContextType, resource *unstructured.Unstructured) error) *UntypedResourceBuilder[CustomResource, ContextType] {
+	b.inner = b.inner.WithAfterStatusUpdate(f)
+	return b
+}
+
+// WithSensitive declares an untyped resource holding sensitive data, see ResourceBuilder.WithSensitive.
+func (b *UntypedResourceBuilder[CustomResource, ContextType]) WithSensitive(sensitive bool) *UntypedResourceBuilder[CustomResource, ContextType] {
+	b.inner = b.inner.WithSensitive(sensitive)
+	return b
+}
+
+// WithClient reconciles the untyped resource with the given client, see ResourceBuilder.WithClient.
+func (b *UntypedResourceBuilder[CustomResource, ContextType]) WithClient(c client.Client) *UntypedResourceBuilder[CustomResource, ContextType] {
+	b.inner = b.inner.WithClient(c)
+	return b
+}
+
+// WithClientFunc reconciles the untyped resource with the client returned by f, see ResourceBuilder.WithClientFunc.
+func (b *UntypedResourceBuilder[CustomResource, ContextType]) WithClientFunc(f func(ctx ContextType) (client.Client, error)) *UntypedResourceBuilder[CustomResource, ContextType] {
+	b.inner = b.inner.WithClientFunc(f)
+	return b
+}
+
+// WithOwnedConditions declares the condition types belonging to the untyped resource, see ResourceBuilder.WithOwnedConditions.
+func (b *UntypedResourceBuilder[CustomResource, ContextType]) WithOwnedConditions(conditionTypes ...string) *UntypedResourceBuilder[CustomResource, ContextType] {
+	b.inner = b.inner.WithOwnedConditions(conditionTypes...)
+	return b
+}
+
+// WithEqualityFunc skips the update of the untyped resource when f reports the mutated object as equal to the live one,
+// see ResourceBuilder.WithEqualityFunc.
+func (b *UntypedResourceBuilder[CustomResource, ContextType]) WithEqualityFunc(f func(current, desired *unstructured.Unstructured) bool) *UntypedResourceBuilder[CustomResource, ContextType] {
+	b.inner = b.inner.WithEqualityFunc(f)
+	return b
+}
+
+// WithAdmissionWebhookValidation sends the mutated untyped resource to the admission webhook at webhookURL before
+// creating or updating it, see ResourceBuilder.WithAdmissionWebhookValidation.
+func (b *UntypedResourceBuilder[CustomResource, ContextType]) WithAdmissionWebhookValidation(webhookURL string) *UntypedResourceBuilder[CustomResource, ContextType] {
+	b.inner = b.inner.WithAdmissionWebhookValidation(webhookURL)
+	return b
+}
+
+// WithRollingUpdateGuard defers the updates of the untyped resource while f reports a rollout in progress,
+// see ResourceBuilder.WithRollingUpdateGuard.
+func (b *UntypedResourceBuilder[CustomResource, ContextType]) WithRollingUpdateGuard(f func(obj *unstructured.Unstructured) bool) *UntypedResourceBuilder[CustomResource, ContextType] {
+	b.inner = b.inner.WithRollingUpdateGuard(f)
+	return b
+}
+
+// WithNotReadyBackoff requeues the reconciliation with an exponential backoff while the untyped resource is not ready,
+// see ResourceBuilder.WithNotReadyBackoff.
+func (b *UntypedResourceBuilder[CustomResource, ContextType]) WithNotReadyBackoff(backoff NotReadyBackoff) *UntypedResourceBuilder[CustomResource, ContextType] {
+	b.inner = b.inner.WithNotReadyBackoff(backoff)
+	return b
+}
+
+// WithGenerationGate skips the mutation and update of the untyped resource when it was already reconciled for the current
+// generation of the custom resource, see ResourceBuilder.WithGenerationGate.
+func (b *UntypedResourceBuilder[CustomResource, ContextType]) WithGenerationGate(enabled bool) *UntypedResourceBuilder[CustomResource, ContextType] {
+	b.inner = b.inner.WithGenerationGate(enabled)
+	return b
+}
+
+// WithBackgroundReconcile reconciles the custom resource every interval once the untyped resource was reconciled,
+// see ResourceBuilder.WithBackgroundReconcile.
+func (b *UntypedResourceBuilder[CustomResource, ContextType]) WithBackgroundReconcile(interval time.Duration) *UntypedResourceBuilder[CustomResource, ContextType] {
+	b.inner = b.inner.WithBackgroundReconcile(interval)
+	return b
+}
+
+// WithSuspendBehavior defines how the untyped resource is suspended while the custom resource is,
+// see ResourceBuilder.WithSuspendBehavior.
+func (b *UntypedResourceBuilder[CustomResource, ContextType]) WithSuspendBehavior(f func(obj *unstructured.Unstructured) error) *UntypedResourceBuilder[CustomResource, ContextType] {
+	b.inner = b.inner.WithSuspendBehavior(f)
+	return b
+}
+
+// WithExternalMutationPolicy detects the modifications of the untyped resource made by other tools than the controller,
+// see ResourceBuilder.WithExternalMutationPolicy.
+func (b *UntypedResourceBuilder[CustomResource, ContextType]) WithExternalMutationPolicy(policy ExternalMutationPolicy) *UntypedResourceBuilder[CustomResource, ContextType] {
+	b.inner = b.inner.WithExternalMutationPolicy(policy)
+	return b
+}
+
+// WithPatchStrategy sends the updates of the untyped resource using strategy, see ResourceBuilder.WithPatchStrategy.
+// Strategic merge patches are not supported by custom resources.
+func (b *UntypedResourceBuilder[CustomResource, ContextType]) WithPatchStrategy(strategy PatchStrategy) *UntypedResourceBuilder[CustomResource, ContextType] {
+	b.inner = b.inner.WithPatchStrategy(strategy)
+	return b
+}
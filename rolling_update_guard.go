@@ -0,0 +1,67 @@
+package ctrlfwk
+
+import (
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// ConditionTypeRollingUpdateInProgress is set on the custom resource while the update of a resource
+	// is deferred because of a rollout in progress, its message listing every deferred resource,
+	// see ResourceBuilder.WithRollingUpdateGuard.
+	ConditionTypeRollingUpdateInProgress = "RollingUpdateInProgress"
+)
+
+// rollingUpdateGuardResource is implemented by the resources that can be built with WithRollingUpdateGuard.
+type rollingUpdateGuardResource interface {
+	// rollingUpdateGuard returns the function telling whether the live object is rolling out, nil if the resource has no guard.
+	rollingUpdateGuard() func(live client.Object) bool
+}
+
+func getRollingUpdateGuard(resource any) func(live client.Object) bool {
+	if guarded, ok := resource.(rollingUpdateGuardResource); ok {
+		return guarded.rollingUpdateGuard()
+	}
+	return nil
+}
+
+// rollingUpdateInProgressError aborts the update of a resource rolling out, the update being deferred
+// to a later reconciliation.
+type rollingUpdateInProgressError struct {
+	resourceID string
+}
+
+func (e *rollingUpdateInProgressError) Error() string {
+	return fmt.Sprintf("update of resource %s deferred, a rollout is in progress", e.resourceID)
+}
+
+// setRollingUpdateInProgressCondition reflects an update deferred because of a rollout on the custom resource status,
+// the resource being removed from the condition once it gets updated, see applyResourceCondition.
+func setRollingUpdateInProgressCondition[
+	ControllerResourceType ControllerCustomResource,
+	ContextType Context[ControllerResourceType],
+](
+	ctx ContextType,
+	reconciler Reconciler[ControllerResourceType],
+	resourceID string,
+	inProgress bool,
+) error {
+	defer LockContext(ctx)()
+
+	cr := ctx.GetCustomResource()
+
+	if !inProgress {
+		_, err := applyResourceCondition(ctx, reconciler, ConditionTypeRollingUpdateInProgress, resourceID, nil, "")
+		return err
+	}
+
+	_, err := applyResourceCondition(ctx, reconciler, ConditionTypeRollingUpdateInProgress, resourceID, &metav1.Condition{
+		Status:             metav1.ConditionTrue,
+		Reason:             "RolloutInProgress",
+		Message:            "update is deferred until the rollout completes",
+		ObservedGeneration: cr.GetGeneration(),
+	}, "")
+	return err
+}
@@ -1,14 +1,22 @@
 package ctrlfwk
 
 import (
+	"errors"
 	"fmt"
 	"reflect"
+	"slices"
+	"strings"
 
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/util/retry"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
+// ConditionTypeReady is the aggregated readiness condition of the custom resource, see SetReadyCondition.
+const ConditionTypeReady = "Ready"
+
 // SetReadyCondition is a function type that sets the Ready condition on a controller resource.
 // It uses reflection and assumes that the controller resource has a standard status field with conditions.
 // Your api MUST have a field like so:
@@ -19,28 +27,13 @@ import (
 //	}
 //
 // If your status field or conditions field is named differently, this function will not work correctly.
+//
+// Informational conditions, such as the ones of missing optional dependencies, never prevent the custom resource
+// from being ready, see IsInformationalCondition.
 func SetReadyCondition[ControllerResourceType client.Object](_ Reconciler[ControllerResourceType]) func(obj ControllerResourceType) (bool, error) {
 	return func(obj ControllerResourceType) (bool, error) {
-		// Use reflection to set the Ready condition
-		objValue := reflect.ValueOf(obj)
-		if objValue.Kind() == reflect.Ptr {
-			objValue = objValue.Elem()
-		}
-
-		statusField := objValue.FieldByName("Status")
-		if !statusField.IsValid() {
-			return false, fmt.Errorf("status field not found on controller resource")
-		}
-
-		conditionsField := statusField.FieldByName("Conditions")
-		if !conditionsField.IsValid() || conditionsField.Kind() != reflect.Slice {
-			return false, fmt.Errorf("conditions field not found or is not a slice on status")
-		}
-
-		conditions := conditionsField.Interface().([]metav1.Condition)
-
 		readyCondition := metav1.Condition{
-			Type:               "Ready",
+			Type:               ConditionTypeReady,
 			Status:             metav1.ConditionTrue,
 			Reason:             "Reconciled",
 			Message:            "The resource is ready",
@@ -48,15 +41,212 @@ func SetReadyCondition[ControllerResourceType client.Object](_ Reconciler[Contro
 			ObservedGeneration: obj.GetGeneration(),
 		}
 
-		changed := meta.SetStatusCondition(&conditions, readyCondition)
-		if !changed {
+		return SetStatusCondition(obj, readyCondition)
+	}
+}
+
+// ResourceStatus is a standard status sub-object describing a resource managed by the custom resource,
+// populated by the framework after each reconciliation, see ResourceBuilder.WithStatusField.
+type ResourceStatus struct {
+	Name        string      `json:"name,omitempty"`
+	Namespace   string      `json:"namespace,omitempty"`
+	Generation  int64       `json:"generation,omitempty"`
+	Ready       bool        `json:"ready"`
+	LastUpdated metav1.Time `json:"lastUpdated,omitempty"`
+}
+
+// DeepCopyInto copies the receiver into out, so ResourceStatus can be embedded in API types.
+func (in *ResourceStatus) DeepCopyInto(out *ResourceStatus) {
+	*out = *in
+	in.LastUpdated.DeepCopyInto(&out.LastUpdated)
+}
+
+// DeepCopy returns a copy of the receiver.
+func (in *ResourceStatus) DeepCopy() *ResourceStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ResourceStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// SetStatusCondition sets the given condition on the custom resource using reflection.
+// It returns true if the conditions were changed.
+// The same requirements as SetReadyCondition apply to the custom resource status.
+func SetStatusCondition(obj client.Object, condition metav1.Condition) (bool, error) {
+	conditionsField, err := getConditionsField(obj)
+	if err != nil {
+		return false, err
+	}
+
+	conditions := conditionsField.Interface().([]metav1.Condition)
+
+	changed := meta.SetStatusCondition(&conditions, condition)
+	if !changed {
+		return false, nil
+	}
+
+	conditionsField.Set(reflect.ValueOf(conditions))
+
+	return true, nil
+}
+
+// RemoveStatusCondition removes the condition with the given type from the custom resource using reflection.
+// It returns true if the conditions were changed.
+// The same requirements as SetReadyCondition apply to the custom resource status.
+func RemoveStatusCondition(obj client.Object, conditionType string) (bool, error) {
+	conditionsField, err := getConditionsField(obj)
+	if err != nil {
+		return false, err
+	}
+
+	conditions := conditionsField.Interface().([]metav1.Condition)
+
+	changed := meta.RemoveStatusCondition(&conditions, conditionType)
+	if !changed {
+		return false, nil
+	}
+
+	conditionsField.Set(reflect.ValueOf(conditions))
+
+	return true, nil
+}
+
+// errNoConditions is returned for the custom resources whose status has no conditions field.
+var errNoConditions = errors.New("custom resource has no status conditions")
+
+func getConditionsField(obj client.Object) (reflect.Value, error) {
+	objValue := reflect.ValueOf(obj)
+	if objValue.Kind() == reflect.Ptr {
+		objValue = objValue.Elem()
+	}
+
+	statusField := objValue.FieldByName("Status")
+	if !statusField.IsValid() {
+		return reflect.Value{}, fmt.Errorf("status field not found on controller resource: %w", errNoConditions)
+	}
+
+	conditionsField := statusField.FieldByName("Conditions")
+	if !conditionsField.IsValid() {
+		return reflect.Value{}, fmt.Errorf("conditions field not found on status: %w", errNoConditions)
+	}
+	if conditionsField.Type() != reflect.TypeOf([]metav1.Condition(nil)) {
+		return reflect.Value{}, fmt.Errorf("conditions field of status is a %s, not a []metav1.Condition", conditionsField.Type())
+	}
+
+	return conditionsField, nil
+}
+
+// getStatusCondition returns the condition of the given type of the custom resource, nil if it has none.
+func getStatusCondition(obj client.Object, conditionType string) (*metav1.Condition, error) {
+	conditionsField, err := getConditionsField(obj)
+	if errors.Is(err, errNoConditions) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return meta.FindStatusCondition(conditionsField.Interface().([]metav1.Condition), conditionType), nil
+}
+
+// applyCondition sets condition on the status of the custom resource with the given type, or removes the condition
+// of this type when condition is nil, and patches the status when it changed. It returns whether it changed.
+// The custom resources whose status has no conditions are left as is.
+//
+// The caller holds the lock of the context when the resources are reconciled concurrently, see LockContext.
+func applyCondition[
+	ControllerResourceType ControllerCustomResource,
+	ContextType Context[ControllerResourceType],
+](
+	ctx ContextType,
+	reconciler Reconciler[ControllerResourceType],
+	conditionType string,
+	condition *metav1.Condition,
+) (bool, error) {
+	cr := ctx.GetCustomResource()
+
+	var changed bool
+	var err error
+	if condition == nil {
+		changed, err = RemoveStatusCondition(cr, conditionType)
+	} else {
+		set := *condition
+		set.Type = conditionType
+		changed, err = SetStatusCondition(cr, set)
+	}
+	if errors.Is(err, errNoConditions) {
+		// Custom resources without conditions can't have the condition set
+		return false, nil
+	}
+	if err != nil || !changed {
+		return false, err
+	}
+
+	return true, PatchCustomResourceStatus(ctx, reconciler)
+}
+
+// applyResourceCondition reports the resource with the given ID on a condition shared by the resources of the custom
+// resource, so that they don't overwrite each other: its message lists the resources reporting it, one line per
+// resource sorted by ID. The line of the resource is removed when condition is nil, the condition being removed
+// along with the last line. When several resources report it, the reason of the condition is multipleReason,
+// unless empty. It returns whether the condition changed.
+//
+// The caller holds the lock of the context when the resources are reconciled concurrently, see LockContext.
+func applyResourceCondition[
+	ControllerResourceType ControllerCustomResource,
+	ContextType Context[ControllerResourceType],
+](
+	ctx ContextType,
+	reconciler Reconciler[ControllerResourceType],
+	conditionType string,
+	resourceID string,
+	condition *metav1.Condition,
+	multipleReason string,
+) (bool, error) {
+	existing, err := getStatusCondition(ctx.GetCustomResource(), conditionType)
+	if err != nil {
+		return false, err
+	}
+
+	prefix := fmt.Sprintf("resource %s: ", resourceID)
+
+	// The lines of the other resources are kept, the message of a condition set otherwise is dropped
+	var lines []string
+	var found bool
+	if existing != nil {
+		for line := range strings.SplitSeq(existing.Message, "\n") {
+			switch {
+			case strings.HasPrefix(line, prefix):
+				found = true
+			case strings.HasPrefix(line, "resource "):
+				lines = append(lines, line)
+			}
+		}
+	}
+
+	if condition == nil {
+		if !found {
 			return false, nil
 		}
+		if len(lines) == 0 {
+			return applyCondition(ctx, reconciler, conditionType, nil)
+		}
+		// The other resources still report the condition, which is left as they set it otherwise
+		set := *existing
+		set.Message = strings.Join(lines, "\n")
+		return applyCondition(ctx, reconciler, conditionType, &set)
+	}
 
-		conditionsField.Set(reflect.ValueOf(conditions))
+	lines = append(lines, prefix+strings.ReplaceAll(condition.Message, "\n", " "))
+	slices.Sort(lines)
 
-		return changed, nil
+	set := *condition
+	set.Message = strings.Join(lines, "\n")
+	if len(lines) > 1 && multipleReason != "" {
+		set.Reason = multipleReason
 	}
+	return applyCondition(ctx, reconciler, conditionType, &set)
 }
 
 // PatchCustomResourceStatus patches the status subresource of the custom resource stored in the context.
@@ -65,12 +255,26 @@ func SetReadyCondition[ControllerResourceType client.Object](_ Reconciler[Contro
 //
 // It also sets the updated custom resource back into the context after patching.
 func PatchCustomResourceStatus[CustomResourceType client.Object](ctx Context[CustomResourceType], reconciler Reconciler[CustomResourceType]) error {
-	// Get the custom resource from the context
-	cleanObject := ctx.GetCleanCustomResource()
+	// Status patches are sent all at once when the transaction is committed
+	if inStatusTransaction(ctx) {
+		return nil
+	}
+
+	return patchCustomResourceStatus(ctx, reconciler, client.MergeFrom(ctx.GetCleanCustomResource()))
+}
+
+// FlushCustomResourceStatus patches the status subresource of the custom resource stored in the context right away,
+// even when the status patches are buffered by a status transaction, see StepperBuilder.WithStatusBatching.
+// Hooks can use it when the status must be visible before the end of the reconciliation.
+func FlushCustomResourceStatus[CustomResourceType client.Object](ctx Context[CustomResourceType], reconciler Reconciler[CustomResourceType]) error {
+	return patchCustomResourceStatus(ctx, reconciler, client.MergeFrom(ctx.GetCleanCustomResource()))
+}
+
+func patchCustomResourceStatus[CustomResourceType client.Object](ctx Context[CustomResourceType], reconciler Reconciler[CustomResourceType], patch client.Patch) error {
 	modifiableObject := ctx.GetCustomResource()
 
 	// Patch the status subresource
-	err := reconciler.Status().Patch(ctx, modifiableObject, client.MergeFrom(cleanObject))
+	err := reconciler.Status().Patch(ctx, modifiableObject, patch)
 	if err != nil {
 		return err
 	}
@@ -79,3 +283,99 @@ func PatchCustomResourceStatus[CustomResourceType client.Object](ctx Context[Cus
 
 	return nil
 }
+
+// flushBatchedStatus ends the status transaction and patches the status of the custom resource if it changed.
+// The patch is sent with optimistic locking, so that on a conflict the status is applied again on the latest version
+// of the custom resource rather than overwriting the changes made in the meantime.
+func flushBatchedStatus[CustomResourceType client.Object](ctx Context[CustomResourceType], reconciler Reconciler[CustomResourceType]) error {
+	endStatusTransaction(ctx)
+
+	cr := ctx.GetCustomResource()
+	// The custom resource was not found
+	if cr.GetName() == "" {
+		return nil
+	}
+
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		patch := client.MergeFromWithOptions(ctx.GetCleanCustomResource(), client.MergeFromWithOptimisticLock{})
+		unchanged := client.MergeFrom(ctx.GetCleanCustomResource())
+		if data, err := unchanged.Data(ctx.GetCustomResource()); err == nil && string(data) == "{}" {
+			return nil
+		}
+
+		err := patchCustomResourceStatus(ctx, reconciler, patch)
+		if !apierrors.IsConflict(err) {
+			return client.IgnoreNotFound(err)
+		}
+
+		// Apply the status on the latest version of the custom resource
+		status := ctx.GetCustomResource().DeepCopyObject().(CustomResourceType)
+		latest := ctx.GetCustomResource().DeepCopyObject().(CustomResourceType)
+		if err := reconciler.Get(ctx, client.ObjectKeyFromObject(latest), latest); err != nil {
+			return client.IgnoreNotFound(err)
+		}
+		ctx.SetCustomResource(latest)
+		restoreStatus(ctx.GetCustomResource(), status)
+
+		return err
+	})
+}
+
+// rollbackBatchedStatus ends the status transaction, restoring the in-memory status of the custom resource
+// to the last status that was patched or read, see StepperBuilder.WithAtomicStatus.
+func rollbackBatchedStatus[CustomResourceType client.Object](ctx Context[CustomResourceType]) {
+	endStatusTransaction(ctx)
+
+	// The custom resource was not found
+	if ctx.GetCustomResource().GetName() == "" {
+		return
+	}
+	restoreStatus(ctx.GetCustomResource(), ctx.GetCleanCustomResource())
+}
+
+// BeginStatusTransaction starts buffering the status patches of the custom resource stored in the context.
+// Until the transaction is committed, PatchCustomResourceStatus only keeps the changes in memory,
+// this allows steps setting several conditions to issue a single PATCH request.
+//
+// You can use it as such:
+//
+//	ctrlfwk.BeginStatusTransaction(ctx)
+//	// ... steps calling PatchCustomResourceStatus
+//	if err := ctrlfwk.CommitStatusTransaction(ctx, reconciler); err != nil {
+//		return err
+//	}
+func BeginStatusTransaction[CustomResourceType client.Object](ctx Context[CustomResourceType]) {
+	if transaction, ok := ctx.(ImplementsStatusTransaction); ok {
+		transaction.BeginStatusTransaction()
+	}
+}
+
+// CommitStatusTransaction ends the status transaction and patches the status subresource of the custom resource
+// with every change made since the beginning of the transaction.
+func CommitStatusTransaction[CustomResourceType client.Object](ctx Context[CustomResourceType], reconciler Reconciler[CustomResourceType]) error {
+	endStatusTransaction(ctx)
+
+	return PatchCustomResourceStatus(ctx, reconciler)
+}
+
+// RollbackStatusTransaction ends the status transaction without patching anything,
+// the in-memory status of the custom resource is restored to its state at the beginning of the transaction.
+func RollbackStatusTransaction[CustomResourceType client.Object](ctx Context[CustomResourceType]) {
+	if transaction, ok := ctx.(ImplementsStatusTransaction); ok {
+		transaction.RollbackStatusTransaction()
+	}
+}
+
+// inStatusTransaction tells whether the status patches of the custom resource are buffered,
+// they never are when the context doesn't implement ImplementsStatusTransaction.
+func inStatusTransaction(ctx any) bool {
+	transaction, ok := ctx.(ImplementsStatusTransaction)
+	return ok && transaction.InStatusTransaction()
+}
+
+// endStatusTransaction stops buffering the status patches of the custom resource, if the context buffers them.
+func endStatusTransaction(ctx any) {
+	if transaction, ok := ctx.(ImplementsStatusTransaction); ok {
+		transaction.EndStatusTransaction()
+	}
+}
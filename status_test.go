@@ -0,0 +1,319 @@
+package ctrlfwk_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/go-logr/logr"
+	ctrlfwk "github.com/u-ctf/controller-fwk"
+	"github.com/u-ctf/controller-fwk/faultinject"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+)
+
+type ExampleStatusObject struct {
+	unstructured.Unstructured
+
+	Status struct {
+		Conditions []metav1.Condition `json:"conditions,omitempty"`
+	} `json:"status"`
+}
+
+func TestSetStatusCondition(t *testing.T) {
+	obj := &ExampleStatusObject{}
+
+	changed, err := ctrlfwk.SetStatusCondition(obj, metav1.Condition{
+		Type:   "Test",
+		Status: metav1.ConditionTrue,
+		Reason: "Testing",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !changed {
+		t.Fatal("expected conditions to be changed")
+	}
+	if !meta.IsStatusConditionTrue(obj.Status.Conditions, "Test") {
+		t.Fatal("expected condition Test to be true")
+	}
+
+	changed, err = ctrlfwk.RemoveStatusCondition(obj, "Test")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !changed {
+		t.Fatal("expected conditions to be changed")
+	}
+	if len(obj.Status.Conditions) != 0 {
+		t.Fatalf("expected no conditions, got %d", len(obj.Status.Conditions))
+	}
+}
+
+func TestSetStatusCondition_NoConditions(t *testing.T) {
+	obj := &unstructured.Unstructured{}
+
+	_, err := ctrlfwk.SetStatusCondition(obj, metav1.Condition{Type: "Test"})
+	if err == nil {
+		t.Fatal("expected an error for an object without status conditions")
+	}
+}
+
+func TestStructuredCondition_RoundTrip(t *testing.T) {
+	var conditions []metav1.Condition
+
+	message := ctrlfwk.StructuredConditionMessage{
+		Summary:   "the database can't be reached",
+		Details:   map[string]string{"host": "db.default.svc"},
+		ErrorCode: "E1001",
+	}
+	changed := ctrlfwk.SetStructuredCondition(&conditions, ctrlfwk.StructuredConditionDetails{
+		Type:    "DatabaseReady",
+		Status:  metav1.ConditionFalse,
+		Reason:  "ConnectionFailed",
+		Message: message,
+	})
+	if !changed {
+		t.Fatal("expected conditions to be changed")
+	}
+
+	condition := meta.FindStatusCondition(conditions, "DatabaseReady")
+	parsed, ok := ctrlfwk.ParseStructuredConditionMessage(condition.Message)
+	if !ok {
+		t.Fatalf("expected a structured message, got %q", condition.Message)
+	}
+	if parsed.Summary != message.Summary || parsed.ErrorCode != message.ErrorCode || parsed.Details["host"] != "db.default.svc" {
+		t.Fatalf("unexpected message %+v", parsed)
+	}
+
+	if _, ok := ctrlfwk.ParseStructuredConditionMessage("The resource is ready"); ok {
+		t.Fatal("expected free-form messages not to be parsed")
+	}
+}
+
+// contextWithoutStatusTransaction is a custom context that doesn't implement ctrlfwk.ImplementsStatusTransaction.
+type contextWithoutStatusTransaction struct {
+	conditionsContext
+}
+
+func TestStatusTransaction_OptionalForContexts(t *testing.T) {
+	ctx, reconciler := newConditionsTest(t)
+	key := types.NamespacedName{Name: "cr", Namespace: "default"}
+
+	patchCondition := func(ctx conditionsContext, conditionType string) {
+		t.Helper()
+		meta.SetStatusCondition(&ctx.GetCustomResource().Status.Conditions, metav1.Condition{Type: conditionType, Status: metav1.ConditionTrue, Reason: "Testing"})
+		if err := ctrlfwk.PatchCustomResourceStatus(ctx, reconciler); err != nil {
+			t.Fatalf("failed to patch status: %v", err)
+		}
+	}
+	patched := func(conditionType string) bool {
+		t.Helper()
+		cr := &conditionsCR{}
+		if err := reconciler.Get(ctx, key, cr); err != nil {
+			t.Fatalf("failed to get custom resource: %v", err)
+		}
+		return meta.FindStatusCondition(cr.Status.Conditions, conditionType) != nil
+	}
+
+	// The contexts of the framework buffer the patches
+	ctrlfwk.BeginStatusTransaction(ctx)
+	patchCondition(ctx, "Buffered")
+	if patched("Buffered") {
+		t.Fatal("expected the patch to be buffered by the transaction")
+	}
+	if err := ctrlfwk.CommitStatusTransaction(ctx, reconciler); err != nil {
+		t.Fatalf("failed to commit status transaction: %v", err)
+	}
+	if !patched("Buffered") {
+		t.Fatal("expected the patch to be sent on commit")
+	}
+
+	// The other contexts send them right away
+	custom := &contextWithoutStatusTransaction{conditionsContext: ctx}
+	if _, ok := any(custom).(ctrlfwk.ImplementsStatusTransaction); ok {
+		t.Fatal("expected the custom context not to implement status transactions")
+	}
+	ctrlfwk.BeginStatusTransaction[*conditionsCR](custom)
+	patchCondition(custom, "Direct")
+	if !patched("Direct") {
+		t.Fatal("expected the patch to be sent right away")
+	}
+	ctrlfwk.RollbackStatusTransaction[*conditionsCR](custom)
+}
+
+func TestStepper_StatusBatching(t *testing.T) {
+	statusPatches := 0
+	ctx, reconciler := newDeletionTest(t, interceptor.Funcs{
+		SubResourcePatch: func(ctx context.Context, c client.Client, subResourceName string, obj client.Object, patch client.Patch, opts ...client.SubResourcePatchOption) error {
+			statusPatches++
+			return nil
+		},
+	})
+
+	// ConfigMaps have no status, their data stands for it
+	setStatus := func(value string, flush bool) ctrlfwk.Step[*corev1.ConfigMap, ctrlfwk.Context[*corev1.ConfigMap]] {
+		return ctrlfwk.NewStep("set status", func(ctx ctrlfwk.Context[*corev1.ConfigMap], _ logr.Logger, _ ctrl.Request) ctrlfwk.StepResult {
+			ctx.GetCustomResource().Data = map[string]string{"phase": value}
+			patch := ctrlfwk.PatchCustomResourceStatus[*corev1.ConfigMap]
+			if flush {
+				patch = ctrlfwk.FlushCustomResourceStatus[*corev1.ConfigMap]
+			}
+			if err := patch(ctx, reconciler); err != nil {
+				return ctrlfwk.ResultInError(err)
+			}
+			return ctrlfwk.ResultSuccess()
+		})
+	}
+
+	stepper := ctrlfwk.NewStepperFor(ctx, logr.Discard()).
+		WithStep(setStatus("Pending", false)).
+		WithStep(setStatus("Progressing", false)).
+		WithStep(ctrlfwk.NewStep("check", func(ctx ctrlfwk.Context[*corev1.ConfigMap], _ logr.Logger, _ ctrl.Request) ctrlfwk.StepResult {
+			if statusPatches != 0 {
+				t.Errorf("expected the status patches to be batched, got %d", statusPatches)
+			}
+			return ctrlfwk.ResultSuccess()
+		})).
+		WithStep(setStatus("Flushed", true)).
+		WithStep(setStatus("Ready", false)).
+		WithStatusBatching(reconciler).
+		Build()
+
+	if _, err := stepper.Execute(ctx, ctrl.Request{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// One patch for the explicit flush, one at the end of the reconciliation
+	if statusPatches != 2 {
+		t.Fatalf("expected 2 status patches, got %d", statusPatches)
+	}
+}
+
+func TestStepper_StatusBatchingWithDataContext(t *testing.T) {
+	statusPatches := 0
+	cr := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "cr", Namespace: "default"}}
+	reconciler := &fakeReconciler{
+		Client: fake.NewClientBuilder().WithObjects(cr).WithInterceptorFuncs(interceptor.Funcs{
+			SubResourcePatch: func(ctx context.Context, c client.Client, subResourceName string, obj client.Object, patch client.Patch, opts ...client.SubResourcePatchOption) error {
+				statusPatches++
+				return nil
+			},
+		}).Build(),
+	}
+
+	type dataContext = *ctrlfwk.ContextWithData[*corev1.ConfigMap, struct{}]
+	ctx := ctrlfwk.NewContextWithData(context.Background(), reconciler, struct{}{})
+	ctx.SetCustomResource(cr)
+
+	// ConfigMaps have no status, their data stands for it
+	setStatus := func(value string) ctrlfwk.Step[*corev1.ConfigMap, dataContext] {
+		return ctrlfwk.NewStep("set status", func(ctx dataContext, _ logr.Logger, _ ctrl.Request) ctrlfwk.StepResult {
+			ctx.GetCustomResource().Data = map[string]string{"phase": value}
+			if err := ctrlfwk.PatchCustomResourceStatus(ctx, reconciler); err != nil {
+				return ctrlfwk.ResultInError(err)
+			}
+			if statusPatches != 0 {
+				t.Errorf("expected the status patches to be batched, got %d", statusPatches)
+			}
+			return ctrlfwk.ResultSuccess()
+		})
+	}
+
+	stepper := ctrlfwk.NewStepperFor(ctx, logr.Discard()).
+		WithStep(setStatus("Pending")).
+		WithStep(setStatus("Ready")).
+		WithStatusBatching(reconciler).
+		Build()
+
+	if _, err := stepper.Execute(ctx, ctrl.Request{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if statusPatches != 1 {
+		t.Fatalf("expected a single status patch, got %d", statusPatches)
+	}
+}
+
+func TestStepper_AtomicStatus(t *testing.T) {
+	ctx, reconciler := newConditionsTest(t)
+
+	setCondition := func(conditionType string) ctrlfwk.Step[*conditionsCR, conditionsContext] {
+		return ctrlfwk.NewStep("set "+conditionType, func(ctx conditionsContext, _ logr.Logger, _ ctrl.Request) ctrlfwk.StepResult {
+			_, _ = ctrlfwk.SetStatusCondition(ctx.GetCustomResource(), metav1.Condition{Type: conditionType, Status: metav1.ConditionTrue, Reason: "Testing"})
+			if err := ctrlfwk.PatchCustomResourceStatus(ctx, reconciler); err != nil {
+				return ctrlfwk.ResultInError(err)
+			}
+			return ctrlfwk.ResultSuccess()
+		})
+	}
+	fail := ctrlfwk.NewStep("fail", func(conditionsContext, logr.Logger, ctrl.Request) ctrlfwk.StepResult {
+		return ctrlfwk.ResultInError(errors.New("boom"))
+	})
+
+	stepper := ctrlfwk.NewStepperFor(ctx, logr.Discard()).
+		WithAtomicStatus(reconciler).
+		WithStep(setCondition("DependenciesResolved")).
+		WithStep(ctrlfwk.NewCommitStatusStep(ctx, reconciler)).
+		WithStep(setCondition("ConfigMapReady")).
+		WithStep(fail).
+		WithStep(setCondition(ctrlfwk.ConditionTypeReady)).
+		Build()
+
+	if _, err := stepper.Execute(ctx, ctrl.Request{}); err == nil {
+		t.Fatal("expected the reconciliation to fail")
+	}
+
+	live := &conditionsCR{}
+	if err := reconciler.Get(ctx, client.ObjectKeyFromObject(ctx.GetCustomResource()), live); err != nil {
+		t.Fatalf("failed to get custom resource: %v", err)
+	}
+	for _, conditions := range [][]metav1.Condition{live.Status.Conditions, ctx.GetCustomResource().Status.Conditions} {
+		if meta.FindStatusCondition(conditions, "DependenciesResolved") == nil {
+			t.Fatalf("expected the committed condition to be kept, got %v", conditions)
+		}
+		if meta.FindStatusCondition(conditions, "ConfigMapReady") != nil {
+			t.Fatalf("expected the condition set after the commit to be rolled back, got %v", conditions)
+		}
+	}
+}
+
+func TestStepper_StatusBatchingRetriesConflicts(t *testing.T) {
+	ctx, reconciler := newConditionsTest(t)
+	faulty := faultinject.NewClient(reconciler.Client, faultinject.Rule{
+		Verbs:       []string{faultinject.VerbPatch},
+		Subresource: "status",
+		Err:         faultinject.Conflict(),
+		Times:       1,
+	})
+	reconciler.Client = faulty
+
+	stepper := ctrlfwk.NewStepperFor(ctx, logr.Discard()).
+		WithStatusBatching(reconciler).
+		WithStep(ctrlfwk.NewStep("set condition", func(ctx conditionsContext, _ logr.Logger, _ ctrl.Request) ctrlfwk.StepResult {
+			_, _ = ctrlfwk.SetStatusCondition(ctx.GetCustomResource(), metav1.Condition{Type: "Reconciled", Status: metav1.ConditionTrue, Reason: "Testing"})
+			return ctrlfwk.ResultSuccess()
+		})).
+		Build()
+
+	if _, err := stepper.Execute(ctx, ctrl.Request{}); err != nil {
+		t.Fatalf("expected the conflicting status patch to be retried, got %v", err)
+	}
+	if faulty.Injected() != 1 {
+		t.Fatalf("expected the status patch to conflict once, got %d faults", faulty.Injected())
+	}
+
+	live := &conditionsCR{}
+	if err := reconciler.Get(ctx, client.ObjectKeyFromObject(ctx.GetCustomResource()), live); err != nil {
+		t.Fatalf("failed to get custom resource: %v", err)
+	}
+	if meta.FindStatusCondition(live.Status.Conditions, "Reconciled") == nil {
+		t.Fatalf("expected the status to be patched after the conflict, got %v", live.Status.Conditions)
+	}
+}
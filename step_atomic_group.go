@@ -0,0 +1,197 @@
+package ctrlfwk
+
+import (
+	stderrors "errors"
+	"fmt"
+	"slices"
+
+	"github.com/go-logr/logr"
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// ConditionTypeAtomicGroupFailed is set on the custom resource when the resources of an atomic group
+	// could not be created, its message holds the resource that caused the failure.
+	ConditionTypeAtomicGroupFailed = "AtomicGroupFailed"
+)
+
+type atomicCreation[ControllerResourceType ControllerCustomResource, ContextType Context[ControllerResourceType]] struct {
+	resource GenericResource[ControllerResourceType, ContextType]
+	obj      client.Object
+	// client is the client of the cluster of the resource, see ResourceBuilder.WithClient
+	client client.Client
+}
+
+// NewAtomicResourceGroupStep creates the missing resources of the group all at once or none of them,
+// for resources that are inconsistent when only some of them exist, such as a ServiceAccount, a Role and its RoleBinding.
+//
+// The missing resources are first created with a server-side dry-run, and only created for real if every dry-run
+// succeeded. They are created with the owner references and tracking labels NewReconcileResourceStep would set,
+// so that they are garbage collected and pruned like the other resources. If a creation fails anyway, the resources already created by the step are deleted. Either failure sets
+// the AtomicGroupFailed condition on the custom resource, naming the resource that caused it.
+//
+// Once they all exist, the resources are reconciled one after the other like with NewReconcileResourceStep.
+// As they are created by the step, their AfterCreate hooks are not called.
+func NewAtomicResourceGroupStep[
+	ControllerResourceType ControllerCustomResource,
+	ContextType Context[ControllerResourceType],
+](
+	_ ContextType,
+	reconciler Reconciler[ControllerResourceType],
+	resources ...GenericResource[ControllerResourceType, ContextType],
+) Step[ControllerResourceType, ContextType] {
+	return Step[ControllerResourceType, ContextType]{
+		Name: StepReconcileAtomicResourceGroup,
+		Step: func(ctx ContextType, logger logr.Logger, req ctrl.Request) StepResult {
+			if !IsFinalizing(ctx.GetCustomResource()) {
+				if result := createAtomicResourceGroup(ctx, reconciler, logger, resources); result.ShouldReturn() {
+					return result
+				}
+			}
+
+			for _, resource := range resources {
+				result := NewReconcileResourceStep(ctx, reconciler, resource).Step(ctx, logger, req)
+				if result.ShouldReturn() {
+					return result
+				}
+			}
+
+			return ResultSuccess()
+		},
+	}
+}
+
+func createAtomicResourceGroup[
+	ControllerResourceType ControllerCustomResource,
+	ContextType Context[ControllerResourceType],
+](
+	ctx ContextType,
+	reconciler Reconciler[ControllerResourceType],
+	logger logr.Logger,
+	resources []GenericResource[ControllerResourceType, ContextType],
+) StepResult {
+	var creations []atomicCreation[ControllerResourceType, ContextType]
+	for _, resource := range resources {
+		if err := getBuildError(resource); err != nil {
+			return ResultInError(errors.Wrapf(err, "invalid resource %s", resource.ID()))
+		}
+
+		obj, skip, err := generateResourceObject(ctx, resource)
+		if err != nil {
+			return ResultInError(errors.Wrapf(err, "failed to generate resource %s", resource.ID()))
+		}
+		if skip || obj == nil {
+			continue
+		}
+
+		c, remote, result := clientFor(ctx, reconciler, resource, resource.ID())
+		if result.ShouldReturn() {
+			return result
+		}
+		if remote && isSharedResource(resource) {
+			return ResultInError(fmt.Errorf("resource %s: shared ownership is not supported for resources of a remote cluster", resource.ID()))
+		}
+
+		// The object of the resource is shared with the reconcile step, work on a copy
+		obj = obj.DeepCopyObject().(client.Object)
+		if err := c.Get(ctx, client.ObjectKeyFromObject(obj), obj.DeepCopyObject().(client.Object)); err == nil {
+			continue
+		} else if client.IgnoreNotFound(err) != nil {
+			return ResultInError(errors.Wrapf(err, "failed to get resource %s", resource.ID()))
+		}
+
+		reserved := getReservedMetadata(obj)
+		if err := runOperation(ctx, "Mutate", resource.GetMutator(obj)); err != nil {
+			return ResultInError(&MutatorError{ResourceID: resource.ID(), Err: redactError(reconciler, resource, obj, err)})
+		}
+		if err := mergeResourceMetadata(obj, reserved, ctx); err != nil {
+			return ResultInError(errors.Wrapf(err, "failed to merge metadata of resource %s", resource.ID()))
+		}
+		// The resources are created with the owner references and tracking labels the reconcile step would set
+		if err := setResourceOwnership(ctx, reconciler, logger, resource, obj, remote); err != nil {
+			return ResultInError(errors.Wrapf(err, "failed to set ownership of resource %s", resource.ID()))
+		}
+
+		creations = append(creations, atomicCreation[ControllerResourceType, ContextType]{resource: resource, obj: obj, client: c})
+	}
+
+	if len(creations) == 0 {
+		if err := setAtomicGroupFailedCondition(ctx, reconciler, "", "", nil); err != nil {
+			return ResultInError(errors.Wrap(err, "failed to remove atomic group condition"))
+		}
+		return ResultSuccess()
+	}
+
+	for _, creation := range creations {
+		if err := creation.client.Create(ctx, creation.obj.DeepCopyObject().(client.Object), client.DryRunAll); err != nil {
+			err = redactError(reconciler, creation.resource, creation.obj, err)
+			logger.Info("Dry-run creation failed, no resource of the group is created", "resource", creation.resource.ID(), "reason", err.Error())
+			if err := setAtomicGroupFailedCondition(ctx, reconciler, "DryRunFailed", creation.resource.ID(), err); err != nil {
+				return ResultInError(errors.Wrap(err, "failed to set atomic group condition"))
+			}
+			return ResultInError(errors.Wrapf(err, "dry-run creation of resource %s failed", creation.resource.ID()))
+		}
+	}
+
+	var created []atomicCreation[ControllerResourceType, ContextType]
+	for _, creation := range creations {
+		if err := creation.client.Create(ctx, creation.obj); err != nil {
+			err = redactError(reconciler, creation.resource, creation.obj, err)
+			logger.Info("Creation failed, rolling back the resources of the group", "resource", creation.resource.ID(), "reason", err.Error())
+
+			var rollbackErrs []error
+			for _, rollback := range slices.Backward(created) {
+				if err := rollback.client.Delete(ctx, rollback.obj, rollback.resource.DeleteOptions()...); client.IgnoreNotFound(err) != nil {
+					rollbackErrs = append(rollbackErrs, fmt.Errorf("failed to roll back resource %s: %w", rollback.resource.ID(), err))
+				}
+			}
+
+			if err := setAtomicGroupFailedCondition(ctx, reconciler, "CreationFailed", creation.resource.ID(), err); err != nil {
+				return ResultInError(errors.Wrap(err, "failed to set atomic group condition"))
+			}
+			err = errors.Wrapf(err, "creation of resource %s failed", creation.resource.ID())
+			return ResultInError(stderrors.Join(append([]error{err}, rollbackErrs...)...))
+		}
+		created = append(created, creation)
+	}
+
+	logger.Info("Created the resources of the group", "count", len(created))
+	if err := setAtomicGroupFailedCondition(ctx, reconciler, "", "", nil); err != nil {
+		return ResultInError(errors.Wrap(err, "failed to remove atomic group condition"))
+	}
+
+	return ResultSuccess()
+}
+
+// setAtomicGroupFailedCondition reports the resource that prevented an atomic group from being created on
+// the custom resource status. The condition is removed once the group is created, when cause is nil.
+func setAtomicGroupFailedCondition[
+	ControllerResourceType ControllerCustomResource,
+	ContextType Context[ControllerResourceType],
+](
+	ctx ContextType,
+	reconciler Reconciler[ControllerResourceType],
+	reason string,
+	resourceID string,
+	cause error,
+) error {
+	defer LockContext(ctx)()
+
+	cr := ctx.GetCustomResource()
+
+	if cause == nil {
+		_, err := applyCondition(ctx, reconciler, ConditionTypeAtomicGroupFailed, nil)
+		return err
+	}
+
+	_, err := applyCondition(ctx, reconciler, ConditionTypeAtomicGroupFailed, &metav1.Condition{
+		Status:             metav1.ConditionTrue,
+		Reason:             reason,
+		Message:            fmt.Sprintf("resource %s: %v", resourceID, cause),
+		ObservedGeneration: cr.GetGeneration(),
+	})
+	return err
+}
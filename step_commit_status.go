@@ -0,0 +1,46 @@
+package ctrlfwk
+
+import (
+	"github.com/go-logr/logr"
+	"github.com/pkg/errors"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+// NewCommitStatusStep patches the status changes buffered so far when the status is batched,
+// see StepperBuilder.WithAtomicStatus, and keeps buffering the following ones.
+// It marks a consistent point of the reconciliation: when a later step fails, the status is only rolled back
+// to this point. It does nothing when the status is not batched.
+//
+// Example:
+//
+//	ctrlfwk.NewStepperFor(ctx, logger).
+//		WithAtomicStatus(reconciler).
+//		WithStep(ctrlfwk.NewFindControllerCustomResourceStep(ctx, reconciler)).
+//		WithStep(ctrlfwk.NewResolveDynamicDependenciesStep(ctx, reconciler)).
+//		WithStep(ctrlfwk.NewCommitStatusStep(ctx, reconciler)). // Dependency conditions are kept on failures
+//		WithStep(ctrlfwk.NewReconcileResourcesStep(ctx, reconciler)).
+//		WithStep(ctrlfwk.NewEndStep(ctx, reconciler, ctrlfwk.SetReadyCondition(reconciler))).
+//		Build()
+func NewCommitStatusStep[
+	ControllerResourceType ControllerCustomResource,
+	ContextType Context[ControllerResourceType],
+](
+	_ ContextType,
+	reconciler Reconciler[ControllerResourceType],
+) Step[ControllerResourceType, ContextType] {
+	return Step[ControllerResourceType, ContextType]{
+		Name: StepCommitStatus,
+		Step: func(ctx ContextType, logger logr.Logger, req ctrl.Request) StepResult {
+			if !inStatusTransaction(ctx) {
+				return ResultSuccess()
+			}
+
+			if err := flushBatchedStatus(ctx, reconciler); err != nil {
+				return ResultInError(errors.Wrap(err, "failed to commit custom resource status"))
+			}
+			BeginStatusTransaction(ctx)
+
+			return ResultSuccess()
+		},
+	}
+}
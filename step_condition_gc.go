@@ -0,0 +1,196 @@
+package ctrlfwk
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/go-logr/logr"
+	"github.com/pkg/errors"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// AnnotationOwnedConditions is set on custom resources by the condition cleanup step, it lists the condition types
+	// owned by the resources declared on the last reconciliation, see ResourceBuilder.WithOwnedConditions.
+	AnnotationOwnedConditions = "ctrlfwk.com/owned-conditions"
+)
+
+// ConditionCleanupConfig configures the condition cleanup step.
+type ConditionCleanupConfig struct {
+	// Keep lists condition types that are never removed, even once the resource owning them is not declared anymore.
+	Keep []string
+}
+
+// conditionOwnerResource is implemented by the resources that can be built with WithOwnedConditions.
+type conditionOwnerResource interface {
+	ownedConditionTypes() []string
+}
+
+// NewConditionCleanupStep removes the conditions of the custom resource owned by resources that are not declared anymore,
+// for example after a feature was removed or a resource renamed. Resources declare the conditions they own,
+// typically written by their hooks, with ResourceBuilder.WithOwnedConditions.
+//
+// The owned condition types are recorded in the ctrlfwk.com/owned-conditions annotation of the custom resource,
+// so that they are recognized once their resource is gone. Other conditions, such as the ones managed by the user
+// or by the framework steps, are left untouched. Like the resources that are skipped, the ones of a reconciliation
+// returning early are not considered declared, so the step should be placed at the end of the reconciliation.
+//
+// The status is only patched when a condition was removed.
+func NewConditionCleanupStep[
+	ControllerResourceType ControllerCustomResource,
+	ContextType Context[ControllerResourceType],
+](
+	_ ContextType,
+	reconciler ReconcilerWithResources[ControllerResourceType, ContextType],
+	config ConditionCleanupConfig,
+) Step[ControllerResourceType, ContextType] {
+	return Step[ControllerResourceType, ContextType]{
+		Name: StepConditionCleanup,
+		Step: func(ctx ContextType, logger logr.Logger, req ctrl.Request) StepResult {
+			if IsFinalizing(ctx.GetCustomResource()) {
+				return ResultSuccess()
+			}
+
+			owned, err := getOwnedConditionTypes(ctx, reconciler, req)
+			if err != nil {
+				return ResultInError(errors.Wrap(err, "failed to get owned condition types"))
+			}
+
+			removed, err := removeStaleConditions(ctx, reconciler, owned, config.Keep)
+			if err != nil {
+				return ResultInError(errors.Wrap(err, "failed to remove stale conditions"))
+			}
+			if len(removed) > 0 {
+				logger.Info("Removed conditions of resources that are not declared anymore", "conditions", removed)
+			}
+
+			if err := setOwnedConditionTypes(ctx, reconciler, owned); err != nil {
+				return ResultInError(errors.Wrap(err, "failed to record owned condition types"))
+			}
+
+			return ResultSuccess()
+		},
+	}
+}
+
+// getOwnedConditionTypes returns the sorted condition types owned by the resources currently declared by the reconciler.
+// Resources that are skipped are not considered declared.
+func getOwnedConditionTypes[
+	ControllerResourceType ControllerCustomResource,
+	ContextType Context[ControllerResourceType],
+](
+	ctx ContextType,
+	reconciler ReconcilerWithResources[ControllerResourceType, ContextType],
+	req ctrl.Request,
+) ([]string, error) {
+	resources, err := reconciler.GetResources(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	var owned []string
+	for _, resource := range resources {
+		owner, ok := resource.(conditionOwnerResource)
+		if !ok || len(owner.ownedConditionTypes()) == 0 {
+			continue
+		}
+
+		obj, skip, err := generateResourceObject(ctx, resource)
+		if err != nil {
+			return nil, err
+		}
+		if skip || obj == nil {
+			continue
+		}
+
+		owned = append(owned, owner.ownedConditionTypes()...)
+	}
+
+	slices.Sort(owned)
+	return slices.Compact(owned), nil
+}
+
+// removeStaleConditions removes the conditions recorded as owned on the custom resource that are not owned anymore,
+// and returns their types.
+func removeStaleConditions[
+	ControllerResourceType ControllerCustomResource,
+	ContextType Context[ControllerResourceType],
+](
+	ctx ContextType,
+	reconciler Reconciler[ControllerResourceType],
+	owned []string,
+	keep []string,
+) ([]string, error) {
+	defer LockContext(ctx)()
+
+	cr := ctx.GetCustomResource()
+
+	var removed []string
+	for _, conditionType := range getRecordedConditionTypes(cr) {
+		if slices.Contains(owned, conditionType) || slices.Contains(keep, conditionType) {
+			continue
+		}
+
+		changed, err := RemoveStatusCondition(cr, conditionType)
+		if errors.Is(err, errNoConditions) {
+			// Custom resources without conditions can't have conditions to remove
+			return nil, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if changed {
+			removed = append(removed, conditionType)
+		}
+	}
+
+	if len(removed) > 0 {
+		if err := PatchCustomResourceStatus(ctx, reconciler); err != nil {
+			return nil, err
+		}
+	}
+
+	return removed, nil
+}
+
+// setOwnedConditionTypes records on the custom resource the condition types owned by the declared resources.
+func setOwnedConditionTypes[
+	ControllerResourceType ControllerCustomResource,
+	ContextType Context[ControllerResourceType],
+](
+	ctx ContextType,
+	reconciler Reconciler[ControllerResourceType],
+	owned []string,
+) error {
+	defer LockContext(ctx)()
+
+	value := strings.Join(owned, ",")
+
+	cr := ctx.GetCustomResource()
+	if GetAnnotation(cr, AnnotationOwnedConditions) == value {
+		return nil
+	}
+
+	// Patch from the clean object so that pending changes of the custom resource are not sent along
+	cleanObject := ctx.GetCleanCustomResource()
+	modifiedObject := cleanObject.DeepCopyObject().(ControllerResourceType)
+	setOrRemoveAnnotation(modifiedObject, AnnotationOwnedConditions, value)
+
+	if err := reconciler.Patch(ctx, modifiedObject, client.MergeFrom(cleanObject)); err != nil {
+		return err
+	}
+
+	setOrRemoveAnnotation(cr, AnnotationOwnedConditions, value)
+
+	return nil
+}
+
+// getRecordedConditionTypes returns the condition types recorded on the custom resource by the condition cleanup step.
+func getRecordedConditionTypes(obj client.Object) []string {
+	value := GetAnnotation(obj, AnnotationOwnedConditions)
+	if value == "" {
+		return nil
+	}
+	return strings.Split(value, ",")
+}
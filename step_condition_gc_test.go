@@ -0,0 +1,120 @@
+package ctrlfwk_test
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/go-logr/logr"
+	ctrlfwk "github.com/u-ctf/controller-fwk"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+// conditionsCR is a custom resource with a status holding conditions.
+type conditionsCR struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Status struct {
+		Conditions []metav1.Condition `json:"conditions,omitempty"`
+		Phase      string             `json:"phase,omitempty"`
+	} `json:"status,omitempty"`
+}
+
+func (in *conditionsCR) DeepCopyObject() runtime.Object {
+	out := *in
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	out.Status.Conditions = slices.Clone(in.Status.Conditions)
+	return &out
+}
+
+type conditionsContext = ctrlfwk.Context[*conditionsCR]
+
+type conditionsReconciler struct {
+	client.Client
+	resources []ctrlfwk.GenericResource[*conditionsCR, conditionsContext]
+}
+
+func (*conditionsReconciler) For(*conditionsCR) {}
+
+func (r *conditionsReconciler) GetResources(conditionsContext, ctrl.Request) ([]ctrlfwk.GenericResource[*conditionsCR, conditionsContext], error) {
+	return r.resources, nil
+}
+
+func newConditionsTest(t *testing.T, conditions ...metav1.Condition) (conditionsContext, *conditionsReconciler) {
+	t.Helper()
+
+	scheme := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(scheme); err != nil {
+		t.Fatalf("failed to build scheme: %v", err)
+	}
+	scheme.AddKnownTypes(schema.GroupVersion{Group: "test.ctrlfwk.com", Version: "v1"}, &conditionsCR{})
+
+	cr := &conditionsCR{ObjectMeta: metav1.ObjectMeta{Name: "cr", Namespace: "default"}}
+	cr.Status.Conditions = conditions
+
+	reconciler := &conditionsReconciler{
+		Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(cr).WithStatusSubresource(cr).Build(),
+	}
+
+	ctx := ctrlfwk.NewContext(context.Background(), reconciler)
+	ctx.SetCustomResource(cr)
+
+	return ctx, reconciler
+}
+
+func TestConditionCleanupStep_RemovesConditionsOfUndeclaredResources(t *testing.T) {
+	condition := func(conditionType string) metav1.Condition {
+		return metav1.Condition{Type: conditionType, Status: metav1.ConditionTrue, Reason: "Testing", LastTransitionTime: metav1.Now()}
+	}
+	ctx, reconciler := newConditionsTest(t, condition("CacheReady"), condition("LegacyReady"), condition("Pinned"), condition("UserManaged"))
+
+	resource := func(name string, conditionTypes ...string) ctrlfwk.GenericResource[*conditionsCR, conditionsContext] {
+		return ctrlfwk.NewResourceBuilder(ctx, &corev1.ConfigMap{}).
+			WithKey(types.NamespacedName{Name: name, Namespace: "default"}).
+			WithOwnedConditions(conditionTypes...).
+			Build()
+	}
+	step := ctrlfwk.NewConditionCleanupStep(ctx, reconciler, ctrlfwk.ConditionCleanupConfig{Keep: []string{"Pinned"}})
+
+	// The first reconciliation records the conditions owned by the declared resources
+	reconciler.resources = append(reconciler.resources, resource("cache", "CacheReady"), resource("legacy", "LegacyReady", "Pinned"))
+	if result := step.Step(ctx, logr.Discard(), ctrl.Request{}); result.ShouldReturn() {
+		t.Fatalf("unexpected result: %v", result)
+	}
+	if len(ctx.GetCustomResource().Status.Conditions) != 4 {
+		t.Fatalf("expected the conditions of declared resources to be kept, got %v", ctx.GetCustomResource().Status.Conditions)
+	}
+
+	// The legacy resource is not declared anymore
+	reconciler.resources = reconciler.resources[:1]
+	if result := step.Step(ctx, logr.Discard(), ctrl.Request{}); result.ShouldReturn() {
+		t.Fatalf("unexpected result: %v", result)
+	}
+
+	live := &conditionsCR{}
+	if err := reconciler.Get(ctx, client.ObjectKeyFromObject(ctx.GetCustomResource()), live); err != nil {
+		t.Fatalf("failed to get custom resource: %v", err)
+	}
+	for _, conditionType := range []string{"CacheReady", "Pinned", "UserManaged"} {
+		if meta.FindStatusCondition(live.Status.Conditions, conditionType) == nil {
+			t.Fatalf("expected condition %s to be kept, got %v", conditionType, live.Status.Conditions)
+		}
+	}
+	if meta.FindStatusCondition(live.Status.Conditions, "LegacyReady") != nil {
+		t.Fatalf("expected condition LegacyReady to be removed, got %v", live.Status.Conditions)
+	}
+	if got := live.Annotations[ctrlfwk.AnnotationOwnedConditions]; got != "CacheReady" {
+		t.Fatalf("expected the owned conditions to be recorded, got %q", got)
+	}
+}
@@ -1,9 +1,14 @@
 package ctrlfwk
 
 import (
+	stderrors "errors"
+	"fmt"
+	"strings"
+
 	"github.com/go-logr/logr"
 	"github.com/pkg/errors"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	ctrl "sigs.k8s.io/controller-runtime"
 )
 
@@ -23,6 +28,7 @@ func NewResolveDynamicDependenciesStep[
 			}
 
 			var returnResults []StepResult
+			var unsupportedErrors []error
 
 			// Add the finalizer to clean up "managed by" references
 			// in dependencies when the CR is deleted
@@ -32,12 +38,25 @@ func NewResolveDynamicDependenciesStep[
 				return result.FromSubStep()
 			}
 
+			// Dependencies are tracked again while being resolved, the ones that are not declared anymore are dropped
+			// once every dependency is resolved
+			if reconcilerWithWatcher, ok := reconciler.(ReconcilerWithWatcher[ControllerResourceType]); ok {
+				defer reconcilerWithWatcher.RetrackDependent(req.NamespacedName)()
+			}
+
 			for _, dependency := range dependencies {
 				subStepLogger := logger.WithValues("dependency", dependency.ID())
 
 				subStep := NewResolveDependencyStep(ctx, reconciler, dependency)
-				result := subStep.Step(ctx, subStepLogger, req)
+				result := subStep.Step(ctx, logger, req)
 				if result.ShouldReturn() {
+					var unsupportedErr *DependencyVersionUnsupportedError
+					if stderrors.As(result.err, &unsupportedErr) {
+						subStepLogger.Info("Dependency API version is not served, skipping it", "constraint", unsupportedErr.Constraint)
+						unsupportedErrors = append(unsupportedErrors, unsupportedErr)
+						continue
+					}
+
 					subStepLogger.Info("Dependency resolution resulted in early return or error")
 					returnResults = append(returnResults, result)
 					continue
@@ -45,6 +64,10 @@ func NewResolveDynamicDependenciesStep[
 				subStepLogger.Info("Resolved dependency successfully")
 			}
 
+			if err := setDependencyVersionUnsupportedCondition(ctx, reconciler, unsupportedErrors); err != nil {
+				logger.Error(err, "Failed to update dependency version condition")
+			}
+
 			// Return result errors first
 			for _, result := range returnResults {
 				if result.err != nil {
@@ -72,3 +95,34 @@ func NewResolveDynamicDependenciesStep[
 		},
 	}
 }
+
+// setDependencyVersionUnsupportedCondition reflects the dependencies skipped because of their API version
+// constraint on the custom resource status. The condition is removed once every dependency is served.
+func setDependencyVersionUnsupportedCondition[
+	ControllerResourceType ControllerCustomResource,
+	ContextType Context[ControllerResourceType],
+](
+	ctx ContextType,
+	reconciler Reconciler[ControllerResourceType],
+	unsupportedErrors []error,
+) error {
+	cr := ctx.GetCustomResource()
+
+	if len(unsupportedErrors) == 0 {
+		_, err := applyCondition(ctx, reconciler, ConditionTypeDependencyVersionUnsupported, nil)
+		return err
+	}
+
+	messages := make([]string, 0, len(unsupportedErrors))
+	for _, unsupportedErr := range unsupportedErrors {
+		messages = append(messages, unsupportedErr.Error())
+	}
+
+	_, err := applyCondition(ctx, reconciler, ConditionTypeDependencyVersionUnsupported, &metav1.Condition{
+		Status:             metav1.ConditionTrue,
+		Reason:             "VersionNotServed",
+		Message:            fmt.Sprintf("%d dependency(ies) skipped: %s", len(unsupportedErrors), strings.Join(messages, "; ")),
+		ObservedGeneration: cr.GetGeneration(),
+	})
+	return err
+}
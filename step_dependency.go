@@ -1,12 +1,21 @@
 package ctrlfwk
 
 import (
+	stderrors "errors"
 	"fmt"
 	"time"
 
 	"github.com/go-logr/logr"
 	"github.com/pkg/errors"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
 
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
@@ -22,33 +31,165 @@ func NewResolveDependencyStep[
 	return Step[ControllerResourceType, ContextType]{
 		Name: fmt.Sprintf(StepResolveDependency, dependency.Kind()),
 		Step: func(ctx ContextType, logger logr.Logger, req ctrl.Request) StepResult {
+			span, restoreSpan := startSpan(ctx, "ResolveDependency", attribute.String("ctrlfwk.dependency", dependency.ID()))
+			defer restoreSpan()
+
+			_, restoreLogger := scopeLogger(ctx, "dependency", dependency.ID())
+			defer restoreLogger()
+
+			// Dependencies failing to resolve are not resolved again while their circuit is open
+			breaker := getCircuitBreaker(dependency)
+			circuitKey := newResourceStateKey(ctx.GetCustomResource(), dependency.ID())
+			if breaker != nil && !IsFinalizing(ctx.GetCustomResource()) {
+				if openFor := breaker.open(circuitKey, time.Now()); openFor > 0 {
+					logger.Info("Circuit of the dependency is open, skipping its resolution", "for", openFor)
+					span.SetAttributes(attribute.Bool("ctrlfwk.dependency.circuit_open", true))
+					span.End()
+					return ResultRequeueIn(openFor).WithRequeueReason(RequeueReasonDependencyCircuitOpen)
+				}
+			}
+
 			var dep client.Object
+			var resolved bool
+			outcome := DependencyOutcomeFound
+			startedAt := time.Now()
+
+			defer func() {
+				dependencyResolutionTotal.WithLabelValues(dependency.ID(), string(outcome)).Inc()
+				dependencyResolutionDuration.WithLabelValues(dependency.ID(), string(outcome)).Observe(time.Since(startedAt).Seconds())
+				span.SetAttributes(attribute.String("ctrlfwk.dependency.outcome", string(outcome)))
+				span.End()
+
+				ctx.GetReconcileReport().recordDependency(DependencyReport{
+					ID:       dependency.ID(),
+					Found:    outcome == DependencyOutcomeFound || outcome == DependencyOutcomeNotReady,
+					Ready:    outcome == DependencyOutcomeFound,
+					Optional: dependency.IsOptional(),
+					State:    dependencyStateFromOutcome(outcome, dependency.IsOptional()),
+				})
+			}()
 
 			funcResult := func() StepResult {
-				if err := dependency.BeforeReconcile(ctx); err != nil {
-					return ResultInError(errors.Wrap(err, "failed to run BeforeReconcile hook"))
+				if err := runOperation(ctx, "BeforeReconcile", func() error { return dependency.BeforeReconcile(ctx) }); err != nil {
+					return ResultInError(&HookError{ResourceID: dependency.ID(), Hook: "BeforeReconcile", Err: err})
 				}
 
 				cr := ctx.GetCustomResource()
 
+				// Dependencies built with WithClient are resolved in another cluster
+				c, remote, clientResult := clientFor(ctx, reconciler, dependency, dependency.ID())
+				if clientResult.ShouldReturn() {
+					return clientResult
+				}
+
+				// Untyped dependencies built with GVK candidates use the first version served by the cluster
+				negotiator, negotiates := dependency.(gvkNegotiator)
+				if negotiates {
+					if _, err := negotiateObjectGVK(ctx, reconciler, c.RESTMapper(), dependency.ID(), negotiator); err != nil {
+						return ResultInError(errors.Wrap(err, "failed to negotiate dependency version"))
+					}
+				}
+
 				depKey := dependency.Key()
 				dep = dependency.New()
+				fallbacks := getFallbackNamespaces(dependency)
+
+				if constraint := dependency.APIVersionConstraint(); constraint != "" {
+					groupVersion, err := schema.ParseGroupVersion(constraint)
+					if err != nil {
+						return ResultInError(errors.Wrapf(err, "invalid API version constraint %q", constraint))
+					}
+
+					gvk, err := getObjectGVK(dep, reconciler.Scheme())
+					if err != nil {
+						return ResultInError(errors.Wrap(err, "failed to get GVK for dependency"))
+					}
+
+					supported, err := isAPIVersionSupported(c.RESTMapper(), gvk.Kind, groupVersion)
+					if err != nil {
+						return ResultInError(errors.Wrap(err, "failed to check API version constraint"))
+					}
+					if !supported {
+						return ResultInError(&DependencyVersionUnsupportedError{
+							DependencyID: dependency.ID(),
+							Constraint:   constraint,
+						})
+					}
+				}
+
+				// Setup watch if we can, before getting the dependency so that
+				// we get notified when a missing dependency gets created.
+				// The watches of the reconciler only cover its own cluster.
+				reconcilerWithWatcher, hasWatcher := reconciler.(ReconcilerWithWatcher[ControllerResourceType])
+				if hasWatcher && !remote && dependency.ShouldAddManagedByAnnotation() {
+					if IsFinalizing(cr) {
+						reconcilerWithWatcher.UntrackDependent(client.ObjectKeyFromObject(cr))
+					} else {
+						gvk, err := getObjectGVK(dep, reconciler.Scheme())
+						if err != nil {
+							return ResultInError(errors.Wrap(err, "failed to get GVK for dependency"))
+						}
 
-				err := reconciler.Get(ctx, depKey, dep)
+						result := SetupWatch(reconcilerWithWatcher, dep, true)(ctx, req)
+						if result.ShouldReturn() {
+							return result.FromSubStep()
+						}
+
+						reconcilerWithWatcher.TrackDependency(gvk, depKey, client.ObjectKeyFromObject(cr))
+						for _, namespace := range fallbacks {
+							fallbackKey := types.NamespacedName{Name: depKey.Name, Namespace: namespace}
+							reconcilerWithWatcher.TrackDependency(gvk, fallbackKey, client.ObjectKeyFromObject(cr))
+						}
+					}
+				}
+
+				// The dependencies of the cluster of the reconciler are read once per reconciliation, see ObjectCache
+				getDependency := func(key types.NamespacedName, obj client.Object) error {
+					if remote {
+						return c.Get(ctx, key, obj)
+					}
+					cache, cacheKey := objectCacheKeyFor(reconciler, cr, obj)
+					cacheKey.NamespacedName = key
+					return getThroughObjectCache(ctx, c, cache, cacheKey, obj)
+				}
+
+				err := getDependency(depKey, dep)
+				if negotiates && meta.IsNoMatchError(err) {
+					// The negotiated version is not served anymore, negotiate again on the next reconciliation
+					InvalidateGVKNegotiation(c.RESTMapper(), negotiator.gvkCandidates()...)
+				}
+
+				// The fallback namespaces are searched in order, the first object found is used
+				foundIn := ""
+				for _, namespace := range fallbacks {
+					if !apierrors.IsNotFound(err) {
+						break
+					}
+					dep = dependency.New()
+					err = getDependency(types.NamespacedName{Name: depKey.Name, Namespace: namespace}, dep)
+					if err == nil {
+						foundIn = namespace
+					}
+				}
 				if err != nil {
 					if client.IgnoreNotFound(err) != nil {
 						return ResultInError(errors.Wrap(err, "failed to get dependency resource"))
 					}
 
 					if IsFinalizing(cr) {
+						outcome = DependencyOutcomeNotFound
 						return ResultSuccess()
 					}
 
-					return ResultRequeueIn(30 * time.Second)
+					gvk, _ := getObjectGVK(dep, reconciler.Scheme())
+					return ResultInError(&DependencyNotFoundError{ID: dependency.ID(), Key: depKey, GVK: gvk, Err: err})
 				}
 				cleanDep := dep.DeepCopyObject().(client.Object)
 
-				dependency.Set(dep)
+				if err := dependency.Set(dep); err != nil {
+					return ResultInError(err)
+				}
+				resolved = !IsFinalizing(cr)
 
 				if IsFinalizing(cr) {
 					changed, err := RemoveManagedBy(dep, cr, reconciler.Scheme())
@@ -56,47 +197,213 @@ func NewResolveDependencyStep[
 						return ResultInError(err)
 					}
 					if changed {
-						if err := reconciler.Patch(ctx, dep, client.MergeFrom(cleanDep)); err != nil {
+						if err := c.Patch(ctx, dep, client.MergeFrom(cleanDep)); err != nil {
 							return ResultInError(err)
 						}
+						if cache, cacheKey := objectCacheKeyFor(reconciler, cr, dep); cache != nil {
+							cache.Invalidate(cacheKey)
+						}
 					}
 
 					return ResultSuccess()
 				}
 
-				if dependency.ShouldAddManagedByAnnotation() {
-					// Setup watch if we can
-					reconcilerWithWatcher, ok := reconciler.(ReconcilerWithWatcher[ControllerResourceType])
-					if ok {
-						result := SetupWatch(reconcilerWithWatcher, dep, true)(ctx, req)
-						if result.ShouldReturn() {
-							return result.FromSubStep()
-						}
+				if len(fallbacks) > 0 {
+					if err := setDependencyFoundInFallbackCondition(ctx, reconciler, dependency.ID(), depKey.Namespace, foundIn); err != nil {
+						return ResultInError(errors.Wrap(err, "failed to update dependency fallback condition"))
 					}
+				}
 
+				if dependency.ShouldAddManagedByAnnotation() {
 					changed, err := AddManagedBy(dep, cr, reconciler.Scheme())
 					if err != nil {
 						return ResultInError(err)
 					}
 					if changed {
-						if err := reconciler.Patch(ctx, dep, client.MergeFrom(cleanDep)); err != nil {
+						if err := c.Patch(ctx, dep, client.MergeFrom(cleanDep)); err != nil {
 							return ResultInError(err)
 						}
+						if cache, cacheKey := objectCacheKeyFor(reconciler, cr, dep); cache != nil && !remote {
+							cache.Put(cacheKey, dep)
+						}
 					}
 				}
 
 				if dependency.ShouldWaitForReady() && !dependency.IsReady() {
-					return ResultRequeueIn(30 * time.Second)
+					return ResultInError(&DependencyNotReadyError{ID: dependency.ID()})
+				}
+
+				// Readiness is re-evaluated once it expires, even if no event is received for the dependency
+				if expiring, ok := dependency.(readinessExpiringDependency); ok && expiring.readinessExpiry() > 0 {
+					ctx.RequeueAfterWithReason(expiring.readinessExpiry(), RequeueReasonReadinessExpired)
 				}
 
 				return ResultSuccess()
 			}()
 
-			if err := dependency.AfterReconcile(ctx, dep); err != nil {
-				return ResultInError(errors.Wrap(err, "failed to run AfterReconcile hook"))
+			if err := runOperation(ctx, "AfterReconcile", func() error { return dependency.AfterReconcile(ctx, dep) }); err != nil {
+				funcResult = ResultInError(&HookError{ResourceID: dependency.ID(), Hook: "AfterReconcile", Err: err})
+			}
+
+			if transforming, ok := dependency.(transformingDependency); ok && resolved && funcResult.err == nil {
+				if err := transforming.transform(); err != nil {
+					funcResult = ResultInError(&HookError{ResourceID: dependency.ID(), Hook: "Transform", Err: err})
+				}
+			}
+
+			// Missing dependencies are checked again with an exponential backoff, reset once they are found
+			backoffKey := newResourceStateKey(ctx.GetCustomResource(), dependency.ID())
+			var nextCheck time.Duration
+			switch {
+			case funcResult.err == nil:
+				resetDependencyBackoff(backoffKey)
+			case stderrors.Is(funcResult.err, ErrDependencyNotFound):
+				nextCheck = getDependencyBackoff(dependency).next(backoffKey, time.Now())
+			}
+
+			var circuitOpenFor time.Duration
+			if breaker != nil && !IsFinalizing(ctx.GetCustomResource()) {
+				var failures int
+				if funcResult.err == nil {
+					closeCircuit(circuitKey)
+				} else {
+					failures, circuitOpenFor = breaker.recordFailure(circuitKey, time.Now())
+				}
+				if err := setDependencyCircuitOpenCondition(ctx, reconciler, dependency.ID(), failures, circuitOpenFor); err != nil && funcResult.err == nil {
+					funcResult = ResultInError(errors.Wrap(err, "failed to update circuit open condition"))
+				}
+			}
+
+			if err := reportDependencyCondition(ctx, reconciler, dependency, funcResult.err, nextCheck); err != nil && funcResult.err == nil {
+				funcResult = ResultInError(errors.Wrap(err, "failed to report dependency condition"))
+			}
+
+			if funcResult.err == nil || stderrors.Is(funcResult.err, ErrDependencyNotFound) || stderrors.Is(funcResult.err, ErrDependencyNotReady) {
+				missing := stderrors.Is(funcResult.err, ErrDependencyNotFound)
+				if err := setOptionalDependencyCondition(ctx, reconciler, dependency, missing); err != nil && funcResult.err == nil {
+					funcResult = ResultInError(errors.Wrap(err, "failed to update optional dependency condition"))
+				}
+			}
+
+			if funcResult.err == nil {
+				return funcResult
+			}
+
+			switch {
+			case stderrors.Is(funcResult.err, ErrDependencyNotFound):
+				outcome = DependencyOutcomeNotFound
+			case stderrors.Is(funcResult.err, ErrDependencyNotReady):
+				outcome = DependencyOutcomeNotReady
+			default:
+				outcome = DependencyOutcomeError
+				span.RecordError(funcResult.err)
+				span.SetStatus(codes.Error, funcResult.err.Error())
+			}
+
+			// Optional dependencies don't hold the reconciliation, later steps can check them with ctx.DependencyState
+			if dependency.IsOptional() && (outcome == DependencyOutcomeNotFound || outcome == DependencyOutcomeNotReady) {
+				logger.Info("Optional dependency is not available, skipping it", "reason", funcResult.err.Error())
+				return ResultSuccess()
+			}
+
+			if circuitOpenFor > 0 {
+				logger.Info("Dependency keeps failing to resolve, opening its circuit", "reason", funcResult.err.Error(), "for", circuitOpenFor)
+				return ResultRequeueIn(circuitOpenFor).WithRequeueReason(RequeueReasonDependencyCircuitOpen)
+			}
+
+			if nextCheck > 0 {
+				logger.Info("Dependency not found, checking it again later", "reason", funcResult.err.Error(), "after", nextCheck)
+				return ResultRequeueIn(nextCheck).WithRequeueReason(RequeueReasonDependencyNotFound)
 			}
 
-			return funcResult
+			return resultFromError(logger, funcResult.err)
 		},
 	}
 }
+
+// readinessExpiringDependency is implemented by the dependencies that can be built with WithReadinessTTL.
+type readinessExpiringDependency interface {
+	readinessExpiry() time.Duration
+}
+
+// transformingDependency is implemented by the dependencies that can be built with WithTransform.
+type transformingDependency interface {
+	transform() error
+}
+
+// conditionReportingDependency is implemented by the dependencies that can be built with WithConditionReporting.
+type conditionReportingDependency interface {
+	reportedConditionType() string
+}
+
+// reportDependencyCondition reflects the outcome of the resolution of the dependency on its condition, if any,
+// nextCheck telling when a missing dependency is checked again.
+// The condition is left as is when the resolution failed for another reason than the dependency missing or not being ready.
+func reportDependencyCondition[
+	ControllerResourceType ControllerCustomResource,
+	ContextType Context[ControllerResourceType],
+](
+	ctx ContextType,
+	reconciler Reconciler[ControllerResourceType],
+	dependency GenericDependency[ControllerResourceType, ContextType],
+	resolutionErr error,
+	nextCheck time.Duration,
+) error {
+	reporting, ok := dependency.(conditionReportingDependency)
+	if !ok || reporting.reportedConditionType() == "" {
+		return nil
+	}
+
+	defer LockContext(ctx)()
+
+	cr := ctx.GetCustomResource()
+	if cr.GetName() == "" || IsFinalizing(cr) {
+		return nil
+	}
+
+	conditionType := reporting.reportedConditionType()
+	kind := dependencyKindName(dependency)
+
+	var reason, message, eventType string
+	var condition *metav1.Condition
+
+	switch {
+	case resolutionErr == nil:
+		reason, message, eventType = kind+"Found", fmt.Sprintf("dependency %s was found", dependency.ID()), "Normal"
+	case stderrors.Is(resolutionErr, ErrDependencyNotFound):
+		reason, message, eventType = kind+"NotFound", fmt.Sprintf("dependency %s was not found", dependency.ID()), "Warning"
+		if nextCheck > 0 {
+			message = fmt.Sprintf("%s, next check in ~%s", message, approximateDuration(nextCheck))
+		}
+	case stderrors.Is(resolutionErr, ErrDependencyNotReady):
+		reason, message, eventType = kind+"NotReady", fmt.Sprintf("dependency %s is not ready", dependency.ID()), "Warning"
+	default:
+		return nil
+	}
+	if resolutionErr != nil {
+		condition = &metav1.Condition{
+			Status:             metav1.ConditionFalse,
+			Reason:             reason,
+			Message:            message,
+			ObservedGeneration: cr.GetGeneration(),
+		}
+	}
+
+	changed, err := applyCondition(ctx, reconciler, conditionType, condition)
+	if changed {
+		if recorder, ok := reconciler.(record.EventRecorder); ok {
+			recorder.Event(cr, eventType, reason, message)
+		}
+	}
+	return err
+}
+
+// dependencyKindName returns the kind of the dependency, without the Untyped prefix of untyped dependencies.
+func dependencyKindName(dependency interface{ Kind() string }) string {
+	if untyped, ok := dependency.(interface {
+		preferredGVK() schema.GroupVersionKind
+	}); ok {
+		return untyped.preferredGVK().Kind
+	}
+	return dependency.Kind()
+}
@@ -6,6 +6,8 @@ import (
 	ctrl "sigs.k8s.io/controller-runtime"
 )
 
+// NewEndStep ends the reconciliation, setting the Ready condition with setReadyCondF when it is not nil
+// and removing the AnnotationForceReconcile annotation of a forced reconciliation, see ForceReconcile.
 func NewEndStep[
 	ControllerResourceType ControllerCustomResource,
 	ContextType Context[ControllerResourceType],
@@ -33,6 +35,11 @@ func NewEndStep[
 				}
 			}
 
+			// The forced reconciliation is over
+			if err := clearForceReconcile(ctx, reconciler); err != nil {
+				return ResultInError(errors.Wrap(err, "failed to remove force reconcile annotation"))
+			}
+
 			return ResultSuccess()
 		},
 	}
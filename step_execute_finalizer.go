@@ -3,8 +3,12 @@ package ctrlfwk
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/go-logr/logr"
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
@@ -12,6 +16,28 @@ import (
 
 type FinalizingFunc func(ctx context.Context, logger logr.Logger, req ctrl.Request) (done bool, err error)
 
+const (
+	// ConditionTypeFinalizing is set on the custom resource while an asynchronous finalization is in progress.
+	ConditionTypeFinalizing = "Finalizing"
+)
+
+// AsyncFinalizerHandler handles finalization actions that are inherently asynchronous,
+// like draining a connection pool, see NewExecuteAsyncFinalizerStep.
+type AsyncFinalizerHandler[ControllerResourceType ControllerCustomResource] interface {
+	// StartFinalize is called on the first reconciliation of the custom resource being deleted. It is called again
+	// when it fails or when the Finalizing condition could not be set afterwards, so it must be idempotent.
+	StartFinalize(ctx context.Context, cr ControllerResourceType) error
+	// FinalizeComplete is called on the following reconciliations until it returns true.
+	FinalizeComplete(ctx context.Context, cr ControllerResourceType) (bool, error)
+}
+
+// AsyncFinalizerConfig configures NewExecuteAsyncFinalizerStep.
+type AsyncFinalizerConfig struct {
+	// RequeueInterval is how often FinalizeComplete is called while the finalization is in progress.
+	// Defaults to 30 seconds.
+	RequeueInterval time.Duration
+}
+
 func NilFinalizerFunc(ctx context.Context, logger logr.Logger, req ctrl.Request) (done bool, err error) {
 	return true, nil
 }
@@ -54,3 +80,83 @@ func NewExecuteFinalizerStep[
 		},
 	}
 }
+
+// NewExecuteAsyncFinalizerStep executes an asynchronous finalizer.
+//
+// On the first reconciliation of the custom resource being deleted, StartFinalize is called and the
+// custom resource gets a Finalizing=True condition. On the following reconciliations, FinalizeComplete
+// is called every config.RequeueInterval until it returns true, only then is the finalizer removed.
+// The condition is set once StartFinalize succeeded, which may thus be called again, see AsyncFinalizerHandler.
+//
+// The progress is tracked using the Finalizing condition, so the custom resource must have
+// a status conditions field, see SetReadyCondition.
+func NewExecuteAsyncFinalizerStep[
+	ControllerResourceType ControllerCustomResource,
+	ContextType Context[ControllerResourceType],
+](
+	_ ContextType,
+	reconciler Reconciler[ControllerResourceType],
+	finalizerName string,
+	handler AsyncFinalizerHandler[ControllerResourceType],
+	config AsyncFinalizerConfig,
+) Step[ControllerResourceType, ContextType] {
+	if config.RequeueInterval <= 0 {
+		config.RequeueInterval = 30 * time.Second
+	}
+
+	return Step[ControllerResourceType, ContextType]{
+		Name: fmt.Sprintf(StepExecuteFinalizer, finalizerName),
+		Step: func(ctx ContextType, logger logr.Logger, req ctrl.Request) StepResult {
+			cr := ctx.GetCustomResource()
+
+			if !IsFinalizing(cr) || !controllerutil.ContainsFinalizer(cr, finalizerName) {
+				return ResultSuccess()
+			}
+
+			conditionsField, err := getConditionsField(cr)
+			if err != nil {
+				return ResultInError(errors.Wrap(err, "async finalizers require status conditions"))
+			}
+
+			conditions := conditionsField.Interface().([]metav1.Condition)
+			if !meta.IsStatusConditionTrue(conditions, ConditionTypeFinalizing) {
+				logger.Info("Starting asynchronous finalization")
+
+				if err := handler.StartFinalize(ctx, cr); err != nil {
+					return ResultInError(errors.Wrap(err, "failed to start finalization"))
+				}
+
+				if _, err := applyCondition(ctx, reconciler, ConditionTypeFinalizing, &metav1.Condition{
+					Status:             metav1.ConditionTrue,
+					Reason:             "FinalizationInProgress",
+					Message:            fmt.Sprintf("Finalizer %s is in progress", finalizerName),
+					ObservedGeneration: cr.GetGeneration(),
+				}); err != nil {
+					return ResultInError(errors.Wrap(err, "failed to update controller resource"))
+				}
+
+				return ResultRequeueIn(config.RequeueInterval).WithRequeueReason(RequeueReasonFinalizationInProgress)
+			}
+
+			done, err := handler.FinalizeComplete(ctx, cr)
+			if err != nil {
+				return ResultInError(errors.Wrap(err, "failed to check finalization"))
+			}
+			if !done {
+				logger.Info("Asynchronous finalization still in progress")
+				return ResultRequeueIn(config.RequeueInterval).WithRequeueReason(RequeueReasonFinalizationInProgress)
+			}
+
+			// Remove finalizer from CR
+			changed := controllerutil.RemoveFinalizer(cr, finalizerName)
+			if changed {
+				err := reconciler.Patch(ctx, cr, client.MergeFrom(ctx.GetCleanCustomResource()))
+				if err != nil {
+					return ResultInError(err)
+				}
+			}
+
+			return ResultSuccess()
+		},
+	}
+}
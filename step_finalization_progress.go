@@ -0,0 +1,167 @@
+package ctrlfwk
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/pkg/errors"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// ConditionTypeFinalizationProgress is set on the custom resource by NewFinalizationProgressStep while
+	// the resources deleted by the framework during finalization still exist.
+	ConditionTypeFinalizationProgress = "FinalizationProgress"
+)
+
+// FinalizationProgressConfig configures NewFinalizationProgressStep.
+type FinalizationProgressConfig struct {
+	// Interval is the minimum time between two patches of the progress, and how often the
+	// progress is checked again while resources remain. Defaults to 5 seconds.
+	Interval time.Duration
+	// MaxListed is the maximum number of remaining resources listed in the progress message. Defaults to 5.
+	MaxListed int
+}
+
+// finalizationProgressPatches holds when the progress of each custom resource was last patched,
+// so that large cleanups don't patch the status on every event of the deleted resources.
+var finalizationProgressPatches = struct {
+	lock      sync.Mutex
+	patchedAt map[types.UID]time.Time
+}{patchedAt: make(map[types.UID]time.Time)}
+
+// NewFinalizationProgressStep waits for the resources deleted by the framework during finalization to be gone,
+// reporting the progress on the FinalizationProgress condition of the custom resource, e.g.
+// "Deleting children: 12/40 remaining (waiting on PersistentVolumeClaim data-1, PersistentVolumeClaim data-2)".
+//
+// Only the resources deleted by the framework are waited for, i.e. the ones requiring manual deletion
+// (see ResourceBuilder.WithRequireManualDeletionForFinalize) and the ones of remote clusters,
+// the others being garbage collected once the custom resource is gone. Combined with
+// ResourceBuilder.WithDeletePropagationPolicy and metav1.DeletePropagationForeground, the finalizer
+// is only removed once the dependents of the resources, e.g. the pods of a StatefulSet, are deleted too.
+//
+// The step must run after NewReconcileResourcesStep and before the step removing the finalizer.
+// The condition is patched at most once per config.Interval.
+func NewFinalizationProgressStep[
+	ControllerResourceType ControllerCustomResource,
+	ContextType Context[ControllerResourceType],
+](
+	_ ContextType,
+	reconciler ReconcilerWithResources[ControllerResourceType, ContextType],
+	config FinalizationProgressConfig,
+) Step[ControllerResourceType, ContextType] {
+	if config.Interval <= 0 {
+		config.Interval = 5 * time.Second
+	}
+	if config.MaxListed <= 0 {
+		config.MaxListed = 5
+	}
+
+	return Step[ControllerResourceType, ContextType]{
+		Name: StepFinalizationProgress,
+		Step: func(ctx ContextType, logger logr.Logger, req ctrl.Request) StepResult {
+			cr := ctx.GetCustomResource()
+			if !IsFinalizing(cr) {
+				return ResultSuccess()
+			}
+
+			resources, err := reconciler.GetResources(ctx, req)
+			if err != nil {
+				return ResultInError(errors.Wrap(err, "failed to get resources"))
+			}
+
+			var total int
+			var remaining []string
+			for _, resource := range resources {
+				c, remote, result := clientFor(ctx, reconciler, resource, resource.ID())
+				if result.ShouldReturn() {
+					return result
+				}
+				if !remote && !resource.RequiresManualDeletion(resource.Get()) {
+					continue
+				}
+
+				obj, _, err := generateResourceObject(ctx, resource)
+				if err != nil {
+					return ResultInError(errors.Wrapf(err, "failed to generate resource %s", resource.ID()))
+				}
+				if obj == nil || obj.GetName() == "" {
+					continue
+				}
+
+				total++
+				if err := c.Get(ctx, client.ObjectKeyFromObject(obj), obj.DeepCopyObject().(client.Object)); err == nil {
+					remaining = append(remaining, fmt.Sprintf("%s %s", resource.Kind(), obj.GetName()))
+				} else if !apierrors.IsNotFound(err) {
+					return ResultInError(errors.Wrapf(err, "failed to get resource %s", resource.ID()))
+				}
+			}
+
+			if len(remaining) == 0 {
+				finalizationProgressPatches.lock.Lock()
+				delete(finalizationProgressPatches.patchedAt, cr.GetUID())
+				finalizationProgressPatches.lock.Unlock()
+
+				return ResultSuccess()
+			}
+
+			logger.Info("Waiting for resources to be deleted", "remaining", len(remaining), "total", total)
+
+			listed := remaining
+			if len(listed) > config.MaxListed {
+				listed = append(listed[:config.MaxListed:config.MaxListed], fmt.Sprintf("%d more", len(remaining)-config.MaxListed))
+			}
+			message := fmt.Sprintf("Deleting children: %d/%d remaining (waiting on %s)", len(remaining), total, strings.Join(listed, ", "))
+
+			if err := setFinalizationProgressCondition(ctx, reconciler, message, config.Interval, time.Now()); err != nil {
+				return ResultInError(errors.Wrap(err, "failed to set finalization progress condition"))
+			}
+
+			return ResultRequeueIn(config.Interval).WithRequeueReason(RequeueReasonFinalizationInProgress)
+		},
+	}
+}
+
+// setFinalizationProgressCondition reports the progress of the finalization on the custom resource,
+// unless it was already patched less than interval ago.
+func setFinalizationProgressCondition[
+	ControllerResourceType ControllerCustomResource,
+	ContextType Context[ControllerResourceType],
+](
+	ctx ContextType,
+	reconciler Reconciler[ControllerResourceType],
+	message string,
+	interval time.Duration,
+	now time.Time,
+) error {
+	defer LockContext(ctx)()
+
+	cr := ctx.GetCustomResource()
+
+	finalizationProgressPatches.lock.Lock()
+	defer finalizationProgressPatches.lock.Unlock()
+
+	if patchedAt, ok := finalizationProgressPatches.patchedAt[cr.GetUID()]; ok && now.Sub(patchedAt) < interval {
+		return nil
+	}
+
+	changed, err := applyCondition(ctx, reconciler, ConditionTypeFinalizationProgress, &metav1.Condition{
+		Status:             metav1.ConditionTrue,
+		Reason:             "DeletingResources",
+		Message:            message,
+		ObservedGeneration: cr.GetGeneration(),
+	})
+	if err != nil || !changed {
+		return err
+	}
+	finalizationProgressPatches.patchedAt[cr.GetUID()] = now
+
+	return nil
+}
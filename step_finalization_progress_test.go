@@ -0,0 +1,69 @@
+package ctrlfwk_test
+
+import (
+	"testing"
+
+	"github.com/go-logr/logr"
+	ctrlfwk "github.com/u-ctf/controller-fwk"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func TestFinalizationProgressStep_WaitsForDeletedResources(t *testing.T) {
+	ctx, reconciler := newConditionsTest(t)
+
+	for _, name := range []string{"data-1", "garbage-collected"} {
+		if err := reconciler.Create(ctx, &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"}}); err != nil {
+			t.Fatalf("failed to create configmap: %v", err)
+		}
+	}
+
+	configMap := func(name string, manualDeletion bool) ctrlfwk.GenericResource[*conditionsCR, conditionsContext] {
+		return ctrlfwk.NewResourceBuilder(ctx, &corev1.ConfigMap{}).
+			WithKey(types.NamespacedName{Name: name, Namespace: "default"}).
+			WithRequireManualDeletionForFinalize(func(*corev1.ConfigMap) bool { return manualDeletion }).
+			Build()
+	}
+	reconciler.resources = []ctrlfwk.GenericResource[*conditionsCR, conditionsContext]{
+		configMap("data-1", true),
+		configMap("data-2", true),
+		configMap("garbage-collected", false),
+	}
+
+	cr := ctx.GetCustomResource()
+	cr.Finalizers = []string{"test.ctrlfwk.com/finalizer"}
+	if err := reconciler.Update(ctx, cr); err != nil {
+		t.Fatalf("failed to add finalizer: %v", err)
+	}
+	if err := reconciler.Delete(ctx, cr); err != nil {
+		t.Fatalf("failed to delete custom resource: %v", err)
+	}
+	if err := reconciler.Get(ctx, client.ObjectKeyFromObject(cr), cr); err != nil {
+		t.Fatalf("failed to get custom resource: %v", err)
+	}
+	ctx.SetCustomResource(cr)
+
+	step := ctrlfwk.NewFinalizationProgressStep(ctx, reconciler, ctrlfwk.FinalizationProgressConfig{})
+
+	result := step.Step(ctx, logr.Discard(), ctrl.Request{})
+	if result.RequeueReason() != ctrlfwk.RequeueReasonFinalizationInProgress {
+		t.Fatalf("expected the finalization to wait for the resources, got %v", result)
+	}
+	condition := meta.FindStatusCondition(ctx.GetCustomResource().Status.Conditions, ctrlfwk.ConditionTypeFinalizationProgress)
+	if condition == nil || condition.Message != "Deleting children: 1/2 remaining (waiting on ConfigMap data-1)" {
+		t.Fatalf("unexpected progress condition %v", condition)
+	}
+
+	if err := reconciler.Delete(ctx, &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "data-1", Namespace: "default"}}); err != nil {
+		t.Fatalf("failed to delete configmap: %v", err)
+	}
+
+	if result := step.Step(ctx, logr.Discard(), ctrl.Request{}); result.ShouldReturn() {
+		t.Fatalf("unexpected result: %v", result)
+	}
+}
@@ -0,0 +1,67 @@
+package ctrlfwk
+
+import (
+	"fmt"
+
+	"github.com/go-logr/logr"
+	"github.com/pkg/errors"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+// NewFinalizerStep manages a finalizer independently of any resource, for custom resources that provision
+// external resources only, e.g. in a cloud provider, and must clean them up when deleted.
+//
+// While the custom resource is not being deleted, the finalizer is added to it. Once it is being deleted,
+// onFinalize is called on each reconciliation until it succeeds, then the finalizer is removed and the
+// reconciliation returns early, as there is nothing left to reconcile.
+//
+// The step should come right after the find step, see StepperBuilder.WithFinalizer.
+//
+// Example:
+//
+//	ctrlfwk.NewFinalizerStep(ctx, reconciler, "bucket.example.com/cleanup", func(ctx ctrlfwk.Context[*v1.Bucket]) error {
+//		return storage.DeleteBucket(ctx, ctx.GetCustomResource().Spec.Name)
+//	})
+func NewFinalizerStep[
+	ControllerResourceType ControllerCustomResource,
+	ContextType Context[ControllerResourceType],
+](
+	_ ContextType,
+	reconciler Reconciler[ControllerResourceType],
+	finalizerName string,
+	onFinalize func(ctx ContextType) error,
+) Step[ControllerResourceType, ContextType] {
+	return Step[ControllerResourceType, ContextType]{
+		Name: fmt.Sprintf(StepFinalizer, finalizerName),
+		Step: func(ctx ContextType, logger logr.Logger, req ctrl.Request) StepResult {
+			cr := ctx.GetCustomResource()
+
+			if !IsFinalizing(cr) {
+				if controllerutil.AddFinalizer(cr, finalizerName) {
+					if err := reconciler.Patch(ctx, cr, client.MergeFrom(ctx.GetCleanCustomResource())); err != nil {
+						return ResultInError(err)
+					}
+				}
+				return ResultSuccess()
+			}
+
+			if !controllerutil.ContainsFinalizer(cr, finalizerName) {
+				return ResultSuccess()
+			}
+
+			if err := runOperation(ctx, "OnFinalize", func() error { return onFinalize(ctx) }); err != nil {
+				return ResultInError(errors.Wrapf(err, "failed to run finalizer %s", finalizerName))
+			}
+
+			controllerutil.RemoveFinalizer(cr, finalizerName)
+			if err := reconciler.Patch(ctx, cr, client.MergeFrom(ctx.GetCleanCustomResource())); client.IgnoreNotFound(err) != nil {
+				return ResultInError(err)
+			}
+
+			logger.Info("Finalizer removed", "finalizer", finalizerName)
+			return ResultEarlyReturn()
+		},
+	}
+}
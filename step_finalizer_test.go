@@ -0,0 +1,155 @@
+package ctrlfwk_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	ctrlfwk "github.com/u-ctf/controller-fwk"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+func TestStepper_WithFinalizer(t *testing.T) {
+	ctx, reconciler := newDeletionTest(t, interceptor.Funcs{})
+	key := types.NamespacedName{Name: "cr", Namespace: "default"}
+	req := ctrl.Request{NamespacedName: key}
+
+	cleanupErr := errors.New("bucket still in use")
+	var cleanups int
+	stepper := ctrlfwk.NewStepperFor(ctx, logr.Discard()).
+		WithFinalizer(reconciler, "test.ctrlfwk.com/cleanup", func(ctx ctrlfwk.Context[*corev1.ConfigMap]) error {
+			cleanups++
+			return cleanupErr
+		}).
+		Build()
+
+	if _, err := stepper.Execute(ctx, req); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	cr := &corev1.ConfigMap{}
+	if err := reconciler.Get(ctx, key, cr); err != nil {
+		t.Fatalf("failed to get custom resource: %v", err)
+	}
+	if !controllerutil.ContainsFinalizer(cr, "test.ctrlfwk.com/cleanup") || cleanups != 0 {
+		t.Fatalf("expected the finalizer to be added without cleaning up, got %v (%d cleanups)", cr.Finalizers, cleanups)
+	}
+
+	if err := reconciler.Delete(ctx, cr); err != nil {
+		t.Fatalf("failed to delete custom resource: %v", err)
+	}
+	reload := func() {
+		cr := &corev1.ConfigMap{}
+		if err := reconciler.Get(ctx, key, cr); err != nil {
+			t.Fatalf("failed to get custom resource: %v", err)
+		}
+		ctx.SetCustomResource(cr)
+	}
+
+	// The finalizer is kept until the cleanup succeeds
+	reload()
+	if _, err := stepper.Execute(ctx, req); !errors.Is(err, cleanupErr) {
+		t.Fatalf("expected the cleanup error, got %v", err)
+	}
+
+	cleanupErr = nil
+	reload()
+	if _, err := stepper.Execute(ctx, req); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cleanups != 2 {
+		t.Fatalf("expected 2 cleanups, got %d", cleanups)
+	}
+	if err := reconciler.Get(ctx, key, &corev1.ConfigMap{}); !apierrors.IsNotFound(err) {
+		t.Fatalf("expected the custom resource to be gone, got %v", err)
+	}
+}
+
+type asyncFinalizerHandler struct {
+	starts int
+	checks int
+	done   bool
+}
+
+func (h *asyncFinalizerHandler) StartFinalize(context.Context, *conditionsCR) error {
+	h.starts++
+	return nil
+}
+
+func (h *asyncFinalizerHandler) FinalizeComplete(context.Context, *conditionsCR) (bool, error) {
+	h.checks++
+	return h.done, nil
+}
+
+func TestExecuteAsyncFinalizerStep(t *testing.T) {
+	ctx, reconciler := newConditionsTest(t)
+	key := types.NamespacedName{Name: "cr", Namespace: "default"}
+	req := ctrl.Request{NamespacedName: key}
+	finalizer := "test.ctrlfwk.com/drain"
+
+	reload := func() *conditionsCR {
+		t.Helper()
+		cr := &conditionsCR{}
+		if err := reconciler.Get(ctx, key, cr); err != nil {
+			t.Fatalf("failed to get custom resource: %v", err)
+		}
+		ctx.SetCustomResource(cr)
+		return cr
+	}
+	cr := reload()
+	controllerutil.AddFinalizer(cr, finalizer)
+	if err := reconciler.Update(ctx, cr); err != nil {
+		t.Fatalf("failed to add finalizer: %v", err)
+	}
+	if err := reconciler.Delete(ctx, cr); err != nil {
+		t.Fatalf("failed to delete custom resource: %v", err)
+	}
+
+	handler := &asyncFinalizerHandler{}
+	step := ctrlfwk.NewExecuteAsyncFinalizerStep(ctx, reconciler, finalizer, handler, ctrlfwk.AsyncFinalizerConfig{RequeueInterval: 5 * time.Second})
+
+	// The finalization is started and tracked by the Finalizing condition
+	reload()
+	result, err := step.Step(ctx, logr.Discard(), req).Normal()
+	if err != nil || result.RequeueAfter != 5*time.Second {
+		t.Fatalf("expected a requeue after the start, got %v, %v", result, err)
+	}
+	if handler.starts != 1 || handler.checks != 0 {
+		t.Fatalf("expected the finalization to be started once, got %d starts and %d checks", handler.starts, handler.checks)
+	}
+	if !meta.IsStatusConditionTrue(reload().Status.Conditions, ctrlfwk.ConditionTypeFinalizing) {
+		t.Fatalf("expected the Finalizing condition to be set, got %v", ctx.GetCustomResource().Status.Conditions)
+	}
+
+	// The finalizer is kept while the finalization is pending
+	result, err = step.Step(ctx, logr.Discard(), req).Normal()
+	if err != nil || result.RequeueAfter != 5*time.Second {
+		t.Fatalf("expected a requeue while pending, got %v, %v", result, err)
+	}
+	if handler.starts != 1 || handler.checks != 1 {
+		t.Fatalf("expected the finalization to be checked, got %d starts and %d checks", handler.starts, handler.checks)
+	}
+	if !controllerutil.ContainsFinalizer(reload(), finalizer) {
+		t.Fatal("expected the finalizer to be kept while pending")
+	}
+
+	// The finalizer is removed once complete
+	handler.done = true
+	if _, err := step.Step(ctx, logr.Discard(), req).Normal(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if handler.starts != 1 || handler.checks != 2 {
+		t.Fatalf("expected the finalization to be checked again, got %d starts and %d checks", handler.starts, handler.checks)
+	}
+	if err := reconciler.Get(ctx, key, &conditionsCR{}); !apierrors.IsNotFound(err) {
+		t.Fatalf("expected the custom resource to be gone, got %v", err)
+	}
+}
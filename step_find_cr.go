@@ -1,11 +1,23 @@
 package ctrlfwk
 
 import (
+	"context"
+	"fmt"
+
 	"github.com/go-logr/logr"
 	"github.com/pkg/errors"
 
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+)
+
+const (
+	// ConditionTypePaused is set on the custom resource while its reconciliation is paused
+	// using the LabelReconciliationPaused label.
+	ConditionTypePaused = "Paused"
 )
 
 func NewFindControllerCustomResourceStep[
@@ -20,30 +32,119 @@ func NewFindControllerCustomResourceStep[
 		Step: func(ctx ContextType, logger logr.Logger, req ctrl.Request) StepResult {
 			cr := ctx.GetCustomResource()
 
+			// The objects cached by the previous reconciliation may be stale
+			if cache := objectCacheFor(reconciler); cache != nil {
+				cache.InvalidateByOwner(req.NamespacedName)
+			}
+
 			// Get the controller resource from the client
-			err := reconciler.Get(ctx, req.NamespacedName, cr)
+			var err error
+			if reconcilerWithConversion, ok := reconciler.(ReconcilerWithConversion[ControllerResourceType]); ok {
+				err = getConvertedCustomResource(ctx, reconcilerWithConversion, req, cr)
+			} else {
+				err = reconciler.Get(ctx, req.NamespacedName, cr)
+			}
 			if err != nil {
 				if client.IgnoreNotFound(err) != nil {
 					// If the resource is not found, return early
 					return ResultInError(errors.Wrap(err, "failed to get controller resource"))
 				}
 
+				// The custom resource is gone, it doesn't need to be refreshed anymore
+				if scheduler, ok := reconciler.(BackgroundReconciler); ok {
+					scheduler.StopBackgroundReconcile(req.NamespacedName)
+				}
+
 				return ResultEarlyReturn()
 			}
 
+			// Set the controller resource in the reconciler
+			ctx.SetCustomResource(cr)
+
+			// Resources built with WithBackgroundReconcile can tell a scheduled refresh from the other triggers
+			markBackgroundRefresh(ctx, reconciler, req.NamespacedName)
+
 			// Check labels for pause
-			labels := cr.GetLabels()
-			if labels != nil {
-				if _, ok := labels[LabelReconciliationPaused]; ok {
-					logger.Info("Reconciliation is paused for this resource, skipping further steps")
-					return ResultEarlyReturn()
-				}
+			pauseValue, paused := cr.GetLabels()[LabelReconciliationPaused]
+			if err := setPausedCondition(ctx, reconciler, paused, pauseValue); err != nil {
+				return ResultInError(errors.Wrap(err, "failed to update paused condition"))
+			}
+			if paused {
+				logger.Info("Reconciliation is paused for this resource, skipping further steps")
+				return ResultEarlyReturn()
 			}
 
-			// Set the controller resource in the reconciler
-			ctx.SetCustomResource(cr)
+			// The same generation already failed with a terminal error, retrying it is pointless
+			if !IsFinalizing(cr) {
+				holds, err := terminalErrorHolds(ctx, reconciler, logger)
+				if err != nil {
+					return ResultInError(errors.Wrap(err, "failed to remove terminal error condition"))
+				}
+				if holds {
+					return ResultEarlyReturn().WithRequeueReason(RequeueReasonTerminalError)
+				}
+			}
 
 			return ResultSuccess()
 		},
 	}
 }
+
+// setPausedCondition reflects the pause label of the custom resource on its status,
+// the condition is removed once the label is removed.
+func setPausedCondition[
+	ControllerResourceType ControllerCustomResource,
+	ContextType Context[ControllerResourceType],
+](
+	ctx ContextType,
+	reconciler Reconciler[ControllerResourceType],
+	paused bool,
+	pauseValue string,
+) error {
+	cr := ctx.GetCustomResource()
+
+	if !paused {
+		_, err := applyCondition(ctx, reconciler, ConditionTypePaused, nil)
+		return err
+	}
+
+	message := fmt.Sprintf("reconciliation paused by label %s", LabelReconciliationPaused)
+	if pauseValue != "" {
+		message = fmt.Sprintf("%s=%s", message, pauseValue)
+	}
+
+	_, err := applyCondition(ctx, reconciler, ConditionTypePaused, &metav1.Condition{
+		Status:             metav1.ConditionTrue,
+		Reason:             "ManualPause",
+		Message:            message,
+		ObservedGeneration: cr.GetGeneration(),
+	})
+	return err
+}
+
+// getConvertedCustomResource reads the custom resource as unstructured and converts it using the reconciler.
+// The client of a manager doesn't cache unstructured objects unless built with client.CacheOptions{Unstructured: true},
+// the custom resource is then read from the API server on every reconciliation.
+func getConvertedCustomResource[ControllerResourceType ControllerCustomResource](
+	ctx context.Context,
+	reconciler ReconcilerWithConversion[ControllerResourceType],
+	req ctrl.Request,
+	cr ControllerResourceType,
+) error {
+	gvk, err := apiutil.GVKForObject(cr, reconciler.Scheme())
+	if err != nil {
+		return err
+	}
+
+	raw := &unstructured.Unstructured{}
+	raw.SetGroupVersionKind(gvk)
+	if err := reconciler.Get(ctx, req.NamespacedName, raw); err != nil {
+		return err
+	}
+
+	if err := reconciler.ConvertCustomResource(ctx, raw, cr); err != nil {
+		return errors.Wrap(err, "failed to convert controller resource")
+	}
+
+	return nil
+}
@@ -0,0 +1,61 @@
+package ctrlfwk_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-logr/logr"
+	ctrlfwk "github.com/u-ctf/controller-fwk"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+type conversionReconciler struct {
+	*conditionsReconciler
+	raw *unstructured.Unstructured
+}
+
+// ConvertCustomResource fills the phase of the custom resource from the legacy annotation of the raw object.
+func (r *conversionReconciler) ConvertCustomResource(_ context.Context, raw *unstructured.Unstructured, cr *conditionsCR) error {
+	r.raw = raw
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(raw.Object, cr); err != nil {
+		return err
+	}
+	if phase, ok := raw.GetAnnotations()["legacy.test.ctrlfwk.com/phase"]; ok {
+		cr.Status.Phase = phase
+	}
+	return nil
+}
+
+func TestFindControllerCustomResourceStep_ConvertsCustomResource(t *testing.T) {
+	ctx, inner := newConditionsTest(t)
+	key := types.NamespacedName{Name: "cr", Namespace: "default"}
+
+	cr := &conditionsCR{}
+	if err := inner.Get(ctx, key, cr); err != nil {
+		t.Fatalf("failed to get custom resource: %v", err)
+	}
+	cr.SetAnnotations(map[string]string{"legacy.test.ctrlfwk.com/phase": "Migrated"})
+	if err := inner.Update(ctx, cr); err != nil {
+		t.Fatalf("failed to update custom resource: %v", err)
+	}
+
+	reconciler := &conversionReconciler{conditionsReconciler: inner}
+	ctx.SetCustomResource(&conditionsCR{})
+	step := ctrlfwk.NewFindControllerCustomResourceStep(ctx, reconciler)
+
+	if result := step.Step(ctx, logr.Discard(), ctrl.Request{NamespacedName: key}); result.ShouldReturn() {
+		t.Fatalf("unexpected result: %v", result)
+	}
+
+	if reconciler.raw == nil || reconciler.raw.GroupVersionKind().Kind != "conditionsCR" {
+		t.Fatalf("expected the custom resource to be read as unstructured, got %v", reconciler.raw)
+	}
+	converted := ctx.GetCustomResource()
+	if converted.GetName() != "cr" || converted.Status.Phase != "Migrated" {
+		t.Fatalf("expected the converted custom resource to be set on the context, got %+v", converted)
+	}
+}
@@ -0,0 +1,89 @@
+package ctrlfwk
+
+import (
+	"fmt"
+
+	"github.com/go-logr/logr"
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// ConditionTypeListDependencyNotReady is set on the custom resource while a list dependency is not ready,
+	// its message holds the matched and ready counts.
+	ConditionTypeListDependencyNotReady = "ListDependencyNotReady"
+)
+
+// NewResolveListDependencyStep lists the objects of a list dependency and waits for them to be ready.
+//
+// While the dependency is not ready, the custom resource gets a ListDependencyNotReady condition
+// and is requeued, like a regular dependency.
+func NewResolveListDependencyStep[
+	ControllerResourceType ControllerCustomResource,
+	ContextType Context[ControllerResourceType],
+	DependencyType client.Object,
+](
+	_ ContextType,
+	reconciler Reconciler[ControllerResourceType],
+	dependency *ListDependency[ControllerResourceType, ContextType, DependencyType],
+) Step[ControllerResourceType, ContextType] {
+	return Step[ControllerResourceType, ContextType]{
+		Name: fmt.Sprintf(StepResolveListDependency, dependency.Kind()),
+		Step: func(ctx ContextType, logger logr.Logger, req ctrl.Request) StepResult {
+			logger, restoreLogger := scopeLogger(ctx, "dependency", dependency.ID())
+			defer restoreLogger()
+
+			if IsFinalizing(ctx.GetCustomResource()) {
+				return ResultSuccess()
+			}
+
+			if err := dependency.List(ctx, reconciler, reconciler.Scheme()); err != nil {
+				return ResultInError(errors.Wrap(err, "failed to list dependency resources"))
+			}
+
+			if !dependency.IsReady() {
+				logger.Info("List dependency is not ready", "status", dependency.Status())
+				if err := setListDependencyNotReadyCondition(ctx, reconciler, dependency.ID(), dependency.Status(), false); err != nil {
+					return ResultInError(errors.Wrap(err, "failed to set list dependency condition"))
+				}
+				return resultFromError(logger, &DependencyNotReadyError{ID: dependency.ID(), Reason: dependency.Status()})
+			}
+
+			if err := setListDependencyNotReadyCondition(ctx, reconciler, dependency.ID(), dependency.Status(), true); err != nil {
+				return ResultInError(errors.Wrap(err, "failed to remove list dependency condition"))
+			}
+
+			return ResultSuccess()
+		},
+	}
+}
+
+// setListDependencyNotReadyCondition reflects a list dependency that is not ready on the custom resource status,
+// the condition is removed once it is ready.
+func setListDependencyNotReadyCondition[
+	ControllerResourceType ControllerCustomResource,
+	ContextType Context[ControllerResourceType],
+](
+	ctx ContextType,
+	reconciler Reconciler[ControllerResourceType],
+	dependencyID string,
+	status string,
+	ready bool,
+) error {
+	cr := ctx.GetCustomResource()
+
+	if ready {
+		_, err := applyCondition(ctx, reconciler, ConditionTypeListDependencyNotReady, nil)
+		return err
+	}
+
+	_, err := applyCondition(ctx, reconciler, ConditionTypeListDependencyNotReady, &metav1.Condition{
+		Status:             metav1.ConditionTrue,
+		Reason:             "WaitingForDependency",
+		Message:            fmt.Sprintf("dependency %s: %s", dependencyID, status),
+		ObservedGeneration: cr.GetGeneration(),
+	})
+	return err
+}
@@ -0,0 +1,104 @@
+package ctrlfwk
+
+import (
+	"fmt"
+
+	"github.com/go-logr/logr"
+	"github.com/pkg/errors"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// AnnotationMigrationPrefix prefixes the annotation recording on the custom resource that a migration
+	// has completed, followed by the ID of the migration, see NewMigrateResourceStep.
+	AnnotationMigrationPrefix = "migrations.ctrlfwk.com/"
+
+	// MigrationCompleted is the value of the migration annotation once the migration has completed.
+	MigrationCompleted = "completed"
+
+	// EventReasonMigrationComplete is the reason of the event emitted once a migration has completed.
+	EventReasonMigrationComplete = "MigrationComplete"
+)
+
+// NewMigrateResourceStep migrates a resource created by a previous version of the controller, e.g. to rename
+// it or restructure it, before the resources of the current version are reconciled.
+//
+// detect tells whether the custom resource needs the migration, e.g. an annotation of the previous version
+// is present. migrate is then called with the live object found at the key returned by key, and once it
+// succeeds the completion is recorded on the custom resource with the annotation AnnotationMigrationPrefix
+// followed by migrationID, so that the migration never runs again, and a MigrationComplete event is emitted
+// if the reconciler is a record.EventRecorder. When there is no object at the key, there is nothing to
+// migrate and the migration is recorded as completed.
+//
+// migrate may be called again if the completion fails to be recorded, it must therefore be idempotent.
+//
+// Example:
+//
+//	ctrlfwk.NewMigrateResourceStep(ctx, reconciler, "v2-service-name", &corev1.Service{},
+//		func() types.NamespacedName {
+//			return types.NamespacedName{Name: cr.Name + "-svc", Namespace: cr.Namespace}
+//		},
+//		func(ctx ctrlfwk.Context[*v1.App]) bool { return true },
+//		func(ctx ctrlfwk.Context[*v1.App], service *corev1.Service) error {
+//			return reconciler.Delete(ctx, service)
+//		},
+//	)
+func NewMigrateResourceStep[
+	ControllerResourceType ControllerCustomResource,
+	ContextType Context[ControllerResourceType],
+	ResourceType client.Object,
+](
+	_ ContextType,
+	reconciler Reconciler[ControllerResourceType],
+	migrationID string,
+	obj ResourceType,
+	key func() types.NamespacedName,
+	detect func(ctx ContextType) bool,
+	migrate func(ctx ContextType, obj ResourceType) error,
+) Step[ControllerResourceType, ContextType] {
+	annotation := AnnotationMigrationPrefix + migrationID
+
+	return Step[ControllerResourceType, ContextType]{
+		Name: fmt.Sprintf(StepMigrateResource, migrationID),
+		Step: func(ctx ContextType, logger logr.Logger, req ctrl.Request) StepResult {
+			cr := ctx.GetCustomResource()
+
+			if IsFinalizing(cr) || GetAnnotation(cr, annotation) == MigrationCompleted {
+				return ResultSuccess()
+			}
+
+			if !detect(ctx) {
+				return ResultSuccess()
+			}
+
+			live := NewInstanceOf(obj)
+			err := reconciler.Get(ctx, key(), live)
+			switch {
+			case apierrors.IsNotFound(err):
+				logger.Info("Nothing to migrate", "migration", migrationID)
+			case err != nil:
+				return ResultInError(errors.Wrapf(err, "failed to get resource of migration %s", migrationID))
+			default:
+				if err := runOperation(ctx, "Migrate", func() error { return migrate(ctx, live) }); err != nil {
+					return ResultInError(errors.Wrapf(err, "failed to run migration %s", migrationID))
+				}
+			}
+
+			setAnnotations(cr, map[string]string{annotation: MigrationCompleted})
+			if err := reconciler.Patch(ctx, cr, client.MergeFrom(ctx.GetCleanCustomResource())); err != nil {
+				return ResultInError(errors.Wrapf(err, "failed to record migration %s", migrationID))
+			}
+
+			logger.Info("Migration completed", "migration", migrationID)
+			if recorder, ok := reconciler.(record.EventRecorder); ok {
+				recorder.Eventf(cr, "Normal", EventReasonMigrationComplete, "Migration %s completed", migrationID)
+			}
+
+			return ResultSuccess()
+		},
+	}
+}
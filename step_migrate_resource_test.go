@@ -0,0 +1,50 @@
+package ctrlfwk_test
+
+import (
+	"testing"
+
+	"github.com/go-logr/logr"
+	ctrlfwk "github.com/u-ctf/controller-fwk"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+)
+
+func TestMigrateResourceStep(t *testing.T) {
+	ctx, reconciler := newDeletionTest(t, interceptor.Funcs{})
+	key := types.NamespacedName{Name: "cr", Namespace: "default"}
+	req := ctrl.Request{NamespacedName: key}
+
+	var migrations int
+	step := ctrlfwk.NewMigrateResourceStep(ctx, reconciler, "v2-secret", &corev1.Secret{},
+		func() types.NamespacedName { return types.NamespacedName{Name: "secret", Namespace: "default"} },
+		func(ctx ctrlfwk.Context[*corev1.ConfigMap]) bool { return true },
+		func(ctx ctrlfwk.Context[*corev1.ConfigMap], secret *corev1.Secret) error {
+			migrations++
+			return reconciler.Delete(ctx, secret)
+		},
+	)
+
+	for range 2 {
+		if _, err := step.Step(ctx, logr.Discard(), req).Normal(); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if migrations != 1 {
+		t.Fatalf("expected the migration to run once, got %d", migrations)
+	}
+	if err := reconciler.Get(ctx, types.NamespacedName{Name: "secret", Namespace: "default"}, &corev1.Secret{}); !apierrors.IsNotFound(err) {
+		t.Fatalf("expected the legacy secret to be deleted, got %v", err)
+	}
+
+	cr := &corev1.ConfigMap{}
+	if err := reconciler.Get(ctx, key, cr); err != nil {
+		t.Fatalf("failed to get custom resource: %v", err)
+	}
+	if got := cr.Annotations[ctrlfwk.AnnotationMigrationPrefix+"v2-secret"]; got != ctrlfwk.MigrationCompleted {
+		t.Fatalf("expected the migration to be recorded, got %q", got)
+	}
+}
@@ -0,0 +1,276 @@
+package ctrlfwk
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/go-logr/logr"
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+)
+
+const (
+	// ConditionTypePruneCandidates is set on the custom resource when the prune step runs in dry-run mode
+	// and found resources that are not declared anymore.
+	ConditionTypePruneCandidates = "PruneCandidates"
+)
+
+// PruneConfig configures the prune step.
+type PruneConfig struct {
+	// Enabled must be set to true for the step to do anything, pruning is opt-in.
+	Enabled bool
+	// DryRun only reports the resources that would be pruned, using events and the PruneCandidates condition.
+	DryRun bool
+	// GVKs lists additional GroupVersionKinds to look for stray resources in.
+	// The step already looks into the GVKs of the declared resources and into the ones it recorded previously,
+	// this is useful for resources that were removed before pruning was enabled.
+	GVKs []schema.GroupVersionKind
+}
+
+type pruneCandidate struct {
+	gvk schema.GroupVersionKind
+	obj *unstructured.Unstructured
+}
+
+func (c pruneCandidate) String() string {
+	return fmt.Sprintf("%s %s", c.gvk.Kind, client.ObjectKeyFromObject(c.obj))
+}
+
+// NewPruneResourcesStep deletes the resources that were created for the custom resource but are not declared anymore
+// by the reconciler, for example after a new version of the operator stopped declaring them.
+//
+// Resources are found using the tracking labels set by the framework on every reconciled resource.
+// The stray resources are deleted as unstructured objects, so their type does not need to be registered in the scheme.
+//
+// The resources declared by the reconciler are never pruned, including the ones that are skipped, which are deleted
+// by the step reconciling them. Neither are the resources the tracking labels were set on during the reconciliation,
+// such as the resources of NewAtomicResourceGroupStep, so the step must come after the steps reconciling resources.
+func NewPruneResourcesStep[
+	ControllerResourceType ControllerCustomResource,
+	ContextType Context[ControllerResourceType],
+](
+	_ ContextType,
+	reconciler ReconcilerWithResources[ControllerResourceType, ContextType],
+	config PruneConfig,
+) Step[ControllerResourceType, ContextType] {
+	return Step[ControllerResourceType, ContextType]{
+		Name: StepPruneResources,
+		Step: func(ctx ContextType, logger logr.Logger, req ctrl.Request) StepResult {
+			cr := ctx.GetCustomResource()
+
+			if !config.Enabled || IsFinalizing(cr) {
+				return ResultSuccess()
+			}
+
+			declared, err := getDeclaredResources(ctx, reconciler, req)
+			if err != nil {
+				return ResultInError(errors.Wrap(err, "failed to get declared resources"))
+			}
+
+			gvks := getTrackedGVKs(cr)
+			gvks = append(gvks, config.GVKs...)
+			for gvk := range declared {
+				gvks = append(gvks, gvk)
+			}
+
+			var candidates []pruneCandidate
+			seen := make(map[schema.GroupVersionKind]bool)
+
+			for _, gvk := range gvks {
+				if seen[gvk] {
+					continue
+				}
+				seen[gvk] = true
+
+				list := &unstructured.UnstructuredList{}
+				list.SetGroupVersionKind(gvk.GroupVersion().WithKind(gvk.Kind + "List"))
+
+				err := reconciler.List(ctx, list, client.MatchingLabels{LabelTrackingOwnerUID: string(cr.GetUID())})
+				if meta.IsNoMatchError(err) {
+					// The type is not served anymore, nothing can be left behind
+					continue
+				}
+				if err != nil {
+					return ResultInError(errors.Wrapf(err, "failed to list %s", gvk.Kind))
+				}
+
+				for i := range list.Items {
+					obj := &list.Items[i]
+					if declared[gvk][client.ObjectKeyFromObject(obj)] {
+						continue
+					}
+
+					candidateGVK := gvk
+					if trackedGVK, ok := DecodeTrackingGVK(obj.GetLabels()[LabelTrackingGVK]); ok {
+						candidateGVK = trackedGVK
+					}
+					obj.SetGroupVersionKind(candidateGVK)
+
+					candidates = append(candidates, pruneCandidate{gvk: candidateGVK, obj: obj})
+				}
+			}
+
+			recorder, hasRecorder := reconciler.(record.EventRecorder)
+
+			var remaining []pruneCandidate
+			for _, candidate := range candidates {
+				if config.DryRun {
+					logger.Info("Resource would be pruned", "candidate", candidate.String())
+					if hasRecorder {
+						recorder.Eventf(cr, "Normal", "PruneCandidate", "%s is not declared anymore and would be pruned", candidate)
+					}
+					remaining = append(remaining, candidate)
+					continue
+				}
+
+				if err := reconciler.Delete(ctx, candidate.obj); client.IgnoreNotFound(err) != nil {
+					logger.Error(err, "Failed to prune resource", "candidate", candidate.String())
+					remaining = append(remaining, candidate)
+					continue
+				}
+
+				logger.Info("Pruned resource", "candidate", candidate.String())
+				if hasRecorder {
+					recorder.Eventf(cr, "Normal", "Pruned", "%s is not declared anymore and was pruned", candidate)
+				}
+			}
+
+			if err := setTrackedGVKs(ctx, reconciler, declared, remaining); err != nil {
+				return ResultInError(errors.Wrap(err, "failed to record tracked GVKs"))
+			}
+
+			if err := setPruneCandidatesCondition(ctx, reconciler, config.DryRun, remaining); err != nil {
+				logger.Error(err, "Failed to update prune candidates condition")
+			}
+
+			if len(remaining) > 0 && !config.DryRun {
+				return ResultInError(fmt.Errorf("failed to prune %d resource(s)", len(remaining)))
+			}
+
+			return ResultSuccess()
+		},
+	}
+}
+
+// getDeclaredResources returns the keys of the resources currently declared by the reconciler, skipped or not, along
+// with the resources the tracking labels were set on during the reconciliation, grouped by GVK.
+func getDeclaredResources[
+	ControllerResourceType ControllerCustomResource,
+	ContextType Context[ControllerResourceType],
+](
+	ctx ContextType,
+	reconciler ReconcilerWithResources[ControllerResourceType, ContextType],
+	req ctrl.Request,
+) (map[schema.GroupVersionKind]map[types.NamespacedName]bool, error) {
+	resources, err := reconciler.GetResources(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	declared := ctx.GetReconcileReport().trackedResources()
+	for _, resource := range resources {
+		// Skipped resources are deleted by the step reconciling them, once their BeforeDelete hooks allow it
+		obj, _, err := generateResourceObject(ctx, resource)
+		if err != nil {
+			return nil, err
+		}
+		if obj == nil {
+			continue
+		}
+
+		gvk, err := apiutil.GVKForObject(obj, reconciler.Scheme())
+		if err != nil {
+			return nil, err
+		}
+
+		if declared[gvk] == nil {
+			declared[gvk] = make(map[types.NamespacedName]bool)
+		}
+		declared[gvk][client.ObjectKeyFromObject(obj)] = true
+	}
+
+	return declared, nil
+}
+
+// setTrackedGVKs records on the custom resource the GVKs that may hold tracked resources,
+// so that they are still looked into once they are not declared anymore.
+func setTrackedGVKs[
+	ControllerResourceType ControllerCustomResource,
+	ContextType Context[ControllerResourceType],
+](
+	ctx ContextType,
+	reconciler Reconciler[ControllerResourceType],
+	declared map[schema.GroupVersionKind]map[types.NamespacedName]bool,
+	remaining []pruneCandidate,
+) error {
+	var encoded []string
+	for gvk := range declared {
+		encoded = append(encoded, EncodeTrackingGVK(gvk))
+	}
+	for _, candidate := range remaining {
+		encoded = append(encoded, EncodeTrackingGVK(candidate.gvk))
+	}
+	slices.Sort(encoded)
+	encoded = slices.Compact(encoded)
+
+	value := strings.Join(encoded, ",")
+
+	cr := ctx.GetCustomResource()
+	if GetAnnotation(cr, AnnotationTrackedGVKs) == value {
+		return nil
+	}
+
+	// Patch from the clean object so that pending changes of the custom resource are not sent along
+	cleanObject := ctx.GetCleanCustomResource()
+	modifiedObject := cleanObject.DeepCopyObject().(ControllerResourceType)
+	SetAnnotation(modifiedObject, AnnotationTrackedGVKs, value)
+
+	if err := reconciler.Patch(ctx, modifiedObject, client.MergeFrom(cleanObject)); err != nil {
+		return err
+	}
+
+	SetAnnotation(cr, AnnotationTrackedGVKs, value)
+
+	return nil
+}
+
+// setPruneCandidatesCondition lists the resources that would be pruned on the custom resource status.
+// The condition is removed once there is nothing to prune anymore.
+func setPruneCandidatesCondition[
+	ControllerResourceType ControllerCustomResource,
+	ContextType Context[ControllerResourceType],
+](
+	ctx ContextType,
+	reconciler Reconciler[ControllerResourceType],
+	dryRun bool,
+	candidates []pruneCandidate,
+) error {
+	cr := ctx.GetCustomResource()
+
+	if !dryRun || len(candidates) == 0 {
+		_, err := applyCondition(ctx, reconciler, ConditionTypePruneCandidates, nil)
+		return err
+	}
+
+	messages := make([]string, 0, len(candidates))
+	for _, candidate := range candidates {
+		messages = append(messages, candidate.String())
+	}
+
+	_, err := applyCondition(ctx, reconciler, ConditionTypePruneCandidates, &metav1.Condition{
+		Status:             metav1.ConditionTrue,
+		Reason:             "DryRun",
+		Message:            fmt.Sprintf("%d resource(s) would be pruned: %s", len(candidates), strings.Join(messages, "; ")),
+		ObservedGeneration: cr.GetGeneration(),
+	})
+	return err
+}
@@ -0,0 +1,66 @@
+package ctrlfwk_test
+
+import (
+	"testing"
+
+	"github.com/go-logr/logr"
+	ctrlfwk "github.com/u-ctf/controller-fwk"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+func TestPruneResourcesStep_KeepsDeclaredResources(t *testing.T) {
+	ctx, reconciler := newConditionsTest(t)
+	cr := ctx.GetCustomResource()
+	cr.SetUID("cr-uid")
+
+	configMap := func(name string) *corev1.ConfigMap {
+		return &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"}}
+	}
+	resource := func(name string) *ctrlfwk.ResourceBuilder[*conditionsCR, conditionsContext, *corev1.ConfigMap] {
+		return ctrlfwk.NewResourceBuilder(ctx, &corev1.ConfigMap{}).
+			WithKey(types.NamespacedName{Name: name, Namespace: "default"}).
+			WithReadinessCondition(func(*corev1.ConfigMap) bool { return true })
+	}
+
+	// The resources created by previous reconciliations
+	for _, name := range []string{"declared", "undeclared", "skipped"} {
+		obj := configMap(name)
+		if err := ctrlfwk.SetTrackingLabels(obj, cr, reconciler.Scheme()); err != nil {
+			t.Fatalf("failed to set tracking labels: %v", err)
+		}
+		if err := reconciler.Create(ctx, obj); err != nil {
+			t.Fatalf("failed to create %s: %v", name, err)
+		}
+	}
+
+	reconciler.resources = append(reconciler.resources,
+		resource("declared").Build(),
+		resource("skipped").WithSkipAndDeleteOnCondition(func() bool { return true }).Build(),
+	)
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Name: "cr", Namespace: "default"}}
+
+	// The resources of the atomic group are not returned by GetResources
+	atomic := ctrlfwk.NewAtomicResourceGroupStep(ctx, reconciler, resource("atomic").Build())
+	if result := atomic.Step(ctx, logr.Discard(), req); result.ShouldReturn() {
+		t.Fatalf("unexpected result: %v", result)
+	}
+
+	prune := ctrlfwk.NewPruneResourcesStep(ctx, reconciler, ctrlfwk.PruneConfig{Enabled: true})
+	if result := prune.Step(ctx, logr.Discard(), req); result.ShouldReturn() {
+		t.Fatalf("unexpected result: %v", result)
+	}
+
+	for _, name := range []string{"declared", "atomic", "skipped"} {
+		if err := reconciler.Get(ctx, types.NamespacedName{Name: name, Namespace: "default"}, configMap(name)); err != nil {
+			t.Fatalf("expected %s to be kept, got %v", name, err)
+		}
+	}
+	if err := reconciler.Get(ctx, types.NamespacedName{Name: "undeclared", Namespace: "default"}, configMap("undeclared")); !apierrors.IsNotFound(err) {
+		t.Fatalf("expected the undeclared resource to be pruned, got %v", err)
+	}
+}
@@ -1,10 +1,20 @@
 package ctrlfwk
 
 import (
+	"context"
+	stderrors "errors"
 	"fmt"
+	"time"
 
 	"github.com/go-logr/logr"
 	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
@@ -13,6 +23,30 @@ import (
 func NewReconcileResourceStep[
 	ControllerResourceType ControllerCustomResource,
 	ContextType Context[ControllerResourceType],
+](
+	ctx ContextType,
+	reconciler Reconciler[ControllerResourceType],
+	resource GenericResource[ControllerResourceType, ContextType],
+) Step[ControllerResourceType, ContextType] {
+	step := newReconcileResourceStep(ctx, reconciler, resource)
+
+	return Step[ControllerResourceType, ContextType]{
+		Name: step.Name,
+		Step: func(ctx ContextType, logger logr.Logger, req ctrl.Request) StepResult {
+			result := step.Step(ctx, logger, req)
+			if result.err != nil {
+				return resultFromError(logger, result.err)
+			}
+			return result
+		},
+	}
+}
+
+// newReconcileResourceStep reconciles a resource, returning the transient errors as is
+// so that NewReconcileResourcesStep can aggregate them.
+func newReconcileResourceStep[
+	ControllerResourceType ControllerCustomResource,
+	ContextType Context[ControllerResourceType],
 ](
 	_ ContextType,
 	reconciler Reconciler[ControllerResourceType],
@@ -21,28 +55,69 @@ func NewReconcileResourceStep[
 	return Step[ControllerResourceType, ContextType]{
 		Name: fmt.Sprintf(StepReconcileResource, resource.Kind()),
 		Step: func(ctx ContextType, logger logr.Logger, req ctrl.Request) StepResult {
+			loggerValues := []any{"resource", resource.ID()}
+			if gvk, err := resource.GroupVersionKind(reconciler.Scheme()); err == nil {
+				loggerValues = append(loggerValues, "object_type", gvk)
+			}
+			logger, restoreLogger := scopeLogger(ctx, loggerValues...)
+			defer restoreLogger()
+
 			var desired client.Object
 			var result StepResult
+			operation := controllerutil.OperationResultNone
 
 			funcResult := func() StepResult {
 				cr := ctx.GetCustomResource()
 
+				if err := getBuildError(resource); err != nil {
+					return ResultInError(errors.Wrapf(err, "invalid resource %s", resource.ID()))
+				}
+
+				// Resources built with WithClient are reconciled in another cluster
+				c, remote, clientResult := clientFor(ctx, reconciler, resource, resource.ID())
+				if clientResult.ShouldReturn() {
+					return clientResult
+				}
+				if remote && isSharedResource(resource) {
+					return ResultInError(fmt.Errorf("resource %s: shared ownership is not supported for resources of a remote cluster", resource.ID()))
+				}
+
 				if IsFinalizing(cr) {
-					// If the resource does not require deletion, we can just finish here, it's gonna get garbage collected
-					if !resource.RequiresManualDeletion(resource.Get()) {
-						if err := resource.OnFinalize(ctx, desired); err != nil {
-							return ResultInError(errors.Wrap(err, "failed to run OnFinalize hook"))
+					// If the resource does not require deletion, we can just finish here, it's gonna get garbage collected.
+					// Owner references don't work across clusters, so the resources of a remote cluster are always deleted.
+					if !remote && !resource.RequiresManualDeletion(resource.Get()) {
+						if err := runOperation(ctx, "AfterFinalize", func() error { return resource.OnFinalize(ctx, desired) }); err != nil {
+							return ResultInError(&HookError{ResourceID: resource.ID(), Hook: "AfterFinalize", Err: err})
 						}
 
 						return ResultSuccess()
 					}
 				}
 
-				if err := resource.BeforeReconcile(ctx); err != nil {
-					return ResultInError(errors.Wrap(err, "failed to run BeforeReconcile hook"))
+				if err := runOperation(ctx, "BeforeReconcile", func() error { return resource.BeforeReconcile(ctx) }); err != nil {
+					return ResultInError(&HookError{ResourceID: resource.ID(), Hook: "BeforeReconcile", Err: err})
+				}
+
+				var propagation *dataPropagation
+				if propagating, ok := resource.(propagatingResource); ok && propagating.dataPropagation() != nil {
+					propagation = propagating.dataPropagation()
+					if err := setPropagationSourceMissingCondition(ctx, reconciler, resource.ID(), propagation); err != nil {
+						return ResultInError(errors.Wrap(err, "failed to update propagation source condition"))
+					}
+				}
+
+				// Untyped resources built with GVK candidates use the first version served by the cluster
+				var negotiated *schema.GroupVersionKind
+				negotiator, negotiates := resource.(gvkNegotiator)
+				if negotiates {
+					var err error
+					negotiated, err = negotiateObjectGVK(ctx, reconciler, c.RESTMapper(), resource.ID(), negotiator)
+					if err != nil {
+						return ResultInError(errors.Wrap(err, "failed to negotiate resource version"))
+					}
 				}
 
-				desired, result = getDesiredObject(reconciler, resource)(ctx, req)
+				desired, result = getDesiredObject(reconciler, c, resource)(ctx, req)
 				if result.ShouldReturn() {
 					return result.FromSubStep()
 				}
@@ -58,53 +133,432 @@ func NewReconcileResourceStep[
 				}
 
 				if IsFinalizing(cr) {
-					if err := reconciler.Delete(ctx, desired); client.IgnoreNotFound(err) != nil {
-						return ResultInError(errors.Wrap(err, "failed to delete resource"))
+					_, result := deleteResource(ctx, reconciler, c, resource, desired)
+					if result.ShouldReturn() {
+						return result.FromSubStep()
 					}
 
-					if err := resource.OnFinalize(ctx, desired); err != nil {
-						return ResultInError(errors.Wrap(err, "failed to run OnFinalize hook"))
+					if err := runOperation(ctx, "AfterFinalize", func() error { return resource.OnFinalize(ctx, desired) }); err != nil {
+						return ResultInError(&HookError{ResourceID: resource.ID(), Hook: "AfterFinalize", Err: err})
 					}
 
 					return ResultSuccess()
 				}
 
-				// Setup watch if we can
+				// Setup watch if we can, the watches of the reconciler only cover its own cluster
 				reconcilerWithWatcher, ok := reconciler.(ReconcilerWithWatcher[ControllerResourceType])
-				if ok {
+				if ok && !remote {
+					unlock := LockContext(ctx)
 					result = SetupWatch(reconcilerWithWatcher, desired, false)(ctx, req)
+					unlock()
 					if result.ShouldReturn() {
 						return result.FromSubStep()
 					}
 				}
 
-				patchResult, err := controllerutil.CreateOrPatch(ctx, reconciler, desired, resource.GetMutator(desired))
+				unlock := LockContext(ctx)
+				scheduleErr := scheduleBackgroundReconcile(reconciler, resource, resource.ID(), cr)
+				unlock()
+				if scheduleErr != nil {
+					return ResultInError(errors.Wrapf(scheduleErr, "failed to schedule background reconcile of resource %s", resource.ID()))
+				}
+
+				if negotiated != nil && negotiator.gvkMigrationPolicy() == GVKMigrationPolicyRecreate {
+					if err := migrateObjectGVK(ctx, c, c.RESTMapper(), client.ObjectKeyFromObject(desired), *negotiated, negotiator.gvkCandidates(), resource.DeleteOptions()...); err != nil {
+						return ResultInError(errors.Wrap(err, "failed to migrate resource version"))
+					}
+				}
+
+				rolloutGuard := getRollingUpdateGuard(resource)
+				equal := getEqualityFunc(resource)
+				admissionWebhook := getAdmissionWebhookURL(resource)
+				fieldManager := resource.ServerSideApplyFieldManager()
+
+				// The objects of the cluster of the reconciler are read once per reconciliation, see ObjectCache
+				objectCache, cacheKey := objectCacheKeyFor(reconciler, cr, desired)
+				if remote {
+					objectCache = nil
+				}
+
+				var immutable *immutableFields
+				if immutableResource, ok := resource.(immutableFieldsResource); ok {
+					immutable = immutableResource.immutableFields()
+				}
+				// live is the resource as it exists before the mutation, nil if it does not exist yet
+				var live client.Object
+
+				suspend := getSuspendBehavior(resource)
+				suspended := suspend != nil && IsSuspended(cr)
+
+				mutate := func(obj client.Object) error {
+					// The values saved when the resource was suspended are restored before the mutator runs
+					if suspend != nil && !suspended {
+						if err := resumeObject(obj); err != nil {
+							return errors.Wrap(err, "failed to resume resource")
+						}
+					}
+					reserved := getReservedMetadata(obj)
+					mutator := resource.GetMutator(obj)
+					if lifecycle, ok := resource.(lifecycleMutatorResource); ok {
+						// The live object was read before the mutation, the fresh object of server-side apply has no resource version
+						mutator = lifecycle.lifecycleMutator(obj, live)
+					}
+					if suspended && live != nil && fieldManager == "" {
+						// Suspended resources only get their suspend behavior applied,
+						// server-side apply configurations are built from scratch so they still need the mutator
+						mutator = func() error { return nil }
+					}
+					if err := runOperation(ctx, "Mutate", mutator); err != nil {
+						return &MutatorError{ResourceID: resource.ID(), Err: redactError(reconciler, resource, obj, err)}
+					}
+					// Framework managed metadata can't be overridden by the mutator, whatever it did to the labels and annotations
+					if err := mergeResourceMetadata(obj, reserved, ctx); err != nil {
+						return err
+					}
+					if suspended {
+						if err := runOperation(ctx, "Suspend", func() error { return suspendObject(obj, suspend) }); err != nil {
+							return errors.Wrap(err, "failed to suspend resource")
+						}
+					}
+					// The propagated data and its hash annotation are framework managed, they are set after the mutator
+					if propagation != nil {
+						if err := propagation.apply(obj); err != nil {
+							return errors.Wrap(err, "failed to propagate data")
+						}
+					}
+					if immutable != nil {
+						drifts, err := immutable.enforce(obj, live)
+						if err != nil {
+							return errors.Wrap(err, "failed to enforce immutable fields")
+						}
+						for _, drift := range drifts {
+							message := redactMessage(reconciler, resource, live, redactMessage(reconciler, resource, obj, drift.String()))
+							logger.Info("Immutable field of the resource was modified externally", "drift", message)
+							if recorder, ok := reconciler.(record.EventRecorder); ok {
+								recorder.Eventf(cr, "Warning", "ImmutableFieldModified", "resource %s: %s", resource.ID(), message)
+							}
+						}
+					}
+					if err := setResourceOwnership(ctx, reconciler, logger, resource, obj, remote); err != nil {
+						return err
+					}
+					// Objects the resource considers equal to the live one are not updated, server-side apply configurations
+					// only hold the applied fields so they can't be compared to the live object
+					if equal != nil && fieldManager == "" && live != nil && equal(live, obj) {
+						restoreLiveObject(obj, live)
+					}
+					// Updates are deferred while the resource rolls out, server-side apply configurations
+					// only hold the applied fields so they can't be compared to the live object
+					if rolloutGuard != nil && live != nil && rolloutGuard(live) && (fieldManager != "" || !equality.Semantic.DeepEqual(live, obj)) {
+						return &rollingUpdateInProgressError{resourceID: resource.ID()}
+					}
+					// Policy engines review the writes only, server-side apply configurations are always written
+					if admissionWebhook != "" && (live == nil || fieldManager != "" || !equality.Semantic.DeepEqual(live, obj)) {
+						if err := reviewAdmission(ctx, c, admissionWebhook, obj, live); err != nil {
+							var denied *ValidationError
+							if stderrors.As(err, &denied) {
+								// The message of the webhook may quote the values of the object
+								denied.Reason = redactMessage(reconciler, resource, obj, denied.Reason)
+								return &preMutateValidationError{err: err}
+							}
+							return errors.Wrap(err, "failed to review resource with admission webhook")
+						}
+					}
+					return nil
+				}
+
+				validate := func(existing client.Object) error {
+					if existing != nil {
+						live = existing.DeepCopyObject().(client.Object)
+					}
+					err := runOperation(ctx, "PreMutateValidate", func() error { return resource.ValidatePreMutate(cr, existing) })
+					if err != nil {
+						return &preMutateValidationError{err: err}
+					}
+					return nil
+				}
+
+				// The updates of a resource caught in a reconcile loop are held by the circuit breaker
+				loopDetector := loopDetectorFor(reconciler)
+				stateKey := newResourceStateKey(cr, resource.ID())
+				if hold := loopDetector.hold(stateKey, time.Now()); hold > 0 {
+					logger.Info("Resource is possibly in a reconcile loop, holding its updates", "for", hold)
+					return ResultRequeueIn(hold).WithRequeueReason(RequeueReasonPossibleReconcileLoop)
+				}
+
+				// Resources already reconciled for the generation of the custom resource are left as is,
+				// unless they were modified since or a full reconciliation is forced
+				gated := isGenerationGated(resource)
+				checkGate := gated && !IsForcedReconcile(cr)
+				// Without server-side apply, the external mutations are detected from the hash of the resource
+				mutationPolicy := getExternalMutationPolicy(resource)
+				hashTracked := mutationPolicy != "" && fieldManager == ""
+				// keepLive is true when the live resource is left as is
+				var keepLive bool
+				if checkGate || hashTracked {
+					live := desired.DeepCopyObject().(client.Object)
+					if err := getThroughObjectCache(ctx, c, objectCache, cacheKey, live); client.IgnoreNotFound(err) != nil {
+						return ResultInError(errors.Wrap(err, "failed to get resource"))
+					} else if err == nil {
+						if checkGate {
+							if keepLive, err = generationGateHolds(live, cr.GetGeneration()); err != nil {
+								return ResultInError(errors.Wrap(err, "failed to check generation gate"))
+							}
+							if keepLive {
+								logger.V(1).Info("Resource was already reconciled for this generation, skipping its update")
+							}
+						}
+						if hashTracked && !keepLive {
+							mutated, err := externallyMutated(live)
+							if err != nil {
+								return ResultInError(errors.Wrap(err, "failed to check external mutations"))
+							}
+							if mutated {
+								keepLive = mutationPolicy.keepsMutation(live, cr.GetGeneration())
+								reportExternalMutation(reconciler, logger, cr, resource.ID(), mutationPolicy, keepLive)
+							}
+						}
+					}
+					if keepLive {
+						desired = live
+					}
+				}
+
+				var patchResult controllerutil.OperationResult
+				var err error
+				if keepLive {
+					patchResult = controllerutil.OperationResultNone
+				} else if fieldManager != "" {
+					// Fields owned by other managers are only taken over when external mutations are repaired
+					patchResult, err = applyResource(ctx, c, desired, fieldManager, mutationPolicy == "", validate, mutate)
+					var mutation *externalMutationError
+					if stderrors.As(err, &mutation) {
+						keepLive = mutationPolicy.keepsMutation(mutation.live, cr.GetGeneration())
+						reportExternalMutation(reconciler, logger, cr, resource.ID(), mutationPolicy, keepLive)
+						if keepLive {
+							patchResult, err = controllerutil.OperationResultNone, nil
+							desired = mutation.live
+						} else {
+							patchResult, err = applyResource(ctx, c, desired, fieldManager, true, validate, mutate)
+						}
+					}
+				} else {
+					mutateFn := func() error {
+						// The object is only filled from the cluster when it exists
+						var existing client.Object
+						if desired.GetResourceVersion() != "" {
+							existing = desired
+						}
+						if err := validate(existing); err != nil {
+							return err
+						}
+						return mutate(desired)
+					}
+					if strategy := getPatchStrategy(resource); strategy != "" {
+						patchResult, err = createOrPatchWithStrategy(ctx, c, desired, strategy, mutateFn)
+					} else {
+						patchResult, err = controllerutil.CreateOrPatch(ctx, c, desired, mutateFn)
+					}
+				}
+
+				var validationErr *preMutateValidationError
+				if stderrors.As(err, &validationErr) {
+					var invalid *ValidationError
+					if !stderrors.As(validationErr.err, &invalid) {
+						return ResultInError(errors.Wrap(validationErr.err, "failed to run PreMutateValidator"))
+					}
+
+					logger.Info("Custom resource failed validation, skipping reconciliation", "reason", invalid.Reason)
+					if err := setValidationFailedCondition(ctx, reconciler, resource.ID(), invalid); err != nil {
+						return ResultInError(errors.Wrap(err, "failed to set validation failed condition"))
+					}
+					return ResultEarlyReturn().WithRequeueReason(RequeueReasonResourceInvalid)
+				}
+				var rolloutErr *rollingUpdateInProgressError
+				if stderrors.As(err, &rolloutErr) {
+					logger.Info("Resource is rolling out, deferring its update")
+					if err := setRollingUpdateInProgressCondition(ctx, reconciler, resource.ID(), true); err != nil {
+						return ResultInError(errors.Wrap(err, "failed to set rolling update condition"))
+					}
+					return ResultRequeueIn(10 * time.Second).WithRequeueReason(RequeueReasonRollingUpdateInProgress)
+				}
+				// The errors of the write may quote the values of the object, e.g. when they are invalid
+				err = redactError(reconciler, resource, desired, err)
+				if immutable != nil && immutable.recreate && isImmutableFieldConflict(err) {
+					logger.Info("Resource can't be updated because of an immutable field, recreating it", "reason", err.Error())
+					if recorder, ok := reconciler.(record.EventRecorder); ok {
+						recorder.Eventf(cr, "Warning", "ImmutableFieldConflict", "resource %s is recreated: %v", resource.ID(), err)
+					}
+					if err := c.Delete(ctx, desired, resource.DeleteOptions()...); client.IgnoreNotFound(err) != nil {
+						return ResultInError(errors.Wrap(err, "failed to delete resource to recreate it"))
+					}
+					if objectCache != nil {
+						objectCache.Invalidate(cacheKey)
+					}
+					return ResultRequeueIn(time.Second).WithRequeueReason(RequeueReasonResourceRecreated)
+				}
+				if negotiated != nil && meta.IsNoMatchError(err) {
+					// The negotiated version is not served anymore, negotiate again on the next reconciliation
+					InvalidateGVKNegotiation(c.RESTMapper(), negotiator.gvkCandidates()...)
+				}
 				if err != nil {
 					return ResultInError(errors.Wrap(err, "failed to create or patch resource"))
 				}
+				if (gated || mutationPolicy != "") && !keepLive {
+					if err := stampGenerationGate(ctx, c, desired, cr.GetGeneration()); err != nil {
+						return ResultInError(errors.Wrap(err, "failed to record reconciled generation"))
+					}
+				}
+
+				if err := setValidationFailedCondition(ctx, reconciler, resource.ID(), nil); err != nil {
+					return ResultInError(errors.Wrap(err, "failed to remove validation failed condition"))
+				}
+				if rolloutGuard != nil {
+					if err := setRollingUpdateInProgressCondition(ctx, reconciler, resource.ID(), false); err != nil {
+						return ResultInError(errors.Wrap(err, "failed to remove rolling update condition"))
+					}
+				}
+
+				if err := resource.Set(desired); err != nil {
+					return ResultInError(err)
+				}
+				if objectCache != nil {
+					objectCache.Put(cacheKey, desired)
+				}
 
-				resource.Set(desired)
+				var loopBackoff time.Duration
+				if patchResult == controllerutil.OperationResultUpdated {
+					hash, err := hashUpdatedObject(desired)
+					if err != nil {
+						return ResultInError(errors.Wrap(err, "failed to hash updated resource"))
+					}
+					loopBackoff = loopDetector.recordUpdate(stateKey, hash, time.Now())
+				}
+				if loopBackoff > 0 || !loopDetector.tripped(stateKey, time.Now()) {
+					if err := setPossibleReconcileLoopCondition(ctx, reconciler, resource.ID(), loopBackoff); err != nil {
+						return ResultInError(errors.Wrap(err, "failed to update reconcile loop condition"))
+					}
+				}
 
+				operation = patchResult
 				switch patchResult {
 				case controllerutil.OperationResultCreated:
-					if err := resource.OnCreate(ctx, desired); err != nil {
-						return ResultInError(errors.Wrap(err, "failed to run OnCreate hook"))
+					recordLifecycleEvent(reconciler, resource, cr, "ResourceCreated", "created")
+					if err := runOperation(ctx, "AfterCreate", func() error { return resource.OnCreate(ctx, desired) }); err != nil {
+						return ResultInError(&HookError{ResourceID: resource.ID(), Hook: "AfterCreate", Err: redactError(reconciler, resource, desired, err)})
 					}
 				case controllerutil.OperationResultUpdated:
-					if err := resource.OnUpdate(ctx, desired); err != nil {
-						return ResultInError(errors.Wrap(err, "failed to run OnUpdate hook"))
+					recordLifecycleEvent(reconciler, resource, cr, "ResourceUpdated", "updated")
+					if err := runOperation(ctx, "AfterUpdate", func() error { return resource.OnUpdate(ctx, desired) }); err != nil {
+						return ResultInError(&HookError{ResourceID: resource.ID(), Hook: "AfterUpdate", Err: redactError(reconciler, resource, desired, err)})
 					}
 				}
 
-				if !resource.IsReady(desired) {
-					return ResultEarlyReturn()
+				if loopBackoff > 0 {
+					logger.Info("Resource is possibly in a reconcile loop, holding its updates", "for", loopBackoff)
+					return ResultRequeueIn(loopBackoff).WithRequeueReason(RequeueReasonPossibleReconcileLoop)
+				}
+
+				// The subresources are written once the resource is reconciled, only when they drifted
+				written, result := reconcileSubresources(ctx, reconciler, c, resource, cr, desired, suspended)
+				if result.ShouldReturn() {
+					return result
+				}
+				if written && objectCache != nil {
+					objectCache.Put(cacheKey, desired)
+				}
+
+				// A managed PodDisruptionBudget shares the lifecycle and readiness of its Deployment
+				pdbReady := true
+				if pdbResource, ok := resource.(managedPDBResource); ok {
+					spec, managed, err := pdbResource.managedPDBSpec(desired)
+					if err != nil {
+						return ResultInError(err)
+					}
+					if managed {
+						pdbReady, err = reconcileManagedPDB(ctx, c, reconciler.Scheme(), desired, spec)
+						if err != nil {
+							return ResultInError(err)
+						}
+					}
+				}
+
+				if err := updateResourceStatusField(ctx, reconciler, resource, desired); err != nil {
+					return ResultInError(errors.Wrap(err, "failed to update resource status"))
+				}
+
+				if suspend != nil {
+					if err := setSuspendedCondition(ctx, reconciler, suspended); err != nil {
+						return ResultInError(errors.Wrap(err, "failed to update suspended condition"))
+					}
+				}
+
+				// Suspended resources are not expected to be ready, they are reported by the Suspended condition
+				ready, reason, message := resource.ReadinessReason(desired)
+				if ready || suspended {
+					reason = ""
+				}
+				message = redactMessage(reconciler, resource, desired, message)
+				if err := setResourceNotReadyCondition(ctx, reconciler, resource.ID(), reason, message); err != nil {
+					return ResultInError(errors.Wrap(err, "failed to update ready condition"))
+				}
+				if !ready && !suspended {
+					if backoff := getNotReadyBackoff(resource); backoff != nil {
+						return ResultRequeueIn(backoff.next(stateKey, time.Now())).WithRequeueReason(RequeueReasonResourceNotReady)
+					}
+					if remote {
+						// The resources of a remote cluster are not watched
+						return ResultRequeueIn(remoteClusterRequeueInterval).WithRequeueReason(RequeueReasonResourceNotReady)
+					}
+					return ResultEarlyReturn().WithRequeueReason(RequeueReasonResourceNotReady)
+				}
+
+				if !pdbReady {
+					if backoff := getNotReadyBackoff(resource); backoff != nil {
+						return ResultRequeueIn(backoff.next(stateKey, time.Now())).WithRequeueReason(RequeueReasonResourceNotReady)
+					}
+					// The PodDisruptionBudget is owned by the Deployment, it is not watched
+					return ResultRequeueIn(5 * time.Second).WithRequeueReason(RequeueReasonResourceNotReady)
+				}
+
+				if getNotReadyBackoff(resource) != nil {
+					resetNotReadyBackoff(stateKey)
 				}
 
 				return ResultSuccess()
 			}()
 
-			if err := resource.AfterReconcile(ctx, desired); err != nil {
-				return ResultInError(errors.Wrap(err, "failed to run AfterReconcile hook"))
+			report := ResourceReport{
+				ID:        resource.ID(),
+				Kind:      resource.Kind(),
+				Operation: operation,
+				Ready:     !funcResult.ShouldReturn(),
+				Error:     funcResult.err,
+			}
+			if desired != nil {
+				report.Key = client.ObjectKeyFromObject(desired)
+			}
+			ctx.GetReconcileReport().recordResource(report)
+
+			if err := runOperation(ctx, "AfterReconcile", func() error { return resource.AfterReconcile(ctx, desired) }); err != nil {
+				err = redactError(reconciler, resource, desired, err)
+				switch resource.AfterReconcileErrorPolicy() {
+				case HookErrorPolicyContinueAndIgnore:
+					logger.Error(err, "AfterReconcile hook failed, ignoring as per error policy")
+				case HookErrorPolicyContinueAndAggregate:
+					if funcResult.err != nil {
+						return funcResult
+					}
+					// The requeue of a resource that is not ready is dropped, controller-runtime ignoring the result
+					// of a reconciliation in error: the backoff of the hook error checks it again instead
+					return ResultInError(&continuedHookError{&HookError{
+						ResourceID: resource.ID(),
+						Hook:       "AfterReconcile",
+						Err:        err,
+					}})
+				default:
+					return ResultInError(&HookError{ResourceID: resource.ID(), Hook: "AfterReconcile", Err: err})
+				}
 			}
 
 			return funcResult
@@ -117,22 +571,28 @@ func getDesiredObject[
 	ContextType Context[ControllerResourceType],
 ](
 	reconciler Reconciler[ControllerResourceType],
+	c client.Client,
 	resource GenericResource[ControllerResourceType, ContextType],
 ) func(ctx ContextType, req ctrl.Request) (client.Object, StepResult) {
 	return func(ctx ContextType, req ctrl.Request) (client.Object, StepResult) {
-		desired, delete, err := resource.ObjectMetaGenerator()
+		desired, delete, err := generateResourceObject(ctx, resource)
 		if delete {
 			if desired != nil && desired.GetName() != "" {
-				err := reconciler.Delete(ctx, desired)
-				if client.IgnoreNotFound(err) != nil {
-					return nil, ResultInError(errors.Wrap(err, "failed to delete resource"))
+				deleted, result := deleteResource(ctx, reconciler, c, resource, desired)
+				if result.ShouldReturn() {
+					return nil, result
 				}
 
-				if err == nil {
-					if err := resource.OnDelete(ctx, desired); err != nil {
-						return nil, ResultInError(errors.Wrap(err, "failed to run OnDelete hook"))
+				if deleted {
+					recordLifecycleEvent(reconciler, resource, ctx.GetCustomResource(), "ResourceDeleted", "deleted")
+					if err := runOperation(ctx, "AfterDelete", func() error { return resource.OnDelete(ctx, desired) }); err != nil {
+						return nil, ResultInError(&HookError{ResourceID: resource.ID(), Hook: "AfterDelete", Err: err})
 					}
 				}
+
+				if err := updateResourceStatusField(ctx, reconciler, resource, nil); err != nil {
+					return nil, ResultInError(errors.Wrap(err, "failed to clear resource status"))
+				}
 			}
 			return nil, ResultEarlyReturn()
 		}
@@ -143,3 +603,392 @@ func getDesiredObject[
 		return desired, ResultSuccess()
 	}
 }
+
+// applyResource reconciles desired using server-side apply, the apply configuration being built by the mutator
+// from an object holding only the identity of the resource. Only untyped resources are supported.
+//
+// Unless force is true, an externalMutationError is returned when the apply conflicts with the fields of another manager.
+func applyResource(
+	ctx context.Context,
+	c client.Client,
+	desired client.Object,
+	fieldManager string,
+	force bool,
+	validate func(existing client.Object) error,
+	mutate func(obj client.Object) error,
+) (controllerutil.OperationResult, error) {
+	desiredUnstructured, ok := desired.(*unstructured.Unstructured)
+	if !ok {
+		return controllerutil.OperationResultNone, fmt.Errorf("server-side apply is only supported for untyped resources, got %T", desired)
+	}
+
+	existing := &unstructured.Unstructured{}
+	existing.SetGroupVersionKind(desiredUnstructured.GroupVersionKind())
+	err := c.Get(ctx, client.ObjectKeyFromObject(desired), existing)
+	if client.IgnoreNotFound(err) != nil {
+		return controllerutil.OperationResultNone, err
+	}
+	exists := err == nil
+
+	if exists {
+		err = validate(existing)
+	} else {
+		err = validate(nil)
+	}
+	if err != nil {
+		return controllerutil.OperationResultNone, err
+	}
+
+	applyConfiguration := &unstructured.Unstructured{}
+	applyConfiguration.SetGroupVersionKind(desiredUnstructured.GroupVersionKind())
+	applyConfiguration.SetName(desired.GetName())
+	applyConfiguration.SetNamespace(desired.GetNamespace())
+
+	if err := mutate(applyConfiguration); err != nil {
+		return controllerutil.OperationResultNone, err
+	}
+
+	opts := []client.PatchOption{client.FieldOwner(fieldManager)}
+	if force {
+		opts = append(opts, client.ForceOwnership)
+	}
+	if err := c.Patch(ctx, applyConfiguration, client.Apply, opts...); err != nil {
+		if !force && exists && apierrors.IsConflict(err) {
+			return controllerutil.OperationResultNone, &externalMutationError{live: existing, err: err}
+		}
+		return controllerutil.OperationResultNone, err
+	}
+
+	// The apply response holds the whole object, including the fields of other managers
+	*desiredUnstructured = *applyConfiguration
+
+	switch {
+	case !exists:
+		return controllerutil.OperationResultCreated, nil
+	case existing.GetResourceVersion() != applyConfiguration.GetResourceVersion():
+		return controllerutil.OperationResultUpdated, nil
+	default:
+		return controllerutil.OperationResultNone, nil
+	}
+}
+
+// deleteResource deletes the live version of obj using c, running the BeforeDelete hook right before the delete request.
+// It returns true if the resource was deleted, false if it did not exist or if its deletion was skipped.
+func deleteResource[
+	ControllerResourceType ControllerCustomResource,
+	ContextType Context[ControllerResourceType],
+](
+	ctx ContextType,
+	reconciler Reconciler[ControllerResourceType],
+	c client.Client,
+	resource GenericResource[ControllerResourceType, ContextType],
+	obj client.Object,
+) (bool, StepResult) {
+	live := obj.DeepCopyObject().(client.Object)
+	if err := c.Get(ctx, client.ObjectKeyFromObject(obj), live); err != nil {
+		if apierrors.IsNotFound(err) {
+			return false, ResultSuccess()
+		}
+		return false, ResultInError(errors.Wrap(err, "failed to get resource before deletion"))
+	}
+
+	if isSharedResource(resource) {
+		released, err := releaseSharedResource(ctx, c, live, ctx.GetCustomResource())
+		if err != nil {
+			return false, ResultInError(errors.Wrap(err, "failed to release shared resource"))
+		}
+		if released {
+			return false, ResultSuccess()
+		}
+	}
+
+	if err := runOperation(ctx, "BeforeDelete", func() error { return resource.OnBeforeDelete(ctx, live) }); err != nil {
+		var skipErr *SkipDeletionError
+		if !stderrors.As(err, &skipErr) {
+			return false, ResultInError(&HookError{ResourceID: resource.ID(), Hook: "BeforeDelete", Err: err})
+		}
+
+		if err := setDeletionSkippedCondition(ctx, reconciler, resource.ID(), skipErr); err != nil {
+			return false, ResultInError(errors.Wrap(err, "failed to set deletion skipped condition"))
+		}
+		if IsFinalizing(ctx.GetCustomResource()) {
+			return false, ResultInError(&FinalizationBlockedError{ResourceIDs: []string{resource.ID()}, Err: skipErr})
+		}
+		return false, ResultRequeueIn(30 * time.Second).WithRequeueReason(RequeueReasonDeletionSkipped)
+	}
+
+	if err := c.Delete(ctx, live, resource.DeleteOptions()...); err != nil {
+		if apierrors.IsNotFound(err) {
+			return false, ResultSuccess()
+		}
+		return false, ResultInError(errors.Wrap(err, "failed to delete resource"))
+	}
+	if cache, cacheKey := objectCacheKeyFor(reconciler, ctx.GetCustomResource(), live); cache != nil {
+		cache.Invalidate(cacheKey)
+	}
+
+	if err := setDeletionSkippedCondition(ctx, reconciler, resource.ID(), nil); err != nil {
+		return true, ResultInError(errors.Wrap(err, "failed to remove deletion skipped condition"))
+	}
+
+	return true, ResultSuccess()
+}
+
+// contextualSkipResource is implemented by the resources that can be built with WithSkipAndDeleteOnConditionFunc.
+type contextualSkipResource[ContextType any] interface {
+	shouldDeleteFor(ctx ContextType) (bool, error)
+}
+
+// generateResourceObject returns the object of the resource and whether it must be skipped and deleted,
+// like ObjectMetaGenerator, also evaluating the skip conditions depending on the context.
+func generateResourceObject[
+	ControllerResourceType ControllerCustomResource,
+	ContextType Context[ControllerResourceType],
+](
+	ctx ContextType,
+	resource GenericResource[ControllerResourceType, ContextType],
+) (client.Object, bool, error) {
+	obj, skip, err := resource.ObjectMetaGenerator()
+	if err != nil || skip {
+		return obj, skip, err
+	}
+
+	if contextual, ok := resource.(contextualSkipResource[ContextType]); ok {
+		skip, err = contextual.shouldDeleteFor(ctx)
+		if err != nil {
+			return nil, false, errors.Wrapf(err, "failed to evaluate skip condition of resource %s", resource.ID())
+		}
+	}
+
+	return obj, skip, nil
+}
+
+// lifecycleMutatorResource is implemented by the resources that can be built with WithCreateMutator and WithUpdateMutator.
+type lifecycleMutatorResource interface {
+	lifecycleMutator(obj client.Object, live client.Object) func() error
+}
+
+// buildValidatedResource is implemented by the resources whose configuration is validated by Build().
+type buildValidatedResource interface {
+	buildError() error
+}
+
+// getBuildError returns the validation error of the configuration of the resource, if any.
+func getBuildError(resource any) error {
+	if validated, ok := resource.(buildValidatedResource); ok {
+		return validated.buildError()
+	}
+	return nil
+}
+
+// setResourceOwnership sets the owner references and the tracking labels of obj, the desired object of the resource:
+// shared resources get an owner reference to the custom resource, the other ones are tracked and get it as controller,
+// unless their owner references are blocked or they are reconciled in a remote cluster.
+func setResourceOwnership[
+	ControllerResourceType ControllerCustomResource,
+	ContextType Context[ControllerResourceType],
+](
+	ctx ContextType,
+	reconciler Reconciler[ControllerResourceType],
+	logger logr.Logger,
+	resource GenericResource[ControllerResourceType, ContextType],
+	obj client.Object,
+	remote bool,
+) error {
+	cr := ctx.GetCustomResource()
+
+	if resource.OwnerReferenceBlocked() && isOwnedBy(obj, cr) {
+		logger.Info("Resource has owner references blocked but is owned by the custom resource, it will be garbage collected along with it")
+	}
+	if isSharedResource(resource) {
+		// Shared resources have several owners, they can't be tracked for a single one
+		return controllerutil.SetOwnerReference(cr, obj, reconciler.Scheme())
+	}
+	// Tracking labels allow the prune step to find resources that are not declared anymore
+	if err := trackResource(ctx, obj, reconciler.Scheme()); err != nil {
+		return err
+	}
+	if !remote && !resource.OwnerReferenceBlocked() && setsControllerReference(resource) {
+		// Owner references don't work across clusters, the resources of a remote cluster are deleted on finalization
+		set, err := setAutomaticControllerReference(obj, cr, reconciler.Scheme())
+		if err != nil {
+			return errors.Wrap(err, "failed to set controller reference")
+		}
+		if !set {
+			logger.V(1).Info("Custom resource can't be set as the controller of the resource, it won't be garbage collected along with it")
+		}
+	}
+	return nil
+}
+
+// sharedResource is implemented by the resources that can be built with WithSharedOwnership.
+type sharedResource interface {
+	isShared() bool
+}
+
+func isSharedResource(resource any) bool {
+	shared, ok := resource.(sharedResource)
+	return ok && shared.isShared()
+}
+
+// releaseSharedResource removes the owner reference of cr from a shared resource, and tells whether other owners
+// remain, in which case the resource must not be deleted.
+func releaseSharedResource(ctx context.Context, c client.Client, obj client.Object, cr client.Object) (bool, error) {
+	var owners []metav1.OwnerReference
+	for _, ref := range obj.GetOwnerReferences() {
+		if ref.UID != cr.GetUID() {
+			owners = append(owners, ref)
+		}
+	}
+	if len(owners) == 0 {
+		return false, nil
+	}
+	if len(owners) == len(obj.GetOwnerReferences()) {
+		return true, nil
+	}
+
+	clean := obj.DeepCopyObject().(client.Object)
+	obj.SetOwnerReferences(owners)
+	if err := c.Patch(ctx, obj, client.MergeFrom(clean)); client.IgnoreNotFound(err) != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// setDeletionSkippedCondition reflects a vetoed deletion on the custom resource status, the resource being removed
+// from the condition once its deletion goes through, see applyResourceCondition.
+func setDeletionSkippedCondition[
+	ControllerResourceType ControllerCustomResource,
+	ContextType Context[ControllerResourceType],
+](
+	ctx ContextType,
+	reconciler Reconciler[ControllerResourceType],
+	resourceID string,
+	skipErr *SkipDeletionError,
+) error {
+	defer LockContext(ctx)()
+
+	cr := ctx.GetCustomResource()
+
+	if skipErr == nil {
+		_, err := applyResourceCondition(ctx, reconciler, ConditionTypeDeletionSkipped, resourceID, nil, "")
+		return err
+	}
+
+	_, err := applyResourceCondition(ctx, reconciler, ConditionTypeDeletionSkipped, resourceID, &metav1.Condition{
+		Status:             metav1.ConditionTrue,
+		Reason:             "BeforeDeleteHookVeto",
+		Message:            fmt.Sprintf("deletion skipped: %s", skipErr.Reason),
+		ObservedGeneration: cr.GetGeneration(),
+	}, "")
+	return err
+}
+
+// setResourceNotReadyCondition reflects a resource that is not ready on the Ready condition of the custom resource,
+// along with the other resources that are not ready, see applyResourceCondition. The resource is removed from the
+// condition when reason is empty. An event is emitted when the condition changes.
+func setResourceNotReadyCondition[
+	ControllerResourceType ControllerCustomResource,
+	ContextType Context[ControllerResourceType],
+](
+	ctx ContextType,
+	reconciler Reconciler[ControllerResourceType],
+	resourceID string,
+	reason string,
+	message string,
+) error {
+	defer LockContext(ctx)()
+
+	cr := ctx.GetCustomResource()
+
+	if reason == "" {
+		_, err := applyResourceCondition(ctx, reconciler, ConditionTypeReady, resourceID, nil, "")
+		return err
+	}
+
+	message = fmt.Sprintf("not ready (%s): %s", reason, message)
+	changed, err := applyResourceCondition(ctx, reconciler, ConditionTypeReady, resourceID, &metav1.Condition{
+		Status:             metav1.ConditionFalse,
+		Reason:             reason,
+		Message:            message,
+		ObservedGeneration: cr.GetGeneration(),
+	}, "ResourcesNotReady")
+	if changed {
+		if recorder, ok := reconciler.(record.EventRecorder); ok {
+			recorder.Eventf(cr, "Normal", reason, "resource %s is %s", resourceID, message)
+		}
+	}
+	return err
+}
+
+// updateResourceStatusField populates the status sub-object of the resource, see ResourceBuilder.WithStatusField.
+func updateResourceStatusField[
+	ControllerResourceType ControllerCustomResource,
+	ContextType Context[ControllerResourceType],
+](
+	ctx ContextType,
+	reconciler Reconciler[ControllerResourceType],
+	resource GenericResource[ControllerResourceType, ContextType],
+	obj client.Object,
+) error {
+	defer LockContext(ctx)()
+
+	if !resource.UpdateStatusField(ctx.GetCustomResource(), obj) {
+		return nil
+	}
+
+	return PatchCustomResourceStatus(ctx, reconciler)
+}
+
+// preMutateValidationError marks the errors returned by the pre-mutate validator,
+// so they can be told apart from the errors of the write itself.
+type preMutateValidationError struct {
+	err error
+}
+
+func (e *preMutateValidationError) Error() string {
+	return e.err.Error()
+}
+
+func (e *preMutateValidationError) Unwrap() error {
+	return e.err
+}
+
+// setValidationFailedCondition reflects a custom resource rejected by the validator of a resource on its status,
+// the resource being removed from the condition once it gets reconciled, see applyResourceCondition.
+func setValidationFailedCondition[
+	ControllerResourceType ControllerCustomResource,
+	ContextType Context[ControllerResourceType],
+](
+	ctx ContextType,
+	reconciler Reconciler[ControllerResourceType],
+	resourceID string,
+	validationErr *ValidationError,
+) error {
+	defer LockContext(ctx)()
+
+	cr := ctx.GetCustomResource()
+
+	if validationErr == nil {
+		_, err := applyResourceCondition(ctx, reconciler, ConditionTypeValidationFailed, resourceID, nil, "")
+		return err
+	}
+
+	_, err := applyResourceCondition(ctx, reconciler, ConditionTypeValidationFailed, resourceID, &metav1.Condition{
+		Status:             metav1.ConditionTrue,
+		Reason:             "PreMutateValidationFailed",
+		Message:            validationErr.Reason,
+		ObservedGeneration: cr.GetGeneration(),
+	}, "")
+	return err
+}
+
+func isOwnedBy(obj client.Object, owner client.Object) bool {
+	for _, ref := range obj.GetOwnerReferences() {
+		if ref.UID == owner.GetUID() {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,2150 @@
+package ctrlfwk_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/go-logr/logr/funcr"
+	ctrlfwk "github.com/u-ctf/controller-fwk"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+)
+
+type fakeReconciler struct {
+	client.Client
+}
+
+func (fakeReconciler) For(*corev1.ConfigMap) {}
+
+func newDeletionTest(t *testing.T, funcs interceptor.Funcs) (ctrlfwk.Context[*corev1.ConfigMap], *fakeReconciler) {
+	t.Helper()
+
+	cr := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "cr", Namespace: "default"}}
+	secret := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "secret", Namespace: "default"}}
+
+	reconciler := &fakeReconciler{
+		Client: fake.NewClientBuilder().WithObjects(cr, secret).WithInterceptorFuncs(funcs).Build(),
+	}
+
+	ctx := ctrlfwk.NewContext(context.Background(), reconciler)
+	ctx.SetCustomResource(cr)
+
+	return ctx, reconciler
+}
+
+func newSkippedSecretResource(ctx ctrlfwk.Context[*corev1.ConfigMap], beforeDelete func(ctx ctrlfwk.Context[*corev1.ConfigMap], secret *corev1.Secret) error) ctrlfwk.GenericResource[*corev1.ConfigMap, ctrlfwk.Context[*corev1.ConfigMap]] {
+	return ctrlfwk.NewResourceBuilder(ctx, &corev1.Secret{}).
+		WithKey(types.NamespacedName{Name: "secret", Namespace: "default"}).
+		WithSkipAndDeleteOnCondition(func() bool { return true }).
+		WithBeforeDelete(beforeDelete).
+		Build()
+}
+
+func TestReconcileResourceStep_BeforeDeleteIsCalledBeforeEachDeleteAttempt(t *testing.T) {
+	var calls []string
+	deleteAttempts := 0
+
+	ctx, reconciler := newDeletionTest(t, interceptor.Funcs{
+		Delete: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.DeleteOption) error {
+			calls = append(calls, "delete")
+			deleteAttempts++
+			if deleteAttempts == 1 {
+				return errors.New("delete failed")
+			}
+			return c.Delete(ctx, obj, opts...)
+		},
+	})
+
+	resource := newSkippedSecretResource(ctx, func(_ ctrlfwk.Context[*corev1.ConfigMap], secret *corev1.Secret) error {
+		if secret.GetResourceVersion() == "" {
+			t.Fatal("expected the hook to receive the live object")
+		}
+		calls = append(calls, "beforeDelete")
+		return nil
+	})
+
+	step := ctrlfwk.NewReconcileResourceStep(ctx, reconciler, resource)
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Name: "cr", Namespace: "default"}}
+
+	if _, err := step.Step(ctx, logr.Discard(), req).Normal(); err == nil {
+		t.Fatal("expected the first deletion attempt to fail")
+	}
+	if _, err := step.Step(ctx, logr.Discard(), req).Normal(); err != nil {
+		t.Fatalf("unexpected error on retry: %v", err)
+	}
+
+	expected := []string{"beforeDelete", "delete", "beforeDelete", "delete"}
+	if len(calls) != len(expected) {
+		t.Fatalf("expected calls %v, got %v", expected, calls)
+	}
+	for i := range expected {
+		if calls[i] != expected[i] {
+			t.Fatalf("expected calls %v, got %v", expected, calls)
+		}
+	}
+
+	err := reconciler.Get(ctx, types.NamespacedName{Name: "secret", Namespace: "default"}, &corev1.Secret{})
+	if !apierrors.IsNotFound(err) {
+		t.Fatalf("expected the secret to be deleted, got %v", err)
+	}
+}
+
+func TestReconcileResourceStep_BeforeDeleteCanSkipDeletion(t *testing.T) {
+	deleted := false
+
+	ctx, reconciler := newDeletionTest(t, interceptor.Funcs{
+		Delete: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.DeleteOption) error {
+			deleted = true
+			return c.Delete(ctx, obj, opts...)
+		},
+	})
+
+	resource := newSkippedSecretResource(ctx, func(_ ctrlfwk.Context[*corev1.ConfigMap], _ *corev1.Secret) error {
+		return ctrlfwk.SkipDeletion("backup is not done yet")
+	})
+
+	step := ctrlfwk.NewReconcileResourceStep(ctx, reconciler, resource)
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Name: "cr", Namespace: "default"}}
+
+	result, err := step.Step(ctx, logr.Discard(), req).Normal()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.RequeueAfter == 0 {
+		t.Fatal("expected a requeue when the deletion is skipped")
+	}
+	if deleted {
+		t.Fatal("expected the deletion to be skipped")
+	}
+}
+
+func TestReconcileResourceStep_DeletionSkippedIsReportedPerResource(t *testing.T) {
+	ctx, reconciler := newConditionsTest(t)
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Name: "cr", Namespace: "default"}}
+
+	vetoes := map[string]string{"vetoed": "backup is not done yet"}
+	resource := func(name string) ctrlfwk.GenericResource[*conditionsCR, conditionsContext] {
+		if err := reconciler.Create(ctx, &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"}}); err != nil {
+			t.Fatalf("failed to create secret: %v", err)
+		}
+		return ctrlfwk.NewResourceBuilder(ctx, &corev1.Secret{}).
+			WithKey(types.NamespacedName{Name: name, Namespace: "default"}).
+			WithSkipAndDeleteOnCondition(func() bool { return true }).
+			WithBeforeDelete(func(conditionsContext, *corev1.Secret) error {
+				if reason, ok := vetoes[name]; ok {
+					return ctrlfwk.SkipDeletion(reason)
+				}
+				return nil
+			}).
+			Build()
+	}
+	vetoed, deleted := resource("vetoed"), resource("deleted")
+
+	ctrlfwk.NewReconcileResourceStep(ctx, reconciler, vetoed).Step(ctx, logr.Discard(), req)
+	if result := ctrlfwk.NewReconcileResourceStep(ctx, reconciler, deleted).Step(ctx, logr.Discard(), req); result.ShouldReturn() {
+		t.Fatalf("unexpected result: %v", result)
+	}
+
+	// The deletion of another resource doesn't clear the veto
+	condition := meta.FindStatusCondition(ctx.GetCustomResource().Status.Conditions, ctrlfwk.ConditionTypeDeletionSkipped)
+	if condition == nil || condition.Message != "resource Secret,default/vetoed: deletion skipped: backup is not done yet" {
+		t.Fatalf("expected the veto to be reported, got %v", condition)
+	}
+
+	delete(vetoes, "vetoed")
+	if result := ctrlfwk.NewReconcileResourceStep(ctx, reconciler, vetoed).Step(ctx, logr.Discard(), req); result.ShouldReturn() {
+		t.Fatalf("unexpected result: %v", result)
+	}
+	if condition := meta.FindStatusCondition(ctx.GetCustomResource().Status.Conditions, ctrlfwk.ConditionTypeDeletionSkipped); condition != nil {
+		t.Fatalf("expected the condition to be removed once the deletion goes through, got %v", condition)
+	}
+}
+
+func TestReconcileResourceStep_SkipAndDeleteOnConditionFunc(t *testing.T) {
+	deleted := false
+
+	ctx, reconciler := newDeletionTest(t, interceptor.Funcs{
+		Delete: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.DeleteOption) error {
+			deleted = true
+			return c.Delete(ctx, obj, opts...)
+		},
+	})
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Name: "cr", Namespace: "default"}}
+
+	failing := ctrlfwk.NewResourceBuilder(ctx, &corev1.Secret{}).
+		WithKey(types.NamespacedName{Name: "secret", Namespace: "default"}).
+		WithSkipAndDeleteOnConditionFunc(func(_ ctrlfwk.Context[*corev1.ConfigMap]) (bool, error) {
+			return false, errors.New("condition failed")
+		}).
+		Build()
+
+	if _, err := ctrlfwk.NewReconcileResourceStep(ctx, reconciler, failing).Step(ctx, logr.Discard(), req).Normal(); err == nil {
+		t.Fatal("expected the condition error to abort the reconciliation")
+	}
+	if deleted {
+		t.Fatal("expected nothing to be deleted when the condition fails")
+	}
+
+	resource := ctrlfwk.NewResourceBuilder(ctx, &corev1.Secret{}).
+		WithKey(types.NamespacedName{Name: "secret", Namespace: "default"}).
+		WithSkipAndDeleteOnConditionFunc(func(ctx ctrlfwk.Context[*corev1.ConfigMap]) (bool, error) {
+			return ctx.GetCustomResource().GetName() == "cr", nil
+		}).
+		Build()
+
+	if _, err := ctrlfwk.NewReconcileResourceStep(ctx, reconciler, resource).Step(ctx, logr.Discard(), req).Normal(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !deleted {
+		t.Fatal("expected the resource to be deleted when the condition is true")
+	}
+}
+
+func TestReconcileResourceStep_DeleteOptions(t *testing.T) {
+	deleteOptions := map[string]*client.DeleteOptions{}
+	ctx, reconciler := newDeletionTest(t, interceptor.Funcs{
+		Delete: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.DeleteOption) error {
+			deleteOptions[obj.GetName()] = (&client.DeleteOptions{}).ApplyOptions(opts)
+			return c.Delete(ctx, obj, opts...)
+		},
+	})
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Name: "cr", Namespace: "default"}}
+
+	// Skipped resources are deleted with their options
+	skipped := ctrlfwk.NewResourceBuilder(ctx, &corev1.Secret{}).
+		WithKey(types.NamespacedName{Name: "secret", Namespace: "default"}).
+		WithSkipAndDeleteOnCondition(func() bool { return true }).
+		WithDeletePropagationPolicy(metav1.DeletePropagationForeground).
+		WithDeleteGracePeriodSeconds(30).
+		Build()
+	if _, err := ctrlfwk.NewReconcileResourceStep(ctx, reconciler, skipped).Step(ctx, logr.Discard(), req).Normal(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	options := deleteOptions["secret"]
+	if options == nil || options.PropagationPolicy == nil || *options.PropagationPolicy != metav1.DeletePropagationForeground ||
+		options.GracePeriodSeconds == nil || *options.GracePeriodSeconds != 30 {
+		t.Fatalf("expected the skipped resource to be deleted with its options, got %+v", options)
+	}
+
+	// Resources requiring manual deletion are deleted with their options on finalization
+	finalized := ctrlfwk.NewResourceBuilder(ctx, &corev1.ConfigMap{}).
+		WithKey(types.NamespacedName{Name: "app", Namespace: "default"}).
+		WithRequireManualDeletionForFinalize(func(*corev1.ConfigMap) bool { return true }).
+		WithDeletePropagationPolicy(metav1.DeletePropagationOrphan).
+		WithReadinessCondition(func(*corev1.ConfigMap) bool { return true }).
+		Build()
+	step := ctrlfwk.NewReconcileResourceStep(ctx, reconciler, finalized)
+	if _, err := step.Step(ctx, logr.Discard(), req).Normal(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	ctx.GetCustomResource().SetDeletionTimestamp(&metav1.Time{Time: time.Now()})
+	if _, err := step.Step(ctx, logr.Discard(), req).Normal(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	options = deleteOptions["app"]
+	if options == nil || options.PropagationPolicy == nil || *options.PropagationPolicy != metav1.DeletePropagationOrphan || options.GracePeriodSeconds != nil {
+		t.Fatalf("expected the finalized resource to be deleted with its options, got %+v", options)
+	}
+
+	// Resources without options are deleted with the defaults of the API server
+	ctx.GetCustomResource().SetDeletionTimestamp(nil)
+	delete(deleteOptions, "app")
+	defaults := ctrlfwk.NewResourceBuilder(ctx, &corev1.ConfigMap{}).
+		WithKey(types.NamespacedName{Name: "app", Namespace: "default"}).
+		WithSkipAndDeleteOnCondition(func() bool { return true }).
+		Build()
+	if err := reconciler.Create(ctx, &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: "default"}}); err != nil {
+		t.Fatalf("failed to create configmap: %v", err)
+	}
+	if _, err := ctrlfwk.NewReconcileResourceStep(ctx, reconciler, defaults).Step(ctx, logr.Discard(), req).Normal(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	options = deleteOptions["app"]
+	if options == nil || options.PropagationPolicy != nil || options.GracePeriodSeconds != nil {
+		t.Fatalf("expected the resource to be deleted with the default options, got %+v", options)
+	}
+}
+
+func TestReconcileResourceStep_DeleteGracePeriodAfterScaleDown(t *testing.T) {
+	var calls []string
+	ctx, reconciler := newDeletionTest(t, interceptor.Funcs{
+		Delete: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.DeleteOption) error {
+			options := (&client.DeleteOptions{}).ApplyOptions(opts)
+			if options.GracePeriodSeconds == nil || *options.GracePeriodSeconds != 30 {
+				t.Errorf("expected the grace period of the resource, got %+v", options)
+			}
+			calls = append(calls, "delete")
+			return c.Delete(ctx, obj, opts...)
+		},
+	})
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Name: "cr", Namespace: "default"}}
+	key := types.NamespacedName{Name: "app", Namespace: "default"}
+
+	// The workload is scaled down and waited for before being deleted by the framework
+	drained := errors.New("waiting for the pods to drain")
+	resource := ctrlfwk.NewResourceBuilder(ctx, &appsv1.Deployment{}).
+		WithKey(key).
+		WithMutator(func(deployment *appsv1.Deployment) error {
+			deployment.Spec.Replicas = ptr.To(int32(3))
+			return nil
+		}).
+		WithRequireManualDeletionForFinalize(func(*appsv1.Deployment) bool { return true }).
+		WithDeleteGracePeriodSeconds(30).
+		WithBeforeReconcile(func(ctx ctrlfwk.Context[*corev1.ConfigMap]) error {
+			if !ctrlfwk.IsFinalizing(ctx.GetCustomResource()) {
+				return nil
+			}
+			deployment := &appsv1.Deployment{}
+			if err := reconciler.Get(ctx, key, deployment); err != nil {
+				return client.IgnoreNotFound(err)
+			}
+			if *deployment.Spec.Replicas != 0 {
+				calls = append(calls, "scale-down")
+				deployment.Spec.Replicas = ptr.To(int32(0))
+				if err := reconciler.Update(ctx, deployment); err != nil {
+					return err
+				}
+			}
+			if deployment.Status.Replicas != 0 {
+				return drained
+			}
+			return nil
+		}).
+		WithAfterFinalize(func(ctx ctrlfwk.Context[*corev1.ConfigMap], _ *appsv1.Deployment) error {
+			calls = append(calls, "after-finalize")
+			return nil
+		}).
+		WithReadinessCondition(func(*appsv1.Deployment) bool { return true }).
+		Build()
+	step := ctrlfwk.NewReconcileResourceStep(ctx, reconciler, resource)
+
+	if _, err := step.Step(ctx, logr.Discard(), req).Normal(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	deployment := &appsv1.Deployment{}
+	if err := reconciler.Get(ctx, key, deployment); err != nil {
+		t.Fatalf("failed to get deployment: %v", err)
+	}
+	deployment.Status.Replicas = 3
+	if err := reconciler.Status().Update(ctx, deployment); err != nil {
+		t.Fatalf("failed to update deployment: %v", err)
+	}
+
+	// The deletion waits for the scale-down
+	ctx.GetCustomResource().SetDeletionTimestamp(&metav1.Time{Time: time.Now()})
+	if _, err := step.Step(ctx, logr.Discard(), req).Normal(); !errors.Is(err, drained) {
+		t.Fatalf("expected the deletion to wait for the drain, got %v", err)
+	}
+	if !slices.Equal(calls, []string{"scale-down"}) {
+		t.Fatalf("expected only the scale-down while draining, got %v", calls)
+	}
+
+	if err := reconciler.Get(ctx, key, deployment); err != nil {
+		t.Fatalf("failed to get deployment: %v", err)
+	}
+	deployment.Status.Replicas = 0
+	if err := reconciler.Status().Update(ctx, deployment); err != nil {
+		t.Fatalf("failed to update deployment: %v", err)
+	}
+
+	// Once drained, the framework deletes the workload before the AfterFinalize hook
+	if _, err := step.Step(ctx, logr.Discard(), req).Normal(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !slices.Equal(calls, []string{"scale-down", "delete", "after-finalize"}) {
+		t.Fatalf("unexpected calls %v", calls)
+	}
+	if err := reconciler.Get(ctx, key, deployment); !apierrors.IsNotFound(err) {
+		t.Fatalf("expected the deployment to be deleted, got %v", err)
+	}
+}
+
+func TestReconcileResourceStep_PreMutateValidatorBlocksCreation(t *testing.T) {
+	ctx, reconciler := newDeletionTest(t, interceptor.Funcs{})
+
+	var existingSeen *corev1.Secret
+	resource := ctrlfwk.NewResourceBuilder(ctx, &corev1.Secret{}).
+		WithKey(types.NamespacedName{Name: "new-secret", Namespace: "default"}).
+		WithPreMutateValidator(func(_ *corev1.ConfigMap, existing *corev1.Secret) error {
+			existingSeen = existing
+			return ctrlfwk.NewValidationError("data is missing")
+		}).
+		WithMutator(func(secret *corev1.Secret) error {
+			t.Fatal("expected the mutator not to run")
+			return nil
+		}).
+		Build()
+
+	step := ctrlfwk.NewReconcileResourceStep(ctx, reconciler, resource)
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Name: "cr", Namespace: "default"}}
+
+	stepResult := step.Step(ctx, logr.Discard(), req)
+	if !stepResult.ShouldReturn() {
+		t.Fatal("expected the reconciliation to stop")
+	}
+	result, err := stepResult.Normal()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.RequeueAfter != 0 {
+		t.Fatalf("expected no requeue, got %s", result.RequeueAfter)
+	}
+	if existingSeen != nil {
+		t.Fatal("expected no existing resource to be passed to the validator")
+	}
+
+	err = reconciler.Get(ctx, types.NamespacedName{Name: "new-secret", Namespace: "default"}, &corev1.Secret{})
+	if !apierrors.IsNotFound(err) {
+		t.Fatalf("expected the secret not to be created, got %v", err)
+	}
+}
+
+func TestReconcileResourceStep_ValidationFailedIsReportedPerResource(t *testing.T) {
+	ctx, reconciler := newConditionsTest(t)
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Name: "cr", Namespace: "default"}}
+
+	invalid := map[string]string{"first": "data is missing", "second": "size is too large"}
+	resource := func(name string) ctrlfwk.GenericResource[*conditionsCR, conditionsContext] {
+		return ctrlfwk.NewResourceBuilder(ctx, &corev1.ConfigMap{}).
+			WithKey(types.NamespacedName{Name: name, Namespace: "default"}).
+			WithPreMutateValidator(func(*conditionsCR, *corev1.ConfigMap) error {
+				if reason, ok := invalid[name]; ok {
+					return ctrlfwk.NewValidationError(reason)
+				}
+				return nil
+			}).
+			WithReadinessCondition(func(*corev1.ConfigMap) bool { return true }).
+			Build()
+	}
+	first, second := resource("first"), resource("second")
+	reconcile := func() {
+		t.Helper()
+		ctrlfwk.NewReconcileResourceStep(ctx, reconciler, first).Step(ctx, logr.Discard(), req)
+		ctrlfwk.NewReconcileResourceStep(ctx, reconciler, second).Step(ctx, logr.Discard(), req)
+	}
+	message := func() string {
+		t.Helper()
+		condition := meta.FindStatusCondition(ctx.GetCustomResource().Status.Conditions, ctrlfwk.ConditionTypeValidationFailed)
+		if condition == nil {
+			return ""
+		}
+		return condition.Message
+	}
+
+	reconcile()
+	if expected := "resource ConfigMap,default/first: data is missing\nresource ConfigMap,default/second: size is too large"; message() != expected {
+		t.Fatalf("expected both resources to be reported, got %q", message())
+	}
+
+	// The reconciliation of the first resource doesn't clear the failure of the second one
+	delete(invalid, "first")
+	ctrlfwk.NewReconcileResourceStep(ctx, reconciler, first).Step(ctx, logr.Discard(), req)
+	if expected := "resource ConfigMap,default/second: size is too large"; message() != expected {
+		t.Fatalf("expected the second resource to still be reported, got %q", message())
+	}
+
+	delete(invalid, "second")
+	reconcile()
+	if message() != "" {
+		t.Fatalf("expected the condition to be removed, got %q", message())
+	}
+}
+
+func TestReconcileResourceStep_PreMutateValidatorOtherErrorsFail(t *testing.T) {
+	ctx, reconciler := newDeletionTest(t, interceptor.Funcs{})
+
+	resource := ctrlfwk.NewResourceBuilder(ctx, &corev1.Secret{}).
+		WithKey(types.NamespacedName{Name: "secret", Namespace: "default"}).
+		WithPreMutateValidator(func(_ *corev1.ConfigMap, existing *corev1.Secret) error {
+			if existing == nil {
+				t.Fatal("expected the existing resource to be passed to the validator")
+			}
+			return errors.New("remote check unavailable")
+		}).
+		Build()
+
+	step := ctrlfwk.NewReconcileResourceStep(ctx, reconciler, resource)
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Name: "cr", Namespace: "default"}}
+
+	if _, err := step.Step(ctx, logr.Discard(), req).Normal(); err == nil {
+		t.Fatal("expected the validator error to fail the reconciliation")
+	}
+}
+
+func TestReconcileResourceStep_HooksGetScopedLogger(t *testing.T) {
+	ctx, reconciler := newDeletionTest(t, interceptor.Funcs{})
+
+	var lines []string
+	ctx.SetLogger(funcr.New(func(prefix, args string) {
+		lines = append(lines, args)
+	}, funcr.Options{}))
+
+	resource := ctrlfwk.NewResourceBuilder(ctx, &corev1.Secret{}).
+		WithKey(types.NamespacedName{Name: "secret", Namespace: "default"}).
+		WithUserIdentifier("my-secret").
+		WithAfterReconcile(func(ctx ctrlfwk.Context[*corev1.ConfigMap], _ *corev1.Secret) error {
+			logr.FromContextOrDiscard(ctx).Info("from context")
+			ctx.GetLogger().Info("from getter")
+			return nil
+		}).
+		Build()
+
+	step := ctrlfwk.NewReconcileResourceStep(ctx, reconciler, resource)
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Name: "cr", Namespace: "default"}}
+
+	if _, err := step.Step(ctx, logr.Discard(), req).Normal(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(lines) != 2 {
+		t.Fatalf("expected 2 log lines, got %v", lines)
+	}
+	for _, line := range lines {
+		if !strings.Contains(line, `"resource"="my-secret"`) || !strings.Contains(line, `"operation"="AfterReconcile"`) {
+			t.Fatalf("expected the log line to be scoped, got %s", line)
+		}
+	}
+}
+
+type fakeReconcilerWithResources struct {
+	*fakeReconciler
+	resources []ctrlfwk.GenericResource[*corev1.ConfigMap, ctrlfwk.Context[*corev1.ConfigMap]]
+}
+
+func (r *fakeReconcilerWithResources) GetResources(ctrlfwk.Context[*corev1.ConfigMap], ctrl.Request) ([]ctrlfwk.GenericResource[*corev1.ConfigMap, ctrlfwk.Context[*corev1.ConfigMap]], error) {
+	return r.resources, nil
+}
+
+func newConfigMapResource(ctx ctrlfwk.Context[*corev1.ConfigMap], name string, dependsOn ...string) ctrlfwk.GenericResource[*corev1.ConfigMap, ctrlfwk.Context[*corev1.ConfigMap]] {
+	return ctrlfwk.NewResourceBuilder(ctx, &corev1.ConfigMap{}).
+		WithKey(types.NamespacedName{Name: name, Namespace: "default"}).
+		WithUserIdentifier(name).
+		WithDependsOn(dependsOn...).
+		WithReadinessCondition(func(*corev1.ConfigMap) bool { return true }).
+		Build()
+}
+
+func TestReconcileResourcesStep_ConcurrencyRespectsDependsOn(t *testing.T) {
+	var lock sync.Mutex
+	created := map[string]int{}
+	order := 0
+
+	ctx, reconciler := newDeletionTest(t, interceptor.Funcs{
+		Create: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.CreateOption) error {
+			lock.Lock()
+			order++
+			created[obj.GetName()] = order
+			lock.Unlock()
+			return c.Create(ctx, obj, opts...)
+		},
+	})
+
+	withResources := &fakeReconcilerWithResources{
+		fakeReconciler: reconciler,
+		resources: []ctrlfwk.GenericResource[*corev1.ConfigMap, ctrlfwk.Context[*corev1.ConfigMap]]{
+			newConfigMapResource(ctx, "app", "config-a", "config-b"),
+			newConfigMapResource(ctx, "config-a"),
+			newConfigMapResource(ctx, "config-b"),
+		},
+	}
+
+	step := ctrlfwk.NewReconcileResourcesStep(ctx, withResources, ctrlfwk.WithConcurrency(4))
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Name: "cr", Namespace: "default"}}
+
+	if _, err := step.Step(ctx, logr.Discard(), req).Normal(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(created) != 3 {
+		t.Fatalf("expected 3 resources to be created, got %v", created)
+	}
+	if created["app"] < created["config-a"] || created["app"] < created["config-b"] {
+		t.Fatalf("expected app to be created after its dependencies, got %v", created)
+	}
+}
+
+func TestReconcileResourcesStep_DependsOnCycle(t *testing.T) {
+	ctx, reconciler := newDeletionTest(t, interceptor.Funcs{})
+
+	withResources := &fakeReconcilerWithResources{
+		fakeReconciler: reconciler,
+		resources: []ctrlfwk.GenericResource[*corev1.ConfigMap, ctrlfwk.Context[*corev1.ConfigMap]]{
+			newConfigMapResource(ctx, "a", "b"),
+			newConfigMapResource(ctx, "b", "a"),
+		},
+	}
+
+	step := ctrlfwk.NewReconcileResourcesStep(ctx, withResources)
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Name: "cr", Namespace: "default"}}
+
+	if _, err := step.Step(ctx, logr.Discard(), req).Normal(); err == nil {
+		t.Fatal("expected a dependency cycle to fail the reconciliation")
+	}
+}
+
+func TestReconcileResourcesStep_AggregatedHookErrorIsRetriedWithBackoff(t *testing.T) {
+	ctx, reconciler := newDeletionTest(t, interceptor.Funcs{})
+
+	withResources := &fakeReconcilerWithResources{
+		fakeReconciler: reconciler,
+		resources: []ctrlfwk.GenericResource[*corev1.ConfigMap, ctrlfwk.Context[*corev1.ConfigMap]]{
+			ctrlfwk.NewResourceBuilder(ctx, &corev1.ConfigMap{}).
+				WithKey(types.NamespacedName{Name: "app", Namespace: "default"}).
+				WithReadinessCondition(func(*corev1.ConfigMap) bool { return false }).
+				Build(),
+			ctrlfwk.NewResourceBuilder(ctx, &corev1.ConfigMap{}).
+				WithKey(types.NamespacedName{Name: "hooked", Namespace: "default"}).
+				WithAfterReconcile(func(ctrlfwk.Context[*corev1.ConfigMap], *corev1.ConfigMap) error { return errors.New("hook failed") }).
+				WithAfterReconcileErrorPolicy(ctrlfwk.HookErrorPolicyContinueAndAggregate).
+				Build(),
+		},
+	}
+
+	stepper := ctrlfwk.NewStepperFor(ctx, logr.Discard()).
+		WithStep(ctrlfwk.NewReconcileResourcesStep(ctx, withResources)).
+		Build()
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Name: "cr", Namespace: "default"}}
+
+	// controller-runtime ignores the result of a reconciliation in error, the error backoff requeues it
+	result, err := stepper.Execute(ctx, req)
+	var hookErr *ctrlfwk.HookError
+	if !errors.As(err, &hookErr) || hookErr.Hook != "AfterReconcile" {
+		t.Fatalf("expected the hook error, got %v", err)
+	}
+	if !result.IsZero() {
+		t.Fatalf("expected no requeue alongside the error, got %v", result)
+	}
+}
+
+func TestResource_GroupVersionKind(t *testing.T) {
+	ctx := ctrlfwk.NewContext[*corev1.ConfigMap](context.Background(), nil)
+
+	typed := newConfigMapResource(ctx, "typed")
+	gvk, err := typed.GroupVersionKind(clientgoscheme.Scheme)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if gvk != corev1.SchemeGroupVersion.WithKind("ConfigMap") {
+		t.Fatalf("expected the typed resource to be resolved through the scheme, got %s", gvk)
+	}
+
+	deployment := schema.GroupVersionKind{Group: "apps", Version: "v1", Kind: "Deployment"}
+	untyped := ctrlfwk.NewUntypedResourceBuilder(ctx, deployment).Build()
+	gvk, err = untyped.GroupVersionKind(clientgoscheme.Scheme)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if gvk != deployment {
+		t.Fatalf("expected the untyped resource GroupVersionKind, got %s", gvk)
+	}
+}
+
+// applyRequest is a server-side apply request received by the fake client.
+type applyRequest struct {
+	configuration *unstructured.Unstructured
+	fieldManager  string
+	force         bool
+}
+
+func TestReconcileResourceStep_UntypedServerSideApply(t *testing.T) {
+	// The fake client doesn't support server-side apply, the apply configurations are merged into the live objects
+	var applies []applyRequest
+	var conflict bool
+	ctx, reconciler := newDeletionTest(t, interceptor.Funcs{
+		Patch: func(ctx context.Context, c client.WithWatch, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+			if patch.Type() != types.ApplyPatchType {
+				return c.Patch(ctx, obj, patch, opts...)
+			}
+			options := (&client.PatchOptions{}).ApplyOptions(opts)
+			force := options.Force != nil && *options.Force
+			applies = append(applies, applyRequest{
+				configuration: obj.(*unstructured.Unstructured).DeepCopy(),
+				fieldManager:  options.FieldManager,
+				force:         force,
+			})
+			if conflict && !force {
+				return apierrors.NewConflict(schema.GroupResource{Resource: "configmaps"}, obj.GetName(), errors.New("data.level is owned by another manager"))
+			}
+
+			data, err := patch.Data(obj)
+			if err != nil {
+				return err
+			}
+			if err := c.Get(ctx, client.ObjectKeyFromObject(obj), obj.DeepCopyObject().(client.Object)); apierrors.IsNotFound(err) {
+				return c.Create(ctx, obj)
+			}
+			return c.Patch(ctx, obj, client.RawPatch(types.MergePatchType, data))
+		},
+	})
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Name: "cr", Namespace: "default"}}
+	key := types.NamespacedName{Name: "applied", Namespace: "default"}
+
+	var received []*unstructured.Unstructured
+	builder := func() *ctrlfwk.UntypedResourceBuilder[*corev1.ConfigMap, ctrlfwk.Context[*corev1.ConfigMap]] {
+		return ctrlfwk.NewUntypedResourceBuilder(ctx, corev1.SchemeGroupVersion.WithKind("ConfigMap")).
+			WithKey(key).
+			WithServerSideApply("my-operator").
+			WithMutator(func(obj *unstructured.Unstructured) error {
+				received = append(received, obj.DeepCopy())
+				return unstructured.SetNestedField(obj.Object, "info", "data", "level")
+			}).
+			WithReadinessCondition(func(*unstructured.Unstructured) bool { return true })
+	}
+	reconcile := func(resource ctrlfwk.GenericResource[*corev1.ConfigMap, ctrlfwk.Context[*corev1.ConfigMap]]) *corev1.ConfigMap {
+		t.Helper()
+		if _, err := ctrlfwk.NewReconcileResourceStep(ctx, reconciler, resource).Step(ctx, logr.Discard(), req).Normal(); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		cm := &corev1.ConfigMap{}
+		if err := reconciler.Get(ctx, key, cm); err != nil {
+			t.Fatalf("failed to get configmap: %v", err)
+		}
+		return cm
+	}
+
+	// The resource is created from the apply configuration, forcing the ownership of its fields
+	if cm := reconcile(builder().Build()); cm.Data["level"] != "info" {
+		t.Fatalf("expected the configmap to be applied, got %v", cm.Data)
+	}
+	if len(applies) != 1 || applies[0].fieldManager != "my-operator" || !applies[0].force {
+		t.Fatalf("expected a forced apply with the field manager, got %+v", applies)
+	}
+
+	// The fields of other managers are neither read nor sent by the controller
+	cm := &corev1.ConfigMap{}
+	if err := reconciler.Get(ctx, key, cm); err != nil {
+		t.Fatalf("failed to get configmap: %v", err)
+	}
+	cm.Data["external"] = "kept"
+	if err := reconciler.Update(ctx, cm); err != nil {
+		t.Fatalf("failed to update configmap: %v", err)
+	}
+	if cm := reconcile(builder().Build()); cm.Data["external"] != "kept" || cm.Data["level"] != "info" {
+		t.Fatalf("expected the fields of the other manager to be kept, got %v", cm.Data)
+	}
+	for _, obj := range received {
+		if _, found := obj.Object["data"]; found || obj.GetName() != "applied" || obj.GetKind() != "ConfigMap" {
+			t.Fatalf("expected the mutator to receive the identity of the resource only, got %v", obj.Object)
+		}
+	}
+	if data := applies[1].configuration.Object["data"]; len(data.(map[string]any)) != 1 {
+		t.Fatalf("expected only the fields of the controller to be applied, got %v", data)
+	}
+
+	// Conflicting fields are kept with the Warn policy
+	applies, conflict = nil, true
+	reconcile(builder().WithExternalMutationPolicy(ctrlfwk.ExternalMutationPolicyWarn).Build())
+	if len(applies) != 1 || applies[0].force {
+		t.Fatalf("expected a single apply without forcing the ownership, got %+v", applies)
+	}
+
+	// And taken over with the Repair policy
+	applies = nil
+	reconcile(builder().WithExternalMutationPolicy(ctrlfwk.ExternalMutationPolicyRepair).Build())
+	if len(applies) != 2 || applies[0].force || !applies[1].force {
+		t.Fatalf("expected the conflicting apply to be forced, got %+v", applies)
+	}
+}
+
+func TestResource_UpdateStatusField(t *testing.T) {
+	ctx := ctrlfwk.NewContext[*corev1.ConfigMap](context.Background(), nil)
+
+	var status ctrlfwk.ResourceStatus
+	resource := ctrlfwk.NewResourceBuilder(ctx, &corev1.Secret{}).
+		WithKey(types.NamespacedName{Name: "secret", Namespace: "default"}).
+		WithReadinessCondition(func(*corev1.Secret) bool { return true }).
+		WithStatusField(func(*corev1.ConfigMap) *ctrlfwk.ResourceStatus { return &status }).
+		Build()
+
+	secret := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "secret", Namespace: "default", Generation: 2}}
+
+	if !resource.UpdateStatusField(&corev1.ConfigMap{}, secret) {
+		t.Fatal("expected the status to be populated")
+	}
+	if status.Name != "secret" || status.Namespace != "default" || status.Generation != 2 || !status.Ready || status.LastUpdated.IsZero() {
+		t.Fatalf("unexpected status %+v", status)
+	}
+
+	if resource.UpdateStatusField(&corev1.ConfigMap{}, secret) {
+		t.Fatal("expected an unchanged resource to leave the status untouched")
+	}
+
+	if !resource.UpdateStatusField(&corev1.ConfigMap{}, nil) || status != (ctrlfwk.ResourceStatus{}) {
+		t.Fatalf("expected the status to be cleared, got %+v", status)
+	}
+}
+
+func TestReconcileResourcesStep_StableOrdering(t *testing.T) {
+	var expected []string
+
+	for run := 0; run < 100; run++ {
+		var operations []string
+		ctx, reconciler := newDeletionTest(t, interceptor.Funcs{
+			Create: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.CreateOption) error {
+				operations = append(operations, obj.GetName())
+				return c.Create(ctx, obj, opts...)
+			},
+		})
+
+		withResources := &fakeReconcilerWithResources{
+			fakeReconciler: reconciler,
+			resources: []ctrlfwk.GenericResource[*corev1.ConfigMap, ctrlfwk.Context[*corev1.ConfigMap]]{
+				newConfigMapResource(ctx, "e"),
+				newConfigMapResource(ctx, "b", "d"),
+				newConfigMapResource(ctx, "d"),
+				newConfigMapResource(ctx, "a"),
+				newConfigMapResource(ctx, "c"),
+			},
+		}
+
+		step := ctrlfwk.NewReconcileResourcesStep(ctx, withResources)
+		req := ctrl.Request{NamespacedName: types.NamespacedName{Name: "cr", Namespace: "default"}}
+
+		if _, err := step.Step(ctx, logr.Discard(), req).Normal(); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if expected == nil {
+			expected = operations
+			if strings.Join(expected, ",") != "e,d,a,c,b" {
+				t.Fatalf("expected resources in declaration order after their dependencies, got %v", expected)
+			}
+			continue
+		}
+		if strings.Join(operations, ",") != strings.Join(expected, ",") {
+			t.Fatalf("run %d: expected operations %v, got %v", run, expected, operations)
+		}
+	}
+}
+
+func TestReconcileResourcesStep_DuplicateIDs(t *testing.T) {
+	ctx, reconciler := newDeletionTest(t, interceptor.Funcs{})
+
+	withResources := &fakeReconcilerWithResources{
+		fakeReconciler: reconciler,
+		resources: []ctrlfwk.GenericResource[*corev1.ConfigMap, ctrlfwk.Context[*corev1.ConfigMap]]{
+			newConfigMapResource(ctx, "a"),
+			newConfigMapResource(ctx, "a"),
+		},
+	}
+
+	step := ctrlfwk.NewReconcileResourcesStep(ctx, withResources)
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Name: "cr", Namespace: "default"}}
+
+	if _, err := step.Step(ctx, logr.Discard(), req).Normal(); err == nil || !strings.Contains(err.Error(), "duplicate resource ID") {
+		t.Fatalf("expected duplicate IDs to fail the reconciliation, got %v", err)
+	}
+}
+
+func TestUntypedResource_IDIncludesGroupVersion(t *testing.T) {
+	ctx := ctrlfwk.NewContext[*corev1.ConfigMap](context.Background(), nil)
+	key := types.NamespacedName{Name: "app", Namespace: "default"}
+
+	apps := ctrlfwk.NewUntypedResourceBuilder(ctx, schema.GroupVersionKind{Group: "apps", Version: "v1", Kind: "Deployment"}).WithKey(key).Build()
+	extensions := ctrlfwk.NewUntypedResourceBuilder(ctx, schema.GroupVersionKind{Group: "extensions", Version: "v1beta1", Kind: "Deployment"}).WithKey(key).Build()
+
+	if apps.ID() != "apps/v1/Deployment,default/app" {
+		t.Fatalf("unexpected ID %s", apps.ID())
+	}
+	if apps.ID() == extensions.ID() {
+		t.Fatalf("expected untyped resources of different groups to have different IDs, got %s", apps.ID())
+	}
+}
+
+func TestReconcileResourceStep_ManagedPDB(t *testing.T) {
+	ctx, reconciler := newDeletionTest(t, interceptor.Funcs{})
+
+	withPDB := true
+	resource := ctrlfwk.NewResourceBuilder(ctx, &appsv1.Deployment{}).
+		WithKey(types.NamespacedName{Name: "app", Namespace: "default"}).
+		WithReadinessCondition(func(*appsv1.Deployment) bool { return true }).
+		WithManagedPDB(func(deploy *appsv1.Deployment) *policyv1.PodDisruptionBudgetSpec {
+			if !withPDB {
+				return nil
+			}
+			minAvailable := intstr.FromInt32(1)
+			return &policyv1.PodDisruptionBudgetSpec{MinAvailable: &minAvailable}
+		}).
+		Build()
+
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Name: "cr", Namespace: "default"}}
+	if _, err := ctrlfwk.NewReconcileResourceStep(ctx, reconciler, resource).Step(ctx, logr.Discard(), req).Normal(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	pdb := &policyv1.PodDisruptionBudget{}
+	if err := reconciler.Get(context.Background(), types.NamespacedName{Name: "app", Namespace: "default"}, pdb); err != nil {
+		t.Fatalf("expected the PodDisruptionBudget to be created: %v", err)
+	}
+	if owner := metav1.GetControllerOf(pdb); owner == nil || owner.Kind != "Deployment" || owner.Name != "app" {
+		t.Fatalf("expected the PodDisruptionBudget to be owned by the Deployment, got %v", pdb.OwnerReferences)
+	}
+
+	withPDB = false
+	if _, err := ctrlfwk.NewReconcileResourceStep(ctx, reconciler, resource).Step(ctx, logr.Discard(), req).Normal(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := reconciler.Get(context.Background(), types.NamespacedName{Name: "app", Namespace: "default"}, pdb); !apierrors.IsNotFound(err) {
+		t.Fatalf("expected the PodDisruptionBudget to be deleted, got %v", err)
+	}
+}
+
+func TestValidateResourcesStep(t *testing.T) {
+	ctx, reconciler := newDeletionTest(t, interceptor.Funcs{})
+
+	existing := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: "default"},
+		Data:       map[string]string{"mode": "manual"},
+	}
+	if err := reconciler.Create(ctx, existing); err != nil {
+		t.Fatalf("failed to create configmap: %v", err)
+	}
+
+	withResources := &fakeReconcilerWithResources{
+		fakeReconciler: reconciler,
+		resources: []ctrlfwk.GenericResource[*corev1.ConfigMap, ctrlfwk.Context[*corev1.ConfigMap]]{
+			newConfigMapResource(ctx, "app"),
+			// Not created yet, so not validated
+			newConfigMapResource(ctx, "missing"),
+		},
+	}
+
+	var validated []string
+	severity := ctrlfwk.ValidationSeverityError
+	step := ctrlfwk.NewValidateResourcesStep(ctx, withResources, func(cm *corev1.ConfigMap) []ctrlfwk.ValidationIssue {
+		validated = append(validated, cm.Name)
+		if cm.Data["mode"] != "auto" {
+			return []ctrlfwk.ValidationIssue{{Severity: severity, Message: "mode must be auto"}}
+		}
+		return nil
+	})
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Name: "cr", Namespace: "default"}}
+
+	result, err := step.Step(ctx, logr.Discard(), req).Normal()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.RequeueAfter == 0 {
+		t.Fatal("expected a requeue on validation errors")
+	}
+	if len(validated) != 1 || validated[0] != "app" {
+		t.Fatalf("expected only the existing resource to be validated, got %v", validated)
+	}
+
+	severity = ctrlfwk.ValidationSeverityWarning
+	result, err = step.Step(ctx, logr.Discard(), req).Normal()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.RequeueAfter != 0 {
+		t.Fatalf("expected warnings not to requeue, got a requeue after %s", result.RequeueAfter)
+	}
+}
+
+func TestReconcileResourceStep_MutatorErrorIsTyped(t *testing.T) {
+	ctx, reconciler := newDeletionTest(t, interceptor.Funcs{})
+
+	resource := ctrlfwk.NewResourceBuilder(ctx, &corev1.Secret{}).
+		WithKey(types.NamespacedName{Name: "secret", Namespace: "default"}).
+		WithUserIdentifier("my-secret").
+		WithMutator(func(*corev1.Secret) error {
+			return errors.New("boom")
+		}).
+		Build()
+
+	step := ctrlfwk.NewReconcileResourceStep(ctx, reconciler, resource)
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Name: "cr", Namespace: "default"}}
+
+	_, err := step.Step(ctx, logr.Discard(), req).Normal()
+	if !errors.Is(err, ctrlfwk.ErrMutatorFailed) {
+		t.Fatalf("expected a mutator error, got %v", err)
+	}
+
+	var mutatorErr *ctrlfwk.MutatorError
+	if !errors.As(err, &mutatorErr) || mutatorErr.ResourceID != "my-secret" {
+		t.Fatalf("expected the mutator error to hold the resource ID, got %v", err)
+	}
+	if class := ctrlfwk.ErrorClass(err); class != "MutatorFailed" {
+		t.Fatalf("expected the MutatorFailed class, got %q", class)
+	}
+}
+
+func TestReconcileResourceStep_RestoresImmutableFields(t *testing.T) {
+	ctx, reconciler := newDeletionTest(t, interceptor.Funcs{})
+
+	resource := ctrlfwk.NewResourceBuilder(ctx, &corev1.ConfigMap{}).
+		WithKey(types.NamespacedName{Name: "app", Namespace: "default"}).
+		WithMutator(func(cm *corev1.ConfigMap) error {
+			// The label is only defaulted, so the mutator does not correct drifts by itself
+			if cm.Labels["app"] == "" {
+				cm.Labels = map[string]string{"app": "web"}
+			}
+			return nil
+		}).
+		WithImmutableFields("/metadata/labels/app").
+		Build()
+
+	step := ctrlfwk.NewReconcileResourceStep(ctx, reconciler, resource)
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Name: "cr", Namespace: "default"}}
+
+	if _, err := step.Step(ctx, logr.Discard(), req).Normal(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	modified := &corev1.ConfigMap{}
+	if err := reconciler.Get(ctx, types.NamespacedName{Name: "app", Namespace: "default"}, modified); err != nil {
+		t.Fatalf("failed to get configmap: %v", err)
+	}
+	modified.Labels["app"] = "other"
+	if err := reconciler.Update(ctx, modified); err != nil {
+		t.Fatalf("failed to update configmap: %v", err)
+	}
+
+	if _, err := step.Step(ctx, logr.Discard(), req).Normal(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	restored := &corev1.ConfigMap{}
+	if err := reconciler.Get(ctx, types.NamespacedName{Name: "app", Namespace: "default"}, restored); err != nil {
+		t.Fatalf("failed to get configmap: %v", err)
+	}
+	if restored.Labels["app"] != "web" {
+		t.Fatalf("expected the label to be restored, got %q", restored.Labels["app"])
+	}
+}
+
+func TestReconcileResourceStep_DefaultMutatorsOrder(t *testing.T) {
+	ctx, reconciler := newDeletionTest(t, interceptor.Funcs{})
+	ctx.AddLabel("team", "context")
+
+	var order []string
+	defaults := ctrlfwk.ResourceDefaults{
+		Mutators: []ctrlfwk.Mutator[client.Object]{
+			func(obj client.Object) error {
+				order = append(order, "first default")
+				obj.SetLabels(map[string]string{"team": "defaults", "tier": "defaults"})
+				return nil
+			},
+			func(client.Object) error {
+				order = append(order, "second default")
+				return nil
+			},
+		},
+		CanBePaused:          true,
+		UserIdentifierPrefix: "app/",
+	}
+
+	resource := ctrlfwk.NewResourceBuilderWithDefaults(ctx, &corev1.ConfigMap{}, defaults).
+		WithKey(types.NamespacedName{Name: "app", Namespace: "default"}).
+		WithUserIdentifier("config").
+		WithCanBePaused(false).
+		WithMutator(func(cm *corev1.ConfigMap) error {
+			order = append(order, "resource")
+			cm.Labels["tier"] = "resource"
+			return nil
+		}).
+		Build()
+
+	if resource.ID() != "app/config" {
+		t.Fatalf("expected the identifier to be prefixed, got %s", resource.ID())
+	}
+	if resource.CanBePaused() {
+		t.Fatal("expected the resource to override the default pausability")
+	}
+
+	step := ctrlfwk.NewReconcileResourceStep(ctx, reconciler, resource)
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Name: "cr", Namespace: "default"}}
+
+	if _, err := step.Step(ctx, logr.Discard(), req).Normal(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if strings.Join(order, ",") != "first default,second default,resource" {
+		t.Fatalf("unexpected mutation order %v", order)
+	}
+
+	cm := &corev1.ConfigMap{}
+	if err := reconciler.Get(ctx, types.NamespacedName{Name: "app", Namespace: "default"}, cm); err != nil {
+		t.Fatalf("failed to get configmap: %v", err)
+	}
+	// The resource mutator overrides the defaults, the metadata of the context overrides both
+	if cm.Labels["tier"] != "resource" || cm.Labels["team"] != "context" {
+		t.Fatalf("unexpected labels %v", cm.Labels)
+	}
+}
+
+func TestAtomicResourceGroupStep_RollsBackOnFailure(t *testing.T) {
+	ctx, reconciler := newDeletionTest(t, interceptor.Funcs{
+		Create: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.CreateOption) error {
+			createOptions := &client.CreateOptions{}
+			createOptions.ApplyOptions(opts)
+			if obj.GetName() == "c" && len(createOptions.DryRun) == 0 {
+				return errors.New("quota exceeded")
+			}
+			return c.Create(ctx, obj, opts...)
+		},
+	})
+
+	step := ctrlfwk.NewAtomicResourceGroupStep(ctx, reconciler,
+		newConfigMapResource(ctx, "a"),
+		newConfigMapResource(ctx, "b"),
+		newConfigMapResource(ctx, "c"),
+	)
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Name: "cr", Namespace: "default"}}
+
+	if _, err := step.Step(ctx, logr.Discard(), req).Normal(); err == nil {
+		t.Fatal("expected the failed creation to fail the step")
+	}
+
+	for _, name := range []string{"a", "b", "c"} {
+		err := reconciler.Get(ctx, types.NamespacedName{Name: name, Namespace: "default"}, &corev1.ConfigMap{})
+		if !apierrors.IsNotFound(err) {
+			t.Fatalf("expected configmap %s to be rolled back, got %v", name, err)
+		}
+	}
+}
+
+func TestAtomicResourceGroupStep_CreatesWithOwnership(t *testing.T) {
+	created := map[string][]metav1.OwnerReference{}
+	ctx, reconciler := newDeletionTest(t, interceptor.Funcs{
+		Create: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.CreateOption) error {
+			createOptions := &client.CreateOptions{}
+			createOptions.ApplyOptions(opts)
+			if len(createOptions.DryRun) == 0 {
+				created[obj.GetName()] = obj.GetOwnerReferences()
+			}
+			return c.Create(ctx, obj, opts...)
+		},
+	})
+	ctx.GetCustomResource().SetUID("cr-uid")
+
+	resource := func(name string) *ctrlfwk.ResourceBuilder[*corev1.ConfigMap, ctrlfwk.Context[*corev1.ConfigMap], *corev1.ConfigMap] {
+		return ctrlfwk.NewResourceBuilder(ctx, &corev1.ConfigMap{}).
+			WithKey(types.NamespacedName{Name: name, Namespace: "default"}).
+			WithReadinessCondition(func(*corev1.ConfigMap) bool { return true })
+	}
+	step := ctrlfwk.NewAtomicResourceGroupStep(ctx, reconciler,
+		resource("owned").Build(),
+		resource("blocked").WithOwnerReferenceBlocked(true).Build(),
+		resource("shared").WithSharedOwnership(true).Build(),
+	)
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Name: "cr", Namespace: "default"}}
+
+	if _, err := step.Step(ctx, logr.Discard(), req).Normal(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// The resources are created owned, not patched afterwards by the reconcile step
+	if refs := created["owned"]; len(refs) != 1 || refs[0].UID != "cr-uid" || refs[0].Controller == nil || !*refs[0].Controller {
+		t.Fatalf("expected the custom resource to be the controller of the resource, got %v", refs)
+	}
+	if refs := created["blocked"]; len(refs) != 0 {
+		t.Fatalf("expected no owner reference on the blocked resource, got %v", refs)
+	}
+	if refs := created["shared"]; len(refs) != 1 || refs[0].UID != "cr-uid" || (refs[0].Controller != nil && *refs[0].Controller) {
+		t.Fatalf("expected a non controller owner reference on the shared resource, got %v", refs)
+	}
+
+	shared := &corev1.ConfigMap{}
+	if err := reconciler.Get(ctx, types.NamespacedName{Name: "shared", Namespace: "default"}, shared); err != nil {
+		t.Fatalf("failed to get configmap: %v", err)
+	}
+	if len(shared.GetLabels()) != 0 {
+		t.Fatalf("expected the shared resource not to be tracked, got labels %v", shared.GetLabels())
+	}
+}
+
+func TestReconcileResourceStep_SharedResourceIsReleased(t *testing.T) {
+	ctx, reconciler := newDeletionTest(t, interceptor.Funcs{})
+	ctx.GetCustomResource().SetUID("cr-uid")
+
+	ownerRef := func(name string, uid types.UID) metav1.OwnerReference {
+		return metav1.OwnerReference{APIVersion: "v1", Kind: "ConfigMap", Name: name, UID: uid}
+	}
+	shared := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{
+		Name:            "shared",
+		Namespace:       "default",
+		OwnerReferences: []metav1.OwnerReference{ownerRef("other", "other-uid"), ownerRef("cr", "cr-uid")},
+	}}
+	if err := reconciler.Create(ctx, shared); err != nil {
+		t.Fatalf("failed to create configmap: %v", err)
+	}
+
+	resource := ctrlfwk.NewResourceBuilder(ctx, &corev1.ConfigMap{}).
+		WithKey(types.NamespacedName{Name: "shared", Namespace: "default"}).
+		WithSharedOwnership(true).
+		WithSkipAndDeleteOnCondition(func() bool { return true }).
+		Build()
+
+	step := ctrlfwk.NewReconcileResourceStep(ctx, reconciler, resource)
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Name: "cr", Namespace: "default"}}
+
+	if _, err := step.Step(ctx, logr.Discard(), req).Normal(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	released := &corev1.ConfigMap{}
+	if err := reconciler.Get(ctx, types.NamespacedName{Name: "shared", Namespace: "default"}, released); err != nil {
+		t.Fatalf("expected the shared configmap to be kept, got %v", err)
+	}
+	if refs := released.GetOwnerReferences(); len(refs) != 1 || refs[0].UID != "other-uid" {
+		t.Fatalf("expected only the other owner to remain, got %v", refs)
+	}
+
+	// The last owner deletes it
+	released.SetOwnerReferences([]metav1.OwnerReference{ownerRef("cr", "cr-uid")})
+	if err := reconciler.Update(ctx, released); err != nil {
+		t.Fatalf("failed to update configmap: %v", err)
+	}
+	if _, err := step.Step(ctx, logr.Discard(), req).Normal(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := reconciler.Get(ctx, types.NamespacedName{Name: "shared", Namespace: "default"}, &corev1.ConfigMap{}); !apierrors.IsNotFound(err) {
+		t.Fatalf("expected the configmap to be deleted by its last owner, got %v", err)
+	}
+}
+
+func TestReconcileResourceStep_CreateAndUpdateMutators(t *testing.T) {
+	ctx, reconciler := newDeletionTest(t, interceptor.Funcs{})
+
+	var calls []string
+	resource := ctrlfwk.NewResourceBuilder(ctx, &corev1.ConfigMap{}).
+		WithKey(types.NamespacedName{Name: "app", Namespace: "default"}).
+		WithCreateMutator(func(cm *corev1.ConfigMap) error {
+			calls = append(calls, "create")
+			cm.Data = map[string]string{"log-level": "info"}
+			return nil
+		}).
+		WithUpdateMutator(func(cm *corev1.ConfigMap) error {
+			calls = append(calls, "update")
+			return nil
+		}).
+		Build()
+
+	step := ctrlfwk.NewReconcileResourceStep(ctx, reconciler, resource)
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Name: "cr", Namespace: "default"}}
+
+	if _, err := step.Step(ctx, logr.Discard(), req).Normal(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	edited := &corev1.ConfigMap{}
+	if err := reconciler.Get(ctx, types.NamespacedName{Name: "app", Namespace: "default"}, edited); err != nil {
+		t.Fatalf("failed to get configmap: %v", err)
+	}
+	edited.Data["log-level"] = "debug"
+	if err := reconciler.Update(ctx, edited); err != nil {
+		t.Fatalf("failed to update configmap: %v", err)
+	}
+
+	if _, err := step.Step(ctx, logr.Discard(), req).Normal(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if strings.Join(calls, ",") != "create,update" {
+		t.Fatalf("unexpected mutator calls %v", calls)
+	}
+
+	current := &corev1.ConfigMap{}
+	if err := reconciler.Get(ctx, types.NamespacedName{Name: "app", Namespace: "default"}, current); err != nil {
+		t.Fatalf("failed to get configmap: %v", err)
+	}
+	if current.Data["log-level"] != "debug" {
+		t.Fatalf("expected the edit to be preserved, got %q", current.Data["log-level"])
+	}
+}
+
+func TestReconcileResourceStep_RollingUpdateGuardDefersUpdates(t *testing.T) {
+	ctx, reconciler := newDeletionTest(t, interceptor.Funcs{})
+	key := types.NamespacedName{Name: "app", Namespace: "default"}
+
+	logLevel := "info"
+	resource := ctrlfwk.NewResourceBuilder(ctx, &corev1.ConfigMap{}).
+		WithKey(key).
+		WithMutator(func(cm *corev1.ConfigMap) error {
+			cm.Data = map[string]string{"log-level": logLevel}
+			return nil
+		}).
+		WithRollingUpdateGuard(func(cm *corev1.ConfigMap) bool {
+			return cm.Annotations["rollout"] == "in-progress"
+		}).
+		WithReadinessCondition(func(*corev1.ConfigMap) bool { return true }).
+		Build()
+	step := ctrlfwk.NewReconcileResourceStep(ctx, reconciler, resource)
+
+	// Creations are never deferred
+	if result := step.Step(ctx, logr.Discard(), ctrl.Request{}); result.ShouldReturn() {
+		t.Fatalf("unexpected result: %v", result)
+	}
+
+	live := &corev1.ConfigMap{}
+	if err := reconciler.Get(ctx, key, live); err != nil {
+		t.Fatalf("failed to get configmap: %v", err)
+	}
+	live.Annotations = map[string]string{"rollout": "in-progress"}
+	if err := reconciler.Update(ctx, live); err != nil {
+		t.Fatalf("failed to update configmap: %v", err)
+	}
+
+	logLevel = "debug"
+	result := step.Step(ctx, logr.Discard(), ctrl.Request{})
+	if result.RequeueReason() != ctrlfwk.RequeueReasonRollingUpdateInProgress {
+		t.Fatalf("expected a requeue because of the rollout, got %v", result)
+	}
+	if err := reconciler.Get(ctx, key, live); err != nil {
+		t.Fatalf("failed to get configmap: %v", err)
+	}
+	if live.Data["log-level"] != "info" {
+		t.Fatalf("expected the update to be deferred, got %q", live.Data["log-level"])
+	}
+
+	// The deferred update is applied once the rollout completes
+	live.Annotations = nil
+	if err := reconciler.Update(ctx, live); err != nil {
+		t.Fatalf("failed to update configmap: %v", err)
+	}
+	if result := step.Step(ctx, logr.Discard(), ctrl.Request{}); result.ShouldReturn() {
+		t.Fatalf("unexpected result: %v", result)
+	}
+	if err := reconciler.Get(ctx, key, live); err != nil {
+		t.Fatalf("failed to get configmap: %v", err)
+	}
+	if live.Data["log-level"] != "debug" {
+		t.Fatalf("expected the deferred update to be applied, got %q", live.Data["log-level"])
+	}
+}
+
+func TestReconcileResourceStep_RollingUpdateInProgressIsReportedPerResource(t *testing.T) {
+	ctx, reconciler := newConditionsTest(t)
+
+	logLevel := "info"
+	resource := func(name string) ctrlfwk.GenericResource[*conditionsCR, conditionsContext] {
+		return ctrlfwk.NewResourceBuilder(ctx, &corev1.ConfigMap{}).
+			WithKey(types.NamespacedName{Name: name, Namespace: "default"}).
+			WithMutator(func(cm *corev1.ConfigMap) error {
+				cm.Data = map[string]string{"log-level": logLevel}
+				return nil
+			}).
+			WithRollingUpdateGuard(func(cm *corev1.ConfigMap) bool {
+				return cm.Annotations["rollout"] == "in-progress"
+			}).
+			WithReadinessCondition(func(*corev1.ConfigMap) bool { return true }).
+			Build()
+	}
+	rolling, updated := resource("rolling"), resource("updated")
+	reconcile := func(resource ctrlfwk.GenericResource[*conditionsCR, conditionsContext]) {
+		t.Helper()
+		ctrlfwk.NewReconcileResourceStep(ctx, reconciler, resource).Step(ctx, logr.Discard(), ctrl.Request{})
+	}
+
+	reconcile(rolling)
+	reconcile(updated)
+
+	live := &corev1.ConfigMap{}
+	if err := reconciler.Get(ctx, types.NamespacedName{Name: "rolling", Namespace: "default"}, live); err != nil {
+		t.Fatalf("failed to get configmap: %v", err)
+	}
+	live.Annotations = map[string]string{"rollout": "in-progress"}
+	if err := reconciler.Update(ctx, live); err != nil {
+		t.Fatalf("failed to update configmap: %v", err)
+	}
+
+	// The update of another resource doesn't clear the deferred update
+	logLevel = "debug"
+	reconcile(rolling)
+	reconcile(updated)
+
+	condition := meta.FindStatusCondition(ctx.GetCustomResource().Status.Conditions, ctrlfwk.ConditionTypeRollingUpdateInProgress)
+	if condition == nil || condition.Message != "resource ConfigMap,default/rolling: update is deferred until the rollout completes" {
+		t.Fatalf("expected the deferred update to be reported, got %v", condition)
+	}
+
+	live.Annotations = nil
+	if err := reconciler.Update(ctx, live); err != nil {
+		t.Fatalf("failed to update configmap: %v", err)
+	}
+	reconcile(rolling)
+	if condition := meta.FindStatusCondition(ctx.GetCustomResource().Status.Conditions, ctrlfwk.ConditionTypeRollingUpdateInProgress); condition != nil {
+		t.Fatalf("expected the condition to be removed once the update is applied, got %v", condition)
+	}
+}
+
+func TestReconcileResourceStep_MutatorWithExisting(t *testing.T) {
+	ctx, reconciler := newDeletionTest(t, interceptor.Funcs{})
+
+	var existingLogLevels []string
+	resource := ctrlfwk.NewResourceBuilder(ctx, &corev1.ConfigMap{}).
+		WithKey(types.NamespacedName{Name: "app", Namespace: "default"}).
+		WithMutatorWithExisting(func(desired, existing *corev1.ConfigMap) error {
+			logLevel := "info"
+			if existing != nil {
+				logLevel = existing.Data["log-level"]
+				existing.Data["log-level"] = "mutated"
+			}
+			existingLogLevels = append(existingLogLevels, logLevel)
+			desired.Data = map[string]string{"log-level": logLevel}
+			return nil
+		}).
+		Build()
+
+	step := ctrlfwk.NewReconcileResourceStep(ctx, reconciler, resource)
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Name: "cr", Namespace: "default"}}
+
+	if _, err := step.Step(ctx, logr.Discard(), req).Normal(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	edited := &corev1.ConfigMap{}
+	if err := reconciler.Get(ctx, types.NamespacedName{Name: "app", Namespace: "default"}, edited); err != nil {
+		t.Fatalf("failed to get configmap: %v", err)
+	}
+	edited.Data["log-level"] = "debug"
+	if err := reconciler.Update(ctx, edited); err != nil {
+		t.Fatalf("failed to update configmap: %v", err)
+	}
+
+	if _, err := step.Step(ctx, logr.Discard(), req).Normal(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if strings.Join(existingLogLevels, ",") != "info,debug" {
+		t.Fatalf("unexpected existing log levels %v", existingLogLevels)
+	}
+
+	current := &corev1.ConfigMap{}
+	if err := reconciler.Get(ctx, types.NamespacedName{Name: "app", Namespace: "default"}, current); err != nil {
+		t.Fatalf("failed to get configmap: %v", err)
+	}
+	if current.Data["log-level"] != "debug" {
+		t.Fatalf("expected the edit to be preserved, got %q", current.Data["log-level"])
+	}
+}
+
+func TestReconcileResourceStep_MutatorWithExistingExcludesMutator(t *testing.T) {
+	ctx, reconciler := newDeletionTest(t, interceptor.Funcs{})
+
+	resource := ctrlfwk.NewResourceBuilder(ctx, &corev1.ConfigMap{}).
+		WithKey(types.NamespacedName{Name: "app", Namespace: "default"}).
+		WithMutator(func(cm *corev1.ConfigMap) error { return nil }).
+		WithMutatorWithExisting(func(desired, existing *corev1.ConfigMap) error { return nil }).
+		Build()
+
+	step := ctrlfwk.NewReconcileResourceStep(ctx, reconciler, resource)
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Name: "cr", Namespace: "default"}}
+
+	_, err := step.Step(ctx, logr.Discard(), req).Normal()
+	if err == nil || !strings.Contains(err.Error(), "mutually exclusive") {
+		t.Fatalf("expected a validation error, got %v", err)
+	}
+}
+
+func TestReconcileResourceStep_NotReadyBackoff(t *testing.T) {
+	ctx, reconciler := newDeletionTest(t, interceptor.Funcs{})
+
+	ready := false
+	resource := ctrlfwk.NewResourceBuilder(ctx, &corev1.ConfigMap{}).
+		WithKey(types.NamespacedName{Name: "app", Namespace: "default"}).
+		WithMutator(func(cm *corev1.ConfigMap) error { return nil }).
+		WithReadinessCondition(func(*corev1.ConfigMap) bool { return ready }).
+		WithNotReadyBackoff(ctrlfwk.NotReadyBackoff{Initial: time.Second, Max: 4 * time.Second, Jitter: 0.1}).
+		Build()
+
+	step := ctrlfwk.NewReconcileResourceStep(ctx, reconciler, resource)
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Name: "cr", Namespace: "default"}}
+
+	expectRequeue := func(expected time.Duration) {
+		t.Helper()
+		result := step.Step(ctx, logr.Discard(), req)
+		after, err := result.Normal()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if result.RequeueReason() != ctrlfwk.RequeueReasonResourceNotReady ||
+			after.RequeueAfter < expected*9/10 || after.RequeueAfter > expected*11/10 {
+			t.Fatalf("expected a requeue after about %s, got %v (%s)", expected, after.RequeueAfter, result.RequeueReason())
+		}
+	}
+
+	expectRequeue(time.Second)
+	expectRequeue(2 * time.Second)
+	expectRequeue(4 * time.Second)
+	expectRequeue(4 * time.Second)
+
+	// The backoff is reset once the resource is ready
+	ready = true
+	if result := step.Step(ctx, logr.Discard(), req); result.ShouldReturn() {
+		t.Fatalf("unexpected result: %v", result)
+	}
+	ready = false
+	expectRequeue(time.Second)
+}
+
+func TestReconcileResourceStep_ReadinessReasonIsReportedPerResource(t *testing.T) {
+	ctx, reconciler := newConditionsTest(t)
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Name: "cr", Namespace: "default"}}
+
+	notReady := map[string][2]string{
+		"cache": {"Warming", "cache is warming up"},
+		"sync":  {"Syncing", "2/3 objects synced"},
+	}
+	resource := func(name string) ctrlfwk.GenericResource[*conditionsCR, conditionsContext] {
+		return ctrlfwk.NewResourceBuilder(ctx, &corev1.ConfigMap{}).
+			WithKey(types.NamespacedName{Name: name, Namespace: "default"}).
+			WithReadinessReason(func(*corev1.ConfigMap) (bool, string, string) {
+				if reason, ok := notReady[name]; ok {
+					return false, reason[0], reason[1]
+				}
+				return true, "", ""
+			}).
+			Build()
+	}
+	cache, sync := resource("cache"), resource("sync")
+	reconcile := func(resource ctrlfwk.GenericResource[*conditionsCR, conditionsContext]) {
+		t.Helper()
+		ctrlfwk.NewReconcileResourceStep(ctx, reconciler, resource).Step(ctx, logr.Discard(), req)
+	}
+	expectReady := func(reason, message string) {
+		t.Helper()
+		condition := meta.FindStatusCondition(ctx.GetCustomResource().Status.Conditions, ctrlfwk.ConditionTypeReady)
+		if reason == "" {
+			if condition != nil {
+				t.Fatalf("expected no Ready condition, got %v", condition)
+			}
+			return
+		}
+		if condition == nil || condition.Status != metav1.ConditionFalse || condition.Reason != reason || condition.Message != message {
+			t.Fatalf("expected Ready=False with reason %s and message %q, got %v", reason, message, condition)
+		}
+	}
+
+	reconcile(cache)
+	reconcile(sync)
+	expectReady("ResourcesNotReady", "resource ConfigMap,default/cache: not ready (Warming): cache is warming up\n"+
+		"resource ConfigMap,default/sync: not ready (Syncing): 2/3 objects synced")
+
+	// A resource getting ready leaves the other ones reported
+	delete(notReady, "cache")
+	reconcile(cache)
+	reconcile(sync)
+	expectReady("Syncing", "resource ConfigMap,default/sync: not ready (Syncing): 2/3 objects synced")
+
+	delete(notReady, "sync")
+	reconcile(sync)
+	expectReady("", "")
+}
+
+func TestReconcileResourceStep_GenerationGate(t *testing.T) {
+	ctx, reconciler := newConditionsTest(t)
+
+	cr := ctx.GetCustomResource()
+	cr.Generation = 1
+	ctx.SetCustomResource(cr)
+
+	var mutations int
+	resource := ctrlfwk.NewResourceBuilder(ctx, &corev1.ConfigMap{}).
+		WithKey(types.NamespacedName{Name: "app", Namespace: "default"}).
+		WithMutator(func(cm *corev1.ConfigMap) error {
+			mutations++
+			cm.Data = map[string]string{"log-level": "info"}
+			return nil
+		}).
+		WithReadinessCondition(func(*corev1.ConfigMap) bool { return true }).
+		WithGenerationGate(true).
+		Build()
+	step := ctrlfwk.NewReconcileResourceStep(ctx, reconciler, resource)
+
+	reconcile := func(expectedMutations int) {
+		t.Helper()
+		if result := step.Step(ctx, logr.Discard(), ctrl.Request{}); result.ShouldReturn() {
+			t.Fatalf("unexpected result: %v", result)
+		}
+		if mutations != expectedMutations {
+			t.Fatalf("expected %d mutations, got %d", expectedMutations, mutations)
+		}
+	}
+
+	reconcile(1)
+	// Reconciling the same generation again skips the mutation
+	reconcile(1)
+
+	// Manual edits bypass the gate and are repaired
+	edited := &corev1.ConfigMap{}
+	if err := reconciler.Get(ctx, types.NamespacedName{Name: "app", Namespace: "default"}, edited); err != nil {
+		t.Fatalf("failed to get configmap: %v", err)
+	}
+	edited.Data["log-level"] = "debug"
+	if err := reconciler.Update(ctx, edited); err != nil {
+		t.Fatalf("failed to update configmap: %v", err)
+	}
+	reconcile(2)
+	reconcile(2)
+
+	repaired := &corev1.ConfigMap{}
+	if err := reconciler.Get(ctx, types.NamespacedName{Name: "app", Namespace: "default"}, repaired); err != nil {
+		t.Fatalf("failed to get configmap: %v", err)
+	}
+	if repaired.Data["log-level"] != "info" {
+		t.Fatalf("expected the manual edit to be repaired, got %q", repaired.Data["log-level"])
+	}
+
+	// A new generation of the custom resource is reconciled
+	cr = ctx.GetCustomResource()
+	cr.Generation = 2
+	ctx.SetCustomResource(cr)
+	reconcile(3)
+
+	// Forced reconciliations bypass the gate, the annotation being removed at the end of the reconciliation
+	forced := ctx.GetCustomResource().DeepCopyObject().(*conditionsCR)
+	if err := ctrlfwk.ForceReconcile(ctx, reconciler, forced); err != nil {
+		t.Fatalf("failed to force reconciliation: %v", err)
+	}
+	// The fake client doesn't track the generation
+	forced.Generation = 2
+	ctx.SetCustomResource(forced)
+	reconcile(4)
+	if result := ctrlfwk.NewEndStep(ctx, reconciler, nil).Step(ctx, logr.Discard(), ctrl.Request{}); result.ShouldReturn() {
+		t.Fatalf("unexpected result: %v", result)
+	}
+
+	live := &conditionsCR{}
+	if err := reconciler.Get(ctx, client.ObjectKeyFromObject(cr), live); err != nil {
+		t.Fatalf("failed to get custom resource: %v", err)
+	}
+	if ctrlfwk.IsForcedReconcile(live) {
+		t.Fatal("expected the force reconcile annotation to be removed")
+	}
+	live.Generation = 2
+	ctx.SetCustomResource(live)
+	reconcile(4)
+}
+
+func TestReconcileResourceStep_ExternalMutationPolicy(t *testing.T) {
+	for _, tc := range []struct {
+		policy ctrlfwk.ExternalMutationPolicy
+		// expected is the value of the edited field after each reconciliation, the second one being for a new generation
+		expected [2]string
+	}{
+		{policy: ctrlfwk.ExternalMutationPolicyRepair, expected: [2]string{"info", "info"}},
+		{policy: ctrlfwk.ExternalMutationPolicyAccept, expected: [2]string{"debug", "info"}},
+		{policy: ctrlfwk.ExternalMutationPolicyWarn, expected: [2]string{"debug", "debug"}},
+	} {
+		t.Run(string(tc.policy), func(t *testing.T) {
+			ctx, reconciler := newConditionsTest(t)
+			key := types.NamespacedName{Name: "app", Namespace: "default"}
+
+			cr := ctx.GetCustomResource()
+			cr.Generation = 1
+			ctx.SetCustomResource(cr)
+
+			resource := ctrlfwk.NewResourceBuilder(ctx, &corev1.ConfigMap{}).
+				WithKey(key).
+				WithMutator(func(cm *corev1.ConfigMap) error {
+					cm.Data = map[string]string{"log-level": "info"}
+					return nil
+				}).
+				WithReadinessCondition(func(*corev1.ConfigMap) bool { return true }).
+				WithExternalMutationPolicy(tc.policy).
+				Build()
+			step := ctrlfwk.NewReconcileResourceStep(ctx, reconciler, resource)
+
+			reconcile := func() string {
+				t.Helper()
+				if result := step.Step(ctx, logr.Discard(), ctrl.Request{}); result.ShouldReturn() {
+					t.Fatalf("unexpected result: %v", result)
+				}
+				cm := &corev1.ConfigMap{}
+				if err := reconciler.Get(ctx, key, cm); err != nil {
+					t.Fatalf("failed to get configmap: %v", err)
+				}
+				return cm.Data["log-level"]
+			}
+
+			reconcile()
+			edited := &corev1.ConfigMap{}
+			if err := reconciler.Get(ctx, key, edited); err != nil {
+				t.Fatalf("failed to get configmap: %v", err)
+			}
+			edited.Data["log-level"] = "debug"
+			if err := reconciler.Update(ctx, edited); err != nil {
+				t.Fatalf("failed to update configmap: %v", err)
+			}
+
+			if got := reconcile(); got != tc.expected[0] {
+				t.Fatalf("expected %q after the external edit, got %q", tc.expected[0], got)
+			}
+
+			cr = ctx.GetCustomResource()
+			cr.Generation = 2
+			ctx.SetCustomResource(cr)
+			if got := reconcile(); got != tc.expected[1] {
+				t.Fatalf("expected %q for a new generation, got %q", tc.expected[1], got)
+			}
+		})
+	}
+}
+
+func TestReconcileResourceStep_SuspendAndResume(t *testing.T) {
+	ctx, reconciler := newConditionsTest(t)
+	key := types.NamespacedName{Name: "app", Namespace: "default"}
+
+	resource := ctrlfwk.NewResourceBuilder(ctx, &appsv1.Deployment{}).
+		WithKey(key).
+		WithMutator(func(deployment *appsv1.Deployment) error {
+			// The replicas are owned by an autoscaler once created
+			if deployment.Spec.Replicas == nil {
+				deployment.Spec.Replicas = ptr.To[int32](3)
+			}
+			return nil
+		}).
+		WithReadinessCondition(func(deployment *appsv1.Deployment) bool { return *deployment.Spec.Replicas > 0 }).
+		WithSuspendBehavior(func(deployment *appsv1.Deployment) error {
+			deployment.Spec.Replicas = ptr.To[int32](0)
+			return nil
+		}).
+		Build()
+	step := ctrlfwk.NewReconcileResourceStep(ctx, reconciler, resource)
+
+	setSuspendLabel := func(labels map[string]string) {
+		cr := ctx.GetCustomResource()
+		cr.Labels = labels
+		if err := reconciler.Update(ctx, cr); err != nil {
+			t.Fatalf("failed to update custom resource: %v", err)
+		}
+		ctx.SetCustomResource(cr)
+	}
+	reconcile := func(expectedReplicas int32) *appsv1.Deployment {
+		t.Helper()
+		if result := step.Step(ctx, logr.Discard(), ctrl.Request{}); result.ShouldReturn() {
+			t.Fatalf("unexpected result: %v", result)
+		}
+		deployment := &appsv1.Deployment{}
+		if err := reconciler.Get(ctx, key, deployment); err != nil {
+			t.Fatalf("failed to get deployment: %v", err)
+		}
+		if *deployment.Spec.Replicas != expectedReplicas {
+			t.Fatalf("expected %d replicas, got %d", expectedReplicas, *deployment.Spec.Replicas)
+		}
+		return deployment
+	}
+
+	deployment := reconcile(3)
+	deployment.Spec.Replicas = ptr.To[int32](5)
+	if err := reconciler.Update(ctx, deployment); err != nil {
+		t.Fatalf("failed to scale deployment: %v", err)
+	}
+
+	setSuspendLabel(map[string]string{ctrlfwk.LabelSuspended: "maintenance"})
+	deployment = reconcile(0)
+	if deployment.Annotations[ctrlfwk.AnnotationSuspendedState] == "" {
+		t.Fatal("expected the replicas to be saved")
+	}
+	reconcile(0)
+	if !meta.IsStatusConditionTrue(ctx.GetCustomResource().Status.Conditions, ctrlfwk.ConditionTypeSuspended) {
+		t.Fatal("expected the suspended condition to be set")
+	}
+
+	setSuspendLabel(nil)
+	deployment = reconcile(5)
+	if _, ok := deployment.Annotations[ctrlfwk.AnnotationSuspendedState]; ok {
+		t.Fatal("expected the saved state to be removed")
+	}
+	if meta.FindStatusCondition(ctx.GetCustomResource().Status.Conditions, ctrlfwk.ConditionTypeSuspended) != nil {
+		t.Fatal("expected the suspended condition to be removed")
+	}
+}
+
+func TestReconcileResourceStep_PatchStrategy(t *testing.T) {
+	for _, strategy := range []ctrlfwk.PatchStrategy{ctrlfwk.PatchStrategyMerge, ctrlfwk.PatchStrategyStrategicMerge} {
+		t.Run(string(strategy), func(t *testing.T) {
+			var patches []string
+			ctx, reconciler := newDeletionTest(t, interceptor.Funcs{
+				Patch: func(ctx context.Context, c client.WithWatch, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+					data, err := patch.Data(obj)
+					if err != nil {
+						return err
+					}
+					patches = append(patches, string(patch.Type())+" "+string(data))
+					return c.Patch(ctx, obj, patch, opts...)
+				},
+			})
+
+			resource := ctrlfwk.NewResourceBuilder(ctx, &corev1.Secret{}).
+				WithKey(types.NamespacedName{Name: "secret", Namespace: "default"}).
+				WithMutator(func(secret *corev1.Secret) error {
+					secret.Data = map[string][]byte{"token": []byte("value")}
+					return nil
+				}).
+				WithReadinessCondition(func(*corev1.Secret) bool { return true }).
+				WithPatchStrategy(strategy).
+				WithoutOwnerReference().
+				Build()
+
+			if _, err := ctrlfwk.NewReconcileResourceStep(ctx, reconciler, resource).Step(ctx, logr.Discard(), ctrl.Request{}).Normal(); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if len(patches) == 0 {
+				t.Fatal("expected the resource to be patched")
+			}
+			patch := patches[0]
+			if !strings.Contains(patch, "resourceVersion") || !strings.Contains(patch, "token") || strings.Contains(patch, `"name"`) {
+				t.Fatalf("expected a patch of the changes with a resource version precondition, got %s", patch)
+			}
+			if strategy == ctrlfwk.PatchStrategyStrategicMerge && !strings.HasPrefix(patch, string(types.StrategicMergePatchType)) {
+				t.Fatalf("expected a strategic merge patch, got %s", patch)
+			}
+		})
+	}
+}
+
+func TestReconcileResourceStep_ControllerReference(t *testing.T) {
+	ctx, reconciler := newDeletionTest(t, interceptor.Funcs{})
+
+	reconcile := func(name, namespace string, withoutOwnerReference bool) *corev1.Secret {
+		builder := ctrlfwk.NewResourceBuilder(ctx, &corev1.Secret{}).
+			WithKey(types.NamespacedName{Name: name, Namespace: namespace}).
+			WithReadinessCondition(func(*corev1.Secret) bool { return true })
+		if withoutOwnerReference {
+			builder = builder.WithoutOwnerReference()
+		}
+		if _, err := ctrlfwk.NewReconcileResourceStep(ctx, reconciler, builder.Build()).Step(ctx, logr.Discard(), ctrl.Request{}).Normal(); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		secret := &corev1.Secret{}
+		if err := reconciler.Get(ctx, types.NamespacedName{Name: name, Namespace: namespace}, secret); err != nil {
+			t.Fatalf("failed to get secret: %v", err)
+		}
+		return secret
+	}
+
+	if owner := metav1.GetControllerOf(reconcile("owned", "default", false)); owner == nil || owner.Name != "cr" {
+		t.Fatalf("expected the custom resource to control the secret, got %v", owner)
+	}
+	if owners := reconcile("not-owned", "default", true).GetOwnerReferences(); len(owners) != 0 {
+		t.Fatalf("expected no owner reference, got %v", owners)
+	}
+	if owners := reconcile("other-namespace", "other", false).GetOwnerReferences(); len(owners) != 0 {
+		t.Fatalf("expected no owner reference across namespaces, got %v", owners)
+	}
+
+	secret := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "secret", Namespace: "default"}}
+	if err := ctx.SetControllerReference(secret); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if owner := metav1.GetControllerOf(secret); owner == nil || owner.Kind != "ConfigMap" {
+		t.Fatalf("expected the context to set the custom resource as controller, got %v", owner)
+	}
+}
+
+func TestReconcileResourceStep_OwnerReferenceBlocked(t *testing.T) {
+	ctx, reconciler := newDeletionTest(t, interceptor.Funcs{})
+	ctx.GetCustomResource().SetUID("cr-uid")
+
+	var lines []string
+	ctx.SetLogger(funcr.New(func(prefix, args string) {
+		lines = append(lines, args)
+	}, funcr.Options{}))
+	warned := func() bool {
+		return slices.ContainsFunc(lines, func(line string) bool {
+			return strings.Contains(line, "owner references blocked but is owned by the custom resource")
+		})
+	}
+
+	var ownedByMutator bool
+	resource := ctrlfwk.NewResourceBuilder(ctx, &corev1.Secret{}).
+		WithKey(types.NamespacedName{Name: "blocked", Namespace: "default"}).
+		WithOwnerReferenceBlocked(true).
+		WithMutator(func(secret *corev1.Secret) error {
+			if ownedByMutator {
+				return ctx.SetControllerReference(secret)
+			}
+			return nil
+		}).
+		WithReadinessCondition(func(*corev1.Secret) bool { return true }).
+		Build()
+	step := ctrlfwk.NewReconcileResourceStep(ctx, reconciler, resource)
+	reconcile := func() *corev1.Secret {
+		t.Helper()
+		if _, err := step.Step(ctx, logr.Discard(), ctrl.Request{}).Normal(); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		secret := &corev1.Secret{}
+		if err := reconciler.Get(ctx, types.NamespacedName{Name: "blocked", Namespace: "default"}, secret); err != nil {
+			t.Fatalf("failed to get secret: %v", err)
+		}
+		return secret
+	}
+
+	// The framework doesn't set the custom resource as controller
+	if owners := reconcile().GetOwnerReferences(); len(owners) != 0 || warned() {
+		t.Fatalf("expected no owner reference nor warning, got %v", owners)
+	}
+
+	// The owner reference set by the mutator is kept, with a warning
+	ownedByMutator = true
+	if owner := metav1.GetControllerOf(reconcile()); owner == nil || owner.UID != "cr-uid" {
+		t.Fatalf("expected the owner reference of the mutator to be kept, got %v", owner)
+	}
+	if !warned() {
+		t.Fatalf("expected a warning about the owned resource, got %v", lines)
+	}
+}
+
+func TestReconcileResourceStep_EqualityFunc(t *testing.T) {
+	ctx, reconciler := newDeletionTest(t, interceptor.Funcs{})
+
+	cpu := "1"
+	var updates int
+	reconcile := func(withEquality bool) *corev1.ConfigMap {
+		builder := ctrlfwk.NewResourceBuilder(ctx, &corev1.ConfigMap{}).
+			WithKey(types.NamespacedName{Name: "limits", Namespace: "default"}).
+			WithMutator(func(cm *corev1.ConfigMap) error {
+				cm.Data = map[string]string{"cpu": cpu}
+				return nil
+			}).
+			WithAfterUpdate(func(ctrlfwk.Context[*corev1.ConfigMap], *corev1.ConfigMap) error {
+				updates++
+				return nil
+			}).
+			WithReadinessCondition(func(*corev1.ConfigMap) bool { return true })
+		if withEquality {
+			builder = builder.WithEqualityFunc(func(current, desired *corev1.ConfigMap) bool {
+				return resource.MustParse(current.Data["cpu"]).Equal(resource.MustParse(desired.Data["cpu"]))
+			})
+		}
+		if _, err := ctrlfwk.NewReconcileResourceStep(ctx, reconciler, builder.Build()).Step(ctx, logr.Discard(), ctrl.Request{}).Normal(); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		cm := &corev1.ConfigMap{}
+		if err := reconciler.Get(ctx, types.NamespacedName{Name: "limits", Namespace: "default"}, cm); err != nil {
+			t.Fatalf("failed to get config map: %v", err)
+		}
+		return cm
+	}
+
+	reconcile(true)
+
+	// The quantities are semantically equal, the resource is left as is
+	cpu = "1000m"
+	if cm := reconcile(true); cm.Data["cpu"] != "1" || updates != 0 {
+		t.Fatalf("expected the update to be skipped, got %v after %d updates", cm.Data, updates)
+	}
+
+	if cm := reconcile(false); cm.Data["cpu"] != "1000m" || updates != 1 {
+		t.Fatalf("expected the resource to be updated without equality function, got %v after %d updates", cm.Data, updates)
+	}
+}
+
+func TestReconcileResourceStep_AdmissionWebhookValidation(t *testing.T) {
+	var reviews []admissionv1.AdmissionReview
+	webhook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		review := admissionv1.AdmissionReview{}
+		if err := json.NewDecoder(r.Body).Decode(&review); err != nil {
+			t.Errorf("failed to decode review: %v", err)
+		}
+		reviews = append(reviews, review)
+
+		secret := &corev1.Secret{}
+		if err := json.Unmarshal(review.Request.Object.Raw, secret); err != nil {
+			t.Errorf("failed to decode object: %v", err)
+		}
+		review.Response = &admissionv1.AdmissionResponse{UID: review.Request.UID, Allowed: secret.Labels["team"] != ""}
+		if !review.Response.Allowed {
+			review.Response.Result = &metav1.Status{Message: "the team label is required"}
+		}
+		_ = json.NewEncoder(w).Encode(review)
+	}))
+	defer webhook.Close()
+
+	ctx, reconciler := newDeletionTest(t, interceptor.Funcs{})
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Name: "cr", Namespace: "default"}}
+	key := types.NamespacedName{Name: "new-secret", Namespace: "default"}
+
+	newResource := func(team string) ctrlfwk.GenericResource[*corev1.ConfigMap, ctrlfwk.Context[*corev1.ConfigMap]] {
+		return ctrlfwk.NewResourceBuilder(ctx, &corev1.Secret{}).
+			WithKey(key).
+			WithAdmissionWebhookValidation(webhook.URL).
+			WithMutator(func(secret *corev1.Secret) error {
+				if team != "" {
+					secret.Labels = map[string]string{"team": team}
+				}
+				return nil
+			}).
+			Build()
+	}
+
+	result, err := ctrlfwk.NewReconcileResourceStep(ctx, reconciler, newResource("")).Step(ctx, logr.Discard(), req).Normal()
+	if err != nil || result.RequeueAfter != 0 {
+		t.Fatalf("expected the denied resource to stop the reconciliation without requeue, got %v and %v", result, err)
+	}
+	if err := reconciler.Get(ctx, key, &corev1.Secret{}); !apierrors.IsNotFound(err) {
+		t.Fatalf("expected the secret not to be created, got %v", err)
+	}
+	if len(reviews) != 1 || reviews[0].Request.Operation != admissionv1.Create || reviews[0].Request.Kind.Kind != "Secret" {
+		t.Fatalf("unexpected reviews: %+v", reviews)
+	}
+
+	if _, err := ctrlfwk.NewReconcileResourceStep(ctx, reconciler, newResource("payments")).Step(ctx, logr.Discard(), req).Normal(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := reconciler.Get(ctx, key, &corev1.Secret{}); err != nil {
+		t.Fatalf("expected the secret to be created, got %v", err)
+	}
+
+	// Resources that would not change are not reviewed again
+	if _, err := ctrlfwk.NewReconcileResourceStep(ctx, reconciler, newResource("payments")).Step(ctx, logr.Discard(), req).Normal(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(reviews) != 2 {
+		t.Fatalf("expected 2 reviews, got %d", len(reviews))
+	}
+}
+
+func TestResourceBuilder_WithKeyFromStatusFallback(t *testing.T) {
+	ctx := ctrlfwk.NewContext[*corev1.ConfigMap](context.Background(), nil)
+	ctx.SetCustomResource(&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "cr", Namespace: "default"}})
+
+	enabled := true
+	statusName := ""
+	resource := ctrlfwk.NewResourceBuilder(ctx, &corev1.ConfigMap{}).
+		WithKeyFromStatusFallback(
+			func() types.NamespacedName { return types.NamespacedName{Name: "from-spec", Namespace: "default"} },
+			func() types.NamespacedName { return types.NamespacedName{Name: statusName, Namespace: "default"} },
+			func() bool { return !enabled },
+		).
+		Build()
+
+	cases := []struct {
+		enabled    bool
+		statusName string
+		want       string
+	}{
+		{enabled: true, statusName: "from-status", want: "from-spec"},
+		{enabled: false, statusName: "from-status", want: "from-status"},
+		// Nothing was recorded in the status, e.g. the resource was never created
+		{enabled: false, statusName: "", want: "from-spec"},
+	}
+	for _, tc := range cases {
+		enabled, statusName = tc.enabled, tc.statusName
+		obj, _, err := resource.ObjectMetaGenerator()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if obj.GetName() != tc.want {
+			t.Errorf("enabled=%t status=%q: expected %s, got %s", tc.enabled, tc.statusName, tc.want, obj.GetName())
+		}
+	}
+}
+
+func TestReconcileResourceStep_Subresources(t *testing.T) {
+	var scaleUpdates, statusPatches int
+	cr := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "cr", Namespace: "default"}}
+	reconciler := &fakeReconciler{
+		Client: fake.NewClientBuilder().
+			WithObjects(cr).
+			WithStatusSubresource(&appsv1.Deployment{}).
+			WithInterceptorFuncs(interceptor.Funcs{
+				SubResourceUpdate: func(ctx context.Context, c client.Client, subResource string, obj client.Object, opts ...client.SubResourceUpdateOption) error {
+					if subResource == "scale" {
+						scaleUpdates++
+					}
+					return c.SubResource(subResource).Update(ctx, obj, opts...)
+				},
+				SubResourcePatch: func(ctx context.Context, c client.Client, subResource string, obj client.Object, patch client.Patch, opts ...client.SubResourcePatchOption) error {
+					if subResource == "status" {
+						statusPatches++
+					}
+					return c.SubResource(subResource).Patch(ctx, obj, patch, opts...)
+				},
+			}).
+			Build(),
+	}
+	ctx := ctrlfwk.NewContext(context.Background(), reconciler)
+	ctx.SetCustomResource(cr)
+
+	replicas := int32(3)
+	var scaled, statusUpdated []int32
+	resource := ctrlfwk.NewResourceBuilder(ctx, &appsv1.Deployment{}).
+		WithKey(types.NamespacedName{Name: "app", Namespace: "default"}).
+		WithMutator(func(deploy *appsv1.Deployment) error {
+			deploy.Spec.Selector = &metav1.LabelSelector{MatchLabels: map[string]string{"app": "app"}}
+			deploy.Spec.Template.Labels = map[string]string{"app": "app"}
+			return nil
+		}).
+		WithScale(func(ctrlfwk.Context[*corev1.ConfigMap]) int32 { return replicas }).
+		WithStatusMutator(func(deploy *appsv1.Deployment) error {
+			deploy.Status.Replicas = *deploy.Spec.Replicas
+			return nil
+		}).
+		WithAfterScale(func(_ ctrlfwk.Context[*corev1.ConfigMap], deploy *appsv1.Deployment) error {
+			scaled = append(scaled, *deploy.Spec.Replicas)
+			return nil
+		}).
+		WithAfterStatusUpdate(func(_ ctrlfwk.Context[*corev1.ConfigMap], deploy *appsv1.Deployment) error {
+			statusUpdated = append(statusUpdated, deploy.Status.Replicas)
+			return nil
+		}).
+		WithReadinessCondition(func(*appsv1.Deployment) bool { return true }).
+		WithoutOwnerReference().
+		Build()
+
+	reconcile := func() {
+		t.Helper()
+		if _, err := ctrlfwk.NewReconcileResourceStep(ctx, reconciler, resource).Step(ctx, logr.Discard(), ctrl.Request{}).Normal(); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	reconcile()
+	if scaleUpdates != 1 || statusPatches != 1 {
+		t.Fatalf("expected the scale and the status to be written once, got %d scale updates and %d status patches", scaleUpdates, statusPatches)
+	}
+	deploy := &appsv1.Deployment{}
+	if err := reconciler.Get(ctx, types.NamespacedName{Name: "app", Namespace: "default"}, deploy); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if *deploy.Spec.Replicas != 3 || deploy.Status.Replicas != 3 {
+		t.Fatalf("expected the deployment to be scaled to 3 replicas, got %d and status %d", *deploy.Spec.Replicas, deploy.Status.Replicas)
+	}
+
+	// Subresources that did not drift are not written
+	reconcile()
+	if scaleUpdates != 1 || statusPatches != 1 {
+		t.Fatalf("expected no write of unchanged subresources, got %d scale updates and %d status patches", scaleUpdates, statusPatches)
+	}
+
+	replicas = 5
+	reconcile()
+	if scaleUpdates != 2 || statusPatches != 2 {
+		t.Fatalf("expected the scale and the status to be written again, got %d scale updates and %d status patches", scaleUpdates, statusPatches)
+	}
+	if !slices.Equal(scaled, []int32{3, 5}) || !slices.Equal(statusUpdated, []int32{3, 5}) {
+		t.Fatalf("expected the hooks to run once per write, got scaled %v and status updated %v", scaled, statusUpdated)
+	}
+}
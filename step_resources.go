@@ -1,18 +1,52 @@
 package ctrlfwk
 
 import (
+	stderrors "errors"
+	"fmt"
+	"slices"
+	"strings"
+
 	"github.com/go-logr/logr"
 	"github.com/pkg/errors"
+	"golang.org/x/sync/errgroup"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	ctrl "sigs.k8s.io/controller-runtime"
 )
 
+// ReconcileResourcesOption configures NewReconcileResourcesStep.
+type ReconcileResourcesOption func(*reconcileResourcesConfig)
+
+type reconcileResourcesConfig struct {
+	concurrency int
+	// minReadyFraction enables the ChildrenReady condition, see WithChildReadinessAggregation
+	minReadyFraction *float64
+}
+
+// WithConcurrency reconciles up to n resources concurrently. Resources are grouped by their ordering
+// constraints (see ResourceBuilder.WithDependsOn), the resources of a group being reconciled concurrently.
+//
+// While resources are reconciled concurrently, the context is shared by the hooks and mutators,
+// which must use LockContext before modifying it. The status patches of the step are sent at once
+// when every resource is reconciled, and the logger of the context is not scoped to each resource.
+func WithConcurrency(n int) ReconcileResourcesOption {
+	return func(config *reconcileResourcesConfig) {
+		config.concurrency = n
+	}
+}
+
 func NewReconcileResourcesStep[
 	ControllerResourceType ControllerCustomResource,
 	ContextType Context[ControllerResourceType],
 ](
 	_ ContextType,
 	reconciler ReconcilerWithResources[ControllerResourceType, ContextType],
+	opts ...ReconcileResourcesOption,
 ) Step[ControllerResourceType, ContextType] {
+	config := reconcileResourcesConfig{concurrency: 1}
+	for _, opt := range opts {
+		opt(&config)
+	}
+
 	return Step[ControllerResourceType, ContextType]{
 		Name: StepReconcileResources,
 		Step: func(ctx ContextType, logger logr.Logger, req ctrl.Request) StepResult {
@@ -21,27 +55,115 @@ func NewReconcileResourcesStep[
 				return ResultInError(errors.Wrap(err, "failed to get resources"))
 			}
 
+			groups, err := groupResourcesByDependencies(resources)
+			if err != nil {
+				return ResultInError(errors.Wrap(err, "invalid resource dependencies"))
+			}
+
+			concurrent := config.concurrency > 1 && len(resources) > 1
+			if concurrent && !inStatusTransaction(ctx) {
+				// Conditions are only set in memory while the resources are reconciled, then patched at once
+				BeginStatusTransaction(ctx)
+				defer func() {
+					if err := CommitStatusTransaction(ctx, reconciler); err != nil {
+						logger.Error(err, "Failed to patch custom resource status")
+					}
+				}()
+			}
+
 			var returnResults []StepResult
+			var hookErrors []error
+			var blocked FinalizationBlockedError
+			var blockedCauses []error
+			notReconciled := make(map[string]bool)
+			var children int
+			var notReadyChildren []string
 
-			for _, resource := range resources {
-				subStepLogger := logger.WithValues("resource", resource.ID())
+			for _, group := range groups {
+				var runnable []GenericResource[ControllerResourceType, ContextType]
+				for _, resource := range group {
+					if !resource.ShouldDeleteNow() {
+						children++
+					}
+					if slices.ContainsFunc(resource.DependsOn(), func(id string) bool { return notReconciled[id] }) {
+						logger.Info("Waiting for the resources it depends on", "resource", resource.ID(), "dependsOn", resource.DependsOn())
+						notReconciled[resource.ID()] = true
+						if !resource.ShouldDeleteNow() {
+							notReadyChildren = append(notReadyChildren, resource.ID())
+						}
+						continue
+					}
+					runnable = append(runnable, resource)
+				}
 
-				subStep := NewReconcileResourceStep(ctx, reconciler, resource)
-				result := subStep.Step(ctx, subStepLogger, req)
-				if result.ShouldReturn() {
-					subStepLogger.Info("Resource reconciliation resulted in early return or error")
-					returnResults = append(returnResults, result)
-					continue
+				results := reconcileResourceGroup(ctx, reconciler, logger, req, runnable, config.concurrency)
+
+				for i, resource := range runnable {
+					subStepLogger := logger.WithValues("resource", resource.ID())
+
+					result := results[i]
+					if result.ShouldReturn() {
+						notReconciled[resource.ID()] = true
+						if !resource.ShouldDeleteNow() {
+							notReadyChildren = append(notReadyChildren, resource.ID())
+						}
+
+						var hookErr *continuedHookError
+						if stderrors.As(result.err, &hookErr) {
+							subStepLogger.Info("Resource hook failed, continuing as per error policy")
+							hookErrors = append(hookErrors, hookErr.HookError)
+							continue
+						}
+
+						var blockedErr *FinalizationBlockedError
+						if stderrors.As(result.err, &blockedErr) {
+							subStepLogger.Info("Resource deletion was vetoed, finalization is blocked")
+							blocked.ResourceIDs = append(blocked.ResourceIDs, blockedErr.ResourceIDs...)
+							blockedCauses = append(blockedCauses, blockedErr.Err)
+							continue
+						}
+
+						subStepLogger.Info("Resource reconciliation resulted in early return or error")
+						returnResults = append(returnResults, result)
+						continue
+					}
+					subStepLogger.Info("Reconciled resource successfully")
 				}
-				subStepLogger.Info("Reconciled resource successfully")
 			}
 
-			// Return result errors first
+			if err := setResourceHooksFailedCondition(ctx, reconciler, hookErrors); err != nil {
+				logger.Error(err, "Failed to update hooks failure condition")
+			}
+
+			if config.minReadyFraction != nil && !IsFinalizing(ctx.GetCustomResource()) {
+				if err := setChildrenReadyCondition(ctx, reconciler, *config.minReadyFraction, children, notReadyChildren); err != nil {
+					logger.Error(err, "Failed to update children readiness condition")
+				}
+			}
+
+			// Return result errors first, all of them
+			var errs []error
 			for _, result := range returnResults {
 				if result.err != nil {
-					return result
+					errs = append(errs, result.err)
 				}
 			}
+			errs = append(errs, hookErrors...)
+
+			// The requeues of the resources that are not ready are dropped along with the errors,
+			// the backoff of the reconciliation in error checking them again
+			if len(errs) == 1 {
+				return resultFromError(logger, errs[0])
+			}
+			if len(errs) > 1 {
+				return ResultInError(stderrors.Join(errs...))
+			}
+
+			// All the resources vetoing their deletion are reported at once
+			if len(blocked.ResourceIDs) > 0 {
+				blocked.Err = stderrors.Join(blockedCauses...)
+				return resultFromError(logger, &blocked)
+			}
 
 			for _, result := range returnResults {
 				if result.ShouldReturn() {
@@ -53,3 +175,129 @@ func NewReconcileResourcesStep[
 		},
 	}
 }
+
+// reconcileResourceGroup reconciles resources without ordering constraints between them,
+// up to concurrency at a time. The results are in the same order as the resources.
+func reconcileResourceGroup[
+	ControllerResourceType ControllerCustomResource,
+	ContextType Context[ControllerResourceType],
+](
+	ctx ContextType,
+	reconciler ReconcilerWithResources[ControllerResourceType, ContextType],
+	logger logr.Logger,
+	req ctrl.Request,
+	resources []GenericResource[ControllerResourceType, ContextType],
+	concurrency int,
+) []StepResult {
+	results := make([]StepResult, len(resources))
+
+	if concurrency <= 1 || len(resources) <= 1 {
+		for i, resource := range resources {
+			results[i] = newReconcileResourceStep(ctx, reconciler, resource).Step(ctx, logger, req)
+		}
+		return results
+	}
+
+	parent := ctx.GetParentContext()
+	ctx.SetParentContext(withConcurrentReconciliation(parent))
+	defer ctx.SetParentContext(parent)
+
+	var group errgroup.Group
+	group.SetLimit(concurrency)
+	for i, resource := range resources {
+		group.Go(func() error {
+			results[i] = newReconcileResourceStep(ctx, reconciler, resource).Step(ctx, logger, req)
+			return nil
+		})
+	}
+	_ = group.Wait()
+
+	return results
+}
+
+// groupResourcesByDependencies splits the resources in groups that can be reconciled one after the other,
+// each resource being in a group after the resources it depends on. The declaration order is kept within a group,
+// so that resources are always reconciled in the same order. Resource IDs must be unique.
+func groupResourcesByDependencies[
+	ControllerResourceType ControllerCustomResource,
+	ContextType Context[ControllerResourceType],
+](
+	resources []GenericResource[ControllerResourceType, ContextType],
+) ([][]GenericResource[ControllerResourceType, ContextType], error) {
+	known := make(map[string]bool, len(resources))
+	for _, resource := range resources {
+		if known[resource.ID()] {
+			return nil, fmt.Errorf("duplicate resource ID %s, use WithUserIdentifier to tell the resources apart", resource.ID())
+		}
+		known[resource.ID()] = true
+	}
+	for _, resource := range resources {
+		for _, id := range resource.DependsOn() {
+			if !known[id] {
+				return nil, fmt.Errorf("resource %s depends on unknown resource %s", resource.ID(), id)
+			}
+		}
+	}
+
+	var groups [][]GenericResource[ControllerResourceType, ContextType]
+	placed := make(map[string]bool, len(resources))
+	remaining := resources
+
+	for len(remaining) > 0 {
+		var group, next []GenericResource[ControllerResourceType, ContextType]
+		for _, resource := range remaining {
+			if slices.ContainsFunc(resource.DependsOn(), func(id string) bool { return !placed[id] }) {
+				next = append(next, resource)
+				continue
+			}
+			group = append(group, resource)
+		}
+
+		if len(group) == 0 {
+			ids := make([]string, 0, len(next))
+			for _, resource := range next {
+				ids = append(ids, resource.ID())
+			}
+			return nil, fmt.Errorf("dependency cycle between resources %s", strings.Join(ids, ", "))
+		}
+
+		for _, resource := range group {
+			placed[resource.ID()] = true
+		}
+		groups = append(groups, group)
+		remaining = next
+	}
+
+	return groups, nil
+}
+
+// setResourceHooksFailedCondition reflects the aggregated hook errors on the custom resource status.
+// The condition is removed once no hook is failing anymore.
+func setResourceHooksFailedCondition[
+	ControllerResourceType ControllerCustomResource,
+	ContextType Context[ControllerResourceType],
+](
+	ctx ContextType,
+	reconciler Reconciler[ControllerResourceType],
+	hookErrors []error,
+) error {
+	cr := ctx.GetCustomResource()
+
+	if len(hookErrors) == 0 {
+		_, err := applyCondition(ctx, reconciler, ConditionTypeResourceHooksFailed, nil)
+		return err
+	}
+
+	messages := make([]string, 0, len(hookErrors))
+	for _, hookErr := range hookErrors {
+		messages = append(messages, hookErr.Error())
+	}
+
+	_, err := applyCondition(ctx, reconciler, ConditionTypeResourceHooksFailed, &metav1.Condition{
+		Status:             metav1.ConditionTrue,
+		Reason:             "HooksFailed",
+		Message:            fmt.Sprintf("%d resource hook(s) failed: %s", len(hookErrors), strings.Join(messages, "; ")),
+		ObservedGeneration: cr.GetGeneration(),
+	})
+	return err
+}
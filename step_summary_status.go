@@ -0,0 +1,128 @@
+package ctrlfwk
+
+import (
+	"github.com/go-logr/logr"
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// ConditionTypeReconcileSummary is set on the custom resource by the SummaryStatusStep,
+	// its message summarizes the ReconcileReport of the last reconciliation.
+	ConditionTypeReconcileSummary = "ReconcileSummary"
+)
+
+// NewSummaryStatusStep writes a summary of the ReconcileReport into the ReconcileSummary condition of the custom resource,
+// e.g. "3 resources up to date, 1 waiting (Deployment frontend not ready), dependencies ok".
+//
+// When summaryField is not nil, the summary is written to the status field it returns as well, nil skipping the update.
+// For custom status schemas, use ctx.GetReconcileReport() in your own step instead.
+//
+// As steps returning early prevent the following ones from running, use SummaryStatusMiddleware
+// to have the summary written whatever the outcome of the reconciliation.
+//
+// Example:
+//
+//	.WithStep(ctrlfwk.NewSummaryStatusStep(ctx, reconciler, func(cr *MyCustomResource) *string {
+//		return &cr.Status.Summary
+//	}))
+func NewSummaryStatusStep[
+	ControllerResourceType ControllerCustomResource,
+	ContextType Context[ControllerResourceType],
+](
+	_ ContextType,
+	reconciler Reconciler[ControllerResourceType],
+	summaryField func(cr ControllerResourceType) *string,
+) Step[ControllerResourceType, ContextType] {
+	return Step[ControllerResourceType, ContextType]{
+		Name: StepSummaryStatus,
+		Step: func(ctx ContextType, logger logr.Logger, req ctrl.Request) StepResult {
+			if err := setReconcileSummary(ctx, reconciler, summaryField); err != nil {
+				return ResultInError(errors.Wrap(err, "failed to set reconcile summary"))
+			}
+			return ResultSuccess()
+		},
+	}
+}
+
+// SummaryStatusMiddleware writes a summary of the ReconcileReport into the status of the custom resource
+// once the steps are executed, whether they succeeded or not. See NewSummaryStatusStep.
+func SummaryStatusMiddleware[
+	ControllerResourceType ControllerCustomResource,
+	ContextType Context[ControllerResourceType],
+](
+	reconciler Reconciler[ControllerResourceType],
+	summaryField func(cr ControllerResourceType) *string,
+) Middleware[ControllerResourceType, ContextType] {
+	return MiddlewareFunc[ControllerResourceType, ContextType](func(next ReconcileFunc[ControllerResourceType, ContextType]) ReconcileFunc[ControllerResourceType, ContextType] {
+		return func(ctx ContextType, req ctrl.Request) (ctrl.Result, error) {
+			result, err := next(ctx, req)
+
+			// The custom resource was not found, or is being deleted
+			if cr := ctx.GetCustomResource(); cr.GetName() == "" || IsFinalizing(cr) {
+				return result, err
+			}
+
+			if summaryErr := setReconcileSummary(ctx, reconciler, summaryField); client.IgnoreNotFound(summaryErr) != nil && err == nil {
+				return result, errors.Wrap(summaryErr, "failed to set reconcile summary")
+			}
+
+			return result, err
+		}
+	})
+}
+
+// setReconcileSummary renders the report of the reconciliation into the ReconcileSummary condition
+// and the summary field of the custom resource.
+func setReconcileSummary[
+	ControllerResourceType ControllerCustomResource,
+	ContextType Context[ControllerResourceType],
+](
+	ctx ContextType,
+	reconciler Reconciler[ControllerResourceType],
+	summaryField func(cr ControllerResourceType) *string,
+) error {
+	defer LockContext(ctx)()
+
+	cr := ctx.GetCustomResource()
+	report := ctx.GetReconcileReport()
+	summary := report.Summary()
+
+	status := metav1.ConditionTrue
+	reason := "UpToDate"
+	switch {
+	case report.HasErrors():
+		status = metav1.ConditionFalse
+		reason = "Failing"
+	case !report.IsReady():
+		status = metav1.ConditionFalse
+		reason = "Waiting"
+	}
+
+	var summaryChanged bool
+	if summaryField != nil {
+		if field := summaryField(cr); field != nil && *field != summary {
+			*field = summary
+			summaryChanged = true
+		}
+	}
+
+	// The summary field is patched along with the condition, when the condition changed
+	patched, err := applyCondition(ctx, reconciler, ConditionTypeReconcileSummary, &metav1.Condition{
+		Status:             status,
+		Reason:             reason,
+		Message:            summary,
+		ObservedGeneration: cr.GetGeneration(),
+	})
+	if err != nil {
+		return err
+	}
+
+	if summaryChanged && !patched {
+		return PatchCustomResourceStatus(ctx, reconciler)
+	}
+
+	return nil
+}
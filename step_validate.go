@@ -0,0 +1,82 @@
+package ctrlfwk
+
+import (
+	"github.com/go-logr/logr"
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+const (
+	// ConditionTypeSpecInvalid is set on the custom resource when its spec is rejected by a ValidateStep.
+	ConditionTypeSpecInvalid = "SpecInvalid"
+)
+
+// ValidateFunc validates the custom resource held by the context, returning the invalid fields.
+type ValidateFunc[ControllerResourceType ControllerCustomResource, ContextType Context[ControllerResourceType]] func(ctx ContextType) field.ErrorList
+
+// NewValidateStep validates the spec of the custom resource before anything gets reconciled.
+//
+// When validate returns errors, the custom resource gets a SpecInvalid condition listing them, a warning
+// event is emitted if the reconciler is a record.EventRecorder, and the reconciliation stops without being
+// requeued: retrying an invalid spec is pointless. The custom resource is only validated again once its
+// generation changes. The condition is removed once the spec is valid.
+//
+// Custom resources being deleted are not validated, so they can always be finalized.
+func NewValidateStep[
+	ControllerResourceType ControllerCustomResource,
+	ContextType Context[ControllerResourceType],
+](
+	_ ContextType,
+	reconciler Reconciler[ControllerResourceType],
+	validate ValidateFunc[ControllerResourceType, ContextType],
+) Step[ControllerResourceType, ContextType] {
+	return Step[ControllerResourceType, ContextType]{
+		Name: StepValidateCustomResource,
+		Step: func(ctx ContextType, logger logr.Logger, req ctrl.Request) StepResult {
+			cr := ctx.GetCustomResource()
+
+			if IsFinalizing(cr) {
+				return ResultSuccess()
+			}
+
+			// The same spec has already been rejected, wait for the next generation
+			condition, err := getStatusCondition(cr, ConditionTypeSpecInvalid)
+			if err != nil {
+				return ResultInError(errors.Wrap(err, "failed to get spec invalid condition"))
+			}
+			if condition != nil && condition.Status == metav1.ConditionTrue && condition.ObservedGeneration == cr.GetGeneration() {
+				logger.Info("Custom resource spec is invalid, waiting for it to change", "generation", cr.GetGeneration())
+				return ResultEarlyReturn().WithRequeueReason(RequeueReasonCustomResourceInvalid)
+			}
+
+			errs := validate(ctx)
+			if len(errs) == 0 {
+				if _, err := applyCondition(ctx, reconciler, ConditionTypeSpecInvalid, nil); err != nil {
+					return ResultInError(errors.Wrap(err, "failed to remove spec invalid condition"))
+				}
+				return ResultSuccess()
+			}
+
+			message := errs.ToAggregate().Error()
+			logger.Info("Custom resource spec is invalid, stopping reconciliation", "errors", message)
+
+			if recorder, ok := reconciler.(record.EventRecorder); ok {
+				recorder.Event(cr, "Warning", ConditionTypeSpecInvalid, message)
+			}
+
+			if _, err := applyCondition(ctx, reconciler, ConditionTypeSpecInvalid, &metav1.Condition{
+				Status:             metav1.ConditionTrue,
+				Reason:             "ValidationFailed",
+				Message:            message,
+				ObservedGeneration: cr.GetGeneration(),
+			}); err != nil {
+				return ResultInError(errors.Wrap(err, "failed to set spec invalid condition"))
+			}
+
+			return ResultEarlyReturn().WithRequeueReason(RequeueReasonCustomResourceInvalid)
+		},
+	}
+}
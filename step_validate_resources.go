@@ -0,0 +1,170 @@
+package ctrlfwk
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// ConditionTypeResourceInvariantViolated is set on the custom resource when existing resources
+	// are rejected by a ValidateResourcesStep.
+	ConditionTypeResourceInvariantViolated = "ResourceInvariantViolated"
+)
+
+// ValidationSeverity tells how a ValidationIssue is handled by the ValidateResourcesStep.
+type ValidationSeverity string
+
+const (
+	// ValidationSeverityWarning issues are logged and reported on the custom resource, the reconciliation goes on.
+	ValidationSeverityWarning ValidationSeverity = "Warning"
+	// ValidationSeverityError issues are reported on the custom resource and the reconciliation is requeued.
+	ValidationSeverityError ValidationSeverity = "Error"
+)
+
+// ValidationIssue is an invariant an existing resource does not satisfy.
+type ValidationIssue struct {
+	Severity ValidationSeverity
+	Message  string
+}
+
+// Validator checks the invariants of a resource as it exists in the cluster.
+type Validator[ResourceType client.Object] func(obj ResourceType) []ValidationIssue
+
+type resourceValidationIssue struct {
+	resourceID string
+	ValidationIssue
+}
+
+func (i resourceValidationIssue) String() string {
+	return fmt.Sprintf("resource %s: %s", i.resourceID, i.Message)
+}
+
+// NewValidateResourcesStep validates the resources of type ResourceType declared by the reconciler, as they currently
+// exist in the cluster, for example to detect a manual intervention or an upgrade breaking what the controller relies on.
+//
+// Unlike the pre-mutate validator, it checks the live state rather than the desired one, and runs whether or not
+// the resources are written during the reconciliation. Resources that do not exist yet or are skipped are not validated.
+//
+// All the issues are listed in the ResourceInvariantViolated condition, which is removed once there are none.
+// Warnings are logged and the reconciliation goes on, errors requeue the reconciliation.
+func NewValidateResourcesStep[
+	ControllerResourceType ControllerCustomResource,
+	ContextType Context[ControllerResourceType],
+	ResourceType client.Object,
+](
+	_ ContextType,
+	reconciler ReconcilerWithResources[ControllerResourceType, ContextType],
+	validator Validator[ResourceType],
+) Step[ControllerResourceType, ContextType] {
+	return Step[ControllerResourceType, ContextType]{
+		Name: StepValidateResources,
+		Step: func(ctx ContextType, logger logr.Logger, req ctrl.Request) StepResult {
+			if IsFinalizing(ctx.GetCustomResource()) {
+				return ResultSuccess()
+			}
+
+			resources, err := reconciler.GetResources(ctx, req)
+			if err != nil {
+				return ResultInError(errors.Wrap(err, "failed to get resources"))
+			}
+
+			var issues []resourceValidationIssue
+			var hasErrors bool
+
+			for _, resource := range resources {
+				obj, skip, err := generateResourceObject(ctx, resource)
+				if err != nil {
+					return ResultInError(errors.Wrapf(err, "failed to generate object of resource %s", resource.ID()))
+				}
+				if skip || obj == nil {
+					continue
+				}
+
+				// The object of the resource is shared with the reconcile step, read the live state into a copy
+				current, ok := obj.DeepCopyObject().(ResourceType)
+				if !ok {
+					continue
+				}
+
+				c, _, result := clientFor(ctx, reconciler, resource, resource.ID())
+				if result.ShouldReturn() {
+					return result
+				}
+
+				if err := c.Get(ctx, client.ObjectKeyFromObject(obj), current); err != nil {
+					if client.IgnoreNotFound(err) == nil {
+						continue
+					}
+					return ResultInError(errors.Wrapf(err, "failed to get resource %s", resource.ID()))
+				}
+
+				for _, issue := range validator(current) {
+					issue := resourceValidationIssue{resourceID: resource.ID(), ValidationIssue: issue}
+					if issue.Severity == ValidationSeverityError {
+						hasErrors = true
+						logger.Info("Resource violates an invariant", "resource", resource.ID(), "issue", issue.Message)
+					} else {
+						logger.Info("Resource violates an invariant, ignoring as a warning", "resource", resource.ID(), "issue", issue.Message)
+					}
+					issues = append(issues, issue)
+				}
+			}
+
+			if err := setResourceInvariantViolatedCondition(ctx, reconciler, issues, hasErrors); err != nil {
+				return ResultInError(errors.Wrap(err, "failed to update resource invariant violated condition"))
+			}
+
+			if hasErrors {
+				return ResultRequeueIn(30 * time.Second).WithRequeueReason(RequeueReasonResourceInvariantViolated)
+			}
+
+			return ResultSuccess()
+		},
+	}
+}
+
+// setResourceInvariantViolatedCondition lists the issues of the existing resources on the custom resource status.
+// The condition is removed once there are none.
+func setResourceInvariantViolatedCondition[
+	ControllerResourceType ControllerCustomResource,
+	ContextType Context[ControllerResourceType],
+](
+	ctx ContextType,
+	reconciler Reconciler[ControllerResourceType],
+	issues []resourceValidationIssue,
+	hasErrors bool,
+) error {
+	defer LockContext(ctx)()
+
+	cr := ctx.GetCustomResource()
+
+	if len(issues) == 0 {
+		_, err := applyCondition(ctx, reconciler, ConditionTypeResourceInvariantViolated, nil)
+		return err
+	}
+
+	reason := "InvariantWarning"
+	if hasErrors {
+		reason = "InvariantViolated"
+	}
+
+	messages := make([]string, 0, len(issues))
+	for _, issue := range issues {
+		messages = append(messages, issue.String())
+	}
+
+	_, err := applyCondition(ctx, reconciler, ConditionTypeResourceInvariantViolated, &metav1.Condition{
+		Status:             metav1.ConditionTrue,
+		Reason:             reason,
+		Message:            strings.Join(messages, "; "),
+		ObservedGeneration: cr.GetGeneration(),
+	})
+	return err
+}
@@ -0,0 +1,89 @@
+package ctrlfwk_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/go-logr/logr"
+	ctrlfwk "github.com/u-ctf/controller-fwk"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+type validatingReconciler struct {
+	*conditionsReconciler
+	*record.FakeRecorder
+}
+
+func TestValidateStep_SpecInvalid(t *testing.T) {
+	ctx, inner := newConditionsTest(t)
+	reconciler := &validatingReconciler{conditionsReconciler: inner, FakeRecorder: record.NewFakeRecorder(10)}
+	key := types.NamespacedName{Name: "cr", Namespace: "default"}
+
+	replicas := -1
+	var validations int
+	step := ctrlfwk.NewValidateStep(ctx, reconciler, func(ctx conditionsContext) field.ErrorList {
+		validations++
+		if replicas < 0 {
+			return field.ErrorList{field.Invalid(field.NewPath("spec", "replicas"), replicas, "must be positive")}
+		}
+		return nil
+	})
+	setGeneration := func(generation int64) {
+		t.Helper()
+		cr := &conditionsCR{}
+		if err := reconciler.Get(ctx, key, cr); err != nil {
+			t.Fatalf("failed to get custom resource: %v", err)
+		}
+		cr.SetGeneration(generation)
+		if err := reconciler.Update(ctx, cr); err != nil {
+			t.Fatalf("failed to update custom resource: %v", err)
+		}
+		ctx.SetCustomResource(cr)
+	}
+	specInvalid := func() *metav1.Condition {
+		t.Helper()
+		cr := &conditionsCR{}
+		if err := reconciler.Get(ctx, key, cr); err != nil {
+			t.Fatalf("failed to get custom resource: %v", err)
+		}
+		return meta.FindStatusCondition(cr.Status.Conditions, ctrlfwk.ConditionTypeSpecInvalid)
+	}
+	setGeneration(1)
+
+	// The invalid spec stops the reconciliation without requeueing it
+	result := step.Step(ctx, logr.Discard(), ctrl.Request{NamespacedName: key})
+	if res, err := result.Normal(); !result.ShouldReturn() || err != nil || !res.IsZero() {
+		t.Fatalf("expected the reconciliation to stop without requeue, got %v, %v", res, err)
+	}
+	if condition := specInvalid(); condition == nil || condition.Status != metav1.ConditionTrue ||
+		!strings.Contains(condition.Message, "spec.replicas") || condition.ObservedGeneration != 1 {
+		t.Fatalf("expected a SpecInvalid condition listing the invalid field, got %v", condition)
+	}
+	if event := <-reconciler.Events; !strings.HasPrefix(event, "Warning SpecInvalid") {
+		t.Fatalf("expected a warning event, got %q", event)
+	}
+
+	// The same generation is not validated again
+	if result := step.Step(ctx, logr.Discard(), ctrl.Request{NamespacedName: key}); !result.ShouldReturn() {
+		t.Fatal("expected the reconciliation to stay stopped for the same generation")
+	}
+	if validations != 1 || len(reconciler.Events) != 0 {
+		t.Fatalf("expected no validation nor event for the same generation, got %d validations", validations)
+	}
+
+	// The next generation is validated again and resumes the reconciliation
+	replicas = 1
+	setGeneration(2)
+	if result := step.Step(ctx, logr.Discard(), ctrl.Request{NamespacedName: key}); result.ShouldReturn() {
+		t.Fatalf("expected the reconciliation to resume, got %v", result)
+	}
+	if condition := specInvalid(); condition != nil {
+		t.Fatalf("expected the SpecInvalid condition to be removed, got %v", condition)
+	}
+}
@@ -1,12 +1,19 @@
 package ctrlfwk
 
 import (
+	"context"
+	stderrors "errors"
+	"reflect"
 	"time"
 
 	"github.com/go-logr/logr"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 )
 
 // Stepper is a utility to execute a series of steps in a controller.
@@ -16,13 +23,31 @@ import (
 // The Stepper can be used in a controller's Reconcile function to manage
 // the execution of multiple steps in a clean and organized manner.
 type Stepper[K client.Object, C Context[K]] struct {
-	logger logr.Logger
-	steps  []Step[K, C]
+	logger        logr.Logger
+	steps         []Step[K, C]
+	requeueJitter float64
+	middlewares   []Middleware[K, C]
+	// statusBatching is the reconciler used to flush the batched status, nil when the status is not batched
+	statusBatching Reconciler[K]
+	// atomicStatus rolls the batched status back when a step fails
+	atomicStatus bool
+	// terminalErrors is the reconciler used to set the TerminalError condition, nil when it is not set
+	terminalErrors Reconciler[K]
+	// timeout bounds each reconciliation, 0 when it is not bounded
+	timeout time.Duration
 }
 
+const stepperTracerName = "github.com/u-ctf/controller-fwk"
+
 type StepperBuilder[K client.Object, C Context[K]] struct {
-	logger logr.Logger
-	steps  []Step[K, C]
+	logger         logr.Logger
+	steps          []Step[K, C]
+	requeueJitter  float64
+	middlewares    []Middleware[K, C]
+	statusBatching Reconciler[K]
+	atomicStatus   bool
+	terminalErrors Reconciler[K]
+	timeout        time.Duration
 }
 
 func NewStepperFor[K client.Object, C Context[K]](ctx C, logger logr.Logger) *StepperBuilder[K, C] {
@@ -38,24 +63,98 @@ func (s *StepperBuilder[K, C]) WithStep(step Step[K, C]) *StepperBuilder[K, C] {
 	return s
 }
 
+// WithRequeueJitter spreads the requeue delays returned by the steps across ±factor of their value
+// (e.g. 0.2 for ±20%), so that objects requeued at the same time do not all come back at once.
+// See JitterRequeue.
+func (s *StepperBuilder[K, C]) WithRequeueJitter(factor float64) *StepperBuilder[K, C] {
+	s.requeueJitter = factor
+	return s
+}
+
+// WithMiddleware wraps the execution of the steps with the given middlewares.
+// The first middleware is the outermost one, it runs before the others.
+func (s *StepperBuilder[K, C]) WithMiddleware(mw ...Middleware[K, C]) *StepperBuilder[K, C] {
+	s.middlewares = append(s.middlewares, mw...)
+	return s
+}
+
+// WithStatusBatching buffers the status patches of the custom resource made during the reconciliation,
+// to patch the status once at the end of it, whatever its outcome. This avoids the intermediate status writes
+// watchers would react to. Hooks can still patch the status right away using FlushCustomResourceStatus.
+//
+// The final patch is retried on conflicts, the status being applied again on the latest version of the custom resource.
+func (s *StepperBuilder[K, C]) WithStatusBatching(reconciler Reconciler[K]) *StepperBuilder[K, C] {
+	s.statusBatching = reconciler
+	return s
+}
+
+// WithAtomicStatus buffers the status patches of the custom resource like WithStatusBatching, but only patches
+// the status when the reconciliation succeeds or requeues. When a step fails, the status changes made since the last
+// commit are rolled back, so that the status is never left half updated, e.g. with the condition of a resource
+// set but not the Ready condition. Add NewCommitStatusStep to the steps to commit the status at well-defined points
+// of the reconciliation, the changes made before it being kept when a later step fails.
+//
+// The failure itself is still reported on the status when WithTerminalErrorCondition is used, its condition being
+// set after the rollback. Hooks can still patch the status right away using FlushCustomResourceStatus.
+func (s *StepperBuilder[K, C]) WithAtomicStatus(reconciler Reconciler[K]) *StepperBuilder[K, C] {
+	s.statusBatching = reconciler
+	s.atomicStatus = true
+	return s
+}
+
+// WithReconcileTimeout bounds each reconciliation to d, so that a hook hanging on an external call cannot hold
+// a worker indefinitely. The context of the reconciliation gets a deadline, hooks doing network calls respect it
+// through the context.Context embedded in the Context. A reconciliation going past the deadline is requeued after d
+// with the RequeueReasonTimeout reason instead of failing. The batched status is patched within the deadline as well.
+func (s *StepperBuilder[K, C]) WithReconcileTimeout(d time.Duration) *StepperBuilder[K, C] {
+	s.timeout = d
+	return s
+}
+
+// WithFinalizer adds a step managing a finalizer independently of any resource, calling onFinalize
+// when the custom resource is deleted, see NewFinalizerStep. Like the other steps, it runs in the order
+// it is added in, so it should be added right after the find step.
+func (s *StepperBuilder[K, C]) WithFinalizer(reconciler Reconciler[K], finalizerName string, onFinalize func(ctx C) error) *StepperBuilder[K, C] {
+	var ctx C
+	return s.WithStep(NewFinalizerStep(ctx, reconciler, finalizerName, onFinalize))
+}
+
 // WithLogger sets the logger for the Stepper.
 func (s *StepperBuilder[K, C]) Build() *Stepper[K, C] {
 	return &Stepper[K, C]{
-		logger: s.logger,
-		steps:  s.steps,
+		logger:         s.logger,
+		steps:          s.steps,
+		requeueJitter:  s.requeueJitter,
+		middlewares:    s.middlewares,
+		statusBatching: s.statusBatching,
+		atomicStatus:   s.atomicStatus,
+		terminalErrors: s.terminalErrors,
+		timeout:        s.timeout,
 	}
 }
 
 type StepResult struct {
-	earlyReturn  bool
-	err          error
-	requeueAfter time.Duration
+	earlyReturn   bool
+	err           error
+	requeueAfter  time.Duration
+	requeueReason RequeueReason
 }
 
 func (result StepResult) ShouldReturn() bool {
 	return result.err != nil || result.requeueAfter > 0 || result.earlyReturn
 }
 
+// WithRequeueReason tells why the step requeues or returns early, see RequeueReason.
+func (result StepResult) WithRequeueReason(reason RequeueReason) StepResult {
+	result.requeueReason = reason
+	return result
+}
+
+// RequeueReason returns the reason of the requeue or early return, empty if none was given.
+func (result StepResult) RequeueReason() RequeueReason {
+	return result.requeueReason
+}
+
 func (result StepResult) FromSubStep() StepResult {
 	result.earlyReturn = false
 	return result
@@ -109,30 +208,132 @@ func NewStep[K client.Object, C Context[K]](name string, step func(ctx C, logger
 }
 
 func (stepper *Stepper[K, C]) Execute(ctx C, req ctrl.Request) (ctrl.Result, error) {
+	_, restoreRequest := startRequest(ctx)
+	defer restoreRequest()
+
+	return reconcileWithTimeout(ctx, stepper.timeout, func() (ctrl.Result, error) {
+		return stepper.executeWithStatus(ctx, req)
+	})
+}
+
+// reconcileWithTimeout runs reconcile with a deadline of d on the context, see StepperBuilder.WithReconcileTimeout.
+// reconcile is run as is when d is 0.
+func reconcileWithTimeout[K client.Object, C Context[K]](ctx C, d time.Duration, reconcile func() (ctrl.Result, error)) (ctrl.Result, error) {
+	if d <= 0 {
+		return reconcile()
+	}
+
+	parent := ctx.GetParentContext()
+	timeoutCtx, cancel := context.WithTimeout(parent, d)
+	defer cancel()
+
+	ctx.SetParentContext(timeoutCtx)
+	defer ctx.SetParentContext(parent)
+
+	result, err := reconcile()
+
+	// Only the deadline of the reconciliation is handled, the cancellation of the parent context, e.g. on shutdown, is not
+	if stderrors.Is(timeoutCtx.Err(), context.DeadlineExceeded) && parent.Err() == nil {
+		logf.FromContext(parent).Info("Reconciliation timed out, requeueing", "timeout", d, "error", err)
+		recordRequeue(ctx, "", RequeueReasonTimeout)
+		return ctrl.Result{RequeueAfter: d}, nil
+	}
+
+	return result, err
+}
+
+// executeWithStatus runs the middlewares and the steps, patching the batched status at the end if it is batched.
+func (stepper *Stepper[K, C]) executeWithStatus(ctx C, req ctrl.Request) (ctrl.Result, error) {
+	reconcile := stepper.execute
+	for i := len(stepper.middlewares) - 1; i >= 0; i-- {
+		reconcile = stepper.middlewares[i].Wrap(reconcile)
+	}
+
+	if stepper.statusBatching == nil {
+		return reconcile(ctx, req)
+	}
+
+	BeginStatusTransaction(ctx)
+	result, err := reconcile(ctx, req)
+	if flushErr := flushBatchedStatus(ctx, stepper.statusBatching); flushErr != nil {
+		stepper.logger.Error(flushErr, "Failed to patch batched custom resource status")
+		if err == nil {
+			return ctrl.Result{}, flushErr
+		}
+	}
+	return result, err
+}
+
+func (stepper *Stepper[K, C]) execute(ctx C, req ctrl.Request) (ctrl.Result, error) {
 	logger := stepper.logger
+	if id := GetRequestID(ctx); id != "" {
+		logger = logger.WithValues("requestID", id)
+	}
 
 	startedAt := time.Now()
 
+	ctx.SetLogger(logger)
+
 	logger.Info("Inserting line return for lisibility\n\n")
 	logger.Info("Starting stepper execution")
 
 	for _, step := range stepper.steps {
-		stepStartedAt := time.Now()
-		result := step.Step(ctx, logger, req)
-		stepDuration := time.Since(stepStartedAt)
+		result, stepDuration := runStep(ctx, step, req)
 
 		if result.ShouldReturn() {
 			if result.err != nil {
+				if stepper.atomicStatus && inStatusTransaction(ctx) {
+					logger.Info("Rolling back the status changes of the failed reconciliation", "step", step.Name)
+					rollbackBatchedStatus(ctx)
+				}
+
 				if IsFinalizing(ctx.GetCustomResource()) && apierrors.IsNotFound(result.err) {
 					logger.Info("Resource not found during finalization, ignoring error", "step", step.Name, "stepDuration", stepDuration)
+					recordRequeue(ctx, step.Name, RequeueReasonNotFoundWhileFinalizing)
 					return ResultRequeueIn(1 * time.Second).Normal()
 				}
 
+				if IsTerminal(result.err) {
+					logger.Error(result.err, "Terminal error in step, not requeueing", "step", step.Name, "stepDuration", stepDuration)
+					if stepper.terminalErrors != nil {
+						if err := setTerminalErrorCondition(ctx, stepper.terminalErrors, step.Name, result.err); err != nil {
+							logger.Error(err, "Failed to set terminal error condition")
+						}
+					}
+					// controller-runtime doesn't requeue terminal errors
+					return ctrl.Result{}, reconcile.TerminalError(result.err)
+				}
+
+				// The API server asks to come back later, e.g. when throttling with a 429 and a Retry-After delay
+				if seconds, ok := apierrors.SuggestsClientDelay(result.err); ok && seconds > 0 {
+					after := time.Duration(seconds) * time.Second
+					logger.Info("API server asked to retry later, requeueing", "step", step.Name, "after", after, "reason", result.err.Error(), "stepDuration", stepDuration)
+					recordRequeue(ctx, step.Name, RequeueReasonThrottled)
+					return ResultRequeueIn(after).Normal()
+				}
+
 				logger.Error(result.err, "Error in step", "step", step.Name, "stepDuration", stepDuration)
-			} else if result.requeueAfter > 0 {
-				logger.Info("Requeueing after step", "step", step.Name, "after", result.requeueAfter, "stepDuration", stepDuration)
+				return result.Normal()
+			}
+
+			// Requeues requested through the context are honored on early returns as well
+			reason := result.requeueReason
+			if contextRequeue := ctx.GetRequeueAfter(); contextRequeue > 0 && (result.requeueAfter <= 0 || contextRequeue < result.requeueAfter) {
+				result.requeueAfter = contextRequeue
+				reason = ctx.GetRequeueReason()
+			}
+			if result.requeueAfter > 0 {
+				if reason == "" {
+					reason = RequeueReasonUnknown
+				}
+				result.requeueAfter = JitterRequeue(result.requeueAfter, stepper.requeueJitter, req.NamespacedName, time.Now())
+				logger.Info("Requeueing after step", "step", step.Name, "after", result.requeueAfter, "reason", reason, "stepDuration", stepDuration)
 			} else {
-				logger.Info("Early return after step", "step", step.Name, "stepDuration", stepDuration)
+				logger.Info("Early return after step", "step", step.Name, "reason", reason, "stepDuration", stepDuration)
+			}
+			// Early returns without a reason are the normal end of the reconciliation, e.g. the custom resource is gone
+			if reason != "" {
+				recordRequeue(ctx, step.Name, reason)
 			}
 			return result.Normal()
 		}
@@ -141,5 +342,59 @@ func (stepper *Stepper[K, C]) Execute(ctx C, req ctrl.Request) (ctrl.Result, err
 	}
 
 	logger.Info("All steps executed successfully", "duration", time.Since(startedAt))
+
+	if requeueAfter := ctx.GetRequeueAfter(); requeueAfter > 0 {
+		requeueAfter = JitterRequeue(requeueAfter, stepper.requeueJitter, req.NamespacedName, time.Now())
+		logger.Info("Requeueing as requested through the context", "after", requeueAfter, "reason", ctx.GetRequeueReason())
+		recordRequeue(ctx, "", ctx.GetRequeueReason())
+		return ResultRequeueIn(requeueAfter).Normal()
+	}
+
 	return ctrl.Result{}, nil
 }
+
+// runStep executes step in its own span, with the logger of the context scoped to it,
+// and records its duration on the reconcile report.
+func runStep[K client.Object, C Context[K]](ctx C, step Step[K, C], req ctrl.Request) (StepResult, time.Duration) {
+	// Steps are traced using the tracer of the current span, if any, the spans started by a step being nested in it
+	span, restoreSpan := startSpan(ctx, step.Name,
+		attribute.String("k8s.resource.name", req.Name),
+		attribute.String("k8s.resource.namespace", req.Namespace),
+	)
+
+	// Hooks and mutators get the scoped logger through the context
+	stepLogger, restoreLogger := scopeLogger(ctx, stepLoggerValues(ctx, step.Name)...)
+
+	stepStartedAt := time.Now()
+	result := step.Step(ctx, stepLogger, req)
+	stepDuration := time.Since(stepStartedAt)
+	ctx.GetReconcileReport().recordStep(StepReport{Name: step.Name, Duration: stepDuration})
+
+	restoreLogger()
+	restoreSpan()
+
+	if result.err != nil {
+		span.RecordError(result.err)
+		span.SetStatus(codes.Error, result.err.Error())
+		if class := ErrorClass(result.err); class != "" {
+			span.SetAttributes(attribute.String("ctrlfwk.error_class", class))
+		}
+	}
+	span.End()
+
+	return result, stepDuration
+}
+
+// recordRequeue counts a requeue or early return of the reconciliation on the ctrlfwk_reconcile_requeue_total metric.
+func recordRequeue[K client.Object](ctx Context[K], stepName string, reason RequeueReason) {
+	kind := reflect.TypeOf(ctx.GetCustomResource()).Elem().Name()
+	reconcileRequeueTotal.WithLabelValues(kind, stepName, string(reason)).Inc()
+}
+
+func stepLoggerValues[K client.Object](ctx Context[K], stepName string) []any {
+	values := []any{"step", stepName}
+	if generation := ctx.GetCustomResource().GetGeneration(); generation > 0 {
+		values = append(values, "generation", generation)
+	}
+	return values
+}
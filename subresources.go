@@ -0,0 +1,162 @@
+package ctrlfwk
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/pkg/errors"
+	autoscalingv1 "k8s.io/api/autoscaling/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// scaledResource is implemented by the resources that can be built with WithScale.
+type scaledResource[ContextType any] interface {
+	// desiredReplicas returns the replicas the resource is scaled to, false if the resource has no scale function.
+	desiredReplicas(ctx ContextType) (int32, bool)
+	OnScale(ctx ContextType, resource client.Object) error
+}
+
+// statusMutatedResource is implemented by the resources that can be built with WithStatusMutator.
+type statusMutatedResource[ContextType any] interface {
+	// statusMutator returns the function mutating the status of the resource, nil if the resource has none.
+	statusMutator() func(obj client.Object) error
+	OnStatusUpdate(ctx ContextType, resource client.Object) error
+}
+
+// reconcileScale sets the replicas of the scale subresource of obj, returning false when they were already set.
+// The scale is read and written as unstructured for unstructured objects, as the unstructured client requires it.
+// obj is fetched again once scaled, so that it holds the replicas it was scaled to.
+func reconcileScale(ctx context.Context, c client.Client, obj client.Object, replicas int32) (bool, error) {
+	if _, ok := obj.(runtime.Unstructured); ok {
+		scale := &unstructured.Unstructured{}
+		scale.SetGroupVersionKind(autoscalingv1.SchemeGroupVersion.WithKind("Scale"))
+		if err := c.SubResource("scale").Get(ctx, obj, scale); err != nil {
+			return false, err
+		}
+		current, _, err := unstructured.NestedInt64(scale.Object, "spec", "replicas")
+		if err != nil {
+			return false, err
+		}
+		if current == int64(replicas) {
+			return false, nil
+		}
+		if err := unstructured.SetNestedField(scale.Object, int64(replicas), "spec", "replicas"); err != nil {
+			return false, err
+		}
+		if err := c.SubResource("scale").Update(ctx, obj, client.WithSubResourceBody(scale)); err != nil {
+			return false, err
+		}
+		return true, c.Get(ctx, client.ObjectKeyFromObject(obj), obj)
+	}
+
+	scale := &autoscalingv1.Scale{}
+	if err := c.SubResource("scale").Get(ctx, obj, scale); err != nil {
+		return false, err
+	}
+	if scale.Spec.Replicas == replicas {
+		return false, nil
+	}
+	// The resource version of the scale makes the update fail with a conflict when the object was modified in the meantime
+	scale.Spec.Replicas = replicas
+	if err := c.SubResource("scale").Update(ctx, obj, client.WithSubResourceBody(scale)); err != nil {
+		return false, err
+	}
+	return true, c.Get(ctx, client.ObjectKeyFromObject(obj), obj)
+}
+
+// reconcileStatus runs mutate on obj and patches its status subresource, returning false when the status was
+// left unchanged by mutate, in which case nothing is sent. Only the status is written, the other changes made
+// by mutate are ignored by the API server.
+func reconcileStatus(ctx context.Context, c client.Client, obj client.Object, mutate func(obj client.Object) error) (bool, error) {
+	before := obj.DeepCopyObject().(client.Object)
+	if err := mutate(obj); err != nil {
+		return false, err
+	}
+
+	changed, err := statusChanged(before, obj)
+	if err != nil || !changed {
+		return false, err
+	}
+
+	return true, c.Status().Patch(ctx, obj, client.MergeFromWithOptions(before, client.MergeFromWithOptimisticLock{}))
+}
+
+// statusChanged tells whether the status of before and after differ, typed and unstructured objects alike.
+func statusChanged(before, after client.Object) (bool, error) {
+	beforeContent, err := runtime.DefaultUnstructuredConverter.ToUnstructured(before)
+	if err != nil {
+		return false, err
+	}
+	afterContent, err := runtime.DefaultUnstructuredConverter.ToUnstructured(after)
+	if err != nil {
+		return false, err
+	}
+	return !equality.Semantic.DeepEqual(beforeContent["status"], afterContent["status"]), nil
+}
+
+// reconcileSubresources writes the scale and the status of the reconciled resource obj, when it has a scale function
+// or a status mutator, returning whether obj was written. The hooks of the resource run for each subresource written.
+func reconcileSubresources[
+	ControllerResourceType ControllerCustomResource,
+	ContextType Context[ControllerResourceType],
+](
+	ctx ContextType,
+	reconciler Reconciler[ControllerResourceType],
+	c client.Client,
+	resource GenericResource[ControllerResourceType, ContextType],
+	cr ControllerResourceType,
+	obj client.Object,
+	suspended bool,
+) (bool, StepResult) {
+	var written bool
+
+	// Suspended resources are left as their suspend behavior set them, e.g. scaled to zero
+	if scaled, ok := resource.(scaledResource[ContextType]); ok && !suspended {
+		if replicas, ok := scaled.desiredReplicas(ctx); ok {
+			changed, err := reconcileScale(ctx, c, obj, replicas)
+			if err != nil {
+				return written, ResultInError(errors.Wrap(redactError(reconciler, resource, obj, err), "failed to scale resource"))
+			}
+			if changed {
+				written = true
+				if err := resource.Set(obj); err != nil {
+					return written, ResultInError(err)
+				}
+				recordLifecycleEvent(reconciler, resource, cr, "ResourceScaled", fmt.Sprintf("scaled to %d replicas", replicas))
+				if err := runOperation(ctx, "AfterScale", func() error { return scaled.OnScale(ctx, obj) }); err != nil {
+					return written, ResultInError(&HookError{ResourceID: resource.ID(), Hook: "AfterScale", Err: redactError(reconciler, resource, obj, err)})
+				}
+			}
+		}
+	}
+
+	if mutated, ok := resource.(statusMutatedResource[ContextType]); ok {
+		if mutate := mutated.statusMutator(); mutate != nil {
+			var mutateErr error
+			changed, err := reconcileStatus(ctx, c, obj, func(obj client.Object) error {
+				mutateErr = runOperation(ctx, "MutateStatus", func() error { return mutate(obj) })
+				return mutateErr
+			})
+			if mutateErr != nil {
+				return written, ResultInError(&MutatorError{ResourceID: resource.ID(), Err: redactError(reconciler, resource, obj, mutateErr)})
+			}
+			if err != nil {
+				return written, ResultInError(errors.Wrap(redactError(reconciler, resource, obj, err), "failed to patch resource status"))
+			}
+			if changed {
+				written = true
+				if err := resource.Set(obj); err != nil {
+					return written, ResultInError(err)
+				}
+				if err := runOperation(ctx, "AfterStatusUpdate", func() error { return mutated.OnStatusUpdate(ctx, obj) }); err != nil {
+					return written, ResultInError(&HookError{ResourceID: resource.ID(), Hook: "AfterStatusUpdate", Err: redactError(reconciler, resource, obj, err)})
+				}
+			}
+		}
+	}
+
+	return written, ResultSuccess()
+}
@@ -0,0 +1,163 @@
+package ctrlfwk
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+
+	jsonpatch "github.com/evanphx/json-patch/v5"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// LabelSuspended can be added to a custom resource to suspend the resources built with WithSuspendBehavior,
+	// e.g. scale its Deployments to zero, while everything else keeps being reconciled.
+	// Like LabelReconciliationPaused, its value can document who/what suspended the custom resource.
+	LabelSuspended = "ctrlfwk.com/suspend"
+
+	// AnnotationSuspendedState is set on the resources suspended by the framework, it holds the JSON merge patch
+	// restoring the values their suspend behavior changed, applied when the custom resource is resumed.
+	AnnotationSuspendedState = "ctrlfwk.com/suspended-state"
+
+	// ConditionTypeSuspended is set on the custom resource while its resources built with WithSuspendBehavior are suspended.
+	ConditionTypeSuspended = "Suspended"
+)
+
+// Suspendable can be implemented by custom resources that have a suspend field,
+// they are suspended when either IsSuspended returns true or they have the LabelSuspended label.
+type Suspendable interface {
+	IsSuspended() bool
+}
+
+// IsSuspended tells if the resources of the custom resource built with WithSuspendBehavior must be suspended.
+func IsSuspended(cr client.Object) bool {
+	if _, ok := cr.GetLabels()[LabelSuspended]; ok {
+		return true
+	}
+	suspendable, ok := cr.(Suspendable)
+	return ok && suspendable.IsSuspended()
+}
+
+// suspendableResource is implemented by the resources that can be built with WithSuspendBehavior.
+type suspendableResource interface {
+	suspendBehavior() func(obj client.Object) error
+}
+
+func getSuspendBehavior(resource any) func(obj client.Object) error {
+	if suspendable, ok := resource.(suspendableResource); ok {
+		return suspendable.suspendBehavior()
+	}
+	return nil
+}
+
+// suspendObject applies the suspend behavior to obj, recording the values it changed so that they can be restored.
+// The values recorded when obj was first suspended are kept, so that suspending it again does not lose them.
+func suspendObject(obj client.Object, suspend func(obj client.Object) error) error {
+	before, err := json.Marshal(obj)
+	if err != nil {
+		return err
+	}
+
+	if err := suspend(obj); err != nil {
+		return err
+	}
+
+	if GetAnnotation(obj, AnnotationSuspendedState) != "" {
+		return nil
+	}
+
+	after, err := json.Marshal(obj)
+	if err != nil {
+		return err
+	}
+	restore, err := jsonpatch.CreateMergePatch(after, before)
+	if err != nil {
+		return err
+	}
+
+	setAnnotations(obj, map[string]string{AnnotationSuspendedState: string(restore)})
+	return nil
+}
+
+// resumeObject restores the values recorded when obj was suspended, if any.
+func resumeObject(obj client.Object) error {
+	restore := GetAnnotation(obj, AnnotationSuspendedState)
+	if restore == "" {
+		return nil
+	}
+
+	current, err := json.Marshal(obj)
+	if err != nil {
+		return err
+	}
+	restored, err := jsonpatch.MergePatch(current, []byte(restore))
+	if err != nil {
+		return fmt.Errorf("invalid %s annotation: %w", AnnotationSuspendedState, err)
+	}
+
+	// Unmarshalling merges into the existing values, start from an empty object
+	if u, ok := obj.(*unstructured.Unstructured); ok {
+		u.Object = nil
+	} else {
+		value := reflect.ValueOf(obj).Elem()
+		value.Set(reflect.Zero(value.Type()))
+	}
+	if err := json.Unmarshal(restored, obj); err != nil {
+		return err
+	}
+
+	annotations := obj.GetAnnotations()
+	delete(annotations, AnnotationSuspendedState)
+	obj.SetAnnotations(annotations)
+	return nil
+}
+
+// setAnnotations sets the given annotations on obj, including unstructured objects whose annotations are copies.
+func setAnnotations(obj client.Object, values map[string]string) {
+	annotations := obj.GetAnnotations()
+	if annotations == nil {
+		annotations = make(map[string]string)
+	}
+	for key, value := range values {
+		annotations[key] = value
+	}
+	obj.SetAnnotations(annotations)
+}
+
+// setSuspendedCondition reflects the suspension of the custom resource on its status,
+// the condition is removed once it is resumed.
+func setSuspendedCondition[
+	ControllerResourceType ControllerCustomResource,
+	ContextType Context[ControllerResourceType],
+](
+	ctx ContextType,
+	reconciler Reconciler[ControllerResourceType],
+	suspended bool,
+) error {
+	defer LockContext(ctx)()
+
+	cr := ctx.GetCustomResource()
+
+	if !suspended {
+		_, err := applyCondition(ctx, reconciler, ConditionTypeSuspended, nil)
+		return err
+	}
+
+	message := "workloads are suspended"
+	if value, ok := cr.GetLabels()[LabelSuspended]; ok {
+		message = fmt.Sprintf("workloads are suspended by label %s", LabelSuspended)
+		if value != "" {
+			message = fmt.Sprintf("%s=%s", message, value)
+		}
+	}
+
+	_, err := applyCondition(ctx, reconciler, ConditionTypeSuspended, &metav1.Condition{
+		Status:             metav1.ConditionTrue,
+		Reason:             "SuspendRequested",
+		Message:            message,
+		ObservedGeneration: cr.GetGeneration(),
+	})
+	return err
+}
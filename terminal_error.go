@@ -0,0 +1,136 @@
+package ctrlfwk
+
+import (
+	stderrors "errors"
+	"fmt"
+
+	"github.com/go-logr/logr"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+const (
+	// ConditionTypeTerminalError is set on the custom resource when a step or hook failed with a TerminalError,
+	// see StepperBuilder.WithTerminalErrorCondition.
+	ConditionTypeTerminalError = "TerminalError"
+)
+
+// TerminalError marks an error that retrying won't fix, e.g. an external reference that is permanently invalid.
+// When a step or hook fails with a TerminalError, the reconciliation stops without being requeued,
+// and the custom resource is not reconciled again until its generation changes.
+type TerminalError struct {
+	Err error
+}
+
+func (e *TerminalError) Error() string {
+	return fmt.Sprintf("terminal error: %v", e.Err)
+}
+
+func (e *TerminalError) Unwrap() error      { return e.Err }
+func (e *TerminalError) ErrorClass() string { return "Terminal" }
+
+// RetryableError marks an error that is worth retrying, it overrides the TerminalError it wraps, if any.
+// Errors that are neither terminal nor retryable are retried, as usual.
+type RetryableError struct {
+	Err error
+}
+
+func (e *RetryableError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *RetryableError) Unwrap() error { return e.Err }
+
+// Terminal wraps err into a TerminalError, nil if err is nil.
+//
+// Example:
+//
+//	if _, err := registry.Resolve(cr.Spec.Image); errors.Is(err, registry.ErrInvalidReference) {
+//		return ctrlfwk.Terminal(err)
+//	}
+func Terminal(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &TerminalError{Err: err}
+}
+
+// Retryable wraps err into a RetryableError, nil if err is nil.
+func Retryable(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &RetryableError{Err: err}
+}
+
+// IsTerminal tells whether err is terminal: the outermost TerminalError or RetryableError of its chain decides,
+// the terminal errors of controller-runtime being terminal as well.
+func IsTerminal(err error) bool {
+	for err != nil {
+		switch err.(type) {
+		case *TerminalError:
+			return true
+		case *RetryableError:
+			return false
+		}
+		if matcher, ok := err.(interface{ Is(error) bool }); ok && matcher.Is(reconcile.TerminalError(nil)) {
+			return true
+		}
+
+		// Joined errors are terminal when any of them is
+		if joined, ok := err.(interface{ Unwrap() []error }); ok {
+			for _, inner := range joined.Unwrap() {
+				if IsTerminal(inner) {
+					return true
+				}
+			}
+			return false
+		}
+		err = stderrors.Unwrap(err)
+	}
+	return false
+}
+
+// WithTerminalErrorCondition sets a TerminalError condition on the custom resource when a step or hook fails with
+// a terminal error, see Terminal. NewFindControllerCustomResourceStep then skips the reconciliation of the custom
+// resource until its generation changes, removing the condition once it does.
+//
+// Terminal errors are never requeued, whether this is set or not.
+func (s *StepperBuilder[K, C]) WithTerminalErrorCondition(reconciler Reconciler[K]) *StepperBuilder[K, C] {
+	s.terminalErrors = reconciler
+	return s
+}
+
+// setTerminalErrorCondition sets the TerminalError condition on the custom resource for its current generation.
+func setTerminalErrorCondition[K client.Object, C Context[K]](ctx C, reconciler Reconciler[K], stepName string, terminalErr error) error {
+	defer LockContext(ctx)()
+
+	cr := ctx.GetCustomResource()
+	_, err := applyCondition(ctx, reconciler, ConditionTypeTerminalError, &metav1.Condition{
+		Status:             metav1.ConditionTrue,
+		Reason:             "TerminalError",
+		Message:            fmt.Sprintf("step %s: %v", stepName, terminalErr),
+		ObservedGeneration: cr.GetGeneration(),
+	})
+	return err
+}
+
+// terminalErrorHolds tells whether the custom resource failed with a terminal error at its current generation,
+// removing the TerminalError condition of a previous generation.
+func terminalErrorHolds[K client.Object, C Context[K]](ctx C, reconciler Reconciler[K], logger logr.Logger) (bool, error) {
+	cr := ctx.GetCustomResource()
+
+	condition, err := getStatusCondition(cr, ConditionTypeTerminalError)
+	if err != nil || condition == nil {
+		return false, err
+	}
+	if condition.Status == metav1.ConditionTrue && condition.ObservedGeneration == cr.GetGeneration() {
+		logger.Info("Custom resource failed with a terminal error, waiting for it to change", "generation", cr.GetGeneration(), "error", condition.Message)
+		return true, nil
+	}
+
+	defer LockContext(ctx)()
+	_, err = applyCondition(ctx, reconciler, ConditionTypeTerminalError, nil)
+	return false, err
+}
@@ -0,0 +1,52 @@
+//go:build integration
+
+package integration_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	ctrlfwk "github.com/u-ctf/controller-fwk"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func TestWidget_DependencyIsWatchedThroughManagedByAnnotation(t *testing.T) {
+	namespace := newNamespace(t)
+	key := createWidget(t, namespace, func(widget *Widget) { widget.Spec.SecretName = "credentials" })
+
+	// Resources are not reconciled while the dependency is missing
+	eventually(t, expectCondition(key, "SecretReady", metav1.ConditionFalse))
+	consistently(t, 2*time.Second, expectConfigMap(types.NamespacedName{Name: "settings", Namespace: namespace}, ""))
+
+	// Creating the secret triggers a reconciliation through the watch set up for the missing dependency
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "credentials", Namespace: namespace},
+		Data:       map[string][]byte{"ready": []byte("false")},
+	}
+	if err := k8sClient.Create(context.Background(), secret); err != nil {
+		t.Fatalf("failed to create secret: %v", err)
+	}
+	eventually(t, func() error {
+		if err := k8sClient.Get(context.Background(), types.NamespacedName{Name: "credentials", Namespace: namespace}, secret); err != nil {
+			return err
+		}
+		if secret.Annotations[ctrlfwk.AnnotationRef] == "" {
+			return fmt.Errorf("expected the secret to be annotated as managed by the widget, got %v", secret.Annotations)
+		}
+		return nil
+	})
+
+	// Updating the annotated secret alone, without touching the widget, reconciles it again
+	secret.Data["ready"] = []byte("true")
+	if err := k8sClient.Update(context.Background(), secret); err != nil {
+		t.Fatalf("failed to update secret: %v", err)
+	}
+	eventually(t, expectCondition(key, "SecretReady", ""))
+	eventually(t, expectConfigMap(types.NamespacedName{Name: "settings", Namespace: namespace}, "blue"))
+	eventually(t, expectCondition(key, ctrlfwk.ConditionTypeReady, metav1.ConditionTrue))
+}
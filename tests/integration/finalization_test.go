@@ -0,0 +1,65 @@
+//go:build integration
+
+package integration_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// expectWidgetGone returns a check of the widget being finalized and deleted.
+func expectWidgetGone(key client.ObjectKey) func() error {
+	return func() error {
+		err := k8sClient.Get(context.Background(), key, &Widget{})
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		return fmt.Errorf("expected widget %s to be deleted", key)
+	}
+}
+
+func deleteWidget(t *testing.T, key client.ObjectKey) {
+	t.Helper()
+
+	widget := &Widget{ObjectMeta: metav1.ObjectMeta{Name: key.Name, Namespace: key.Namespace}}
+	if err := k8sClient.Delete(context.Background(), widget); err != nil {
+		t.Fatalf("failed to delete widget: %v", err)
+	}
+}
+
+func TestWidget_FinalizationLeavesResourcesToGarbageCollection(t *testing.T) {
+	namespace := newNamespace(t)
+	key := createWidget(t, namespace, nil)
+
+	settings := types.NamespacedName{Name: "settings", Namespace: namespace}
+	eventually(t, expectConfigMap(settings, "blue"))
+
+	deleteWidget(t, key)
+	eventually(t, expectWidgetGone(key))
+
+	// envtest does not run the garbage collector, the owned ConfigMap is left as is
+	eventually(t, expectConfigMap(settings, "blue"))
+}
+
+func TestWidget_FinalizationDeletesResourcesRequiringManualDeletion(t *testing.T) {
+	namespace := newNamespace(t)
+	key := createWidget(t, namespace, func(widget *Widget) {
+		widget.Annotations = map[string]string{annotationDeleteOnFinalize: "true"}
+	})
+
+	settings := types.NamespacedName{Name: "settings", Namespace: namespace}
+	eventually(t, expectConfigMap(settings, "blue"))
+
+	deleteWidget(t, key)
+	eventually(t, expectConfigMap(settings, ""))
+	eventually(t, expectWidgetGone(key))
+}
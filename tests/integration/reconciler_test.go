@@ -0,0 +1,115 @@
+//go:build integration
+
+package integration_test
+
+import (
+	"context"
+	"maps"
+
+	ctrlfwk "github.com/u-ctf/controller-fwk"
+	"github.com/u-ctf/controller-fwk/instrument"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+const (
+	widgetFinalizer = "integration.ctrlfwk.com/finalizer"
+
+	// annotationDeleteOnFinalize makes the framework delete the ConfigMap of a widget when it is finalized,
+	// instead of leaving it to the garbage collector, which does not run in envtest
+	annotationDeleteOnFinalize = "integration.ctrlfwk.com/delete-on-finalize"
+)
+
+type widgetContext = ctrlfwk.Context[*Widget]
+
+// WidgetReconciler reconciles widgets with the framework, declaring a ConfigMap resource
+// and an optional Secret dependency.
+type WidgetReconciler struct {
+	client.Client
+	ctrlfwk.WatchCache
+	instrument.Instrumenter
+	record.EventRecorder
+}
+
+func (WidgetReconciler) For(*Widget) {}
+
+var _ ctrlfwk.ReconcilerWithDependencies[*Widget, widgetContext] = &WidgetReconciler{}
+var _ ctrlfwk.ReconcilerWithResources[*Widget, widgetContext] = &WidgetReconciler{}
+var _ ctrlfwk.ReconcilerWithWatcher[*Widget] = &WidgetReconciler{}
+
+func (reconciler *WidgetReconciler) GetDependencies(ctx widgetContext, req ctrl.Request) ([]ctrlfwk.GenericDependency[*Widget, widgetContext], error) {
+	cr := ctx.GetCustomResource()
+	if cr.Spec.SecretName == "" {
+		return nil, nil
+	}
+
+	return []ctrlfwk.GenericDependency[*Widget, widgetContext]{
+		ctrlfwk.NewDependencyBuilder(ctx, &corev1.Secret{}).
+			WithName(cr.Spec.SecretName).
+			WithNamespace(cr.Namespace).
+			WithIsReadyFunc(func(secret *corev1.Secret) bool { return string(secret.Data["ready"]) == "true" }).
+			WithWaitForReady(true).
+			WithAddManagedByAnnotation(true).
+			WithConditionReporting("SecretReady").
+			Build(),
+	}, nil
+}
+
+func (reconciler *WidgetReconciler) GetResources(ctx widgetContext, req ctrl.Request) ([]ctrlfwk.GenericResource[*Widget, widgetContext], error) {
+	cr := ctx.GetCustomResource()
+
+	return []ctrlfwk.GenericResource[*Widget, widgetContext]{
+		ctrlfwk.NewResourceBuilder(ctx, &corev1.ConfigMap{}).
+			WithKeyFunc(func() types.NamespacedName {
+				return types.NamespacedName{Name: cr.Spec.ConfigMap.Name, Namespace: cr.Namespace}
+			}).
+			WithSkipAndDeleteOnCondition(func() bool { return !cr.Spec.ConfigMap.Enabled }).
+			WithCanBePaused(true).
+			WithMutator(func(cm *corev1.ConfigMap) error {
+				cm.Data = maps.Clone(cr.Spec.ConfigMap.Data)
+				return controllerutil.SetControllerReference(cr, cm, reconciler.Scheme())
+			}).
+			WithReadinessCondition(func(*corev1.ConfigMap) bool { return true }).
+			WithRequireManualDeletionForFinalize(func(*corev1.ConfigMap) bool {
+				return cr.Annotations[annotationDeleteOnFinalize] == "true"
+			}).
+			Build(),
+	}, nil
+}
+
+func (reconciler *WidgetReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logger := logf.FromContext(ctx)
+
+	context := ctrlfwk.NewContext(ctx, reconciler)
+
+	stepper := ctrlfwk.NewStepperFor(context, logger).
+		WithStep(ctrlfwk.NewFindControllerCustomResourceStep(context, reconciler)).
+		WithStep(ctrlfwk.NewAddFinalizerStep(context, reconciler, widgetFinalizer)).
+		WithStep(ctrlfwk.NewResolveDynamicDependenciesStep(context, reconciler)).
+		WithStep(ctrlfwk.NewReconcileResourcesStep(context, reconciler)).
+		WithStep(ctrlfwk.NewPruneResourcesStep(context, reconciler, ctrlfwk.PruneConfig{Enabled: true})).
+		WithStep(ctrlfwk.NewExecuteFinalizerStep(context, reconciler, widgetFinalizer, ctrlfwk.NilFinalizerFunc)).
+		WithStep(ctrlfwk.NewEndStep(context, reconciler, ctrlfwk.SetReadyCondition(reconciler))).
+		Build()
+
+	return stepper.Execute(context, req)
+}
+
+func (reconciler *WidgetReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	ctrler, err := instrument.InstrumentedControllerManagedBy(reconciler, mgr).
+		For(&Widget{}).
+		Named("widget").
+		Build(reconciler)
+	if err != nil {
+		return err
+	}
+
+	reconciler.WatchCache.SetController(ctrler)
+	return nil
+}
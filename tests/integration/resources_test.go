@@ -0,0 +1,119 @@
+//go:build integration
+
+package integration_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	ctrlfwk "github.com/u-ctf/controller-fwk"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// createWidget creates a widget with an enabled ConfigMap named "settings".
+func createWidget(t *testing.T, namespace string, change func(widget *Widget)) client.ObjectKey {
+	t.Helper()
+
+	widget := &Widget{
+		ObjectMeta: metav1.ObjectMeta{Name: "widget", Namespace: namespace},
+		Spec: WidgetSpec{
+			ConfigMap: WidgetConfigMap{Enabled: true, Name: "settings", Data: map[string]string{"color": "blue"}},
+		},
+	}
+	if change != nil {
+		change(widget)
+	}
+	if err := k8sClient.Create(context.Background(), widget); err != nil {
+		t.Fatalf("failed to create widget: %v", err)
+	}
+
+	return client.ObjectKeyFromObject(widget)
+}
+
+// expectCondition returns a check of the status of a condition of the widget, an empty status expecting no condition.
+func expectCondition(key client.ObjectKey, conditionType string, status metav1.ConditionStatus) func() error {
+	return func() error {
+		widget := &Widget{}
+		if err := k8sClient.Get(context.Background(), key, widget); err != nil {
+			return err
+		}
+		condition := meta.FindStatusCondition(widget.Status.Conditions, conditionType)
+		switch {
+		case status == "" && condition != nil:
+			return fmt.Errorf("expected no %s condition, got %v", conditionType, condition)
+		case status != "" && (condition == nil || condition.Status != status):
+			return fmt.Errorf("expected condition %s to be %s, got %v", conditionType, status, condition)
+		}
+		return nil
+	}
+}
+
+// expectConfigMap returns a check of the color of a ConfigMap, an empty color expecting the ConfigMap not to exist.
+func expectConfigMap(key client.ObjectKey, color string) func() error {
+	return func() error {
+		cm := &corev1.ConfigMap{}
+		err := k8sClient.Get(context.Background(), key, cm)
+		switch {
+		case color == "" && apierrors.IsNotFound(err):
+			return nil
+		case color == "" && err == nil:
+			return fmt.Errorf("expected configmap %s to be deleted", key)
+		case err != nil:
+			return err
+		case cm.Data["color"] != color:
+			return fmt.Errorf("expected configmap %s to have color %q, got %q", key, color, cm.Data["color"])
+		}
+		return nil
+	}
+}
+
+func TestWidget_ConfigMapLifecycle(t *testing.T) {
+	namespace := newNamespace(t)
+	key := createWidget(t, namespace, nil)
+
+	settings := types.NamespacedName{Name: "settings", Namespace: namespace}
+	eventually(t, expectConfigMap(settings, "blue"))
+	eventually(t, expectCondition(key, ctrlfwk.ConditionTypeReady, metav1.ConditionTrue))
+
+	// Update
+	updateWidget(t, key, func(widget *Widget) { widget.Spec.ConfigMap.Data["color"] = "red" })
+	eventually(t, expectConfigMap(settings, "red"))
+
+	// Rename, the previous ConfigMap is pruned as it is not declared anymore
+	updateWidget(t, key, func(widget *Widget) { widget.Spec.ConfigMap.Name = "settings-v2" })
+	renamed := types.NamespacedName{Name: "settings-v2", Namespace: namespace}
+	eventually(t, expectConfigMap(renamed, "red"))
+	eventually(t, expectConfigMap(settings, ""))
+
+	// Disable
+	updateWidget(t, key, func(widget *Widget) { widget.Spec.ConfigMap.Enabled = false })
+	eventually(t, expectConfigMap(renamed, ""))
+	eventually(t, expectCondition(key, ctrlfwk.ConditionTypeReady, metav1.ConditionTrue))
+}
+
+func TestWidget_PauseLabel(t *testing.T) {
+	namespace := newNamespace(t)
+	key := createWidget(t, namespace, nil)
+
+	settings := types.NamespacedName{Name: "settings", Namespace: namespace}
+	eventually(t, expectConfigMap(settings, "blue"))
+
+	updateWidget(t, key, func(widget *Widget) {
+		widget.Labels = map[string]string{ctrlfwk.LabelReconciliationPaused: "maintenance"}
+		widget.Spec.ConfigMap.Data["color"] = "red"
+	})
+	eventually(t, expectCondition(key, ctrlfwk.ConditionTypePaused, metav1.ConditionTrue))
+	consistently(t, 2*time.Second, expectConfigMap(settings, "blue"))
+
+	updateWidget(t, key, func(widget *Widget) { delete(widget.Labels, ctrlfwk.LabelReconciliationPaused) })
+	eventually(t, expectCondition(key, ctrlfwk.ConditionTypePaused, ""))
+	eventually(t, expectConfigMap(settings, "red"))
+}
@@ -0,0 +1,166 @@
+//go:build integration
+
+// Package integration_test drives the framework against a real API server started with envtest.
+//
+// Each scenario creates its widgets in its own namespace and waits for the reconciler running
+// in the manager of the suite to converge. New framework features should come with a scenario here.
+// Run the suite with `make test-integration`.
+package integration_test
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	ctrlfwk "github.com/u-ctf/controller-fwk"
+	"github.com/u-ctf/controller-fwk/instrument"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/envtest"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	metricsserver "sigs.k8s.io/controller-runtime/pkg/metrics/server"
+)
+
+const (
+	eventuallyTimeout  = 30 * time.Second
+	eventuallyInterval = 250 * time.Millisecond
+)
+
+// k8sClient reads from the API server directly, so that assertions are not subject to cache staleness.
+var k8sClient client.Client
+
+func TestMain(m *testing.M) {
+	os.Exit(run(m))
+}
+
+func run(m *testing.M) int {
+	ctrl.SetLogger(logr.Discard())
+
+	testEnv := &envtest.Environment{
+		CRDDirectoryPaths:     []string{filepath.Join("testdata", "crds")},
+		ErrorIfCRDPathMissing: true,
+	}
+
+	cfg, err := testEnv.Start()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to start test environment, is KUBEBUILDER_ASSETS set? %v\n", err)
+		return 1
+	}
+	defer func() { _ = testEnv.Stop() }()
+
+	scheme := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(scheme); err != nil {
+		fmt.Fprintf(os.Stderr, "failed to build scheme: %v\n", err)
+		return 1
+	}
+	if err := addToScheme(scheme); err != nil {
+		fmt.Fprintf(os.Stderr, "failed to build scheme: %v\n", err)
+		return 1
+	}
+
+	k8sClient, err = client.New(cfg, client.Options{Scheme: scheme})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to create client: %v\n", err)
+		return 1
+	}
+
+	mgr, err := ctrl.NewManager(cfg, ctrl.Options{
+		Scheme:  scheme,
+		Metrics: metricsserver.Options{BindAddress: "0"},
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to create manager: %v\n", err)
+		return 1
+	}
+
+	instrumenter := instrument.NewInstrumenter(mgr).
+		WithLoggerFunc(func(ctx context.Context) logr.Logger { return logf.FromContext(ctx) }).
+		Build()
+
+	if err := (&WidgetReconciler{
+		Client:        mgr.GetClient(),
+		WatchCache:    ctrlfwk.NewWatchCache(mgr),
+		Instrumenter:  instrumenter,
+		EventRecorder: mgr.GetEventRecorderFor("widget"),
+	}).SetupWithManager(mgr); err != nil {
+		fmt.Fprintf(os.Stderr, "failed to set up reconciler: %v\n", err)
+		return 1
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	go func() {
+		if err := mgr.Start(ctx); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to run manager: %v\n", err)
+		}
+	}()
+
+	return m.Run()
+}
+
+// newNamespace creates the namespace of a scenario, deleted once it completes.
+func newNamespace(t *testing.T) string {
+	t.Helper()
+
+	ns := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{GenerateName: "ctrlfwk-"}}
+	if err := k8sClient.Create(context.Background(), ns); err != nil {
+		t.Fatalf("failed to create namespace: %v", err)
+	}
+	t.Cleanup(func() { _ = k8sClient.Delete(context.Background(), ns) })
+
+	return ns.Name
+}
+
+// eventually polls check until it succeeds, failing the test with its last error on timeout.
+func eventually(t *testing.T, check func() error) {
+	t.Helper()
+
+	deadline := time.Now().Add(eventuallyTimeout)
+	for {
+		err := check()
+		if err == nil {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("condition not met after %s: %v", eventuallyTimeout, err)
+		}
+		time.Sleep(eventuallyInterval)
+	}
+}
+
+// consistently checks that check keeps succeeding for the given duration.
+func consistently(t *testing.T, duration time.Duration, check func() error) {
+	t.Helper()
+
+	deadline := time.Now().Add(duration)
+	for time.Now().Before(deadline) {
+		if err := check(); err != nil {
+			t.Fatalf("condition not held: %v", err)
+		}
+		time.Sleep(eventuallyInterval)
+	}
+}
+
+// updateWidget applies change to the latest version of the widget, retrying on conflicts with the reconciler.
+func updateWidget(t *testing.T, key client.ObjectKey, change func(widget *Widget)) {
+	t.Helper()
+
+	eventually(t, func() error {
+		widget := &Widget{}
+		if err := k8sClient.Get(context.Background(), key, widget); err != nil {
+			return err
+		}
+		change(widget)
+		return k8sClient.Update(context.Background(), widget)
+	})
+}
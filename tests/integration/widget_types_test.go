@@ -0,0 +1,70 @@
+//go:build integration
+
+package integration_test
+
+import (
+	"maps"
+	"slices"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// groupVersion is the group version of the Widget test CRD, see testdata/crds.
+var groupVersion = schema.GroupVersion{Group: "integration.ctrlfwk.com", Version: "v1"}
+
+func addToScheme(scheme *runtime.Scheme) error {
+	scheme.AddKnownTypes(groupVersion, &Widget{}, &WidgetList{})
+	metav1.AddToGroupVersion(scheme, groupVersion)
+	return nil
+}
+
+type WidgetConfigMap struct {
+	Enabled bool              `json:"enabled,omitempty"`
+	Name    string            `json:"name,omitempty"`
+	Data    map[string]string `json:"data,omitempty"`
+}
+
+type WidgetSpec struct {
+	ConfigMap  WidgetConfigMap `json:"configMap,omitempty"`
+	SecretName string          `json:"secretName,omitempty"`
+}
+
+type WidgetStatus struct {
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+// Widget is the minimal custom resource reconciled by the integration suite.
+type Widget struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   WidgetSpec   `json:"spec,omitempty"`
+	Status WidgetStatus `json:"status,omitempty"`
+}
+
+func (in *Widget) DeepCopyObject() runtime.Object {
+	out := *in
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	out.Spec.ConfigMap.Data = maps.Clone(in.Spec.ConfigMap.Data)
+	out.Status.Conditions = slices.Clone(in.Status.Conditions)
+	return &out
+}
+
+type WidgetList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+
+	Items []Widget `json:"items"`
+}
+
+func (in *WidgetList) DeepCopyObject() runtime.Object {
+	out := *in
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	out.Items = make([]Widget, len(in.Items))
+	for i := range in.Items {
+		out.Items[i] = *in.Items[i].DeepCopyObject().(*Widget)
+	}
+	return &out
+}
@@ -24,20 +24,25 @@ func NewUntypedConfigMapResource(ctx testv1.UntypedTestContext, reconciler ctrlf
 		WithSkipAndDeleteOnCondition(func() bool {
 			return !cr.Spec.ConfigMap.Enabled
 		}).
-		WithKeyFunc(func() types.NamespacedName {
-			if !cr.Spec.ConfigMap.Enabled && cr.Status.ConfigMapStatus != nil && cr.Status.ConfigMapStatus.Name != "" {
-				// Use the name from status if the ConfigMap is disabled but still exists
+		WithKeyFromStatusFallback(
+			func() types.NamespacedName {
+				return types.NamespacedName{
+					Name:      cr.Spec.ConfigMap.Name,
+					Namespace: cr.Namespace,
+				}
+			},
+			func() types.NamespacedName {
+				if cr.Status.ConfigMapStatus == nil {
+					return types.NamespacedName{}
+				}
 				return types.NamespacedName{
 					Name:      cr.Status.ConfigMapStatus.Name,
 					Namespace: cr.Namespace,
 				}
-			}
-
-			return types.NamespacedName{
-				Name:      cr.Spec.ConfigMap.Name,
-				Namespace: cr.Namespace,
-			}
-		}).
+			},
+			// Use the name from status if the ConfigMap is disabled but still exists
+			func() bool { return !cr.Spec.ConfigMap.Enabled },
+		).
 		WithMutator(func(resource *unstructured.Unstructured) (err error) {
 			datas := make(map[string]any)
 			for k, v := range cr.Spec.ConfigMap.Data {
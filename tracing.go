@@ -0,0 +1,35 @@
+package ctrlfwk
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// spanScope is the part of the context needed to nest spans, see startSpan.
+type spanScope interface {
+	GetParentContext() context.Context
+	SetParentContext(ctx context.Context)
+}
+
+// startSpan starts a span with the tracer of the current span, if any, so it plugs into the configured tracing backend.
+// The span is made the current one of the context until restore is called, so that the spans started meanwhile are nested.
+func startSpan(ctx spanScope, name string, attributes ...attribute.KeyValue) (trace.Span, func()) {
+	parent := ctx.GetParentContext()
+	if id := GetRequestID(parent); id != "" {
+		attributes = append(attributes, attribute.String("ctrlfwk.request_id", id))
+	}
+
+	tracer := trace.SpanFromContext(parent).TracerProvider().Tracer(stepperTracerName)
+	spanCtx, span := tracer.Start(parent, name, trace.WithAttributes(attributes...))
+
+	if isConcurrentReconciliation(parent) {
+		return span, func() {}
+	}
+
+	ctx.SetParentContext(spanCtx)
+	return span, func() {
+		ctx.SetParentContext(parent)
+	}
+}
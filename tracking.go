@@ -0,0 +1,93 @@
+package ctrlfwk
+
+import (
+	"strings"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+)
+
+// EncodeTrackingGVK encodes a GroupVersionKind so that it can be used as a label value,
+// e.g. "PodDisruptionBudget.v1.policy" or "ConfigMap.v1" for the core group.
+func EncodeTrackingGVK(gvk schema.GroupVersionKind) string {
+	parts := []string{gvk.Kind, gvk.Version}
+	if gvk.Group != "" {
+		parts = append(parts, gvk.Group)
+	}
+	return strings.Join(parts, ".")
+}
+
+// DecodeTrackingGVK decodes a GroupVersionKind encoded by EncodeTrackingGVK.
+// It returns false if the value is not a valid encoded GroupVersionKind.
+func DecodeTrackingGVK(value string) (schema.GroupVersionKind, bool) {
+	parts := strings.SplitN(value, ".", 3)
+	if len(parts) < 2 || parts[0] == "" || parts[1] == "" {
+		return schema.GroupVersionKind{}, false
+	}
+
+	gvk := schema.GroupVersionKind{Kind: parts[0], Version: parts[1]}
+	if len(parts) == 3 {
+		gvk.Group = parts[2]
+	}
+	return gvk, true
+}
+
+// SetTrackingLabels marks obj as a resource declared by the custom resource owner.
+// The GroupVersionKind label is only set when it fits in a label value.
+func SetTrackingLabels(obj client.Object, owner client.Object, scheme *runtime.Scheme) error {
+	gvk, err := apiutil.GVKForObject(obj, scheme)
+	if err != nil {
+		return err
+	}
+
+	labels := obj.GetLabels()
+	if labels == nil {
+		labels = make(map[string]string)
+	}
+
+	labels[LabelTrackingOwnerUID] = string(owner.GetUID())
+
+	encoded := EncodeTrackingGVK(gvk)
+	if len(validation.IsValidLabelValue(encoded)) == 0 {
+		labels[LabelTrackingGVK] = encoded
+	}
+
+	obj.SetLabels(labels)
+
+	return nil
+}
+
+// trackResource sets the tracking labels on obj, see SetTrackingLabels, and records it in the report of the
+// reconciliation so that the prune step considers it declared, whichever step declared it.
+func trackResource[K client.Object](ctx Context[K], obj client.Object, scheme *runtime.Scheme) error {
+	if err := SetTrackingLabels(obj, ctx.GetCustomResource(), scheme); err != nil {
+		return err
+	}
+
+	gvk, err := apiutil.GVKForObject(obj, scheme)
+	if err != nil {
+		return err
+	}
+	ctx.GetReconcileReport().recordTrackedResource(gvk, client.ObjectKeyFromObject(obj))
+
+	return nil
+}
+
+// getTrackedGVKs returns the GroupVersionKinds recorded on the custom resource by the prune step.
+func getTrackedGVKs(obj client.Object) []schema.GroupVersionKind {
+	value := GetAnnotation(obj, AnnotationTrackedGVKs)
+	if value == "" {
+		return nil
+	}
+
+	var out []schema.GroupVersionKind
+	for encoded := range strings.SplitSeq(value, ",") {
+		if gvk, ok := DecodeTrackingGVK(encoded); ok {
+			out = append(out, gvk)
+		}
+	}
+	return out
+}
@@ -0,0 +1,52 @@
+package ctrlfwk_test
+
+import (
+	"testing"
+
+	ctrlfwk "github.com/u-ctf/controller-fwk"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+)
+
+func TestTrackingGVK_RoundTrip(t *testing.T) {
+	gvks := []schema.GroupVersionKind{
+		{Group: "policy", Version: "v1", Kind: "PodDisruptionBudget"},
+		{Group: "", Version: "v1", Kind: "ConfigMap"},
+		{Group: "monitoring.coreos.com", Version: "v1", Kind: "ServiceMonitor"},
+	}
+
+	for _, gvk := range gvks {
+		decoded, ok := ctrlfwk.DecodeTrackingGVK(ctrlfwk.EncodeTrackingGVK(gvk))
+		if !ok {
+			t.Fatalf("failed to decode %v", gvk)
+		}
+		if decoded != gvk {
+			t.Fatalf("expected %v, got %v", gvk, decoded)
+		}
+	}
+
+	if _, ok := ctrlfwk.DecodeTrackingGVK("ConfigMap"); ok {
+		t.Fatal("expected a value without version to be invalid")
+	}
+}
+
+func TestSetTrackingLabels(t *testing.T) {
+	owner := &corev1.Secret{}
+	owner.SetUID(types.UID("1234"))
+
+	obj := &corev1.ConfigMap{}
+	if err := ctrlfwk.SetTrackingLabels(obj, owner, scheme.Scheme); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	labels := obj.GetLabels()
+	if labels[ctrlfwk.LabelTrackingOwnerUID] != "1234" {
+		t.Fatalf("unexpected owner uid label %q", labels[ctrlfwk.LabelTrackingOwnerUID])
+	}
+	if labels[ctrlfwk.LabelTrackingGVK] != "ConfigMap.v1" {
+		t.Fatalf("unexpected gvk label %q", labels[ctrlfwk.LabelTrackingGVK])
+	}
+}
@@ -0,0 +1,65 @@
+// Package unstructuredutil provides typed accessors over unstructured objects,
+// to be used from the mutators and readiness functions of untyped resources and dependencies.
+package unstructuredutil
+
+import (
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// UnstructuredHelper wraps an unstructured object with typed accessors.
+//
+// Getters return false when the field is missing or does not have the expected type.
+//
+// Example:
+//
+//	.WithReadinessCondition(func(obj *unstructured.Unstructured) bool {
+//		phase, _ := unstructuredutil.NewUnstructuredHelper(obj).GetString("status", "phase")
+//		return phase == "Ready"
+//	})
+type UnstructuredHelper struct {
+	obj *unstructured.Unstructured
+}
+
+// NewUnstructuredHelper returns an UnstructuredHelper for obj.
+func NewUnstructuredHelper(obj *unstructured.Unstructured) *UnstructuredHelper {
+	return &UnstructuredHelper{obj: obj}
+}
+
+// GetString returns the string at path.
+func (h *UnstructuredHelper) GetString(path ...string) (string, bool) {
+	value, found, err := unstructured.NestedString(h.content(), path...)
+	return value, found && err == nil
+}
+
+// GetInt64 returns the integer at path.
+func (h *UnstructuredHelper) GetInt64(path ...string) (int64, bool) {
+	value, found, err := unstructured.NestedInt64(h.content(), path...)
+	return value, found && err == nil
+}
+
+// GetBool returns the boolean at path.
+func (h *UnstructuredHelper) GetBool(path ...string) (bool, bool) {
+	value, found, err := unstructured.NestedBool(h.content(), path...)
+	return value, found && err == nil
+}
+
+// GetStringMap returns the map of strings at path, e.g. labels or a ConfigMap data.
+func (h *UnstructuredHelper) GetStringMap(path ...string) (map[string]string, bool) {
+	value, found, err := unstructured.NestedStringMap(h.content(), path...)
+	return value, found && err == nil
+}
+
+// SetString sets the string at path, creating the intermediate maps if needed.
+func (h *UnstructuredHelper) SetString(value string, path ...string) error {
+	if h.obj.Object == nil {
+		h.obj.Object = make(map[string]any)
+	}
+	return unstructured.SetNestedField(h.obj.Object, value, path...)
+}
+
+func (h *UnstructuredHelper) content() map[string]any {
+	if h.obj == nil {
+		return nil
+	}
+	return h.obj.Object
+}
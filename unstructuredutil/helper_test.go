@@ -0,0 +1,56 @@
+package unstructuredutil_test
+
+import (
+	"testing"
+
+	"github.com/u-ctf/controller-fwk/unstructuredutil"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestUnstructuredHelper_Getters(t *testing.T) {
+	obj := &unstructured.Unstructured{Object: map[string]any{
+		"metadata": map[string]any{
+			"labels": map[string]any{"app": "test"},
+		},
+		"spec": map[string]any{
+			"replicas": int64(3),
+			"paused":   true,
+		},
+		"status": map[string]any{
+			"phase": "Ready",
+		},
+	}}
+	helper := unstructuredutil.NewUnstructuredHelper(obj)
+
+	if phase, ok := helper.GetString("status", "phase"); !ok || phase != "Ready" {
+		t.Fatalf("expected phase Ready, got %q (%v)", phase, ok)
+	}
+	if replicas, ok := helper.GetInt64("spec", "replicas"); !ok || replicas != 3 {
+		t.Fatalf("expected 3 replicas, got %d (%v)", replicas, ok)
+	}
+	if paused, ok := helper.GetBool("spec", "paused"); !ok || !paused {
+		t.Fatalf("expected paused, got %v (%v)", paused, ok)
+	}
+	if labels, ok := helper.GetStringMap("metadata", "labels"); !ok || labels["app"] != "test" {
+		t.Fatalf("expected app label, got %v (%v)", labels, ok)
+	}
+	if _, ok := helper.GetString("spec", "replicas"); ok {
+		t.Fatal("expected a type mismatch to be reported as not found")
+	}
+	if _, ok := helper.GetString("spec", "missing"); ok {
+		t.Fatal("expected a missing field to be reported as not found")
+	}
+}
+
+func TestUnstructuredHelper_SetString(t *testing.T) {
+	obj := &unstructured.Unstructured{}
+	helper := unstructuredutil.NewUnstructuredHelper(obj)
+
+	if err := helper.SetString("/metrics", "spec", "endpoint", "path"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if path, ok := helper.GetString("spec", "endpoint", "path"); !ok || path != "/metrics" {
+		t.Fatalf("expected path /metrics, got %q (%v)", path, ok)
+	}
+}
@@ -1,16 +1,23 @@
 package ctrlfwk
 
 import (
+	"context"
+	"slices"
+
 	"github.com/pkg/errors"
+	"golang.org/x/time/rate"
+	"k8s.io/client-go/util/workqueue"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
 	"sigs.k8s.io/controller-runtime/pkg/event"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 	"sigs.k8s.io/controller-runtime/pkg/source"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 )
 
@@ -25,30 +32,52 @@ func SetupWatch[
 		// Setup watch if not already set
 		var partialObject metav1.PartialObjectMetadata
 		var partialObjectInterface client.Object = &partialObject
-		var gvk schema.GroupVersionKind
-		var err error
 
-		if object.GetObjectKind().GroupVersionKind().Kind != "" {
-			gvk = object.GetObjectKind().GroupVersionKind()
-			partialObject.SetGroupVersionKind(object.GetObjectKind().GroupVersionKind())
-		} else {
-			gvk, err = apiutil.GVKForObject(object, reconciler.Scheme())
-			if err != nil {
-				return ResultInError(errors.Wrap(err, "failed to get GVK for object"))
-			}
-			partialObject.SetGroupVersionKind(gvk)
+		gvk, err := getObjectGVK(object, reconciler.Scheme())
+		if err != nil {
+			return ResultInError(errors.Wrap(err, "failed to get GVK for object"))
 		}
+		partialObject.SetGroupVersionKind(gvk)
 
-		watchSource := NewWatchKey(gvk, CacheTypeEnqueueForOwner)
+		watchType := CacheTypeEnqueueForOwner
+		if isDependency {
+			watchType = CacheTypeEnqueueForDependents
+		}
+
+		watchSource := NewWatchKey(gvk, watchType)
 		if !reconciler.IsWatchingSource(watchSource) {
-			requestHandler := handler.EnqueueRequestForOwner(reconciler.GetScheme(), reconciler.GetRESTMapper(), ctx.GetCustomResource())
+			var requestHandler handler.TypedEventHandler[client.Object, reconcile.Request]
+			var watchPredicate predicate.Predicate = ResourceVersionChangedPredicate{}
+
 			if isDependency {
 				managedByHandler, err := GetManagedByReconcileRequests(ctx.GetCustomResource(), reconciler.GetScheme())
 				if err != nil {
 					return ResultInError(errors.Wrap(err, "failed to add watch source"))
 				}
 
-				requestHandler = handler.EnqueueRequestsFromMapFunc(managedByHandler)
+				mapFunc := getDependentsReconcileRequests(reconciler, gvk, managedByHandler)
+				if limiter := reconciler.GetDependencyRequeueLimiter(); limiter != nil {
+					requestHandler = &rateLimitedEnqueueRequestsFromMapFunc{mapFunc: mapFunc, limiter: limiter}
+				} else {
+					requestHandler = handler.EnqueueRequestsFromMapFunc(mapFunc)
+				}
+				crGVK, err := getObjectGVK(ctx.GetCustomResource(), reconciler.GetScheme())
+				if err != nil {
+					return ResultInError(errors.Wrap(err, "failed to add watch source"))
+				}
+
+				// Only the dependencies annotated by or tracked for this controller's custom resources are relevant
+				watchPredicate = predicate.And[client.Object](
+					DependencyChangedPredicate{},
+					predicate.Or[client.Object](
+						ManagedByPredicate{GVK: crGVK},
+						predicate.NewPredicateFuncs(func(obj client.Object) bool {
+							return len(reconciler.GetDependents(gvk, client.ObjectKeyFromObject(obj))) > 0
+						}),
+					),
+				)
+			} else {
+				requestHandler = ownerRequestHandler(reconciler, ctx.GetCustomResource())
 			}
 
 			// Add the watch source to the reconciler
@@ -57,7 +86,7 @@ func SetupWatch[
 					reconciler.GetCache(),
 					partialObjectInterface,
 					requestHandler,
-					ResourceVersionChangedPredicate{},
+					watchPredicate,
 				),
 			)
 			if err != nil {
@@ -71,6 +100,136 @@ func SetupWatch[
 	}
 }
 
+// ownerRequestHandler enqueues the custom resources owning the objects, owner being any custom resource of the reconciler.
+func ownerRequestHandler(watcher Watcher, owner client.Object) handler.TypedEventHandler[client.Object, reconcile.Request] {
+	return handler.EnqueueRequestForOwner(watcher.GetScheme(), watcher.GetRESTMapper(), owner)
+}
+
+// Owns sets up the watches of the objects of gvks owned by the custom resources of the controller, owner being
+// any custom resource of its type, so that the changes made to the resources they manage, e.g. a Deployment
+// edited by a user, trigger their reconciliation right away. It must be called once the controller is set,
+// see SetController.
+//
+// The watch of the resources of a GVK is otherwise set up when a resource of the GVK is first reconciled,
+// a change made before, e.g. while the controller restarts, only being corrected on the next resync.
+// Owns is typically used for the GVKs of the resources declared by every custom resource.
+//
+// Example:
+//
+//	reconciler.WatchCache.SetController(ctrler)
+//	return reconciler.WatchCache.Owns(&v1.App{}, appsv1.SchemeGroupVersion.WithKind("Deployment"))
+func (w *WatchCache) Owns(owner client.Object, gvks ...schema.GroupVersionKind) error {
+	if w.controller == nil {
+		return errors.New("failed to watch owned objects: the controller is not set")
+	}
+
+	for _, gvk := range gvks {
+		watchSource := NewWatchKey(gvk, CacheTypeEnqueueForOwner)
+		if w.IsWatchingSource(watchSource) {
+			continue
+		}
+
+		var partialObject metav1.PartialObjectMetadata
+		partialObject.SetGroupVersionKind(gvk)
+
+		err := w.controller.Watch(
+			source.Kind[client.Object](
+				w.GetCache(),
+				&partialObject,
+				ownerRequestHandler(w, owner),
+				ResourceVersionChangedPredicate{},
+			),
+		)
+		if err != nil {
+			return errors.Wrapf(err, "failed to watch owned %s", gvk)
+		}
+
+		w.AddWatchSource(watchSource)
+	}
+
+	return nil
+}
+
+func getObjectGVK(object client.Object, scheme *runtime.Scheme) (schema.GroupVersionKind, error) {
+	if object.GetObjectKind().GroupVersionKind().Kind != "" {
+		return object.GetObjectKind().GroupVersionKind(), nil
+	}
+	return apiutil.GVKForObject(object, scheme)
+}
+
+// getDependentsReconcileRequests maps an event on a dependency to the custom resources depending on it,
+// either through the managed-by annotation or through the dependencies tracked by the watcher.
+// The latter allows reacting to the creation of a dependency that did not exist yet.
+func getDependentsReconcileRequests(
+	watcher Watcher,
+	gvk schema.GroupVersionKind,
+	managedByHandler func(ctx context.Context, obj client.Object) []reconcile.Request,
+) func(ctx context.Context, obj client.Object) []reconcile.Request {
+	return func(ctx context.Context, obj client.Object) []reconcile.Request {
+		requests := managedByHandler(ctx, obj)
+
+		dependents := watcher.GetDependents(gvk, client.ObjectKeyFromObject(obj))
+		for _, dependent := range dependents {
+			request := reconcile.Request{NamespacedName: dependent}
+			if !slices.Contains(requests, request) {
+				requests = append(requests, request)
+			}
+		}
+
+		return requests
+	}
+}
+
+// rateLimitedEnqueueRequestsFromMapFunc enqueues the requests returned by mapFunc,
+// delaying them once the limiter runs out of tokens instead of dropping them.
+type rateLimitedEnqueueRequestsFromMapFunc struct {
+	mapFunc handler.MapFunc
+	limiter *rate.Limiter
+}
+
+var _ handler.EventHandler = &rateLimitedEnqueueRequestsFromMapFunc{}
+
+func (e *rateLimitedEnqueueRequestsFromMapFunc) Create(ctx context.Context, evt event.CreateEvent, q workqueue.TypedRateLimitingInterface[reconcile.Request]) {
+	e.mapAndEnqueue(ctx, q, evt.Object)
+}
+
+func (e *rateLimitedEnqueueRequestsFromMapFunc) Update(ctx context.Context, evt event.UpdateEvent, q workqueue.TypedRateLimitingInterface[reconcile.Request]) {
+	e.mapAndEnqueue(ctx, q, evt.ObjectOld, evt.ObjectNew)
+}
+
+func (e *rateLimitedEnqueueRequestsFromMapFunc) Delete(ctx context.Context, evt event.DeleteEvent, q workqueue.TypedRateLimitingInterface[reconcile.Request]) {
+	e.mapAndEnqueue(ctx, q, evt.Object)
+}
+
+func (e *rateLimitedEnqueueRequestsFromMapFunc) Generic(ctx context.Context, evt event.GenericEvent, q workqueue.TypedRateLimitingInterface[reconcile.Request]) {
+	e.mapAndEnqueue(ctx, q, evt.Object)
+}
+
+func (e *rateLimitedEnqueueRequestsFromMapFunc) mapAndEnqueue(ctx context.Context, q workqueue.TypedRateLimitingInterface[reconcile.Request], objects ...client.Object) {
+	var requests []reconcile.Request
+	for _, obj := range objects {
+		for _, request := range e.mapFunc(ctx, obj) {
+			if !slices.Contains(requests, request) {
+				requests = append(requests, request)
+			}
+		}
+	}
+
+	for _, request := range requests {
+		// Reservations that can't be granted, e.g. with a burst of 0, never end: the request is not delayed
+		reservation := e.limiter.Reserve()
+		if !reservation.OK() {
+			q.Add(request)
+			continue
+		}
+		if delay := reservation.Delay(); delay > 0 {
+			q.AddAfter(request, delay)
+		} else {
+			q.Add(request)
+		}
+	}
+}
+
 type ResourceVersionChangedPredicate struct {
 	predicate.Funcs
 }
@@ -90,3 +249,13 @@ func (ResourceVersionChangedPredicate) Delete(e event.DeleteEvent) bool {
 func (ResourceVersionChangedPredicate) Generic(e event.GenericEvent) bool {
 	return true
 }
+
+// DependencyChangedPredicate behaves like ResourceVersionChangedPredicate but also lets creation
+// events through, so that custom resources waiting for a missing dependency get reconciled as soon as it appears.
+type DependencyChangedPredicate struct {
+	ResourceVersionChangedPredicate
+}
+
+func (DependencyChangedPredicate) Create(e event.CreateEvent) bool {
+	return true
+}
@@ -1,7 +1,12 @@
 package ctrlfwk
 
 import (
+	"sync"
+
+	"golang.org/x/time/rate"
+
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
@@ -11,7 +16,8 @@ type WatchCacheKey string
 type WatchCacheType string
 
 const (
-	CacheTypeEnqueueForOwner WatchCacheType = "enqueueForOwner"
+	CacheTypeEnqueueForOwner      WatchCacheType = "enqueueForOwner"
+	CacheTypeEnqueueForDependents WatchCacheType = "enqueueForDependents"
 )
 
 type Watcher interface {
@@ -23,22 +29,71 @@ type Watcher interface {
 	IsWatchingSource(key WatchCacheKey) bool
 	// GetController returns the controller for the watch cache
 	GetController() controller.TypedController[reconcile.Request]
+
+	// TrackDependency records that the custom resource "dependent" depends on the object identified by gvk and key
+	TrackDependency(gvk schema.GroupVersionKind, key types.NamespacedName, dependent types.NamespacedName)
+	// UntrackDependent forgets every dependency recorded for the custom resource "dependent"
+	UntrackDependent(dependent types.NamespacedName)
+	// RetrackDependent records the dependencies of the custom resource "dependent" anew: the dependencies tracked
+	// again before the returned function is called are kept, the others are forgotten when it is called
+	RetrackDependent(dependent types.NamespacedName) (done func())
+	// GetDependents returns the custom resources that depend on the object identified by gvk and key
+	GetDependents(gvk schema.GroupVersionKind, key types.NamespacedName) []types.NamespacedName
+	// GetDependencyRequeueLimiter returns the limiter for requeues caused by dependency events, nil if unlimited
+	GetDependencyRequeueLimiter() *rate.Limiter
+}
+
+type dependencyRef struct {
+	gvk schema.GroupVersionKind
+	key types.NamespacedName
+}
+
+type dependentsRegistry struct {
+	lock       sync.RWMutex
+	dependents map[dependencyRef]map[types.NamespacedName]bool
+	// stale holds the dependencies of the dependents being retracked that were not tracked again yet
+	stale map[types.NamespacedName]map[dependencyRef]bool
 }
 
 type WatchCache struct {
-	cache      map[WatchCacheKey]bool
-	controller controller.TypedController[reconcile.Request]
+	cache        map[WatchCacheKey]bool
+	controller   controller.TypedController[reconcile.Request]
+	registry     *dependentsRegistry
+	registryOnce sync.Once
+	limiter      *rate.Limiter
+	objects      *objectCache
+	background   *backgroundRefresher
 
 	ctrl.Manager
 }
 
 func NewWatchCache(mgr ctrl.Manager) WatchCache {
 	return WatchCache{
-		cache:   make(map[WatchCacheKey]bool),
-		Manager: mgr,
+		cache:      make(map[WatchCacheKey]bool),
+		registry:   newDependentsRegistry(),
+		objects:    newObjectCache(),
+		background: newBackgroundRefresher(),
+		Manager:    mgr,
 	}
 }
 
+func newDependentsRegistry() *dependentsRegistry {
+	return &dependentsRegistry{
+		dependents: make(map[dependencyRef]map[types.NamespacedName]bool),
+		stale:      make(map[types.NamespacedName]map[dependencyRef]bool),
+	}
+}
+
+// getRegistry returns the registry of the dependents, created on first use for the zero value of WatchCache.
+func (w *WatchCache) getRegistry() *dependentsRegistry {
+	w.registryOnce.Do(func() {
+		if w.registry == nil {
+			w.registry = newDependentsRegistry()
+		}
+	})
+	return w.registry
+}
+
 func NewWatchKey(gvk schema.GroupVersionKind, watchType WatchCacheType) WatchCacheKey {
 	return WatchCacheKey(gvk.String() + "/" + string(watchType))
 }
@@ -65,3 +120,93 @@ func (w *WatchCache) GetController() controller.TypedController[reconcile.Reques
 func (w *WatchCache) SetController(ctrler controller.TypedController[reconcile.Request]) {
 	w.controller = ctrler
 }
+
+func (w *WatchCache) TrackDependency(gvk schema.GroupVersionKind, key types.NamespacedName, dependent types.NamespacedName) {
+	registry := w.getRegistry()
+
+	registry.lock.Lock()
+	defer registry.lock.Unlock()
+
+	ref := dependencyRef{gvk: gvk, key: key}
+	if registry.dependents[ref] == nil {
+		registry.dependents[ref] = make(map[types.NamespacedName]bool)
+	}
+	registry.dependents[ref][dependent] = true
+
+	if stale := registry.stale[dependent]; stale != nil {
+		delete(stale, ref)
+	}
+}
+
+func (w *WatchCache) UntrackDependent(dependent types.NamespacedName) {
+	registry := w.getRegistry()
+
+	registry.lock.Lock()
+	defer registry.lock.Unlock()
+
+	for ref, dependents := range registry.dependents {
+		registry.untrack(ref, dependents, dependent)
+	}
+	delete(registry.stale, dependent)
+}
+
+// RetrackDependent marks the dependencies of dependent as stale, the ones that are not tracked again being
+// forgotten by the returned function. Unlike UntrackDependent then TrackDependency, the dependencies that are
+// still declared are never missing from GetDependents in the meantime.
+func (w *WatchCache) RetrackDependent(dependent types.NamespacedName) func() {
+	registry := w.getRegistry()
+
+	registry.lock.Lock()
+	defer registry.lock.Unlock()
+
+	stale := make(map[dependencyRef]bool)
+	for ref, dependents := range registry.dependents {
+		if dependents[dependent] {
+			stale[ref] = true
+		}
+	}
+	registry.stale[dependent] = stale
+
+	return func() {
+		registry.lock.Lock()
+		defer registry.lock.Unlock()
+
+		for ref := range registry.stale[dependent] {
+			registry.untrack(ref, registry.dependents[ref], dependent)
+		}
+		delete(registry.stale, dependent)
+	}
+}
+
+// untrack removes dependent from the dependents of ref, the caller holding the lock of the registry.
+func (r *dependentsRegistry) untrack(ref dependencyRef, dependents map[types.NamespacedName]bool, dependent types.NamespacedName) {
+	delete(dependents, dependent)
+	if len(dependents) == 0 {
+		delete(r.dependents, ref)
+	}
+}
+
+func (w *WatchCache) GetDependents(gvk schema.GroupVersionKind, key types.NamespacedName) []types.NamespacedName {
+	registry := w.getRegistry()
+
+	registry.lock.RLock()
+	defer registry.lock.RUnlock()
+
+	dependents := registry.dependents[dependencyRef{gvk: gvk, key: key}]
+	out := make([]types.NamespacedName, 0, len(dependents))
+	for dependent := range dependents {
+		out = append(out, dependent)
+	}
+	return out
+}
+
+// SetDependencyRequeueRateLimit limits how many reconcile requests per second events on dependencies may enqueue,
+// using a token bucket of the given burst. Requests going over the limit are delayed rather than dropped.
+// It must be called before the watches are set up.
+func (w *WatchCache) SetDependencyRequeueRateLimit(limit rate.Limit, burst int) {
+	w.limiter = rate.NewLimiter(limit, max(burst, 1))
+}
+
+func (w *WatchCache) GetDependencyRequeueLimiter() *rate.Limiter {
+	return w.limiter
+}
@@ -0,0 +1,280 @@
+package ctrlfwk
+
+import (
+	"container/list"
+	"context"
+	"reflect"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+// DefaultObjectCacheLimit is the number of objects a WatchCache holds before evicting the least recently used ones,
+// see WatchCache.SetObjectCacheLimit.
+const DefaultObjectCacheLimit = 1024
+
+var (
+	// objectCacheObjects is the number of objects held by the object caches.
+	objectCacheObjects = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "ctrlfwk_object_cache_objects",
+		Help: "Number of objects held by the object caches",
+	})
+
+	// objectCacheRequestsTotal counts the reads of the object caches by result, hit or miss,
+	// the hit ratio being hits over the total.
+	objectCacheRequestsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "ctrlfwk_object_cache_requests_total",
+		Help: "Total number of object cache reads per result",
+	}, []string{"result"})
+)
+
+func init() {
+	metrics.Registry.MustRegister(objectCacheObjects, objectCacheRequestsTotal)
+}
+
+// ObjectCacheKey identifies an object read during the reconciliation of the custom resource Owner.
+type ObjectCacheKey struct {
+	Owner types.NamespacedName
+	GVK   schema.GroupVersionKind
+	types.NamespacedName
+}
+
+// NewObjectCacheKey returns the key of the object identified by gvk and key, read while reconciling owner.
+func NewObjectCacheKey(owner client.Object, gvk schema.GroupVersionKind, key types.NamespacedName) ObjectCacheKey {
+	return ObjectCacheKey{Owner: client.ObjectKeyFromObject(owner), GVK: gvk, NamespacedName: key}
+}
+
+// ObjectCache holds the objects read by the dependency and resource steps during the reconciliation
+// of a custom resource, so that they are read once per reconciliation. The entries of a custom resource are
+// invalidated when its next reconciliation starts, see NewFindControllerCustomResourceStep.
+//
+// It is implemented by WatchCache, and must be safe for concurrent use.
+type ObjectCache interface {
+	// GetObject returns a copy of the cached object, false if it is not cached.
+	GetObject(key ObjectCacheKey) (client.Object, bool)
+	// Put caches a copy of obj.
+	Put(key ObjectCacheKey, obj client.Object)
+	// Invalidate removes the object from the cache, e.g. after patching it, so that it is read again on the next access.
+	Invalidate(key ObjectCacheKey)
+	// InvalidateByOwner removes the objects read during the reconciliation of the custom resource owner.
+	InvalidateByOwner(owner types.NamespacedName)
+	// OnEvict registers f to be called with the objects removed from the cache, whether invalidated or evicted.
+	OnEvict(f func(key ObjectCacheKey, obj client.Object))
+}
+
+var _ ObjectCache = &WatchCache{}
+
+type objectCacheEntry struct {
+	key ObjectCacheKey
+	obj client.Object
+}
+
+type objectCache struct {
+	lock  sync.Mutex
+	limit int
+	// entries are ordered from the most to the least recently used
+	entries  *list.List
+	elements map[ObjectCacheKey]*list.Element
+	onEvict  []func(key ObjectCacheKey, obj client.Object)
+}
+
+func newObjectCache() *objectCache {
+	return &objectCache{
+		limit:    DefaultObjectCacheLimit,
+		entries:  list.New(),
+		elements: make(map[ObjectCacheKey]*list.Element),
+	}
+}
+
+func (w *WatchCache) objectCache() *objectCache {
+	if w.objects == nil {
+		w.objects = newObjectCache()
+	}
+	return w.objects
+}
+
+// SetObjectCacheLimit sets the number of objects the cache holds before evicting the least recently used ones,
+// DefaultObjectCacheLimit by default.
+func (w *WatchCache) SetObjectCacheLimit(limit int) {
+	cache := w.objectCache()
+
+	cache.lock.Lock()
+	cache.limit = max(limit, 1)
+	evicted := cache.evictOverLimit()
+	callbacks := cache.onEvict
+	cache.lock.Unlock()
+
+	notifyEvicted(callbacks, evicted)
+}
+
+func (w *WatchCache) GetObject(key ObjectCacheKey) (client.Object, bool) {
+	cache := w.objectCache()
+
+	cache.lock.Lock()
+	defer cache.lock.Unlock()
+
+	element, ok := cache.elements[key]
+	if !ok {
+		objectCacheRequestsTotal.WithLabelValues("miss").Inc()
+		return nil, false
+	}
+	objectCacheRequestsTotal.WithLabelValues("hit").Inc()
+	cache.entries.MoveToFront(element)
+	return element.Value.(*objectCacheEntry).obj.DeepCopyObject().(client.Object), true
+}
+
+func (w *WatchCache) Put(key ObjectCacheKey, obj client.Object) {
+	cache := w.objectCache()
+
+	cache.lock.Lock()
+	entry := &objectCacheEntry{key: key, obj: obj.DeepCopyObject().(client.Object)}
+	if element, ok := cache.elements[key]; ok {
+		element.Value = entry
+		cache.entries.MoveToFront(element)
+	} else {
+		cache.elements[key] = cache.entries.PushFront(entry)
+		objectCacheObjects.Inc()
+	}
+	evicted := cache.evictOverLimit()
+	callbacks := cache.onEvict
+	cache.lock.Unlock()
+
+	notifyEvicted(callbacks, evicted)
+}
+
+func (w *WatchCache) Invalidate(key ObjectCacheKey) {
+	cache := w.objectCache()
+
+	cache.lock.Lock()
+	var evicted []*objectCacheEntry
+	if element, ok := cache.elements[key]; ok {
+		evicted = append(evicted, cache.remove(element))
+	}
+	callbacks := cache.onEvict
+	cache.lock.Unlock()
+
+	notifyEvicted(callbacks, evicted)
+}
+
+func (w *WatchCache) InvalidateByOwner(owner types.NamespacedName) {
+	cache := w.objectCache()
+
+	cache.lock.Lock()
+	var evicted []*objectCacheEntry
+	for key, element := range cache.elements {
+		if key.Owner == owner {
+			evicted = append(evicted, cache.remove(element))
+		}
+	}
+	callbacks := cache.onEvict
+	cache.lock.Unlock()
+
+	notifyEvicted(callbacks, evicted)
+}
+
+func (w *WatchCache) OnEvict(f func(key ObjectCacheKey, obj client.Object)) {
+	cache := w.objectCache()
+
+	cache.lock.Lock()
+	defer cache.lock.Unlock()
+
+	cache.onEvict = append(cache.onEvict, f)
+}
+
+// ObjectCacheSize returns the number of objects held by the cache.
+func (w *WatchCache) ObjectCacheSize() int {
+	cache := w.objectCache()
+
+	cache.lock.Lock()
+	defer cache.lock.Unlock()
+
+	return len(cache.elements)
+}
+
+// evictOverLimit removes the least recently used entries while the cache holds more than its limit.
+// The lock must be held.
+func (c *objectCache) evictOverLimit() []*objectCacheEntry {
+	var evicted []*objectCacheEntry
+	for len(c.elements) > c.limit {
+		evicted = append(evicted, c.remove(c.entries.Back()))
+	}
+	return evicted
+}
+
+// remove removes the entry of element from the cache. The lock must be held.
+func (c *objectCache) remove(element *list.Element) *objectCacheEntry {
+	entry := element.Value.(*objectCacheEntry)
+	c.entries.Remove(element)
+	delete(c.elements, entry.key)
+	objectCacheObjects.Dec()
+	return entry
+}
+
+// notifyEvicted calls the eviction callbacks, without holding the lock so that they can use the cache.
+func notifyEvicted(callbacks []func(key ObjectCacheKey, obj client.Object), evicted []*objectCacheEntry) {
+	for _, entry := range evicted {
+		for _, f := range callbacks {
+			f(entry.key, entry.obj)
+		}
+	}
+}
+
+// GetCachedObject returns the object of cache identified by key as a T, false if it is not cached
+// or is not a T.
+//
+// Example:
+//
+//	secret, ok := ctrlfwk.GetCachedObject[*corev1.Secret](reconciler, ctrlfwk.NewObjectCacheKey(cr, secretGVK, key))
+func GetCachedObject[T client.Object](cache ObjectCache, key ObjectCacheKey) (T, bool) {
+	obj, ok := cache.GetObject(key)
+	if !ok {
+		var zero T
+		return zero, false
+	}
+	typed, ok := obj.(T)
+	return typed, ok
+}
+
+// objectCacheFor returns the object cache of the reconciler, nil if it has none.
+func objectCacheFor(reconciler any) ObjectCache {
+	cache, _ := reconciler.(ObjectCache)
+	return cache
+}
+
+// getThroughObjectCache reads the object identified by key into obj, from cache when it holds it.
+// Objects read from the client are cached, except the ones that are not found.
+func getThroughObjectCache(ctx context.Context, c client.Client, cache ObjectCache, cacheKey ObjectCacheKey, obj client.Object) error {
+	if cache == nil {
+		return c.Get(ctx, cacheKey.NamespacedName, obj)
+	}
+
+	if cached, ok := cache.GetObject(cacheKey); ok && reflect.TypeOf(cached) == reflect.TypeOf(obj) {
+		reflect.ValueOf(obj).Elem().Set(reflect.ValueOf(cached).Elem())
+		return nil
+	}
+
+	if err := c.Get(ctx, cacheKey.NamespacedName, obj); err != nil {
+		return err
+	}
+	cache.Put(cacheKey, obj)
+	return nil
+}
+
+// objectCacheKeyFor returns the object cache of the reconciler and the key of obj read while reconciling cr,
+// a nil cache when the reconciler has none or the GVK of obj can't be resolved.
+func objectCacheKeyFor[K client.Object](reconciler Reconciler[K], cr client.Object, obj client.Object) (ObjectCache, ObjectCacheKey) {
+	key := ObjectCacheKey{NamespacedName: client.ObjectKeyFromObject(obj)}
+	cache := objectCacheFor(reconciler)
+	if cache == nil {
+		return nil, key
+	}
+	gvk, err := getObjectGVK(obj, reconciler.Scheme())
+	if err != nil {
+		return nil, key
+	}
+	return cache, NewObjectCacheKey(cr, gvk, key.NamespacedName)
+}
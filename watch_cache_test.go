@@ -0,0 +1,255 @@
+package ctrlfwk_test
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	ctrlfwk "github.com/u-ctf/controller-fwk"
+	"github.com/u-ctf/controller-fwk/mocks"
+	"go.uber.org/mock/gomock"
+	"golang.org/x/time/rate"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+func TestWatchCache_TrackDependency(t *testing.T) {
+	cache := ctrlfwk.NewWatchCache(nil)
+
+	secretGVK := schema.GroupVersionKind{Version: "v1", Kind: "Secret"}
+	secretKey := types.NamespacedName{Name: "secret", Namespace: "default"}
+	crA := types.NamespacedName{Name: "a", Namespace: "default"}
+	crB := types.NamespacedName{Name: "b", Namespace: "default"}
+
+	cache.TrackDependency(secretGVK, secretKey, crA)
+	cache.TrackDependency(secretGVK, secretKey, crB)
+	cache.TrackDependency(secretGVK, secretKey, crB)
+
+	dependents := cache.GetDependents(secretGVK, secretKey)
+	if len(dependents) != 2 {
+		t.Fatalf("expected 2 dependents, got %d", len(dependents))
+	}
+
+	configMapGVK := schema.GroupVersionKind{Version: "v1", Kind: "ConfigMap"}
+	if len(cache.GetDependents(configMapGVK, secretKey)) != 0 {
+		t.Fatal("expected no dependents for another GVK")
+	}
+
+	cache.UntrackDependent(crA)
+
+	dependents = cache.GetDependents(secretGVK, secretKey)
+	if len(dependents) != 1 || dependents[0] != crB {
+		t.Fatalf("expected only %v to be a dependent, got %v", crB, dependents)
+	}
+}
+
+func TestWatchCache_RetrackDependent(t *testing.T) {
+	cache := ctrlfwk.NewWatchCache(nil)
+
+	secretGVK := schema.GroupVersionKind{Version: "v1", Kind: "Secret"}
+	kept := types.NamespacedName{Name: "kept", Namespace: "default"}
+	dropped := types.NamespacedName{Name: "dropped", Namespace: "default"}
+	cr := types.NamespacedName{Name: "cr", Namespace: "default"}
+
+	cache.TrackDependency(secretGVK, kept, cr)
+	cache.TrackDependency(secretGVK, dropped, cr)
+
+	done := cache.RetrackDependent(cr)
+	cache.TrackDependency(secretGVK, kept, cr)
+
+	// Dependencies are only forgotten once retracked
+	if len(cache.GetDependents(secretGVK, kept)) != 1 || len(cache.GetDependents(secretGVK, dropped)) != 1 {
+		t.Fatal("expected the dependencies to be kept while retracking")
+	}
+
+	done()
+
+	if dependents := cache.GetDependents(secretGVK, kept); len(dependents) != 1 || dependents[0] != cr {
+		t.Fatalf("expected %v to still depend on the dependency tracked again, got %v", cr, dependents)
+	}
+	if dependents := cache.GetDependents(secretGVK, dropped); len(dependents) != 0 {
+		t.Fatalf("expected the dependency not tracked again to be forgotten, got %v", dependents)
+	}
+}
+
+func TestWatchCache_ZeroValueConcurrentTracking(t *testing.T) {
+	var cache ctrlfwk.WatchCache
+
+	var wg sync.WaitGroup
+	for i := range 10 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			key := types.NamespacedName{Name: fmt.Sprintf("secret-%d", i), Namespace: "default"}
+			cache.TrackDependency(schema.GroupVersionKind{}, key, key)
+			cache.GetDependents(schema.GroupVersionKind{}, key)
+		}()
+	}
+	wg.Wait()
+
+	for i := range 10 {
+		key := types.NamespacedName{Name: fmt.Sprintf("secret-%d", i), Namespace: "default"}
+		if len(cache.GetDependents(schema.GroupVersionKind{}, key)) != 1 {
+			t.Fatalf("expected dependency %v to be tracked", key)
+		}
+	}
+}
+
+func TestWatchCache_ZeroValue(t *testing.T) {
+	var cache ctrlfwk.WatchCache
+
+	key := types.NamespacedName{Name: "secret", Namespace: "default"}
+	if len(cache.GetDependents(schema.GroupVersionKind{}, key)) != 0 {
+		t.Fatal("expected no dependents on a zero value cache")
+	}
+
+	cache.TrackDependency(schema.GroupVersionKind{}, key, key)
+	if len(cache.GetDependents(schema.GroupVersionKind{}, key)) != 1 {
+		t.Fatal("expected dependency to be tracked on a zero value cache")
+	}
+}
+
+func TestWatchCache_Objects(t *testing.T) {
+	cache := ctrlfwk.NewWatchCache(nil)
+
+	var evicted []string
+	cache.OnEvict(func(key ctrlfwk.ObjectCacheKey, obj client.Object) {
+		evicted = append(evicted, obj.GetName())
+	})
+
+	secretGVK := schema.GroupVersionKind{Version: "v1", Kind: "Secret"}
+	crA := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "a", Namespace: "default"}}
+	crB := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "b", Namespace: "default"}}
+	put := func(cr client.Object, name string) ctrlfwk.ObjectCacheKey {
+		key := ctrlfwk.NewObjectCacheKey(cr, secretGVK, types.NamespacedName{Name: name, Namespace: "default"})
+		cache.Put(key, &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"}})
+		return key
+	}
+
+	first := put(crA, "first")
+	put(crA, "second")
+	third := put(crB, "third")
+
+	secret, ok := ctrlfwk.GetCachedObject[*corev1.Secret](&cache, first)
+	if !ok || secret.GetName() != "first" {
+		t.Fatalf("expected the cached secret, got %v", secret)
+	}
+	if _, ok := ctrlfwk.GetCachedObject[*corev1.ConfigMap](&cache, first); ok {
+		t.Fatal("expected a secret not to be returned as a configmap")
+	}
+
+	cache.Invalidate(first)
+	if _, ok := cache.GetObject(first); ok {
+		t.Fatal("expected the invalidated secret to be removed")
+	}
+
+	cache.InvalidateByOwner(client.ObjectKeyFromObject(crA))
+	if cache.ObjectCacheSize() != 1 {
+		t.Fatalf("expected only the objects of %s to remain, got %d objects", crB.Name, cache.ObjectCacheSize())
+	}
+
+	cache.SetObjectCacheLimit(1)
+	put(crB, "fourth")
+	if _, ok := cache.GetObject(third); ok {
+		t.Fatal("expected the least recently used secret to be evicted")
+	}
+
+	if len(evicted) != 3 || evicted[0] != "first" || evicted[2] != "third" {
+		t.Fatalf("expected the removed secrets to be notified, got %v", evicted)
+	}
+}
+
+func TestWatchCache_Owns(t *testing.T) {
+	cache := ctrlfwk.NewWatchCache(newStubManager())
+	deploymentGVK := schema.GroupVersionKind{Group: "apps", Version: "v1", Kind: "Deployment"}
+
+	if err := cache.Owns(&corev1.ConfigMap{}, deploymentGVK); err == nil {
+		t.Fatal("expected an error while the controller is not set")
+	}
+
+	controller := mocks.NewMockTypedController[reconcile.Request](gomock.NewController(t))
+	controller.EXPECT().Watch(gomock.Any()).Return(nil).Times(1)
+	cache.SetController(controller)
+
+	for range 2 {
+		if err := cache.Owns(&corev1.ConfigMap{}, deploymentGVK); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if !cache.IsWatchingSource(ctrlfwk.NewWatchKey(deploymentGVK, ctrlfwk.CacheTypeEnqueueForOwner)) {
+		t.Fatal("expected the owned deployments to be watched")
+	}
+}
+
+func TestWatchCache_BackgroundReconcile(t *testing.T) {
+	cache := ctrlfwk.NewWatchCache(nil)
+	cr := types.NamespacedName{Name: "cr", Namespace: "default"}
+
+	if err := cache.ScheduleBackgroundReconcile(cr, "certificate", time.Millisecond); err == nil {
+		t.Fatal("expected an error while the controller is not set")
+	}
+
+	controller := mocks.NewMockTypedController[reconcile.Request](gomock.NewController(t))
+	controller.EXPECT().Watch(gomock.Any()).Return(nil).Times(1)
+	cache.SetController(controller)
+
+	for range 2 {
+		if err := cache.ScheduleBackgroundReconcile(cr, "certificate", time.Millisecond); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	defer cache.StopBackgroundReconcile(cr)
+
+	deadline := time.Now().Add(time.Second)
+	for !cache.ConsumeBackgroundRefresh(cr) {
+		if time.Now().After(deadline) {
+			t.Fatal("expected a background refresh to be pending")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	if cache.ConsumeBackgroundRefresh(types.NamespacedName{Name: "other", Namespace: "default"}) {
+		t.Fatal("expected no background refresh of an unscheduled custom resource")
+	}
+}
+
+func TestRateLimitedEnqueueRequests(t *testing.T) {
+	request := reconcile.Request{NamespacedName: types.NamespacedName{Name: "cr", Namespace: "default"}}
+	mapFunc := func(context.Context, client.Object) []reconcile.Request { return []reconcile.Request{request} }
+
+	exhausted := rate.NewLimiter(rate.Every(time.Hour), 1)
+	exhausted.Allow()
+
+	cases := map[string]struct {
+		limiter  *rate.Limiter
+		enqueued bool
+	}{
+		"within the limit":  {limiter: rate.NewLimiter(1, 1), enqueued: true},
+		"over the limit":    {limiter: exhausted, enqueued: false},
+		"burst of 0":        {limiter: rate.NewLimiter(1, 0), enqueued: true},
+		"limit and burst 0": {limiter: rate.NewLimiter(0, 0), enqueued: true},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			q := workqueue.NewTypedRateLimitingQueue(workqueue.DefaultTypedControllerRateLimiter[reconcile.Request]())
+			defer q.ShutDown()
+
+			h := ctrlfwk.NewRateLimitedEnqueueRequestsFromMapFunc(mapFunc, tc.limiter)
+			h.Create(context.Background(), event.CreateEvent{Object: &corev1.Secret{}}, q)
+
+			// Delayed requests are not in the queue yet
+			if enqueued := q.Len() == 1; enqueued != tc.enqueued {
+				t.Fatalf("expected the request to be enqueued right away: %v, got %v", tc.enqueued, enqueued)
+			}
+		})
+	}
+}
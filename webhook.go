@@ -0,0 +1,169 @@
+package ctrlfwk
+
+import (
+	"context"
+	"fmt"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+// WebhookBuilder builds the defaulting and validating admission webhooks of a custom resource.
+//
+// The webhooks work on the same typed custom resource as the reconciler, so the validation
+// logic can be shared with NewValidateStep, see WithFieldValidator.
+//
+// Example:
+//
+//	func validateSpec(cr *v1.MyResource) field.ErrorList {
+//		var errs field.ErrorList
+//		if cr.Spec.Replicas < 0 {
+//			errs = append(errs, field.Invalid(field.NewPath("spec", "replicas"), cr.Spec.Replicas, "must be positive"))
+//		}
+//		return errs
+//	}
+//
+//	// In main.go
+//	err := ctrlfwk.NewWebhookBuilder(&v1.MyResource{}).
+//		WithDefaulter(func(cr *v1.MyResource) {
+//			if cr.Spec.Replicas == 0 {
+//				cr.Spec.Replicas = 1
+//			}
+//		}).
+//		WithFieldValidator(validateSpec).
+//		Complete(mgr)
+//
+//	// In the reconciler
+//	stepper.WithStep(ctrlfwk.NewValidateStep(ctx, reconciler, ctrlfwk.ValidateCustomResource[*v1.MyResource, MyContext](validateSpec)))
+type WebhookBuilder[ControllerResourceType ControllerCustomResource] struct {
+	obj             ControllerResourceType
+	defaulter       func(cr ControllerResourceType)
+	validator       func(old, new ControllerResourceType) error
+	fieldValidators []func(cr ControllerResourceType) field.ErrorList
+}
+
+// NewWebhookBuilder creates a new WebhookBuilder for the type of the given custom resource.
+func NewWebhookBuilder[ControllerResourceType ControllerCustomResource](obj ControllerResourceType) *WebhookBuilder[ControllerResourceType] {
+	return &WebhookBuilder[ControllerResourceType]{obj: obj}
+}
+
+// WithDefaulter registers a defaulting webhook setting the default values of the custom resource.
+func (b *WebhookBuilder[ControllerResourceType]) WithDefaulter(f func(cr ControllerResourceType)) *WebhookBuilder[ControllerResourceType] {
+	b.defaulter = f
+	return b
+}
+
+// WithValidator registers a validating webhook, called on creation and update of the custom resource.
+// On creation, old is the zero value of the custom resource type.
+// Returning an error rejects the request.
+func (b *WebhookBuilder[ControllerResourceType]) WithValidator(f func(old, new ControllerResourceType) error) *WebhookBuilder[ControllerResourceType] {
+	b.validator = f
+	return b
+}
+
+// WithFieldValidator registers a validating webhook, called with the new version of the custom resource
+// on creation and update. The same function can be used by NewValidateStep, see ValidateCustomResource.
+func (b *WebhookBuilder[ControllerResourceType]) WithFieldValidator(f func(cr ControllerResourceType) field.ErrorList) *WebhookBuilder[ControllerResourceType] {
+	b.fieldValidators = append(b.fieldValidators, f)
+	return b
+}
+
+// Complete registers the webhooks with the manager.
+func (b *WebhookBuilder[ControllerResourceType]) Complete(mgr ctrl.Manager) error {
+	gvk, err := apiutil.GVKForObject(b.obj, mgr.GetScheme())
+	if err != nil {
+		return err
+	}
+
+	handler := &customResourceWebhook[ControllerResourceType]{
+		WebhookBuilder: b,
+		groupKind:      gvk.GroupKind(),
+	}
+
+	webhook := ctrl.NewWebhookManagedBy(mgr).For(b.obj)
+	if b.defaulter != nil {
+		webhook = webhook.WithDefaulter(handler)
+	}
+	if b.validator != nil || len(b.fieldValidators) > 0 {
+		webhook = webhook.WithValidator(handler)
+	}
+
+	return webhook.Complete()
+}
+
+// ValidateCustomResource adapts a validation function of the custom resource to be used by NewValidateStep.
+func ValidateCustomResource[
+	ControllerResourceType ControllerCustomResource,
+	ContextType Context[ControllerResourceType],
+](f func(cr ControllerResourceType) field.ErrorList) ValidateFunc[ControllerResourceType, ContextType] {
+	return func(ctx ContextType) field.ErrorList {
+		return f(ctx.GetCustomResource())
+	}
+}
+
+// customResourceWebhook implements the controller-runtime webhook interfaces for a WebhookBuilder.
+type customResourceWebhook[ControllerResourceType ControllerCustomResource] struct {
+	*WebhookBuilder[ControllerResourceType]
+	groupKind schema.GroupKind
+}
+
+var _ admission.CustomDefaulter = &customResourceWebhook[ControllerCustomResource]{}
+var _ admission.CustomValidator = &customResourceWebhook[ControllerCustomResource]{}
+
+func (w *customResourceWebhook[ControllerResourceType]) Default(_ context.Context, obj runtime.Object) error {
+	cr, ok := obj.(ControllerResourceType)
+	if !ok {
+		return fmt.Errorf("expected a %T, got %T", w.obj, obj)
+	}
+
+	w.defaulter(cr)
+	return nil
+}
+
+func (w *customResourceWebhook[ControllerResourceType]) ValidateCreate(_ context.Context, obj runtime.Object) (admission.Warnings, error) {
+	cr, ok := obj.(ControllerResourceType)
+	if !ok {
+		return nil, fmt.Errorf("expected a %T, got %T", w.obj, obj)
+	}
+
+	var zero ControllerResourceType
+	return nil, w.validate(zero, cr)
+}
+
+func (w *customResourceWebhook[ControllerResourceType]) ValidateUpdate(_ context.Context, oldObj, newObj runtime.Object) (admission.Warnings, error) {
+	oldCR, ok := oldObj.(ControllerResourceType)
+	if !ok {
+		return nil, fmt.Errorf("expected a %T, got %T", w.obj, oldObj)
+	}
+	newCR, ok := newObj.(ControllerResourceType)
+	if !ok {
+		return nil, fmt.Errorf("expected a %T, got %T", w.obj, newObj)
+	}
+
+	return nil, w.validate(oldCR, newCR)
+}
+
+func (w *customResourceWebhook[ControllerResourceType]) ValidateDelete(_ context.Context, _ runtime.Object) (admission.Warnings, error) {
+	return nil, nil
+}
+
+func (w *customResourceWebhook[ControllerResourceType]) validate(oldCR, newCR ControllerResourceType) error {
+	var errs field.ErrorList
+	for _, f := range w.fieldValidators {
+		errs = append(errs, f(newCR)...)
+	}
+	if len(errs) > 0 {
+		return apierrors.NewInvalid(w.groupKind, newCR.GetName(), errs)
+	}
+
+	if w.validator != nil {
+		return w.validator(oldCR, newCR)
+	}
+
+	return nil
+}
@@ -0,0 +1,142 @@
+package ctrlfwk_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	ctrlfwk "github.com/u-ctf/controller-fwk"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/client-go/rest"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+)
+
+// webhookManager is a manager serving the registered webhooks from an in-memory mux.
+type webhookManager struct {
+	manager.Manager
+	scheme *runtime.Scheme
+	server webhook.Server
+}
+
+func (m *webhookManager) GetScheme() *runtime.Scheme       { return m.scheme }
+func (m *webhookManager) GetConfig() *rest.Config          { return &rest.Config{} }
+func (m *webhookManager) GetWebhookServer() webhook.Server { return m.server }
+
+func TestWebhookBuilder(t *testing.T) {
+	ctx, _ := newConditionsTest(t)
+	gvk := schema.GroupVersionKind{Group: "test.ctrlfwk.com", Version: "v1", Kind: "conditionsCR"}
+
+	scheme := runtime.NewScheme()
+	scheme.AddKnownTypeWithName(gvk, &conditionsCR{})
+	mgr := &webhookManager{scheme: scheme, server: webhook.NewServer(webhook.Options{})}
+
+	validateSize := func(cr *conditionsCR) field.ErrorList {
+		if cr.GetAnnotations()["size"] == "-1" {
+			return field.ErrorList{field.Invalid(field.NewPath("metadata", "annotations", "size"), "-1", "must be positive")}
+		}
+		return nil
+	}
+	err := ctrlfwk.NewWebhookBuilder(&conditionsCR{}).
+		WithDefaulter(func(cr *conditionsCR) {
+			if cr.GetAnnotations()["size"] == "" {
+				cr.SetAnnotations(map[string]string{"size": "1"})
+			}
+		}).
+		WithFieldValidator(validateSize).
+		WithValidator(func(old, new *conditionsCR) error {
+			if old != nil && old.GetLabels()["tier"] != new.GetLabels()["tier"] {
+				return errors.New("tier is immutable")
+			}
+			return nil
+		}).
+		Complete(mgr)
+	if err != nil {
+		t.Fatalf("failed to register webhooks: %v", err)
+	}
+
+	review := func(path string, operation admissionv1.Operation, cr, old *conditionsCR) *admissionv1.AdmissionResponse {
+		t.Helper()
+		raw := func(cr *conditionsCR) runtime.RawExtension {
+			if cr == nil {
+				return runtime.RawExtension{}
+			}
+			cr.SetGroupVersionKind(gvk)
+			data, err := json.Marshal(cr)
+			if err != nil {
+				t.Fatalf("failed to encode custom resource: %v", err)
+			}
+			return runtime.RawExtension{Raw: data}
+		}
+		body, err := json.Marshal(admissionv1.AdmissionReview{
+			TypeMeta: metav1.TypeMeta{APIVersion: "admission.k8s.io/v1", Kind: "AdmissionReview"},
+			Request: &admissionv1.AdmissionRequest{
+				UID:       "uid",
+				Kind:      metav1.GroupVersionKind(gvk),
+				Operation: operation,
+				Object:    raw(cr),
+				OldObject: raw(old),
+			},
+		})
+		if err != nil {
+			t.Fatalf("failed to encode admission review: %v", err)
+		}
+
+		request := httptest.NewRequest(http.MethodPost, path, bytes.NewReader(body))
+		request.Header.Set("Content-Type", "application/json")
+		recorder := httptest.NewRecorder()
+		mgr.server.WebhookMux().ServeHTTP(recorder, request)
+
+		response := &admissionv1.AdmissionReview{}
+		if err := json.Unmarshal(recorder.Body.Bytes(), response); err != nil || response.Response == nil {
+			t.Fatalf("failed to decode admission review %q: %v", recorder.Body.String(), err)
+		}
+		return response.Response
+	}
+	cr := func(size, tier string) *conditionsCR {
+		cr := &conditionsCR{ObjectMeta: metav1.ObjectMeta{Name: "cr", Namespace: "default", Labels: map[string]string{"tier": tier}}}
+		if size != "" {
+			cr.SetAnnotations(map[string]string{"size": size})
+		}
+		return cr
+	}
+
+	// The defaulter fills the missing values
+	response := review("/mutate-test-ctrlfwk-com-v1-conditionscr", admissionv1.Create, cr("", "web"), nil)
+	if !response.Allowed || !strings.Contains(string(response.Patch), `"size":"1"`) {
+		t.Fatalf("expected the defaults to be patched, got %+v", response)
+	}
+
+	// The field validators reject the invalid fields
+	validatePath := "/validate-test-ctrlfwk-com-v1-conditionscr"
+	response = review(validatePath, admissionv1.Create, cr("-1", "web"), nil)
+	if response.Allowed || response.Result.Code != http.StatusUnprocessableEntity || !strings.Contains(response.Result.Message, "metadata.annotations.size") {
+		t.Fatalf("expected the invalid field to be rejected, got %+v", response)
+	}
+	if response := review(validatePath, admissionv1.Create, cr("2", "web"), nil); !response.Allowed {
+		t.Fatalf("expected the valid custom resource to be allowed, got %+v", response.Result)
+	}
+
+	// The validator compares the old and new versions on updates
+	if response := review(validatePath, admissionv1.Update, cr("2", "db"), cr("2", "web")); response.Allowed || !strings.Contains(response.Result.Message, "tier is immutable") {
+		t.Fatalf("expected the update to be rejected, got %+v", response)
+	}
+	if response := review(validatePath, admissionv1.Update, cr("3", "web"), cr("2", "web")); !response.Allowed {
+		t.Fatalf("expected the update to be allowed, got %+v", response.Result)
+	}
+
+	// The same validation is used by the validate step
+	ctx.GetCustomResource().SetAnnotations(map[string]string{"size": "-1"})
+	if errs := ctrlfwk.ValidateCustomResource[*conditionsCR, conditionsContext](validateSize)(ctx); len(errs) != 1 {
+		t.Fatalf("expected the custom resource of the context to be validated, got %v", errs)
+	}
+}